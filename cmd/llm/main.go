@@ -1,41 +1,82 @@
 package main
 
 import (
+    "context"
     "flag"
     "log"
     "net"
-    "net/http"
+    "os"
+    "os/signal"
+    "strconv"
+    "syscall"
+    "time"
 
     "google.golang.org/grpc"
 
+    "yuzu/agent/internal/grpcmw"
+    "yuzu/agent/internal/health"
+    "yuzu/agent/internal/probes"
+    "yuzu/agent/internal/warmup"
     llm "yuzu/agent/internal/llm"
     pb "yuzu/agent/internal/llm/pb"
-    "github.com/prometheus/client_golang/prometheus/promhttp"
 )
 
 var (
-    addr = flag.String("addr", ":9092", "llm service listen addr")
+    addr      = flag.String("addr", ":9092", "llm service listen addr")
+    probeAddr = flag.String("probe-addr", probes.Addr("LLM_PROBE_ADDR", ":8083"), "health/ready/metrics listen addr")
 )
 
 func main(){
     flag.Parse()
-    s := grpc.NewServer()
+    s := grpc.NewServer(grpcmw.ServerOptions(grpcmw.OptionsFromEnv("llm"))...)
     srv := llm.NewServer()
     pb.RegisterLLMServer(s, srv)
 
+    // Readiness: config presence plus a cheap provider ping, rechecked periodically
+    ping := os.Getenv("READYZ_PING") != "false"
+    ready := health.NewReadiness("azure_openai", func(ctx context.Context) error { return llm.CheckProvider(ctx, ping) })
+    if v, err := strconv.Atoi(os.Getenv("READYZ_INTERVAL_S")); err == nil && v > 0 { ready.Interval = time.Duration(v) * time.Second }
+    go ready.Run(context.Background())
+
+    // Optional warm-ups so the first turn doesn't pay cold-start costs
+    if w := warmup.FromEnv("llm", srv.Warm); w != nil {
+        srv.SetWarmer(w)
+        go w.Run(context.Background())
+    }
+
     // metrics/health
-    go func(){
-        mux := http.NewServeMux()
-        mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) { w.Write([]byte("ok\n")) })
-        mux.HandleFunc("/readyz", func(w http.ResponseWriter, r *http.Request) { w.Write([]byte("ok\n")) })
-        mux.Handle("/metrics", promhttp.Handler())
-        log.Printf("llm probes/metrics on :8083")
-        _ = http.ListenAndServe(":8083", mux)
-    }()
+    mux := probes.NewMux()
+    mux.Handle("/readyz", ready)
+    probe, err := probes.Start("llm", *probeAddr, mux)
+    if err != nil { log.Fatalf("probes: %v", err) }
 
     l, err := net.Listen("tcp", *addr)
     if err != nil { log.Fatalf("listen: %v", err) }
     log.Printf("llm listening on %s", *addr)
+    go stopOn(s, probe)
     if err := s.Serve(l); err != nil { log.Fatalf("serve: %v", err) }
 }
 
+// stopOn stops the gRPC server and the probes on SIGINT/SIGTERM, and the
+// whole service if the probe server fails.
+func stopOn(s *grpc.Server, probe *probes.Server) {
+    sig := make(chan os.Signal, 1)
+    signal.Notify(sig, syscall.SIGINT, syscall.SIGTERM)
+    select {
+    case <-sig:
+        log.Printf("shutdown signal received, draining...")
+    case err := <-probe.Err():
+        log.Fatalf("probes: %v", err)
+    }
+    done := make(chan struct{})
+    go func(){ s.GracefulStop(); close(done) }()
+    select {
+    case <-done:
+    case <-time.After(5 * time.Second):
+        s.Stop()
+    }
+    ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+    defer cancel()
+    _ = probe.Shutdown(ctx)
+}
+
@@ -1,40 +1,87 @@
 package main
 
 import (
+    "context"
     "flag"
     "log"
     "net"
     "net/http"
+    "os"
+    "os/signal"
+    "syscall"
+    "time"
 
     "google.golang.org/grpc"
 
+    "yuzu/agent/internal/grpcmw"
     orch "yuzu/agent/internal/orchestrator"
     gw "yuzu/agent/internal/orchestrator/pb"
-    "github.com/prometheus/client_golang/prometheus/promhttp"
+    "yuzu/agent/internal/probes"
 )
 
 var (
-    addr = flag.String("addr", ":9090", "orchestrator listen addr")
+    addr      = flag.String("addr", ":9090", "orchestrator listen addr")
+    probeAddr = flag.String("probe-addr", probes.Addr("ORCH_PROBE_ADDR", ":8082"), "health/ready/metrics listen addr")
 )
 
 func main(){
     flag.Parse()
-    s := grpc.NewServer()
+    s := grpc.NewServer(grpcmw.ServerOptions(grpcmw.OptionsFromEnv("orchestrator"))...)
     srv := orch.NewServer()
     gw.RegisterGatewayControlServer(s, srv)
 
     // health endpoints
+    mux := probes.NewMux()
+    mux.HandleFunc("/readyz", func(w http.ResponseWriter, r *http.Request) { w.Write([]byte("ok\n")) })
+    // Sessions whose gateway has gone quiet; ?all=1 lists every session.
+    // Needs ORCH_ADMIN_TOKEN, like /admin/
+    mux.Handle("/livez/sessions", srv.LivenessHandler())
+    // Runtime prompt/flow management; 404 unless ORCH_ADMIN_TOKEN is set
+    mux.Handle("/admin/", srv.AdminHandler())
+    probe, err := probes.Start("orchestrator", *probeAddr, mux)
+    if err != nil { log.Fatalf("probes: %v", err) }
     go func(){
-        mux := http.NewServeMux()
-        mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) { w.Write([]byte("ok\n")) })
-        mux.HandleFunc("/readyz", func(w http.ResponseWriter, r *http.Request) { w.Write([]byte("ok\n")) })
-        mux.Handle("/metrics", promhttp.Handler())
-        log.Printf("orchestrator probes/metrics on :8082")
-        _ = http.ListenAndServe(":8082", mux)
+        if err := <-probe.Err(); err != nil { log.Fatalf("probes: %v", err) }
     }()
 
     l, err := net.Listen("tcp", *addr)
     if err != nil { log.Fatalf("listen: %v", err) }
     log.Printf("orchestrator listening on %s", *addr)
+
+    // SIGHUP re-reads ORCH_THRESHOLDS_FILE
+    hupCh := make(chan os.Signal, 1)
+    signal.Notify(hupCh, syscall.SIGHUP)
+    go func(){
+        for range hupCh {
+            _ = srv.ReloadThresholds("SIGHUP")
+        }
+    }()
+
+    // Close sessions whose gateway went silent, until shutdown
+    watchCtx, stopWatch := context.WithCancel(context.Background())
+    go srv.WatchLiveness(watchCtx, 5*time.Second)
+    // Reload ORCH_THRESHOLDS_FILE when it changes, if ORCH_THRESHOLDS_WATCH_MS is set
+    go srv.WatchThresholds(watchCtx)
+
+    // Stop every open session's speech and mic before the streams go away
+    stopCh := make(chan os.Signal, 1)
+    signal.Notify(stopCh, syscall.SIGINT, syscall.SIGTERM)
+    go func(){
+        <-stopCh
+        log.Printf("shutdown signal received, stopping sessions...")
+        stopWatch()
+        srv.CloseAll("shutdown")
+        // Gateway streams are long-lived; don't wait on them for long
+        done := make(chan struct{})
+        go func(){ s.GracefulStop(); close(done) }()
+        select {
+        case <-done:
+        case <-time.After(5 * time.Second):
+            s.Stop()
+        }
+        ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+        defer cancel()
+        _ = probe.Shutdown(ctx)
+    }()
     if err := s.Serve(l); err != nil { log.Fatalf("serve: %v", err) }
 }
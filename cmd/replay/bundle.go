@@ -0,0 +1,203 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	pb "yuzu/agent/internal/orchestrator/pb"
+	"yuzu/agent/pkg/client"
+)
+
+// A bundle is a session's stored events, in the shape GET
+// /sessions/{id}/events returns, so `curl .../events > bundle.json` and
+// `replay -save bundle.json` produce the same file.
+type bundle struct {
+	SessionID string         `json:"session_id"`
+	Events    []client.Event `json:"events"`
+}
+
+func loadBundle(path string) (*bundle, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var bd bundle
+	if err := json.Unmarshal(b, &bd); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if len(bd.Events) == 0 {
+		return nil, fmt.Errorf("%s: no events", path)
+	}
+	return &bd, nil
+}
+
+func fetchBundle(ctx context.Context, api, apiKey, sessionID string) (*bundle, error) {
+	events, err := client.New(api, client.WithAPIKey(apiKey)).Events(ctx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	if len(events) == 0 {
+		return nil, fmt.Errorf("session %s has no events", sessionID)
+	}
+	return &bundle{SessionID: sessionID, Events: events}, nil
+}
+
+func (bd *bundle) save(path string) error {
+	b, err := json.MarshalIndent(bd, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(b, '\n'), 0o644)
+}
+
+// step is one gateway event to send, at offset at from the session open.
+type step struct {
+	at  time.Duration
+	typ string // stored event type
+	ev  client.Event
+}
+
+// plan picks the events the gateway sent to the orchestrator, timed from
+// the worker's hello (or the first of them), and the style the session
+// was created with. Features aren't stored, so barge-ins only replay when
+// the orchestrator takes VAD from the gateway (ORCH_VAD_SOURCE=gateway).
+func (bd *bundle) plan() (style *pb.SessionStyle, steps []step) {
+	style = &pb.SessionStyle{}
+	var t0 time.Time
+	for _, e := range bd.Events {
+		switch e.Type {
+		case "session_created":
+			style.Persona, style.Verbosity = str(e.Payload, "persona"), str(e.Payload, "verbosity")
+		case "worker_hello":
+			if t0.IsZero() {
+				t0 = e.Timestamp
+			}
+		case "vad_start", "transcript_final", "tts_started", "tts_first_audio", "tts_stopped":
+			if t0.IsZero() {
+				t0 = e.Timestamp
+			}
+			at := e.Timestamp.Sub(t0)
+			if at < 0 {
+				at = 0
+			}
+			steps = append(steps, step{at: at, typ: e.Type, ev: e})
+		}
+	}
+	return style, steps
+}
+
+// An outcome is one orchestrator decision the replay checks.
+type outcome struct {
+	kind string
+	desc string
+}
+
+func (o outcome) String() string {
+	if o.kind == "agent_text" {
+		return fmt.Sprintf("agent_text %q", o.desc)
+	}
+	return o.kind + " " + o.desc
+}
+
+// expected lists the outcomes of the original run in kinds, in order.
+func (bd *bundle) expected(kinds map[string]bool) []outcome {
+	var out []outcome
+	for _, e := range bd.Events {
+		if o, ok := outcomeFromEvent(e); ok && kinds[o.kind] {
+			out = append(out, o)
+		}
+	}
+	return mergeText(out)
+}
+
+// outcomeFromEvent and outcomeFromCommand describe the same orchestrator
+// decision identically, from the event the gateway logged and from the
+// command the replayed orchestrator sent. The session's own close is left
+// out: the replay ends it with SessionClose whenever the original ended.
+func outcomeFromEvent(e client.Event) (outcome, bool) {
+	p := e.Payload
+	switch e.Type {
+	case "turn_state":
+		if str(p, "trigger") == "close" {
+			return outcome{}, false
+		}
+		return turnStateOutcome(str(p, "from"), str(p, "to"), str(p, "trigger"), p["rejected"] == true), true
+	case "llm_status":
+		return outcome{"llm_status", fmt.Sprintf("degraded=%t reason=%s", p["degraded"] == true, str(p, "reason"))}, true
+	case "moderation_flagged":
+		return outcome{"moderation_flagged", "action=" + str(p, "action")}, true
+	case "agent_text":
+		return outcome{"agent_text", str(p, "text")}, true
+	}
+	return outcome{}, false
+}
+
+// outcomeFromCommand takes an unbatched command.
+func outcomeFromCommand(cmd *pb.OrchestratorCommand) []outcome {
+	switch c := cmd.Cmd.(type) {
+	case *pb.OrchestratorCommand_TurnState:
+		ts := c.TurnState
+		if ts.GetTrigger() == "close" {
+			return nil
+		}
+		return []outcome{turnStateOutcome(ts.GetFromState(), ts.GetToState(), ts.GetTrigger(), ts.GetRejected())}
+	case *pb.OrchestratorCommand_LlmStatus:
+		return []outcome{{"llm_status", fmt.Sprintf("degraded=%t reason=%s", c.LlmStatus.GetDegraded(), c.LlmStatus.GetReason())}}
+	case *pb.OrchestratorCommand_ModerationFlag:
+		return []outcome{{"moderation_flagged", "action=" + c.ModerationFlag.GetAction()}}
+	case *pb.OrchestratorCommand_StartTts:
+		return []outcome{{"agent_text", c.StartTts.GetText()}}
+	}
+	return nil
+}
+
+func turnStateOutcome(from, to, trigger string, rejected bool) outcome {
+	d := fmt.Sprintf("%s->%s on %s", from, to, trigger)
+	if rejected {
+		d += " (rejected)"
+	}
+	return outcome{"turn_state", d}
+}
+
+// mergeText joins agent text that follows other agent text: the gateway
+// logs sentences as it flushes them to TTS, several at a time, while the
+// orchestrator sends them one by one.
+func mergeText(in []outcome) []outcome {
+	var out []outcome
+	for _, o := range in {
+		if n := len(out); n > 0 && o.kind == "agent_text" && out[n-1].kind == "agent_text" {
+			out[n-1].desc = strings.TrimSpace(out[n-1].desc + " " + o.desc)
+			continue
+		}
+		out = append(out, o)
+	}
+	return out
+}
+
+func parseKinds(v string) (map[string]bool, error) {
+	kinds := map[string]bool{}
+	for _, k := range strings.Split(v, ",") {
+		switch k = strings.TrimSpace(k); k {
+		case "turn_state", "llm_status", "moderation_flagged", "agent_text":
+			kinds[k] = true
+		case "":
+		default:
+			return nil, fmt.Errorf("unknown kind %q: want turn_state, llm_status, moderation_flagged or agent_text", k)
+		}
+	}
+	return kinds, nil
+}
+
+func str(p map[string]any, k string) string {
+	s, _ := p[k].(string)
+	return s
+}
+
+func num(p map[string]any, k string) int64 {
+	f, _ := p[k].(float64)
+	return int64(f)
+}
@@ -0,0 +1,315 @@
+// Command replay re-runs a recorded session against a local orchestrator
+// and checks that it makes the same decisions. It sends the gateway events
+// from the session's event log (or a saved bundle) with their timing
+// compressed by -speed, collects the commands that come back, and compares
+// the turn-state transitions, LLM status changes and moderation actions
+// (and, with -compare, the agent's text) against the ones logged in the
+// original run. It exits 1 when they differ.
+//
+//	go run ./cmd/replay -session sess_123 -save sess_123.json
+//	go run ./cmd/replay -bundle sess_123.json -speed 20
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+
+	"yuzu/agent/internal/auth"
+	pb "yuzu/agent/internal/orchestrator/pb"
+)
+
+func main() {
+	orchAddr := flag.String("orch", ":9090", "Orchestrator gRPC address")
+	api := flag.String("server", envOr("YUZU_SERVER", "http://localhost:8080"), "API server to fetch -session from")
+	apiKey := flag.String("api-key", os.Getenv("YUZU_API_KEY"), "Tenant API key")
+	sessionID := flag.String("session", "", "Recorded session to fetch from the API server")
+	bundlePath := flag.String("bundle", "", "Saved events to replay instead of fetching (GET /sessions/{id}/events output)")
+	savePath := flag.String("save", "", "Write the fetched events here as a bundle")
+	as := flag.String("as", "", "Session ID for the replay (default <session>-replay-<time>)")
+	speed := flag.Float64("speed", 10, "Replay this many times faster than recorded")
+	settle := flag.Duration("settle", 3*time.Second, "Wait after the last event for replies before closing")
+	compare := flag.String("compare", "turn_state,llm_status,moderation_flagged", "Outcomes to check: turn_state, llm_status, moderation_flagged, agent_text")
+	token := flag.String("token", "", "Gateway token (default: minted from WORKER_TOKEN_SECRET)")
+	verbose := flag.Bool("v", false, "Print every event sent and command received")
+	flag.Parse()
+
+	kinds, err := parseKinds(*compare)
+	if err != nil {
+		log.Fatalf("-compare: %v", err)
+	}
+	if *speed <= 0 {
+		log.Fatalf("-speed must be positive")
+	}
+
+	var bd *bundle
+	switch {
+	case *bundlePath != "":
+		bd, err = loadBundle(*bundlePath)
+	case *sessionID != "":
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		bd, err = fetchBundle(ctx, *api, *apiKey, *sessionID)
+		cancel()
+	default:
+		log.Fatalf("need -session or -bundle")
+	}
+	if err != nil {
+		log.Fatalf("load events: %v", err)
+	}
+	if *savePath != "" {
+		if err := bd.save(*savePath); err != nil {
+			log.Fatalf("save bundle: %v", err)
+		}
+		fmt.Printf("Saved %d events to %s\n", len(bd.Events), *savePath)
+	}
+
+	style, steps := bd.plan()
+	if len(steps) == 0 {
+		log.Fatalf("session %s has no gateway events to replay", bd.SessionID)
+	}
+	sid := *as
+	if sid == "" {
+		sid = bd.SessionID + "-replay-" + time.Now().Format("150405")
+	}
+	if *token == "" {
+		if secret := os.Getenv("WORKER_TOKEN_SECRET"); secret != "" {
+			*token = auth.MustToken(secret, sid, time.Now().Add(time.Hour).Unix())
+		}
+	}
+
+	run := steps[len(steps)-1].at
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(float64(run)/(*speed))+*settle+30*time.Second)
+	defer cancel()
+	conn, err := grpc.DialContext(ctx, *orchAddr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		log.Fatalf("dial orchestrator: %v", err)
+	}
+	defer conn.Close()
+	sctx := ctx
+	if *token != "" {
+		sctx = metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+*token)
+	}
+	stream, err := pb.NewGatewayControlClient(conn).Session(sctx)
+	if err != nil {
+		log.Fatalf("open session: %v", err)
+	}
+
+	fmt.Printf("=== Replay ===\nSession: %s as %s\nEvents: %d over %s, at %gx\n\n", bd.SessionID, sid, len(steps), run.Round(time.Millisecond), *speed)
+	r := &replayer{sid: sid, verbose: *verbose, closed: make(chan struct{})}
+	go r.recv(stream)
+
+	if err := r.send(stream, &pb.GatewayEvent{SessionId: sid, Evt: &pb.GatewayEvent_SessionOpen{SessionOpen: &pb.SessionOpen{
+		SessionId: sid, RoomUrl: "replay://" + bd.SessionID, Style: style,
+	}}}); err != nil {
+		log.Fatalf("send session_open: %v", err)
+	}
+	began := time.Now()
+	for i, st := range steps {
+		if d := time.Until(began.Add(time.Duration(float64(st.at) / *speed))); d > 0 {
+			time.Sleep(d)
+		}
+		// Playback can't start before the reply it plays; whatever the
+		// orchestrator took longer than the original run pushes the rest back
+		if st.typ == "tts_started" {
+			waited := time.Now()
+			if !r.waitReply(*settle) {
+				log.Printf("event %d: no StartTTS within %s; sending tts_started anyway", i, *settle)
+			}
+			began = began.Add(time.Since(waited))
+		}
+		if err := r.send(stream, r.event(st)); err != nil {
+			log.Fatalf("send event %d (%s): %v", i, st.typ, err)
+		}
+	}
+	time.Sleep(*settle)
+
+	// Outcomes of the close itself aren't compared
+	r.mu.Lock()
+	got := mergeText(filterKinds(r.got, kinds))
+	r.mu.Unlock()
+	if err := r.send(stream, &pb.GatewayEvent{SessionId: sid, Evt: &pb.GatewayEvent_SessionClose{SessionClose: &pb.SessionClose{Reason: "replay"}}}); err != nil {
+		log.Printf("send session_close: %v", err)
+	}
+	select {
+	case <-r.closed:
+	case <-time.After(5 * time.Second):
+		log.Printf("no session_closed ack from the orchestrator")
+	}
+	stream.CloseSend()
+
+	if !report(os.Stdout, bd.expected(kinds), got) {
+		os.Exit(1)
+	}
+}
+
+// replayer tracks the IDs the orchestrator issued, so replayed transcripts
+// and TTS events echo them, and collects the outcomes of its commands.
+type replayer struct {
+	sid     string
+	verbose bool
+	closed  chan struct{}
+
+	mu        sync.Mutex
+	user      string
+	turn      string
+	agent     string
+	agentTurn string
+	replies   int // StartTTS received
+	started   int // tts_started sent
+	got       []outcome
+}
+
+// waitReply waits up to d for a StartTTS that no tts_started has answered.
+func (r *replayer) waitReply(d time.Duration) bool {
+	deadline := time.Now().Add(d)
+	for {
+		r.mu.Lock()
+		ok := r.replies > r.started
+		if ok || time.Now().After(deadline) {
+			r.started = r.replies
+			r.mu.Unlock()
+			return ok
+		}
+		r.mu.Unlock()
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func (r *replayer) recv(stream pb.GatewayControl_SessionClient) {
+	for {
+		cmd, err := stream.Recv()
+		if err != nil {
+			if err != io.EOF {
+				log.Printf("recv: %v", err)
+			}
+			return
+		}
+		if r.verbose {
+			fmt.Printf("[%s] <- %v\n", time.Now().Format("15:04:05.000"), cmd.Cmd)
+		}
+		for _, c := range flatten(cmd) {
+			r.observe(c)
+		}
+		if cmd.GetAck().GetInfo() == "session_closed" {
+			close(r.closed)
+			return
+		}
+	}
+}
+
+func (r *replayer) observe(cmd *pb.OrchestratorCommand) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	switch c := cmd.Cmd.(type) {
+	case *pb.OrchestratorCommand_BeginListening:
+		if m := c.BeginListening.GetMic(); m != nil {
+			r.user, r.turn = m.GetUtteranceId(), m.GetTurnId()
+		}
+	case *pb.OrchestratorCommand_StartMicToStt:
+		r.user, r.turn = c.StartMicToStt.GetUtteranceId(), c.StartMicToStt.GetTurnId()
+	case *pb.OrchestratorCommand_StartTts:
+		r.agent, r.agentTurn = c.StartTts.GetUtteranceId(), c.StartTts.GetTurnId()
+		r.replies++
+	}
+	r.got = append(r.got, outcomeFromCommand(cmd)...)
+}
+
+// event builds the GatewayEvent for a recorded step under the IDs the
+// replayed orchestrator issued.
+func (r *replayer) event(st step) *pb.GatewayEvent {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	p := st.ev.Payload
+	out := &pb.GatewayEvent{SessionId: r.sid}
+	switch st.typ {
+	case "vad_start":
+		out.Evt = &pb.GatewayEvent_VadStart{VadStart: &pb.VADStart{TsMs: uint64(time.Now().UnixMilli())}}
+	case "transcript_final":
+		out.Evt = &pb.GatewayEvent_TranscriptFinal{TranscriptFinal: &pb.TranscriptFinal{
+			Text: str(p, "text"), TurnId: r.turn, UtteranceId: r.user, Speaker: uint32(num(p, "speaker")), Source: str(p, "source"),
+		}}
+	case "tts_started":
+		out.Evt = &pb.GatewayEvent_Tts{Tts: &pb.TTSEvent{Type: "started", TurnId: r.agentTurn, UtteranceId: r.agent}}
+	case "tts_first_audio":
+		out.Evt = &pb.GatewayEvent_Tts{Tts: &pb.TTSEvent{Type: "first_audio", TurnId: r.agentTurn, UtteranceId: r.agent, FirstAudioMs: uint32(num(p, "first_audio_ms"))}}
+	case "tts_stopped":
+		out.Evt = &pb.GatewayEvent_Tts{Tts: &pb.TTSEvent{Type: "stopped", TurnId: r.agentTurn, UtteranceId: r.agent, Reason: str(p, "reason")}}
+	}
+	return out
+}
+
+// flatten unpacks batched commands.
+func flatten(cmd *pb.OrchestratorCommand) []*pb.OrchestratorCommand {
+	b := cmd.GetBatch()
+	if b == nil {
+		return []*pb.OrchestratorCommand{cmd}
+	}
+	var out []*pb.OrchestratorCommand
+	for _, inner := range b.GetCommands() {
+		out = append(out, flatten(inner)...)
+	}
+	return out
+}
+
+func (r *replayer) send(stream pb.GatewayControl_SessionClient, ev *pb.GatewayEvent) error {
+	if r.verbose {
+		fmt.Printf("[%s] -> %v\n", time.Now().Format("15:04:05.000"), ev.Evt)
+	}
+	return stream.Send(ev)
+}
+
+func filterKinds(in []outcome, kinds map[string]bool) []outcome {
+	var out []outcome
+	for _, o := range in {
+		if kinds[o.kind] {
+			out = append(out, o)
+		}
+	}
+	return out
+}
+
+// report prints where the replay diverged from the original, if it did,
+// and says whether they matched.
+func report(w io.Writer, want, got []outcome) bool {
+	i := 0
+	for i < len(want) && i < len(got) && want[i] == got[i] {
+		i++
+	}
+	if i == len(want) && i == len(got) {
+		fmt.Fprintf(w, "MATCH: %d outcomes\n", len(want))
+		return true
+	}
+	fmt.Fprintf(w, "MISMATCH at outcome %d (original %d, replay %d)\n", i+1, len(want), len(got))
+	from := max(0, i-2)
+	for j := from; j < i+5 && (j < len(want) || j < len(got)); j++ {
+		mark := " "
+		if j >= i {
+			mark = "!"
+		}
+		fmt.Fprintf(w, "%s %3d  original: %-50s replay: %s\n", mark, j+1, at(want, j), at(got, j))
+	}
+	return false
+}
+
+func at(list []outcome, i int) string {
+	if i >= len(list) {
+		return "-"
+	}
+	return list[i].String()
+}
+
+func envOr(key, def string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return def
+}
@@ -3,23 +3,25 @@ package main
 import (
     "context"
     "encoding/json"
-    "errors"
     "log"
     "net/http"
     "os"
-    "os/exec"
     "os/signal"
     "syscall"
     "time"
 
     "github.com/joho/godotenv"
+    "github.com/prometheus/client_golang/prometheus/promhttp"
     "yuzu/agent/internal/api"
     "yuzu/agent/internal/bot"
+    "yuzu/agent/internal/bus"
     "yuzu/agent/internal/config"
     "yuzu/agent/internal/daily"
+    "yuzu/agent/internal/floor"
     "yuzu/agent/internal/health"
     "yuzu/agent/internal/loop"
     "yuzu/agent/internal/store"
+    "yuzu/agent/internal/tenant"
     "yuzu/agent/internal/workerws"
 )
 
@@ -28,6 +30,9 @@ func main() {
 	_ = godotenv.Load()
 
 	cfg := config.Load()
+	if err := cfg.Validate(); err != nil {
+		log.Fatalf("config: %v", err)
+	}
 
 	// Run startup health checks
 	log.Println("running startup health checks...")
@@ -39,7 +44,35 @@ func main() {
 		log.Println("WARNING: some health checks failed, continuing anyway...")
 	}
 
-	st := store.New()
+	mem := store.NewWithCap(cfg.Store.MaxSessions)
+	// Events over their class's retention limit spill to disk when set
+	var spill store.Persister
+	if cfg.Store.SpillPath != "" {
+		p, err := store.NewFilePersister(cfg.Store.SpillPath)
+		if err != nil {
+			log.Fatalf("store: %v", err)
+		}
+		spill = p
+		log.Printf("store: spilling truncated events to %s", cfg.Store.SpillPath)
+	}
+	mem.SetRetention(store.ParseRetention(cfg.Store.EventRetention), spill)
+	var st store.Store = mem
+	// Events and network stats also go to disk, off the request path
+	var persisted *store.WriteBehind
+	if cfg.Store.PersistPath != "" {
+		p, err := store.NewFilePersister(cfg.Store.PersistPath)
+		if err != nil {
+			log.Fatalf("store: %v", err)
+		}
+		persisted = store.NewWriteBehind(st, p, store.WriteBehindConfig{
+			MaxBatch:   cfg.Store.FlushBatch,
+			MaxQueue:   cfg.Store.QueueMax,
+			FlushEvery: time.Duration(cfg.Store.FlushMs) * time.Millisecond,
+			SyncEvery:  time.Duration(cfg.Store.FsyncMs) * time.Millisecond,
+		})
+		st = persisted
+		log.Printf("store: persisting events to %s", cfg.Store.PersistPath)
+	}
 	dailyClient := daily.NewClient(cfg.Daily.APIKey, daily.AudioConfig{
 		EnableMusicMode:     cfg.Daily.EnableMusicMode,
 		AudioBitrate:        cfg.Daily.AudioBitrate,
@@ -49,21 +82,20 @@ func main() {
 		EnableNoiseCancelUI: cfg.Daily.EnableNoiseCancelUI,
 	})
 
-	runner := bot.NewLocalRunner(cfg.Bot.WorkerCmd, func(sessionID string, err error) {
-		// On process exit, mark not running and append event.
-		st.SetBotRunning(sessionID, false)
-		code := exitCodeFromErr(err)
-		st.SetBotExit(sessionID, code, time.Now().UTC())
-		st.AppendEvent(sessionID, "bot_exit", map[string]any{
-			"error": errString(err),
-		})
-	}, func(sessionID, stream, line string) {
-		st.AppendEvent(sessionID, "bot_log", map[string]any{"stream": stream, "line": line})
-	}, func(sessionID string, pid int) {
-		st.SetBotPID(sessionID, pid)
-	})
+	// Modules talk through the bus (see package bus)
+	events := bus.New()
+	onExit, onLog, onStart := bot.Publishers(events)
+	runner := bot.NewLocalRunner(cfg.Bot.WorkerCmd, onExit, onLog, onStart)
 
 	h := api.NewHandlers(cfg, st, dailyClient, runner)
+	tenants, err := tenant.Load(cfg.Tenants.File)
+	if err != nil {
+		log.Fatalf("tenants: %v", err)
+	}
+	if tenants.MultiTenant() {
+		log.Printf("multi-tenant mode: tenants from %s", cfg.Tenants.File)
+	}
+	h.SetTenants(tenants)
 	mux := http.NewServeMux()
 	mux.Handle("/", api.NewRouter(h))
     // WS worker route
@@ -71,10 +103,16 @@ func main() {
     wss := workerws.NewServer(cfg, st, reg)
     // Dispatcher for Loop A floor control
     disp := loop.New(reg, st, cfg.Floor.TTSTimeoutSeconds)
-    wss.OnMessage = disp.OnMessage
+    disp.SetPolicy(floor.Policy{Interject: cfg.Floor.InterjectionMode, MaxInterjectMs: int64(cfg.Floor.MaxInterjectionMs)})
+    wss.Bus = events
+    // The dispatcher handles each worker message before the handlers do
+    disp.Subscribe(events)
+    h.SetBus(events)
+    // Re-prompts for candidates who go quiet (see floor.Strategy)
+    go disp.Run(context.Background(), 250*time.Millisecond)
     mux.HandleFunc("/ws/worker", wss.HandleWorkerWS)
-    // Wire dispatcher to REST debug endpoints
-    h.SetOnWorkerMessage(disp.OnMessage)
+
+    mux.Handle("/metrics", promhttp.Handler())
 
     // Health endpoint
     mux.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
@@ -87,6 +125,8 @@ func main() {
         }
         json.NewEncoder(w).Encode(status)
     })
+    // On-demand provider and service checks for deployment pipelines
+    mux.HandleFunc("/healthz/providers", health.ProvidersHandler(cfg))
 
 	addr := ":" + cfg.Server.Port
 	srv := &http.Server{
@@ -97,10 +137,16 @@ func main() {
 
 	// Graceful shutdown on SIGINT/SIGTERM
 	sigc := make(chan os.Signal, 1)
+	drained := make(chan struct{})
     signal.Notify(sigc, os.Interrupt, syscall.SIGTERM)
     go func() {
+        defer close(drained)
         <-sigc
         log.Printf("shutdown signal received; stopping server...")
+        // Tell workers first, so they reconnect elsewhere while bots stop
+        drainCtx, drainCancel := context.WithTimeout(context.Background(), 5*time.Second)
+        wss.Drain(drainCtx)
+        drainCancel()
         // Stop running bots before draining HTTP
         for _, id := range st.ListSessionIDs() {
             if runner.IsRunning(id) {
@@ -119,24 +165,25 @@ func main() {
 	}
 
     // Bots already stopped in signal handler above
-}
-
-func errString(err error) string {
-	if err == nil {
-		return ""
-	}
-	return err.Error()
-}
-
-func exitCodeFromErr(err error) int {
-	if err == nil {
-		return 0
+	<-drained
+	if persisted != nil {
+		// Write what is still buffered once requests have drained
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		if err := persisted.Close(ctx); err != nil {
+			log.Printf("store: flushing on shutdown: %v", err)
+		}
 	}
-	var ee *exec.ExitError
-	if errors.As(err, &ee) {
-		return ee.ExitCode()
+	if spill != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := mem.StopSpill(ctx); err != nil {
+			log.Printf("store: flushing the spill on shutdown: %v", err)
+		}
+		if err := spill.Close(); err != nil {
+			log.Printf("store: closing spill file: %v", err)
+		}
 	}
-	return 1
 }
 
 func logMiddleware(next http.Handler) http.Handler {
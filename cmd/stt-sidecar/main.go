@@ -3,6 +3,8 @@ package main
 import (
     "context"
     "flag"
+    "fmt"
+    "io"
     "log"
     "net"
     "net/http"
@@ -14,8 +16,8 @@ import (
     "google.golang.org/grpc"
     "google.golang.org/grpc/keepalive"
 
-    "github.com/prometheus/client_golang/prometheus/promhttp"
-
+    "yuzu/agent/internal/grpcmw"
+    "yuzu/agent/internal/probes"
     pb "yuzu/agent/internal/stt/pb"
     sttsrv "yuzu/agent/internal/stt"
 )
@@ -23,11 +25,25 @@ import (
 // UDS default location; override with --uds or STT_UDS_PATH
 var (
     udsPath   = flag.String("uds", "", "unix domain socket path (default /run/app/stt.sock)")
-    httpProbe = flag.String("http", ":8081", "http addr for health/ready probes")
+    httpProbe = flag.String("http", probes.Addr("STT_PROBE_ADDR", ":8081"), "http addr for health/ready probes")
+
+    // --bench runs the self-benchmark against a mock provider and exits
+    bench         = flag.Bool("bench", false, "run N synthetic sessions against a mock provider, print a report and exit")
+    benchSessions = flag.Int("bench-sessions", 10, "bench: concurrent sessions")
+    benchDuration = flag.Duration("bench-duration", 30*time.Second, "bench: run time")
+    benchSource   = flag.String("bench-source", "tone", "bench: tone, noise, or a PCM16 16kHz mono file (raw or WAV)")
+    benchSpeed    = flag.Float64("bench-speed", 1, "bench: audio seconds per second per session")
+    benchRamp     = flag.Duration("bench-ramp", time.Second, "bench: spread session starts over this long")
+    benchJSON     = flag.Bool("bench-json", false, "bench: print the report as JSON")
+    benchVerbose  = flag.Bool("bench-verbose", false, "bench: keep the sidecar's logs")
 )
 
 func main() {
     flag.Parse()
+    if *bench {
+        runBench()
+        return
+    }
     path := *udsPath
     if path == "" {
         path = os.Getenv("STT_UDS_PATH")
@@ -60,26 +76,28 @@ func main() {
         PermitWithoutStream: true,
     }
 
-    s := grpc.NewServer(grpc.KeepaliveParams(kap), grpc.KeepaliveEnforcementPolicy(kasp))
+    opts := []grpc.ServerOption{grpc.KeepaliveParams(kap), grpc.KeepaliveEnforcementPolicy(kasp)}
+    opts = append(opts, grpcmw.ServerOptions(grpcmw.OptionsFromEnv("stt"))...)
+    s := grpc.NewServer(opts...)
     srv := sttsrv.NewSTTServer()
     pb.RegisterSTTServer(s, srv)
 
     // Health/ready probes
-    go func() {
-        mux := http.NewServeMux()
-        mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) { w.Write([]byte("ok\n")) })
-        mux.HandleFunc("/readyz", func(w http.ResponseWriter, r *http.Request) {
-            if srv.Ready() {
-                w.Write([]byte("ok\n"))
-                return
-            }
-            w.WriteHeader(503)
-            w.Write([]byte("not ready\n"))
-        })
-        mux.Handle("/metrics", promhttp.Handler())
-        log.Printf("probes/metrics on %s", *httpProbe)
-        _ = http.ListenAndServe(*httpProbe, mux)
-    }()
+    mux := probes.NewMux()
+    mux.HandleFunc("/readyz", func(w http.ResponseWriter, r *http.Request) {
+        if srv.Ready() {
+            w.Write([]byte("ok\n"))
+            return
+        }
+        w.WriteHeader(503)
+        w.Write([]byte("not ready\n"))
+    })
+    // Recent provider frames per session; 404 unless STT_ADMIN_TOKEN is set
+    mux.Handle("/admin/", srv.AdminHandler())
+    probe, err := probes.Start("stt", *httpProbe, mux)
+    if err != nil {
+        log.Fatalf("probes: %v", err)
+    }
 
     log.Printf("STT sidecar listening on UDS %s", path)
 
@@ -87,12 +105,17 @@ func main() {
     stopCh := make(chan os.Signal, 1)
     signal.Notify(stopCh, syscall.SIGINT, syscall.SIGTERM)
     go func() {
-        <-stopCh
+        select {
+        case <-stopCh:
+        case err := <-probe.Err():
+            log.Fatalf("probes: %v", err)
+        }
         log.Printf("shutdown signal received, draining...")
         ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
         defer cancel()
         _ = srv.GracefulShutdown(ctx, 5*time.Second)
         s.GracefulStop()
+        _ = probe.Shutdown(ctx)
     }()
 
     if err := s.Serve(l); err != nil {
@@ -107,3 +130,27 @@ func dir(p string) string {
     return p[:i]
 }
 
+
+func runBench() {
+    if !*benchVerbose {
+        log.SetOutput(io.Discard)
+    }
+    ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+    defer stop()
+    rep, err := sttsrv.RunBench(ctx, sttsrv.BenchConfig{
+        Sessions: *benchSessions,
+        Duration: *benchDuration,
+        Source:   *benchSource,
+        Speed:    *benchSpeed,
+        Ramp:     *benchRamp,
+    })
+    if err != nil {
+        fmt.Fprintln(os.Stderr, "bench:", err)
+        os.Exit(1)
+    }
+    if *benchJSON {
+        fmt.Println(rep.JSON())
+    } else {
+        fmt.Print(rep)
+    }
+}
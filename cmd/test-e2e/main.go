@@ -7,12 +7,16 @@ import (
 	"io"
 	"log"
 	"os"
+	"os/signal"
+	"syscall"
 	"time"
 
+	"yuzu/agent/internal/auth"
 	pb "yuzu/agent/internal/orchestrator/pb"
 
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
 )
 
 func main() {
@@ -20,9 +24,34 @@ func main() {
 	sessionID := flag.String("session", "test-e2e-"+time.Now().Format("150405"), "Session ID")
 	text := flag.String("text", "Hello, how are you today?", "Text to send as transcript")
 	timeout := flag.Duration("timeout", 30*time.Second, "Timeout for receiving responses")
+	token := flag.String("token", "", "Gateway token (default: minted from WORKER_TOKEN_SECRET)")
+	scriptPath := flag.String("script", "", "YAML scenario of timed events to replay instead of the built-in one (see script.go)")
 	flag.Parse()
 
-	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	var sc *script
+	if *scriptPath != "" {
+		var err error
+		if sc, err = loadScript(*scriptPath); err != nil {
+			log.Fatalf("script: %v", err)
+		}
+		if sc.Session != "" {
+			*sessionID = sc.Session
+		}
+		// The timeout covers the wait after the last event
+		*timeout += sc.Events[len(sc.Events)-1].At
+	}
+
+	// The orchestrator authenticates SessionOpen with the per-session worker token
+	if *token == "" {
+		if secret := os.Getenv("WORKER_TOKEN_SECRET"); secret != "" {
+			*token = auth.MustToken(secret, *sessionID, time.Now().Add(time.Hour).Unix())
+		}
+	}
+
+	// Ctrl+C (or SIGTERM) ends the run early and closes the stream cleanly
+	sigCtx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	ctx, cancel := context.WithTimeout(sigCtx, *timeout)
 	defer cancel()
 
 	// Connect to Orchestrator
@@ -33,12 +62,17 @@ func main() {
 	defer conn.Close()
 
 	client := pb.NewGatewayControlClient(conn)
-	stream, err := client.Session(ctx)
+	sctx := ctx
+	if *token != "" {
+		sctx = metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+*token)
+	}
+	stream, err := client.Session(sctx)
 	if err != nil {
 		log.Fatalf("open session: %v", err)
 	}
 
 	// Start receiver goroutine
+	ids := &scriptIDs{}
 	done := make(chan struct{})
 	go func() {
 		defer close(done)
@@ -55,10 +89,21 @@ func main() {
 				fmt.Printf("\n[stream] recv error: %v\n", err)
 				return
 			}
+			ids.observe(cmd)
 			printCommand(cmd)
 		}
 	}()
 
+	if sc != nil {
+		fmt.Printf("=== E2E Scripted Test ===\n")
+		fmt.Printf("Session: %s\nScript: %s (%d events)\n\n", *sessionID, *scriptPath, len(sc.Events))
+		if err := sc.run(ctx, stream, *sessionID, ids); err != nil && ctx.Err() == nil {
+			log.Fatalf("script: %v", err)
+		}
+		wait(ctx, sigCtx, done, stream)
+		return
+	}
+
 	fmt.Printf("=== E2E Internal Test ===\n")
 	fmt.Printf("Session: %s\n", *sessionID)
 	fmt.Printf("Text: %q\n\n", *text)
@@ -121,19 +166,26 @@ func main() {
 	}
 
 	fmt.Println("\n[*] Waiting for OrchestratorCommands (StartTTS expected)...")
-	fmt.Println("    Press Ctrl+C to exit or wait for timeout\n")
+	fmt.Println("    Press Ctrl+C to exit or wait for timeout")
+	fmt.Println()
+
+	wait(ctx, sigCtx, done, stream)
+}
 
-	// Wait for responses or timeout
+// wait blocks until the stream closes, the timeout passes or the user
+// interrupts, then half-closes the stream.
+func wait(ctx, sigCtx context.Context, done <-chan struct{}, stream pb.GatewayControl_SessionClient) {
+	defer stream.CloseSend()
 	select {
 	case <-done:
 		fmt.Println("[*] Stream closed")
 	case <-ctx.Done():
-		fmt.Println("[*] Timeout reached")
-	case <-waitForSignal():
-		fmt.Println("[*] Interrupted")
+		if sigCtx.Err() != nil {
+			fmt.Println("[*] Interrupted")
+		} else {
+			fmt.Println("[*] Timeout reached")
+		}
 	}
-
-	os.Exit(0)
 }
 
 func printCommand(cmd *pb.OrchestratorCommand) {
@@ -147,6 +199,14 @@ func printCommand(cmd *pb.OrchestratorCommand) {
 		fmt.Printf("[%s] <- StartMicToSTT\n", ts)
 	case *pb.OrchestratorCommand_StopMicToStt:
 		fmt.Printf("[%s] <- StopMicToSTT\n", ts)
+	case *pb.OrchestratorCommand_StopAll:
+		fmt.Printf("[%s] <- StopAll: reason=%s\n", ts, c.StopAll.GetReason())
+	case *pb.OrchestratorCommand_Batch:
+		for _, inner := range c.Batch.GetCommands() {
+			printCommand(inner)
+		}
+	case *pb.OrchestratorCommand_TurnState:
+		fmt.Printf("[%s] <- TurnState: %s -> %s on %s rejected=%t\n", ts, c.TurnState.GetFromState(), c.TurnState.GetToState(), c.TurnState.GetTrigger(), c.TurnState.GetRejected())
 	case *pb.OrchestratorCommand_ArmBargeIn:
 		fmt.Printf("[%s] <- ArmBargeIn: guard_ms=%d min_rms=%d\n", ts, c.ArmBargeIn.GetGuardMs(), c.ArmBargeIn.GetMinRms())
 	case *pb.OrchestratorCommand_Ack:
@@ -155,12 +215,3 @@ func printCommand(cmd *pb.OrchestratorCommand) {
 		fmt.Printf("[%s] <- Unknown command: %T\n", ts, c)
 	}
 }
-
-func waitForSignal() chan struct{} {
-	ch := make(chan struct{})
-	go func() {
-		// Simple blocking - let context timeout handle it
-		select {}
-	}()
-	return ch
-}
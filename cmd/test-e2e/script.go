@@ -0,0 +1,159 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	pb "yuzu/agent/internal/orchestrator/pb"
+)
+
+// A script replays a regression scenario against the orchestrator:
+//
+//	session: barge-in-1          # optional, overrides -session
+//	events:
+//	  - {at: 0s, type: session_open}
+//	  - {at: 300ms, type: vad_start}
+//	  - {at: 1.5s, type: transcript_final, text: "Tell me about Go."}
+//	  - {at: 4s, type: tts, tts: started}
+//	  - {at: 4.2s, type: tts, tts: first_audio}
+//	  - {at: 5s, type: feature, rms: 3000}
+//	  - {at: 9s, type: session_close, reason: done}
+//
+// at is the offset from the start of the run. Transcripts without an
+// utterance_id use the one from the latest StartMicToSTT, and tts events the
+// one from the latest StartTTS, so scripts keep working when the
+// orchestrator requires echoed IDs.
+type script struct {
+	Session string        `yaml:"session"`
+	Events  []scriptEvent `yaml:"events"`
+}
+
+type scriptEvent struct {
+	At          time.Duration `yaml:"at"`
+	Type        string        `yaml:"type"`
+	Text        string        `yaml:"text"`
+	UtteranceID string        `yaml:"utterance_id"`
+	TTS         string        `yaml:"tts"` // started | first_audio | stopped | failed
+	Reason      string        `yaml:"reason"`
+	RMS         float32       `yaml:"rms"`
+	Speaker     uint32        `yaml:"speaker"`
+}
+
+func loadScript(path string) (*script, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var sc script
+	if err := yaml.Unmarshal(b, &sc); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if len(sc.Events) == 0 {
+		return nil, fmt.Errorf("%s: no events", path)
+	}
+	for i, ev := range sc.Events {
+		if _, err := (&scriptIDs{}).event("s", ev); err != nil {
+			return nil, fmt.Errorf("%s: event %d: %w", path, i, err)
+		}
+		if i > 0 && ev.At < sc.Events[i-1].At {
+			return nil, fmt.Errorf("%s: event %d: at %s is before the previous event", path, i, ev.At)
+		}
+	}
+	return &sc, nil
+}
+
+// scriptIDs tracks the IDs the orchestrator issued so far.
+type scriptIDs struct {
+	mu    sync.Mutex
+	user  string
+	turn  string
+	agent string
+}
+
+// observe notes the IDs in a command from the orchestrator.
+func (ids *scriptIDs) observe(cmd *pb.OrchestratorCommand) {
+	ids.mu.Lock()
+	defer ids.mu.Unlock()
+	switch c := cmd.Cmd.(type) {
+	case *pb.OrchestratorCommand_StartMicToStt:
+		ids.user, ids.turn = c.StartMicToStt.GetUtteranceId(), c.StartMicToStt.GetTurnId()
+	case *pb.OrchestratorCommand_StartTts:
+		ids.agent = c.StartTts.GetUtteranceId()
+	}
+}
+
+// event builds the GatewayEvent for ev.
+func (ids *scriptIDs) event(sid string, ev scriptEvent) (*pb.GatewayEvent, error) {
+	ids.mu.Lock()
+	defer ids.mu.Unlock()
+	user, agent := ev.UtteranceID, ev.UtteranceID
+	if user == "" {
+		user = ids.user
+	}
+	if agent == "" {
+		agent = ids.agent
+	}
+	ts := uint64(time.Now().UnixMilli())
+	out := &pb.GatewayEvent{SessionId: sid}
+	switch ev.Type {
+	case "session_open":
+		out.Evt = &pb.GatewayEvent_SessionOpen{SessionOpen: &pb.SessionOpen{SessionId: sid, RoomUrl: "test://e2e"}}
+	case "session_close":
+		out.Evt = &pb.GatewayEvent_SessionClose{SessionClose: &pb.SessionClose{Reason: ev.Reason}}
+	case "vad_start":
+		out.Evt = &pb.GatewayEvent_VadStart{VadStart: &pb.VADStart{TsMs: ts}}
+	case "vad_end":
+		out.Evt = &pb.GatewayEvent_VadEnd{VadEnd: &pb.VADEnd{TsMs: ts}}
+	case "feature":
+		out.Evt = &pb.GatewayEvent_Feature{Feature: &pb.Feature{Rms: ev.RMS}}
+	case "transcript_interim":
+		out.Evt = &pb.GatewayEvent_TranscriptInterim{TranscriptInterim: &pb.TranscriptInterim{Text: ev.Text, TurnId: ids.turn, UtteranceId: user}}
+	case "transcript_final":
+		out.Evt = &pb.GatewayEvent_TranscriptFinal{TranscriptFinal: &pb.TranscriptFinal{Text: ev.Text, TurnId: ids.turn, UtteranceId: user, Speaker: ev.Speaker}}
+	case "tts":
+		switch ev.TTS {
+		case "started", "first_audio", "stopped", "failed":
+		default:
+			return nil, fmt.Errorf("tts %q: want started, first_audio, stopped or failed", ev.TTS)
+		}
+		out.Evt = &pb.GatewayEvent_Tts{Tts: &pb.TTSEvent{Type: ev.TTS, UtteranceId: agent, Reason: ev.Reason}}
+	default:
+		return nil, fmt.Errorf("unknown event type %q", ev.Type)
+	}
+	return out, nil
+}
+
+// run sends the events at their offsets. It stops early when ctx ends.
+func (sc *script) run(ctx context.Context, stream pb.GatewayControl_SessionClient, sid string, ids *scriptIDs) error {
+	began := time.Now()
+	for i, ev := range sc.Events {
+		if d := time.Until(began.Add(ev.At)); d > 0 {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(d):
+			}
+		}
+		msg, err := ids.event(sid, ev)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("[%s] -> %d %s %s%s\n", time.Now().Format("15:04:05.000"), i, ev.Type, ev.TTS, quoteIf(ev.Text))
+		if err := stream.Send(msg); err != nil {
+			return fmt.Errorf("send event %d (%s): %w", i, ev.Type, err)
+		}
+	}
+	return nil
+}
+
+func quoteIf(s string) string {
+	if s == "" {
+		return ""
+	}
+	return fmt.Sprintf(" %q", s)
+}
@@ -1,38 +1,83 @@
 package main
 
 import (
+    "context"
     "flag"
     "log"
     "net"
-    "net/http"
+    "os"
+    "os/signal"
+    "strconv"
+    "syscall"
+    "time"
 
     "google.golang.org/grpc"
 
+    "yuzu/agent/internal/grpcmw"
+    "yuzu/agent/internal/health"
+    "yuzu/agent/internal/probes"
+    "yuzu/agent/internal/warmup"
     tts "yuzu/agent/internal/tts"
     pb "yuzu/agent/internal/tts/pb"
-    "github.com/prometheus/client_golang/prometheus/promhttp"
 )
 
-var addr = flag.String("addr", ":9093", "tts service listen addr")
+var (
+    addr      = flag.String("addr", ":9093", "tts service listen addr")
+    probeAddr = flag.String("probe-addr", probes.Addr("TTS_PROBE_ADDR", ":8084"), "health/ready/metrics listen addr")
+)
 
 func main(){
     flag.Parse()
-    s := grpc.NewServer()
+    s := grpc.NewServer(grpcmw.ServerOptions(grpcmw.OptionsFromEnv("tts"))...)
     srv := tts.NewServer()
     pb.RegisterTTSServer(s, srv)
 
-    go func(){
-        mux := http.NewServeMux()
-        mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) { w.Write([]byte("ok\n")) })
-        mux.HandleFunc("/readyz", func(w http.ResponseWriter, r *http.Request) { w.Write([]byte("ok\n")) })
-        mux.Handle("/metrics", promhttp.Handler())
-        log.Printf("tts probes/metrics on :8084")
-        _ = http.ListenAndServe(":8084", mux)
-    }()
+    // Readiness: config presence plus a cheap provider ping, rechecked periodically
+    ping := os.Getenv("READYZ_PING") != "false"
+    ready := health.NewReadiness("elevenlabs", func(ctx context.Context) error { return tts.CheckProvider(ctx, ping) })
+    if v, err := strconv.Atoi(os.Getenv("READYZ_INTERVAL_S")); err == nil && v > 0 { ready.Interval = time.Duration(v) * time.Second }
+    go ready.Run(context.Background())
+
+    // Optional warm-ups so the first turn doesn't pay cold-start costs
+    if w := warmup.FromEnv("tts", srv.Warm); w != nil {
+        srv.SetWarmer(w)
+        go w.Run(context.Background())
+    }
+
+    mux := probes.NewMux()
+    mux.Handle("/readyz", ready)
+    // Dev-only WAV download of one utterance; DEV_MODE or X-Dev-Key
+    mux.Handle("/synthesize", srv.SynthesizeHandler())
+    probe, err := probes.Start("tts", *probeAddr, mux)
+    if err != nil { log.Fatalf("probes: %v", err) }
 
     l, err := net.Listen("tcp", *addr)
     if err != nil { log.Fatalf("listen: %v", err) }
     log.Printf("tts listening on %s", *addr)
+    go stopOn(s, probe)
     if err := s.Serve(l); err != nil { log.Fatalf("serve: %v", err) }
 }
 
+// stopOn stops the gRPC server and the probes on SIGINT/SIGTERM, and the
+// whole service if the probe server fails.
+func stopOn(s *grpc.Server, probe *probes.Server) {
+    sig := make(chan os.Signal, 1)
+    signal.Notify(sig, syscall.SIGINT, syscall.SIGTERM)
+    select {
+    case <-sig:
+        log.Printf("shutdown signal received, draining...")
+    case err := <-probe.Err():
+        log.Fatalf("probes: %v", err)
+    }
+    done := make(chan struct{})
+    go func(){ s.GracefulStop(); close(done) }()
+    select {
+    case <-done:
+    case <-time.After(5 * time.Second):
+        s.Stop()
+    }
+    ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+    defer cancel()
+    _ = probe.Shutdown(ctx)
+}
+
@@ -0,0 +1,331 @@
+// Command yuzuctl manages a running API server from a terminal.
+//
+//	yuzuctl [-server URL] [-api-key KEY] <command> [flags] [args]
+//
+//	sessions list                      list sessions, newest first
+//	sessions create [-preset NAME] [-barge-in-profile P] [-persona P] [-verbosity V] [-captions] [-token-stream] [-echo-test] [-context NAME=FILE]... [-start]
+//	sessions end <id>                  stop the session's bot
+//	events tail <id> [-since N] [-f] [-type T]
+//	transcript get <id> [-format text|openai-jsonl]
+//	health                             liveness and dependency checks
+//	loadtest [-n N] [-c C] [-start] [-end]
+//
+// -server and -api-key default to YUZU_SERVER and YUZU_API_KEY.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"yuzu/agent/pkg/client"
+)
+
+func main() {
+	server := flag.String("server", envOr("YUZU_SERVER", "http://localhost:8080"), "API server base URL")
+	apiKey := flag.String("api-key", os.Getenv("YUZU_API_KEY"), "Tenant API key")
+	timeout := flag.Duration("timeout", 30*time.Second, "Per-command timeout (not applied to events tail -f)")
+	flag.Usage = usage
+	flag.Parse()
+	args := flag.Args()
+	if len(args) == 0 {
+		usage()
+		os.Exit(2)
+	}
+
+	c := client.New(*server, client.WithAPIKey(*apiKey))
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	// events tail applies the timeout itself once it knows whether -f is set
+	tail := len(args) >= 2 && args[0] == "events" && args[1] == "tail"
+	if !tail {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, *timeout)
+		defer cancel()
+	}
+
+	var err error
+	switch cmd := strings.Join(args[:min(2, len(args))], " "); {
+	case args[0] == "health":
+		err = health(ctx, c)
+	case args[0] == "loadtest":
+		err = loadtest(ctx, c, args[1:])
+	case cmd == "sessions list":
+		err = sessionsList(ctx, c)
+	case cmd == "sessions create":
+		err = sessionsCreate(ctx, c, args[2:])
+	case cmd == "sessions end":
+		err = withID(args[2:], func(id string) error { return c.EndSession(ctx, id) })
+	case cmd == "events tail":
+		err = eventsTail(ctx, c, args[2:], *timeout)
+	case cmd == "transcript get":
+		err = transcriptGet(ctx, c, args[2:])
+	default:
+		err = fmt.Errorf("unknown command %q", cmd)
+	}
+	if err != nil && !errors.Is(err, context.Canceled) {
+		fmt.Fprintln(os.Stderr, "yuzuctl:", err)
+		os.Exit(1)
+	}
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, `usage: yuzuctl [-server URL] [-api-key KEY] <command>
+
+commands:
+  sessions list
+  sessions create [-preset NAME] [-barge-in-profile P] [-persona P] [-verbosity V] [-captions] [-token-stream] [-echo-test] [-context NAME=FILE]... [-start]
+  sessions end <id>
+  events tail <id> [-since N] [-f] [-type T]
+  transcript get <id> [-format text|openai-jsonl]
+  health
+  loadtest [-n N] [-c C] [-start] [-end]`)
+	flag.PrintDefaults()
+}
+
+func envOr(key, def string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return def
+}
+
+func withID(args []string, fn func(id string) error) error {
+	if len(args) != 1 || args[0] == "" {
+		return errors.New("expected one session ID")
+	}
+	return fn(args[0])
+}
+
+func sessionsList(ctx context.Context, c *client.Client) error {
+	ss, err := c.ListSessions(ctx)
+	if err != nil {
+		return err
+	}
+	tw := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "SESSION\tCREATED\tSTATUS\tBOT\tEVENTS")
+	for _, s := range ss {
+		bot := "-"
+		if s.BotRunning {
+			bot = "running"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%d\n", s.ID, s.CreatedAt.Local().Format(time.DateTime), s.Status, bot, s.Events)
+	}
+	return tw.Flush()
+}
+
+func sessionsCreate(ctx context.Context, c *client.Client, args []string) error {
+	fs := flag.NewFlagSet("sessions create", flag.ContinueOnError)
+	preset := fs.String("preset", "", "Start from this session preset")
+	profile := fs.String("barge-in-profile", "", "headset | laptop-speakers | phone | auto")
+	persona := fs.String("persona", "", "friendly | formal | technical-interviewer")
+	verbosity := fs.String("verbosity", "", "brief | normal | detailed")
+	captions := fs.Bool("captions", false, "Stream live captions to the room")
+	tokenStream := fs.Bool("token-stream", false, "Stream the agent's replies to the room token by token")
+	echoTest := fs.Bool("echo-test", false, "Repeat what the candidate says instead of replying, to check the audio loop")
+	maxDuration := fs.Duration("max-duration", 0, "End the interview after this long, e.g. 30m")
+	start := fs.Bool("start", false, "Start the bot after creating the session")
+	var docs []client.ContextDoc
+	fs.Func("context", "Upload FILE as reference document NAME, e.g. resume=cv.txt (repeatable)", func(v string) error {
+		name, path, ok := strings.Cut(v, "=")
+		if !ok || name == "" || path == "" {
+			return errors.New("want NAME=FILE")
+		}
+		b, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		docs = append(docs, client.ContextDoc{Name: name, Kind: name, Text: string(b)})
+		return nil
+	})
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	s, err := c.CreateSessionWithProfile(ctx, *preset, *profile, &client.Style{Persona: *persona, Verbosity: *verbosity, Captions: *captions, TokenStream: *tokenStream, EchoTest: *echoTest, MaxDurationSeconds: int(maxDuration.Seconds())})
+	if err != nil {
+		return err
+	}
+	for _, d := range docs {
+		if _, err := c.UploadContext(ctx, s.ID, d); err != nil {
+			return fmt.Errorf("session %s created, uploading %s failed: %w", s.ID, d.Name, err)
+		}
+	}
+	if *start {
+		if err := c.StartBot(ctx, s.ID); err != nil {
+			return fmt.Errorf("session %s created, bot start failed: %w", s.ID, err)
+		}
+	}
+	fmt.Printf("%s\t%s\n", s.ID, s.RoomURL)
+	return nil
+}
+
+func eventsTail(ctx context.Context, c *client.Client, args []string, timeout time.Duration) error {
+	fs := flag.NewFlagSet("events tail", flag.ContinueOnError)
+	since := fs.Int("since", 0, "First event index to print")
+	followF := fs.Bool("f", false, "Keep polling for new events")
+	typ := fs.String("type", "", "Only print events whose type starts with this")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if !*followF {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	return withID(fs.Args(), func(id string) error {
+		show := func(i int, ev client.Event) error {
+			if *typ != "" && !strings.HasPrefix(ev.Type, *typ) {
+				return nil
+			}
+			p, _ := json.Marshal(ev.Payload)
+			fmt.Printf("%d\t%s\t%s\t%s\n", i, ev.Timestamp.Local().Format("15:04:05.000"), ev.Type, p)
+			return nil
+		}
+		if *followF {
+			return c.StreamEvents(ctx, id, *since, show)
+		}
+		evs, err := c.Events(ctx, id)
+		if err != nil {
+			return err
+		}
+		for i := *since; i < len(evs); i++ {
+			show(i, evs[i])
+		}
+		return nil
+	})
+}
+
+func transcriptGet(ctx context.Context, c *client.Client, args []string) error {
+	fs := flag.NewFlagSet("transcript get", flag.ContinueOnError)
+	format := fs.String("format", "text", "text | openai-jsonl")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	return withID(fs.Args(), func(id string) error {
+		if *format != "text" {
+			return c.Export(ctx, id, *format, os.Stdout)
+		}
+		evs, err := c.Events(ctx, id)
+		if err != nil {
+			return err
+		}
+		for _, ev := range evs {
+			who := map[string]string{"transcript_final": "candidate", "agent_text": "agent"}[ev.Type]
+			if text, _ := ev.Payload["text"].(string); who != "" && text != "" {
+				fmt.Printf("[%s] %s: %s\n", ev.Timestamp.Local().Format("15:04:05"), who, text)
+			}
+		}
+		return nil
+	})
+}
+
+func health(ctx context.Context, c *client.Client) error {
+	if err := c.Healthz(ctx); err != nil {
+		return fmt.Errorf("healthz: %w", err)
+	}
+	fmt.Println("healthz: ok")
+	report, err := c.Health(ctx)
+	if report != nil {
+		b, _ := json.MarshalIndent(report, "", "  ")
+		fmt.Println(string(b))
+	}
+	return err
+}
+
+// loadtest creates n sessions with c workers and reports latency
+// percentiles. Sessions are left in place unless -end is given.
+func loadtest(ctx context.Context, c *client.Client, args []string) error {
+	fs := flag.NewFlagSet("loadtest", flag.ContinueOnError)
+	n := fs.Int("n", 20, "Sessions to create")
+	conc := fs.Int("c", 4, "Concurrent requests")
+	start := fs.Bool("start", false, "Also start a bot per session")
+	end := fs.Bool("end", false, "End each session afterwards")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *n <= 0 || *conc <= 0 {
+		return errors.New("-n and -c must be positive")
+	}
+
+	var (
+		mu     sync.Mutex
+		lat    []time.Duration
+		errs   = map[string]int{}
+		jobs   = make(chan struct{})
+		wg     sync.WaitGroup
+		began  = time.Now()
+		record = func(d time.Duration, err error) {
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				errs[errClass(err)]++
+				return
+			}
+			lat = append(lat, d)
+		}
+	)
+	for w := 0; w < *conc; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range jobs {
+				t0 := time.Now()
+				s, err := c.CreateSession(ctx, nil)
+				if err == nil && *start {
+					err = c.StartBot(ctx, s.ID)
+				}
+				record(time.Since(t0), err)
+				if s != nil && *end {
+					_ = c.EndSession(ctx, s.ID)
+				}
+			}
+		}()
+	}
+	for i := 0; i < *n && ctx.Err() == nil; i++ {
+		jobs <- struct{}{}
+	}
+	close(jobs)
+	wg.Wait()
+
+	elapsed := time.Since(began)
+	sort.Slice(lat, func(i, j int) bool { return lat[i] < lat[j] })
+	fmt.Printf("requests=%d ok=%d failed=%d elapsed=%s rate=%.1f/s\n", *n, len(lat), *n-len(lat), elapsed.Round(time.Millisecond), float64(len(lat))/elapsed.Seconds())
+	if len(lat) > 0 {
+		fmt.Printf("latency p50=%s p95=%s p99=%s max=%s\n", pct(lat, 50), pct(lat, 95), pct(lat, 99), lat[len(lat)-1])
+	}
+	for k, v := range errs {
+		fmt.Printf("error %s: %d\n", k, v)
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("%d requests failed", *n-len(lat))
+	}
+	return nil
+}
+
+func pct(sorted []time.Duration, p int) time.Duration {
+	i := (len(sorted)*p+99)/100 - 1
+	if i < 0 {
+		i = 0
+	}
+	return sorted[i].Round(time.Millisecond)
+}
+
+func errClass(err error) string {
+	var ae *client.APIError
+	if errors.As(err, &ae) {
+		return fmt.Sprintf("http_%d", ae.StatusCode)
+	}
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, context.Canceled) {
+		return "canceled"
+	}
+	return "transport"
+}
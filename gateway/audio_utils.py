@@ -1,3 +1,4 @@
+import os
 import time
 from collections import deque
 from dataclasses import dataclass
@@ -11,6 +12,21 @@ def now_mono_ms() -> int:
     return int(time.monotonic() * 1000)
 
 
+def grpc_compression(env_key: str):
+    """Request compression for a channel carrying PCM, from env_key (e.g.
+    STT_GRPC_COMPRESSION), the list the Go service reads too. Python gRPC has
+    gzip and deflate only, so the first of those in the list is used. Responses
+    need nothing here: the service compresses them with whichever of its
+    choices we advertise (gzip), e.g. TTS audio with TTS_GRPC_COMPRESSION."""
+    import grpc
+    names = {'gzip': grpc.Compression.Gzip, 'deflate': grpc.Compression.Deflate}
+    for name in os.environ.get(env_key, '').split(','):
+        c = names.get(name.strip().lower())
+        if c is not None:
+            return c
+    return grpc.Compression.NoCompression
+
+
 def downsample_48k_to_16k(pcm48: bytes) -> bytes:
     if not pcm48:
         return b""
@@ -41,6 +57,13 @@ class RingBuffer:
         while len(self._q) > self.hard_cap_frames:
             self._q.popleft()
 
+    def tail(self, ms: int) -> bytes:
+        """The most recent ms of audio, left in the buffer."""
+        n = max(0, int(ms // self.frame_ms))
+        if n == 0 or not self._q:
+            return b""
+        return b"".join(f.data for f in list(self._q)[-n:])
+
     def flush_all(self) -> bytes:
         if not self._q:
             return b""
@@ -79,3 +102,125 @@ class FrameBatcher:
         self.batch_ms = batch_ms
         self._target = self.batch_ms * self._bytes_per_ms
 
+
+
+class SoftStop:
+    """Ends playback gently for a StopTTS with a boundary or fade_ms. With boundary
+    'word', frames play on until one is quiet next to the loudest since the stop
+    (a gap between words) or max_wait_ms has passed; from there fade_ms of audio
+    is ramped down to silence and done is set. Frames keep their length so pacing
+    is unchanged; a frame returned empty means stop before it."""
+
+    QUIET_DBFS = -45.0
+    QUIET_BELOW_DB = 20.0
+
+    def __init__(self, fade_ms: int = 0, boundary: str = '', max_wait_ms: int = 200, sample_rate: int = 48000):
+        self.fade_samples = int(sample_rate * max(0, fade_ms) / 1000)
+        self.waiting = boundary == 'word'
+        self.max_wait_ms = max(0, int(max_wait_ms))
+        self.waited_ms = 0
+        self.at_word = False  # a gap was found before max_wait_ms
+        self.done = False
+        self._loudest = None
+        self._faded = 0
+        self._sample_rate = sample_rate
+
+    @classmethod
+    def for_stop(cls, stop) -> Optional['SoftStop']:
+        """The SoftStop a StopTTS asks for, or None for an immediate cut.
+        TTS_STOP_WORD_MAX_MS (default 200) caps the wait for a word boundary."""
+        boundary = (getattr(stop, 'boundary', '') or '').lower()
+        fade_ms = int(getattr(stop, 'fade_ms', 0) or 0)
+        if boundary != 'word' and fade_ms <= 0:
+            return None
+        return cls(fade_ms, boundary, int(os.environ.get('TTS_STOP_WORD_MAX_MS', '200')))
+
+    def budget_s(self) -> float:
+        """Longest the stop can take, for a fallback when no frames flow."""
+        return (self.max_wait_ms + 1000 * self.fade_samples / self._sample_rate) / 1000 + 0.1
+
+    def process(self, frame: bytes) -> bytes:
+        if self.done or not frame:
+            return b''
+        x = np.frombuffer(frame, dtype=np.int16).astype(np.float32)
+        if self.waiting:
+            db = 10 * np.log10(max(float(np.mean((x / 32768.0) ** 2)), 1e-12))
+            self._loudest = db if self._loudest is None else max(self._loudest, db)
+            quiet = db < self.QUIET_DBFS or db < self._loudest - self.QUIET_BELOW_DB
+            if not quiet and self.waited_ms < self.max_wait_ms:
+                self.waited_ms += int(1000 * len(x) / self._sample_rate)
+                return frame
+            self.waiting = False
+            self.at_word = quiet
+        if self.fade_samples <= 0:
+            self.done = True
+            return b''
+        ramp = 1.0 - (self._faded + np.arange(len(x), dtype=np.float32)) / self.fade_samples
+        x *= np.clip(ramp, 0.0, 1.0)
+        self._faded += len(x)
+        if self._faded >= self.fade_samples:
+            self.done = True
+        return x.astype(np.int16).tobytes()
+
+
+class LoudnessNormalizer:
+    """Streaming counterpart of the TTS service's loudness pass (internal/tts/loudness.go)
+    for PCM16@48k played straight from the provider. The level is a running gated
+    RMS over the session's 20ms frames: frames under -60 dBFS don't count, nor ones
+    more than 10 dB below the running level. The gain toward target_dbfs is capped
+    at max_gain_db and eased in frame by frame. A frame whose peak would pass
+    limit_dbfs is turned down at once, and the gain recovers over the next frames."""
+
+    def __init__(self, target_dbfs: float = -20.0, max_gain_db: float = 12.0, limit_dbfs: float = -1.0):
+        self.target_dbfs = float(target_dbfs)
+        self.max_gain_db = float(max_gain_db)
+        self.ceiling = 32768.0 * 10 ** (float(limit_dbfs) / 20)
+        self._power = 0.0  # running mean square of gated frames, full scale = 1
+        self._frames = 0
+        self._gain = 1.0
+        self.limited_frames = 0
+
+    @classmethod
+    def from_env(cls) -> Optional['LoudnessNormalizer']:
+        """The normalizer TTS_NORMALIZE asks for, or None; same settings as the service."""
+        if os.environ.get('TTS_NORMALIZE', 'false').lower() not in ('1', 'true', 'yes'):
+            return None
+        return cls(float(os.environ.get('TTS_TARGET_DBFS', '-20')),
+                   float(os.environ.get('TTS_MAX_GAIN_DB', '12')),
+                   float(os.environ.get('TTS_LIMIT_DBFS', '-1')))
+
+    def level_dbfs(self) -> Optional[float]:
+        if self._frames == 0:
+            return None
+        return 10 * np.log10(max(self._power, 1e-12))
+
+    def process(self, frame: bytes) -> bytes:
+        if not frame:
+            return frame
+        x = np.frombuffer(frame, dtype=np.int16).astype(np.float32)
+        p = float(np.mean((x / 32768.0) ** 2))
+        db = 10 * np.log10(max(p, 1e-12))
+        level = self.level_dbfs()
+        if db >= -60.0 and (level is None or db >= level - 10.0):
+            self._frames += 1
+            # Cumulative mean at first, then a ~10s moving window
+            self._power += (p - self._power) / min(self._frames, 500)
+        level = self.level_dbfs()
+        if level is None:
+            return frame
+        gain_db = max(-self.max_gain_db, min(self.max_gain_db, self.target_dbfs - level))
+        want = 10 ** (gain_db / 20)
+        peak = float(np.max(np.abs(x))) if x.size else 0.0
+        if peak * want > self.ceiling:
+            want = self.ceiling / peak
+            self.limited_frames += 1
+        if want < self._gain:
+            # Attack at once so the frame can't overshoot the ceiling
+            self._gain = want
+            y = x * want
+        else:
+            # Release: ramp up across the frame
+            ramp = np.linspace(self._gain, self._gain + (want - self._gain) * 0.2, x.size, dtype=np.float32)
+            self._gain = float(ramp[-1])
+            y = x * ramp
+        return np.clip(np.round(y), -32768, 32767).astype(np.int16).tobytes()
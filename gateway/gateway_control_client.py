@@ -1,14 +1,20 @@
 import asyncio
 import os
+import time
 from typing import Optional, Callable
 
 try:
     from . import gateway_control_pb2 as gw
     from . import gateway_control_pb2_grpc as gw_grpc
+    from .audio_utils import SoftStop
 except Exception:
     # Fallback to absolute import if package-relative fails
     import gateway_control_pb2 as gw
     import gateway_control_pb2_grpc as gw_grpc
+    from audio_utils import SoftStop
+
+# Advertised on SessionOpen; this client unpacks CommandBatch
+GATEWAY_CAPABILITIES = ['command_batch', 'begin_listening', 'stop_tts_ack', 'arm_barge_in_ack']
 
 
 def _grpc_error_info(e: Exception) -> str:
@@ -25,6 +31,60 @@ def _grpc_error_info(e: Exception) -> str:
     return str(e)[:200]
 
 
+def _style_from_env():
+    """Session response style passed down by the API server (LLM_* env)."""
+    def _num(name, conv):
+        try:
+            return conv(os.environ.get(name, '') or 0)
+        except ValueError:
+            return conv(0)
+    style = gw.SessionStyle(
+        persona=os.environ.get('LLM_PERSONA', ''),
+        verbosity=os.environ.get('LLM_VERBOSITY', ''),
+        max_tokens=max(0, _num('LLM_MAX_TOKENS', int)),
+        system_prompt=os.environ.get('LLM_SYSTEM_PROMPT', ''),
+        instructions=os.environ.get('LLM_SESSION_INSTRUCTIONS', ''),
+        # Session preset settings (flow, barge-in thresholds)
+        flow_json=os.environ.get('LLM_FLOW_JSON', ''),
+        barge_in_min_rms=max(0, _num('LOCAL_STOP_MIN_RMS', int)),
+        barge_in_guard_ms=max(0, _num('LOCAL_STOP_GUARD_MS', int)),
+        barge_in_hangover=max(0, _num('LOCAL_STOP_HANGOVER_FRAMES', int)),
+        barge_in_profile=os.environ.get('BARGE_IN_PROFILE', ''),
+        captions=os.environ.get('CAPTIONS', '').lower() in ('1', 'true', 'yes'),
+        max_duration_s=max(0, _num('MAX_DURATION_S', int)),
+        token_stream=os.environ.get('TOKEN_STREAM', '').lower() in ('1', 'true', 'yes'),
+        echo_test=os.environ.get('ECHO_TEST', '').lower() in ('1', 'true', 'yes'),
+        # Session consent (set by the API from POST /sessions)
+        no_llm_logging=os.environ.get('NO_LLM_LOGGING', '').lower() in ('1', 'true', 'yes'),
+        no_transcript_retention=os.environ.get('NO_TRANSCRIPT_RETENTION', '').lower() in ('1', 'true', 'yes'),
+        # Reference documents uploaded to the session (job description, resume)
+        context_json=os.environ.get('LLM_CONTEXT_JSON', ''),
+    )
+    # temperature has presence: set it only when given, so an explicit 0
+    # reaches the orchestrator and an unset one keeps its default
+    try:
+        style.temperature = float(os.environ.get('LLM_TEMPERATURE', ''))
+    except ValueError:
+        pass
+    return style
+
+
+def _expand_begin_listening(cmds):
+    """Yields cmds with each BeginListening replaced by its parts (stop, arm,
+    mic), so they are applied back to back before the next command is read."""
+    for cmd in cmds:
+        if cmd.WhichOneof('cmd') != 'begin_listening':
+            yield cmd
+            continue
+        bl = cmd.begin_listening
+        if bl.HasField('stop_tts'):
+            yield gw.OrchestratorCommand(session_id=cmd.session_id, stop_tts=bl.stop_tts)
+        if bl.HasField('arm_barge_in'):
+            yield gw.OrchestratorCommand(session_id=cmd.session_id, arm_barge_in=bl.arm_barge_in)
+        if bl.HasField('mic'):
+            yield gw.OrchestratorCommand(session_id=cmd.session_id, start_mic_to_stt=bl.mic)
+
+
 class GatewayControlClient:
     """Async gRPC client for Orchestrator control stream.
 
@@ -44,28 +104,67 @@ class GatewayControlClient:
         self._recv_task = None
         self._write_task = None
         self._feature_task = None
+        self._heartbeat_task = None
         self._reconnect_task = None
         self._write_queue: asyncio.Queue = None  # Will be created in connect()
         self._closed = False
         self._reconnecting = False
         self._room_url_last: Optional[str] = None
+        # Set when the orchestrator acknowledges SessionClose
+        self._close_acked = asyncio.Event()
         # Feature coalescing (rate limit to ~10Hz)
         self._feature_latest: Optional[float] = None
         self._feature_last_sent: Optional[float] = None
         self._feature_interval_sec: float = float(os.environ.get('ORCH_FEATURE_INTERVAL_SEC', '0.1'))
+        # Heartbeats keep a quiet session from looking dead to the orchestrator; 0 disables
+        self._heartbeat_interval_sec: float = float(os.environ.get('GATEWAY_HEARTBEAT_SEC', '5'))
+        self._heartbeat_seq = 0
+        # Finals written in the last GATEWAY_RESEND_FINALS_MS are sent again after a reconnect,
+        # since a broken stream may have lost them; the orchestrator drops the ones it already has
+        self._resend_finals_sec: float = float(os.environ.get('GATEWAY_RESEND_FINALS_MS', '5000')) / 1000.0
+        self._recent_finals = []  # (monotonic time, GatewayEvent)
         # Optional callbacks that gateway wires
         self.on_start_tts: Optional[Callable[[str], asyncio.Future]] = None
+        # Called with the orchestrator-issued utterance ID on each StartMicToSTT
+        self.on_utterance_id: Optional[Callable[[str], asyncio.Future]] = None
+        # Called with the playback gain on each SetVolume
+        self.on_set_volume: Optional[Callable[[float], None]] = None
+        # Called with (text, turn_id, utterance_id) on each DisplayText
+        self.on_display_text: Optional[Callable[[str, str, str], None]] = None
+        # Called with each Caption command when the session has captions on
+        self.on_caption: Optional[Callable[[object], None]] = None
+        # Called with each ModerationFlag so it reaches the session's event log
+        self.on_moderation_flag: Optional[Callable[[object], None]] = None
+        # Called with each TokenDelta when the session streams tokens
+        self.on_token_delta: Optional[Callable[[object], None]] = None
+        # Called with each TurnState so transitions reach the session's event log
+        self.on_turn_state: Optional[Callable[[object], None]] = None
+        # Called with each LLMStatus (degraded mode entered or left)
+        self.on_llm_status: Optional[Callable[[object], None]] = None
+        # Called with the BargeInProfileSuggestion made from the first seconds of audio
+        self.on_profile_suggestion: Optional[Callable[[object], None]] = None
+        # Called with the reason on EndInterview, so the API can complete the session
+        self.on_end_interview: Optional[Callable[[str], None]] = None
+        # Called with the reason on StopAll, to drop speech queued in the gateway
+        self.on_stop_all: Optional[Callable[[str], None]] = None
+
+    def _auth_metadata(self):
+        """Per-session worker token; the orchestrator validates it on SessionOpen."""
+        token = os.environ.get('WORKER_TOKEN', '')
+        return (('authorization', f'Bearer {token}'),) if token else None
 
     async def connect(self):
         from grpc import aio
         target = os.environ.get('ORCH_ADDR', 'localhost:9090')
         self._channel = aio.insecure_channel(target)
         self._stub = gw_grpc.GatewayControlStub(self._channel)
-        self._call = self._stub.Session()
+        self._call = self._stub.Session(metadata=self._auth_metadata())
         self._write_queue = asyncio.Queue()
         self._recv_task = self._loop.create_task(self._recv_loop())
         self._write_task = self._loop.create_task(self._write_loop())
         self._feature_task = self._loop.create_task(self._feature_loop())
+        if self._heartbeat_interval_sec > 0 and self._heartbeat_task is None:
+            self._heartbeat_task = self._loop.create_task(self._heartbeat_loop())
         # Reconnect supervisor
         if self._reconnect_task is None:
             self._reconnect_task = self._loop.create_task(self._reconnect_supervisor())
@@ -138,6 +237,12 @@ class GatewayControlClient:
                 await self._feature_task
             except Exception:
                 pass
+        if self._heartbeat_task is not None:
+            self._heartbeat_task.cancel()
+            try:
+                await self._heartbeat_task
+            except Exception:
+                pass
         if self._reconnect_task is not None:
             self._reconnect_task.cancel()
             try:
@@ -169,9 +274,29 @@ class GatewayControlClient:
         if self._closed:
             return
         self._room_url_last = room_url
-        ev = gw.GatewayEvent(session_id=self.session_id, session_open=gw.SessionOpen(session_id=self.session_id, room_url=room_url))
+        ev = gw.GatewayEvent(session_id=self.session_id, session_open=gw.SessionOpen(session_id=self.session_id, room_url=room_url, style=_style_from_env(), capabilities=GATEWAY_CAPABILITIES))
         self._enqueue(ev)
 
+    async def close_session(self, reason: str, timeout_s: float = 3.0) -> bool:
+        """Tell the orchestrator the session is over and wait for its final Ack.
+
+        The orchestrator cancels the LLM, stops TTS, persists the session summary
+        and then acks. Returns False if the ack didn't arrive within timeout_s; the
+        orchestrator then finalizes the session itself once the stream drops."""
+        if self._closed or self._call is None:
+            return False
+        self._close_acked.clear()
+        ev = gw.GatewayEvent(session_id=self.session_id, session_close=gw.SessionClose(reason=reason))
+        if not self._enqueue(ev):
+            return False
+        try:
+            await asyncio.wait_for(self._close_acked.wait(), timeout=timeout_s)
+        except asyncio.TimeoutError:
+            self._log("orchestrator_session_close_timeout", session_id=self.session_id, metrics={"reason": reason, "timeout_s": timeout_s})
+            return False
+        self._log("orchestrator_session_closed", session_id=self.session_id, metrics={"reason": reason})
+        return True
+
     async def send_feature(self, rms: float):
         """Coalesce features to a 10Hz loop. Store latest RMS; writer will send."""
         if self._closed:
@@ -181,24 +306,95 @@ class GatewayControlClient:
         except Exception:
             pass
 
-    async def send_transcript_interim(self, utterance_id: str, text: str):
+    async def send_transcript_interim(self, utterance_id: str, text: str, committed_text: str = "", volatile_text: str = ""):
         if self._closed or self._call is None:
             return
-        ev = gw.GatewayEvent(session_id=self.session_id, transcript_interim=gw.TranscriptInterim(utterance_id=utterance_id, text=text))
+        ev = gw.GatewayEvent(session_id=self.session_id, transcript_interim=gw.TranscriptInterim(utterance_id=utterance_id, text=text, turn_id=self._state.get('orch_turn_id', ''),
+                                                                                                 committed_text=committed_text, volatile_text=volatile_text))
         self._enqueue(ev)
 
-    async def send_transcript_final(self, utterance_id: str, text: str):
-        if self._closed or self._call is None:
+    async def send_transcript_final(self, utterance_id: str, text: str, word_count: int = 0, speech_ms: int = 0, speaker: int = 0, source: str = "", audio_fingerprint: int = 0):
+        if self._closed:
+            return
+        ev = gw.GatewayEvent(session_id=self.session_id, transcript_final=gw.TranscriptFinal(utterance_id=utterance_id, text=text, turn_id=self._state.get('orch_turn_id', ''),
+                                                                                             word_count=word_count, speech_ms=speech_ms, speaker=speaker, source=source,
+                                                                                             audio_fingerprint=audio_fingerprint))
+        if self._resend_finals_sec > 0:
+            now = time.monotonic()
+            self._recent_finals = [(t, e) for t, e in self._recent_finals if now - t <= self._resend_finals_sec] + [(now, ev)]
+        if self._call is None:
             return
-        ev = gw.GatewayEvent(session_id=self.session_id, transcript_final=gw.TranscriptFinal(utterance_id=utterance_id, text=text))
         if self._enqueue(ev):
             self._log("orchestrator_transcript_queued", session_id=self.session_id, metrics={"text_len": len(text)})
 
+    async def send_error(self, code: str, message: str = ""):
+        if self._closed or self._call is None:
+            return
+        ev = gw.GatewayEvent(session_id=self.session_id, error=gw.GatewayError(code=code, message=message[:500]))
+        if self._enqueue(ev):
+            self._log("orchestrator_error_queued", session_id=self.session_id, metrics={"code": code})
+
+    def _apply_stop_tts(self, stop) -> tuple[str, str]:
+        """Stops playback if the StopTTS still applies; returns the result and the
+        utterance stopped. A generation is applied once, and a targeted stop only
+        cuts that utterance, so a late or repeated stop leaves the next one alone.
+        A stop with a word boundary or fade hands playback a SoftStop that sets
+        stop_event once the audio has wound down, with a timer in case it can't."""
+        if stop.generation:
+            if stop.generation <= self._state.get('stop_gen_applied', 0):
+                return 'duplicate', ''
+            self._state['stop_gen_applied'] = stop.generation
+        # active_utterance_id turns into the user's on VAD start; this stays the agent's
+        playing = self._state.get('playing_agent_utterance_id', '') if self._state.get('speaking') else ''
+        # A filler stop must not cut the reply that superseded it
+        if stop.reason == 'filler_superseded' and not self._state.get('filler_playing'):
+            return 'not_playing', ''
+        if stop.utterance_id:
+            if not playing:
+                return 'not_playing', ''
+            if stop.utterance_id != playing:
+                return 'stale', ''
+        soft = SoftStop.for_stop(stop) if playing else None
+        if soft is not None and self._state.get('soft_stop') is None:
+            self._state['soft_stop'] = soft
+
+            def _fallback():
+                if self._state.get('soft_stop') is soft:
+                    self._state['soft_stop'] = None
+                    self._stop_event.set()
+            self._loop.call_later(soft.budget_s(), _fallback)
+            return 'stopped', playing
+        try:
+            self._stop_event.set()
+        except Exception:
+            pass
+        return 'stopped', playing
+
+    async def send_stop_ack(self, stop, result: str, stopped: str = ""):
+        if self._closed or self._call is None:
+            return
+        ev = gw.GatewayEvent(session_id=self.session_id, stop_ack=gw.StopTTSAck(
+            generation=stop.generation, utterance_id=stop.utterance_id, result=result, stopped_utterance_id=stopped))
+        self._enqueue(ev)
+
+    async def send_arm_ack(self, arm):
+        """Echoes the barge-in thresholds in force after an ArmBargeIn."""
+        if self._closed or self._call is None:
+            return
+        ev = gw.GatewayEvent(session_id=self.session_id, arm_ack=gw.ArmBargeInAck(
+            generation=arm.generation,
+            guard_ms=int(self._state.get('local_stop_guard_ms', 0) or 0),
+            min_rms=int(self._state.get('local_stop_min_rms', 0) or 0)))
+        self._enqueue(ev)
+
     async def send_tts_event(self, typ: str, reason: str = "", first_audio_ms: int | None = None):
         if self._closed:
             self._log("orchestrator_tts_event_call_none", session_id=self.session_id, metrics={"type": typ})
             return
         evt = gw.TTSEvent(type=typ)
+        # Echo the orchestrator-issued IDs of the utterance being spoken
+        evt.turn_id = self._state.get('orch_tts_turn_id', '')
+        evt.utterance_id = self._state.get('orch_tts_utterance_id', '')
         if reason:
             evt.reason = reason
         if first_audio_ms is not None:
@@ -218,35 +414,157 @@ class GatewayControlClient:
                     self._log("orchestrator_stream_closed", session_id=self.session_id)
                     self._call = None
                     return
-                which = cmd.WhichOneof('cmd')
-                if which == 'arm_barge_in':
-                    guard = int(getattr(cmd.arm_barge_in, 'guard_ms', 0) or 0)
-                    min_rms = int(getattr(cmd.arm_barge_in, 'min_rms', 0) or 0)
-                    # Update local thresholds for backward-compat logs
-                    if guard > 0:
-                        self._state['local_stop_guard_ms'] = guard
-                    if min_rms > 0:
-                        self._state['local_stop_min_rms'] = min_rms
-                    self._log("orchestrator_arm_barge_in", session_id=self.session_id, metrics={"guard_ms": guard, "min_rms": min_rms})
-                elif which == 'start_mic_to_stt' or which == 'stop_mic_to_stt':
-                    enabled = (which == 'start_mic_to_stt')
-                    self._state['mic_to_stt_enabled'] = enabled
-                    self._log("orchestrator_mic_to_stt", session_id=self.session_id, metrics={"enabled": enabled})
-                elif which == 'stop_tts':
-                    self._log("orchestrator_stop_tts", session_id=self.session_id)
-                    try:
-                        self._stop_event.set()
-                    except Exception:
-                        pass
-                elif which == 'start_tts':
-                    if callable(self.on_start_tts):
+                # A CommandBatch is its commands, handled in order
+                cmds = cmd.batch.commands if cmd.WhichOneof('cmd') == 'batch' else (cmd,)
+                for cmd in _expand_begin_listening(cmds):
+                    which = cmd.WhichOneof('cmd')
+                    if which == 'arm_barge_in':
+                        guard = int(getattr(cmd.arm_barge_in, 'guard_ms', 0) or 0)
+                        min_rms = int(getattr(cmd.arm_barge_in, 'min_rms', 0) or 0)
+                        # Update local thresholds for backward-compat logs
+                        if guard > 0:
+                            self._state['local_stop_guard_ms'] = guard
+                        if min_rms > 0:
+                            self._state['local_stop_min_rms'] = min_rms
+                        self._log("orchestrator_arm_barge_in", session_id=self.session_id, metrics={"guard_ms": guard, "min_rms": min_rms, "generation": cmd.arm_barge_in.generation})
+                        if cmd.arm_barge_in.generation:
+                            await self.send_arm_ack(cmd.arm_barge_in)
+                    elif which == 'start_mic_to_stt' or which == 'stop_mic_to_stt':
+                        enabled = (which == 'start_mic_to_stt')
+                        if enabled and cmd.start_mic_to_stt.preroll_ms:
+                            self._state['stt_preroll_ms'] = cmd.start_mic_to_stt.preroll_ms
+                        self._state['mic_to_stt_enabled'] = enabled
+                        self._log("orchestrator_mic_to_stt", session_id=self.session_id, metrics={"enabled": enabled})
+                        if enabled and cmd.start_mic_to_stt.utterance_id:
+                            # Orchestrator-issued IDs: STT must use them so transcripts can be correlated
+                            self._state['orch_turn_id'] = cmd.start_mic_to_stt.turn_id
+                            self._state['orch_utterance_id'] = cmd.start_mic_to_stt.utterance_id
+                            if callable(self.on_utterance_id):
+                                try:
+                                    await self.on_utterance_id(cmd.start_mic_to_stt.utterance_id)
+                                except Exception as e:
+                                    self._log("gateway_utterance_id_error", session_id=self.session_id, metrics={"error": str(e)})
+                    elif which == 'stop_tts':
+                        result, stopped = self._apply_stop_tts(cmd.stop_tts)
+                        self._log("orchestrator_stop_tts", session_id=self.session_id, metrics={
+                            "reason": cmd.stop_tts.reason, "target": cmd.stop_tts.utterance_id,
+                            "generation": cmd.stop_tts.generation, "result": result})
+                        if cmd.stop_tts.generation:
+                            await self.send_stop_ack(cmd.stop_tts, result, stopped)
+                    elif which == 'stop_all':
+                        # The session is over: silence the bot and stop transcribing,
+                        # then let the main loop leave
+                        reason = cmd.stop_all.reason or 'stop_all'
+                        self._state['mic_to_stt_enabled'] = False
+                        self._state['stop_all'] = reason
+                        self._log("orchestrator_stop_all", session_id=self.session_id, reason=reason)
+                        if callable(self.on_stop_all):
+                            try:
+                                self.on_stop_all(reason)
+                            except Exception as e:
+                                self._log("gateway_stop_all_error", session_id=self.session_id, metrics={"error": str(e)})
                         try:
-                            await self.on_start_tts(cmd.start_tts.text)
-                        except Exception as e:
-                            self._log("gateway_tts_start_error", session_id=self.session_id, metrics={"error": str(e)})
-                else:
-                    # ack / join_room / unknown
-                    pass
+                            self._stop_event.set()
+                        except Exception:
+                            pass
+                    elif which == 'start_tts':
+                        self._state['orch_tts_turn_id'] = cmd.start_tts.turn_id
+                        self._state['orch_tts_utterance_id'] = cmd.start_tts.utterance_id
+                        # Set when the orchestrator re-routes a failed sentence
+                        self._state['orch_tts_provider'] = cmd.start_tts.provider
+                        # Pace mirroring; 0 means provider default
+                        self._state['orch_tts_speaking_rate'] = cmd.start_tts.speaking_rate
+                        self._state['orch_tts_pause_ms'] = cmd.start_tts.pause_ms
+                        # "question" | "exclamation" | "" from the sentence's punctuation
+                        self._state['orch_tts_intonation'] = cmd.start_tts.intonation
+                        # Stand-in while the LLM is slow; played at once, not batched
+                        self._state['orch_tts_filler'] = cmd.start_tts.filler
+                        if callable(self.on_start_tts):
+                            try:
+                                await self.on_start_tts(cmd.start_tts.text)
+                            except Exception as e:
+                                self._log("gateway_tts_start_error", session_id=self.session_id, metrics={"error": str(e)})
+                    elif which == 'set_volume':
+                        # Spoken "louder"/"quieter"; 1.0 is unity
+                        gain = cmd.set_volume.gain or 1.0
+                        self._state['output_gain'] = gain
+                        self._log("orchestrator_set_volume", session_id=self.session_id, metrics={"gain": round(gain, 3)})
+                        if callable(self.on_set_volume):
+                            try:
+                                self.on_set_volume(gain)
+                            except Exception as e:
+                                self._log("gateway_set_volume_error", session_id=self.session_id, metrics={"error": str(e)})
+                    elif which == 'display_text':
+                        # TTS is down: the sentence is shown instead of spoken
+                        dt = cmd.display_text
+                        self._log("orchestrator_display_text", session_id=self.session_id, utterance_id=dt.utterance_id, metrics={"text_len": len(dt.text)})
+                        if callable(self.on_display_text):
+                            try:
+                                self.on_display_text(dt.text, dt.turn_id, dt.utterance_id)
+                            except Exception as e:
+                                self._log("gateway_display_text_error", session_id=self.session_id, metrics={"error": str(e)})
+                    elif which == 'caption':
+                        # Live captions; frequent, so not logged per message
+                        if callable(self.on_caption):
+                            try:
+                                self.on_caption(cmd.caption)
+                            except Exception as e:
+                                self._log("gateway_caption_error", session_id=self.session_id, metrics={"error": str(e)})
+                    elif which == 'token_delta':
+                        # One per LLM token; not logged per message
+                        if callable(self.on_token_delta):
+                            try:
+                                self.on_token_delta(cmd.token_delta)
+                            except Exception as e:
+                                self._log("gateway_token_delta_error", session_id=self.session_id, metrics={"error": str(e)})
+                    elif which == 'turn_state':
+                        tst = cmd.turn_state
+                        if tst.rejected:
+                            self._log("orchestrator_turn_state_rejected", session_id=self.session_id, metrics={"from": tst.from_state, "to": tst.to_state, "trigger": tst.trigger})
+                        if callable(self.on_turn_state):
+                            try:
+                                self.on_turn_state(tst)
+                            except Exception as e:
+                                self._log("gateway_turn_state_error", session_id=self.session_id, metrics={"error": str(e)})
+                    elif which == 'llm_status':
+                        ls = cmd.llm_status
+                        self._log("orchestrator_llm_status", session_id=self.session_id, reason=ls.reason, metrics={"degraded": ls.degraded, "failures": ls.failures})
+                        if callable(self.on_llm_status):
+                            try:
+                                self.on_llm_status(ls)
+                            except Exception as e:
+                                self._log("gateway_llm_status_error", session_id=self.session_id, metrics={"error": str(e)})
+                    elif which == 'profile_suggestion':
+                        ps = cmd.profile_suggestion
+                        self._log("orchestrator_profile_suggestion", session_id=self.session_id, reason=ps.profile, metrics={"current": ps.current, "noise_floor_rms": round(ps.noise_floor_rms, 1), "echo_rms": round(ps.echo_rms, 1), "applied": ps.applied})
+                        if callable(self.on_profile_suggestion):
+                            try:
+                                self.on_profile_suggestion(ps)
+                            except Exception as e:
+                                self._log("gateway_profile_suggestion_error", session_id=self.session_id, metrics={"error": str(e)})
+                    elif which == 'moderation_flag':
+                        mf = cmd.moderation_flag
+                        self._log("orchestrator_moderation_flag", session_id=self.session_id, utterance_id=mf.utterance_id, metrics={"action": mf.action, "violations": mf.violations})
+                        if callable(self.on_moderation_flag):
+                            try:
+                                self.on_moderation_flag(mf)
+                            except Exception as e:
+                                self._log("gateway_moderation_flag_error", session_id=self.session_id, metrics={"error": str(e)})
+                    elif which == 'end_interview':
+                        # Leave once the goodbye has played; the idle loop checks this
+                        self._state['end_requested'] = cmd.end_interview.reason or 'end_requested'
+                        self._log("orchestrator_end_interview", session_id=self.session_id, reason=self._state['end_requested'])
+                        if callable(self.on_end_interview):
+                            try:
+                                self.on_end_interview(self._state['end_requested'])
+                            except Exception as e:
+                                self._log("gateway_end_interview_error", session_id=self.session_id, metrics={"error": str(e)})
+                    elif which == 'ack':
+                        if cmd.ack.info == 'session_closed':
+                            self._close_acked.set()
+                    else:
+                        # join_room / unknown
+                        pass
         except asyncio.CancelledError:
             return
         except Exception as e:
@@ -281,6 +599,29 @@ class GatewayControlClient:
         except asyncio.CancelledError:
             return
 
+    async def _heartbeat_loop(self):
+        """Send a Heartbeat every GATEWAY_HEARTBEAT_SEC while the stream is up."""
+        try:
+            while not self._closed:
+                await asyncio.sleep(self._heartbeat_interval_sec)
+                if self._call is None:
+                    continue
+                self._heartbeat_seq += 1
+                ev = gw.GatewayEvent(session_id=self.session_id, heartbeat=gw.Heartbeat(seq=self._heartbeat_seq, ts_ms=int(time.time() * 1000)))
+                self._enqueue(ev)
+        except asyncio.CancelledError:
+            return
+
+    def _resend_recent_finals(self):
+        """Queues the finals written shortly before the stream broke again, right
+        after the new SessionOpen."""
+        now = time.monotonic()
+        self._recent_finals = [(t, e) for t, e in self._recent_finals if now - t <= self._resend_finals_sec]
+        for _, ev in self._recent_finals:
+            self._enqueue(ev)
+        if self._recent_finals:
+            self._log("orchestrator_finals_resent", session_id=self.session_id, metrics={"count": len(self._recent_finals)})
+
     async def _reconnect_supervisor(self):
         """Keep the control stream connected; reconnect with backoff when _call is None."""
         try:
@@ -301,15 +642,16 @@ class GatewayControlClient:
                     if self._channel is None:
                         self._channel = aio.insecure_channel(target)
                     self._stub = gw_grpc.GatewayControlStub(self._channel)
-                    call = self._stub.Session()
+                    call = self._stub.Session(metadata=self._auth_metadata())
                     # Swap in
                     self._call = call
                     # Start a fresh recv loop
                     self._recv_task = self._loop.create_task(self._recv_loop())
                     # Re-send session_open if we have it
                     if self._room_url_last:
-                        ev = gw.GatewayEvent(session_id=self.session_id, session_open=gw.SessionOpen(session_id=self.session_id, room_url=self._room_url_last))
+                        ev = gw.GatewayEvent(session_id=self.session_id, session_open=gw.SessionOpen(session_id=self.session_id, room_url=self._room_url_last, style=_style_from_env(), capabilities=GATEWAY_CAPABILITIES))
                         self._enqueue(ev)
+                        self._resend_recent_finals()
                     self._log("orchestrator_reconnected", session_id=self.session_id)
                     backoff = 0.2
                 except Exception as e:
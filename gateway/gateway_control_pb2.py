@@ -24,7 +24,7 @@ _sym_db = _symbol_database.Default()
 
 
 
-DESCRIPTOR = _descriptor_pool.Default().AddSerializedFile(b'\n\x15gateway_control.proto\x12\ngateway.v1\"3\n\x0bSessionOpen\x12\x12\n\nsession_id\x18\x01 \x01(\t\x12\x10\n\x08room_url\x18\x02 \x01(\t\"\x19\n\x08VADStart\x12\r\n\x05ts_ms\x18\x01 \x01(\x04\"\x17\n\x06VADEnd\x12\r\n\x05ts_ms\x18\x01 \x01(\x04\"7\n\x11TranscriptInterim\x12\x14\n\x0cutterance_id\x18\x01 \x01(\t\x12\x0c\n\x04text\x18\x02 \x01(\t\"5\n\x0fTranscriptFinal\x12\x14\n\x0cutterance_id\x18\x01 \x01(\t\x12\x0c\n\x04text\x18\x02 \x01(\t\"@\n\x08TTSEvent\x12\x0c\n\x04type\x18\x01 \x01(\t\x12\x0e\n\x06reason\x18\x02 \x01(\t\x12\x16\n\x0e\x66irst_audio_ms\x18\x03 \x01(\r\"-\n\x0cGatewayError\x12\x0c\n\x04\x63ode\x18\x01 \x01(\t\x12\x0f\n\x07message\x18\x02 \x01(\t\"\x1a\n\x08\x46rameTap\x12\x0e\n\x06pcm48k\x18\x01 \x01(\x0c\"\x16\n\x07\x46\x65\x61ture\x12\x0b\n\x03rms\x18\x01 \x01(\x02\"\xc5\x03\n\x0cGatewayEvent\x12\x12\n\nsession_id\x18\x01 \x01(\t\x12/\n\x0csession_open\x18\x02 \x01(\x0b\x32\x17.gateway.v1.SessionOpenH\x00\x12)\n\tvad_start\x18\x03 \x01(\x0b\x32\x14.gateway.v1.VADStartH\x00\x12%\n\x07vad_end\x18\x04 \x01(\x0b\x32\x12.gateway.v1.VADEndH\x00\x12;\n\x12transcript_interim\x18\x05 \x01(\x0b\x32\x1d.gateway.v1.TranscriptInterimH\x00\x12\x37\n\x10transcript_final\x18\x06 \x01(\x0b\x32\x1b.gateway.v1.TranscriptFinalH\x00\x12#\n\x03tts\x18\x07 \x01(\x0b\x32\x14.gateway.v1.TTSEventH\x00\x12)\n\x05\x65rror\x18\x08 \x01(\x0b\x32\x18.gateway.v1.GatewayErrorH\x00\x12)\n\tframe_tap\x18\t \x01(\x0b\x32\x14.gateway.v1.FrameTapH\x00\x12&\n\x07\x66\x65\x61ture\x18\n \x01(\x0b\x32\x13.gateway.v1.FeatureH\x00\x42\x05\n\x03\x65vt\"+\n\x08JoinRoom\x12\x10\n\x08room_url\x18\x01 \x01(\t\x12\r\n\x05token\x18\x02 \x01(\t\"\x0f\n\rStartMicToSTT\"\x0e\n\x0cStopMicToSTT\"\x18\n\x08StartTTS\x12\x0c\n\x04text\x18\x01 \x01(\t\"\x19\n\x07StopTTS\x12\x0e\n\x06reason\x18\x01 \x01(\t\"/\n\nArmBargeIn\x12\x10\n\x08guard_ms\x18\x01 \x01(\r\x12\x0f\n\x07min_rms\x18\x02 \x01(\r\"\x13\n\x03\x41\x63k\x12\x0c\n\x04info\x18\x01 \x01(\t\"\xeb\x02\n\x13OrchestratorCommand\x12\x12\n\nsession_id\x18\x01 \x01(\t\x12)\n\tjoin_room\x18\x02 \x01(\x0b\x32\x14.gateway.v1.JoinRoomH\x00\x12\x35\n\x10start_mic_to_stt\x18\x03 \x01(\x0b\x32\x19.gateway.v1.StartMicToSTTH\x00\x12\x33\n\x0fstop_mic_to_stt\x18\x04 \x01(\x0b\x32\x18.gateway.v1.StopMicToSTTH\x00\x12)\n\tstart_tts\x18\x05 \x01(\x0b\x32\x14.gateway.v1.StartTTSH\x00\x12\'\n\x08stop_tts\x18\x06 \x01(\x0b\x32\x13.gateway.v1.StopTTSH\x00\x12.\n\x0c\x61rm_barge_in\x18\x07 \x01(\x0b\x32\x16.gateway.v1.ArmBargeInH\x00\x12\x1e\n\x03\x61\x63k\x18\x08 \x01(\x0b\x32\x0f.gateway.v1.AckH\x00\x42\x05\n\x03\x63md2Z\n\x0eGatewayControl\x12H\n\x07Session\x12\x18.gateway.v1.GatewayEvent\x1a\x1f.gateway.v1.OrchestratorCommand(\x01\x30\x01\x42/Z-yuzu/agent/internal/orchestrator/pb;gatewaypbb\x06proto3')
+DESCRIPTOR = _descriptor_pool.Default().AddSerializedFile(b'\n\x15gateway_control.proto\x12\ngateway.v1\"r\n\x0bSessionOpen\x12\x12\n\nsession_id\x18\x01 \x01(\t\x12\x10\n\x08room_url\x18\x02 \x01(\t\x12\'\n\x05style\x18\x03 \x01(\x0b\x32\x18.gateway.v1.SessionStyle\x12\x14\n\x0c\x63\x61pabilities\x18\x04 \x03(\t\"\xbc\x03\n\x0cSessionStyle\x12\x0f\n\x07persona\x18\x01 \x01(\t\x12\x11\n\tverbosity\x18\x02 \x01(\t\x12\x18\n\x0btemperature\x18\x03 \x01(\x01H\x00\x88\x01\x01\x12\x12\n\nmax_tokens\x18\x04 \x01(\r\x12\x15\n\rsystem_prompt\x18\x05 \x01(\t\x12\x11\n\tflow_json\x18\x06 \x01(\t\x12\x18\n\x10\x62\x61rge_in_min_rms\x18\x07 \x01(\r\x12\x19\n\x11\x62\x61rge_in_guard_ms\x18\x08 \x01(\r\x12\x10\n\x08\x63\x61ptions\x18\t \x01(\x08\x12\x14\n\x0ctoken_stream\x18\n \x01(\x08\x12\x14\n\x0c\x63ontext_json\x18\x0b \x01(\t\x12\x16\n\x0emax_duration_s\x18\x0c \x01(\r\x12\x19\n\x11\x62\x61rge_in_hangover\x18\r \x01(\r\x12\x18\n\x10\x62\x61rge_in_profile\x18\x0e \x01(\t\x12\x14\n\x0cinstructions\x18\x0f \x01(\t\x12\x11\n\techo_test\x18\x10 \x01(\x08\x12\x16\n\x0eno_llm_logging\x18\x11 \x01(\x08\x12\x1f\n\x17no_transcript_retention\x18\x12 \x01(\x08\x42\x0e\n\x0c_temperature\"\x19\n\x08VADStart\x12\r\n\x05ts_ms\x18\x01 \x01(\x04\"\x17\n\x06VADEnd\x12\r\n\x05ts_ms\x18\x01 \x01(\x04\"w\n\x11TranscriptInterim\x12\x14\n\x0cutterance_id\x18\x01 \x01(\t\x12\x0c\n\x04text\x18\x02 \x01(\t\x12\x0f\n\x07turn_id\x18\x03 \x01(\t\x12\x16\n\x0e\x63ommitted_text\x18\x04 \x01(\t\x12\x15\n\rvolatile_text\x18\x05 \x01(\t\"\xa9\x01\n\x0fTranscriptFinal\x12\x14\n\x0cutterance_id\x18\x01 \x01(\t\x12\x0c\n\x04text\x18\x02 \x01(\t\x12\x0f\n\x07turn_id\x18\x03 \x01(\t\x12\x12\n\nword_count\x18\x04 \x01(\r\x12\x11\n\tspeech_ms\x18\x05 \x01(\r\x12\x0f\n\x07speaker\x18\x06 \x01(\r\x12\x0e\n\x06source\x18\x07 \x01(\t\x12\x19\n\x11\x61udio_fingerprint\x18\x08 \x01(\x06\"g\n\x08TTSEvent\x12\x0c\n\x04type\x18\x01 \x01(\t\x12\x0e\n\x06reason\x18\x02 \x01(\t\x12\x16\n\x0e\x66irst_audio_ms\x18\x03 \x01(\r\x12\x0f\n\x07turn_id\x18\x04 \x01(\t\x12\x14\n\x0cutterance_id\x18\x05 \x01(\t\"-\n\x0cGatewayError\x12\x0c\n\x04\x63ode\x18\x01 \x01(\t\x12\x0f\n\x07message\x18\x02 \x01(\t\"d\n\nStopTTSAck\x12\x12\n\ngeneration\x18\x01 \x01(\x04\x12\x14\n\x0cutterance_id\x18\x02 \x01(\t\x12\x0e\n\x06result\x18\x03 \x01(\t\x12\x1c\n\x14stopped_utterance_id\x18\x04 \x01(\t\"F\n\rArmBargeInAck\x12\x12\n\ngeneration\x18\x01 \x01(\x04\x12\x10\n\x08guard_ms\x18\x02 \x01(\r\x12\x0f\n\x07min_rms\x18\x03 \x01(\r\"\x1a\n\x08\x46rameTap\x12\x0e\n\x06pcm48k\x18\x01 \x01(\x0c\"\x16\n\x07\x46\x65\x61ture\x12\x0b\n\x03rms\x18\x01 \x01(\x02\"\x1e\n\x0cSessionClose\x12\x0e\n\x06reason\x18\x01 \x01(\t\"\'\n\tHeartbeat\x12\x0b\n\x03seq\x18\x01 \x01(\x04\x12\r\n\x05ts_ms\x18\x02 \x01(\x03\"\xfe\x04\n\x0cGatewayEvent\x12\x12\n\nsession_id\x18\x01 \x01(\t\x12/\n\x0csession_open\x18\x02 \x01(\x0b\x32\x17.gateway.v1.SessionOpenH\x00\x12)\n\tvad_start\x18\x03 \x01(\x0b\x32\x14.gateway.v1.VADStartH\x00\x12%\n\x07vad_end\x18\x04 \x01(\x0b\x32\x12.gateway.v1.VADEndH\x00\x12;\n\x12transcript_interim\x18\x05 \x01(\x0b\x32\x1d.gateway.v1.TranscriptInterimH\x00\x12\x37\n\x10transcript_final\x18\x06 \x01(\x0b\x32\x1b.gateway.v1.TranscriptFinalH\x00\x12#\n\x03tts\x18\x07 \x01(\x0b\x32\x14.gateway.v1.TTSEventH\x00\x12)\n\x05\x65rror\x18\x08 \x01(\x0b\x32\x18.gateway.v1.GatewayErrorH\x00\x12)\n\tframe_tap\x18\t \x01(\x0b\x32\x14.gateway.v1.FrameTapH\x00\x12&\n\x07\x66\x65\x61ture\x18\n \x01(\x0b\x32\x13.gateway.v1.FeatureH\x00\x12\x31\n\rsession_close\x18\x0b \x01(\x0b\x32\x18.gateway.v1.SessionCloseH\x00\x12*\n\theartbeat\x18\x0c \x01(\x0b\x32\x15.gateway.v1.HeartbeatH\x00\x12*\n\x08stop_ack\x18\r \x01(\x0b\x32\x16.gateway.v1.StopTTSAckH\x00\x12,\n\x07\x61rm_ack\x18\x0e \x01(\x0b\x32\x19.gateway.v1.ArmBargeInAckH\x00\x42\x05\n\x03\x65vt\"+\n\x08JoinRoom\x12\x10\n\x08room_url\x18\x01 \x01(\t\x12\r\n\x05token\x18\x02 \x01(\t\"J\n\rStartMicToSTT\x12\x0f\n\x07turn_id\x18\x01 \x01(\t\x12\x14\n\x0cutterance_id\x18\x02 \x01(\t\x12\x12\n\npreroll_ms\x18\x03 \x01(\r\"\x0e\n\x0cStopMicToSTT\"\x9e\x01\n\x08StartTTS\x12\x0c\n\x04text\x18\x01 \x01(\t\x12\x0f\n\x07turn_id\x18\x02 \x01(\t\x12\x14\n\x0cutterance_id\x18\x03 \x01(\t\x12\x10\n\x08provider\x18\x04 \x01(\t\x12\x15\n\rspeaking_rate\x18\x05 \x01(\x02\x12\x10\n\x08pause_ms\x18\x06 \x01(\r\x12\x0e\n\x06\x66iller\x18\x07 \x01(\x08\x12\x12\n\nintonation\x18\x08 \x01(\t\"f\n\x07StopTTS\x12\x0e\n\x06reason\x18\x01 \x01(\t\x12\x14\n\x0cutterance_id\x18\x02 \x01(\t\x12\x12\n\ngeneration\x18\x03 \x01(\x04\x12\x0f\n\x07\x66\x61\x64\x65_ms\x18\x04 \x01(\r\x12\x10\n\x08\x62oundary\x18\x05 \x01(\t\"F\n\nTokenDelta\x12\x0f\n\x07turn_id\x18\x01 \x01(\t\x12\x0c\n\x04text\x18\x02 \x01(\t\x12\x0b\n\x03seq\x18\x03 \x01(\r\x12\x0c\n\x04\x64one\x18\x04 \x01(\x08\"\x19\n\x07StopAll\x12\x0e\n\x06reason\x18\x01 \x01(\t\"C\n\nArmBargeIn\x12\x10\n\x08guard_ms\x18\x01 \x01(\r\x12\x0f\n\x07min_rms\x18\x02 \x01(\r\x12\x12\n\ngeneration\x18\x03 \x01(\x04\"\x13\n\x03\x41\x63k\x12\x0c\n\x04info\x18\x01 \x01(\t\"\x19\n\tSetVolume\x12\x0c\n\x04gain\x18\x01 \x01(\x02\"\x1e\n\x0c\x45ndInterview\x12\x0e\n\x06reason\x18\x01 \x01(\t\"B\n\x0b\x44isplayText\x12\x0c\n\x04text\x18\x01 \x01(\t\x12\x0f\n\x07turn_id\x18\x02 \x01(\t\x12\x14\n\x0cutterance_id\x18\x03 \x01(\t\"\x8a\x01\n\x07\x43\x61ption\x12\x0c\n\x04role\x18\x01 \x01(\t\x12\x0c\n\x04text\x18\x02 \x01(\t\x12\r\n\x05\x66inal\x18\x03 \x01(\x08\x12\x0f\n\x07turn_id\x18\x04 \x01(\t\x12\x14\n\x0cutterance_id\x18\x05 \x01(\t\x12\x16\n\x0e\x63ommitted_text\x18\x06 \x01(\t\x12\x15\n\rvolatile_text\x18\x07 \x01(\t\"o\n\x0eModerationFlag\x12\x0f\n\x07turn_id\x18\x01 \x01(\t\x12\x14\n\x0cutterance_id\x18\x02 \x01(\t\x12\x12\n\ncategories\x18\x03 \x03(\t\x12\x0e\n\x06\x61\x63tion\x18\x04 \x01(\t\x12\x12\n\nviolations\x18\x05 \x01(\r\"e\n\tTurnState\x12\x12\n\nfrom_state\x18\x01 \x01(\t\x12\x10\n\x08to_state\x18\x02 \x01(\t\x12\x0f\n\x07trigger\x18\x03 \x01(\t\x12\x0f\n\x07turn_id\x18\x04 \x01(\t\x12\x10\n\x08rejected\x18\x05 \x01(\x08\"P\n\tLLMStatus\x12\x10\n\x08\x64\x65graded\x18\x01 \x01(\x08\x12\x0e\n\x06reason\x18\x02 \x01(\t\x12\x0f\n\x07turn_id\x18\x03 \x01(\t\x12\x10\n\x08\x66\x61ilures\x18\x04 \x01(\r\"x\n\x18\x42\x61rgeInProfileSuggestion\x12\x0f\n\x07profile\x18\x01 \x01(\t\x12\x0f\n\x07\x63urrent\x18\x02 \x01(\t\x12\x17\n\x0fnoise_floor_rms\x18\x03 \x01(\x02\x12\x10\n\x08\x65\x63ho_rms\x18\x04 \x01(\x02\x12\x0f\n\x07\x61pplied\x18\x05 \x01(\x08\"\x8d\x01\n\x0e\x42\x65ginListening\x12%\n\x08stop_tts\x18\x01 \x01(\x0b\x32\x13.gateway.v1.StopTTS\x12,\n\x0c\x61rm_barge_in\x18\x02 \x01(\x0b\x32\x16.gateway.v1.ArmBargeIn\x12&\n\x03mic\x18\x03 \x01(\x0b\x32\x19.gateway.v1.StartMicToSTT\"A\n\x0c\x43ommandBatch\x12\x31\n\x08\x63ommands\x18\x01 \x03(\x0b\x32\x1f.gateway.v1.OrchestratorCommand\"\xb3\x07\n\x13OrchestratorCommand\x12\x12\n\nsession_id\x18\x01 \x01(\t\x12)\n\tjoin_room\x18\x02 \x01(\x0b\x32\x14.gateway.v1.JoinRoomH\x00\x12\x35\n\x10start_mic_to_stt\x18\x03 \x01(\x0b\x32\x19.gateway.v1.StartMicToSTTH\x00\x12\x33\n\x0fstop_mic_to_stt\x18\x04 \x01(\x0b\x32\x18.gateway.v1.StopMicToSTTH\x00\x12)\n\tstart_tts\x18\x05 \x01(\x0b\x32\x14.gateway.v1.StartTTSH\x00\x12\'\n\x08stop_tts\x18\x06 \x01(\x0b\x32\x13.gateway.v1.StopTTSH\x00\x12.\n\x0c\x61rm_barge_in\x18\x07 \x01(\x0b\x32\x16.gateway.v1.ArmBargeInH\x00\x12\x1e\n\x03\x61\x63k\x18\x08 \x01(\x0b\x32\x0f.gateway.v1.AckH\x00\x12+\n\nset_volume\x18\t \x01(\x0b\x32\x15.gateway.v1.SetVolumeH\x00\x12\x31\n\rend_interview\x18\n \x01(\x0b\x32\x18.gateway.v1.EndInterviewH\x00\x12/\n\x0c\x64isplay_text\x18\x0b \x01(\x0b\x32\x17.gateway.v1.DisplayTextH\x00\x12&\n\x07\x63\x61ption\x18\x0c \x01(\x0b\x32\x13.gateway.v1.CaptionH\x00\x12\x35\n\x0fmoderation_flag\x18\r \x01(\x0b\x32\x1a.gateway.v1.ModerationFlagH\x00\x12\'\n\x08stop_all\x18\x0e \x01(\x0b\x32\x13.gateway.v1.StopAllH\x00\x12-\n\x0btoken_delta\x18\x0f \x01(\x0b\x32\x16.gateway.v1.TokenDeltaH\x00\x12+\n\nturn_state\x18\x10 \x01(\x0b\x32\x15.gateway.v1.TurnStateH\x00\x12)\n\x05\x62\x61tch\x18\x11 \x01(\x0b\x32\x18.gateway.v1.CommandBatchH\x00\x12+\n\nllm_status\x18\x12 \x01(\x0b\x32\x15.gateway.v1.LLMStatusH\x00\x12\x35\n\x0f\x62\x65gin_listening\x18\x13 \x01(\x0b\x32\x1a.gateway.v1.BeginListeningH\x00\x12\x42\n\x12profile_suggestion\x18\x14 \x01(\x0b\x32$.gateway.v1.BargeInProfileSuggestionH\x00\x42\x05\n\x03\x63md2Z\n\x0eGatewayControl\x12H\n\x07Session\x12\x18.gateway.v1.GatewayEvent\x1a\x1f.gateway.v1.OrchestratorCommand(\x01\x30\x01\x42/Z-yuzu/agent/internal/orchestrator/pb;gatewaypbb\x06proto3')
 
 _globals = globals()
 _builder.BuildMessageAndEnumDescriptors(DESCRIPTOR, _globals)
@@ -33,41 +33,75 @@ if not _descriptor._USE_C_DESCRIPTORS:
   _globals['DESCRIPTOR']._loaded_options = None
   _globals['DESCRIPTOR']._serialized_options = b'Z-yuzu/agent/internal/orchestrator/pb;gatewaypb'
   _globals['_SESSIONOPEN']._serialized_start=37
-  _globals['_SESSIONOPEN']._serialized_end=88
-  _globals['_VADSTART']._serialized_start=90
-  _globals['_VADSTART']._serialized_end=115
-  _globals['_VADEND']._serialized_start=117
-  _globals['_VADEND']._serialized_end=140
-  _globals['_TRANSCRIPTINTERIM']._serialized_start=142
-  _globals['_TRANSCRIPTINTERIM']._serialized_end=197
-  _globals['_TRANSCRIPTFINAL']._serialized_start=199
-  _globals['_TRANSCRIPTFINAL']._serialized_end=252
-  _globals['_TTSEVENT']._serialized_start=254
-  _globals['_TTSEVENT']._serialized_end=318
-  _globals['_GATEWAYERROR']._serialized_start=320
-  _globals['_GATEWAYERROR']._serialized_end=365
-  _globals['_FRAMETAP']._serialized_start=367
-  _globals['_FRAMETAP']._serialized_end=393
-  _globals['_FEATURE']._serialized_start=395
-  _globals['_FEATURE']._serialized_end=417
-  _globals['_GATEWAYEVENT']._serialized_start=420
-  _globals['_GATEWAYEVENT']._serialized_end=873
-  _globals['_JOINROOM']._serialized_start=875
-  _globals['_JOINROOM']._serialized_end=918
-  _globals['_STARTMICTOSTT']._serialized_start=920
-  _globals['_STARTMICTOSTT']._serialized_end=935
-  _globals['_STOPMICTOSTT']._serialized_start=937
-  _globals['_STOPMICTOSTT']._serialized_end=951
-  _globals['_STARTTTS']._serialized_start=953
-  _globals['_STARTTTS']._serialized_end=977
-  _globals['_STOPTTS']._serialized_start=979
-  _globals['_STOPTTS']._serialized_end=1004
-  _globals['_ARMBARGEIN']._serialized_start=1006
-  _globals['_ARMBARGEIN']._serialized_end=1053
-  _globals['_ACK']._serialized_start=1055
-  _globals['_ACK']._serialized_end=1074
-  _globals['_ORCHESTRATORCOMMAND']._serialized_start=1077
-  _globals['_ORCHESTRATORCOMMAND']._serialized_end=1440
-  _globals['_GATEWAYCONTROL']._serialized_start=1442
-  _globals['_GATEWAYCONTROL']._serialized_end=1532
+  _globals['_SESSIONOPEN']._serialized_end=151
+  _globals['_SESSIONSTYLE']._serialized_start=154
+  _globals['_SESSIONSTYLE']._serialized_end=598
+  _globals['_VADSTART']._serialized_start=600
+  _globals['_VADSTART']._serialized_end=625
+  _globals['_VADEND']._serialized_start=627
+  _globals['_VADEND']._serialized_end=650
+  _globals['_TRANSCRIPTINTERIM']._serialized_start=652
+  _globals['_TRANSCRIPTINTERIM']._serialized_end=771
+  _globals['_TRANSCRIPTFINAL']._serialized_start=774
+  _globals['_TRANSCRIPTFINAL']._serialized_end=943
+  _globals['_TTSEVENT']._serialized_start=945
+  _globals['_TTSEVENT']._serialized_end=1048
+  _globals['_GATEWAYERROR']._serialized_start=1050
+  _globals['_GATEWAYERROR']._serialized_end=1095
+  _globals['_STOPTTSACK']._serialized_start=1097
+  _globals['_STOPTTSACK']._serialized_end=1197
+  _globals['_ARMBARGEINACK']._serialized_start=1199
+  _globals['_ARMBARGEINACK']._serialized_end=1269
+  _globals['_FRAMETAP']._serialized_start=1271
+  _globals['_FRAMETAP']._serialized_end=1297
+  _globals['_FEATURE']._serialized_start=1299
+  _globals['_FEATURE']._serialized_end=1321
+  _globals['_SESSIONCLOSE']._serialized_start=1323
+  _globals['_SESSIONCLOSE']._serialized_end=1353
+  _globals['_HEARTBEAT']._serialized_start=1355
+  _globals['_HEARTBEAT']._serialized_end=1394
+  _globals['_GATEWAYEVENT']._serialized_start=1397
+  _globals['_GATEWAYEVENT']._serialized_end=2035
+  _globals['_JOINROOM']._serialized_start=2037
+  _globals['_JOINROOM']._serialized_end=2080
+  _globals['_STARTMICTOSTT']._serialized_start=2082
+  _globals['_STARTMICTOSTT']._serialized_end=2156
+  _globals['_STOPMICTOSTT']._serialized_start=2158
+  _globals['_STOPMICTOSTT']._serialized_end=2172
+  _globals['_STARTTTS']._serialized_start=2175
+  _globals['_STARTTTS']._serialized_end=2333
+  _globals['_STOPTTS']._serialized_start=2335
+  _globals['_STOPTTS']._serialized_end=2437
+  _globals['_TOKENDELTA']._serialized_start=2439
+  _globals['_TOKENDELTA']._serialized_end=2509
+  _globals['_STOPALL']._serialized_start=2511
+  _globals['_STOPALL']._serialized_end=2536
+  _globals['_ARMBARGEIN']._serialized_start=2538
+  _globals['_ARMBARGEIN']._serialized_end=2605
+  _globals['_ACK']._serialized_start=2607
+  _globals['_ACK']._serialized_end=2626
+  _globals['_SETVOLUME']._serialized_start=2628
+  _globals['_SETVOLUME']._serialized_end=2653
+  _globals['_ENDINTERVIEW']._serialized_start=2655
+  _globals['_ENDINTERVIEW']._serialized_end=2685
+  _globals['_DISPLAYTEXT']._serialized_start=2687
+  _globals['_DISPLAYTEXT']._serialized_end=2753
+  _globals['_CAPTION']._serialized_start=2756
+  _globals['_CAPTION']._serialized_end=2894
+  _globals['_MODERATIONFLAG']._serialized_start=2896
+  _globals['_MODERATIONFLAG']._serialized_end=3007
+  _globals['_TURNSTATE']._serialized_start=3009
+  _globals['_TURNSTATE']._serialized_end=3110
+  _globals['_LLMSTATUS']._serialized_start=3112
+  _globals['_LLMSTATUS']._serialized_end=3192
+  _globals['_BARGEINPROFILESUGGESTION']._serialized_start=3194
+  _globals['_BARGEINPROFILESUGGESTION']._serialized_end=3314
+  _globals['_BEGINLISTENING']._serialized_start=3317
+  _globals['_BEGINLISTENING']._serialized_end=3458
+  _globals['_COMMANDBATCH']._serialized_start=3460
+  _globals['_COMMANDBATCH']._serialized_end=3525
+  _globals['_ORCHESTRATORCOMMAND']._serialized_start=3528
+  _globals['_ORCHESTRATORCOMMAND']._serialized_end=4475
+  _globals['_GATEWAYCONTROL']._serialized_start=4477
+  _globals['_GATEWAYCONTROL']._serialized_end=4567
 # @@protoc_insertion_point(module_scope)
@@ -24,7 +24,7 @@ _sym_db = _symbol_database.Default()
 
 
 
-DESCRIPTOR = _descriptor_pool.Default().AddSerializedFile(b'\n\tllm.proto\x12\x06llm.v1\",\n\x0b\x43hatMessage\x12\x0c\n\x04role\x18\x01 \x01(\t\x12\x0f\n\x07\x63ontent\x18\x02 \x01(\t\"\xbf\x01\n\x0cStartRequest\x12\x12\n\nsession_id\x18\x01 \x01(\t\x12\x12\n\nrequest_id\x18\x02 \x01(\t\x12\x12\n\ndeployment\x18\x03 \x01(\t\x12\x13\n\x0b\x61pi_version\x18\x04 \x01(\t\x12%\n\x08messages\x18\x05 \x03(\x0b\x32\x13.llm.v1.ChatMessage\x12\x0e\n\x06stream\x18\x06 \x01(\x08\x12\x12\n\nmax_tokens\x18\x07 \x01(\r\x12\x13\n\x0btemperature\x18\x08 \x01(\x01\"\x1c\n\x06\x43\x61ncel\x12\x12\n\nrequest_id\x18\x01 \x01(\t\"_\n\rClientMessage\x12%\n\x05start\x18\x01 \x01(\x0b\x32\x14.llm.v1.StartRequestH\x00\x12 \n\x06\x63\x61ncel\x18\x02 \x01(\x0b\x32\x0e.llm.v1.CancelH\x00\x42\x05\n\x03msg\"\x1f\n\tConnected\x12\x12\n\nsession_id\x18\x01 \x01(\t\"\x15\n\x05Token\x12\x0c\n\x04text\x18\x01 \x01(\t\"\x18\n\x08Sentence\x12\x0c\n\x04text\x18\x01 \x01(\t\"O\n\x05Usage\x12\x15\n\rprompt_tokens\x18\x01 \x01(\r\x12\x19\n\x11\x63ompletion_tokens\x18\x02 \x01(\r\x12\x14\n\x0ctotal_tokens\x18\x03 \x01(\r\"&\n\x05\x45rror\x12\x0c\n\x04\x63ode\x18\x01 \x01(\t\x12\x0f\n\x07message\x18\x02 \x01(\t\"\xc4\x01\n\rServerMessage\x12&\n\tconnected\x18\x01 \x01(\x0b\x32\x11.llm.v1.ConnectedH\x00\x12\x1e\n\x05token\x18\x02 \x01(\x0b\x32\r.llm.v1.TokenH\x00\x12$\n\x08sentence\x18\x03 \x01(\x0b\x32\x10.llm.v1.SentenceH\x00\x12\x1e\n\x05usage\x18\x04 \x01(\x0b\x32\r.llm.v1.UsageH\x00\x12\x1e\n\x05\x65rror\x18\x05 \x01(\x0b\x32\r.llm.v1.ErrorH\x00\x42\x05\n\x03msg2B\n\x03LLM\x12;\n\x07Session\x12\x15.llm.v1.ClientMessage\x1a\x15.llm.v1.ServerMessage(\x01\x30\x01\x42\"Z yuzu/agent/internal/llm/pb;llmpbb\x06proto3')
+DESCRIPTOR = _descriptor_pool.Default().AddSerializedFile(b'\n\tllm.proto\x12\x06llm.v1\",\n\x0b\x43hatMessage\x12\x0c\n\x04role\x18\x01 \x01(\t\x12\x0f\n\x07\x63ontent\x18\x02 \x01(\t\"\xe4\x01\n\x0cStartRequest\x12\x12\n\nsession_id\x18\x01 \x01(\t\x12\x12\n\nrequest_id\x18\x02 \x01(\t\x12\x12\n\ndeployment\x18\x03 \x01(\t\x12\x13\n\x0b\x61pi_version\x18\x04 \x01(\t\x12%\n\x08messages\x18\x05 \x03(\x0b\x32\x13.llm.v1.ChatMessage\x12\x0e\n\x06stream\x18\x06 \x01(\x08\x12\x12\n\nmax_tokens\x18\x07 \x01(\r\x12\x18\n\x0btemperature\x18\x08 \x01(\x01H\x00\x88\x01\x01\x12\x0e\n\x06no_log\x18\t \x01(\x08\x42\x0e\n\x0c_temperature\"\x1c\n\x06\x43\x61ncel\x12\x12\n\nrequest_id\x18\x01 \x01(\t\"_\n\rClientMessage\x12%\n\x05start\x18\x01 \x01(\x0b\x32\x14.llm.v1.StartRequestH\x00\x12 \n\x06\x63\x61ncel\x18\x02 \x01(\x0b\x32\x0e.llm.v1.CancelH\x00\x42\x05\n\x03msg\"z\n\tConnected\x12\x12\n\nsession_id\x18\x01 \x01(\t\x12\x10\n\x08provider\x18\x02 \x01(\t\x12\r\n\x05model\x18\x03 \x01(\t\x12\x10\n\x08language\x18\x04 \x01(\t\x12\x12\n\nconnect_ms\x18\x05 \x01(\r\x12\x12\n\nrequest_id\x18\x06 \x01(\t\"\x15\n\x05Token\x12\x0c\n\x04text\x18\x01 \x01(\t\"\x18\n\x08Sentence\x12\x0c\n\x04text\x18\x01 \x01(\t\"O\n\x05Usage\x12\x15\n\rprompt_tokens\x18\x01 \x01(\r\x12\x19\n\x11\x63ompletion_tokens\x18\x02 \x01(\r\x12\x14\n\x0ctotal_tokens\x18\x03 \x01(\r\"&\n\x05\x45rror\x12\x0c\n\x04\x63ode\x18\x01 \x01(\t\x12\x0f\n\x07message\x18\x02 \x01(\t\"\xc4\x01\n\rServerMessage\x12&\n\tconnected\x18\x01 \x01(\x0b\x32\x11.llm.v1.ConnectedH\x00\x12\x1e\n\x05token\x18\x02 \x01(\x0b\x32\r.llm.v1.TokenH\x00\x12$\n\x08sentence\x18\x03 \x01(\x0b\x32\x10.llm.v1.SentenceH\x00\x12\x1e\n\x05usage\x18\x04 \x01(\x0b\x32\r.llm.v1.UsageH\x00\x12\x1e\n\x05\x65rror\x18\x05 \x01(\x0b\x32\r.llm.v1.ErrorH\x00\x42\x05\n\x03msg\"\\\n\x0fModerateRequest\x12\x12\n\nsession_id\x18\x01 \x01(\t\x12\x0c\n\x04text\x18\x02 \x01(\t\x12\x12\n\ndeployment\x18\x03 \x01(\t\x12\x13\n\x0b\x61pi_version\x18\x04 \x01(\t\"7\n\x10ModerateResponse\x12\x0f\n\x07\x66lagged\x18\x01 \x01(\x08\x12\x12\n\ncategories\x18\x02 \x03(\t2\x81\x01\n\x03LLM\x12;\n\x07Session\x12\x15.llm.v1.ClientMessage\x1a\x15.llm.v1.ServerMessage(\x01\x30\x01\x12=\n\x08Moderate\x12\x17.llm.v1.ModerateRequest\x1a\x18.llm.v1.ModerateResponseB\"Z yuzu/agent/internal/llm/pb;llmpbb\x06proto3')
 
 _globals = globals()
 _builder.BuildMessageAndEnumDescriptors(DESCRIPTOR, _globals)
@@ -35,23 +35,27 @@ if not _descriptor._USE_C_DESCRIPTORS:
   _globals['_CHATMESSAGE']._serialized_start=21
   _globals['_CHATMESSAGE']._serialized_end=65
   _globals['_STARTREQUEST']._serialized_start=68
-  _globals['_STARTREQUEST']._serialized_end=259
-  _globals['_CANCEL']._serialized_start=261
-  _globals['_CANCEL']._serialized_end=289
-  _globals['_CLIENTMESSAGE']._serialized_start=291
-  _globals['_CLIENTMESSAGE']._serialized_end=386
-  _globals['_CONNECTED']._serialized_start=388
-  _globals['_CONNECTED']._serialized_end=419
-  _globals['_TOKEN']._serialized_start=421
-  _globals['_TOKEN']._serialized_end=442
-  _globals['_SENTENCE']._serialized_start=444
-  _globals['_SENTENCE']._serialized_end=468
-  _globals['_USAGE']._serialized_start=470
-  _globals['_USAGE']._serialized_end=549
-  _globals['_ERROR']._serialized_start=551
-  _globals['_ERROR']._serialized_end=589
-  _globals['_SERVERMESSAGE']._serialized_start=592
-  _globals['_SERVERMESSAGE']._serialized_end=788
-  _globals['_LLM']._serialized_start=790
-  _globals['_LLM']._serialized_end=856
+  _globals['_STARTREQUEST']._serialized_end=296
+  _globals['_CANCEL']._serialized_start=298
+  _globals['_CANCEL']._serialized_end=326
+  _globals['_CLIENTMESSAGE']._serialized_start=328
+  _globals['_CLIENTMESSAGE']._serialized_end=423
+  _globals['_CONNECTED']._serialized_start=425
+  _globals['_CONNECTED']._serialized_end=547
+  _globals['_TOKEN']._serialized_start=549
+  _globals['_TOKEN']._serialized_end=570
+  _globals['_SENTENCE']._serialized_start=572
+  _globals['_SENTENCE']._serialized_end=596
+  _globals['_USAGE']._serialized_start=598
+  _globals['_USAGE']._serialized_end=677
+  _globals['_ERROR']._serialized_start=679
+  _globals['_ERROR']._serialized_end=717
+  _globals['_SERVERMESSAGE']._serialized_start=720
+  _globals['_SERVERMESSAGE']._serialized_end=916
+  _globals['_MODERATEREQUEST']._serialized_start=918
+  _globals['_MODERATEREQUEST']._serialized_end=1010
+  _globals['_MODERATERESPONSE']._serialized_start=1012
+  _globals['_MODERATERESPONSE']._serialized_end=1067
+  _globals['_LLM']._serialized_start=1070
+  _globals['_LLM']._serialized_end=1199
 # @@protoc_insertion_point(module_scope)
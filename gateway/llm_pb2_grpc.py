@@ -39,6 +39,11 @@ class LLMStub(object):
                 request_serializer=llm__pb2.ClientMessage.SerializeToString,
                 response_deserializer=llm__pb2.ServerMessage.FromString,
                 _registered_method=True)
+        self.Moderate = channel.unary_unary(
+                '/llm.v1.LLM/Moderate',
+                request_serializer=llm__pb2.ModerateRequest.SerializeToString,
+                response_deserializer=llm__pb2.ModerateResponse.FromString,
+                _registered_method=True)
 
 
 class LLMServicer(object):
@@ -50,6 +55,12 @@ class LLMServicer(object):
         context.set_details('Method not implemented!')
         raise NotImplementedError('Method not implemented!')
 
+    def Moderate(self, request, context):
+        """Missing associated documentation comment in .proto file."""
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
 
 def add_LLMServicer_to_server(servicer, server):
     rpc_method_handlers = {
@@ -58,6 +69,11 @@ def add_LLMServicer_to_server(servicer, server):
                     request_deserializer=llm__pb2.ClientMessage.FromString,
                     response_serializer=llm__pb2.ServerMessage.SerializeToString,
             ),
+            'Moderate': grpc.unary_unary_rpc_method_handler(
+                    servicer.Moderate,
+                    request_deserializer=llm__pb2.ModerateRequest.FromString,
+                    response_serializer=llm__pb2.ModerateResponse.SerializeToString,
+            ),
     }
     generic_handler = grpc.method_handlers_generic_handler(
             'llm.v1.LLM', rpc_method_handlers)
@@ -95,3 +111,30 @@ class LLM(object):
             timeout,
             metadata,
             _registered_method=True)
+
+    @staticmethod
+    def Moderate(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(
+            request,
+            target,
+            '/llm.v1.LLM/Moderate',
+            llm__pb2.ModerateRequest.SerializeToString,
+            llm__pb2.ModerateResponse.FromString,
+            options,
+            channel_credentials,
+            insecure,
+            call_credentials,
+            compression,
+            wait_for_ready,
+            timeout,
+            metadata,
+            _registered_method=True)
@@ -4,10 +4,11 @@ import sys
 import time
 import json
 import asyncio
+import platform
 import urllib.parse
 from .gateway_control_client import GatewayControlClient
 from .stt_sidecar_client import STTSidecarClient
-from .audio_utils import RingBuffer, FrameBatcher, downsample_48k_to_16k
+from .audio_utils import RingBuffer, FrameBatcher, LoudnessNormalizer, downsample_48k_to_16k
 from .tts_client import TTSClient
 import webrtcvad
 import numpy as np
@@ -36,10 +37,12 @@ LOG_MIN_EVENTS = set((os.environ.get("LOG_MIN_EVENTS", "") or "").split(",")) if
     "tts_mode", "tts_started", "tts_first_audio", "tts_playback_done", "tts_timing_breakdown",
     "tts_producer_http_response", "tts_producer_first_chunk", "tts_producer_exception",
     "tts_prebuffer_done", "tts_consumer_underrun", "tts_stream_complete",
-    "tts_pcm_fetched", "tts_pcm_fetch_failed",
+    "tts_pcm_fetched", "tts_pcm_fetch_failed", "tts_filler_start", "tts_filler_error",
     "tts_fetch_start", "tts_fetch_connected", "tts_fetch_eof", "tts_fetch_error", "tts_fetch_exception",
     # Barge-in
-    "local_stop_triggered", "vad_start_suppressed", "barge_in_detected",
+    "local_stop_triggered", "vad_start_suppressed", "barge_in_detected", "tts_soft_stop",
+    # Backend connection
+    "ws_server_shutdown", "ws_reconnecting", "ws_reconnected", "ws_reconnect_failed",
     # VAD events (important for debugging user speech detection)
     "vad_start_fired", "vad_start_detected", "vad_end_reached_hangover",
     # Orchestrator/STT
@@ -48,7 +51,9 @@ LOG_MIN_EVENTS = set((os.environ.get("LOG_MIN_EVENTS", "") or "").split(",")) if
     "orchestrator_feature_send_failed", "orchestrator_feature_call_none",
     "orchestrator_tts_event_sent", "orchestrator_tts_event_failed", "orchestrator_tts_event_call_none",
     "orchestrator_tts_event_queued", "orchestrator_transcript_queued", "orchestrator_write_error",
-    "stt_connected", "stt_error", "stt_utterance_start", "stt_audio_sent",
+    "orchestrator_session_closed", "orchestrator_set_volume", "orchestrator_display_text", "orchestrator_moderation_flag", "orchestrator_llm_status", "orchestrator_finals_resent", "orchestrator_profile_suggestion", "orchestrator_turn_state_rejected", "orchestrator_stop_all", "stt_mic_resumed", "orchestrator_end_interview", "orchestrator_session_close_timeout", "stt_flushed", "stt_flush_error", "stt_usage", "tts_usage",
+    "ws_pause_tts", "ws_resume_tts", "tts_hold_expired", "ws_reprompt",
+    "stt_connected", "stt_provider_connected", "stt_provider_switched", "stt_error", "stt_utterance_start", "stt_audio_sent",
     "stt_transcript_final", "stt_transcript_interim",
     "stt_sending_to_orchestrator", "stt_sent_to_orchestrator", "stt_orchestrator_send_error", "stt_no_orchestrator_attached",
     # Debug
@@ -404,6 +409,9 @@ class VADManager:
         self.frame_batcher = None
         self._in_utterance = False
         self._stt_continuous = os.environ.get('STT_CONTINUOUS', 'false').lower() not in ('0','false','no')
+        # The sidecar keeps the audio before each utterance (STT_PRESPEECH_MS), so
+        # VAD-bounded mode streams between utterances too instead of replaying the ring
+        self._stt_prespeech = int(os.environ.get('STT_PRESPEECH_MS', '300') or 0) > 0
         self._stt_suppression_until = 0  # Cooldown timestamp after suppression
         # Ensure per-utterance counters exist in state (reset at utterance start elsewhere)
         self.state.setdefault('vad_counters', {'vad_starts_total': 0, 'vad_stops_allowed': 0, 'vad_suppressed_guard': 0, 'vad_suppressed_energy': 0, 'vad_suppressed_minframes': 0})
@@ -511,13 +519,13 @@ class VADManager:
                     rms_ok = rms >= stt_min_rms and (not in_cooldown or rms >= stt_min_rms * 2)
                     if rms_ok:
                         self._in_utterance = True
-                        utt_id = f"utt-{int(time.time()*1000)}"
+                        utt_id = self.state.get('orch_utterance_id') or f"utt-{int(time.time()*1000)}"
                         self.state['active_utterance_id'] = utt_id
                         log_event("debug_stt_starting_utterance", session_id=self.session_id, metrics={"utt_id": utt_id, "rms": int(rms)})
-                        # Flush ring pre-speech into batcher
+                        # Flush ring pre-speech into batcher, unless the sidecar already holds it
                         if self.ring_buffer is not None:
                             flushed = self.ring_buffer.flush_all()
-                            if flushed:
+                            if flushed and not self._stt_prespeech:
                                 ds = downsample_48k_to_16k(flushed)
                                 if self.frame_batcher is not None:
                                     self.frame_batcher.add(ds)
@@ -689,6 +697,8 @@ def attach_candidate_vad(transport, ws_queue, session_id, loop, vad, stop_event,
     manager.frame_batcher = frame_batcher
 
     started_stream = [False]
+    # Mirrors the orchestrator's Start/StopMicToSTT (half-duplex gating)
+    mic_gated = [False]
 
     # Track RMS after format conversion for diagnostics
     processed_rms_samples = []
@@ -772,6 +782,23 @@ def attach_candidate_vad(transport, ws_queue, session_id, loop, vad, stop_event,
         while len(buf) >= frame_bytes:
             frame = bytes(buf[:frame_bytes])
             del buf[:frame_bytes]
+            # Half-duplex: no STT while the agent plays; on resume after a barge-in,
+            # send the buffered pre-roll so the interrupting words aren't clipped
+            gated = state.get('mic_to_stt_enabled') is False
+            if gated != mic_gated[0]:
+                mic_gated[0] = gated
+                if not gated:
+                    preroll_ms = int(state.pop('stt_preroll_ms', 0) or 0)
+                    try:
+                        if frame_batcher is not None:
+                            if preroll_ms > 0 and ring_buffer is not None:
+                                frame_batcher.add(downsample_48k_to_16k(ring_buffer.tail(preroll_ms)))
+                            elif preroll_ms <= 0:
+                                # Whatever queued up while gated is the agent's own voice
+                                frame_batcher.flush()
+                    except Exception:
+                        pass
+                    log_event("stt_mic_resumed", session_id=session_id or "", metrics={"preroll_ms": preroll_ms})
             # Always push frame to ring buffer for STT
             try:
                 if ring_buffer is not None:
@@ -781,12 +808,13 @@ def attach_candidate_vad(transport, ws_queue, session_id, loop, vad, stop_event,
             # Stream frame to STT sidecar
             # Use run_coroutine_threadsafe since handle_frame is called from audio callback thread
             try:
-                if stt_client is not None and frame_batcher is not None:
+                if stt_client is not None and frame_batcher is not None and not gated:
                     if manager._stt_continuous:
                         # Continuous: single long-form utterance
                         if not started_stream[0]:
                             started_stream[0] = True
-                            utt = f"utt-{int(time.time()*1000)}"
+                            state['stt_stream_started'] = True
+                            utt = state.get('orch_utterance_id') or f"utt-{int(time.time()*1000)}"
                             asyncio.run_coroutine_threadsafe(stt_client.start_utterance(utt), loop)
                         # Silence gating when not in VAD utterance to avoid filling provider queue with near-zero frames
                         stt_silence_floor = int(os.environ.get('STT_SILENCE_RMS_FLOOR', '20'))
@@ -803,8 +831,9 @@ def attach_candidate_vad(transport, ws_queue, session_id, loop, vad, stop_event,
                             asyncio.run_coroutine_threadsafe(stt_client.send_audio(chunk), loop)
                             chunk = frame_batcher.emit_ready()
                     else:
-                        # VAD-bounded: only stream during active utterance
-                        if manager._in_utterance:
+                        # VAD-bounded: stream during an active utterance, and between
+                        # utterances when the sidecar buffers pre-speech
+                        if manager._in_utterance or manager._stt_prespeech:
                             ds = downsample_48k_to_16k(frame)
                             frame_batcher.add(ds)
                             chunk = frame_batcher.emit_ready()
@@ -833,6 +862,95 @@ def attach_candidate_vad(transport, ws_queue, session_id, loop, vad, stop_event,
     log_event("candidate_audio_rx_unavailable")
 
 
+def ambient_pcm48k(duration_ms: int, level: float = 0.004) -> bytes:
+    """Soft low-passed noise, faded in and out, to fill a silence without speaking."""
+    n = max(1, int(48000 * duration_ms / 1000))
+    noise = np.random.default_rng().standard_normal(n)
+    # A 1ms moving average keeps it a gentle room hum rather than hiss
+    out = np.convolve(noise, np.ones(48) / 48, mode='same')
+    out *= level * 32767 / max(1e-9, float(np.max(np.abs(out))))
+    fade = min(n // 2, 48000 // 5)
+    if fade > 0:
+        ramp = np.linspace(0.0, 1.0, fade)
+        out[:fade] *= ramp
+        out[-fade:] *= ramp[::-1]
+    return out.astype(np.int16).tobytes()
+
+
+def count_tts_usage(state: dict, text: str, frames: int):
+    """Add one synthesized sentence to the session's TTS usage. The provider
+    bills the characters whether or not playback was cut short."""
+    u = state.setdefault('tts_usage', {'sentences': 0, 'chars': 0, 'frames': 0})
+    u['sentences'] += 1
+    u['chars'] += len(text)
+    u['frames'] += int(frames or 0)
+
+
+def tts_usage_payload(state: dict) -> dict:
+    """Session TTS usage and its estimated cost at TTS_COST_PER_1K_CHARS_USD."""
+    u = state.get('tts_usage') or {'sentences': 0, 'chars': 0, 'frames': 0}
+    try:
+        rate = float(os.environ.get('TTS_COST_PER_1K_CHARS_USD', '0.30'))
+    except Exception:
+        rate = 0.30
+    return {"sentences": u['sentences'], "characters": u['chars'], "audio_seconds": round(u['frames'] * 0.02, 2),
+            "estimated_cost_usd": round(u['chars'] / 1000.0 * rate, 4)}
+
+
+# pause_tts from the backend lowers the agent's speech to TTS_DUCK_GAIN
+# ("duck") or holds it ("hold") while the candidate interjects, until
+# resume_tts; a hold the backend never resumes ends after TTS_MAX_HOLD_MS.
+TTS_DUCK_GAIN = float(os.environ.get("TTS_DUCK_GAIN", "0.3"))
+TTS_MAX_HOLD_MS = int(os.environ.get("TTS_MAX_HOLD_MS", "5000"))
+
+
+async def wait_tts_hold(stop_event, state) -> bool:
+    """Waits while a pause_tts hold is in effect; returns whether it waited."""
+    if state.get('tts_pause') != 'hold':
+        return False
+    deadline = time.monotonic() + TTS_MAX_HOLD_MS / 1000.0
+    while state.get('tts_pause') == 'hold' and not stop_event.is_set():
+        if time.monotonic() >= deadline:
+            state['tts_pause'] = None
+            log_event("tts_hold_expired", metrics={"max_hold_ms": TTS_MAX_HOLD_MS})
+            break
+        try:
+            await asyncio.wait_for(stop_event.wait(), timeout=0.02)
+        except asyncio.TimeoutError:
+            pass
+    return True
+
+
+def duck_frame(frame, state):
+    """Lowers a frame to TTS_DUCK_GAIN while a pause_tts duck is in effect."""
+    if state.get('tts_pause') != 'duck':
+        return frame
+    pcm = np.frombuffer(frame, dtype=np.int16).astype(np.float32) * TTS_DUCK_GAIN
+    return pcm.astype(np.int16).tobytes()
+
+
+def soft_stop_frame(frame, state, stop_event, session_id, utterance_id):
+    """Runs a frame through a pending word-boundary/fade stop (SoftStop, set by the
+    orchestrator client) and sets stop_event once it has wound down. An empty
+    result means stop without sending the frame."""
+    soft = state.get('soft_stop')
+    if soft is None:
+        return frame
+    frame = soft.process(frame)
+    if soft.done:
+        state['soft_stop'] = None
+        log_event("tts_soft_stop", session_id=session_id or "", utterance_id=utterance_id, metrics={
+            "waited_ms": soft.waited_ms, "at_word": soft.at_word, "fade_samples": soft.fade_samples})
+        stop_event.set()
+    return frame
+
+
+def finish_soft_stop(state, stop_event):
+    """The audio ran out before a pending soft stop finished: the stop still applies."""
+    if state.pop('soft_stop', None) is not None:
+        stop_event.set()
+
+
 async def playback_task(transport, pcm16_bytes, sr, stop_event, loop, ws_queue, session_id, utterance_id, state):
     """Send audio in 20ms frames with precise pacing, drift metrics, and early-wake stop."""
     bytes_per_sample = 2
@@ -847,10 +965,15 @@ async def playback_task(transport, pcm16_bytes, sr, stop_event, loop, ws_queue,
     next_frame_time = time.monotonic()
     tm = TTSMetrics(state.get('tts_started_ts_ms'))
     tm.begin_send_timing()
+    state['tts_pause'] = None
 
     while pos < len(pcm16_bytes):
         if stop_event.is_set():
             break
+        if await wait_tts_hold(stop_event, state):
+            next_frame_time = time.monotonic()
+            if stop_event.is_set():
+                break
         now = time.monotonic()
         sleep_time = next_frame_time - now
         if sleep_time > 0:
@@ -864,6 +987,10 @@ async def playback_task(transport, pcm16_bytes, sr, stop_event, loop, ws_queue,
         next_frame_time += frame_duration
         chunk = pcm16_bytes[pos:pos + bytes_per_frame]
         pos += bytes_per_frame
+        if not chunk:
+            break
+        chunk = duck_frame(chunk, state)
+        chunk = soft_stop_frame(chunk, state, stop_event, session_id, utterance_id)
         if not chunk:
             break
         try:
@@ -885,6 +1012,7 @@ async def playback_task(transport, pcm16_bytes, sr, stop_event, loop, ws_queue,
         except Exception as e:
             eprint("publish error:", e)
             raise
+    finish_soft_stop(state, stop_event)
 
     duration_ms = int(time.time() * 1000) - started_ms
     log_event("audio_publish_summary", metrics={
@@ -1009,8 +1137,35 @@ def slice_frames(pcm16_bytes: bytearray, frame_bytes: int):
         yield chunk
 
 
-def _producer_stream_elevenlabs(eleven_api_key, voice_id, text, loop, queue, stop_flag: threading.Event, metrics):
-    """Blocking producer: streams raw PCM from ElevenLabs and pushes 20ms PCM16@48k frames via the loop to an asyncio.Queue with backpressure."""
+_PROSODY_KEYS = ("stability", "similarity_boost", "style")
+
+
+def _parse_prosody(env: str, default: str) -> dict:
+    """Voice settings for an intonation, as the TTS service reads them (internal/tts/prosody.go)."""
+    v = (os.environ.get(env) or "").strip() or default
+    if v == "off":
+        return {}
+    out = {}
+    for pair in v.split(","):
+        k, _, val = pair.strip().partition("=")
+        try:
+            f = float(val)
+        except ValueError:
+            continue
+        if k in _PROSODY_KEYS and 0 <= f <= 1:
+            out[k] = f
+    return out
+
+
+_PROSODY_HINTS = {
+    "question": _parse_prosody("TTS_PROSODY_QUESTION", "stability=0.4"),
+    "exclamation": _parse_prosody("TTS_PROSODY_EXCLAMATION", "stability=0.3,style=0.4"),
+}
+
+
+def _producer_stream_elevenlabs(eleven_api_key, voice_id, text, loop, queue, stop_flag: threading.Event, metrics, speed: float = 0.0, normalizer=None, intonation: str = ""):
+    """Blocking producer: streams raw PCM from ElevenLabs and pushes 20ms PCM16@48k frames via the loop to an asyncio.Queue with backpressure.
+    normalizer, when given, evens out each frame's level (TTS_NORMALIZE)."""
     import requests
     # Use native 48kHz PCM format - no resampling needed
     pcm_sample_rate = 48000
@@ -1020,6 +1175,12 @@ def _producer_stream_elevenlabs(eleven_api_key, voice_id, text, loop, queue, sto
         "content-type": "application/json",
     }
     data = {"text": text}
+    voice_settings = dict(_PROSODY_HINTS.get(intonation) or {})
+    if speed:
+        # Mirror the user's pace (orchestrator StartTTS.speaking_rate)
+        voice_settings["speed"] = speed
+    if voice_settings:
+        data["voice_settings"] = voice_settings
     frame_bytes_48k = int(48000 * 0.02) * 2  # 20ms @ 48kHz, 16-bit = 1920 bytes
     out_buf = bytearray()
     raw_buf = bytearray()  # Buffer for unaligned incoming bytes
@@ -1055,6 +1216,8 @@ def _producer_stream_elevenlabs(eleven_api_key, voice_id, text, loop, queue, sto
                 out_buf.extend(aligned_bytes)
                 # Emit complete 20ms frames with backpressure
                 for frm in slice_frames(out_buf, frame_bytes_48k):
+                    if normalizer is not None:
+                        frm = normalizer.process(frm)
                     if metrics.producer_first_frame_queued_ts_ms is None:
                         metrics.mark_producer_first_frame_queued()
                         log_event("tts_producer_first_frame_queued")
@@ -1089,10 +1252,15 @@ async def tts_streaming_play(loop, transport, eleven_api_key, voice_id, text, st
     frame_bytes = int(48000 * 0.02) * 2
     tm = TTSMetrics(state.get('tts_started_ts_ms'))
     stop_flag = threading.Event()
+    # One normalizer per session, so its level estimate carries across sentences
+    if 'tts_normalizer' not in state:
+        state['tts_normalizer'] = LoudnessNormalizer.from_env()
+    normalizer = state['tts_normalizer']
 
     def start_producer():
         log_event("tts_producer_start", session_id=session_id or "", utterance_id=utterance_id)
-        _producer_stream_elevenlabs(eleven_api_key, voice_id, text, loop, queue, stop_flag, tm)
+        _producer_stream_elevenlabs(eleven_api_key, voice_id, text, loop, queue, stop_flag, tm, speed=state.get('orch_tts_speaking_rate') or 0.0, normalizer=normalizer,
+                                    intonation=state.get('orch_tts_intonation') or "")
         log_event("tts_producer_finished", session_id=session_id or "", utterance_id=utterance_id)
 
     # Start producer in threadpool
@@ -1120,6 +1288,7 @@ async def tts_streaming_play(loop, transport, eleven_api_key, voice_id, text, st
     tm.mark_prebuffer_done()
 
     # Consumer loop with monotonic timing for consistent frame rate
+    state['tts_pause'] = None
     sent_frames = 0
     first_audio_emitted = False
     completed_normally = False
@@ -1157,6 +1326,11 @@ async def tts_streaming_play(loop, transport, eleven_api_key, voice_id, text, st
                 log_event("tts_stream_complete", session_id=session_id or "", utterance_id=utterance_id)
                 completed_normally = True
                 break
+            if await wait_tts_hold(stop_event, state):
+                next_frame_time = time.monotonic()
+                if stop_event.is_set():
+                    break
+            frm = duck_frame(frm, state)
 
             # Wait until it's time to send this frame (monotonic timing)
             now = time.monotonic()
@@ -1171,6 +1345,11 @@ async def tts_streaming_play(loop, transport, eleven_api_key, voice_id, text, st
                     except asyncio.TimeoutError:
                         pass
 
+            # Wind down here rather than at the sleep, which a stop cuts short
+            frm = soft_stop_frame(frm, state, stop_event, session_id, utterance_id)
+            if not frm:
+                break
+
             # Send frame
             try:
                 if hasattr(transport, 'send_audio_pcm16'):
@@ -1209,6 +1388,7 @@ async def tts_streaming_play(loop, transport, eleven_api_key, voice_id, text, st
             qsz = queue.qsize()
             tm.add_queue_sample(qsz)
             next_frame_time += frame_duration  # Schedule next frame exactly 20ms later
+        finish_soft_stop(state, stop_event)
         # Emit tts_stopped with appropriate reason and include VAD/RMS profiling
         if session_id and not state.get('tts_stop_emitted', False):
             now_ts = int(time.time() * 1000)
@@ -1255,6 +1435,7 @@ async def tts_streaming_play(loop, transport, eleven_api_key, voice_id, text, st
             except Exception:
                 pass
         log_event("tts_playback_done", session_id=session_id or "", utterance_id=utterance_id, metrics={"sent_frames": sent_frames, "completed_normally": completed_normally})
+        state['tts_last_sent_frames'] = sent_frames
     finally:
         # Adapt prebuffer for next utterance based on underruns
         try:
@@ -1307,6 +1488,18 @@ class DailyTransportWrapper(daily.EventHandler):
         self._participant_joined_flag = threading.Event()
         self._user_participant_id = None
         self._use_participant_audio = False  # When True, use per-participant audio instead of speaker
+        self.output_gain = 1.0  # Playback gain from the orchestrator's SetVolume
+        self.aec_enabled = None  # Whether Daily runs echo cancellation on bot-mic; set by connect()
+
+    def audio_device(self):
+        """The virtual devices connect() set up, as reported in worker_device."""
+        def describe(dev, name):
+            sr = getattr(dev, 'sample_rate', 0) or 0
+            ch = getattr(dev, 'channels', 0) or 0
+            return f"{getattr(dev, 'name', '') or name} {sr // 1000}k {'mono' if ch == 1 else f'{ch}ch'}"
+        if self.mic is None or self.speaker is None:
+            return ""
+        return f"daily:{describe(self.mic, 'bot-mic')}/{describe(self.speaker, 'bot-speaker')}"
 
     def connect(self):
         # Create virtual microphone for sending audio (via Daily factory)
@@ -1354,6 +1547,7 @@ class DailyTransportWrapper(daily.EventHandler):
                     }
                 }
             })
+            self.aec_enabled = False
             log_event("daily_mic_enabled", metrics={"audio_processing": "disabled"})
         except Exception as e:
             # Fallback: enable mic without custom constraints
@@ -1366,6 +1560,7 @@ class DailyTransportWrapper(daily.EventHandler):
                     }
                 }
             })
+            self.aec_enabled = True
             log_event("daily_mic_enabled", metrics={"audio_processing": "default"})
 
         # Start a background reader to pull frames from the virtual speaker
@@ -1447,6 +1642,23 @@ class DailyTransportWrapper(daily.EventHandler):
         t = threading.Thread(target=_speaker_reader, daemon=True)
         t.start()
 
+    def send_text(self, text: str, turn_id: str, utterance_id: str):
+        """Show an agent sentence in the room as an app message for the client to render as chat/captions."""
+        self.client.send_app_message({"type": "agent_text", "text": text, "turn_id": turn_id, "utterance_id": utterance_id})
+
+    def send_caption(self, role: str, text: str, final: bool, turn_id: str, utterance_id: str, committed_text: str = "", volatile_text: str = ""):
+        """Send a live caption to the room as an app message. Interims that
+        STT split carry committed_text (settled) and volatile_text."""
+        msg = {"type": "caption", "role": role, "text": text, "final": final, "turn_id": turn_id, "utterance_id": utterance_id}
+        if committed_text or volatile_text:
+            msg["committed_text"] = committed_text
+            msg["volatile_text"] = volatile_text
+        self.client.send_app_message(msg)
+
+    def send_token_delta(self, turn_id: str, text: str, seq: int, done: bool):
+        """Send a piece of the agent's reply as it is generated; done marks the end."""
+        self.client.send_app_message({"type": "token_delta", "turn_id": turn_id, "text": text, "seq": seq, "done": done})
+
     def _on_joined(self, data, error):
         if error:
             log_event("daily_join_error", metrics={"error": str(error)})
@@ -1623,6 +1835,9 @@ class DailyTransportWrapper(daily.EventHandler):
         """Send PCM16 audio to the room."""
         if self.mic:
             try:
+                if self.output_gain != 1.0:
+                    pcm = np.frombuffer(chunk, dtype=np.int16).astype(np.float32) * self.output_gain
+                    chunk = np.clip(pcm, -32768, 32767).astype(np.int16).tobytes()
                 # daily-python VirtualMicrophoneDevice.write_frames takes raw bytes directly
                 self.mic.write_frames(chunk)
             except Exception as e:
@@ -1646,26 +1861,81 @@ def try_join_daily(room_url, token):
 
 
 async def run_ws(ws_url, worker_token, session_id, ws_queue, stop_event, state):
+    """Keeps the backend connection; when a replica drains (server_shutdown,
+    then close 1001) reconnects, reaching another replica through the load
+    balancer, up to WS_RECONNECT_ATTEMPTS times with backoff. Events queued
+    meanwhile are sent once reconnected."""
     if not ws_url:
         return
+    try:
+        attempts = int(os.environ.get("WS_RECONNECT_ATTEMPTS", "5"))
+    except Exception:
+        attempts = 5
+    tries = 0
+    while True:
+        try:
+            drained = await run_ws_once(ws_url, worker_token, session_id, ws_queue, stop_event, state)
+            if drained:
+                tries = 0
+        except Exception as e:
+            if tries == 0 and not state.get('ws_drained'):
+                raise
+            log_event("ws_reconnect_failed", session_id=session_id or "", metrics={"attempt": tries, "error": str(e)})
+            drained = True
+        if not drained or tries >= attempts:
+            return
+        tries += 1
+        delay = min(0.5 * (2 ** (tries - 1)), 8.0)
+        log_event("ws_reconnecting", session_id=session_id or "", metrics={"attempt": tries, "delay_ms": int(delay * 1000)})
+        await asyncio.sleep(delay)
+
+
+def _worker_device(state):
+    """The environment reported in worker_hello, shown in GET /sessions/{id}.
+
+    audio_device and aec_enabled are left out until the Daily devices are
+    set up; the join reports them in a worker_device message, and hellos
+    sent after it (reconnects) carry them.
+    """
+    device = {
+        "os": platform.platform(),
+        "gateway_version": os.environ.get("GATEWAY_VERSION", "dev"),
+    }
+    if state.get('audio_device'):
+        device["audio_device"] = state['audio_device']
+    if state.get('aec_enabled') is not None:
+        device["aec_enabled"] = state['aec_enabled']
+    return device
+
+
+async def run_ws_once(ws_url, worker_token, session_id, ws_queue, stop_event, state):
+    """Runs one connection; returns True when the backend drained it."""
     import websockets
     headers = {}
     if worker_token:
         headers["Authorization"] = f"Bearer {worker_token}"
+    drained = False
     async with websockets.connect(ws_url, extra_headers=headers) as ws:
+        if state.get('ws_drained'):
+            log_event("ws_reconnected", session_id=session_id or "")
+        state['ws_drained'] = False
         seq = 1
+        # seq restarts per connection; the epoch tells the backend which run it belongs to
+        epoch = int(time.time() * 1000)
         hello = {
             "type": "worker_hello",
             "ts_ms": int(time.time() * 1000),
             "session_id": session_id or "",
             "seq": seq,
-            "payload": {"version": "p1", "transport": "pipecat", "audio_format": "pcm16_48k_mono", "local_stop_capable": True}
+            "epoch": epoch,
+            "payload": {"version": "p1", "transport": "pipecat", "audio_format": "pcm16_48k_mono", "local_stop_capable": True,
+                        "device": _worker_device(state)}
         }
         seq += 1
         await ws.send(json.dumps(hello))
 
         async def reader():
-            nonlocal seq
+            nonlocal seq, drained
             async for raw in ws:
                 try:
                     msg = json.loads(raw)
@@ -1673,11 +1943,66 @@ async def run_ws(ws_url, worker_token, session_id, ws_queue, stop_event, state):
                     continue
                 t = msg.get("type")
                 if t == "stop_tts":
+                    state['tts_pause'] = None
                     stop_event.set()
                     cmd_id = msg.get("command_id")
-                    ack = {"type": "cmd_ack", "ts_ms": int(time.time() * 1000), "session_id": session_id or "", "seq": seq, "command_id": cmd_id, "payload": {"ack": True, "error": ""}}
+                    ack = {"type": "cmd_ack", "ts_ms": int(time.time() * 1000), "session_id": session_id or "", "seq": seq, "epoch": epoch, "command_id": cmd_id, "payload": {"ack": True, "error": ""}}
                     seq += 1
                     await ws.send(json.dumps(ack))
+                elif t == "server_shutdown":
+                    # The replica is draining: stop speaking, ack once quiet
+                    # (bounded), and reconnect after it closes with 1001
+                    drained = True
+                    state['ws_drained'] = True
+                    state['tts_pause'] = None
+                    stop_event.set()
+                    t0 = time.time()
+                    while state.get('speaking') and time.time() - t0 < 0.5:
+                        await asyncio.sleep(0.02)
+                    cmd_id = msg.get("command_id")
+                    log_event("ws_server_shutdown", session_id=session_id or "", metrics={"reason": (msg.get("payload") or {}).get("reason", ""), "quiet_ms": int((time.time() - t0) * 1000)})
+                    ack = {"type": "cmd_ack", "ts_ms": int(time.time() * 1000), "session_id": session_id or "", "seq": seq, "epoch": epoch, "command_id": cmd_id, "payload": {"ack": True, "error": ""}}
+                    seq += 1
+                    await ws.send(json.dumps(ack))
+                elif t in ("pause_tts", "resume_tts"):
+                    # Duck or hold the agent for an interjection, or bring it back
+                    cmd_id = msg.get("command_id")
+                    target = msg.get("utterance_id") or ""
+                    mode = (msg.get("payload") or {}).get("mode") or "hold"
+                    err = ""
+                    if not state.get('speaking'):
+                        err = "not_playing"
+                    elif target and target != state.get('playing_agent_utterance_id'):
+                        err = "stale"
+                    else:
+                        state['tts_pause'] = mode if t == "pause_tts" else None
+                    log_event("ws_" + t, session_id=session_id or "", utterance_id=target, metrics={"mode": mode if t == "pause_tts" else "", "error": err})
+                    ack = {"type": "cmd_ack", "ts_ms": int(time.time() * 1000), "session_id": session_id or "", "seq": seq, "epoch": epoch, "command_id": cmd_id, "payload": {"ack": not err, "error": err}}
+                    seq += 1
+                    await ws.send(json.dumps(ack))
+                elif t == "reprompt":
+                    # The candidate has gone quiet; the session's turn policy
+                    # asks the agent to prompt again
+                    cmd_id = msg.get("command_id")
+                    text = (msg.get("payload") or {}).get("text") or ""
+                    say = state.get('say_reprompt')
+                    err = ""
+                    if say is None:
+                        err = "unsupported"
+                    elif state.get('speaking'):
+                        err = "speaking"
+                    elif not text:
+                        err = "empty"
+                    else:
+                        asyncio.create_task(say(text))
+                    log_event("ws_reprompt", session_id=session_id or "", metrics={"text_len": len(text), "error": err})
+                    ack = {"type": "cmd_ack", "ts_ms": int(time.time() * 1000), "session_id": session_id or "", "seq": seq, "epoch": epoch, "command_id": cmd_id, "payload": {"ack": not err, "error": err}}
+                    seq += 1
+                    await ws.send(json.dumps(ack))
+                elif t == "error":
+                    # Backend rejected one of our messages; surface the reason for debugging
+                    p = msg.get("payload") or {}
+                    log_event("ws_message_rejected", session_id=session_id or "", metrics={"reason": p.get("reason", ""), "field": p.get("field", ""), "message": p.get("message", ""), "in_reply_to": p.get("in_reply_to")})
                 elif t == "policy":
                     try:
                         p = msg.get("payload") or {}
@@ -1691,10 +2016,20 @@ async def run_ws(ws_url, worker_token, session_id, ws_queue, stop_event, state):
             while True:
                 e = await ws_queue.get()
                 e["seq"] = seq
+                e["epoch"] = epoch
                 seq += 1
                 await ws.send(json.dumps(e))
 
-        await asyncio.gather(reader(), writer())
+        # The reader ends when the socket closes; the writer would wait on
+        # the queue forever
+        tasks = [asyncio.create_task(reader()), asyncio.create_task(writer())]
+        done, pending = await asyncio.wait(tasks, return_when=asyncio.FIRST_COMPLETED)
+        for task in pending:
+            task.cancel()
+        for task in done:
+            if task.exception() and not drained:
+                raise task.exception()
+    return drained
 
 
 async def main():
@@ -1721,7 +2056,7 @@ async def main():
     ws_queue = asyncio.Queue()
     stop_event = asyncio.Event()
     # Shared worker state for local-stop logic (init early so WS policy can update it)
-    state = {'speaking': False, 'active_utterance_id': '', 'last_vad_ts_ms': 0, 'tts_stop_emitted': False}
+    state = {'speaking': False, 'active_utterance_id': '', 'playing_agent_utterance_id': '', 'last_vad_ts_ms': 0, 'tts_stop_emitted': False}
     state['last_activity_ms'] = int(time.time() * 1000)
     state['local_stop_enabled'] = os.environ.get('LOCAL_STOP_ENABLED', 'true').lower() not in ('0', 'false', 'no')
     # Guard to avoid barge-in before users hear anything; default 500ms (tunable)
@@ -1753,7 +2088,13 @@ async def main():
     loop = asyncio.get_running_loop()
     try:
         transport = await loop.run_in_executor(None, try_join_daily, room_url, token)
+        state['aec_enabled'] = getattr(transport, 'aec_enabled', None)
+        state['audio_device'] = transport.audio_device()
         log_event("bot_joined", session_id=session_id or "")
+        if ws_url:
+            # The first hello went out before the join; send what it lacked
+            await ws_queue.put({"type": "worker_device", "ts_ms": int(time.time() * 1000), "session_id": session_id or "",
+                                "payload": {"device": _worker_device(state)}})
     except Exception as e:
         eprint("join failed:", e)
         log_event("bot_exit")
@@ -1808,9 +2149,21 @@ async def main():
             phrase_text = " ".join(buf).strip()
             if not phrase_text:
                 return
-            # New utterance id per flush
-            utterance_id2 = f"u-{int(time.time()*1000)}"
+            # Pause before the sentence as long as the orchestrator asked, counting time already idle
+            pause_ms = int(state.get('orch_tts_pause_ms') or 0)
+            idle_ms = int(time.time() * 1000) - int(state.get('tts_last_end_ms') or 0)
+            if 0 < idle_ms < pause_ms:
+                await asyncio.sleep((pause_ms - idle_ms) / 1000.0)
+            # Utterance id per flush: the orchestrator-issued one for the latest sentence
+            utterance_id2 = state.get('orch_tts_utterance_id') or f"u-{int(time.time()*1000)}"
+            if session_id:
+                # Record the agent side of the turn for transcripts/exports
+                await ws_queue.put({"type": "agent_text", "ts_ms": int(time.time() * 1000), "session_id": session_id,
+                                    "utterance_id": utterance_id2,
+                                    "payload": {"text": phrase_text, "turn_id": state.get('orch_tts_turn_id', '')}})
             state['active_utterance_id'] = utterance_id2
+            # VAD start overwrites active_utterance_id with the user's; stops target this one
+            state['playing_agent_utterance_id'] = utterance_id2
             state['tts_started_ts_ms'] = int(time.time() * 1000)
             state['tts_stop_emitted'] = False
             state['speaking'] = True
@@ -1823,16 +2176,78 @@ async def main():
                     await oc.send_tts_event('started')
             except Exception:
                 pass
+            state['tts_last_sent_frames'] = 0
+            provider = state.get('orch_tts_provider') or ''
             try:
-                # Use streaming playback for smoother pacing
-                await tts_streaming_play(loop, transport, eleven_api_key, voice_id_env, phrase_text, stop_event, ws_queue, session_id, utterance_id2, state)
+                if provider == 'service':
+                    # Re-routed by the orchestrator: synthesize via the TTS service, which retries the provider
+                    pcm = await tts_client.fetch_pcm48k(session_id or "", voice_id_env, phrase_text, speaking_rate=state.get('orch_tts_speaking_rate') or 0.0,
+                                                        intonation=state.get('orch_tts_intonation') or "")
+                    if pcm:
+                        await playback_task(transport, pcm, 48000, stop_event, loop, ws_queue, session_id, utterance_id2, state)
+                        state['tts_last_sent_frames'] = len(pcm) // 1920
+                else:
+                    # Use streaming playback for smoother pacing
+                    await tts_streaming_play(loop, transport, eleven_api_key, voice_id_env, phrase_text, stop_event, ws_queue, session_id, utterance_id2, state)
             except Exception:
                 log_event("tts_streaming_play_error", session_id=session_id or "", utterance_id=utterance_id2)
             finally:
                 state['speaking'] = False
                 state['active_utterance_id'] = ''
+                state['playing_agent_utterance_id'] = ''
+                state['tts_last_end_ms'] = int(time.time() * 1000)
+            count_tts_usage(state, phrase_text, state.get('tts_last_sent_frames'))
+            # No audio and not interrupted: let the orchestrator re-route or re-queue the sentences
+            if not state.get('tts_last_sent_frames') and not stop_event.is_set():
+                log_event("tts_failed", session_id=session_id or "", utterance_id=utterance_id2, metrics={"provider": provider or "elevenlabs_stream"})
+                try:
+                    oc = state.get('orch_client')
+                    if oc is not None:
+                        await oc.send_tts_event('failed', reason='no_audio')
+                except Exception:
+                    pass
+
+        async def _play_filler(text: str):
+            # Filler while the LLM is slow: a short phrase, or soft ambient noise when text is empty.
+            # The orchestrator stops it (StopTTS filler_superseded) once the reply is ready.
+            utterance_id_f = state.get('orch_tts_utterance_id') or f"u-{int(time.time()*1000)}"
+            state['filler_playing'] = True
+            state['active_utterance_id'] = utterance_id_f
+            state['playing_agent_utterance_id'] = utterance_id_f
+            state['tts_started_ts_ms'] = int(time.time() * 1000)
+            state['tts_stop_emitted'] = False
+            state['speaking'] = True
+            log_event("tts_filler_start", session_id=session_id or "", utterance_id=utterance_id_f, metrics={"ambient": not text})
+            try:
+                oc = state.get('orch_client')
+                if oc is not None:
+                    await oc.send_tts_event('started')
+            except Exception:
+                pass
+            try:
+                if text:
+                    await tts_streaming_play(loop, transport, eleven_api_key, voice_id_env, text, stop_event, ws_queue, session_id, utterance_id_f, state)
+                    count_tts_usage(state, text, state.get('tts_last_sent_frames'))
+                else:
+                    try:
+                        max_ms = int(os.environ.get('FILLER_AMBIENT_MAX_MS', '4000'))
+                    except Exception:
+                        max_ms = 4000
+                    await playback_task(transport, ambient_pcm48k(max_ms), 48000, stop_event, loop, ws_queue, session_id, utterance_id_f, state)
+                    stop_event.clear()
+            except Exception:
+                log_event("tts_filler_error", session_id=session_id or "", utterance_id=utterance_id_f)
+            finally:
+                state['filler_playing'] = False
+                state['speaking'] = False
+                state['active_utterance_id'] = ''
+                state['playing_agent_utterance_id'] = ''
+                state['tts_last_end_ms'] = int(time.time() * 1000)
 
         async def _on_start_tts(text: str):
+            if state.get('orch_tts_filler'):
+                asyncio.create_task(_play_filler(text))
+                return
             # Accumulate short sentences briefly to avoid staccato speech
             state.setdefault('tts_accum_buf', []).append(text)
             # Mark activity on LLM sentence
@@ -1852,6 +2267,78 @@ async def main():
             state['tts_accum_task'] = asyncio.create_task(_delayed_flush())
 
         orch.on_start_tts = _on_start_tts
+
+        async def _say_reprompt(text: str):
+            # A reprompt from the backend's turn policy is spoken like a reply
+            # sentence, under the agent's last utterance id
+            state['orch_tts_filler'] = False
+            await _on_start_tts(text)
+        state['say_reprompt'] = _say_reprompt
+
+        def _on_set_volume(gain: float):
+            transport.output_gain = gain
+        orch.on_set_volume = _on_set_volume
+
+        # Text-only fallback while TTS is down
+        def _on_display_text(text: str, turn_id: str, utterance_id: str):
+            transport.send_text(text, turn_id, utterance_id)
+        orch.on_display_text = _on_display_text
+
+        def _on_caption(c):
+            transport.send_caption(c.role, c.text, c.final, c.turn_id, c.utterance_id, c.committed_text, c.volatile_text)
+        orch.on_caption = _on_caption
+
+        def _on_token_delta(d):
+            transport.send_token_delta(d.turn_id, d.text, d.seq, d.done)
+        orch.on_token_delta = _on_token_delta
+
+        # Audit trail for flagged candidate speech
+        def _on_moderation_flag(mf):
+            if session_id:
+                ws_queue.put_nowait({"type": "moderation_flagged", "ts_ms": int(time.time() * 1000), "session_id": session_id,
+                                     "utterance_id": mf.utterance_id,
+                                     "payload": {"turn_id": mf.turn_id, "categories": list(mf.categories), "action": mf.action, "violations": mf.violations}})
+        orch.on_moderation_flag = _on_moderation_flag
+
+        def _on_turn_state(ts):
+            if session_id:
+                ws_queue.put_nowait({"type": "turn_state", "ts_ms": int(time.time() * 1000), "session_id": session_id,
+                                     "payload": {"from": ts.from_state, "to": ts.to_state, "trigger": ts.trigger,
+                                                 "turn_id": ts.turn_id, "rejected": ts.rejected}})
+        orch.on_turn_state = _on_turn_state
+
+        # Degraded mode: the agent is speaking canned lines while the LLM is down
+        def _on_llm_status(ls):
+            if session_id:
+                ws_queue.put_nowait({"type": "llm_status", "ts_ms": int(time.time() * 1000), "session_id": session_id,
+                                     "payload": {"degraded": ls.degraded, "reason": ls.reason, "turn_id": ls.turn_id,
+                                                 "failures": ls.failures}})
+        orch.on_llm_status = _on_llm_status
+
+        # Barge-in profile that fits the candidate's mic, from the first seconds of audio
+        def _on_profile_suggestion(ps):
+            if session_id:
+                ws_queue.put_nowait({"type": "barge_in_profile_suggested", "ts_ms": int(time.time() * 1000), "session_id": session_id,
+                                     "payload": {"profile": ps.profile, "current": ps.current, "applied": ps.applied,
+                                                 "noise_floor_rms": round(ps.noise_floor_rms, 1), "echo_rms": round(ps.echo_rms, 1)}})
+        orch.on_profile_suggestion = _on_profile_suggestion
+
+        # The orchestrator ended the interview (time limit, candidate request,
+        # moderation); the API marks the session completed and cleans up
+        def _on_end_interview(reason: str):
+            if session_id:
+                ws_queue.put_nowait({"type": "interview_ended", "ts_ms": int(time.time() * 1000), "session_id": session_id,
+                                     "payload": {"reason": reason}})
+        orch.on_end_interview = _on_end_interview
+
+        # StopAll: drop sentences still waiting for the debounce flush; the
+        # client sets stop_event, which cuts playback and any filler
+        def _on_stop_all(reason: str):
+            state['tts_accum_buf'] = []
+            t = state.get('tts_accum_task')
+            if t and not t.done():
+                t.cancel()
+        orch.on_stop_all = _on_stop_all
     except Exception as e:
         log_event("orchestrator_connect_error", session_id=session_id or "", metrics={"error": str(e)})
 
@@ -1877,6 +2364,13 @@ async def main():
             if orch:
                 stt_client.attach_orchestrator(orch)
                 log_event("debug_stt_attached_orch", session_id=session_id or "")
+
+                async def _on_utterance_id(utt_id: str):
+                    # Continuous streams are already open: re-key them to the newly issued ID.
+                    # VAD-bounded utterances pick the ID up from state when they start.
+                    if state.get('stt_stream_started') and stt_client is not None:
+                        await stt_client.start_utterance(utt_id)
+                orch.on_utterance_id = _on_utterance_id
             log_event("debug_stt_preconnecting", session_id=session_id or "")
             await stt_client.preconnect()
             log_event("debug_stt_preconnected", session_id=session_id or "")
@@ -1913,6 +2407,7 @@ async def main():
     state['guard_elapsed_logged'] = False
     utterance_id = f"u-{int(time.time()*1000)}"
     state['active_utterance_id'] = utterance_id
+    state['playing_agent_utterance_id'] = utterance_id
     state['tts_started_ts_ms'] = int(time.time() * 1000)
     if session_id:
         await ws_queue.put({
@@ -1937,6 +2432,7 @@ async def main():
         if use_streaming:
             log_event("tts_streaming_mode", session_id=session_id or "", utterance_id=utterance_id)
             await tts_streaming_play(loop, transport, eleven_api_key, voice_id, phrase, stop_event, ws_queue, session_id, utterance_id, state)
+            count_tts_usage(state, phrase, state.get('tts_last_sent_frames'))
         else:
             # Fallback: fetch-then-play
             log_event("tts_fetch_start", session_id=session_id or "", utterance_id=utterance_id)
@@ -1946,6 +2442,7 @@ async def main():
             pcm16_bytes = pcm_arr_48k.tobytes()
             log_event("tts_fetch_done", session_id=session_id or "", utterance_id=utterance_id, metrics={"bytes": len(pcm16_bytes)})
             await playback_task(transport, pcm16_bytes, 48000, stop_event, loop, ws_queue, session_id, utterance_id, state)
+            count_tts_usage(state, phrase, len(pcm16_bytes) // 1920)
     except Exception:
         log_event("bot_error", session_id=session_id or "", reason="publish_send_failed")
         log_event("bot_exit", session_id=session_id or "")
@@ -1954,6 +2451,7 @@ async def main():
         speaking = False
         state['speaking'] = False
         state['active_utterance_id'] = ''
+        state['playing_agent_utterance_id'] = ''
         vad.min_start_frames = 2  # restore default when not speaking
         state['tts_stop_emitted'] = False
 
@@ -1974,6 +2472,14 @@ async def main():
         # Optional hard cap: respect BOT_STAY_CONNECTED_SECONDS if configured
         if stay_s > 0 and (now - start_ts) >= stay_s and idle_for >= idle_exit_s and not state.get('speaking'):
             break
+        # The orchestrator ended the session (close, error or shutdown)
+        if state.get('stop_all'):
+            break
+        # Candidate asked to end: leave once the goodbye has played
+        if state.get('end_requested') and not state.get('speaking'):
+            pending = state.get('tts_accum_task')
+            if not state.get('tts_accum_buf') and not (pending and not pending.done()):
+                break
         # Idle-based exit
         if idle_for >= idle_exit_s and not state.get('speaking') and not state.get('active_utterance_id'):
             break
@@ -1991,6 +2497,23 @@ async def main():
         except asyncio.TimeoutError:
             pass
 
+    # End the session explicitly: flush the last utterance through STT so its final
+    # reaches the orchestrator, then close the orchestrator session and wait for its Ack
+    try:
+        flush_s = int(os.environ.get('STT_CLOSE_FLUSH_MS', '1000')) / 1000.0
+    except Exception:
+        flush_s = 1.0
+    if stt_client is not None:
+        await stt_client.flush_and_close(timeout_s=flush_s)
+    # What the agent's speech cost this session
+    usage = tts_usage_payload(state)
+    log_event("tts_usage", session_id=session_id or "", metrics=usage)
+    if session_id:
+        await ws_queue.put({"type": "tts_usage", "ts_ms": int(time.time() * 1000), "session_id": session_id, "payload": usage})
+    if orch is not None:
+        await orch.close_session('end_requested' if state.get('end_requested') else 'idle_exit')
+        await orch.close()
+
     log_event("bot_exit", session_id=session_id or "")
 
     # Cleanup WS task if running
@@ -24,7 +24,7 @@ _sym_db = _symbol_database.Default()
 
 
 
-DESCRIPTOR = _descriptor_pool.Default().AddSerializedFile(b'\n\tstt.proto\x12\x06stt.v1\"\x8c\x01\n\x0c\x43ontrolStart\x12\x12\n\nsession_id\x18\x01 \x01(\t\x12\x11\n\tworker_id\x18\x02 \x01(\t\x12\x14\n\x0cutterance_id\x18\x03 \x01(\t\x12\x10\n\x08language\x18\x04 \x01(\t\x12\x13\n\x0bsample_rate\x18\x05 \x01(\r\x12\x18\n\x10protocol_version\x18\x06 \x01(\t\"1\n\nAudioChunk\x12\x0e\n\x06pcm16k\x18\x01 \x01(\x0c\x12\x13\n\x0b\x64uration_ms\x18\x02 \x01(\r\"\x07\n\x05\x44rain\"\x0e\n\x0cSessionClose\"\x13\n\x04Ping\x12\x0b\n\x03seq\x18\x01 \x01(\x04\"\x13\n\x04Pong\x12\x0b\n\x03seq\x18\x01 \x01(\x04\"\xc7\x01\n\rClientMessage\x12%\n\x05start\x18\x01 \x01(\x0b\x32\x14.stt.v1.ControlStartH\x00\x12#\n\x05\x61udio\x18\x02 \x01(\x0b\x32\x12.stt.v1.AudioChunkH\x00\x12\x1e\n\x05\x64rain\x18\x03 \x01(\x0b\x32\r.stt.v1.DrainH\x00\x12%\n\x05\x63lose\x18\x04 \x01(\x0b\x32\x14.stt.v1.SessionCloseH\x00\x12\x1c\n\x04ping\x18\x05 \x01(\x0b\x32\x0c.stt.v1.PingH\x00\x42\x05\n\x03msg\".\n\tConnected\x12\x12\n\nsession_id\x18\x01 \x01(\t\x12\r\n\x05model\x18\x02 \x01(\t\"K\n\x11TranscriptInterim\x12\x12\n\nsession_id\x18\x01 \x01(\t\x12\x14\n\x0cutterance_id\x18\x02 \x01(\t\x12\x0c\n\x04text\x18\x03 \x01(\t\"I\n\x0fTranscriptFinal\x12\x12\n\nsession_id\x18\x01 \x01(\t\x12\x14\n\x0cutterance_id\x18\x02 \x01(\t\x12\x0c\n\x04text\x18\x03 \x01(\t\"`\n\x05\x45rror\x12\x12\n\nsession_id\x18\x01 \x01(\t\x12\x0c\n\x04\x63ode\x18\x02 \x01(\t\x12\x0f\n\x07message\x18\x03 \x01(\t\x12$\n\tenum_code\x18\x04 \x01(\x0e\x32\x11.stt.v1.ErrorCode\"F\n\x07Metrics\x12\x12\n\nsession_id\x18\x01 \x01(\t\x12\x12\n\nbytes_sent\x18\x02 \x01(\x04\x12\x13\n\x0b\x66rames_sent\x18\x03 \x01(\x04\"\xf8\x01\n\rServerMessage\x12&\n\tconnected\x18\x01 \x01(\x0b\x32\x11.stt.v1.ConnectedH\x00\x12,\n\x07interim\x18\x02 \x01(\x0b\x32\x19.stt.v1.TranscriptInterimH\x00\x12(\n\x05\x66inal\x18\x03 \x01(\x0b\x32\x17.stt.v1.TranscriptFinalH\x00\x12\x1e\n\x05\x65rror\x18\x04 \x01(\x0b\x32\r.stt.v1.ErrorH\x00\x12\x1c\n\x04pong\x18\x05 \x01(\x0b\x32\x0c.stt.v1.PongH\x00\x12\"\n\x07metrics\x18\x06 \x01(\x0b\x32\x0f.stt.v1.MetricsH\x00\x42\x05\n\x03msg*\x92\x01\n\tErrorCode\x12\x1a\n\x16\x45RROR_CODE_UNSPECIFIED\x10\x00\x12\x15\n\x11\x43ONNECTION_FAILED\x10\x01\x12\x12\n\x0ePROVIDER_ERROR\x10\x02\x12\x0b\n\x07TIMEOUT\x10\x03\x12\x10\n\x0c\x43IRCUIT_OPEN\x10\x04\x12\x11\n\rINVALID_AUDIO\x10\x05\x12\x0c\n\x08SHUTDOWN\x10\x06\x32\x42\n\x03STT\x12;\n\x07Session\x12\x15.stt.v1.ClientMessage\x1a\x15.stt.v1.ServerMessage(\x01\x30\x01\x42 Z\x1eyuzu/agent/internal/stt/pb;sttb\x06proto3')
+DESCRIPTOR = _descriptor_pool.Default().AddSerializedFile(b'\n\tstt.proto\x12\x06stt.v1\"\xa6\x01\n\x0c\x43ontrolStart\x12\x12\n\nsession_id\x18\x01 \x01(\t\x12\x11\n\tworker_id\x18\x02 \x01(\t\x12\x14\n\x0cutterance_id\x18\x03 \x01(\t\x12\x10\n\x08language\x18\x04 \x01(\t\x12\x13\n\x0bsample_rate\x18\x05 \x01(\r\x12\x18\n\x10protocol_version\x18\x06 \x01(\t\x12\x18\n\x10no_audio_archive\x18\x07 \x01(\x08\"1\n\nAudioChunk\x12\x0e\n\x06pcm16k\x18\x01 \x01(\x0c\x12\x13\n\x0b\x64uration_ms\x18\x02 \x01(\r\"\x07\n\x05\x44rain\"\x0e\n\x0cSessionClose\"\x13\n\x04Ping\x12\x0b\n\x03seq\x18\x01 \x01(\x04\"\x13\n\x04Pong\x12\x0b\n\x03seq\x18\x01 \x01(\x04\"\xc7\x01\n\rClientMessage\x12%\n\x05start\x18\x01 \x01(\x0b\x32\x14.stt.v1.ControlStartH\x00\x12#\n\x05\x61udio\x18\x02 \x01(\x0b\x32\x12.stt.v1.AudioChunkH\x00\x12\x1e\n\x05\x64rain\x18\x03 \x01(\x0b\x32\r.stt.v1.DrainH\x00\x12%\n\x05\x63lose\x18\x04 \x01(\x0b\x32\x14.stt.v1.SessionCloseH\x00\x12\x1c\n\x04ping\x18\x05 \x01(\x0b\x32\x0c.stt.v1.PingH\x00\x42\x05\n\x03msg\"\x91\x01\n\tConnected\x12\x12\n\nsession_id\x18\x01 \x01(\t\x12\r\n\x05model\x18\x02 \x01(\t\x12\x10\n\x08provider\x18\x03 \x01(\t\x12\x10\n\x08language\x18\x04 \x01(\t\x12\x12\n\nconnect_ms\x18\x05 \x01(\r\x12\x12\n\nrequest_id\x18\x06 \x01(\t\x12\x15\n\rswitch_reason\x18\x07 \x01(\t\"z\n\x11TranscriptInterim\x12\x12\n\nsession_id\x18\x01 \x01(\t\x12\x14\n\x0cutterance_id\x18\x02 \x01(\t\x12\x0c\n\x04text\x18\x03 \x01(\t\x12\x16\n\x0e\x63ommitted_text\x18\x04 \x01(\t\x12\x15\n\rvolatile_text\x18\x05 \x01(\t\"\xac\x01\n\x0fTranscriptFinal\x12\x12\n\nsession_id\x18\x01 \x01(\t\x12\x14\n\x0cutterance_id\x18\x02 \x01(\t\x12\x0c\n\x04text\x18\x03 \x01(\t\x12\x12\n\nword_count\x18\x04 \x01(\r\x12\x11\n\tspeech_ms\x18\x05 \x01(\r\x12\x0f\n\x07speaker\x18\x06 \x01(\r\x12\x0e\n\x06source\x18\x07 \x01(\t\x12\x19\n\x11\x61udio_fingerprint\x18\x08 \x01(\x06\"o\n\x05\x45rror\x12\x12\n\nsession_id\x18\x01 \x01(\t\x12\x0c\n\x04\x63ode\x18\x02 \x01(\t\x12\x0f\n\x07message\x18\x03 \x01(\t\x12$\n\tenum_code\x18\x04 \x01(\x0e\x32\x11.stt.v1.ErrorCode\x12\r\n\x05\x66\x61tal\x18\x05 \x01(\x08\"\xe1\x01\n\x07Metrics\x12\x12\n\nsession_id\x18\x01 \x01(\t\x12\x12\n\nbytes_sent\x18\x02 \x01(\x04\x12\x13\n\x0b\x66rames_sent\x18\x03 \x01(\x04\x12\x15\n\raudio_seconds\x18\x04 \x01(\x01\x12\x1a\n\x12\x65stimated_cost_usd\x18\x05 \x01(\x01\x12\r\n\x05\x66inal\x18\x06 \x01(\x08\x12)\n\x05\x64rops\x18\x07 \x03(\x0b\x32\x1a.stt.v1.Metrics.DropsEntry\x1a,\n\nDropsEntry\x12\x0b\n\x03key\x18\x01 \x01(\t\x12\r\n\x05value\x18\x02 \x01(\x04:\x02\x38\x01\"\xf8\x01\n\rServerMessage\x12&\n\tconnected\x18\x01 \x01(\x0b\x32\x11.stt.v1.ConnectedH\x00\x12,\n\x07interim\x18\x02 \x01(\x0b\x32\x19.stt.v1.TranscriptInterimH\x00\x12(\n\x05\x66inal\x18\x03 \x01(\x0b\x32\x17.stt.v1.TranscriptFinalH\x00\x12\x1e\n\x05\x65rror\x18\x04 \x01(\x0b\x32\r.stt.v1.ErrorH\x00\x12\x1c\n\x04pong\x18\x05 \x01(\x0b\x32\x0c.stt.v1.PongH\x00\x12\"\n\x07metrics\x18\x06 \x01(\x0b\x32\x0f.stt.v1.MetricsH\x00\x42\x05\n\x03msg*\xc8\x01\n\tErrorCode\x12\x1a\n\x16\x45RROR_CODE_UNSPECIFIED\x10\x00\x12\x15\n\x11\x43ONNECTION_FAILED\x10\x01\x12\x12\n\x0ePROVIDER_ERROR\x10\x02\x12\x0b\n\x07TIMEOUT\x10\x03\x12\x10\n\x0c\x43IRCUIT_OPEN\x10\x04\x12\x11\n\rINVALID_AUDIO\x10\x05\x12\x0c\n\x08SHUTDOWN\x10\x06\x12\x0f\n\x0b\x41UTH_FAILED\x10\x07\x12\x10\n\x0cRATE_LIMITED\x10\x08\x12\x11\n\rSOCKET_CLOSED\x10\t2B\n\x03STT\x12;\n\x07Session\x12\x15.stt.v1.ClientMessage\x1a\x15.stt.v1.ServerMessage(\x01\x30\x01\x42 Z\x1eyuzu/agent/internal/stt/pb;sttb\x06proto3')
 
 _globals = globals()
 _builder.BuildMessageAndEnumDescriptors(DESCRIPTOR, _globals)
@@ -32,34 +32,36 @@ _builder.BuildTopDescriptorsAndMessages(DESCRIPTOR, 'stt_pb2', _globals)
 if not _descriptor._USE_C_DESCRIPTORS:
   _globals['DESCRIPTOR']._loaded_options = None
   _globals['DESCRIPTOR']._serialized_options = b'Z\036yuzu/agent/internal/stt/pb;stt'
-  _globals['_ERRORCODE']._serialized_start=1106
-  _globals['_ERRORCODE']._serialized_end=1252
+  _globals['_ERRORCODE']._serialized_start=1550
+  _globals['_ERRORCODE']._serialized_end=1750
   _globals['_CONTROLSTART']._serialized_start=22
-  _globals['_CONTROLSTART']._serialized_end=162
-  _globals['_AUDIOCHUNK']._serialized_start=164
-  _globals['_AUDIOCHUNK']._serialized_end=213
-  _globals['_DRAIN']._serialized_start=215
-  _globals['_DRAIN']._serialized_end=222
-  _globals['_SESSIONCLOSE']._serialized_start=224
-  _globals['_SESSIONCLOSE']._serialized_end=238
-  _globals['_PING']._serialized_start=240
-  _globals['_PING']._serialized_end=259
-  _globals['_PONG']._serialized_start=261
-  _globals['_PONG']._serialized_end=280
-  _globals['_CLIENTMESSAGE']._serialized_start=283
-  _globals['_CLIENTMESSAGE']._serialized_end=482
-  _globals['_CONNECTED']._serialized_start=484
-  _globals['_CONNECTED']._serialized_end=530
-  _globals['_TRANSCRIPTINTERIM']._serialized_start=532
-  _globals['_TRANSCRIPTINTERIM']._serialized_end=607
-  _globals['_TRANSCRIPTFINAL']._serialized_start=609
-  _globals['_TRANSCRIPTFINAL']._serialized_end=682
-  _globals['_ERROR']._serialized_start=684
-  _globals['_ERROR']._serialized_end=780
-  _globals['_METRICS']._serialized_start=782
-  _globals['_METRICS']._serialized_end=852
-  _globals['_SERVERMESSAGE']._serialized_start=855
-  _globals['_SERVERMESSAGE']._serialized_end=1103
-  _globals['_STT']._serialized_start=1254
-  _globals['_STT']._serialized_end=1320
+  _globals['_CONTROLSTART']._serialized_end=188
+  _globals['_AUDIOCHUNK']._serialized_start=190
+  _globals['_AUDIOCHUNK']._serialized_end=239
+  _globals['_DRAIN']._serialized_start=241
+  _globals['_DRAIN']._serialized_end=248
+  _globals['_SESSIONCLOSE']._serialized_start=250
+  _globals['_SESSIONCLOSE']._serialized_end=264
+  _globals['_PING']._serialized_start=266
+  _globals['_PING']._serialized_end=285
+  _globals['_PONG']._serialized_start=287
+  _globals['_PONG']._serialized_end=306
+  _globals['_CLIENTMESSAGE']._serialized_start=309
+  _globals['_CLIENTMESSAGE']._serialized_end=508
+  _globals['_CONNECTED']._serialized_start=511
+  _globals['_CONNECTED']._serialized_end=656
+  _globals['_TRANSCRIPTINTERIM']._serialized_start=658
+  _globals['_TRANSCRIPTINTERIM']._serialized_end=780
+  _globals['_TRANSCRIPTFINAL']._serialized_start=783
+  _globals['_TRANSCRIPTFINAL']._serialized_end=955
+  _globals['_ERROR']._serialized_start=957
+  _globals['_ERROR']._serialized_end=1068
+  _globals['_METRICS']._serialized_start=1071
+  _globals['_METRICS']._serialized_end=1296
+  _globals['_METRICS_DROPSENTRY']._serialized_start=1252
+  _globals['_METRICS_DROPSENTRY']._serialized_end=1296
+  _globals['_SERVERMESSAGE']._serialized_start=1299
+  _globals['_SERVERMESSAGE']._serialized_end=1547
+  _globals['_STT']._serialized_start=1752
+  _globals['_STT']._serialized_end=1818
 # @@protoc_insertion_point(module_scope)
@@ -1,13 +1,20 @@
 import asyncio
 import os
+import time
 from typing import Optional, Callable
 
 try:
     from . import stt_pb2 as stt
     from . import stt_pb2_grpc as stt_grpc
+    from .audio_utils import grpc_compression
 except Exception:
     import stt_pb2 as stt
     import stt_pb2_grpc as stt_grpc
+    from audio_utils import grpc_compression
+
+
+def _env_flag(name: str) -> bool:
+    return os.environ.get(name, '').lower() in ('1', 'true', 'yes')
 
 
 class STTSidecarClient:
@@ -25,6 +32,10 @@ class STTSidecarClient:
         self._orch = None
         # Write lock to serialize gRPC stream writes (only one write allowed at a time)
         self._write_lock = asyncio.Lock()
+        # Set on every final; flush_and_close waits on it
+        self._final_seen = asyncio.Event()
+        # Set when the sidecar reports final usage in reply to close
+        self._usage_seen = asyncio.Event()
         # metrics
         self.bytes_sent = 0
         self.frames_sent = 0
@@ -33,7 +44,7 @@ class STTSidecarClient:
         from grpc import aio
         uds = os.environ.get('STT_UDS_PATH', '/run/app/stt.sock')
         target = f"unix://{uds}" if not uds.startswith('unix://') else uds
-        self._channel = aio.insecure_channel(target)
+        self._channel = aio.insecure_channel(target, compression=grpc_compression('STT_GRPC_COMPRESSION'))
         self._stub = stt_grpc.STTStub(self._channel)
         self._call = self._stub.Session()
         self._recv_task = self._loop.create_task(self._recv_loop())
@@ -45,7 +56,7 @@ class STTSidecarClient:
     async def start_utterance(self, utterance_id: str):
         if not self._call:
             return
-        msg = stt.ClientMessage(start=stt.ControlStart(session_id=self.session_id, worker_id="", utterance_id=utterance_id, language="en-US", sample_rate=16000, protocol_version="1"))
+        msg = stt.ClientMessage(start=stt.ControlStart(session_id=self.session_id, worker_id="", utterance_id=utterance_id, language="en-US", sample_rate=16000, protocol_version="1", no_audio_archive=_env_flag('NO_AUDIO_ARCHIVE')))
         async with self._write_lock:
             await self._call.write(msg)
         self._log("stt_utterance_start", session_id=self.session_id, utterance_id=utterance_id)
@@ -68,6 +79,41 @@ class STTSidecarClient:
             await self._call.write(stt.ClientMessage(drain=stt.Drain()))
         self._log("stt_utterance_end", session_id=self.session_id)
 
+    async def flush_and_close(self, timeout_s: float = 1.0):
+        """End the session cleanly: drain the open utterance, give its final up to
+        timeout_s to reach the orchestrator, then send SessionClose and close.
+
+        The sidecar forwards provider events only as client messages arrive, so ping
+        while waiting."""
+        if self._call:
+            self._final_seen.clear()
+            got_final = False
+            try:
+                async with self._write_lock:
+                    await self._call.write(stt.ClientMessage(drain=stt.Drain()))
+                deadline = time.monotonic() + timeout_s
+                seq = 0
+                while time.monotonic() < deadline:
+                    seq += 1
+                    async with self._write_lock:
+                        await self._call.write(stt.ClientMessage(ping=stt.Ping(seq=seq)))
+                    try:
+                        await asyncio.wait_for(self._final_seen.wait(), timeout=0.05)
+                        got_final = True
+                        break
+                    except asyncio.TimeoutError:
+                        pass
+                async with self._write_lock:
+                    await self._call.write(stt.ClientMessage(close=stt.SessionClose()))
+                try:
+                    await asyncio.wait_for(self._usage_seen.wait(), timeout=0.5)
+                except asyncio.TimeoutError:
+                    pass
+            except Exception as e:
+                self._log("stt_flush_error", session_id=self.session_id, metrics={"error": str(e)})
+            self._log("stt_flushed", session_id=self.session_id, metrics={"final": got_final})
+        await self.close()
+
     async def close(self):
         try:
             if self._call:
@@ -98,26 +144,64 @@ class STTSidecarClient:
                         pass
                     if self._orch is not None:
                         try:
-                            await self._orch.send_transcript_interim(resp.interim.utterance_id, text)
+                            await self._orch.send_transcript_interim(resp.interim.utterance_id, text,
+                                                                     committed_text=resp.interim.committed_text, volatile_text=resp.interim.volatile_text)
                         except Exception:
                             pass
                     self._log("stt_transcript_interim", session_id=self.session_id, metrics={"chars": len(text)})
                 elif which == 'final':
                     text = resp.final.text
-                    self._log("stt_transcript_final", session_id=self.session_id, metrics={"chars": len(text), "text": text[:100] if text else ""})
+                    self._final_seen.set()
+                    final_metrics = {"chars": len(text)}
+                    if not _env_flag('NO_TRANSCRIPT_RETENTION'):
+                        final_metrics["text"] = text[:100] if text else ""
+                    self._log("stt_transcript_final", session_id=self.session_id, metrics=final_metrics)
+                    if self._ws_queue is not None and self.session_id and text:
+                        # Record the user side of the turn for transcripts/exports
+                        await self._ws_queue.put({"type": "transcript_final", "ts_ms": int(time.time() * 1000), "session_id": self.session_id,
+                                                  "utterance_id": resp.final.utterance_id,
+                                                  "payload": {"text": text, "turn_id": self._state.get('orch_turn_id', ''),
+                                                              "speaker": resp.final.speaker, "source": resp.final.source}})
                     if self._orch is not None:
                         try:
                             self._log("stt_sending_to_orchestrator", session_id=self.session_id, metrics={"utterance_id": resp.final.utterance_id, "text_len": len(text)})
-                            await self._orch.send_transcript_final(resp.final.utterance_id, text)
+                            await self._orch.send_transcript_final(resp.final.utterance_id, text, word_count=resp.final.word_count, speech_ms=resp.final.speech_ms, speaker=resp.final.speaker, source=resp.final.source,
+                                                                   audio_fingerprint=resp.final.audio_fingerprint)
                             self._log("stt_sent_to_orchestrator", session_id=self.session_id)
                         except Exception as e:
                             self._log("stt_orchestrator_send_error", session_id=self.session_id, metrics={"error": str(e)})
                     else:
                         self._log("stt_no_orchestrator_attached", session_id=self.session_id)
                 elif which == 'error':
-                    self._log("stt_error", session_id=self.session_id, metrics={"code": getattr(resp.error, 'enum_code', 0), "msg": resp.error.message})
+                    # Taxonomy name (auth_failed, rate_limited, bad_audio, timeout, socket_closed, ...);
+                    # older sidecars only set the enum
+                    code = resp.error.code
+                    if not code:
+                        try:
+                            code = stt.ErrorCode.Name(resp.error.enum_code).lower()
+                        except Exception:
+                            code = "provider_error"
+                    self._log("stt_error", session_id=self.session_id, metrics={"code": code, "msg": resp.error.message, "fatal": resp.error.fatal})
+                    if self._orch is not None:
+                        try:
+                            await self._orch.send_error("stt." + code, resp.error.message)
+                        except Exception:
+                            pass
+                elif which == 'connected':
+                    # Sent on start and on every provider (re)connect
+                    c = resp.connected
+                    self._log("stt_provider_connected", session_id=self.session_id,
+                              metrics={"provider": c.provider, "model": c.model, "language": c.language,
+                                       "connect_ms": c.connect_ms, "request_id": c.request_id})
+                    if c.switch_reason:
+                        # The sidecar failed over to its shadow provider
+                        self._log("stt_provider_switched", session_id=self.session_id,
+                                  metrics={"provider": c.provider, "model": c.model, "reason": c.switch_reason})
+                elif which == 'metrics' and resp.metrics.final:
+                    self._log("stt_usage", session_id=self.session_id, metrics={"audio_s": round(resp.metrics.audio_seconds, 1), "est_cost_usd": round(resp.metrics.estimated_cost_usd, 4), "drops": dict(resp.metrics.drops)})
+                    self._usage_seen.set()
                 else:
-                    # connected/metrics/pong ignored here
+                    # periodic metrics/pong ignored here
                     pass
         except asyncio.CancelledError:
             return
@@ -17,14 +17,14 @@ class TTSClient:
         self._channel = None
         self._stub = None
 
-    async def fetch_pcm48k(self, session_id: str, voice_id: str, text: str) -> bytes:
+    async def fetch_pcm48k(self, session_id: str, voice_id: str, text: str, speaking_rate: float = 0.0, intonation: str = '') -> bytes:
         from grpc import aio
         self._log('tts_fetch_start', session_id=session_id, metrics={'text_len': len(text), 'addr': self._addr})
         try:
             self._channel = aio.insecure_channel(self._addr)
             self._stub = tts_grpc.TTSStub(self._channel)
             call = self._stub.Session()
-            await call.write(tts.ClientMessage(start=tts.StartRequest(session_id=session_id, request_id='req', voice_id=voice_id, text=text)))
+            await call.write(tts.ClientMessage(start=tts.StartRequest(session_id=session_id, request_id='req', voice_id=voice_id, text=text, speaking_rate=speaking_rate, intonation=intonation)))
             pcm = bytearray()
             chunk_count = 0
             # Timeouts and limits
@@ -54,7 +54,10 @@ class TTSClient:
                         self._log('tts_fetch_eof_empty_msg', session_id=session_id, metrics={'chunks': chunk_count, 'bytes': len(pcm)})
                         break
                     if which == 'connected':
-                        self._log('tts_fetch_connected', session_id=session_id)
+                        c = resp.connected
+                        self._log('tts_fetch_connected', session_id=session_id,
+                                  metrics={'provider': c.provider, 'model': c.model, 'language': c.language, 'voice_id': c.voice_id,
+                                           'connect_ms': c.connect_ms, 'request_id': c.request_id})
                         continue
                     if which == 'audio':
                         pcm.extend(resp.audio.pcm48k)
@@ -66,6 +69,10 @@ class TTSClient:
                     elif which == 'error':
                         self._log('tts_fetch_error', session_id=session_id, metrics={'msg': resp.error.message})
                         break
+                    elif which == 'failed':
+                        # The service already retried the provider; nothing more to wait for
+                        self._log('tts_fetch_failed', session_id=session_id, metrics={'code': resp.failed.code, 'attempts': resp.failed.attempts, 'msg': resp.failed.message[:200]})
+                        break
                     else:
                         self._log('tts_fetch_unknown', session_id=session_id, metrics={'which': which})
                         break
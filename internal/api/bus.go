@@ -0,0 +1,47 @@
+package api
+
+import (
+	"time"
+
+	"yuzu/agent/internal/bot"
+	"yuzu/agent/internal/bus"
+	"yuzu/agent/internal/types"
+	"yuzu/agent/internal/workerws"
+)
+
+// Session lifecycle on the bus (see package bus).
+var (
+	TopicSessionCreated   = bus.NewTopic[types.Session]("session.created")
+	TopicSessionCompleted = bus.NewTopic[SessionCompleted]("session.completed")
+)
+
+// SessionCompleted is published when a session's interview ends on its own.
+type SessionCompleted struct {
+	Reason string
+}
+
+// SetBus connects the handlers to b: they follow worker messages and bot
+// processes from it, the debug endpoints publish worker messages onto it,
+// and session events are published to it. Call before serving.
+func (h *Handlers) SetBus(b *bus.Bus) {
+	h.bus = b
+	bus.On(b, workerws.TopicMessage, h.OnWorkerMessage)
+	bus.On(b, bot.TopicStarted, func(sessionID string, e bot.Started) {
+		h.store.SetBotPID(sessionID, e.PID)
+	})
+	bus.On(b, bot.TopicExited, func(sessionID string, e bot.Exited) {
+		// On process exit, mark not running and append event
+		h.store.SetBotRunning(sessionID, false)
+		h.store.SetBotExit(sessionID, e.Code, time.Now().UTC())
+		errText := ""
+		if e.Err != nil {
+			errText = e.Err.Error()
+		}
+		h.store.AppendEvent(sessionID, "bot_exit", map[string]any{"error": errText})
+		h.redactTranscripts(sessionID)
+		h.indexSummary(sessionID)
+	})
+	bus.On(b, bot.TopicLog, func(sessionID string, l bot.LogLine) {
+		h.store.AppendEvent(sessionID, "bot_log", map[string]any{"stream": l.Stream, "line": l.Line})
+	})
+}
@@ -0,0 +1,65 @@
+package api
+
+import (
+	"log"
+	"time"
+
+	"yuzu/agent/internal/bus"
+	"yuzu/agent/internal/workerws"
+)
+
+// complete.go finishes sessions whose interview ended on its own: the
+// orchestrator ran out of time, the candidate asked to stop, or moderation
+// ended it. The worker reports "interview_ended" after the orchestrator's
+// EndInterview; the session is marked completed, the bot gets
+// BOT_END_GRACE_SECONDS (default 30) to play its goodbye and leave before
+// it is stopped, and then the Daily room is deleted.
+
+const (
+	statusCreated   = "created"
+	statusCompleted = "completed"
+)
+
+// OnWorkerMessage handles the worker messages that change the session
+// itself; SetBus subscribes it to worker messages.
+func (h *Handlers) OnWorkerMessage(sessionID string, msg workerws.Message) {
+	if msg.Type != "interview_ended" {
+		return
+	}
+	// Duplicate reports (a resend, a reconnect) race here; only the one
+	// that makes the change finishes the session
+	if !h.store.CompareAndSetStatus(sessionID, statusCreated, statusCompleted) {
+		return
+	}
+	sess := h.store.GetSession(sessionID)
+	if sess == nil {
+		return
+	}
+	reason, _ := msg.Payload["reason"].(string)
+	h.store.AppendEvent(sessionID, "session_completed", map[string]any{"reason": reason})
+	bus.Publish(h.bus, TopicSessionCompleted, sessionID, SessionCompleted{Reason: reason})
+	go h.finish(sessionID, sess.RoomName, time.Duration(h.cfg.Bot.EndGraceSeconds)*time.Second)
+}
+
+// finish waits up to grace for the bot to exit, stops it if it hasn't, and
+// deletes the room.
+func (h *Handlers) finish(sessionID, room string, grace time.Duration) {
+	deadline := time.Now().Add(grace)
+	for h.runner.IsRunning(sessionID) && time.Now().Before(deadline) {
+		time.Sleep(250 * time.Millisecond)
+	}
+	if h.runner.IsRunning(sessionID) {
+		_ = h.runner.Stop(sessionID)
+		h.store.SetBotRunning(sessionID, false)
+		h.store.AppendEvent(sessionID, "bot_stopped", map[string]any{"reason": "interview_ended"})
+	}
+	if room == "" {
+		return
+	}
+	if err := h.daily.DeleteRoom(room); err != nil {
+		log.Printf("delete room %s: %v", room, err)
+		h.store.AppendEvent(sessionID, "room_delete_error", map[string]any{"room": room, "error": err.Error()})
+		return
+	}
+	h.store.AppendEvent(sessionID, "room_deleted", map[string]any{"room": room})
+}
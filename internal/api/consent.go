@@ -0,0 +1,61 @@
+package api
+
+import (
+	"net/http"
+
+	"yuzu/agent/internal/store"
+	"yuzu/agent/internal/types"
+)
+
+// consent.go applies a session's recording consent (types.Consent), set
+// with POST /sessions {"do_not_record": true} or per kind with
+// {"consent": {"no_audio_archive": true, "no_transcript_retention": true,
+// "no_llm_logging": true}}. The API server enforces transcript retention
+// itself, reading the flag from the store:
+//
+//   - the export endpoint refuses the session with 403
+//   - search skips it
+//   - when the bot exits its transcript events are redacted
+//     (transcripts_redacted)
+//   - store.WriteBehind never persists transcript text
+//
+// Refusals are recorded as recording_rejected events. Audio archiving and
+// LLM logging happen outside this process. The bot gets NO_AUDIO_ARCHIVE,
+// NO_TRANSCRIPT_RETENTION and NO_LLM_LOGGING, and the gateway passes them
+// to the STT sidecar (ControlStart.no_audio_archive) and the orchestrator
+// (SessionStyle.no_llm_logging, then StartRequest.no_log). It also drops
+// transcript text from its own logs, which reach the event log as bot_log.
+
+// consentEnv adds the session's opt-outs to the bot's environment.
+func consentEnv(c types.Consent, env map[string]string) {
+	for name, on := range map[string]bool{
+		"NO_AUDIO_ARCHIVE":        c.NoAudioArchive,
+		"NO_TRANSCRIPT_RETENTION": c.NoTranscriptRetention,
+		"NO_LLM_LOGGING":          c.NoLLMLogging,
+	} {
+		if on {
+			env[name] = "true"
+		}
+	}
+}
+
+// refuseTranscript answers 403 and records the rejection when the session
+// keeps no transcript; what names the attempt.
+func (h *Handlers) refuseTranscript(w http.ResponseWriter, id, what string) bool {
+	if !h.store.GetConsent(id).NoTranscriptRetention {
+		return false
+	}
+	store.Reject(h.store, id, what)
+	http.Error(w, "session opted out of transcript retention", http.StatusForbidden)
+	return true
+}
+
+// redactTranscripts drops transcript text once the bot has left the call.
+func (h *Handlers) redactTranscripts(sessionID string) {
+	if !h.store.GetConsent(sessionID).NoTranscriptRetention {
+		return
+	}
+	if n := h.store.RedactTranscripts(sessionID); n > 0 {
+		h.store.AppendEvent(sessionID, "transcripts_redacted", map[string]any{"events": n})
+	}
+}
@@ -0,0 +1,74 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"yuzu/agent/internal/store"
+	"yuzu/agent/internal/types"
+)
+
+// context.go takes the reference documents an interview is grounded in:
+//
+//	GET  /sessions/{id}/context  list the documents (names and sizes)
+//	POST /sessions/{id}/context  {"name": "resume", "kind": "resume", "text": "..."}
+//
+// A POST replaces a document with the same name. Documents are handed to
+// the worker when the bot starts (LLM_CONTEXT_JSON) and forwarded to the
+// orchestrator in SessionOpen, which retrieves the relevant chunks for each
+// turn; uploads after /start are rejected with 409 since the running bot
+// would never see them.
+
+const maxContextBody = types.MaxContextDocLen + 4<<10
+
+type contextDocInfo struct {
+	Name  string `json:"name"`
+	Kind  string `json:"kind,omitempty"`
+	Bytes int    `json:"bytes"`
+}
+
+func contextInfo(docs []types.ContextDoc) []contextDocInfo {
+	out := make([]contextDocInfo, 0, len(docs))
+	for _, d := range docs {
+		out = append(out, contextDocInfo{Name: d.Name, Kind: d.Kind, Bytes: len(d.Text)})
+	}
+	return out
+}
+
+// HandleContext serves /sessions/{id}/context.
+func (h *Handlers) HandleContext(w http.ResponseWriter, r *http.Request, id string) {
+	if h.lookup(r, id) == nil {
+		http.NotFound(w, r)
+		return
+	}
+	switch r.Method {
+	case http.MethodGet:
+		writeJSON(w, http.StatusOK, map[string]any{"session_id": id, "documents": contextInfo(h.store.ListContextDocs(id))})
+	case http.MethodPost:
+		var doc types.ContextDoc
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxContextBody)).Decode(&doc); err != nil {
+			http.Error(w, "invalid JSON body: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		if err := doc.Validate(); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if h.runner.IsRunning(id) {
+			http.Error(w, "bot already started; upload context before /start", http.StatusConflict)
+			return
+		}
+		if err := h.store.PutContextDoc(id, doc); errors.Is(err, store.ErrNoSession) {
+			http.NotFound(w, r)
+			return
+		} else if err != nil {
+			http.Error(w, err.Error(), http.StatusRequestEntityTooLarge)
+			return
+		}
+		h.store.AppendEvent(id, "context_uploaded", map[string]any{"name": doc.Name, "kind": doc.Kind, "bytes": len(doc.Text)})
+		writeJSON(w, http.StatusCreated, map[string]any{"session_id": id, "documents": contextInfo(h.store.ListContextDocs(id))})
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
@@ -0,0 +1,127 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"yuzu/agent/internal/config"
+)
+
+// devOrigins are allowed in dev mode when CORS_ALLOWED_ORIGINS is unset.
+var devOrigins = []string{"http://localhost", "http://localhost:*", "http://127.0.0.1", "http://127.0.0.1:*"}
+
+// cors answers preflight requests and adds CORS headers for allowed
+// origins. Requests without an Origin header pass through untouched, and so
+// do disallowed origins, minus the headers, so the browser blocks them.
+type cors struct {
+	origins     []string
+	methods     string
+	headers     string
+	exposed     string
+	credentials bool
+	maxAge      string
+}
+
+// withCORS wraps next per cfg.CORS; with no origins to allow it returns
+// next unchanged.
+func withCORS(cfg config.Config, next http.Handler) http.Handler {
+	c := &cors{
+		origins:     splitList(cfg.CORS.AllowedOrigins),
+		methods:     strings.Join(splitList(cfg.CORS.AllowedMethods), ", "),
+		headers:     strings.Join(splitList(cfg.CORS.AllowedHeaders), ", "),
+		exposed:     strings.Join(splitList(cfg.CORS.ExposedHeaders), ", "),
+		credentials: cfg.CORS.AllowCredentials,
+	}
+	if cfg.CORS.MaxAgeSecs > 0 {
+		c.maxAge = strconv.Itoa(cfg.CORS.MaxAgeSecs)
+	}
+	if len(c.origins) == 0 && cfg.Dev.Mode {
+		c.origins = devOrigins
+	}
+	if len(c.origins) == 0 {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		origin := r.Header.Get("Origin")
+		if origin == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		w.Header().Add("Vary", "Origin")
+		preflight := r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != ""
+		if !c.allowed(origin) {
+			if preflight {
+				http.Error(w, "origin not allowed", http.StatusForbidden)
+				return
+			}
+			next.ServeHTTP(w, r)
+			return
+		}
+		if c.credentials || !c.any() {
+			w.Header().Set("Access-Control-Allow-Origin", origin)
+		} else {
+			w.Header().Set("Access-Control-Allow-Origin", "*")
+		}
+		if c.credentials {
+			w.Header().Set("Access-Control-Allow-Credentials", "true")
+		}
+		if !preflight {
+			if c.exposed != "" {
+				w.Header().Set("Access-Control-Expose-Headers", c.exposed)
+			}
+			next.ServeHTTP(w, r)
+			return
+		}
+		w.Header().Add("Vary", "Access-Control-Request-Method")
+		w.Header().Add("Vary", "Access-Control-Request-Headers")
+		w.Header().Set("Access-Control-Allow-Methods", c.methods)
+		if c.headers != "" {
+			w.Header().Set("Access-Control-Allow-Headers", c.headers)
+		}
+		if c.maxAge != "" {
+			w.Header().Set("Access-Control-Max-Age", c.maxAge)
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+}
+
+// any reports whether every origin is allowed.
+func (c *cors) any() bool {
+	for _, o := range c.origins {
+		if o == "*" {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *cors) allowed(origin string) bool {
+	for _, pattern := range c.origins {
+		if pattern == "*" || strings.EqualFold(pattern, origin) {
+			return true
+		}
+		prefix, suffix, ok := strings.Cut(strings.ToLower(pattern), "*")
+		o := strings.ToLower(origin)
+		if !ok || len(o) <= len(prefix)+len(suffix) || !strings.HasPrefix(o, prefix) || !strings.HasSuffix(o, suffix) {
+			continue
+		}
+		// The wildcard stands for a subdomain label or a port, never a path
+		// or userinfo
+		if !strings.ContainsAny(o[len(prefix):len(o)-len(suffix)], "/@") {
+			return true
+		}
+	}
+	return false
+}
+
+// splitList splits a comma-separated setting, dropping blanks.
+func splitList(s string) []string {
+	var out []string
+	for _, p := range strings.Split(s, ",") {
+		if p = strings.TrimSpace(p); p != "" {
+			out = append(out, p)
+		}
+	}
+	return out
+}
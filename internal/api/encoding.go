@@ -0,0 +1,100 @@
+package api
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// encoding.go compresses large JSON responses for clients that ask for it.
+// zstd is preferred over gzip when Accept-Encoding allows both; bodies under
+// minCompressBytes go out as-is since the framing would outweigh the gain.
+
+const minCompressBytes = 1024
+
+// zstdEncoder is shared; EncodeAll is safe for concurrent use.
+var zstdEncoder, _ = zstd.NewWriter(nil, zstd.WithEncoderLevel(zstd.SpeedDefault))
+
+// negotiateEncoding picks zstd, gzip or "" (identity) from Accept-Encoding.
+func negotiateEncoding(accept string) string {
+	q := map[string]float64{}
+	for _, part := range strings.Split(accept, ",") {
+		name, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		weight := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if f, err := strconv.ParseFloat(v, 64); err == nil {
+				weight = f
+			}
+		}
+		q[strings.ToLower(strings.TrimSpace(name))] = weight
+	}
+	for _, enc := range []string{"zstd", "gzip"} {
+		if w, ok := q[enc]; ok && w > 0 {
+			return enc
+		}
+		if w, ok := q["*"]; ok && w > 0 {
+			if _, named := q[enc]; !named {
+				return enc
+			}
+		}
+	}
+	return ""
+}
+
+// writeJSONEncoded writes v as JSON, compressed when the client accepts it,
+// and returns the content encoding used ("identity" when none).
+func writeJSONEncoded(w http.ResponseWriter, r *http.Request, v any) string {
+	body, err := json.Marshal(v)
+	if err != nil {
+		log.Printf("encode error: %v", err)
+		http.Error(w, "encode error", http.StatusInternalServerError)
+		return "identity"
+	}
+	body = append(body, '\n')
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Add("Vary", "Accept-Encoding")
+	enc := ""
+	if len(body) >= minCompressBytes {
+		enc = negotiateEncoding(r.Header.Get("Accept-Encoding"))
+	}
+	switch enc {
+	case "zstd":
+		body = zstdEncoder.EncodeAll(body, nil)
+	case "gzip":
+		var buf bytes.Buffer
+		zw := gzip.NewWriter(&buf)
+		zw.Write(body)
+		zw.Close()
+		body = buf.Bytes()
+	default:
+		enc = "identity"
+	}
+	if enc != "identity" {
+		w.Header().Set("Content-Encoding", enc)
+	}
+	w.Header().Set("Content-Length", strconv.Itoa(len(body)))
+	if _, err := w.Write(body); err != nil {
+		log.Printf("write error: %v", err)
+	}
+	return enc
+}
+
+// etagMatches reports whether If-None-Match lists etag (weak comparison).
+func etagMatches(ifNoneMatch, etag string) bool {
+	if ifNoneMatch == "" {
+		return false
+	}
+	for _, t := range strings.Split(ifNoneMatch, ",") {
+		t = strings.TrimSpace(t)
+		if t == "*" || strings.TrimPrefix(t, "W/") == strings.TrimPrefix(etag, "W/") {
+			return true
+		}
+	}
+	return false
+}
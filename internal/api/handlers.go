@@ -2,47 +2,115 @@ package api
 
 import (
     "encoding/json"
+    "io"
     "log"
     "net/http"
+    "strconv"
+    "sync"
     "time"
 
     "github.com/google/uuid"
     "yuzu/agent/internal/bot"
+    "yuzu/agent/internal/bus"
     "yuzu/agent/internal/config"
     "yuzu/agent/internal/daily"
+    "yuzu/agent/internal/errdefs"
+    "yuzu/agent/internal/export"
+    "yuzu/agent/internal/floor"
+    "yuzu/agent/internal/prompt"
     "yuzu/agent/internal/auth"
     "yuzu/agent/internal/store"
+    "yuzu/agent/internal/tenant"
     "yuzu/agent/internal/types"
     "yuzu/agent/internal/workerws"
 )
 
 type Handlers struct {
     cfg    config.Config
-    store  *store.Store
+    store  store.Store
     daily  daily.Client
     runner bot.Runner
-    onWorkerMsg func(sessionID string, msg workerws.Message)
+    bus    *bus.Bus
+    tenants *tenant.Registry
+    // startMu guards the quota check and starting, the bot starts not yet
+    // running that already count against their tenant's quota
+    startMu  sync.Mutex
+    starting map[string]string // session ID -> tenant ID
 }
 
-func NewHandlers(cfg config.Config, st *store.Store, d daily.Client, r bot.Runner) *Handlers {
-    return &Handlers{cfg: cfg, store: st, daily: d, runner: r}
+func NewHandlers(cfg config.Config, st store.Store, d daily.Client, r bot.Runner) *Handlers {
+    return &Handlers{cfg: cfg, store: st, daily: d, runner: r, tenants: tenant.Single(), starting: map[string]string{}}
 }
 
-func (h *Handlers) SetOnWorkerMessage(fn func(sessionID string, msg workerws.Message)) { h.onWorkerMsg = fn }
+// SetTenants enables multi-tenant mode; call before serving.
+func (h *Handlers) SetTenants(reg *tenant.Registry) { h.tenants = reg }
 
 func (h *Handlers) HandleCreateSession(w http.ResponseWriter, r *http.Request) {
 	if h.cfg.Daily.APIKey == "" || h.cfg.Daily.Domain == "" {
-		http.Error(w, "missing Daily configuration", http.StatusBadRequest)
+		errdefs.WriteHTTP(w, &errdefs.ConfigError{Key: "DAILY_API_KEY/DAILY_DOMAIN", Msg: "not set; sessions can't get a room"})
 		return
 	}
+	// Optional body: {"preset": "...", "style": {...}}; unset style fields
+	// fall back to the preset, then to config defaults
+	var body struct {
+		Preset string             `json:"preset"`
+		Style  types.SessionStyle `json:"style"`
+		// BargeInProfile picks the candidate's audio setup (see
+		// types.BargeInProfiles) over the preset's thresholds
+		BargeInProfile string `json:"barge_in_profile"`
+		// TurnPolicy picks the turn-taking rules (see floor.Strategies)
+		// over the deployment's
+		TurnPolicy string `json:"turn_policy"`
+		// Recording opt-outs (see consent.go); do_not_record sets them all
+		DoNotRecord bool          `json:"do_not_record"`
+		Consent     types.Consent `json:"consent"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil && err != io.EOF {
+		http.Error(w, "invalid JSON body: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	if body.Style.SystemPrompt != "" {
+		http.Error(w, "system_prompt is configured per tenant", http.StatusBadRequest)
+		return
+	}
+	t := h.tenantOf(r)
+	var preset types.Preset
+	if body.Preset != "" {
+		var ok bool
+		if preset, ok = h.store.GetPreset(t.ID, body.Preset); !ok {
+			http.Error(w, "unknown preset "+body.Preset, http.StatusBadRequest)
+			return
+		}
+	}
+	style := body.Style.Merge(preset.Style).Merge(h.defaultStyle(t))
+	if err := style.Validate(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	vad := preset.VAD
+	if body.BargeInProfile != "" {
+		vad = types.VADSettings{Profile: body.BargeInProfile}
+	}
+	if err := vad.Validate(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if _, ok := floor.StrategyFor(body.TurnPolicy, nil); !ok {
+		http.Error(w, "unknown turn_policy "+body.TurnPolicy, http.StatusBadRequest)
+		return
+	}
+	consent := body.Consent
+	if body.DoNotRecord {
+		consent = types.DoNotRecord()
+	}
 	// Generate session ID
 	id := uuid.New().String()
-	roomName := h.cfg.Daily.RoomPrefix + id
+	roomName := t.RoomName(h.cfg.Daily.RoomPrefix, id)
 	roomURL := "https://" + h.cfg.Daily.Domain + "/" + roomName
 
 	// Create room in Daily
 	if err := h.daily.CreateRoom(roomName, h.cfg.Daily.RoomPrivacy); err != nil {
-		http.Error(w, err.Error(), http.StatusBadGateway)
+		errdefs.WriteHTTP(w, err)
 		return
 	}
 
@@ -50,38 +118,104 @@ func (h *Handlers) HandleCreateSession(w http.ResponseWriter, r *http.Request) {
 	exp := time.Now().Add(time.Duration(h.cfg.Daily.BotTokenExpMin) * time.Minute).Unix()
 	token, err := h.daily.CreateMeetingToken(roomName, h.cfg.Daily.BotName, exp, true /* isBot */)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadGateway)
+		errdefs.WriteHTTP(w, err)
 		return
 	}
 
 	sess := &types.Session{
 		ID:        id,
+		TenantID:  t.ID,
 		RoomName:  roomName,
 		RoomURL:   roomURL,
 		BotToken:  token,
 		CreatedAt: time.Now().UTC(),
-		Status:    "created",
+		Status:    statusCreated,
+		Style:     style,
+		Preset:    preset.Name,
+		VoiceID:   preset.VoiceID,
+		Flow:      preset.Flow,
+		VAD:       vad.Resolve(),
+
+		TurnPolicy: body.TurnPolicy,
+		Consent:    consent,
 	}
 	if err := h.store.CreateSession(sess); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		// ErrStoreFull maps to 429 with Retry-After
+		errdefs.WriteHTTP(w, err)
 		return
 	}
-	h.store.AppendEvent(id, "session_created", map[string]any{"room_name": roomName})
+	created := map[string]any{"room_name": roomName, "tenant_id": t.ID, "persona": style.Persona, "verbosity": style.Verbosity}
+	if preset.Name != "" {
+		created["preset"] = preset.Name
+	}
+	if vad.Profile != "" {
+		created["barge_in_profile"] = vad.Profile
+	}
+	if body.TurnPolicy != "" {
+		created["turn_policy"] = body.TurnPolicy
+	}
+	if consent.Any() {
+		created["consent"] = consent
+	}
+	h.store.AppendEvent(id, "session_created", created)
+	bus.Publish(h.bus, TopicSessionCreated, id, *sess)
+	metricSessionsCreated.WithLabelValues(t.ID).Inc()
 
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
 	if err := json.NewEncoder(w).Encode(map[string]any{
 		"session_id": id,
+		"tenant_id":  t.ID,
 		"room_name":  roomName,
 		"room_url":   roomURL,
 		"bot_token":  token,
+		"style":      publicStyle(style),
+		"preset":     preset.Name,
 	}); err != nil {
 		log.Printf("encode error: %v", err)
 	}
 }
 
+// HandleListSessions lists the caller's sessions, newest first. Bot tokens
+// are left out.
+func (h *Handlers) HandleListSessions(w http.ResponseWriter, r *http.Request) {
+	type item struct {
+		ID         string    `json:"session_id"`
+		RoomName   string    `json:"room_name"`
+		CreatedAt  time.Time `json:"created_at"`
+		Status     string    `json:"status"`
+		BotRunning bool      `json:"bot_running"`
+		Events     int       `json:"events"`
+	}
+	out := []item{}
+	for _, s := range h.store.ListSessions(h.tenantOf(r).ID) {
+		out = append(out, item{ID: s.ID, RoomName: s.RoomName, CreatedAt: s.CreatedAt, Status: s.Status,
+			BotRunning: h.store.IsBotRunning(s.ID), Events: len(h.store.ListEvents(s.ID))})
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(map[string]any{"sessions": out}); err != nil {
+		log.Printf("encode error: %v", err)
+	}
+}
+
+// HandleGetSession returns one of the caller's sessions, with the worker's
+// device info once it has said hello. The bot token is left out.
+func (h *Handlers) HandleGetSession(w http.ResponseWriter, r *http.Request, id string) {
+	sess := h.lookup(r, id)
+	if sess == nil {
+		http.NotFound(w, r)
+		return
+	}
+	out := *sess
+	out.BotToken = ""
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(map[string]any{"session": out, "bot_running": h.store.IsBotRunning(id)}); err != nil {
+		log.Printf("encode error: %v", err)
+	}
+}
+
 func (h *Handlers) HandleStartSession(w http.ResponseWriter, r *http.Request, id string) {
-	sess := h.store.GetSession(id)
+	sess := h.lookup(r, id)
 	if sess == nil {
 		http.NotFound(w, r)
 		return
@@ -95,20 +229,83 @@ func (h *Handlers) HandleStartSession(w http.ResponseWriter, r *http.Request, id
 		}
 		return
 	}
+	t := h.tenantOf(r)
+	if reason, err := h.reserveStart(id, t); err != nil {
+		h.store.AppendEvent(id, "bot_start_rejected", map[string]any{"reason": reason})
+		errdefs.WriteHTTP(w, err)
+		return
+	}
+	defer h.releaseStart(id)
 	h.store.AppendEvent(id, "bot_start_requested", nil)
 
+    voiceID := h.cfg.Eleven.VoiceID
+    if t.VoiceID != "" {
+        voiceID = t.VoiceID
+    }
+    if sess.VoiceID != "" {
+        voiceID = sess.VoiceID
+    }
     env := map[string]string{
         "DAILY_ROOM_URL":             sess.RoomURL,
         "DAILY_TOKEN":                sess.BotToken,
         "ELEVENLABS_API_KEY":         h.cfg.Eleven.APIKey,
-        "ELEVENLABS_VOICE_ID":        h.cfg.Eleven.VoiceID,
+        "ELEVENLABS_VOICE_ID":        voiceID,
         "ELEVENLABS_CANNED_PHRASE":   h.cfg.Eleven.CannedPhrase,
         "BOT_STAY_CONNECTED_SECONDS": h.cfg.Bot.StayConnectedSeconds,
         // Ensure worker connects to orchestrator and streams STT
         "ORCH_ADDR":                  "localhost:9090",
         "STT_ENABLED":                "true",
         "STT_UDS_PATH":               "/tmp/stt.sock",
+        // Response style, forwarded by the worker to the orchestrator in SessionOpen
+        "LLM_PERSONA":                sess.Style.Persona,
+        "LLM_VERBOSITY":              sess.Style.Verbosity,
+        "LLM_MAX_TOKENS":             strconv.Itoa(sess.Style.MaxTokens),
+        "TENANT_ID":                  t.ID,
+    }
+    if t := sess.Style.Temperature; t != nil {
+        env["LLM_TEMPERATURE"] = strconv.FormatFloat(*t, 'f', -1, 64)
+    }
+    if sess.Style.SystemPrompt != "" {
+        env["LLM_SYSTEM_PROMPT"] = sess.Style.SystemPrompt
+    }
+    if sess.Style.Instructions != "" {
+        env["LLM_SESSION_INSTRUCTIONS"] = sess.Style.Instructions
+    }
+    if sess.Style.Captions {
+        env["CAPTIONS"] = "true"
+    }
+    if sess.Style.TokenStream {
+        env["TOKEN_STREAM"] = "true"
+    }
+    if sess.Style.EchoTest {
+        env["ECHO_TEST"] = "true"
     }
+    if sess.Style.MaxDurationSeconds > 0 {
+        env["MAX_DURATION_S"] = strconv.Itoa(sess.Style.MaxDurationSeconds)
+    }
+    // Preset flow and barge-in thresholds, also forwarded in SessionOpen
+    if len(sess.Flow) > 0 {
+        env["LLM_FLOW_JSON"] = string(sess.Flow)
+    }
+    // Uploaded reference documents (see context.go)
+    if docs := h.store.ListContextDocs(id); len(docs) > 0 {
+        if b, err := json.Marshal(docs); err == nil {
+            env["LLM_CONTEXT_JSON"] = string(b)
+        }
+    }
+    if sess.VAD.MinRMS > 0 {
+        env["LOCAL_STOP_MIN_RMS"] = strconv.Itoa(sess.VAD.MinRMS)
+    }
+    if sess.VAD.GuardMs > 0 {
+        env["LOCAL_STOP_GUARD_MS"] = strconv.Itoa(sess.VAD.GuardMs)
+    }
+    if sess.VAD.Hangover > 0 {
+        env["LOCAL_STOP_HANGOVER_FRAMES"] = strconv.Itoa(sess.VAD.Hangover)
+    }
+    if sess.VAD.Profile != "" {
+        env["BARGE_IN_PROFILE"] = sess.VAD.Profile
+    }
+    consentEnv(sess.Consent, env)
     // Wire backend WS for control messages (stop_tts) if configured
     if h.cfg.Worker.TokenSecret != "" {
         exp := time.Now().Add(time.Duration(h.cfg.Worker.TokenTTLSecs) * time.Second).Unix()
@@ -128,7 +325,9 @@ func (h *Handlers) HandleStartSession(w http.ResponseWriter, r *http.Request, id
 		return
 	}
 	h.store.SetBotRunning(id, true)
+	h.store.AppendEvent(id, "config_snapshot", h.configSnapshot(sess, t, env))
 	h.store.AppendEvent(id, "bot_started", nil)
+	metricBotStarts.WithLabelValues(t.ID).Inc()
 
 	w.Header().Set("Content-Type", "application/json")
 	if err := json.NewEncoder(w).Encode(map[string]any{"ok": true, "running": true}); err != nil {
@@ -137,7 +336,7 @@ func (h *Handlers) HandleStartSession(w http.ResponseWriter, r *http.Request, id
 }
 
 func (h *Handlers) HandleEndSession(w http.ResponseWriter, r *http.Request, id string) {
-	sess := h.store.GetSession(id)
+	sess := h.lookup(r, id)
 	if sess == nil {
 		http.NotFound(w, r)
 		return
@@ -164,31 +363,78 @@ func (h *Handlers) HandleEndSession(w http.ResponseWriter, r *http.Request, id s
 	}
 }
 
+// HandleListEvents returns the session's event log. ?since_index=N returns
+// only events from index N on; next_index is the value to pass next time.
+// The ETag changes with every new event, so a poller sending If-None-Match
+// gets 304 until something happens. Large responses are compressed with
+// zstd or gzip when the client accepts it.
 func (h *Handlers) HandleListEvents(w http.ResponseWriter, r *http.Request, id string) {
-    sess := h.store.GetSession(id)
+    sess := h.lookup(r, id)
     if sess == nil {
         http.NotFound(w, r)
         return
     }
-    events := h.store.ListEvents(id)
-    w.Header().Set("Content-Type", "application/json")
-    if err := json.NewEncoder(w).Encode(map[string]any{
+    since := 0
+    if v := r.URL.Query().Get("since_index"); v != "" {
+        n, err := strconv.Atoi(v)
+        if err != nil || n < 0 {
+            http.Error(w, "since_index must be a non-negative integer", http.StatusBadRequest)
+            return
+        }
+        since = n
+    }
+    events, next := h.store.ListEventsSince(id, since)
+    etag := `W/"` + strconv.Itoa(next) + `"`
+    w.Header().Set("ETag", etag)
+    w.Header().Set("Cache-Control", "no-cache")
+    if etagMatches(r.Header.Get("If-None-Match"), etag) {
+        w.Header().Add("Vary", "Accept-Encoding")
+        w.WriteHeader(http.StatusNotModified)
+        metricEventListResponses.WithLabelValues("not_modified").Inc()
+        return
+    }
+    enc := writeJSONEncoded(w, r, map[string]any{
         "session_id": id,
         "events":     events,
-    }); err != nil { log.Printf("encode error: %v", err) }
+        "next_index": next,
+    })
+    metricEventListResponses.WithLabelValues(enc).Inc()
+}
+
+// HandleExport downloads the session's turns as a fine-tuning dataset.
+func (h *Handlers) HandleExport(w http.ResponseWriter, r *http.Request, id string) {
+    sess := h.lookup(r, id)
+    if sess == nil {
+        http.NotFound(w, r)
+        return
+    }
+    format := r.URL.Query().Get("format")
+    if format != "openai-jsonl" {
+        http.Error(w, "unsupported format (want format=openai-jsonl)", http.StatusBadRequest)
+        return
+    }
+    if h.refuseTranscript(w, id, "transcript_export") {
+        return
+    }
+    turns := export.Turns(h.store.ListEvents(id))
+    w.Header().Set("Content-Type", "application/jsonl")
+    w.Header().Set("Content-Disposition", `attachment; filename="session-`+id+`.jsonl"`)
+    if err := export.WriteOpenAIJSONL(w, id, prompt.System(sess.Style), turns); err != nil {
+        log.Printf("export write error: %v", err)
+    }
 }
 
 // Dev-only: mint worker token
 func (h *Handlers) HandleMintWorkerToken(w http.ResponseWriter, r *http.Request, id string) {
     if !h.devAuthorized(r) {
-        http.Error(w, "forbidden", http.StatusForbidden)
+        errdefs.WriteHTTP(w, &errdefs.AuthError{Reason: "dev endpoint", Forbidden: true})
         return
     }
     if h.cfg.Worker.TokenSecret == "" {
-        http.Error(w, "worker token not configured", http.StatusBadRequest)
+        errdefs.WriteHTTP(w, &errdefs.ConfigError{Key: "WORKER_TOKEN_SECRET", Msg: "not set; worker tokens can't be minted"})
         return
     }
-    if h.store.GetSession(id) == nil {
+    if h.lookup(r, id) == nil {
         http.Error(w, "unknown session", http.StatusNotFound)
         return
     }
@@ -205,14 +451,14 @@ func (h *Handlers) HandleMintWorkerToken(w http.ResponseWriter, r *http.Request,
 // Dev-only: WS URL + token in one shot
 func (h *Handlers) HandleMintWSCreds(w http.ResponseWriter, r *http.Request, id string) {
     if !h.devAuthorized(r) {
-        http.Error(w, "forbidden", http.StatusForbidden)
+        errdefs.WriteHTTP(w, &errdefs.AuthError{Reason: "dev endpoint", Forbidden: true})
         return
     }
     if h.cfg.Worker.TokenSecret == "" {
-        http.Error(w, "worker token not configured", http.StatusBadRequest)
+        errdefs.WriteHTTP(w, &errdefs.ConfigError{Key: "WORKER_TOKEN_SECRET", Msg: "not set; worker tokens can't be minted"})
         return
     }
-    if h.store.GetSession(id) == nil {
+    if h.lookup(r, id) == nil {
         http.Error(w, "unknown session", http.StatusNotFound)
         return
     }
@@ -227,23 +473,39 @@ func (h *Handlers) HandleMintWSCreds(w http.ResponseWriter, r *http.Request, id
 // Dev-only: inject VAD start/end
 func (h *Handlers) HandleDebugVAD(w http.ResponseWriter, r *http.Request, id string, typ string) {
     if !h.devAuthorized(r) {
-        http.Error(w, "forbidden", http.StatusForbidden)
+        errdefs.WriteHTTP(w, &errdefs.AuthError{Reason: "dev endpoint", Forbidden: true})
         return
     }
-    if h.store.GetSession(id) == nil {
+    if h.lookup(r, id) == nil {
         http.Error(w, "unknown session", http.StatusNotFound)
         return
     }
-    if h.onWorkerMsg == nil {
+    if h.bus == nil {
         http.Error(w, "dispatcher not ready", http.StatusServiceUnavailable)
         return
     }
     msg := workerws.Message{Type: typ, TsMs: time.Now().UnixMilli(), SessionID: id, Seq: 0, Payload: map[string]any{"source":"debug"}}
-    h.onWorkerMsg(id, msg)
+    bus.Publish(h.bus, workerws.TopicMessage, id, msg)
     w.Header().Set("Content-Type", "application/json")
     _ = json.NewEncoder(w).Encode(map[string]any{"ok": true})
 }
 
+// defaultStyle layers the tenant's style over the deployment defaults.
+func (h *Handlers) defaultStyle(t *tenant.Tenant) types.SessionStyle {
+    return t.Style.Merge(types.SessionStyle{
+        Persona:     h.cfg.Style.Persona,
+        Verbosity:   h.cfg.Style.Verbosity,
+        Temperature: h.cfg.Style.Temperature,
+        MaxTokens:   h.cfg.Style.MaxTokens,
+    })
+}
+
+// publicStyle hides the tenant's system prompt from API responses.
+func publicStyle(st types.SessionStyle) types.SessionStyle {
+    st.SystemPrompt = ""
+    return st
+}
+
 func (h *Handlers) devAuthorized(r *http.Request) bool {
     if h.cfg.Dev.Mode {
         return true
@@ -0,0 +1,38 @@
+package api
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	metricSessionsCreated = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "api_sessions_created_total",
+		Help: "Sessions created, by tenant",
+	}, []string{"tenant"})
+
+	metricBotStarts = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "api_bot_starts_total",
+		Help: "Bots started, by tenant",
+	}, []string{"tenant"})
+
+	metricQuotaRejections = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "api_quota_rejections_total",
+		Help: "Bot starts refused because the tenant is at max_active_sessions",
+	}, []string{"tenant"})
+
+	metricAuthFailures = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "api_auth_failures_total",
+		Help: "Requests rejected for a missing or unknown tenant API key",
+	}, []string{"reason"})
+
+	metricEventListResponses = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "api_event_list_responses_total",
+		Help: "GET /sessions/{id}/events responses, by content encoding (identity, gzip, zstd) or not_modified",
+	}, []string{"encoding"})
+
+	metricSearches = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "api_searches_total",
+		Help: "GET /search requests answered",
+	})
+)
@@ -0,0 +1,101 @@
+package api
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"yuzu/agent/internal/types"
+)
+
+// netstats.go serves GET /sessions/{id}/network-stats: the WebRTC stats the
+// worker reported (see workerws/netstats.go), newest samples plus a summary
+// over everything the store kept, for call-quality dashboards and checks.
+// The session's clock skew report (see workerws/skew.go) comes with them,
+// null until one has been stored.
+
+const defaultNetworkStatsLimit = 60
+
+// statSummary aggregates one metric over the kept samples.
+type statSummary struct {
+	Avg float64 `json:"avg"`
+	P95 float64 `json:"p95"`
+	Max float64 `json:"max"`
+}
+
+type networkStatsSummary struct {
+	Samples       int          `json:"samples"`
+	FirstAt       *time.Time   `json:"first_at,omitempty"`
+	LastAt        *time.Time   `json:"last_at,omitempty"`
+	RTTMs         *statSummary `json:"rtt_ms,omitempty"`
+	JitterMs      *statSummary `json:"jitter_ms,omitempty"`
+	PacketLossPct *statSummary `json:"packet_loss_pct,omitempty"`
+	AudioLevel    *statSummary `json:"audio_level,omitempty"`
+}
+
+func summarizeNetworkStats(samples []types.NetworkStats) networkStatsSummary {
+	sum := networkStatsSummary{Samples: len(samples)}
+	if len(samples) == 0 {
+		return sum
+	}
+	first, last := samples[0].Ts, samples[len(samples)-1].Ts
+	sum.FirstAt, sum.LastAt = &first, &last
+	pick := func(get func(types.NetworkStats) *float64) *statSummary {
+		var vals []float64
+		for _, s := range samples {
+			if v := get(s); v != nil {
+				vals = append(vals, *v)
+			}
+		}
+		if len(vals) == 0 {
+			return nil
+		}
+		sort.Float64s(vals)
+		total := 0.0
+		for _, v := range vals {
+			total += v
+		}
+		return &statSummary{Avg: total / float64(len(vals)), P95: vals[(len(vals)*95+99)/100-1], Max: vals[len(vals)-1]}
+	}
+	sum.RTTMs = pick(func(s types.NetworkStats) *float64 { return s.RTTMs })
+	sum.JitterMs = pick(func(s types.NetworkStats) *float64 { return s.JitterMs })
+	sum.PacketLossPct = pick(func(s types.NetworkStats) *float64 { return s.PacketLossPct })
+	sum.AudioLevel = pick(func(s types.NetworkStats) *float64 { return s.AudioLevel })
+	return sum
+}
+
+// HandleNetworkStats returns the session's summary and up to ?limit= of its
+// latest samples (default 60).
+func (h *Handlers) HandleNetworkStats(w http.ResponseWriter, r *http.Request, id string) {
+	sess := h.lookup(r, id)
+	if sess == nil {
+		http.NotFound(w, r)
+		return
+	}
+	limit := defaultNetworkStatsLimit
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			http.Error(w, "limit must be a non-negative integer", http.StatusBadRequest)
+			return
+		}
+		limit = n
+	}
+	samples := h.store.ListNetworkStats(id)
+	latest := samples
+	if len(latest) > limit {
+		latest = latest[len(latest)-limit:]
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(map[string]any{
+		"session_id": id,
+		"summary":    summarizeNetworkStats(samples),
+		"samples":    latest,
+		"clock_skew": sess.ClockSkew,
+	}); err != nil {
+		log.Printf("encode error: %v", err)
+	}
+}
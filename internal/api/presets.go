@@ -0,0 +1,105 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+
+	"yuzu/agent/internal/store"
+	"yuzu/agent/internal/types"
+)
+
+// presets.go manages session presets, named templates holding the prompt,
+// flow, voice, VAD thresholds and LLM settings for a kind of interview:
+//
+//	GET    /presets          list the tenant's presets
+//	POST   /presets          create one (409 if the name is taken)
+//	GET    /presets/{name}
+//	PUT    /presets/{name}   create or replace
+//	DELETE /presets/{name}
+//
+// POST /sessions {"preset": "phone-screen"} starts from a preset; the
+// session keeps a copy, so editing a preset only affects new sessions.
+
+const maxPresetBody = 64 << 10
+
+// HandlePresets serves the /presets collection.
+func (h *Handlers) HandlePresets(w http.ResponseWriter, r *http.Request) {
+	t := h.tenantOf(r)
+	switch r.Method {
+	case http.MethodGet:
+		writeJSON(w, http.StatusOK, map[string]any{"presets": h.store.ListPresets(t.ID)})
+	case http.MethodPost:
+		p, ok := decodePreset(w, r, "")
+		if !ok {
+			return
+		}
+		created, err := h.store.CreatePreset(t.ID, p)
+		if errors.Is(err, store.ErrPresetExists) {
+			http.Error(w, "preset "+p.Name+" already exists", http.StatusConflict)
+			return
+		}
+		writeJSON(w, http.StatusCreated, created)
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// HandlePreset serves /presets/{name}.
+func (h *Handlers) HandlePreset(w http.ResponseWriter, r *http.Request, name string) {
+	t := h.tenantOf(r)
+	switch r.Method {
+	case http.MethodGet:
+		p, ok := h.store.GetPreset(t.ID, name)
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		writeJSON(w, http.StatusOK, p)
+	case http.MethodPut:
+		p, ok := decodePreset(w, r, name)
+		if !ok {
+			return
+		}
+		writeJSON(w, http.StatusOK, h.store.PutPreset(t.ID, p))
+	case http.MethodDelete:
+		if !h.store.DeletePreset(t.ID, name) {
+			http.NotFound(w, r)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// decodePreset reads and validates a preset body; name, when set, comes
+// from the URL and must match any name in the body.
+func decodePreset(w http.ResponseWriter, r *http.Request, name string) (types.Preset, bool) {
+	var p types.Preset
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxPresetBody)).Decode(&p); err != nil {
+		http.Error(w, "invalid JSON body: "+err.Error(), http.StatusBadRequest)
+		return p, false
+	}
+	if name != "" {
+		if p.Name != "" && p.Name != name {
+			http.Error(w, "name does not match the URL", http.StatusBadRequest)
+			return p, false
+		}
+		p.Name = name
+	}
+	if err := p.Validate(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return p, false
+	}
+	return p, true
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Printf("encode error: %v", err)
+	}
+}
@@ -5,6 +5,8 @@ import (
 	"strings"
 )
 
+// NewRouter serves the session API, behind CORS when it is configured (see
+// cors.go).
 func NewRouter(h *Handlers) http.Handler {
     mux := http.NewServeMux()
 
@@ -13,16 +15,41 @@ func NewRouter(h *Handlers) http.Handler {
 		w.Write([]byte("ok"))
 	})
 
-	mux.HandleFunc("/sessions", func(w http.ResponseWriter, r *http.Request) {
-		if r.Method == http.MethodPost {
+	// Session routes resolve the caller's tenant from its API key first
+	mux.HandleFunc("/sessions", h.withTenant(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodPost:
 			h.HandleCreateSession(w, r)
 			return
+		case http.MethodGet:
+			h.HandleListSessions(w, r)
+			return
 		}
 		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
-	})
+	}))
 
-    mux.HandleFunc("/sessions/", func(w http.ResponseWriter, r *http.Request) {
-		// /sessions/{id}/start | /end | /events
+	// Transcript search (see search.go)
+	mux.HandleFunc("/search", h.withTenant(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		h.HandleSearch(w, r)
+	}))
+
+	// Session presets (see presets.go)
+	mux.HandleFunc("/presets", h.withTenant(h.HandlePresets))
+	mux.HandleFunc("/presets/", h.withTenant(func(w http.ResponseWriter, r *http.Request) {
+		name := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/presets/"), "/")
+		if name == "" || strings.Contains(name, "/") {
+			http.NotFound(w, r)
+			return
+		}
+		h.HandlePreset(w, r, name)
+	}))
+
+    mux.HandleFunc("/sessions/", h.withTenant(func(w http.ResponseWriter, r *http.Request) {
+		// /sessions/{id} | /start | /end | /events | /export | /network-stats | /context
 		path := strings.TrimSuffix(r.URL.Path, "/")
 		const prefix = "/sessions/"
 		if !strings.HasPrefix(path, prefix) {
@@ -42,6 +69,13 @@ func NewRouter(h *Handlers) http.Handler {
 		}
 
         switch tail {
+        case "":
+            if r.Method != http.MethodGet {
+                http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+                return
+            }
+            h.HandleGetSession(w, r, id)
+            return
         case "start":
             if r.Method != http.MethodPost {
                 http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
@@ -63,6 +97,23 @@ func NewRouter(h *Handlers) http.Handler {
             }
             h.HandleListEvents(w, r, id)
             return
+        case "network-stats":
+            if r.Method != http.MethodGet {
+                http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+                return
+            }
+            h.HandleNetworkStats(w, r, id)
+            return
+        case "export":
+            if r.Method != http.MethodGet {
+                http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+                return
+            }
+            h.HandleExport(w, r, id)
+            return
+        case "context":
+            h.HandleContext(w, r, id)
+            return
         case "worker-token":
             if r.Method != http.MethodPost { http.Error(w, "method not allowed", http.StatusMethodNotAllowed); return }
             h.HandleMintWorkerToken(w, r, id)
@@ -90,7 +141,7 @@ func NewRouter(h *Handlers) http.Handler {
             http.NotFound(w, r)
             return
         }
-    })
+    }))
 
-    return mux
+    return withCORS(h.cfg, mux)
 }
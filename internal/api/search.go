@@ -0,0 +1,238 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"html"
+	"io/fs"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"yuzu/agent/internal/store"
+)
+
+// search.go serves GET /search?q=...: the caller's sessions whose
+// transcripts match, with the matching utterances highlighted. It asks the
+// store's word index (store/search.go), which holds the transcript_final
+// and agent_text events the store keeps and, with STORE_SUMMARY_DIR set,
+// the transcripts of the orchestrator's session summaries; lines truncated
+// from a session's log are found through its summary. An utterance matches
+// when it contains every query word, case-insensitively; a word ending in
+// "*" matches as a prefix, and a "*" with no word before it is refused. A
+// search reads at most maxSearchHits matching utterances, newest sessions
+// first, and says so with "truncated". Sessions with more matching
+// utterances come first, then newer ones. ?limit= caps the sessions
+// returned (default 20, at most 100) and each lists up to
+// maxSearchUtterances matches. The highlight is HTML: the text is escaped
+// and matched words are wrapped in <mark></mark>. Long utterances are cut
+// to about searchSnippetWords words around the first match.
+
+const (
+	defaultSearchLimit  = 20
+	maxSearchLimit      = 100
+	maxSearchUtterances = 5
+	maxSearchHits       = 2000
+	searchSnippetWords  = 30
+)
+
+type searchUtterance struct {
+	Role      string    `json:"role"`   // candidate | agent | interviewer
+	Source    string    `json:"source"` // event | summary
+	TurnID    string    `json:"turn_id,omitempty"`
+	Ts        time.Time `json:"ts"`
+	Text      string    `json:"text"`
+	Highlight string    `json:"highlight"`
+}
+
+type searchResult struct {
+	SessionID  string            `json:"session_id"`
+	CreatedAt  time.Time         `json:"created_at"`
+	Status     string            `json:"status"`
+	Matches    int               `json:"matches"`
+	Utterances []searchUtterance `json:"utterances"`
+}
+
+// HandleSearch searches the caller's transcripts.
+func (h *Handlers) HandleSearch(w http.ResponseWriter, r *http.Request) {
+	terms, err := searchTerms(r.URL.Query().Get("q"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	limit := defaultSearchLimit
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			http.Error(w, "invalid limit", http.StatusBadRequest)
+			return
+		}
+		limit = min(n, maxSearchLimit)
+	}
+
+	// Hits come newest session first, each session's in time order
+	hits, truncated := h.store.SearchUtterances(h.tenantOf(r).ID, terms, maxSearchHits)
+	out := []searchResult{}
+	for _, u := range hits {
+		if len(out) == 0 || out[len(out)-1].SessionID != u.SessionID {
+			s := h.store.GetSession(u.SessionID)
+			if s == nil {
+				continue
+			}
+			out = append(out, searchResult{SessionID: s.ID, CreatedAt: s.CreatedAt, Status: s.Status, Utterances: []searchUtterance{}})
+		}
+		res := &out[len(out)-1]
+		hl, ok := highlight(u.Text, terms)
+		if !ok {
+			continue
+		}
+		res.Matches++
+		if len(res.Utterances) < maxSearchUtterances {
+			res.Utterances = append(res.Utterances, searchUtterance{Role: u.Role, Source: u.Source, TurnID: u.TurnID, Ts: u.Ts, Text: u.Text, Highlight: hl})
+		}
+	}
+	// The stable sort keeps newest first among ties
+	sort.SliceStable(out, func(i, j int) bool { return out[i].Matches > out[j].Matches })
+	if len(out) > limit {
+		out = out[:limit]
+	}
+	metricSearches.Inc()
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(map[string]any{"query": r.URL.Query().Get("q"), "results": out, "truncated": truncated}); err != nil {
+		log.Printf("encode error: %v", err)
+	}
+}
+
+// searchTerms parses q into lowercased words for the index.
+func searchTerms(q string) ([]store.SearchTerm, error) {
+	var out []store.SearchTerm
+	for _, f := range strings.Fields(strings.ToLower(q)) {
+		ws := words(f)
+		if !strings.HasSuffix(f, "*") {
+			for _, w := range ws {
+				out = append(out, store.SearchTerm{Word: w})
+			}
+			continue
+		}
+		// The * belongs to the word right before it, which would otherwise
+		// match everything
+		if len(ws) == 0 || !strings.HasSuffix(strings.TrimRight(f, "*"), ws[len(ws)-1]) {
+			return nil, errors.New("q has a * with no word before it")
+		}
+		for i, w := range ws {
+			out = append(out, store.SearchTerm{Word: w, Prefix: i == len(ws)-1})
+		}
+	}
+	if len(out) == 0 {
+		return nil, errors.New("q must contain at least one word")
+	}
+	return out, nil
+}
+
+func termMatches(t store.SearchTerm, word string) bool {
+	if t.Prefix {
+		return strings.HasPrefix(word, t.Word)
+	}
+	return word == t.Word
+}
+
+// highlight marks the words of text that match terms, trimmed to a snippet
+// around the first; ok is false unless every term matches.
+func highlight(text string, terms []store.SearchTerm) (string, bool) {
+	spans := store.WordSpans(text)
+	found := make([]bool, len(terms))
+	var marked []int // indexes into spans
+	for i, sp := range spans {
+		w := strings.ToLower(text[sp[0]:sp[1]])
+		hit := false
+		for j, t := range terms {
+			if termMatches(t, w) {
+				found[j], hit = true, true
+			}
+		}
+		if hit {
+			marked = append(marked, i)
+		}
+	}
+	for _, f := range found {
+		if !f {
+			return "", false
+		}
+	}
+
+	// Snippet of about searchSnippetWords words starting a little before
+	// the first match
+	from := max(0, marked[0]-searchSnippetWords/4)
+	to := min(len(spans), from+searchSnippetWords)
+	start, end := 0, len(text)
+	if from > 0 {
+		start = spans[from][0]
+	}
+	if to < len(spans) {
+		end = spans[to-1][1]
+	}
+	var b strings.Builder
+	if start > 0 {
+		b.WriteString("…")
+	}
+	pos := start
+	for _, i := range marked {
+		if i < from || i >= to {
+			continue
+		}
+		b.WriteString(html.EscapeString(text[pos:spans[i][0]]))
+		b.WriteString("<mark>" + html.EscapeString(text[spans[i][0]:spans[i][1]]) + "</mark>")
+		pos = spans[i][1]
+	}
+	b.WriteString(html.EscapeString(text[pos:end]))
+	if end < len(text) {
+		b.WriteString("…")
+	}
+	return b.String(), true
+}
+
+func words(s string) []string {
+	var out []string
+	for _, sp := range store.WordSpans(s) {
+		out = append(out, s[sp[0]:sp[1]])
+	}
+	return out
+}
+
+// indexSummary adds the transcript of the orchestrator's summary of the
+// session to the search index. The orchestrator writes it before it acks
+// the gateway's SessionClose, so it is there by the time the bot exits.
+func (h *Handlers) indexSummary(sessionID string) {
+	dir := h.cfg.Store.SummaryDir
+	if dir == "" || sessionID == "" || sessionID != filepath.Base(sessionID) || strings.HasPrefix(sessionID, ".") {
+		return
+	}
+	b, err := os.ReadFile(filepath.Join(dir, sessionID+".json"))
+	if err != nil {
+		if !errors.Is(err, fs.ErrNotExist) {
+			log.Printf("read session summary %s: %v", sessionID, err)
+		}
+		return
+	}
+	var sum struct {
+		Transcript []struct {
+			At   time.Time `json:"at"`
+			Role string    `json:"role"`
+			Text string    `json:"text"`
+		} `json:"transcript"`
+	}
+	if err := json.Unmarshal(b, &sum); err != nil {
+		log.Printf("decode session summary %s: %v", sessionID, err)
+		return
+	}
+	lines := make([]store.Utterance, 0, len(sum.Transcript))
+	for _, e := range sum.Transcript {
+		lines = append(lines, store.Utterance{Role: e.Role, Ts: e.At, Text: e.Text})
+	}
+	h.store.IndexSummary(sessionID, lines)
+}
@@ -0,0 +1,146 @@
+package api
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"strconv"
+	"strings"
+
+	"yuzu/agent/internal/floor"
+	"yuzu/agent/internal/tenant"
+	"yuzu/agent/internal/types"
+)
+
+// snapshot.go records, when a session's bot starts, the configuration it
+// was started with as a config_snapshot event: the resolved style and
+// prompt version, which voice and STT path it uses and where each came
+// from, the barge-in thresholds, the turn policy, the feature flags and
+// the recording consent.
+// Together with the preset snapshot on the session, it answers "what
+// settings produced this conversation" after the fact, even once the
+// tenant, preset or deployment config has moved on. Prompts appear only
+// as a version (the first 12 hex digits of their SHA-256), never as text,
+// and no credentials are included.
+//
+// Values are the ones the bot runs with, not just what the session set:
+// STT is always the worker's own sidecar connection (STT_UDS_PATH), since
+// the bundled bot doesn't use the relay even when WORKER_STT_RELAY offers
+// it, and a barge-in threshold the session left unset is the one the bot
+// falls back to. Each threshold says where it came from: "session" (the
+// session, its preset or profile), "deployment" (the API server's own
+// environment, which the bot inherits) or "bot_default". An unset hangover
+// is left to the orchestrator and recorded as null.
+
+// The bot's barge-in thresholds when neither the session nor the
+// deployment sets them (gateway/main.py).
+const (
+	botDefaultMinRMS  = 1200
+	botDefaultGuardMs = 1200
+)
+
+// configSnapshot is the payload of the config_snapshot event for sess,
+// started by tenant t with the worker environment env.
+func (h *Handlers) configSnapshot(sess *types.Session, t *tenant.Tenant, env map[string]string) map[string]any {
+	voiceSource := "deployment"
+	switch {
+	case sess.VoiceID != "":
+		voiceSource = "session"
+	case t.VoiceID != "":
+		voiceSource = "tenant"
+	}
+	stt := map[string]any{"mode": "worker", "uds_path": env["STT_UDS_PATH"], "relay_available": h.relayConfigured()}
+
+	policy := sess.TurnPolicy
+	if policy == "" {
+		policy = "default"
+	}
+	def := floor.Policy{Interject: h.cfg.Floor.InterjectionMode, MaxInterjectMs: int64(h.cfg.Floor.MaxInterjectionMs)}
+	strategy, _ := floor.StrategyFor(sess.TurnPolicy, def)
+	localStop := h.cfg.Worker.LocalStopEnabled && strategy != nil && strategy.LocalStop()
+
+	s := sess.Style
+	return map[string]any{
+		"tenant_id": t.ID,
+		"preset":    sess.Preset,
+		"style": map[string]any{
+			"persona":              s.Persona,
+			"verbosity":            s.Verbosity,
+			"temperature":          s.Temperature,
+			"max_tokens":           s.MaxTokens,
+			"max_duration_seconds": s.MaxDurationSeconds,
+		},
+		"prompt": map[string]any{
+			// "" means the orchestrator builds it from persona and verbosity
+			"system_prompt_version": promptVersion(s.SystemPrompt),
+			"instructions_version":  promptVersion(s.Instructions),
+		},
+		"providers": map[string]any{
+			"tts": map[string]any{"provider": "elevenlabs", "voice_id": env["ELEVENLABS_VOICE_ID"], "voice_source": voiceSource},
+			"stt": stt,
+		},
+		"vad": vadSnapshot(env),
+		"turn": map[string]any{
+			"policy":              policy,
+			"interjection_mode":   h.cfg.Floor.InterjectionMode,
+			"max_interjection_ms": h.cfg.Floor.MaxInterjectionMs,
+			"local_stop_enabled":  localStop,
+		},
+		"features": map[string]any{
+			"captions":     s.Captions,
+			"token_stream": s.TokenStream,
+			"echo_test":    s.EchoTest,
+			"flow":         len(sess.Flow) > 0,
+			"context_docs": len(h.store.ListContextDocs(sess.ID)),
+			"worker_ws":    env["WS_URL"] != "",
+		},
+		"consent": sess.Consent,
+	}
+}
+
+// vadSnapshot is the barge-in tuning the bot started with env runs with.
+func vadSnapshot(env map[string]string) map[string]any {
+	source := map[string]any{}
+	// setting is key's value from the bot's env or, failing that, the one
+	// it inherits, if either holds at least min.
+	setting := func(name, key string, min int) (int, bool) {
+		for _, src := range []struct{ from, v string }{{"session", env[key]}, {"deployment", os.Getenv(key)}} {
+			if n, err := strconv.Atoi(strings.TrimSpace(src.v)); err == nil && n >= min {
+				source[name] = src.from
+				return n, true
+			}
+		}
+		return 0, false
+	}
+	out := map[string]any{"profile": env["BARGE_IN_PROFILE"], "hangover": nil, "source": source}
+	for _, th := range []struct {
+		name, key string
+		def       int
+	}{{"min_rms", "LOCAL_STOP_MIN_RMS", botDefaultMinRMS}, {"guard_ms", "LOCAL_STOP_GUARD_MS", botDefaultGuardMs}} {
+		n, ok := setting(th.name, th.key, 0)
+		if !ok {
+			n, source[th.name] = th.def, "bot_default"
+		}
+		out[th.name] = n
+	}
+	source["hangover"] = "orchestrator"
+	if n, ok := setting("hangover", "LOCAL_STOP_HANGOVER_FRAMES", 1); ok {
+		out["hangover"] = n
+	}
+	return out
+}
+
+// relayConfigured mirrors workerws: WORKER_STT_RELAY other than "off".
+func (h *Handlers) relayConfigured() bool {
+	v := strings.TrimSpace(h.cfg.Worker.STTRelay)
+	return v != "" && v != "off"
+}
+
+// promptVersion identifies a prompt's text without recording it.
+func promptVersion(text string) string {
+	if text == "" {
+		return ""
+	}
+	sum := sha256.Sum256([]byte(text))
+	return hex.EncodeToString(sum[:6])
+}
@@ -0,0 +1,90 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"yuzu/agent/internal/errdefs"
+	"yuzu/agent/internal/tenant"
+	"yuzu/agent/internal/types"
+)
+
+// withTenant authenticates the caller's API key and stores the tenant in the
+// request context for the handlers.
+func (h *Handlers) withTenant(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		t, err := h.tenants.Authenticate(r)
+		if err != nil {
+			if ae, ok := err.(*errdefs.AuthError); ok {
+				metricAuthFailures.WithLabelValues(ae.Reason).Inc()
+			}
+			errdefs.WriteHTTP(w, err)
+			return
+		}
+		next(w, r.WithContext(tenant.WithContext(r.Context(), t)))
+	}
+}
+
+// tenantOf returns the request's tenant; the default tenant when the request
+// did not pass through withTenant.
+func (h *Handlers) tenantOf(r *http.Request) *tenant.Tenant {
+	if t := tenant.FromContext(r.Context()); t != nil {
+		return t
+	}
+	return &tenant.Tenant{ID: tenant.DefaultID}
+}
+
+// lookup returns session id if it belongs to the request's tenant. Sessions
+// of other tenants are reported as missing so IDs don't leak across tenants.
+func (h *Handlers) lookup(r *http.Request, id string) *types.Session {
+	sess := h.store.GetSession(id)
+	if sess == nil || sess.TenantID != h.tenantOf(r).ID {
+		return nil
+	}
+	return sess
+}
+
+// reserveStart counts a bot start of session id against t's quota until
+// releaseStart, so concurrent starts can't overshoot it while runner.Start
+// runs without h.startMu. A refusal comes with the reason recorded in
+// bot_start_rejected.
+func (h *Handlers) reserveStart(id string, t *tenant.Tenant) (string, error) {
+	h.startMu.Lock()
+	defer h.startMu.Unlock()
+	if _, ok := h.starting[id]; ok {
+		return "start_in_progress", &errdefs.Backpressure{Resource: "bot start of session " + id, RetryAfter: time.Second}
+	}
+	if err := h.checkQuota(t); err != nil {
+		return "tenant_quota", err
+	}
+	h.starting[id] = t.ID
+	return "", nil
+}
+
+// releaseStart ends the reservation made by reserveStart; by then the bot is
+// running (and counted by the store) or failed to start.
+func (h *Handlers) releaseStart(id string) {
+	h.startMu.Lock()
+	delete(h.starting, id)
+	h.startMu.Unlock()
+}
+
+// checkQuota refuses a bot start when the tenant is at its active session
+// cap, counting starts in flight. Callers hold h.startMu.
+func (h *Handlers) checkQuota(t *tenant.Tenant) error {
+	if t.MaxActiveSessions <= 0 {
+		return nil
+	}
+	n := h.store.CountRunning(t.ID)
+	for _, tid := range h.starting {
+		if tid == t.ID {
+			n++
+		}
+	}
+	if n < t.MaxActiveSessions {
+		return nil
+	}
+	metricQuotaRejections.WithLabelValues(t.ID).Inc()
+	return &errdefs.Backpressure{Resource: "tenant " + t.ID + " active sessions (max " + strconv.Itoa(t.MaxActiveSessions) + ")", RetryAfter: 30 * time.Second}
+}
@@ -0,0 +1,57 @@
+package bot
+
+import (
+	"errors"
+	"os/exec"
+
+	"yuzu/agent/internal/bus"
+)
+
+// Worker process lifecycle on the bus (see package bus).
+var (
+	TopicStarted = bus.NewTopic[Started]("bot.started")
+	TopicExited  = bus.NewTopic[Exited]("bot.exited")
+	TopicLog     = bus.NewTopic[LogLine]("bot.log")
+)
+
+// Started is published once a session's worker process is running.
+type Started struct {
+	PID int
+}
+
+// Exited is published when a worker process exits, naturally or killed.
+type Exited struct {
+	Err  error
+	Code int // exit status; 1 when the process didn't report one
+}
+
+// LogLine is one line of a worker's stdout or stderr.
+type LogLine struct {
+	Stream string
+	Line   string
+}
+
+// Publishers returns LocalRunner callbacks that publish on b.
+func Publishers(b *bus.Bus) (ExitCallback, LogCallback, StartCallback) {
+	onExit := func(sessionID string, err error) {
+		bus.Publish(b, TopicExited, sessionID, Exited{Err: err, Code: exitCode(err)})
+	}
+	onLog := func(sessionID, stream, line string) {
+		bus.Publish(b, TopicLog, sessionID, LogLine{Stream: stream, Line: line})
+	}
+	onStart := func(sessionID string, pid int) {
+		bus.Publish(b, TopicStarted, sessionID, Started{PID: pid})
+	}
+	return onExit, onLog, onStart
+}
+
+func exitCode(err error) int {
+	if err == nil {
+		return 0
+	}
+	var ee *exec.ExitError
+	if errors.As(err, &ee) {
+		return ee.ExitCode()
+	}
+	return 1
+}
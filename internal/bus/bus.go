@@ -0,0 +1,132 @@
+// Package bus is the API server's in-process event bus. Modules publish
+// what happened on a topic (worker.message, bot.exited, session.created,
+// ...) without knowing who listens, and new consumers such as webhooks or
+// analytics subscribe without changes to the publishers or to main's
+// wiring.
+//
+// Topics are declared next to their publisher with NewTopic, which fixes
+// the payload type; On and Publish are the typed way in and out. Subscribe
+// takes a pattern instead, "worker.*" or "*", and sees the payload as any.
+// Delivery is synchronous, in publish order and then subscription order, so
+// a subscriber sees a session's events in the order they happened; one that
+// does slow work should hand it to its own goroutine. A subscriber that
+// panics is logged and counted in bus_subscriber_panics_total{topic}, and
+// the others still run. Published events are counted in
+// bus_events_total{topic}.
+package bus
+
+import (
+	"log"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Event is one published event.
+type Event struct {
+	Topic     string
+	SessionID string
+	Time      time.Time
+	Data      any
+}
+
+// Topic names a topic whose events carry a T.
+type Topic[T any] struct{ name string }
+
+// NewTopic declares a topic; name is "<module>.<what>", e.g. "bot.exited".
+func NewTopic[T any](name string) Topic[T] { return Topic[T]{name: name} }
+
+func (t Topic[T]) Name() string { return t.name }
+
+type subscription struct {
+	id      int
+	pattern string
+	fn      func(Event)
+}
+
+// Bus delivers events to subscribers. A nil *Bus drops everything and its
+// subscriptions are no-ops, so modules built without one (as in tests)
+// need no checks.
+type Bus struct {
+	mu     sync.RWMutex
+	nextID int
+	subs   []subscription
+}
+
+func New() *Bus { return &Bus{} }
+
+// Subscribe calls fn for every event whose topic matches pattern: a topic
+// name, a prefix ending in ".*" for everything under it, or "*". It
+// returns a func that removes the subscription.
+func (b *Bus) Subscribe(pattern string, fn func(Event)) (unsubscribe func()) {
+	if b == nil {
+		return func() {}
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.nextID++
+	id := b.nextID
+	b.subs = append(b.subs, subscription{id: id, pattern: pattern, fn: fn})
+	return func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		for i, s := range b.subs {
+			if s.id == id {
+				b.subs = append(b.subs[:i:i], b.subs[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+// On subscribes fn to topic t with its payload typed.
+func On[T any](b *Bus, t Topic[T], fn func(sessionID string, v T)) (unsubscribe func()) {
+	return b.Subscribe(t.name, func(e Event) {
+		v, _ := e.Data.(T)
+		fn(e.SessionID, v)
+	})
+}
+
+// Publish delivers v on topic t to the current subscribers and returns
+// once all of them have run.
+func Publish[T any](b *Bus, t Topic[T], sessionID string, v T) {
+	b.publish(Event{Topic: t.name, SessionID: sessionID, Time: time.Now(), Data: v})
+}
+
+func (b *Bus) publish(e Event) {
+	if b == nil {
+		return
+	}
+	metricEvents.WithLabelValues(e.Topic).Inc()
+	b.mu.RLock()
+	var fns []func(Event)
+	for _, s := range b.subs {
+		if matches(s.pattern, e.Topic) {
+			fns = append(fns, s.fn)
+		}
+	}
+	b.mu.RUnlock()
+	for _, fn := range fns {
+		deliver(fn, e)
+	}
+}
+
+func deliver(fn func(Event), e Event) {
+	defer func() {
+		if r := recover(); r != nil {
+			metricPanics.WithLabelValues(e.Topic).Inc()
+			log.Printf("bus: subscriber to %s panicked (session=%s): %v", e.Topic, e.SessionID, r)
+		}
+	}()
+	fn(e)
+}
+
+func matches(pattern, topic string) bool {
+	switch {
+	case pattern == "*":
+		return true
+	case strings.HasSuffix(pattern, ".*"):
+		return strings.HasPrefix(topic, pattern[:len(pattern)-1])
+	}
+	return pattern == topic
+}
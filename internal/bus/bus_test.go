@@ -0,0 +1,64 @@
+package bus
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTypedAndPatternDelivery(t *testing.T) {
+	b := New()
+	msgs := NewTopic[string]("worker.message")
+	exits := NewTopic[int]("bot.exited")
+
+	var got []string
+	On(b, msgs, func(sessionID string, v string) { got = append(got, "typed:"+sessionID+":"+v) })
+	b.Subscribe("worker.*", func(e Event) { got = append(got, "worker.*:"+e.Topic) })
+	b.Subscribe("*", func(e Event) { got = append(got, "*:"+e.Topic) })
+
+	Publish(b, msgs, "s1", "hello")
+	Publish(b, exits, "s1", 2)
+
+	want := []string{"typed:s1:hello", "worker.*:worker.message", "*:worker.message", "*:bot.exited"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("delivered %q, want %q", got, want)
+	}
+}
+
+func TestUnsubscribeAndPanics(t *testing.T) {
+	b := New()
+	topic := NewTopic[int]("session.created")
+	n := 0
+	On(b, topic, func(string, int) { panic("boom") })
+	off := On(b, topic, func(_ string, v int) { n += v })
+
+	Publish(b, topic, "s1", 1)
+	off()
+	Publish(b, topic, "s1", 1)
+	if n != 1 {
+		t.Fatalf("subscriber ran %d times, want once before unsubscribing", n)
+	}
+
+	// A nil bus drops events and takes subscriptions that never fire
+	var nb *Bus
+	On(nb, topic, func(string, int) { t.Error("nil bus delivered an event") })()
+	nb.Subscribe("*", func(Event) { t.Error("nil bus delivered an event") })()
+	Publish(nb, topic, "s1", 1)
+}
+
+func TestMatches(t *testing.T) {
+	for _, c := range []struct {
+		pattern, topic string
+		want           bool
+	}{
+		{"worker.message", "worker.message", true},
+		{"worker.*", "worker.disconnected", true},
+		{"worker.*", "workers.x", false},
+		{"bot.*", "worker.message", false},
+		{"*", "session.created", true},
+		{"session.created", "session.completed", false},
+	} {
+		if got := matches(c.pattern, c.topic); got != c.want {
+			t.Errorf("matches(%q, %q) = %v", c.pattern, c.topic, got)
+		}
+	}
+}
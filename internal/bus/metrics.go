@@ -0,0 +1,17 @@
+package bus
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	metricEvents = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "bus_events_total",
+		Help: "Events published on the in-process bus, by topic",
+	}, []string{"topic"})
+	metricPanics = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "bus_subscriber_panics_total",
+		Help: "Bus subscribers that panicked while handling an event, by topic",
+	}, []string{"topic"})
+)
@@ -0,0 +1,135 @@
+// Package chaos injects faults into provider calls and stream sends so the
+// retries, fallbacks and circuit breakers around them can be exercised in
+// staging. Nothing is injected unless CHAOS_ENABLED is true.
+//
+// Each call site names a target and reads its rates from
+// CHAOS_<TARGET>_DELAY_P, CHAOS_<TARGET>_DROP_P and CHAOS_<TARGET>_ERROR_P,
+// probabilities from 0 to 1. A delayed call first waits a random time up to
+// CHAOS_<TARGET>_DELAY_MS (default 500) and may then also be dropped or
+// failed. A dropped call never gets an answer: a provider call hangs until
+// its context ends, and a send is discarded while reporting success. A
+// failed call looks like the provider or peer returned a retryable error.
+// Faults are counted in chaos_faults_total{target,fault}.
+package chaos
+
+import (
+	"context"
+	"errors"
+	"log"
+	"math/rand"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// Targets wired into the services.
+const (
+	TargetSTT      = "stt"       // Deepgram connects (internal/stt)
+	TargetLLM      = "llm"       // Azure OpenAI requests (internal/llm)
+	TargetTTS      = "tts"       // ElevenLabs requests (internal/tts)
+	TargetOrchSend = "orch_send" // orchestrator commands to the gateway
+)
+
+// Fault is what Inject decided for one call, after any delay.
+type Fault int
+
+const (
+	None Fault = iota
+	Drop
+	Error
+)
+
+// ErrInjected is the cause of every injected error.
+var ErrInjected = errors.New("chaos: injected fault")
+
+var metricFaults = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "chaos_faults_total",
+	Help: "Faults injected by target and kind (delay, drop, error)",
+}, []string{"target", "fault"})
+
+// Injector decides the faults for one target. A nil Injector injects none.
+type Injector struct {
+	target   string
+	delayP   float64
+	dropP    float64
+	errorP   float64
+	maxDelay time.Duration
+
+	mu  sync.Mutex
+	rnd *rand.Rand
+}
+
+// New returns an Injector for target with the given rates, or nil when all
+// of them are zero.
+func New(target string, delayP, dropP, errorP float64, maxDelay time.Duration) *Injector {
+	if delayP <= 0 && dropP <= 0 && errorP <= 0 {
+		return nil
+	}
+	return &Injector{target: target, delayP: delayP, dropP: dropP, errorP: errorP, maxDelay: maxDelay,
+		rnd: rand.New(rand.NewSource(time.Now().UnixNano()))}
+}
+
+// FromEnv returns the Injector for target, or nil when chaos is disabled or
+// the target has no rates set.
+func FromEnv(target string) *Injector {
+	if on, _ := strconv.ParseBool(os.Getenv("CHAOS_ENABLED")); !on {
+		return nil
+	}
+	prefix := "CHAOS_" + strings.ToUpper(target) + "_"
+	maxDelay := 500 * time.Millisecond
+	if v, err := strconv.Atoi(os.Getenv(prefix + "DELAY_MS")); err == nil && v >= 0 {
+		maxDelay = time.Duration(v) * time.Millisecond
+	}
+	in := New(target, envProb(prefix+"DELAY_P"), envProb(prefix+"DROP_P"), envProb(prefix+"ERROR_P"), maxDelay)
+	if in != nil {
+		log.Printf("[chaos] %s: delay=%.2f (up to %s) drop=%.2f error=%.2f", target, in.delayP, maxDelay, in.dropP, in.errorP)
+	}
+	return in
+}
+
+func envProb(key string) float64 {
+	p, err := strconv.ParseFloat(os.Getenv(key), 64)
+	if err != nil || p < 0 {
+		return 0
+	}
+	return min(p, 1)
+}
+
+// Inject rolls the faults for one call, waiting out any delay (cut short
+// when ctx ends), and returns the fault the caller should act out.
+func (in *Injector) Inject(ctx context.Context) Fault {
+	if in == nil {
+		return None
+	}
+	in.mu.Lock()
+	delay := time.Duration(0)
+	if in.rnd.Float64() < in.delayP {
+		delay = time.Duration(in.rnd.Int63n(int64(in.maxDelay) + 1))
+	}
+	roll := in.rnd.Float64()
+	in.mu.Unlock()
+
+	if delay > 0 {
+		metricFaults.WithLabelValues(in.target, "delay").Inc()
+		t := time.NewTimer(delay)
+		select {
+		case <-t.C:
+		case <-ctx.Done():
+			t.Stop()
+		}
+	}
+	switch {
+	case roll < in.dropP:
+		metricFaults.WithLabelValues(in.target, "drop").Inc()
+		return Drop
+	case roll < in.dropP+in.errorP:
+		metricFaults.WithLabelValues(in.target, "error").Inc()
+		return Error
+	}
+	return None
+}
@@ -0,0 +1,59 @@
+package chaos
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestFromEnv(t *testing.T) {
+	t.Setenv("CHAOS_LLM_ERROR_P", "0.2")
+	if FromEnv(TargetLLM) != nil {
+		t.Fatal("rates should be ignored without CHAOS_ENABLED")
+	}
+	t.Setenv("CHAOS_ENABLED", "true")
+	if FromEnv(TargetTTS) != nil {
+		t.Fatal("a target without rates should get no injector")
+	}
+	t.Setenv("CHAOS_LLM_DROP_P", "2")
+	t.Setenv("CHAOS_LLM_DELAY_MS", "50")
+	in := FromEnv(TargetLLM)
+	if in == nil || in.errorP != 0.2 || in.dropP != 1 || in.maxDelay != 50*time.Millisecond {
+		t.Fatalf("injector = %+v", in)
+	}
+}
+
+func TestInjectRates(t *testing.T) {
+	in := New(TargetOrchSend, 0, 0.1, 0.3, 0)
+	counts := map[Fault]int{}
+	const n = 20000
+	for i := 0; i < n; i++ {
+		counts[in.Inject(context.Background())]++
+	}
+	for f, want := range map[Fault]float64{Drop: 0.1, Error: 0.3, None: 0.6} {
+		if got := float64(counts[f]) / n; math.Abs(got-want) > 0.02 {
+			t.Errorf("fault %d rate = %.3f, want about %.1f", f, got, want)
+		}
+	}
+}
+
+func TestInjectDelayEndsWithContext(t *testing.T) {
+	in := New(TargetSTT, 1, 0, 0, time.Hour)
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if f := in.Inject(ctx); f != None {
+		t.Fatalf("fault = %d, want None", f)
+	}
+	if d := time.Since(start); d > time.Second {
+		t.Fatalf("delay outlived its context: %s", d)
+	}
+}
+
+func TestNilInjector(t *testing.T) {
+	var in *Injector
+	if in.Inject(context.Background()) != None {
+		t.Fatal("nil injector should inject nothing")
+	}
+}
@@ -1,11 +1,14 @@
 package config
 
 import (
+	"errors"
 	"fmt"
 	"log"
 	"strings"
 
 	"github.com/spf13/viper"
+
+	"yuzu/agent/internal/secrets"
 )
 
 type Config struct {
@@ -31,6 +34,9 @@ type Config struct {
     Bot struct {
         WorkerCmd            string
         StayConnectedSeconds string
+        // EndGraceSeconds is how long a bot gets to leave on its own after
+        // the interview ends before the API stops it
+        EndGraceSeconds int
     }
     Eleven struct {
         APIKey       string
@@ -42,14 +48,79 @@ type Config struct {
         TokenTTLSecs      int
         TokenSkewSecs     int
         LocalStopEnabled  bool
+        // RateLimits caps worker WS messages per second by class
+        // ("vad=10,default=100"); RateLimitMaxDrops disconnects a worker
+        // that drops that many within ten seconds (0 never does)
+        RateLimits        string
+        RateLimitMaxDrops int
+        // DrainTimeoutMs is how long shutdown waits for each worker to
+        // answer server_shutdown before closing it (see workerws.Drain)
+        DrainTimeoutMs    int
+        // STTRelay is the STT sidecar a worker's binary audio frames are
+        // relayed to (see workerws/sttrelay.go); "off" disables the relay
+        STTRelay          string
     }
     Floor struct {
         TTSTimeoutSeconds int
+        // InterjectionMode is off, duck or hold: whether speech over the
+        // agent pauses it (see floor.Policy) instead of stopping it
+        InterjectionMode  string
+        MaxInterjectionMs int
+    }
+    // Store bounds the in-memory session store and, with PersistPath set,
+    // persists events through a write-behind buffer (see store/writebehind.go)
+    Store struct {
+        MaxSessions int
+        PersistPath string
+        FlushMs     int
+        FlushBatch  int
+        QueueMax    int
+        FsyncMs     int
+        // EventRetention caps each session's events by class, and events
+        // over it go to SpillPath when set (see store/retention.go)
+        EventRetention string
+        SpillPath      string
+        // SummaryDir is where the orchestrator writes session summaries
+        // (its ORCH_STATE_DIR); their transcripts are added to the search
+        // index when the bot exits. Empty indexes events only
+        SummaryDir string
+    }
+    // Style holds the default response style for new sessions
+    Style struct {
+        Persona     string
+        Verbosity   string
+        Temperature *float64 // nil unless LLM_TEMPERATURE is set
+        MaxTokens   int
+    }
+    // Tenants points at the tenants file; empty runs single-tenant
+    Tenants struct {
+        File string
+    }
+    // CORS lets browser dashboards call the API. Lists are comma-separated;
+    // an origin may be "*" or hold one "*" wildcard ("https://*.example.com").
+    // With no origins set, dev mode allows localhost on any port. "*" with
+    // credentials would let any site act as a signed-in user, so Validate
+    // refuses it.
+    CORS struct {
+        AllowedOrigins   string
+        AllowedMethods   string
+        AllowedHeaders   string
+        ExposedHeaders   string
+        AllowCredentials bool
+        MaxAgeSecs       int
     }
     Dev struct {
         Mode bool
         Key  string
     }
+    // Health locates the services POST /healthz/providers probes at
+    // /readyz; "off" skips the service
+    Health struct {
+        OrchestratorURL string
+        STTURL          string
+        LLMURL          string
+        TTSURL          string
+    }
 }
 
 func Load() Config {
@@ -73,20 +144,37 @@ func Load() Config {
 	v.SetDefault("daily.enable_noise_cancel_ui", true)
 
 	v.SetDefault("bot.stay_connected_seconds", 30)
+	v.SetDefault("bot.end_grace_seconds", 30)
 
     v.SetDefault("elevenlabs.canned_phrase", "Hi, I'm your AI interviewer. Can you hear me clearly?")
 
     v.SetDefault("worker.token_ttl_seconds", 1800)
     v.SetDefault("worker.token_skew_seconds", 60)
     v.SetDefault("worker.local_stop_enabled", true)
+    v.SetDefault("worker.rate_limits", "vad=10,default=100")
+    v.SetDefault("worker.rate_limit_max_drops", 500)
+    v.SetDefault("worker.drain_timeout_ms", 2000)
+    v.SetDefault("worker.stt_relay", "off")
     v.SetDefault("floor.tts_timeout_seconds", 60)
+    v.SetDefault("floor.interjection_mode", "off")
+    v.SetDefault("floor.max_interjection_ms", 1500)
+    v.SetDefault("store.max_sessions", 1000)
+    v.SetDefault("store.flush_ms", 200)
+    v.SetDefault("store.flush_batch", 500)
+    v.SetDefault("store.queue_max", 50000)
+    v.SetDefault("store.fsync_ms", 1000)
+    v.SetDefault("style.persona", "friendly")
+    v.SetDefault("style.verbosity", "normal")
 
+    v.SetDefault("cors.allowed_methods", "GET,POST,PUT,DELETE,OPTIONS")
+    v.SetDefault("cors.allowed_headers", "Authorization,Content-Type,X-API-Key,X-Dev-Key,If-None-Match")
+    v.SetDefault("cors.exposed_headers", "ETag,Retry-After,X-Error-Class")
+    v.SetDefault("cors.max_age_seconds", 600)
     v.SetDefault("dev.mode", false)
 	// Map envs
 	v.BindEnv("server.port", "PORT")
 	v.BindEnv("server.log_level", "LOG_LEVEL")
 
-	v.BindEnv("daily.api_key", "DAILY_API_KEY")
 	v.BindEnv("daily.domain", "DAILY_DOMAIN")
 	v.BindEnv("daily.room_prefix", "DAILY_ROOM_PREFIX")
 	v.BindEnv("daily.room_privacy", "DAILY_ROOM_PRIVACY")
@@ -101,24 +189,58 @@ func Load() Config {
 
 	v.BindEnv("bot.worker_cmd", "BOT_WORKER_CMD")
 	v.BindEnv("bot.stay_connected_seconds", "BOT_STAY_CONNECTED_SECONDS")
+	v.BindEnv("bot.end_grace_seconds", "BOT_END_GRACE_SECONDS")
 
-	v.BindEnv("elevenlabs.api_key", "ELEVENLABS_API_KEY")
 	v.BindEnv("elevenlabs.voice_id", "ELEVENLABS_VOICE_ID")
     v.BindEnv("elevenlabs.canned_phrase", "ELEVENLABS_CANNED_PHRASE")
 
-    v.BindEnv("worker.token_secret", "WORKER_TOKEN_SECRET")
     v.BindEnv("worker.token_ttl_seconds", "WORKER_TOKEN_TTL_SECONDS")
     v.BindEnv("worker.token_skew_seconds", "WORKER_TOKEN_SKEW_SECONDS")
     v.BindEnv("worker.local_stop_enabled", "WORKER_LOCAL_STOP_ENABLED")
+    v.BindEnv("worker.rate_limits", "WORKER_WS_RATE_LIMITS")
+    v.BindEnv("worker.rate_limit_max_drops", "WORKER_WS_RATE_LIMIT_MAX_DROPS")
+    v.BindEnv("worker.drain_timeout_ms", "WORKER_DRAIN_TIMEOUT_MS")
+    v.BindEnv("worker.stt_relay", "WORKER_STT_RELAY")
     v.BindEnv("floor.tts_timeout_seconds", "FLOOR_TTS_TIMEOUT_SECONDS")
+    v.BindEnv("floor.interjection_mode", "FLOOR_INTERJECTION_MODE")
+    v.BindEnv("floor.max_interjection_ms", "FLOOR_MAX_INTERJECTION_MS")
+    v.BindEnv("store.max_sessions", "STORE_MAX_SESSIONS")
+    v.BindEnv("store.persist_path", "STORE_PERSIST_PATH")
+    v.BindEnv("store.flush_ms", "STORE_FLUSH_MS")
+    v.BindEnv("store.flush_batch", "STORE_FLUSH_BATCH")
+    v.BindEnv("store.queue_max", "STORE_QUEUE_MAX")
+    v.BindEnv("store.fsync_ms", "STORE_FSYNC_MS")
+    v.BindEnv("store.event_retention", "STORE_EVENT_RETENTION")
+    v.BindEnv("store.spill_path", "STORE_EVENT_SPILL_PATH")
+    v.BindEnv("store.summary_dir", "STORE_SUMMARY_DIR")
+    v.BindEnv("style.persona", "LLM_PERSONA")
+    v.BindEnv("style.verbosity", "LLM_VERBOSITY")
+    v.BindEnv("style.temperature", "LLM_TEMPERATURE")
+    v.BindEnv("style.max_tokens", "LLM_MAX_TOKENS")
+    v.BindEnv("tenants.file", "TENANTS_FILE")
+    v.BindEnv("cors.allowed_origins", "CORS_ALLOWED_ORIGINS")
+    v.BindEnv("cors.allowed_methods", "CORS_ALLOWED_METHODS")
+    v.BindEnv("cors.allowed_headers", "CORS_ALLOWED_HEADERS")
+    v.BindEnv("cors.exposed_headers", "CORS_EXPOSED_HEADERS")
+    v.BindEnv("cors.allow_credentials", "CORS_ALLOW_CREDENTIALS")
+    v.BindEnv("cors.max_age_seconds", "CORS_MAX_AGE_SECONDS")
     v.BindEnv("dev.mode", "DEV_MODE")
     v.BindEnv("dev.key", "DEV_KEY")
+    v.SetDefault("health.orch_url", "http://localhost:8082")
+    v.SetDefault("health.stt_url", "http://localhost:8081")
+    v.SetDefault("health.llm_url", "http://localhost:8083")
+    v.SetDefault("health.tts_url", "http://localhost:8084")
+    v.BindEnv("health.orch_url", "HEALTH_ORCH_URL")
+    v.BindEnv("health.stt_url", "HEALTH_STT_URL")
+    v.BindEnv("health.llm_url", "HEALTH_LLM_URL")
+    v.BindEnv("health.tts_url", "HEALTH_TTS_URL")
 
 	var c Config
 	c.Server.Port = toString(v.Get("server.port"))
 	c.Server.LogLevel = v.GetString("server.log_level")
 
-	c.Daily.APIKey = v.GetString("daily.api_key")
+	// Credentials come from the secrets provider (see internal/secrets)
+	c.Daily.APIKey = secrets.Get("DAILY_API_KEY")
 	c.Daily.Domain = v.GetString("daily.domain")
 	c.Daily.RoomPrefix = v.GetString("daily.room_prefix")
 	c.Daily.RoomPrivacy = v.GetString("daily.room_privacy")
@@ -133,21 +255,67 @@ func Load() Config {
 
 	c.Bot.WorkerCmd = v.GetString("bot.worker_cmd")
 	c.Bot.StayConnectedSeconds = toString(v.Get("bot.stay_connected_seconds"))
+	c.Bot.EndGraceSeconds = v.GetInt("bot.end_grace_seconds")
 
-    c.Eleven.APIKey = v.GetString("elevenlabs.api_key")
+    c.Eleven.APIKey = secrets.Get("ELEVENLABS_API_KEY")
     c.Eleven.VoiceID = v.GetString("elevenlabs.voice_id")
     c.Eleven.CannedPhrase = v.GetString("elevenlabs.canned_phrase")
 
-    c.Worker.TokenSecret = v.GetString("worker.token_secret")
+    c.Worker.TokenSecret = secrets.Get("WORKER_TOKEN_SECRET")
     c.Worker.TokenTTLSecs = v.GetInt("worker.token_ttl_seconds")
     c.Worker.TokenSkewSecs = v.GetInt("worker.token_skew_seconds")
     c.Worker.LocalStopEnabled = v.GetBool("worker.local_stop_enabled")
+    c.Worker.RateLimits = v.GetString("worker.rate_limits")
+    c.Worker.RateLimitMaxDrops = v.GetInt("worker.rate_limit_max_drops")
+    c.Worker.DrainTimeoutMs = v.GetInt("worker.drain_timeout_ms")
+    c.Worker.STTRelay = v.GetString("worker.stt_relay")
     c.Floor.TTSTimeoutSeconds = v.GetInt("floor.tts_timeout_seconds")
+    c.Floor.InterjectionMode = v.GetString("floor.interjection_mode")
+    c.Floor.MaxInterjectionMs = v.GetInt("floor.max_interjection_ms")
+    c.Store.MaxSessions = v.GetInt("store.max_sessions")
+    c.Store.PersistPath = v.GetString("store.persist_path")
+    c.Store.FlushMs = v.GetInt("store.flush_ms")
+    c.Store.FlushBatch = v.GetInt("store.flush_batch")
+    c.Store.QueueMax = v.GetInt("store.queue_max")
+    c.Store.FsyncMs = v.GetInt("store.fsync_ms")
+    c.Store.EventRetention = v.GetString("store.event_retention")
+    c.Store.SpillPath = v.GetString("store.spill_path")
+    c.Store.SummaryDir = v.GetString("store.summary_dir")
+    c.Style.Persona = v.GetString("style.persona")
+    c.Style.Verbosity = v.GetString("style.verbosity")
+    if v.IsSet("style.temperature") {
+        t := v.GetFloat64("style.temperature")
+        c.Style.Temperature = &t
+    }
+    c.Style.MaxTokens = v.GetInt("style.max_tokens")
+    c.Tenants.File = v.GetString("tenants.file")
+    c.CORS.AllowedOrigins = v.GetString("cors.allowed_origins")
+    c.CORS.AllowedMethods = v.GetString("cors.allowed_methods")
+    c.CORS.AllowedHeaders = v.GetString("cors.allowed_headers")
+    c.CORS.ExposedHeaders = v.GetString("cors.exposed_headers")
+    c.CORS.AllowCredentials = v.GetBool("cors.allow_credentials")
+    c.CORS.MaxAgeSecs = v.GetInt("cors.max_age_seconds")
     c.Dev.Mode = v.GetBool("dev.mode")
     c.Dev.Key = v.GetString("dev.key")
+    c.Health.OrchestratorURL = v.GetString("health.orch_url")
+    c.Health.STTURL = v.GetString("health.stt_url")
+    c.Health.LLMURL = v.GetString("health.llm_url")
+    c.Health.TTSURL = v.GetString("health.tts_url")
 
 	log.Printf("config loaded: port=%s daily_domain=%s", c.Server.Port, c.Daily.Domain)
 	return c
 }
 
+// Validate reports settings that must not be combined.
+func (c Config) Validate() error {
+	if c.CORS.AllowCredentials {
+		for _, o := range strings.Split(c.CORS.AllowedOrigins, ",") {
+			if strings.TrimSpace(o) == "*" {
+				return errors.New(`CORS_ALLOWED_ORIGINS="*" cannot be used with CORS_ALLOW_CREDENTIALS=true; list the origins instead`)
+			}
+		}
+	}
+	return nil
+}
+
 func toString(v any) string { return fmt.Sprint(v) }
@@ -11,6 +11,8 @@ func TestLoadDefaults(t *testing.T) {
 	os.Unsetenv("LOG_LEVEL")
 	os.Unsetenv("DAILY_ROOM_PREFIX")
 	os.Unsetenv("DAILY_ROOM_PRIVACY")
+	os.Unsetenv("LLM_PERSONA")
+	os.Unsetenv("LLM_VERBOSITY")
 
 	c := Load()
 
@@ -26,4 +28,24 @@ func TestLoadDefaults(t *testing.T) {
 	if c.Daily.RoomPrivacy != "private" {
 		t.Fatalf("expected default room privacy private, got %q", c.Daily.RoomPrivacy)
 	}
+	if c.Style.Persona != "friendly" || c.Style.Verbosity != "normal" {
+		t.Fatalf("expected default style friendly/normal, got %q/%q", c.Style.Persona, c.Style.Verbosity)
+	}
+}
+
+func TestValidateRefusesAnyOriginWithCredentials(t *testing.T) {
+	var c Config
+	c.CORS.AllowedOrigins = "https://app.example.com, *"
+	c.CORS.AllowCredentials = true
+	if err := c.Validate(); err == nil {
+		t.Fatal(`"*" with credentials passed validation`)
+	}
+	c.CORS.AllowedOrigins = "https://*.example.com"
+	if err := c.Validate(); err != nil {
+		t.Fatalf("listed origins with credentials: %v", err)
+	}
+	c.CORS.AllowedOrigins, c.CORS.AllowCredentials = "*", false
+	if err := c.Validate(); err != nil {
+		t.Fatalf(`"*" without credentials: %v`, err)
+	}
 }
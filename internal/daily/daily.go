@@ -3,10 +3,14 @@ package daily
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
 	"time"
+
+	"yuzu/agent/internal/errdefs"
 )
 
 // AudioConfig holds room/token audio settings for high-fidelity TTS
@@ -22,6 +26,8 @@ type AudioConfig struct {
 type Client interface {
 	CreateRoom(name, privacy string) error
 	CreateMeetingToken(roomName, userName string, exp int64, isBot bool) (string, error)
+	// DeleteRoom removes the room; a room that is already gone is not an error.
+	DeleteRoom(name string) error
 }
 
 type HTTPClient struct {
@@ -82,12 +88,12 @@ func (c *HTTPClient) CreateRoom(name, privacy string) error {
             }
             if resp2.StatusCode/100 != 2 {
                 b2, _ := io.ReadAll(resp2.Body)
-                return fmt.Errorf("daily CreateRoom: %s: %s (retry after %s: %s)", resp2.Status, string(b2), resp.Status, string(b))
+                return errdefs.ProviderStatus("daily", resp2.StatusCode, fmt.Sprintf("CreateRoom: %s (retry after %s: %s)", string(b2), resp.Status, string(b)))
             }
             return nil
         }
         b, _ := io.ReadAll(resp.Body)
-        return fmt.Errorf("daily CreateRoom: %s: %s", resp.Status, string(b))
+        return errdefs.ProviderStatus("daily", resp.StatusCode, "CreateRoom: "+string(b))
     }
     return nil
 }
@@ -113,7 +119,7 @@ func (c *HTTPClient) CreateMeetingToken(roomName, userName string, exp int64, is
 	defer resp.Body.Close()
 	if resp.StatusCode/100 != 2 {
 		b, _ := io.ReadAll(resp.Body)
-		return "", fmt.Errorf("daily CreateMeetingToken: %s: %s", resp.Status, string(b))
+		return "", errdefs.ProviderStatus("daily", resp.StatusCode, "CreateMeetingToken: "+string(b))
 	}
 	var parsed struct {
 		Token string `json:"token"`
@@ -122,11 +128,27 @@ func (c *HTTPClient) CreateMeetingToken(roomName, userName string, exp int64, is
 		return "", err
 	}
 	if parsed.Token == "" {
-		return "", fmt.Errorf("daily CreateMeetingToken: empty token")
+		return "", &errdefs.ProviderError{Provider: "daily", Err: errors.New("CreateMeetingToken: empty token")}
 	}
 	return parsed.Token, nil
 }
 
+func (c *HTTPClient) DeleteRoom(name string) error {
+	resp, err := c.doJSONWithRetry("DELETE", c.base+"/rooms/"+url.PathEscape(name), nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return nil
+	}
+	if resp.StatusCode/100 != 2 {
+		b, _ := io.ReadAll(resp.Body)
+		return errdefs.ProviderStatus("daily", resp.StatusCode, "DeleteRoom: "+string(b))
+	}
+	return nil
+}
+
 // doJSONWithRetry creates a fresh request each attempt to avoid consumed bodies.
 func (c *HTTPClient) doJSONWithRetry(method, url string, payload any) (*http.Response, error) {
 	// two attempts max
@@ -148,7 +170,7 @@ func (c *HTTPClient) doJSONWithRetry(method, url string, payload any) (*http.Res
 		resp, err := c.http.Do(req)
 		if err != nil {
 			if attempts >= 2 {
-				return nil, err
+				return nil, errdefs.ProviderTransport("daily", err)
 			}
 			time.Sleep(300 * time.Millisecond)
 			continue
@@ -0,0 +1,141 @@
+// Package discovery finds the replicas of an internal service for gRPC
+// clients. An address setting such as LLM_ADDR takes one of:
+//
+//	host:port[,host:port...]       static list
+//	srv://_llm._tcp.example.internal  DNS SRV, re-resolved every DISCOVERY_REFRESH_S
+//	consul://llm[?tag=T&dc=D]      healthy instances from the Consul catalog
+//
+// A single static address is dialed as before. Anything else is dialed
+// through a gRPC resolver that follows the source, so replicas added or
+// removed later are picked up without restarting, and calls are spread over
+// them round-robin. Consul is reached at CONSUL_HTTP_ADDR (default
+// 127.0.0.1:8500) with CONSUL_HTTP_TOKEN, using blocking queries so changes
+// arrive as they happen.
+package discovery
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Source reports the current addresses of a service.
+type Source interface {
+	// Watch calls update with the full address list whenever it changes,
+	// and re-resolves early when refresh fires. It returns when ctx ends.
+	Watch(ctx context.Context, refresh <-chan struct{}, update func([]string))
+	// Kind labels metrics: static, srv or consul.
+	Kind() string
+}
+
+// ErrNoEndpoints is reported to gRPC when a source resolves to nothing.
+var ErrNoEndpoints = errors.New("discovery: no endpoints")
+
+// Parse returns the Source for spec.
+func Parse(spec string) (Source, error) {
+	spec = strings.TrimSpace(spec)
+	switch {
+	case spec == "":
+		return nil, errors.New("discovery: empty address")
+	case strings.HasPrefix(spec, "srv://"):
+		name := strings.TrimPrefix(spec, "srv://")
+		if name == "" {
+			return nil, fmt.Errorf("discovery: %q: missing SRV name", spec)
+		}
+		return &srvSource{name: name, every: refreshInterval(), lookup: lookupSRV}, nil
+	case strings.HasPrefix(spec, "consul://"):
+		u, err := url.Parse(spec)
+		if err != nil || u.Host == "" {
+			return nil, fmt.Errorf("discovery: %q: want consul://service", spec)
+		}
+		return newConsulSource(u.Host, u.Query().Get("tag"), u.Query().Get("dc")), nil
+	case strings.Contains(spec, "://"):
+		return nil, fmt.Errorf("discovery: %q: unknown scheme", spec)
+	}
+	var addrs []string
+	for _, a := range strings.Split(spec, ",") {
+		if a = strings.TrimSpace(a); a != "" {
+			addrs = append(addrs, a)
+		}
+	}
+	return staticSource(addrs), nil
+}
+
+// staticSource is a fixed address list.
+type staticSource []string
+
+func (s staticSource) Kind() string { return "static" }
+
+func (s staticSource) Watch(ctx context.Context, _ <-chan struct{}, update func([]string)) {
+	update(append([]string(nil), s...))
+	<-ctx.Done()
+}
+
+// refreshInterval reads DISCOVERY_REFRESH_S (default 30).
+func refreshInterval() time.Duration {
+	if v, err := strconv.Atoi(os.Getenv("DISCOVERY_REFRESH_S")); err == nil && v > 0 {
+		return time.Duration(v) * time.Second
+	}
+	return 30 * time.Second
+}
+
+// poll runs resolve now, every interval and on refresh, reporting only
+// changed lists. Failures keep the last good list.
+func poll(ctx context.Context, kind, name string, every time.Duration, refresh <-chan struct{}, resolve func(context.Context) ([]string, error), update func([]string)) {
+	var last []string
+	t := time.NewTicker(every)
+	defer t.Stop()
+	for {
+		addrs, err := resolve(ctx)
+		switch {
+		case err != nil:
+			if ctx.Err() != nil {
+				return
+			}
+			metricErrors.WithLabelValues(kind).Inc()
+			log.Printf("[discovery] %s %s: %v; keeping %d endpoint(s)", kind, name, err, len(last))
+		case !equal(addrs, last):
+			log.Printf("[discovery] %s %s: %v", kind, name, addrs)
+			last = addrs
+			update(append([]string(nil), addrs...))
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+		case <-refresh:
+		}
+	}
+}
+
+// equal compares sorted address lists.
+func equal(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// sorted sorts and de-duplicates addrs.
+func sorted(addrs []string) []string {
+	sort.Strings(addrs)
+	out := addrs[:0]
+	for i, a := range addrs {
+		if i == 0 || a != addrs[i-1] {
+			out = append(out, a)
+		}
+	}
+	return out
+}
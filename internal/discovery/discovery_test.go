@@ -0,0 +1,162 @@
+package discovery
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+func TestParse(t *testing.T) {
+	for spec, kind := range map[string]string{
+		":9092":                    "static",
+		"llm-a:9092, llm-b:9092":   "static",
+		"srv://_llm._tcp.internal": "srv",
+		"consul://llm?tag=primary": "consul",
+	} {
+		src, err := Parse(spec)
+		if err != nil || src.Kind() != kind {
+			t.Errorf("Parse(%q) = %v, %v; want %s", spec, src, err, kind)
+		}
+	}
+	for _, bad := range []string{"", "srv://", "consul://", "etcd://llm"} {
+		if _, err := Parse(bad); err == nil {
+			t.Errorf("Parse(%q) succeeded", bad)
+		}
+	}
+	if target, opts, err := Target("llm", "llm:9092"); target != "llm:9092" || opts != nil || err != nil {
+		t.Errorf("single address = %q %v %v, want it unchanged", target, opts, err)
+	}
+}
+
+// collect runs src and returns the updates it pushes.
+func collect(t *testing.T, src Source) (<-chan []string, chan struct{}) {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	updates, refresh := make(chan []string, 8), make(chan struct{}, 1)
+	go src.Watch(ctx, refresh, func(a []string) { updates <- a })
+	return updates, refresh
+}
+
+func next(t *testing.T, updates <-chan []string) []string {
+	t.Helper()
+	select {
+	case a := <-updates:
+		return a
+	case <-time.After(2 * time.Second):
+		t.Fatal("no update")
+		return nil
+	}
+}
+
+func TestSRVSourceFollowsChanges(t *testing.T) {
+	var calls atomic.Int32
+	src := &srvSource{name: "_llm._tcp.internal", every: time.Hour, lookup: func(context.Context, string) ([]*net.SRV, error) {
+		switch calls.Add(1) {
+		case 1:
+			return []*net.SRV{{Target: "b.internal.", Port: 9092}, {Target: "a.internal.", Port: 9092}}, nil
+		case 2:
+			return nil, fmt.Errorf("timeout")
+		default:
+			return []*net.SRV{{Target: "a.internal.", Port: 9092}}, nil
+		}
+	}}
+	updates, refresh := collect(t, src)
+	if got := fmt.Sprint(next(t, updates)); got != "[a.internal:9092 b.internal:9092]" {
+		t.Fatalf("first = %s", got)
+	}
+	// A failed lookup keeps the list; the next one shrinks it
+	refresh <- struct{}{}
+	time.Sleep(20 * time.Millisecond)
+	refresh <- struct{}{}
+	if got := fmt.Sprint(next(t, updates)); got != "[a.internal:9092]" {
+		t.Fatalf("after change = %s", got)
+	}
+}
+
+func TestConsulSourceBlockingQueries(t *testing.T) {
+	var queries atomic.Int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/health/service/llm" || r.URL.Query().Get("passing") != "true" || r.Header.Get("X-Consul-Token") != "tok" {
+			http.Error(w, "bad request "+r.URL.String(), http.StatusBadRequest)
+			return
+		}
+		switch queries.Add(1) {
+		case 1:
+			w.Header().Set("X-Consul-Index", "5")
+			fmt.Fprint(w, `[{"Node":{"Address":"10.0.0.1"},"Service":{"Port":9092}},{"Node":{"Address":"10.0.0.9"},"Service":{"Address":"10.0.1.2","Port":9092}}]`)
+		default:
+			if r.URL.Query().Get("index") != "5" {
+				http.Error(w, "want index=5", http.StatusBadRequest)
+				return
+			}
+			w.Header().Set("X-Consul-Index", "6")
+			fmt.Fprint(w, `[{"Node":{"Address":"10.0.0.1"},"Service":{"Port":9092}}]`)
+		}
+	}))
+	defer ts.Close()
+	t.Setenv("CONSUL_HTTP_ADDR", ts.URL)
+	t.Setenv("CONSUL_HTTP_TOKEN", "tok")
+	src := newConsulSource("llm", "", "")
+
+	updates, _ := collect(t, src)
+	if got := fmt.Sprint(next(t, updates)); got != "[10.0.0.1:9092 10.0.1.2:9092]" {
+		t.Fatalf("first = %s", got)
+	}
+	if got := fmt.Sprint(next(t, updates)); got != "[10.0.0.1:9092]" {
+		t.Fatalf("after change = %s", got)
+	}
+}
+
+func TestTargetBalancesOverReplicas(t *testing.T) {
+	var hits [2]atomic.Int32
+	var addrs string
+	for i := range hits {
+		l, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatal(err)
+		}
+		i := i
+		s := grpc.NewServer(grpc.UnaryInterceptor(func(ctx context.Context, req any, _ *grpc.UnaryServerInfo, h grpc.UnaryHandler) (any, error) {
+			hits[i].Add(1)
+			return h(ctx, req)
+		}))
+		healthpb.RegisterHealthServer(s, health.NewServer())
+		go s.Serve(l)
+		defer s.Stop()
+		if addrs != "" {
+			addrs += ","
+		}
+		addrs += l.Addr().String()
+	}
+
+	target, opts, err := Target("test", addrs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn, err := grpc.Dial(target, append(opts, grpc.WithTransportCredentials(insecure.NewCredentials()))...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	for i := 0; i < 10; i++ {
+		if _, err := healthpb.NewHealthClient(conn).Check(ctx, &healthpb.HealthCheckRequest{}, grpc.WaitForReady(true)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if hits[0].Load() == 0 || hits[1].Load() == 0 {
+		t.Fatalf("calls per replica = %d, %d; want both used", hits[0].Load(), hits[1].Load())
+	}
+}
@@ -0,0 +1,23 @@
+package discovery
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	metricEndpoints = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "discovery_endpoints",
+		Help: "Addresses currently known for a discovered service",
+	}, []string{"service"})
+
+	metricUpdates = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "discovery_updates_total",
+		Help: "Address list changes pushed to gRPC, by service",
+	}, []string{"service"})
+
+	metricErrors = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "discovery_errors_total",
+		Help: "Failed lookups by source kind (srv, consul)",
+	}, []string{"source"})
+)
@@ -0,0 +1,78 @@
+package discovery
+
+import (
+	"context"
+	"fmt"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/resolver"
+)
+
+// scheme is private to each dial: the builder is passed with
+// grpc.WithResolvers rather than registered globally.
+const scheme = "yuzu-discovery"
+
+// roundRobin spreads calls over every resolved address.
+const roundRobin = `{"loadBalancingConfig":[{"round_robin":{}}]}`
+
+// Target returns what to dial for service (a label for logs and metrics)
+// given its address setting, plus the dial options that go with it. A
+// single static address comes back unchanged with no extra options.
+func Target(service, spec string) (string, []grpc.DialOption, error) {
+	src, err := Parse(spec)
+	if err != nil {
+		return "", nil, err
+	}
+	if st, ok := src.(staticSource); ok && len(st) == 1 {
+		return st[0], nil, nil
+	}
+	if st, ok := src.(staticSource); ok && len(st) == 0 {
+		return "", nil, fmt.Errorf("discovery: %q: no addresses", spec)
+	}
+	b := &builder{service: service, src: src}
+	return scheme + ":///" + service, []grpc.DialOption{grpc.WithResolvers(b), grpc.WithDefaultServiceConfig(roundRobin)}, nil
+}
+
+// builder starts one watcher per ClientConn.
+type builder struct {
+	service string
+	src     Source
+}
+
+func (b *builder) Scheme() string { return scheme }
+
+func (b *builder) Build(_ resolver.Target, cc resolver.ClientConn, _ resolver.BuildOptions) (resolver.Resolver, error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	r := &watchResolver{cancel: cancel, refresh: make(chan struct{}, 1)}
+	go b.src.Watch(ctx, r.refresh, func(addrs []string) {
+		metricEndpoints.WithLabelValues(b.service).Set(float64(len(addrs)))
+		metricUpdates.WithLabelValues(b.service).Inc()
+		if len(addrs) == 0 {
+			cc.ReportError(fmt.Errorf("%w for %s via %s", ErrNoEndpoints, b.service, b.src.Kind()))
+			return
+		}
+		state := resolver.State{}
+		for _, a := range addrs {
+			state.Addresses = append(state.Addresses, resolver.Address{Addr: a})
+		}
+		// gRPC calls ResolveNow itself when the list turns out unusable
+		_ = cc.UpdateState(state)
+	})
+	return r, nil
+}
+
+// watchResolver ties a watcher to its ClientConn.
+type watchResolver struct {
+	cancel  context.CancelFunc
+	refresh chan struct{}
+}
+
+// ResolveNow asks the source to look again, e.g. after connections failed.
+func (r *watchResolver) ResolveNow(resolver.ResolveNowOptions) {
+	select {
+	case r.refresh <- struct{}{}:
+	default:
+	}
+}
+
+func (r *watchResolver) Close() { r.cancel() }
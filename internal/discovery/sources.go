@@ -0,0 +1,173 @@
+package discovery
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// srvSource re-resolves a DNS SRV name.
+type srvSource struct {
+	name   string
+	every  time.Duration
+	lookup func(ctx context.Context, name string) ([]*net.SRV, error)
+}
+
+func lookupSRV(ctx context.Context, name string) ([]*net.SRV, error) {
+	_, srvs, err := net.DefaultResolver.LookupSRV(ctx, "", "", name)
+	return srvs, err
+}
+
+func (s *srvSource) Kind() string { return "srv" }
+
+func (s *srvSource) Watch(ctx context.Context, refresh <-chan struct{}, update func([]string)) {
+	poll(ctx, "srv", s.name, s.every, refresh, func(ctx context.Context) ([]string, error) {
+		srvs, err := s.lookup(ctx, s.name)
+		if err != nil {
+			return nil, err
+		}
+		var addrs []string
+		for _, r := range srvs {
+			addrs = append(addrs, net.JoinHostPort(strings.TrimSuffix(r.Target, "."), strconv.Itoa(int(r.Port))))
+		}
+		return sorted(addrs), nil
+	}, update)
+}
+
+// consulSource follows the passing instances of a Consul service with
+// blocking queries.
+type consulSource struct {
+	base    string // Consul HTTP API base URL
+	token   string
+	service string
+	tag     string
+	dc      string
+	wait    time.Duration // blocking query timeout
+	backoff time.Duration // pause after a failed query
+	client  *http.Client
+}
+
+func newConsulSource(service, tag, dc string) *consulSource {
+	base := os.Getenv("CONSUL_HTTP_ADDR")
+	if base == "" {
+		base = "127.0.0.1:8500"
+	}
+	if !strings.Contains(base, "://") {
+		base = "http://" + base
+	}
+	return &consulSource{
+		base: strings.TrimRight(base, "/"), token: os.Getenv("CONSUL_HTTP_TOKEN"),
+		service: service, tag: tag, dc: dc,
+		wait: 5 * time.Minute, backoff: 5 * time.Second,
+		client: &http.Client{},
+	}
+}
+
+func (c *consulSource) Kind() string { return "consul" }
+
+// consulEntry is the part of /v1/health/service we use.
+type consulEntry struct {
+	Node struct {
+		Address string
+	}
+	Service struct {
+		Address string
+		Port    int
+	}
+}
+
+func (c *consulSource) Watch(ctx context.Context, refresh <-chan struct{}, update func([]string)) {
+	var index string
+	var last []string
+	for ctx.Err() == nil {
+		addrs, next, err := c.query(ctx, index)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			metricErrors.WithLabelValues("consul").Inc()
+			log.Printf("[discovery] consul %s: %v; keeping %d endpoint(s)", c.service, err, len(last))
+			index = ""
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(c.backoff):
+			case <-refresh:
+			}
+			continue
+		}
+		// Without an index, or when it went backwards because Consul was
+		// reset, the next query can't block; pause and start over
+		n, _ := strconv.ParseUint(next, 10, 64)
+		if prev, _ := strconv.ParseUint(index, 10, 64); n == 0 || n < prev {
+			index = ""
+			select {
+			case <-ctx.Done():
+			case <-time.After(c.backoff):
+			case <-refresh:
+			}
+		} else {
+			index = next
+		}
+		if !equal(addrs, last) {
+			log.Printf("[discovery] consul %s: %v", c.service, addrs)
+			last = addrs
+			update(append([]string(nil), addrs...))
+		}
+	}
+}
+
+// query runs one (blocking, when index is set) health query.
+func (c *consulSource) query(ctx context.Context, index string) ([]string, string, error) {
+	q := url.Values{"passing": {"true"}}
+	if c.tag != "" {
+		q.Set("tag", c.tag)
+	}
+	if c.dc != "" {
+		q.Set("dc", c.dc)
+	}
+	if index != "" {
+		q.Set("index", index)
+		q.Set("wait", fmt.Sprintf("%ds", int(c.wait.Seconds())))
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.base+"/v1/health/service/"+url.PathEscape(c.service)+"?"+q.Encode(), nil)
+	if err != nil {
+		return nil, "", err
+	}
+	if c.token != "" {
+		req.Header.Set("X-Consul-Token", c.token)
+	}
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		b, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return nil, "", fmt.Errorf("status %d: %s", resp.StatusCode, strings.TrimSpace(string(b)))
+	}
+	var entries []consulEntry
+	if err := json.NewDecoder(resp.Body).Decode(&entries); err != nil {
+		return nil, "", err
+	}
+	var addrs []string
+	for _, e := range entries {
+		host := e.Service.Address
+		if host == "" {
+			host = e.Node.Address
+		}
+		if host != "" && e.Service.Port > 0 {
+			addrs = append(addrs, net.JoinHostPort(host, strconv.Itoa(e.Service.Port)))
+		}
+	}
+	return sorted(addrs), resp.Header.Get("X-Consul-Index"), nil
+}
@@ -0,0 +1,7 @@
+// Package e2e holds the end-to-end pipeline tests. They run the real
+// orchestrator, STT, LLM and TTS servers in-process over bufconn, with fake
+// Deepgram, Azure OpenAI and ElevenLabs backends, and drive a scripted
+// conversation the way the Python gateway would.
+//
+// Run with: go test ./internal/e2e
+package e2e
@@ -0,0 +1,310 @@
+package e2e
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/test/bufconn"
+	"nhooyr.io/websocket"
+
+	"yuzu/agent/internal/grpcmw"
+	"yuzu/agent/internal/llm"
+	llmpb "yuzu/agent/internal/llm/pb"
+	orch "yuzu/agent/internal/orchestrator"
+	gw "yuzu/agent/internal/orchestrator/pb"
+	"yuzu/agent/internal/stt"
+	sttpb "yuzu/agent/internal/stt/pb"
+	"yuzu/agent/internal/tts"
+	ttspb "yuzu/agent/internal/tts/pb"
+)
+
+const (
+	tokenSecret = "e2e-secret"
+	// Frames the fake Deepgram counts towards an utterance. The STT
+	// keepalive sends 640-byte silent frames, so test audio uses 100ms frames.
+	audioFrameBytes = 3200
+	framesPerUtter  = 5
+	// PCM the fake ElevenLabs returns per sentence: three 20ms frames at 48kHz.
+	ttsPCMBytes = 3 * 1920
+)
+
+// harness wires the services together. Fields are the clients a gateway
+// would hold; the fakes record what reached each provider.
+type harness struct {
+	orch gw.GatewayControlClient
+	stt  sttpb.STTClient
+	tts  ttspb.TTSClient
+
+	deepgram *fakeDeepgram
+	azure    *fakeAzure
+	eleven   *fakeElevenLabs
+}
+
+// turnScript is one exchange: what the user says and how the LLM replies.
+type turnScript struct {
+	user  string
+	reply []string // streamed as one delta per sentence
+}
+
+func newHarness(t *testing.T, script []turnScript) *harness {
+	t.Helper()
+	h := &harness{
+		deepgram: &fakeDeepgram{},
+		azure:    &fakeAzure{},
+		eleven:   &fakeElevenLabs{},
+	}
+	for _, s := range script {
+		h.deepgram.transcripts = append(h.deepgram.transcripts, s.user)
+		h.azure.replies = append(h.azure.replies, s.reply)
+	}
+
+	dg := httptest.NewServer(h.deepgram)
+	az := httptest.NewServer(h.azure)
+	el := httptest.NewServer(h.eleven)
+	t.Cleanup(dg.Close)
+	t.Cleanup(az.Close)
+	t.Cleanup(el.Close)
+
+	t.Setenv("DEEPGRAM_WS_URL", "ws"+strings.TrimPrefix(dg.URL, "http"))
+	t.Setenv("DEEPGRAM_API_KEY", "dg-key")
+	t.Setenv("AZURE_OPENAI_ENDPOINT", az.URL)
+	t.Setenv("AZURE_OPENAI_API_KEY", "az-key")
+	t.Setenv("ELEVENLABS_BASE_URL", el.URL)
+	t.Setenv("ELEVENLABS_API_KEY", "el-key")
+	t.Setenv("WORKER_TOKEN_SECRET", tokenSecret)
+	t.Setenv("ORCH_AUTH_SECRET", "")
+
+	llmLis := serve(t, "llm", func(s *grpc.Server) { llmpb.RegisterLLMServer(s, llm.NewServer()) })
+	sttLis := serve(t, "stt", func(s *grpc.Server) { sttpb.RegisterSTTServer(s, stt.NewSTTServer()) })
+	ttsLis := serve(t, "tts", func(s *grpc.Server) { ttspb.RegisterTTSServer(s, tts.NewServer()) })
+
+	o := orch.NewServer()
+	// A fresh connection per dial: the orchestrator closes the old one on reconnect
+	o.SetLLMDialer(func(context.Context) (*grpc.ClientConn, error) { return dial(llmLis, "llm") })
+	orchLis := serve(t, "orchestrator", func(s *grpc.Server) { gw.RegisterGatewayControlServer(s, o) })
+
+	h.orch = gw.NewGatewayControlClient(connect(t, orchLis, "orchestrator"))
+	h.stt = sttpb.NewSTTClient(connect(t, sttLis, "stt"))
+	h.tts = ttspb.NewTTSClient(connect(t, ttsLis, "tts"))
+	return h
+}
+
+// serve starts a gRPC server with the production middleware on a bufconn
+// listener.
+func serve(t *testing.T, service string, register func(*grpc.Server)) *bufconn.Listener {
+	t.Helper()
+	lis := bufconn.Listen(1 << 20)
+	s := grpc.NewServer(grpcmw.ServerOptions(grpcmw.OptionsFromEnv(service))...)
+	register(s)
+	go s.Serve(lis)
+	t.Cleanup(s.Stop)
+	return lis
+}
+
+func dial(lis *bufconn.Listener, service string) (*grpc.ClientConn, error) {
+	return grpc.NewClient("passthrough:///"+service,
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+}
+
+// connect dials lis and closes the connection when the test ends.
+func connect(t *testing.T, lis *bufconn.Listener, service string) *grpc.ClientConn {
+	t.Helper()
+	conn, err := dial(lis, service)
+	if err != nil {
+		t.Fatalf("dial %s: %v", service, err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+// fakeDeepgram speaks enough of the Deepgram streaming protocol for the STT
+// session: after framesPerUtter audio frames it sends an interim, a final with
+// word timings, and UtteranceEnd, using the next scripted transcript.
+type fakeDeepgram struct {
+	mu          sync.Mutex
+	transcripts []string
+	next        int
+	authHeader  string
+}
+
+func (f *fakeDeepgram) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	f.authHeader = r.Header.Get("Authorization")
+	f.mu.Unlock()
+	c, err := websocket.Accept(w, r, nil)
+	if err != nil {
+		return
+	}
+	defer c.Close(websocket.StatusNormalClosure, "")
+	ctx := r.Context()
+	frames := 0
+	for {
+		typ, data, err := c.Read(ctx)
+		if err != nil {
+			return
+		}
+		if typ != websocket.MessageBinary || len(data) != audioFrameBytes {
+			continue // keepalive or control message
+		}
+		if frames++; frames < framesPerUtter {
+			continue
+		}
+		frames = 0
+		f.mu.Lock()
+		if f.next >= len(f.transcripts) {
+			f.mu.Unlock()
+			continue
+		}
+		text := f.transcripts[f.next]
+		f.next++
+		f.mu.Unlock()
+
+		for _, msg := range []any{results(text, false), results(text, true), map[string]any{"type": "UtteranceEnd"}} {
+			b, _ := json.Marshal(msg)
+			if err := c.Write(ctx, websocket.MessageText, b); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// results builds a Results message with one word per 400ms of speech.
+func results(text string, final bool) map[string]any {
+	var words []map[string]any
+	for i, w := range strings.Fields(text) {
+		words = append(words, map[string]any{"word": w, "start": 0.4 * float64(i), "end": 0.4*float64(i) + 0.35})
+	}
+	return map[string]any{
+		"type":     "Results",
+		"is_final": final,
+		"channel": map[string]any{
+			"alternatives": []any{map[string]any{"transcript": text, "words": words}},
+		},
+	}
+}
+
+// fakeAzure streams the next scripted reply as chat-completion SSE chunks and
+// records the messages of each request.
+type fakeAzure struct {
+	mu       sync.Mutex
+	replies  [][]string
+	next     int
+	requests [][]map[string]string
+}
+
+func (f *fakeAzure) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Header.Get("api-key") != "az-key" {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	var body struct {
+		Messages []map[string]string `json:"messages"`
+	}
+	_ = json.NewDecoder(r.Body).Decode(&body)
+	f.mu.Lock()
+	f.requests = append(f.requests, body.Messages)
+	var reply []string
+	if f.next < len(f.replies) {
+		reply = f.replies[f.next]
+		f.next++
+	}
+	f.mu.Unlock()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	fl, _ := w.(http.Flusher)
+	for _, delta := range reply {
+		b, _ := json.Marshal(map[string]any{"choices": []any{map[string]any{"delta": map[string]any{"content": delta}}}})
+		fmt.Fprintf(w, "data: %s\n\n", b)
+		if fl != nil {
+			fl.Flush()
+		}
+	}
+	io.WriteString(w, "data: [DONE]\n\n")
+}
+
+func (f *fakeAzure) request(i int) []map[string]string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if i >= len(f.requests) {
+		return nil
+	}
+	return f.requests[i]
+}
+
+// fakeElevenLabs returns ttsPCMBytes of PCM per request and records the text.
+type fakeElevenLabs struct {
+	mu    sync.Mutex
+	texts []string
+}
+
+func (f *fakeElevenLabs) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Header.Get("xi-api-key") != "el-key" {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	var body struct {
+		Text string `json:"text"`
+	}
+	_ = json.NewDecoder(r.Body).Decode(&body)
+	f.mu.Lock()
+	f.texts = append(f.texts, body.Text)
+	f.mu.Unlock()
+	w.Write(make([]byte, ttsPCMBytes))
+}
+
+func (f *fakeElevenLabs) synthesized() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]string(nil), f.texts...)
+}
+
+// timeout bounds every wait in the harness. Generous so CI noise doesn't
+// flake; the latency invariants are asserted separately.
+const timeout = 5 * time.Second
+
+// commands pumps an orchestrator stream into a channel. TurnState only
+// feeds the event log, so it is left out.
+func commands(stream gw.GatewayControl_SessionClient) <-chan *gw.OrchestratorCommand {
+	ch := make(chan *gw.OrchestratorCommand, 64)
+	go func() {
+		defer close(ch)
+		for {
+			cmd, err := stream.Recv()
+			if err != nil {
+				return
+			}
+			if cmd.GetTurnState() != nil {
+				continue
+			}
+			ch <- cmd
+		}
+	}()
+	return ch
+}
+
+func nextCmd(t *testing.T, ch <-chan *gw.OrchestratorCommand) *gw.OrchestratorCommand {
+	t.Helper()
+	select {
+	case cmd, ok := <-ch:
+		if !ok {
+			t.Fatal("orchestrator stream closed")
+		}
+		return cmd
+	case <-time.After(timeout):
+		t.Fatal("timed out waiting for orchestrator command")
+	}
+	return nil
+}
@@ -0,0 +1,264 @@
+package e2e
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/metadata"
+
+	"yuzu/agent/internal/auth"
+	gw "yuzu/agent/internal/orchestrator/pb"
+	sttpb "yuzu/agent/internal/stt/pb"
+	ttspb "yuzu/agent/internal/tts/pb"
+)
+
+// Latency budgets. The fakes answer immediately, so these only catch the
+// pipeline itself stalling (lost wakeups, serialised sends, polling gaps).
+const (
+	maxDrainToFinal    = 2 * time.Second
+	maxFinalToFirstTTS = 2 * time.Second
+	maxTTSStartToAudio = time.Second
+)
+
+func TestScriptedConversation(t *testing.T) {
+	script := []turnScript{
+		{user: "hi there I am ready to start", reply: []string{"Great to meet you.", " What role are you applying for?"}},
+		{user: "a backend engineering role at a startup", reply: []string{"Sounds exciting!"}},
+	}
+	h := newHarness(t, script)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	sid := "e2e-session-0001"
+	token := auth.MustToken(tokenSecret, sid, time.Now().Add(time.Hour).Unix())
+	octx := metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+token)
+	ostream, err := h.orch.Session(octx)
+	if err != nil {
+		t.Fatalf("orchestrator session: %v", err)
+	}
+	cmds := commands(ostream)
+	if err := ostream.Send(&gw.GatewayEvent{SessionId: sid, Evt: &gw.GatewayEvent_SessionOpen{SessionOpen: &gw.SessionOpen{SessionId: sid}}}); err != nil {
+		t.Fatalf("send SessionOpen: %v", err)
+	}
+
+	if cmd := nextCmd(t, cmds); cmd.GetArmBargeIn() == nil {
+		t.Fatalf("first command = %v, want ArmBargeIn", cmd)
+	}
+	mic := nextCmd(t, cmds).GetStartMicToStt()
+	if mic == nil || mic.TurnId != "t1" || mic.UtteranceId != "t1-u" {
+		t.Fatalf("second command = %v, want StartMicToSTT t1/t1-u", mic)
+	}
+
+	sstream, err := h.stt.Session(ctx)
+	if err != nil {
+		t.Fatalf("stt session: %v", err)
+	}
+	defer sstream.CloseSend()
+
+	agentSeq := 0
+	for i, turn := range script {
+		final := speak(t, sstream, sid, mic.UtteranceId)
+		// STT may segment on its own and append ".N" to the issued ID
+		if final.Text != turn.user || (final.UtteranceId != mic.UtteranceId && !strings.HasPrefix(final.UtteranceId, mic.UtteranceId+".")) {
+			t.Fatalf("turn %d: STT final = %q (%s), want %q (%s)", i+1, final.Text, final.UtteranceId, turn.user, mic.UtteranceId)
+		}
+		if want := uint32(len(strings.Fields(turn.user))); final.WordCount != want || final.SpeechMs == 0 {
+			t.Errorf("turn %d: word timing = %d words / %dms, want %d words", i+1, final.WordCount, final.SpeechMs, want)
+		}
+
+		sentAt := time.Now()
+		err := ostream.Send(&gw.GatewayEvent{SessionId: sid, Evt: &gw.GatewayEvent_TranscriptFinal{TranscriptFinal: &gw.TranscriptFinal{
+			UtteranceId: final.UtteranceId, Text: final.Text, TurnId: mic.TurnId, WordCount: final.WordCount, SpeechMs: final.SpeechMs,
+		}}})
+		if err != nil {
+			t.Fatalf("send TranscriptFinal: %v", err)
+		}
+
+		// Listening moves to the next turn before the reply starts
+		next := nextCmd(t, cmds).GetStartMicToStt()
+		if wantTurn := fmt.Sprintf("t%d", i+2); next == nil || next.TurnId != wantTurn || next.UtteranceId != wantTurn+"-u" {
+			t.Fatalf("turn %d: command after final = %v, want StartMicToSTT %s", i+1, next, wantTurn)
+		}
+
+		for j, want := range turn.reply {
+			cmd := nextCmd(t, cmds).GetStartTts()
+			if cmd == nil {
+				t.Fatalf("turn %d: expected StartTTS for sentence %d", i+1, j+1)
+			}
+			if j == 0 {
+				if d := time.Since(sentAt); d > maxFinalToFirstTTS {
+					t.Errorf("turn %d: final -> first StartTTS took %s, budget %s", i+1, d, maxFinalToFirstTTS)
+				}
+			}
+			agentSeq++
+			if wantUtt := fmt.Sprintf("%s-a%d", mic.TurnId, agentSeq); cmd.TurnId != mic.TurnId || cmd.UtteranceId != wantUtt {
+				t.Errorf("turn %d: StartTTS ids = %s/%s, want %s/%s", i+1, cmd.TurnId, cmd.UtteranceId, mic.TurnId, wantUtt)
+			}
+			if strings.TrimSpace(cmd.Text) != strings.TrimSpace(want) {
+				t.Errorf("turn %d: StartTTS text = %q, want %q", i+1, cmd.Text, want)
+			}
+			if cmd.SpeakingRate <= 0 {
+				t.Errorf("turn %d: StartTTS speaking_rate unset", i+1)
+			}
+			play(t, h.tts, ostream, sid, cmd)
+		}
+
+		// The LLM saw the persona prompt and the user's words
+		msgs := h.azure.request(i)
+		if len(msgs) < 2 || msgs[0]["role"] != "system" || msgs[len(msgs)-1]["content"] != turn.user {
+			t.Errorf("turn %d: LLM request messages = %v", i+1, msgs)
+		}
+		mic = next
+	}
+
+	if got, want := len(h.eleven.synthesized()), agentSeq; got != want {
+		t.Errorf("TTS provider requests = %d, want %d", got, want)
+	}
+	h.deepgram.mu.Lock()
+	if h.deepgram.authHeader != "Token dg-key" {
+		t.Errorf("deepgram auth header = %q", h.deepgram.authHeader)
+	}
+	h.deepgram.mu.Unlock()
+
+	// Nothing unexpected trails the conversation
+	select {
+	case cmd := <-cmds:
+		t.Errorf("unexpected trailing command %v", cmd)
+	case <-time.After(200 * time.Millisecond):
+	}
+
+	// An explicit close stops everything and is acknowledged last
+	if err := ostream.Send(&gw.GatewayEvent{SessionId: sid, Evt: &gw.GatewayEvent_SessionClose{SessionClose: &gw.SessionClose{Reason: "participant_left"}}}); err != nil {
+		t.Fatalf("send SessionClose: %v", err)
+	}
+	if cmd := nextCmd(t, cmds); cmd.GetStopAll().GetReason() != "participant_left" {
+		t.Errorf("first command after close = %v, want StopAll", cmd)
+	}
+	if cmd := nextCmd(t, cmds); cmd.GetAck().GetInfo() != "session_closed" {
+		t.Errorf("last command after close = %v, want Ack session_closed", cmd)
+	}
+}
+
+// speak starts an STT utterance, streams one scripted utterance of audio,
+// drains and pings until the final arrives, the way the gateway does.
+func speak(t *testing.T, stream sttpb.STT_SessionClient, sid, utteranceID string) *sttpb.TranscriptFinal {
+	t.Helper()
+	send := func(m *sttpb.ClientMessage) {
+		if err := stream.Send(m); err != nil {
+			t.Fatalf("stt send: %v", err)
+		}
+	}
+	send(&sttpb.ClientMessage{Msg: &sttpb.ClientMessage_Start{Start: &sttpb.ControlStart{SessionId: sid, UtteranceId: utteranceID, SampleRate: 16000}}})
+	// Give the provider socket a moment so frames aren't dropped on a cold connect
+	time.Sleep(50 * time.Millisecond)
+	for i := 0; i < framesPerUtter; i++ {
+		send(&sttpb.ClientMessage{Msg: &sttpb.ClientMessage_Audio{Audio: &sttpb.AudioChunk{Pcm16K: make([]byte, audioFrameBytes), DurationMs: 100}}})
+	}
+	send(&sttpb.ClientMessage{Msg: &sttpb.ClientMessage_Drain{Drain: &sttpb.Drain{}}})
+	drainedAt := time.Now()
+
+	// The sidecar forwards provider events as client messages arrive, so poll
+	msgs := make(chan *sttpb.ServerMessage, 64)
+	go func() {
+		for {
+			m, err := stream.Recv()
+			if err != nil {
+				close(msgs)
+				return
+			}
+			msgs <- m
+			if m.GetFinal() != nil {
+				return
+			}
+		}
+	}()
+	tick := time.NewTicker(20 * time.Millisecond)
+	defer tick.Stop()
+	deadline := time.After(timeout)
+	for seq := uint64(1); ; seq++ {
+		select {
+		case m, ok := <-msgs:
+			if !ok {
+				t.Fatal("stt stream closed before final")
+			}
+			if e := m.GetError(); e != nil {
+				t.Fatalf("stt error: %s", e.Message)
+			}
+			if f := m.GetFinal(); f != nil {
+				if d := time.Since(drainedAt); d > maxDrainToFinal {
+					t.Errorf("drain -> final took %s, budget %s", d, maxDrainToFinal)
+				}
+				return f
+			}
+		case <-tick.C:
+			send(&sttpb.ClientMessage{Msg: &sttpb.ClientMessage_Ping{Ping: &sttpb.Ping{Seq: seq}}})
+		case <-deadline:
+			t.Fatalf("timed out waiting for STT final for %s", utteranceID)
+		}
+	}
+}
+
+// play synthesizes one StartTTS through the TTS service and reports the
+// playback lifecycle back to the orchestrator.
+func play(t *testing.T, client ttspb.TTSClient, ostream gw.GatewayControl_SessionClient, sid string, cmd *gw.StartTTS) {
+	t.Helper()
+	event := func(typ string, firstAudioMs uint32) {
+		err := ostream.Send(&gw.GatewayEvent{SessionId: sid, Evt: &gw.GatewayEvent_Tts{Tts: &gw.TTSEvent{
+			Type: typ, FirstAudioMs: firstAudioMs, TurnId: cmd.TurnId, UtteranceId: cmd.UtteranceId,
+		}}})
+		if err != nil {
+			t.Fatalf("send TTSEvent %s: %v", typ, err)
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(ostream.Context(), timeout)
+	defer cancel()
+	stream, err := client.Session(ctx)
+	if err != nil {
+		t.Fatalf("tts session: %v", err)
+	}
+	start := time.Now()
+	event("started", 0)
+	err = stream.Send(&ttspb.ClientMessage{Msg: &ttspb.ClientMessage_Start{Start: &ttspb.StartRequest{
+		SessionId: sid, RequestId: cmd.UtteranceId, VoiceId: "voice", Text: cmd.Text, SpeakingRate: cmd.SpeakingRate,
+	}}})
+	if err != nil {
+		t.Fatalf("tts send: %v", err)
+	}
+
+	var audio int
+	for {
+		m, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("tts recv: %v", err)
+		}
+		if f := m.GetFailed(); f != nil {
+			t.Fatalf("tts failed for %s: %s %s", cmd.UtteranceId, f.Code, f.Message)
+		}
+		if e := m.GetError(); e != nil {
+			t.Fatalf("tts error for %s: %s %s", cmd.UtteranceId, e.Code, e.Message)
+		}
+		if a := m.GetAudio(); a != nil {
+			if audio == 0 {
+				d := time.Since(start)
+				if d > maxTTSStartToAudio {
+					t.Errorf("%s: StartTTS -> first audio took %s, budget %s", cmd.UtteranceId, d, maxTTSStartToAudio)
+				}
+				event("first_audio", uint32(d.Milliseconds()))
+			}
+			audio += len(a.Pcm48K)
+		}
+	}
+	if audio != ttsPCMBytes {
+		t.Errorf("%s: received %d bytes of audio, want %d", cmd.UtteranceId, audio, ttsPCMBytes)
+	}
+	event("stopped", 0)
+}
@@ -0,0 +1,152 @@
+// Package errdefs defines the error classes shared by the services so callers
+// can branch on what went wrong instead of matching message strings. Each
+// class maps to one gRPC code and one HTTP status; handlers return the typed
+// error and let GRPCStatus/WriteHTTP pick the wire representation.
+package errdefs
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"google.golang.org/grpc/status"
+)
+
+// Class names, also used as in-band error codes on provider streams.
+const (
+	ClassProvider     = "provider"
+	ClassConfig       = "config"
+	ClassAuth         = "auth"
+	ClassBackpressure = "backpressure"
+	ClassInternal     = "internal"
+)
+
+// ProviderError is a failure of an upstream provider (Azure, ElevenLabs,
+// Deepgram, Daily). Status is the provider's HTTP status, 0 for transport errors.
+type ProviderError struct {
+	Provider  string
+	Status    int
+	Retryable bool
+	Err       error
+}
+
+func (e *ProviderError) Error() string {
+	if e.Status != 0 {
+		return fmt.Sprintf("%s: status %d: %v", e.Provider, e.Status, e.Err)
+	}
+	return fmt.Sprintf("%s: %v", e.Provider, e.Err)
+}
+
+func (e *ProviderError) Unwrap() error { return e.Err }
+
+// ProviderStatus builds a ProviderError for a non-2xx response; 429 and 5xx
+// are retryable.
+func ProviderStatus(provider string, status int, body string) *ProviderError {
+	return &ProviderError{
+		Provider:  provider,
+		Status:    status,
+		Retryable: status == 429 || status >= 500,
+		Err:       errors.New(body),
+	}
+}
+
+// ProviderTransport wraps a network-level failure talking to provider.
+func ProviderTransport(provider string, err error) *ProviderError {
+	return &ProviderError{Provider: provider, Retryable: true, Err: err}
+}
+
+// ErrCircuitOpen is wrapped in a retryable ProviderError when a circuit
+// breaker fails a call fast instead of trying the provider.
+var ErrCircuitOpen = errors.New("circuit open")
+
+// CodeCircuitOpen is ErrCircuitOpen's in-band code on provider streams, so a
+// caller can switch to a fallback rather than wait out the breaker.
+const CodeCircuitOpen = "circuit_open"
+
+// CircuitOpen builds the error a breaker returns for provider.
+func CircuitOpen(provider string) *ProviderError {
+	return &ProviderError{Provider: provider, Retryable: true, Err: ErrCircuitOpen}
+}
+
+// GRPCStatus lets grpc-go map the error without an interceptor.
+func (e *ProviderError) GRPCStatus() *status.Status { return status.New(GRPCCode(e), e.Error()) }
+
+// ConfigError means the service is missing or has invalid configuration.
+type ConfigError struct {
+	Key string // env var or setting name, if known
+	Msg string
+}
+
+func (e *ConfigError) Error() string {
+	if e.Key != "" {
+		return fmt.Sprintf("config %s: %s", e.Key, e.Msg)
+	}
+	return "config: " + e.Msg
+}
+
+func (e *ConfigError) GRPCStatus() *status.Status { return status.New(GRPCCode(e), e.Error()) }
+
+// AuthError rejects a caller. Forbidden distinguishes a known caller without
+// permission from missing or invalid credentials.
+type AuthError struct {
+	Reason    string
+	Forbidden bool
+	Err       error
+}
+
+func (e *AuthError) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("auth: %s: %v", e.Reason, e.Err)
+	}
+	return "auth: " + e.Reason
+}
+
+func (e *AuthError) Unwrap() error { return e.Err }
+
+func (e *AuthError) GRPCStatus() *status.Status { return status.New(GRPCCode(e), e.Error()) }
+
+// Backpressure asks the caller to slow down; RetryAfter is a hint, zero if unknown.
+type Backpressure struct {
+	Resource   string
+	RetryAfter time.Duration
+}
+
+func (e *Backpressure) Error() string {
+	if e.RetryAfter > 0 {
+		return fmt.Sprintf("backpressure: %s (retry after %s)", e.Resource, e.RetryAfter)
+	}
+	return "backpressure: " + e.Resource
+}
+
+func (e *Backpressure) GRPCStatus() *status.Status { return status.New(GRPCCode(e), e.Error()) }
+
+// Class returns the class of err, or ClassInternal for untyped errors.
+func Class(err error) string {
+	var (
+		pe *ProviderError
+		ce *ConfigError
+		ae *AuthError
+		be *Backpressure
+	)
+	switch {
+	case errors.As(err, &pe):
+		return ClassProvider
+	case errors.As(err, &ce):
+		return ClassConfig
+	case errors.As(err, &ae):
+		return ClassAuth
+	case errors.As(err, &be):
+		return ClassBackpressure
+	}
+	return ClassInternal
+}
+
+// IsRetryable reports whether trying the same call again later may succeed.
+func IsRetryable(err error) bool {
+	var pe *ProviderError
+	if errors.As(err, &pe) {
+		return pe.Retryable
+	}
+	var be *Backpressure
+	return errors.As(err, &be)
+}
@@ -0,0 +1,84 @@
+package errdefs
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestMapping(t *testing.T) {
+	for _, c := range []struct {
+		name  string
+		err   error
+		class string
+		code  codes.Code
+		http  int
+	}{
+		{"provider retryable", ProviderStatus("azure", 503, "busy"), ClassProvider, codes.Unavailable, http.StatusBadGateway},
+		{"provider permanent", ProviderStatus("azure", 400, "bad"), ClassProvider, codes.FailedPrecondition, http.StatusBadGateway},
+		{"config", &ConfigError{Key: "X", Msg: "missing"}, ClassConfig, codes.FailedPrecondition, http.StatusServiceUnavailable},
+		{"auth", &AuthError{Reason: "invalid token"}, ClassAuth, codes.Unauthenticated, http.StatusUnauthorized},
+		{"forbidden", &AuthError{Reason: "dev", Forbidden: true}, ClassAuth, codes.PermissionDenied, http.StatusForbidden},
+		{"backpressure", &Backpressure{Resource: "queue"}, ClassBackpressure, codes.ResourceExhausted, http.StatusTooManyRequests},
+		{"wrapped", fmt.Errorf("start: %w", &ConfigError{Msg: "x"}), ClassConfig, codes.FailedPrecondition, http.StatusServiceUnavailable},
+		{"untyped", errors.New("boom"), ClassInternal, codes.Internal, http.StatusInternalServerError},
+	} {
+		if got := Class(c.err); got != c.class {
+			t.Errorf("%s: Class = %s, want %s", c.name, got, c.class)
+		}
+		if got := GRPCCode(c.err); got != c.code {
+			t.Errorf("%s: GRPCCode = %s, want %s", c.name, got, c.code)
+		}
+		if got := status.Code(ToGRPC(c.err)); got != c.code {
+			t.Errorf("%s: status.Code(ToGRPC) = %s, want %s", c.name, got, c.code)
+		}
+		if got := HTTPStatus(c.err); got != c.http {
+			t.Errorf("%s: HTTPStatus = %d, want %d", c.name, got, c.http)
+		}
+	}
+}
+
+func TestGRPCStatusWithoutConversion(t *testing.T) {
+	// grpc-go consults GRPCStatus, so handlers can return typed errors directly
+	if got := status.Code(&AuthError{Reason: "x"}); got != codes.Unauthenticated {
+		t.Fatalf("status.Code = %s, want Unauthenticated", got)
+	}
+}
+
+func TestFromGRPCRoundTrip(t *testing.T) {
+	err := FromGRPC("llm", ToGRPC(ProviderStatus("azure", 502, "bad gateway")))
+	if Class(err) != ClassProvider || !IsRetryable(err) {
+		t.Fatalf("FromGRPC = %v (class %s), want retryable provider error", err, Class(err))
+	}
+	if IsRetryable(FromGRPC("llm", status.Error(codes.InvalidArgument, "x"))) {
+		t.Fatal("InvalidArgument should not be retryable")
+	}
+}
+
+func TestWriteHTTP(t *testing.T) {
+	rec := httptest.NewRecorder()
+	WriteHTTP(rec, &Backpressure{Resource: "ws", RetryAfter: 1500 * time.Millisecond})
+	if rec.Code != http.StatusTooManyRequests {
+		t.Fatalf("status = %d, want 429", rec.Code)
+	}
+	if got := rec.Header().Get("Retry-After"); got != "2" {
+		t.Errorf("Retry-After = %q, want 2", got)
+	}
+	if got := rec.Header().Get("X-Error-Class"); got != ClassBackpressure {
+		t.Errorf("X-Error-Class = %q, want %s", got, ClassBackpressure)
+	}
+
+	// An unset secret is a 503 that names it
+	rec = httptest.NewRecorder()
+	WriteHTTP(rec, &ConfigError{Key: "WORKER_TOKEN_SECRET", Msg: "worker auth not configured"})
+	if rec.Code != http.StatusServiceUnavailable || !strings.Contains(rec.Body.String(), "WORKER_TOKEN_SECRET") {
+		t.Errorf("config error = %d %q", rec.Code, rec.Body.String())
+	}
+}
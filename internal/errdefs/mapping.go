@@ -0,0 +1,110 @@
+package errdefs
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// GRPCCode maps err to a gRPC status code. Non-retryable provider errors are
+// FailedPrecondition so clients don't reconnect-loop on them.
+func GRPCCode(err error) codes.Code {
+	if err == nil {
+		return codes.OK
+	}
+	switch Class(err) {
+	case ClassProvider:
+		var pe *ProviderError
+		if errors.As(err, &pe) && !pe.Retryable {
+			return codes.FailedPrecondition
+		}
+		return codes.Unavailable
+	case ClassConfig:
+		return codes.FailedPrecondition
+	case ClassAuth:
+		var ae *AuthError
+		if errors.As(err, &ae) && ae.Forbidden {
+			return codes.PermissionDenied
+		}
+		return codes.Unauthenticated
+	case ClassBackpressure:
+		return codes.ResourceExhausted
+	}
+	if s, ok := status.FromError(err); ok {
+		return s.Code()
+	}
+	return codes.Internal
+}
+
+// HTTPStatus maps err to an HTTP status code. A config error, such as an
+// unset secret, is 503: the server is up but can't serve this until it is
+// configured, as /synthesize answers without ELEVENLABS_API_KEY.
+func HTTPStatus(err error) int {
+	switch Class(err) {
+	case ClassProvider:
+		return http.StatusBadGateway
+	case ClassConfig:
+		return http.StatusServiceUnavailable
+	case ClassAuth:
+		var ae *AuthError
+		if errors.As(err, &ae) && ae.Forbidden {
+			return http.StatusForbidden
+		}
+		return http.StatusUnauthorized
+	case ClassBackpressure:
+		return http.StatusTooManyRequests
+	}
+	return http.StatusInternalServerError
+}
+
+// ToGRPC converts err into a status error, keeping typed errors' messages.
+func ToGRPC(err error) error {
+	if err == nil {
+		return nil
+	}
+	if _, ok := status.FromError(err); ok && Class(err) == ClassInternal {
+		return err
+	}
+	return status.Error(GRPCCode(err), err.Error())
+}
+
+// FromGRPC turns a status error received from another service back into the
+// matching typed error, so callers can use Class/IsRetryable across the wire.
+func FromGRPC(provider string, err error) error {
+	s, ok := status.FromError(err)
+	if !ok || err == nil {
+		return err
+	}
+	switch s.Code() {
+	case codes.Unavailable:
+		return &ProviderError{Provider: provider, Retryable: true, Err: err}
+	case codes.FailedPrecondition:
+		return &ProviderError{Provider: provider, Err: err}
+	case codes.Unauthenticated:
+		return &AuthError{Reason: s.Message(), Err: err}
+	case codes.PermissionDenied:
+		return &AuthError{Reason: s.Message(), Forbidden: true, Err: err}
+	case codes.ResourceExhausted:
+		return &Backpressure{Resource: provider}
+	}
+	return err
+}
+
+// WriteHTTP writes err as a plain-text response with the mapped status,
+// adding Retry-After for backpressure.
+func WriteHTTP(w http.ResponseWriter, err error) {
+	var be *Backpressure
+	if errors.As(err, &be) && be.RetryAfter > 0 {
+		secs := int(be.RetryAfter / time.Second)
+		if be.RetryAfter%time.Second != 0 {
+			secs++
+		}
+		w.Header().Set("Retry-After", strconv.Itoa(secs))
+	}
+	w.Header().Set("X-Error-Class", Class(err))
+	http.Error(w, err.Error(), HTTPStatus(err))
+}
@@ -0,0 +1,162 @@
+// Package export turns a session's stored worker events into datasets.
+package export
+
+import (
+	"encoding/json"
+	"io"
+	"sort"
+
+	"yuzu/agent/internal/types"
+)
+
+// Turn is one user utterance and the agent's reply to it, reassembled from
+// transcript_final / agent_text events and the TTS lifecycle events that
+// share their utterance IDs.
+type Turn struct {
+	TurnID          string
+	User            string
+	Assistant       string
+	UserFinalAt     int64 // backend receive time, unix ms
+	FirstAudioAt    int64 // backend receive time of the reply's first audio, unix ms
+	TTSFirstAudioMs int64 // synthesis latency reported by the worker
+	Interrupted     bool
+}
+
+// ResponseLatencyMs is user final transcript → first agent audio, or -1.
+func (t Turn) ResponseLatencyMs() int64 {
+	if t.UserFinalAt == 0 || t.FirstAudioAt == 0 || t.FirstAudioAt < t.UserFinalAt {
+		return -1
+	}
+	return t.FirstAudioAt - t.UserFinalAt
+}
+
+// Turns groups events by orchestrator turn ID. Turns without user text or
+// without any agent text are dropped; order follows the first user final.
+func Turns(events []types.Event) []Turn {
+	byID := map[string]*Turn{}
+	var order []string
+	uttTurn := map[string]string{} // agent utterance ID → turn ID
+	get := func(id string) *Turn {
+		t := byID[id]
+		if t == nil {
+			t = &Turn{TurnID: id}
+			byID[id] = t
+			order = append(order, id)
+		}
+		return t
+	}
+	for _, e := range events {
+		p := e.Payload
+		ms := e.Ts.UnixMilli()
+		switch e.Type {
+		case "transcript_final":
+			id := str(p, "turn_id")
+			if id == "" {
+				continue
+			}
+			t := get(id)
+			t.User = join(t.User, str(p, "text"))
+			if t.UserFinalAt == 0 {
+				t.UserFinalAt = ms
+			}
+		case "agent_text":
+			id := str(p, "turn_id")
+			if id == "" {
+				continue
+			}
+			t := get(id)
+			t.Assistant = join(t.Assistant, str(p, "text"))
+			if u := str(p, "utterance_id"); u != "" {
+				uttTurn[u] = id
+			}
+		case "tts_first_audio":
+			if t := byID[uttTurn[str(p, "utterance_id")]]; t != nil && t.FirstAudioAt == 0 {
+				t.FirstAudioAt = ms
+				t.TTSFirstAudioMs = num(p, "first_audio_ms")
+			}
+		case "tts_stopped":
+			if t := byID[uttTurn[str(p, "utterance_id")]]; t != nil && str(p, "reason") == "interrupted" {
+				t.Interrupted = true
+			}
+		}
+	}
+	out := make([]Turn, 0, len(order))
+	for _, id := range order {
+		if t := byID[id]; t.User != "" && t.Assistant != "" {
+			out = append(out, *t)
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].UserFinalAt < out[j].UserFinalAt })
+	return out
+}
+
+type chatMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type line struct {
+	Messages []chatMessage  `json:"messages"`
+	Metadata map[string]any `json:"metadata"`
+}
+
+// WriteOpenAIJSONL writes one chat example per turn: the system prompt, all
+// earlier turns as history, then the turn's user/assistant pair. Per-turn
+// metadata (latencies, interruption) rides alongside under "metadata".
+func WriteOpenAIJSONL(w io.Writer, sessionID, system string, turns []Turn) error {
+	enc := json.NewEncoder(w)
+	history := []chatMessage{}
+	if system != "" {
+		history = append(history, chatMessage{Role: "system", Content: system})
+	}
+	for i, t := range turns {
+		msgs := append(append([]chatMessage(nil), history...),
+			chatMessage{Role: "user", Content: t.User},
+			chatMessage{Role: "assistant", Content: t.Assistant})
+		meta := map[string]any{
+			"session_id":  sessionID,
+			"turn_id":     t.TurnID,
+			"turn_index":  i,
+			"interrupted": t.Interrupted,
+		}
+		if l := t.ResponseLatencyMs(); l >= 0 {
+			meta["response_latency_ms"] = l
+		}
+		if t.TTSFirstAudioMs > 0 {
+			meta["tts_first_audio_ms"] = t.TTSFirstAudioMs
+		}
+		if err := enc.Encode(line{Messages: msgs, Metadata: meta}); err != nil {
+			return err
+		}
+		history = msgs
+	}
+	return nil
+}
+
+func str(p map[string]any, k string) string {
+	s, _ := p[k].(string)
+	return s
+}
+
+// num reads a JSON number (float64 after decoding) or an int written in-process.
+func num(p map[string]any, k string) int64 {
+	switch v := p[k].(type) {
+	case float64:
+		return int64(v)
+	case int64:
+		return v
+	case int:
+		return int64(v)
+	}
+	return 0
+}
+
+func join(a, b string) string {
+	if a == "" {
+		return b
+	}
+	if b == "" {
+		return a
+	}
+	return a + " " + b
+}
@@ -0,0 +1,64 @@
+package export
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"yuzu/agent/internal/types"
+)
+
+func TestOpenAIJSONL(t *testing.T) {
+	t0 := time.UnixMilli(1700000000000)
+	ev := func(ms int, typ string, p map[string]any) types.Event {
+		return types.Event{Type: typ, Ts: t0.Add(time.Duration(ms) * time.Millisecond), Payload: p}
+	}
+	events := []types.Event{
+		ev(0, "vad_start", map[string]any{"source": "candidate_audio"}),
+		ev(100, "transcript_final", map[string]any{"utterance_id": "t1-u", "text": "Hi there", "turn_id": "t1"}),
+		ev(600, "agent_text", map[string]any{"utterance_id": "t1-a1", "text": "Hello! How are you?", "turn_id": "t1"}),
+		ev(900, "tts_first_audio", map[string]any{"utterance_id": "t1-a1", "first_audio_ms": 250.0}),
+		ev(2000, "tts_stopped", map[string]any{"utterance_id": "t1-a1", "reason": "completed"}),
+		ev(3000, "transcript_final", map[string]any{"utterance_id": "t2-u", "text": "Tell me a story", "turn_id": "t2"}),
+		ev(3400, "agent_text", map[string]any{"utterance_id": "t2-a2", "text": "Once upon a time", "turn_id": "t2"}),
+		ev(3700, "tts_first_audio", map[string]any{"utterance_id": "t2-a2", "first_audio_ms": 200.0}),
+		ev(4000, "tts_stopped", map[string]any{"utterance_id": "t2-a2", "reason": "interrupted"}),
+		// User spoke but no reply yet: not exported
+		ev(4100, "transcript_final", map[string]any{"utterance_id": "t3-u", "text": "Stop", "turn_id": "t3"}),
+	}
+
+	turns := Turns(events)
+	if len(turns) != 2 {
+		t.Fatalf("expected 2 complete turns, got %d", len(turns))
+	}
+	if turns[0].ResponseLatencyMs() != 800 || turns[1].Interrupted != true || turns[0].Interrupted {
+		t.Fatalf("unexpected turn metadata: %+v", turns)
+	}
+
+	var buf bytes.Buffer
+	if err := WriteOpenAIJSONL(&buf, "s1", "SYS", turns); err != nil {
+		t.Fatal(err)
+	}
+	var lines []line
+	sc := bufio.NewScanner(&buf)
+	for sc.Scan() {
+		var l line
+		if err := json.Unmarshal(sc.Bytes(), &l); err != nil {
+			t.Fatalf("bad line %q: %v", sc.Text(), err)
+		}
+		lines = append(lines, l)
+	}
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 lines, got %d", len(lines))
+	}
+	// Second example carries the first turn as history
+	got := lines[1].Messages
+	if len(got) != 5 || got[0].Role != "system" || got[1].Content != "Hi there" || got[4].Content != "Once upon a time" {
+		t.Fatalf("unexpected messages %+v", got)
+	}
+	if lines[1].Metadata["interrupted"] != true || lines[1].Metadata["tts_first_audio_ms"] != float64(200) {
+		t.Fatalf("unexpected metadata %+v", lines[1].Metadata)
+	}
+}
@@ -5,41 +5,140 @@ type Decision struct {
     ShouldStop      bool
     StopUtteranceID string
     Reason          string // e.g., "barge_in"
+
+    // Pause is "duck" or "hold" when the agent's speech should be lowered
+    // or held for an interjection; Resume brings it back. UtteranceID is
+    // the utterance either applies to.
+    Pause       string
+    Resume      bool
+    UtteranceID string
+
+    // Reprompt is what the agent should say to a candidate who has gone
+    // quiet (see Strategy)
+    Reprompt string
 }
 
+// Interjection modes for Policy.Interject.
+const (
+    InterjectOff  = "off"  // any speech stops the agent (barge-in)
+    InterjectDuck = "duck" // lower the agent's volume while the user speaks
+    InterjectHold = "hold" // pause the agent while the user speaks
+)
+
+// Policy decides what speech over the agent does. With Interject set to
+// duck or hold, speech first pauses the agent; if it ends within
+// MaxInterjectMs it was an interjection ("mm-hm", "right") and the agent
+// resumes, otherwise it is a barge-in and the agent is stopped. It is the
+// deployment-wide Strategy, used by sessions without a turn policy.
+type Policy struct {
+    Interject      string
+    MaxInterjectMs int64
+}
+
+// Pauses reports whether interjection mode pauses the agent at all.
+func Pauses(mode string) bool { return mode == InterjectDuck || mode == InterjectHold }
+
 type Manager struct {
+    strategy           Strategy
     speaking           bool
     activeUtteranceID  string
     lastVADStartTsMs   int64
     lastTTSStartedTsMs int64
+    firstAudioTsMs     int64
+    paused             bool
+    pausedAtTsMs       int64
+    reprompts          int // since the candidate last spoke
 }
 
-func New() *Manager { return &Manager{} }
+func New() *Manager { return &Manager{strategy: Policy{}} }
+
+// NewWithPolicy returns a Manager that handles speech over the agent per p.
+func NewWithPolicy(p Policy) *Manager { return &Manager{strategy: p} }
+
+// NewWithStrategy returns a Manager that follows s's turn-taking rules.
+func NewWithStrategy(s Strategy) *Manager { return &Manager{strategy: s} }
 
 func (m *Manager) OnTTSStarted(utteranceID string, tsMs int64) Decision {
     m.speaking = true
     m.activeUtteranceID = utteranceID
     m.lastTTSStartedTsMs = tsMs
+    m.firstAudioTsMs = 0
+    m.paused = false
     return Decision{}
 }
 
+// OnTTSFirstAudio notes when the agent became audible, which a guard
+// window counts from.
+func (m *Manager) OnTTSFirstAudio(tsMs int64) {
+    if m.speaking && m.firstAudioTsMs == 0 {
+        m.firstAudioTsMs = tsMs
+    }
+}
+
 func (m *Manager) OnTTSStopped(utteranceID string, tsMs int64, reason string) Decision {
     // Regardless of ID match, stopping clears speaking.
     m.speaking = false
     m.activeUtteranceID = ""
+    m.paused = false
     return Decision{}
 }
 
 func (m *Manager) OnVADStart(tsMs int64) Decision {
     m.lastVADStartTsMs = tsMs
-    if m.speaking {
-        // barge-in: stop immediately
-        return Decision{ShouldStop: true, StopUtteranceID: m.activeUtteranceID, Reason: "barge_in"}
+    m.reprompts = 0
+    if !m.speaking {
+        return Decision{}
     }
-    return Decision{}
+    return m.apply(tsMs, m.strategy.SpeechStarted(m.turn(tsMs)))
 }
 
 func (m *Manager) OnVADEnd(tsMs int64) Decision {
-    return Decision{}
+    if !m.speaking || !m.paused {
+        return Decision{}
+    }
+    return m.apply(tsMs, m.strategy.SpeechEnded(m.turn(tsMs)))
 }
 
+// OnSilence asks the strategy whether to re-prompt a candidate who has
+// been silent for silentMs since the agent finished.
+func (m *Manager) OnSilence(silentMs int64) Decision {
+    if m.speaking {
+        return Decision{}
+    }
+    t := Turn{SilentMs: silentMs, Reprompts: m.reprompts}
+    text := m.strategy.Reprompt(t)
+    if text == "" {
+        return Decision{}
+    }
+    m.reprompts++
+    return Decision{Reprompt: text, Reason: "reprompt"}
+}
+
+// LocalStop reports whether the strategy lets the worker stop the agent on
+// its own.
+func (m *Manager) LocalStop() bool { return m.strategy.LocalStop() }
+
+func (m *Manager) turn(tsMs int64) Turn {
+    t := Turn{UtteranceID: m.activeUtteranceID, Paused: m.paused, Reprompts: m.reprompts}
+    audible := m.firstAudioTsMs
+    if audible == 0 {
+        audible = m.lastTTSStartedTsMs
+    }
+    t.SpokenMs = tsMs - audible
+    if m.paused {
+        t.PausedMs = tsMs - m.pausedAtTsMs
+    }
+    return t
+}
+
+// apply updates the floor for the strategy's decision d.
+func (m *Manager) apply(tsMs int64, d Decision) Decision {
+    switch {
+    case d.ShouldStop, d.Resume:
+        m.paused = false
+    case d.Pause != "" && !m.paused:
+        m.paused = true
+        m.pausedAtTsMs = tsMs
+    }
+    return d
+}
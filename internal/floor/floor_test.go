@@ -29,3 +29,35 @@ func TestTTSStoppedClearsSpeaking(t *testing.T) {
     }
 }
 
+
+func TestInterjectionPausesAndResumes(t *testing.T) {
+    f := NewWithPolicy(Policy{Interject: InterjectHold, MaxInterjectMs: 1500})
+    f.OnTTSStarted("u1", 1000)
+    d := f.OnVADStart(2000)
+    if d.ShouldStop || d.Pause != InterjectHold || d.UtteranceID != "u1" {
+        t.Fatalf("expected hold on speech over the agent, got %+v", d)
+    }
+    d = f.OnVADEnd(2600)
+    if !d.Resume || d.UtteranceID != "u1" {
+        t.Fatalf("expected resume after a short interjection, got %+v", d)
+    }
+}
+
+func TestLongInterjectionBecomesBargeIn(t *testing.T) {
+    f := NewWithPolicy(Policy{Interject: InterjectDuck, MaxInterjectMs: 1500})
+    f.OnTTSStarted("u1", 1000)
+    if d := f.OnVADStart(2000); d.Pause != InterjectDuck {
+        t.Fatalf("expected duck, got %+v", d)
+    }
+    // A second start within the window changes nothing; past it, stop
+    if d := f.OnVADStart(3000); d.ShouldStop || d.Pause != "" {
+        t.Fatalf("second start inside the window = %+v", d)
+    }
+    d := f.OnVADEnd(4000)
+    if !d.ShouldStop || d.StopUtteranceID != "u1" || d.Reason != "barge_in" {
+        t.Fatalf("expected stop after a long interjection, got %+v", d)
+    }
+    if d := f.OnVADEnd(4100); d.Resume || d.ShouldStop {
+        t.Fatalf("nothing should follow the stop, got %+v", d)
+    }
+}
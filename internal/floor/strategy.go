@@ -0,0 +1,151 @@
+package floor
+
+// strategy.go holds the turn-taking rules apart from the Manager, which
+// only tracks who holds the floor. A Strategy decides what speech over the
+// agent does (ignore it inside a guard window, stop, or duck/hold and
+// resume) and when to prompt a candidate who has gone quiet. Policy, the
+// deployment's FLOOR_INTERJECTION_MODE setting, is the default; sessions
+// created with "turn_policy": "aggressive" or "polite" use one of
+// Strategies instead:
+//
+//   - aggressive stops the agent on any speech and asks again after 6s of
+//     silence, once
+//   - polite ignores speech in the first 800ms of the agent's audio, ducks
+//     for anything later, stops only once the speech has gone on for 2s,
+//     and re-prompts after 10s of silence, at most twice
+
+// Turn is what a Strategy sees of the floor when deciding.
+type Turn struct {
+    UtteranceID string
+    // Since the agent's first audio (or its start, before any audio)
+    SpokenMs int64
+    // Paused is set while speech has the agent ducked or held; PausedMs
+    // is how long that has lasted
+    Paused   bool
+    PausedMs int64
+    // Since the agent finished with no speech from the candidate, and the
+    // re-prompts already made in that silence
+    SilentMs  int64
+    Reprompts int
+}
+
+// Strategy is a set of turn-taking rules. Decisions are applied by the
+// Manager: Pause pauses the floor, ShouldStop and Resume clear it.
+type Strategy interface {
+    // SpeechStarted decides what speech starting over the agent does.
+    SpeechStarted(t Turn) Decision
+    // SpeechEnded decides what the end of speech over a paused agent does.
+    SpeechEnded(t Turn) Decision
+    // Reprompt returns what the agent says to a silent candidate, or ""
+    // to keep waiting.
+    Reprompt(t Turn) string
+    // LocalStop reports whether the worker may stop the agent on its own,
+    // which would cut it before a guard or pause could apply.
+    LocalStop() bool
+}
+
+// Strategies are the turn policies a session can be created with.
+var Strategies = map[string]Strategy{
+    "aggressive": Aggressive{RepromptAfterMs: 6000},
+    "polite":     Polite{GuardMs: 800, MaxInterjectMs: 2000, RepromptAfterMs: 10000, MaxReprompts: 2},
+}
+
+// StrategyFor returns the named strategy, or def for "".
+func StrategyFor(name string, def Strategy) (Strategy, bool) {
+    if name == "" {
+        return def, true
+    }
+    s, ok := Strategies[name]
+    return s, ok
+}
+
+func stop(t Turn) Decision {
+    return Decision{ShouldStop: true, StopUtteranceID: t.UtteranceID, UtteranceID: t.UtteranceID, Reason: "barge_in"}
+}
+
+func (p Policy) SpeechStarted(t Turn) Decision {
+    if !Pauses(p.Interject) {
+        return Decision{ShouldStop: true, StopUtteranceID: t.UtteranceID, Reason: "barge_in"}
+    }
+    if !t.Paused {
+        return Decision{Pause: p.Interject, UtteranceID: t.UtteranceID, Reason: "interjection"}
+    }
+    // Speech resumed within the hold; it becomes a barge-in once it has
+    // gone on too long
+    if t.PausedMs > p.MaxInterjectMs {
+        return stop(t)
+    }
+    return Decision{}
+}
+
+func (p Policy) SpeechEnded(t Turn) Decision {
+    if t.PausedMs > p.MaxInterjectMs {
+        return stop(t)
+    }
+    return Decision{Resume: true, UtteranceID: t.UtteranceID, Reason: "interjection"}
+}
+
+func (p Policy) Reprompt(t Turn) string { return "" }
+
+func (p Policy) LocalStop() bool { return !Pauses(p.Interject) }
+
+// Aggressive yields the floor at once and keeps the conversation moving.
+type Aggressive struct {
+    RepromptAfterMs int64 // 0 never re-prompts
+}
+
+func (a Aggressive) SpeechStarted(t Turn) Decision { return stop(t) }
+
+func (a Aggressive) SpeechEnded(t Turn) Decision { return Decision{} }
+
+func (a Aggressive) Reprompt(t Turn) string {
+    if a.RepromptAfterMs <= 0 || t.Reprompts > 0 || t.SilentMs < a.RepromptAfterMs {
+        return ""
+    }
+    return "Are you still there?"
+}
+
+func (a Aggressive) LocalStop() bool { return true }
+
+// Polite lets the agent finish its opening words, talks under short
+// interjections and gives the candidate time before prompting.
+type Polite struct {
+    GuardMs         int64 // speech this soon after the agent's first audio is ignored
+    MaxInterjectMs  int64 // ducked speech longer than this stops the agent
+    RepromptAfterMs int64 // 0 never re-prompts
+    MaxReprompts    int
+}
+
+// politeReprompts are said in turn; the last repeats.
+var politeReprompts = []string{
+    "Take your time. Let me know when you're ready.",
+    "Whenever you're ready, go ahead.",
+}
+
+func (p Polite) SpeechStarted(t Turn) Decision {
+    switch {
+    case !t.Paused && t.SpokenMs < p.GuardMs:
+        return Decision{Reason: "guard"}
+    case !t.Paused:
+        return Decision{Pause: InterjectDuck, UtteranceID: t.UtteranceID, Reason: "interjection"}
+    case t.PausedMs > p.MaxInterjectMs:
+        return stop(t)
+    }
+    return Decision{}
+}
+
+func (p Polite) SpeechEnded(t Turn) Decision {
+    if t.PausedMs > p.MaxInterjectMs {
+        return stop(t)
+    }
+    return Decision{Resume: true, UtteranceID: t.UtteranceID, Reason: "interjection"}
+}
+
+func (p Polite) Reprompt(t Turn) string {
+    if p.RepromptAfterMs <= 0 || t.Reprompts >= p.MaxReprompts || t.SilentMs < p.RepromptAfterMs {
+        return ""
+    }
+    return politeReprompts[min(t.Reprompts, len(politeReprompts)-1)]
+}
+
+func (p Polite) LocalStop() bool { return false }
@@ -0,0 +1,73 @@
+package floor
+
+import "testing"
+
+func TestAggressiveStopsAtOnce(t *testing.T) {
+    f := NewWithStrategy(Strategies["aggressive"])
+    f.OnTTSStarted("u1", 1000)
+    f.OnTTSFirstAudio(1100)
+    if d := f.OnVADStart(1150); !d.ShouldStop || d.StopUtteranceID != "u1" {
+        t.Fatalf("expected stop on speech, got %+v", d)
+    }
+    if f.LocalStop() != true {
+        t.Error("aggressive should allow local stop")
+    }
+}
+
+func TestPoliteGuardsThenDucks(t *testing.T) {
+    f := NewWithStrategy(Strategies["polite"])
+    f.OnTTSStarted("u1", 1000)
+    f.OnTTSFirstAudio(1300)
+    // Within 800ms of the first audio the speech is ignored
+    if d := f.OnVADStart(1900); d.ShouldStop || d.Pause != "" || d.Reason != "guard" {
+        t.Fatalf("expected guard, got %+v", d)
+    }
+    if d := f.OnVADStart(2200); d.Pause != InterjectDuck {
+        t.Fatalf("expected duck after the guard, got %+v", d)
+    }
+    if d := f.OnVADEnd(3000); !d.Resume {
+        t.Fatalf("expected resume after a short interjection, got %+v", d)
+    }
+    f.OnVADStart(4000)
+    if d := f.OnVADEnd(6500); !d.ShouldStop || d.StopUtteranceID != "u1" {
+        t.Fatalf("expected stop after 2.5s of speech, got %+v", d)
+    }
+    if f.LocalStop() {
+        t.Error("polite must not allow local stop")
+    }
+}
+
+func TestReprompts(t *testing.T) {
+    f := NewWithStrategy(Strategies["polite"])
+    if d := f.OnSilence(9000); d.Reprompt != "" {
+        t.Fatalf("re-prompted too early: %+v", d)
+    }
+    var said []string
+    for i := 0; i < 3; i++ {
+        if d := f.OnSilence(10000); d.Reprompt != "" {
+            said = append(said, d.Reprompt)
+        }
+    }
+    if len(said) != 2 || said[0] == said[1] {
+        t.Fatalf("polite re-prompts = %q, want two different ones", said)
+    }
+    // Speech from the candidate starts the count over
+    f.OnVADStart(20000)
+    if d := f.OnSilence(10000); d.Reprompt == "" {
+        t.Fatal("no re-prompt after the candidate spoke again")
+    }
+    // The deployment's Policy never re-prompts
+    if d := New().OnSilence(60000); d.Reprompt != "" {
+        t.Fatalf("default policy re-prompted: %+v", d)
+    }
+}
+
+func TestStrategyFor(t *testing.T) {
+    def := Policy{Interject: InterjectHold}
+    if s, ok := StrategyFor("", def); !ok || s != Strategy(def) {
+        t.Errorf("StrategyFor(\"\") = %v, %v", s, ok)
+    }
+    if _, ok := StrategyFor("shy", def); ok {
+        t.Error("unknown name accepted")
+    }
+}
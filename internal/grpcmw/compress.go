@@ -0,0 +1,148 @@
+package grpcmw
+
+import (
+	"io"
+	"log"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/klauspost/compress/snappy"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/encoding"
+	_ "google.golang.org/grpc/encoding/gzip" // registers "gzip"
+)
+
+// compress.go compresses the audio streams. STT requests and TTS responses
+// are raw PCM, which is worth shrinking when the gateway and the services
+// run on separate hosts; on one host it only costs CPU, so it is off by
+// default. <SERVICE>_GRPC_COMPRESSION (STT_GRPC_COMPRESSION,
+// TTS_GRPC_COMPRESSION) lists compressors in order of preference, e.g.
+// "snappy,gzip". The server answers a stream with the first one its client
+// advertises in grpc-accept-encoding; Go clients dialing with
+// CompressionDialOptions compress requests with the first. Messages in any
+// registered encoding (gzip, snappy) are always accepted. Streams are
+// counted in grpc_server_stream_compression_total{service,compressor}.
+
+// Snappy is the name of the snappy compressor registered by this package.
+const Snappy = "snappy"
+
+func init() {
+	c := &snappyCompressor{}
+	c.writers.New = func() any { return &snappyWriter{Writer: snappy.NewBufferedWriter(io.Discard), pool: &c.writers} }
+	encoding.RegisterCompressor(c)
+}
+
+// snappyCompressor pools writers and readers like gRPC's gzip: audio
+// frames are small and frequent, and a fresh snappy buffer per message
+// would dwarf the frame.
+type snappyCompressor struct {
+	writers sync.Pool
+	readers sync.Pool
+}
+
+func (c *snappyCompressor) Name() string { return Snappy }
+
+func (c *snappyCompressor) Compress(w io.Writer) (io.WriteCloser, error) {
+	z := c.writers.Get().(*snappyWriter)
+	z.Writer.Reset(w)
+	return z, nil
+}
+
+func (c *snappyCompressor) Decompress(r io.Reader) (io.Reader, error) {
+	z, ok := c.readers.Get().(*snappyReader)
+	if !ok {
+		return &snappyReader{Reader: snappy.NewReader(r), pool: &c.readers}, nil
+	}
+	z.Reader.Reset(r)
+	return z, nil
+}
+
+type snappyWriter struct {
+	*snappy.Writer
+	pool *sync.Pool
+}
+
+func (z *snappyWriter) Close() error {
+	defer z.pool.Put(z)
+	return z.Writer.Close()
+}
+
+type snappyReader struct {
+	*snappy.Reader
+	pool *sync.Pool
+}
+
+func (z *snappyReader) Read(p []byte) (int, error) {
+	n, err := z.Reader.Read(p)
+	if err == io.EOF {
+		z.pool.Put(z)
+	}
+	return n, err
+}
+
+// CompressionFromEnv reads <SERVICE>_GRPC_COMPRESSION for service ("stt",
+// "tts"), dropping names that are not registered compressors.
+func CompressionFromEnv(service string) []string {
+	key := strings.ToUpper(service) + "_GRPC_COMPRESSION"
+	var out []string
+	for _, name := range strings.Split(os.Getenv(key), ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" || name == "none" {
+			continue
+		}
+		if encoding.GetCompressor(name) == nil {
+			log.Printf("[grpc] %s: unknown compressor %q ignored", key, name)
+			continue
+		}
+		out = append(out, name)
+	}
+	return out
+}
+
+// CompressionDialOptions makes a client compress its requests to service
+// with the first compressor in <SERVICE>_GRPC_COMPRESSION.
+func CompressionDialOptions(service string) []grpc.DialOption {
+	names := CompressionFromEnv(service)
+	if len(names) == 0 {
+		return nil
+	}
+	return []grpc.DialOption{grpc.WithDefaultCallOptions(grpc.UseCompressor(names[0]))}
+}
+
+// StreamCompression picks the response compressor for each stream.
+func StreamCompression(o Options) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if len(o.Compression) == 0 {
+			return handler(srv, ss)
+		}
+		advertised, err := grpc.ClientSupportedCompressors(ss.Context())
+		if err != nil {
+			return handler(srv, ss)
+		}
+		name := pickCompressor(o.Compression, advertised)
+		if name != "" {
+			if err := grpc.SetSendCompressor(ss.Context(), name); err != nil {
+				log.Printf("[grpc] %s %s: %v", o.Service, info.FullMethod, err)
+				name = ""
+			}
+		}
+		if name == "" {
+			name = "identity"
+		}
+		metricStreamCompression.WithLabelValues(o.Service, name).Inc()
+		return handler(srv, ss)
+	}
+}
+
+// pickCompressor returns the first of preferred the client advertised, or "".
+func pickCompressor(preferred, advertised []string) string {
+	for _, p := range preferred {
+		for _, a := range advertised {
+			if strings.EqualFold(strings.TrimSpace(a), p) {
+				return p
+			}
+		}
+	}
+	return ""
+}
@@ -0,0 +1,147 @@
+package grpcmw
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"io"
+	"math"
+	"math/rand"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/encoding"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// pcmFrame returns n samples of speech-like 16-bit PCM: a few harmonics
+// under a syllable-rate envelope plus low noise.
+func pcmFrame(n int, seed int64) []byte { return pcm(n, seed, 1, 150) }
+
+// pcm synthesizes n samples with the harmonics scaled by level and
+// Gaussian noise of the given deviation; level 0 is room tone.
+func pcm(n int, seed int64, level, noise float64) []byte {
+	r := rand.New(rand.NewSource(seed))
+	buf := make([]byte, 2*n)
+	for i := 0; i < n; i++ {
+		t := float64(i) / 16000
+		env := 0.5 + 0.5*math.Sin(2*math.Pi*4*t)
+		v := level * env * (6000*math.Sin(2*math.Pi*180*t) + 2500*math.Sin(2*math.Pi*360*t) + 1200*math.Sin(2*math.Pi*720*t))
+		v += r.NormFloat64() * noise
+		binary.LittleEndian.PutUint16(buf[2*i:], uint16(int16(v)))
+	}
+	return buf
+}
+
+func compressWith(t testing.TB, name string, in []byte) []byte {
+	var out bytes.Buffer
+	w, err := encoding.GetCompressor(name).Compress(&out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.Write(in)
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return out.Bytes()
+}
+
+func TestSnappyRoundTrip(t *testing.T) {
+	c := encoding.GetCompressor(Snappy)
+	if c == nil {
+		t.Fatal("snappy not registered")
+	}
+	// Twice, so the pooled writer and reader are reused
+	for seed := int64(1); seed <= 2; seed++ {
+		in := pcmFrame(320, seed)
+		r, err := c.Decompress(bytes.NewReader(compressWith(t, Snappy, in)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := io.ReadAll(r)
+		if err != nil || !bytes.Equal(got, in) {
+			t.Fatalf("round trip: %d bytes, %v", len(got), err)
+		}
+	}
+}
+
+func TestCompressionFromEnv(t *testing.T) {
+	t.Setenv("STT_GRPC_COMPRESSION", " Snappy, brotli ,gzip,none")
+	if got := CompressionFromEnv("stt"); len(got) != 2 || got[0] != "snappy" || got[1] != "gzip" {
+		t.Errorf("CompressionFromEnv = %v", got)
+	}
+	if got := CompressionFromEnv("tts"); got != nil {
+		t.Errorf("unset = %v", got)
+	}
+	if pickCompressor([]string{"snappy", "gzip"}, []string{"identity", "deflate", "gzip"}) != "gzip" {
+		t.Error("should fall back to the client's gzip")
+	}
+	if pickCompressor([]string{"snappy"}, []string{"gzip"}) != "" {
+		t.Error("picked a compressor the client did not advertise")
+	}
+}
+
+func TestStreamCompressionNegotiated(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	o := Options{Service: "ctest", Compression: []string{Snappy, "gzip"}}
+	srv := grpc.NewServer(ServerOptions(o)...)
+	healthpb.RegisterHealthServer(srv, health.NewServer())
+	go srv.Serve(l)
+	defer srv.Stop()
+
+	t.Setenv("CTEST_GRPC_COMPRESSION", "snappy")
+	conn, err := grpc.NewClient(l.Addr().String(), append(CompressionDialOptions("ctest"), grpc.WithTransportCredentials(insecure.NewCredentials()))...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	stream, err := healthpb.NewHealthClient(conn).Watch(ctx, &healthpb.HealthCheckRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp, err := stream.Recv(); err != nil || resp.GetStatus() != healthpb.HealthCheckResponse_SERVING {
+		t.Fatalf("Watch = %v, %v", resp, err)
+	}
+	if n := testutil.ToFloat64(metricStreamCompression.WithLabelValues("ctest", Snappy)); n != 1 {
+		t.Errorf("snappy streams = %v, want 1", n)
+	}
+}
+
+// BenchmarkCompressPCM compresses a 20ms 16kHz frame (an STT request) and a
+// 100ms 48kHz chunk (about one TTS response) of speech and of room tone;
+// ratio is compressed/raw bytes. Lossless compressors gain little on
+// speech and a lot on the pauses between turns, which is most of a mic
+// stream.
+func BenchmarkCompressPCM(b *testing.B) {
+	for _, in := range []struct {
+		name string
+		pcm  []byte
+	}{
+		{"stt-speech", pcm(320, 1, 1, 150)},
+		{"stt-silence", pcm(320, 1, 0, 2)},
+		{"tts-speech", pcm(4800, 2, 1, 150)},
+		{"tts-silence", pcm(4800, 2, 0, 2)},
+	} {
+		for _, name := range []string{"gzip", Snappy} {
+			b.Run(in.name+"/"+name, func(b *testing.B) {
+				b.SetBytes(int64(len(in.pcm)))
+				b.ReportAllocs()
+				var out int
+				for i := 0; i < b.N; i++ {
+					out = len(compressWith(b, name, in.pcm))
+				}
+				b.ReportMetric(float64(out)/float64(len(in.pcm)), "ratio")
+			})
+		}
+	}
+}
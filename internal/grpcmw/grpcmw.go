@@ -0,0 +1,217 @@
+// Package grpcmw provides the gRPC server interceptors shared by the
+// orchestrator, STT sidecar, LLM and TTS services: request logging, panic
+// recovery, Prometheus RPC metrics and deadline enforcement, plus their
+// message-size and flow-control settings (see transport.go) and stream
+// compression (see compress.go).
+package grpcmw
+
+import (
+	"context"
+	"log"
+	"os"
+	"runtime/debug"
+	"strconv"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Options controls interceptor behavior for a single service.
+type Options struct {
+	// Service labels metrics and log lines (e.g. "orchestrator", "stt").
+	Service string
+	// UnaryTimeout is applied to unary calls that arrive without a deadline.
+	// Zero disables the default deadline.
+	UnaryTimeout time.Duration
+	// MaxStreamDuration bounds the lifetime of a streaming call. Zero means
+	// streams only end when the client or server closes them.
+	MaxStreamDuration time.Duration
+	// LogStreams logs stream open/close lines in addition to unary calls.
+	LogStreams bool
+	// Transport sizes messages and flow-control windows.
+	Transport Transport
+	// Compression lists response compressors by preference (see compress.go).
+	Compression []string
+}
+
+// OptionsFromEnv builds Options for service using the shared GRPC_* env vars.
+func OptionsFromEnv(service string) Options {
+	return Options{
+		Service:           service,
+		UnaryTimeout:      time.Duration(envInt("GRPC_UNARY_TIMEOUT_MS", 10000)) * time.Millisecond,
+		MaxStreamDuration: time.Duration(envInt("GRPC_MAX_STREAM_S", 0)) * time.Second,
+		LogStreams:        envBool("GRPC_LOG_STREAMS", true),
+		Transport:         TransportFromEnv(),
+		Compression:       CompressionFromEnv(service),
+	}
+}
+
+// ServerOptions returns the chained interceptors and transport settings for
+// use with grpc.NewServer. Recovery is innermost, so a panic is counted and
+// logged as the codes.Internal it becomes.
+func ServerOptions(o Options) []grpc.ServerOption {
+	return append([]grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(
+			UnaryMetrics(o),
+			UnaryLogging(o),
+			UnaryDeadline(o),
+			UnaryRecovery(o),
+		),
+		grpc.ChainStreamInterceptor(
+			StreamMetrics(o),
+			StreamLogging(o),
+			StreamDeadline(o),
+			StreamCompression(o),
+			StreamRecovery(o),
+		),
+	}, o.Transport.serverOptions()...)
+}
+
+// UnaryRecovery converts handler panics into codes.Internal errors.
+func UnaryRecovery(o Options) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp any, err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				err = recovered(o, info.FullMethod, r)
+			}
+		}()
+		return handler(ctx, req)
+	}
+}
+
+// StreamRecovery converts stream handler panics into codes.Internal errors.
+func StreamRecovery(o Options) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				err = recovered(o, info.FullMethod, r)
+			}
+		}()
+		return handler(srv, ss)
+	}
+}
+
+func recovered(o Options, method string, r any) error {
+	metricPanics.WithLabelValues(o.Service, method).Inc()
+	log.Printf("[grpc] %s panic in %s: %v\n%s", o.Service, method, r, debug.Stack())
+	return status.Errorf(codes.Internal, "internal error")
+}
+
+// UnaryMetrics records handled counts and latency for unary calls.
+func UnaryMetrics(o Options) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		start := time.Now()
+		resp, err := handler(ctx, req)
+		observe(o.Service, info.FullMethod, "unary", start, err)
+		return resp, err
+	}
+}
+
+// StreamMetrics records handled counts, duration and active streams.
+func StreamMetrics(o Options) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		start := time.Now()
+		gaugeStreamsActive.WithLabelValues(o.Service).Inc()
+		defer gaugeStreamsActive.WithLabelValues(o.Service).Dec()
+		err := handler(srv, ss)
+		observe(o.Service, info.FullMethod, "stream", start, err)
+		return err
+	}
+}
+
+func observe(service, method, kind string, start time.Time, err error) {
+	code := status.Code(err).String()
+	metricHandled.WithLabelValues(service, method, kind, code).Inc()
+	metricHandlingMS.WithLabelValues(service, method, kind).Observe(float64(time.Since(start).Milliseconds()))
+}
+
+// UnaryLogging logs one line per unary call.
+func UnaryLogging(o Options) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		start := time.Now()
+		resp, err := handler(ctx, req)
+		log.Printf("[grpc] %s unary %s code=%s dur=%dms", o.Service, info.FullMethod, status.Code(err), time.Since(start).Milliseconds())
+		return resp, err
+	}
+}
+
+// StreamLogging logs stream open and close when o.LogStreams is set.
+func StreamLogging(o Options) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if !o.LogStreams {
+			return handler(srv, ss)
+		}
+		start := time.Now()
+		log.Printf("[grpc] %s stream open %s", o.Service, info.FullMethod)
+		err := handler(srv, ss)
+		log.Printf("[grpc] %s stream close %s code=%s dur=%dms", o.Service, info.FullMethod, status.Code(err), time.Since(start).Milliseconds())
+		return err
+	}
+}
+
+// UnaryDeadline applies o.UnaryTimeout to calls without a client deadline.
+func UnaryDeadline(o Options) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if o.UnaryTimeout <= 0 {
+			return handler(ctx, req)
+		}
+		if _, ok := ctx.Deadline(); ok {
+			return handler(ctx, req)
+		}
+		ctx, cancel := context.WithTimeout(ctx, o.UnaryTimeout)
+		defer cancel()
+		return handler(ctx, req)
+	}
+}
+
+// StreamDeadline bounds stream lifetime to o.MaxStreamDuration. A tighter
+// client deadline is always honored.
+func StreamDeadline(o Options) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if o.MaxStreamDuration <= 0 {
+			return handler(srv, ss)
+		}
+		ctx, cancel := context.WithTimeout(ss.Context(), o.MaxStreamDuration)
+		defer cancel()
+		err := handler(srv, &wrappedStream{ServerStream: ss, ctx: ctx})
+		if err != nil && ctx.Err() == context.DeadlineExceeded {
+			metricDeadlineExceeded.WithLabelValues(o.Service, info.FullMethod).Inc()
+			return status.Error(codes.DeadlineExceeded, "stream exceeded max duration")
+		}
+		return err
+	}
+}
+
+// wrappedStream overrides the stream context so handlers observe the deadline.
+type wrappedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (w *wrappedStream) Context() context.Context { return w.ctx }
+
+func envInt(key string, def int) int {
+	v := os.Getenv(key)
+	if v == "" {
+		return def
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		return def
+	}
+	return n
+}
+
+func envBool(key string, def bool) bool {
+	v := os.Getenv(key)
+	if v == "" {
+		return def
+	}
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		return def
+	}
+	return b
+}
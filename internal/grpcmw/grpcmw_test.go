@@ -0,0 +1,137 @@
+package grpcmw
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+type fakeStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (f *fakeStream) Context() context.Context { return f.ctx }
+
+func TestUnaryRecoveryReturnsInternal(t *testing.T) {
+	o := Options{Service: "test"}
+	info := &grpc.UnaryServerInfo{FullMethod: "/test.v1.Svc/Call"}
+	_, err := UnaryRecovery(o)(context.Background(), nil, info, func(ctx context.Context, req any) (any, error) {
+		panic("boom")
+	})
+	if status.Code(err) != codes.Internal {
+		t.Fatalf("expected Internal, got %v", err)
+	}
+}
+
+func TestStreamRecoveryReturnsInternal(t *testing.T) {
+	o := Options{Service: "test"}
+	info := &grpc.StreamServerInfo{FullMethod: "/test.v1.Svc/Session"}
+	err := StreamRecovery(o)(nil, &fakeStream{ctx: context.Background()}, info, func(srv any, ss grpc.ServerStream) error {
+		panic("boom")
+	})
+	if status.Code(err) != codes.Internal {
+		t.Fatalf("expected Internal, got %v", err)
+	}
+}
+
+func TestPanicsAreCountedAsInternal(t *testing.T) {
+	// Recovery runs inside the metrics interceptor, which sees the status
+	const method = "/test.v1.Svc/Session"
+	l := bufconn.Listen(1 << 16)
+	opts := append(ServerOptions(Options{Service: "panics"}), grpc.UnknownServiceHandler(func(srv any, ss grpc.ServerStream) error {
+		panic("boom")
+	}))
+	gs := grpc.NewServer(opts...)
+	go gs.Serve(l)
+	defer gs.Stop()
+	conn, err := grpc.NewClient("passthrough:///bufnet", grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return l.DialContext(ctx) }))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	cs, err := conn.NewStream(ctx, &grpc.StreamDesc{ServerStreams: true}, method)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cs.RecvMsg(new(struct{})); status.Code(err) != codes.Internal {
+		t.Fatalf("client got %v, want Internal", err)
+	}
+	if n := testutil.ToFloat64(metricHandled.WithLabelValues("panics", method, "stream", codes.Internal.String())); n != 1 {
+		t.Fatalf("handled{code=Internal} = %v, want 1", n)
+	}
+}
+
+func TestUnaryDeadlineApplied(t *testing.T) {
+	o := Options{Service: "test", UnaryTimeout: 50 * time.Millisecond}
+	info := &grpc.UnaryServerInfo{FullMethod: "/test.v1.Svc/Call"}
+	_, _ = UnaryDeadline(o)(context.Background(), nil, info, func(ctx context.Context, req any) (any, error) {
+		if _, ok := ctx.Deadline(); !ok {
+			t.Fatal("expected deadline on handler context")
+		}
+		return nil, nil
+	})
+}
+
+func TestUnaryDeadlineKeepsClientDeadline(t *testing.T) {
+	o := Options{Service: "test", UnaryTimeout: time.Hour}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	want, _ := ctx.Deadline()
+	info := &grpc.UnaryServerInfo{FullMethod: "/test.v1.Svc/Call"}
+	_, _ = UnaryDeadline(o)(ctx, nil, info, func(ctx context.Context, req any) (any, error) {
+		if got, _ := ctx.Deadline(); !got.Equal(want) {
+			t.Fatalf("client deadline overridden: got %v want %v", got, want)
+		}
+		return nil, nil
+	})
+}
+
+func TestStreamDeadlineEnforced(t *testing.T) {
+	o := Options{Service: "test", MaxStreamDuration: 20 * time.Millisecond}
+	info := &grpc.StreamServerInfo{FullMethod: "/test.v1.Svc/Session"}
+	err := StreamDeadline(o)(nil, &fakeStream{ctx: context.Background()}, info, func(srv any, ss grpc.ServerStream) error {
+		<-ss.Context().Done()
+		return ss.Context().Err()
+	})
+	if status.Code(err) != codes.DeadlineExceeded {
+		t.Fatalf("expected DeadlineExceeded, got %v", err)
+	}
+}
+
+func TestTransportFromEnv(t *testing.T) {
+	if opts := TransportFromEnv().serverOptions(); len(opts) != 0 {
+		t.Fatalf("unset env produced %d server options, want gRPC defaults", len(opts))
+	}
+	t.Setenv("GRPC_MAX_RECV_MSG_BYTES", "1048576")
+	t.Setenv("GRPC_MAX_SEND_MSG_BYTES", "1048576")
+	t.Setenv("GRPC_INITIAL_WINDOW_BYTES", "262144")
+	t.Setenv("GRPC_WRITE_BUFFER_BYTES", "bogus")
+	tr := TransportFromEnv()
+	want := Transport{MaxRecvMsgSize: 1 << 20, MaxSendMsgSize: 1 << 20, InitialWindowSize: 256 << 10}
+	if tr != want {
+		t.Fatalf("TransportFromEnv = %+v, want %+v", tr, want)
+	}
+	if n := len(tr.serverOptions()); n != 3 {
+		t.Errorf("server options = %d, want 3", n)
+	}
+	// Both size limits travel as one set of default call options
+	if n := len(tr.DialOptions()); n != 2 {
+		t.Errorf("dial options = %d, want 2", n)
+	}
+	if n := len(ServerOptions(Options{Service: "test", Transport: tr})); n != 5 {
+		t.Errorf("ServerOptions = %d, want 2 interceptor chains + 3 transport options", n)
+	}
+}
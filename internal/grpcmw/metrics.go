@@ -0,0 +1,39 @@
+package grpcmw
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	metricHandled = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "grpc_server_handled_total",
+		Help: "Total RPCs completed on the server by status code",
+	}, []string{"service", "method", "kind", "code"})
+
+	metricHandlingMS = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "grpc_server_handling_ms",
+		Help:    "RPC handling time (ms); for streams this is the stream lifetime",
+		Buckets: prometheus.ExponentialBuckets(1, 2.5, 14),
+	}, []string{"service", "method", "kind"})
+
+	metricPanics = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "grpc_server_panics_total",
+		Help: "Handler panics recovered by the interceptor",
+	}, []string{"service", "method"})
+
+	metricDeadlineExceeded = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "grpc_server_stream_deadline_exceeded_total",
+		Help: "Streams terminated by the max stream duration",
+	}, []string{"service", "method"})
+
+	gaugeStreamsActive = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "grpc_server_streams_active",
+		Help: "Currently open server streams",
+	}, []string{"service"})
+
+	metricStreamCompression = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "grpc_server_stream_compression_total",
+		Help: "Streams by the compressor chosen for responses (identity when the client supports none of the configured ones)",
+	}, []string{"service", "compressor"})
+)
@@ -0,0 +1,97 @@
+package grpcmw
+
+import (
+	"log"
+
+	"google.golang.org/grpc"
+)
+
+// transport.go sizes messages and HTTP/2 flow control. gRPC's defaults
+// (4 MiB receive limit, unlimited send, 64 KiB stream windows grown by BDP
+// estimation, 32 KiB read/write buffers) suit neither a small control
+// stream nor 20 ms audio frames at high rates, so each is a knob. Zero
+// keeps the gRPC default.
+
+// Transport holds the message-size and flow-control settings.
+type Transport struct {
+	MaxRecvMsgSize int // bytes
+	MaxSendMsgSize int // bytes
+	// Windows below 64 KiB are ignored by gRPC; setting either also turns
+	// off BDP-based window growth.
+	InitialWindowSize     int32 // per stream, bytes
+	InitialConnWindowSize int32 // per connection, bytes
+	WriteBufferSize       int   // bytes
+	ReadBufferSize        int   // bytes
+}
+
+const minWindowSize = 64 << 10
+
+// TransportFromEnv reads the shared GRPC_* transport env vars.
+func TransportFromEnv() Transport {
+	t := Transport{
+		MaxRecvMsgSize:        envInt("GRPC_MAX_RECV_MSG_BYTES", 0),
+		MaxSendMsgSize:        envInt("GRPC_MAX_SEND_MSG_BYTES", 0),
+		InitialWindowSize:     int32(envInt("GRPC_INITIAL_WINDOW_BYTES", 0)),
+		InitialConnWindowSize: int32(envInt("GRPC_INITIAL_CONN_WINDOW_BYTES", 0)),
+		WriteBufferSize:       envInt("GRPC_WRITE_BUFFER_BYTES", 0),
+		ReadBufferSize:        envInt("GRPC_READ_BUFFER_BYTES", 0),
+	}
+	for name, w := range map[string]int32{"GRPC_INITIAL_WINDOW_BYTES": t.InitialWindowSize, "GRPC_INITIAL_CONN_WINDOW_BYTES": t.InitialConnWindowSize} {
+		if w > 0 && w < minWindowSize {
+			log.Printf("[grpc] %s=%d is below the 64KiB minimum and will be ignored", name, w)
+		}
+	}
+	return t
+}
+
+// serverOptions returns the grpc.ServerOptions for the non-zero settings.
+func (t Transport) serverOptions() []grpc.ServerOption {
+	var opts []grpc.ServerOption
+	if t.MaxRecvMsgSize > 0 {
+		opts = append(opts, grpc.MaxRecvMsgSize(t.MaxRecvMsgSize))
+	}
+	if t.MaxSendMsgSize > 0 {
+		opts = append(opts, grpc.MaxSendMsgSize(t.MaxSendMsgSize))
+	}
+	if t.InitialWindowSize > 0 {
+		opts = append(opts, grpc.InitialWindowSize(t.InitialWindowSize))
+	}
+	if t.InitialConnWindowSize > 0 {
+		opts = append(opts, grpc.InitialConnWindowSize(t.InitialConnWindowSize))
+	}
+	if t.WriteBufferSize > 0 {
+		opts = append(opts, grpc.WriteBufferSize(t.WriteBufferSize))
+	}
+	if t.ReadBufferSize > 0 {
+		opts = append(opts, grpc.ReadBufferSize(t.ReadBufferSize))
+	}
+	return opts
+}
+
+// DialOptions returns the matching grpc.DialOptions for clients.
+func (t Transport) DialOptions() []grpc.DialOption {
+	var call []grpc.CallOption
+	if t.MaxRecvMsgSize > 0 {
+		call = append(call, grpc.MaxCallRecvMsgSize(t.MaxRecvMsgSize))
+	}
+	if t.MaxSendMsgSize > 0 {
+		call = append(call, grpc.MaxCallSendMsgSize(t.MaxSendMsgSize))
+	}
+	var opts []grpc.DialOption
+	if len(call) > 0 {
+		opts = append(opts, grpc.WithDefaultCallOptions(call...))
+	}
+	if t.InitialWindowSize > 0 {
+		opts = append(opts, grpc.WithInitialWindowSize(t.InitialWindowSize))
+	}
+	if t.InitialConnWindowSize > 0 {
+		opts = append(opts, grpc.WithInitialConnWindowSize(t.InitialConnWindowSize))
+	}
+	if t.WriteBufferSize > 0 {
+		opts = append(opts, grpc.WithWriteBufferSize(t.WriteBufferSize))
+	}
+	if t.ReadBufferSize > 0 {
+		opts = append(opts, grpc.WithReadBufferSize(t.ReadBufferSize))
+	}
+	return opts
+}
@@ -0,0 +1,106 @@
+package health
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var providerUp = promauto.NewGaugeVec(prometheus.GaugeOpts{
+	Name: "provider_up",
+	Help: "1 if the last readiness check against the upstream provider succeeded",
+}, []string{"provider"})
+
+// Readiness gates a service's /readyz on a provider check that runs at
+// startup and then every Interval. Until the first check completes the
+// service reports not ready.
+type Readiness struct {
+	Provider string
+	Check    func(ctx context.Context) error
+	Interval time.Duration
+	Timeout  time.Duration
+
+	mu      sync.RWMutex
+	ready   bool
+	lastErr string
+	checked time.Time
+}
+
+// NewReadiness returns a gate for provider with sane defaults.
+func NewReadiness(provider string, check func(ctx context.Context) error) *Readiness {
+	return &Readiness{Provider: provider, Check: check, Interval: 30 * time.Second, Timeout: 5 * time.Second}
+}
+
+// Run checks immediately and then periodically until ctx is done.
+func (r *Readiness) Run(ctx context.Context) {
+	r.CheckNow(ctx)
+	t := time.NewTicker(r.Interval)
+	defer t.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+			r.CheckNow(ctx)
+		}
+	}
+}
+
+// CheckNow runs the check once and records the result.
+func (r *Readiness) CheckNow(ctx context.Context) error {
+	cctx, cancel := context.WithTimeout(ctx, r.Timeout)
+	defer cancel()
+	err := r.Check(cctx)
+
+	r.mu.Lock()
+	was := r.ready
+	r.ready = err == nil
+	r.lastErr = ""
+	if err != nil {
+		r.lastErr = err.Error()
+	}
+	r.checked = time.Now()
+	r.mu.Unlock()
+
+	if err == nil {
+		providerUp.WithLabelValues(r.Provider).Set(1)
+		if !was {
+			log.Printf("[ready] provider=%s up", r.Provider)
+		}
+	} else {
+		providerUp.WithLabelValues(r.Provider).Set(0)
+		log.Printf("[ready] provider=%s down: %v", r.Provider, err)
+	}
+	return err
+}
+
+// Ready reports the result of the last check.
+func (r *Readiness) Ready() bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.ready
+}
+
+// ServeHTTP implements /readyz: 200 "ok" when ready, 503 with the last error otherwise.
+// With ?refresh=1 the check runs again before answering.
+func (r *Readiness) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.URL.Query().Get("refresh") == "1" {
+		r.CheckNow(req.Context())
+	}
+	r.mu.RLock()
+	ready, lastErr, checked := r.ready, r.lastErr, r.checked
+	r.mu.RUnlock()
+	if ready {
+		w.Write([]byte("ok\n"))
+		return
+	}
+	if checked.IsZero() {
+		lastErr = "not checked yet"
+	}
+	http.Error(w, r.Provider+": "+lastErr, http.StatusServiceUnavailable)
+}
@@ -0,0 +1,47 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestReadinessFlips(t *testing.T) {
+	var fail error
+	r := NewReadiness("test", func(context.Context) error { return fail })
+
+	code := func() int {
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+		return rec.Code
+	}
+
+	if code() != http.StatusServiceUnavailable {
+		t.Fatal("expected not ready before first check")
+	}
+	r.CheckNow(context.Background())
+	if !r.Ready() || code() != http.StatusOK {
+		t.Fatal("expected ready after passing check")
+	}
+	fail = errors.New("boom")
+	r.CheckNow(context.Background())
+	if r.Ready() || code() != http.StatusServiceUnavailable {
+		t.Fatal("expected not ready after failing check")
+	}
+}
+
+func TestReadinessRefresh(t *testing.T) {
+	calls := 0
+	r := NewReadiness("test", func(context.Context) error { calls++; return nil })
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/readyz?refresh=1", nil))
+	if calls != 1 || rec.Code != http.StatusOK {
+		t.Fatalf("calls=%d code=%d, want the check run before answering", calls, rec.Code)
+	}
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	if calls != 1 {
+		t.Fatalf("plain /readyz ran the check")
+	}
+}
@@ -0,0 +1,132 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"yuzu/agent/internal/config"
+)
+
+// services.go checks the voice pipeline's own services on demand, for
+// deployment pipelines that want everything green before cutting traffic.
+// Each service is probed at its /readyz with ?refresh=1, so the LLM and TTS
+// services re-run their provider check instead of answering from the last
+// periodic one. CheckProviders runs those probes and CheckAll's provider
+// checks concurrently under one deadline; ProvidersHandler serves it as
+// POST /healthz/providers?timeout=5s (default 10s, at most a minute).
+
+const (
+	defaultProvidersTimeout = 10 * time.Second
+	maxProvidersTimeout     = time.Minute
+)
+
+// CheckProviders runs CheckAll and a readiness check of every configured
+// service (HEALTH_ORCH_URL, HEALTH_STT_URL, HEALTH_LLM_URL,
+// HEALTH_TTS_URL; "off" skips the service).
+func CheckProviders(ctx context.Context, cfg config.Config) HealthStatus {
+	services := []struct{ name, url string }{
+		{"orchestrator", cfg.Health.OrchestratorURL},
+		{"stt", cfg.Health.STTURL},
+		{"llm", cfg.Health.LLMURL},
+		{"tts", cfg.Health.TTSURL},
+	}
+	var (
+		wg       sync.WaitGroup
+		all      HealthStatus
+		checks   = make([]CheckResult, len(services))
+		included = make([]bool, len(services))
+	)
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		all = CheckAll(ctx, cfg)
+	}()
+	for i, svc := range services {
+		if svc.url == "" || svc.url == "off" {
+			continue
+		}
+		included[i] = true
+		wg.Add(1)
+		go func(i int, name, url string) {
+			defer wg.Done()
+			checks[i] = CheckService(ctx, name, url)
+		}(i, svc.name, svc.url)
+	}
+	wg.Wait()
+
+	for i, c := range checks {
+		if !included[i] {
+			continue
+		}
+		all.Checks = append(all.Checks, c)
+		all.OK = all.OK && c.OK
+	}
+	all.CheckedAt = time.Now().UTC()
+	return all
+}
+
+// CheckService probes the /readyz of the service at baseURL, asking it to
+// check its provider again first.
+func CheckService(ctx context.Context, name, baseURL string) CheckResult {
+	start := time.Now()
+	result := CheckResult{Name: name}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", strings.TrimRight(baseURL, "/")+"/readyz?refresh=1", nil)
+	if err != nil {
+		result.Error = fmt.Sprintf("request build failed: %v", err)
+		result.Latency = time.Since(start)
+		return result
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		result.Error = fmt.Sprintf("request failed: %v", err)
+		result.Latency = time.Since(start)
+		return result
+	}
+	defer resp.Body.Close()
+
+	result.Latency = time.Since(start)
+
+	if resp.StatusCode != 200 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 256))
+		result.Error = fmt.Sprintf("not ready (%d): %s", resp.StatusCode, strings.TrimSpace(string(body)))
+		return result
+	}
+
+	result.OK = true
+	return result
+}
+
+// ProvidersHandler runs CheckProviders on POST, answering 200 when every
+// check passed and 503 otherwise, with the results either way.
+func ProvidersHandler(cfg config.Config) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		timeout := defaultProvidersTimeout
+		if v := r.URL.Query().Get("timeout"); v != "" {
+			d, err := time.ParseDuration(v)
+			if err != nil || d <= 0 || d > maxProvidersTimeout {
+				http.Error(w, fmt.Sprintf("timeout must be a duration within (0, %s]", maxProvidersTimeout), http.StatusBadRequest)
+				return
+			}
+			timeout = d
+		}
+		ctx, cancel := context.WithTimeout(r.Context(), timeout)
+		defer cancel()
+		status := CheckProviders(ctx, cfg)
+		w.Header().Set("Content-Type", "application/json")
+		if !status.OK {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+		json.NewEncoder(w).Encode(status)
+	}
+}
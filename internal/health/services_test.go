@@ -0,0 +1,76 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"yuzu/agent/internal/config"
+)
+
+func TestCheckProvidersProbesServices(t *testing.T) {
+	var refreshed bool
+	up := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		refreshed = r.URL.Path == "/readyz" && r.URL.Query().Get("refresh") == "1"
+		w.Write([]byte("ok\n"))
+	}))
+	defer up.Close()
+	down := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "elevenlabs: 401", http.StatusServiceUnavailable)
+	}))
+	defer down.Close()
+
+	// No provider keys, so daily and elevenlabs fail without a request
+	var cfg config.Config
+	cfg.Health.OrchestratorURL = up.URL
+	cfg.Health.TTSURL = down.URL
+	cfg.Health.STTURL = "off"
+
+	st := CheckProviders(context.Background(), cfg)
+	got := map[string]CheckResult{}
+	for _, c := range st.Checks {
+		got[c.Name] = c
+	}
+	if st.OK || len(st.Checks) != 4 {
+		t.Fatalf("status = %+v, want 4 checks and not ok", st)
+	}
+	if !got["orchestrator"].OK || !refreshed {
+		t.Errorf("orchestrator = %+v refreshed=%v, want ok after ?refresh=1", got["orchestrator"], refreshed)
+	}
+	if c := got["tts"]; c.OK || c.Error != "not ready (503): elevenlabs: 401" {
+		t.Errorf("tts = %+v", c)
+	}
+	if _, ok := got["stt"]; ok {
+		t.Error("a service set to off was checked")
+	}
+}
+
+func TestProvidersHandler(t *testing.T) {
+	var cfg config.Config
+	cfg.Health.OrchestratorURL, cfg.Health.STTURL, cfg.Health.LLMURL, cfg.Health.TTSURL = "off", "off", "off", "off"
+	h := ProvidersHandler(cfg)
+
+	for _, c := range []struct {
+		method, query string
+		want          int
+	}{
+		{http.MethodGet, "", http.StatusMethodNotAllowed},
+		{http.MethodPost, "?timeout=soon", http.StatusBadRequest},
+		{http.MethodPost, "?timeout=2m", http.StatusBadRequest},
+		{http.MethodPost, "?timeout=2s", http.StatusServiceUnavailable},
+	} {
+		rec := httptest.NewRecorder()
+		h(rec, httptest.NewRequest(c.method, "/healthz/providers"+c.query, nil))
+		if rec.Code != c.want {
+			t.Errorf("%s %s = %d, want %d", c.method, c.query, rec.Code, c.want)
+		}
+		if rec.Code == http.StatusServiceUnavailable {
+			var st HealthStatus
+			if err := json.NewDecoder(rec.Body).Decode(&st); err != nil || len(st.Checks) != 2 {
+				t.Errorf("body = %+v (%v), want the provider checks", st, err)
+			}
+		}
+	}
+}
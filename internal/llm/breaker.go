@@ -0,0 +1,198 @@
+package llm
+
+import (
+    "log"
+    "os"
+    "strconv"
+    "sync"
+    "time"
+
+    "github.com/prometheus/client_golang/prometheus"
+    "github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// breaker.go keeps one circuit breaker per Azure deployment. Outcomes are
+// kept for LLM_BREAKER_WINDOW_S (default 60); once at least
+// LLM_BREAKER_MIN_REQUESTS (default 5) of them are in and
+// LLM_BREAKER_FAILURE_RATE (default 0.5) or more failed, the breaker opens
+// and requests fail fast with errdefs.ErrCircuitOpen (in-band code
+// "circuit_open") for LLM_BREAKER_OPEN_S (default 30). Then one request is
+// let through as a probe: success closes the breaker, failure re-opens it.
+// Only provider health counts as failure: transport errors, 429 and 5xx,
+// and streams that break mid-response. Client errors and cancelled
+// requests don't.
+
+type breakerState int
+
+const (
+    breakerClosed breakerState = iota
+    breakerHalfOpen
+    breakerOpen
+)
+
+func (s breakerState) String() string {
+    switch s {
+    case breakerHalfOpen: return "half_open"
+    case breakerOpen: return "open"
+    }
+    return "closed"
+}
+
+// callResult is how a call that the breaker let through ended.
+type callResult int
+
+const (
+    callSucceeded callResult = iota
+    callFailed
+    callIgnored // cancelled or a client error; says nothing about the endpoint
+)
+
+var (
+    metricCircuitOpens = promauto.NewCounterVec(prometheus.CounterOpts{
+        Name: "llm_circuit_open_total",
+        Help: "Circuit breaker open events, by deployment",
+    }, []string{"deployment"})
+
+    metricCircuitRejected = promauto.NewCounterVec(prometheus.CounterOpts{
+        Name: "llm_circuit_rejected_total",
+        Help: "Requests failed fast while the breaker was open, by deployment",
+    }, []string{"deployment"})
+
+    metricCircuitState = promauto.NewGaugeVec(prometheus.GaugeOpts{
+        Name: "llm_circuit_state",
+        Help: "Circuit breaker state by deployment: 0 closed, 1 half-open, 2 open",
+    }, []string{"deployment"})
+)
+
+type breakerConfig struct {
+    window      time.Duration
+    minRequests int
+    failureRate float64
+    openFor     time.Duration
+}
+
+func breakerConfigFromEnv() breakerConfig {
+    c := breakerConfig{window: 60 * time.Second, minRequests: 5, failureRate: 0.5, openFor: 30 * time.Second}
+    if n, err := strconv.Atoi(os.Getenv("LLM_BREAKER_WINDOW_S")); err == nil && n > 0 { c.window = time.Duration(n) * time.Second }
+    if n, err := strconv.Atoi(os.Getenv("LLM_BREAKER_MIN_REQUESTS")); err == nil && n > 0 { c.minRequests = n }
+    if f, err := strconv.ParseFloat(os.Getenv("LLM_BREAKER_FAILURE_RATE"), 64); err == nil && f > 0 && f <= 1 { c.failureRate = f }
+    if n, err := strconv.Atoi(os.Getenv("LLM_BREAKER_OPEN_S")); err == nil && n > 0 { c.openFor = time.Duration(n) * time.Second }
+    return c
+}
+
+type outcome struct {
+    at     time.Time
+    failed bool
+}
+
+// breaker guards one deployment.
+type breaker struct {
+    name string
+    cfg  breakerConfig
+    now  func() time.Time
+
+    mu       sync.Mutex
+    state    breakerState
+    openedAt time.Time
+    probing  bool // half-open probe in flight
+    outcomes []outcome
+}
+
+// allow reports whether a call may go to the provider. Every allowed call
+// must be followed by done.
+func (b *breaker) allow() bool {
+    if b == nil { return true }
+    b.mu.Lock()
+    defer b.mu.Unlock()
+    switch b.state {
+    case breakerOpen:
+        if b.now().Sub(b.openedAt) < b.cfg.openFor {
+            metricCircuitRejected.WithLabelValues(b.name).Inc()
+            return false
+        }
+        b.setState(breakerHalfOpen)
+        fallthrough
+    case breakerHalfOpen:
+        if b.probing {
+            metricCircuitRejected.WithLabelValues(b.name).Inc()
+            return false
+        }
+        b.probing = true
+    }
+    return true
+}
+
+// done records how an allowed call ended.
+func (b *breaker) done(r callResult) {
+    if b == nil { return }
+    b.mu.Lock()
+    defer b.mu.Unlock()
+    now := b.now()
+    if b.state == breakerHalfOpen {
+        b.probing = false
+        switch r {
+        case callSucceeded:
+            b.outcomes = nil
+            b.setState(breakerClosed)
+            log.Printf("[llm] circuit closed deployment=%s", b.name)
+        case callFailed:
+            b.trip(now)
+        }
+        return
+    }
+    if r == callIgnored { return }
+    b.outcomes = append(b.outcomes, outcome{at: now, failed: r == callFailed})
+    cutoff := now.Add(-b.cfg.window)
+    i := 0
+    for i < len(b.outcomes) && !b.outcomes[i].at.After(cutoff) { i++ }
+    b.outcomes = b.outcomes[i:]
+    if b.state != breakerClosed || len(b.outcomes) < b.cfg.minRequests { return }
+    failed := 0
+    for _, o := range b.outcomes {
+        if o.failed { failed++ }
+    }
+    if float64(failed) >= b.cfg.failureRate*float64(len(b.outcomes)) {
+        log.Printf("[llm] circuit open deployment=%s failures=%d/%d for %s", b.name, failed, len(b.outcomes), b.cfg.openFor)
+        b.trip(now)
+    }
+}
+
+// trip opens the breaker. Callers hold b.mu.
+func (b *breaker) trip(now time.Time) {
+    b.openedAt = now
+    b.outcomes = nil
+    b.setState(breakerOpen)
+    metricCircuitOpens.WithLabelValues(b.name).Inc()
+}
+
+// setState changes state and exports it. Callers hold b.mu.
+func (b *breaker) setState(s breakerState) {
+    b.state = s
+    metricCircuitState.WithLabelValues(b.name).Set(float64(s))
+}
+
+// breakers hands out one breaker per deployment. A nil *breakers allows
+// everything, so hand-built Servers in tests need none.
+type breakers struct {
+    cfg breakerConfig
+    now func() time.Time
+
+    mu sync.Mutex
+    m  map[string]*breaker
+}
+
+func newBreakers(cfg breakerConfig) *breakers {
+    return &breakers{cfg: cfg, now: time.Now, m: map[string]*breaker{}}
+}
+
+func (bs *breakers) get(deployment string) *breaker {
+    if bs == nil { return nil }
+    bs.mu.Lock()
+    defer bs.mu.Unlock()
+    b := bs.m[deployment]
+    if b == nil {
+        b = &breaker{name: deployment, cfg: bs.cfg, now: bs.now}
+        bs.m[deployment] = b
+    }
+    return b
+}
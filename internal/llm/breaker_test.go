@@ -0,0 +1,64 @@
+package llm
+
+import (
+    "testing"
+    "time"
+)
+
+func TestBreakerOpensProbesAndCloses(t *testing.T) {
+    now := time.Unix(1700000000, 0)
+    bs := &breakers{cfg: breakerConfig{window: time.Minute, minRequests: 4, failureRate: 0.5, openFor: 30 * time.Second}, now: func() time.Time { return now }, m: map[string]*breaker{}}
+    b := bs.get("gpt-4o")
+
+    // Ignored calls never count; one failure in four stays closed
+    for _, r := range []callResult{callIgnored, callIgnored, callSucceeded, callFailed, callSucceeded, callSucceeded} {
+        if !b.allow() {
+            t.Fatal("closed breaker refused a call")
+        }
+        b.done(r)
+    }
+    if b.state != breakerClosed {
+        t.Fatalf("state = %s after 1/4 failures", b.state)
+    }
+
+    // Failures older than the window fall out; two fresh ones make 2/4
+    now = now.Add(2 * time.Minute)
+    for _, r := range []callResult{callSucceeded, callFailed, callSucceeded, callFailed} {
+        b.allow()
+        b.done(r)
+    }
+    if b.state != breakerOpen {
+        t.Fatalf("state = %s after 2/4 failures", b.state)
+    }
+    if b.allow() {
+        t.Fatal("open breaker allowed a call")
+    }
+
+    // After openFor one probe goes through; a failed probe re-opens
+    now = now.Add(30 * time.Second)
+    if !b.allow() || b.allow() {
+        t.Fatal("half-open breaker should allow exactly one probe")
+    }
+    b.done(callFailed)
+    if b.state != breakerOpen || b.allow() {
+        t.Fatalf("state = %s after failed probe", b.state)
+    }
+
+    now = now.Add(30 * time.Second)
+    if !b.allow() {
+        t.Fatal("probe refused")
+    }
+    b.done(callSucceeded)
+    if b.state != breakerClosed || !b.allow() {
+        t.Fatalf("state = %s after good probe", b.state)
+    }
+
+    // Other deployments have their own breaker; a nil set allows everything
+    if bs.get("gpt-4o-mini") == b || !bs.get("gpt-4o-mini").allow() {
+        t.Fatal("deployments share a breaker")
+    }
+    var none *breakers
+    if !none.get("x").allow() {
+        t.Fatal("nil breakers refused a call")
+    }
+}
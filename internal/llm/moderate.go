@@ -0,0 +1,164 @@
+package llm
+
+import (
+    "bytes"
+    "context"
+    "encoding/json"
+    "fmt"
+    "io"
+    "net/http"
+    "os"
+    "sort"
+    "strings"
+
+    "github.com/prometheus/client_golang/prometheus"
+    "github.com/prometheus/client_golang/prometheus/promauto"
+
+    "yuzu/agent/internal/errdefs"
+    "yuzu/agent/internal/secrets"
+    pb "yuzu/agent/internal/llm/pb"
+)
+
+// moderate.go classifies candidate text against the interview's conduct
+// policy with a small non-streaming completion. The deployment comes from
+// the request, then LLM_MODERATION_DEPLOYMENT, then AZURE_OPENAI_DEPLOYMENT.
+// When Azure's own content filter rejects the text, that counts as flagged
+// with the filter's categories.
+
+var moderationCategories = []string{"harassment", "hate", "sexual", "violence", "self_harm"}
+
+const moderationPrompt = `You moderate what a job candidate says to an AI interviewer. Decide whether the text is abusive towards the interviewer or others: harassment (insults, threats or demeaning language aimed at someone), hate (attacks on protected groups), sexual (sexual content or advances), violence (threats or incitement), self_harm (intent to harm oneself). Frustration, mild profanity that targets no one, and discussing these topics in a work context are allowed. Reply with JSON only: {"flagged": true|false, "categories": [...]}, using only these category names.`
+
+var metricModeration = promauto.NewCounterVec(prometheus.CounterOpts{
+    Name: "llm_moderation_total",
+    Help: "Moderation checks by result (clean, flagged, content_filter, error)",
+}, []string{"result"})
+
+// Moderate reports whether req.Text breaks the conduct policy.
+func (s *Server) Moderate(ctx context.Context, req *pb.ModerateRequest) (*pb.ModerateResponse, error) {
+    endpoint := os.Getenv("AZURE_OPENAI_ENDPOINT")
+    apiKey := secrets.Get("AZURE_OPENAI_API_KEY")
+    if endpoint == "" || apiKey == "" {
+        metricModeration.WithLabelValues("error").Inc()
+        return nil, &errdefs.ConfigError{Key: "AZURE_OPENAI_ENDPOINT/AZURE_OPENAI_API_KEY", Msg: "missing"}
+    }
+    if strings.TrimSpace(req.GetText()) == "" {
+        metricModeration.WithLabelValues("clean").Inc()
+        return &pb.ModerateResponse{}, nil
+    }
+    deployment := firstNonEmpty(req.GetDeployment(), os.Getenv("LLM_MODERATION_DEPLOYMENT"), os.Getenv("AZURE_OPENAI_DEPLOYMENT"))
+    if deployment == "" {
+        metricModeration.WithLabelValues("error").Inc()
+        return nil, &errdefs.ConfigError{Key: "LLM_MODERATION_DEPLOYMENT", Msg: "no deployment for moderation"}
+    }
+    apiVersion := firstNonEmpty(req.GetApiVersion(), os.Getenv("AZURE_OPENAI_API_VERSION"), "2024-02-15-preview")
+
+    body, _ := json.Marshal(map[string]any{
+        "messages": []map[string]any{
+            {"role": "system", "content": moderationPrompt},
+            {"role": "user", "content": req.GetText()},
+        },
+        "temperature":     0,
+        "max_tokens":      60,
+        "response_format": map[string]any{"type": "json_object"},
+    })
+    url := fmt.Sprintf("%s/openai/deployments/%s/chat/completions?api-version=%s", strings.TrimRight(endpoint, "/"), deployment, apiVersion)
+    hreq, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+    if err != nil { return nil, err }
+    hreq.Header.Set("api-key", apiKey)
+    hreq.Header.Set("Content-Type", "application/json")
+    brk := s.breakers.get(deployment)
+    if !brk.allow() {
+        metricModeration.WithLabelValues("error").Inc()
+        return nil, errdefs.CircuitOpen("azure")
+    }
+    resp, err := s.httpc.Do(hreq)
+    if err != nil {
+        brk.done(callFailed)
+        metricModeration.WithLabelValues("error").Inc()
+        return nil, errdefs.ProviderTransport("azure", err)
+    }
+    defer resp.Body.Close()
+    b, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+    switch {
+    case resp.StatusCode/100 == 2: brk.done(callSucceeded)
+    case resp.StatusCode == 429 || resp.StatusCode >= 500: brk.done(callFailed)
+    default: brk.done(callIgnored)
+    }
+    if resp.StatusCode/100 != 2 {
+        if cats := contentFilterCategories(b); resp.StatusCode == http.StatusBadRequest && cats != nil {
+            metricModeration.WithLabelValues("content_filter").Inc()
+            return &pb.ModerateResponse{Flagged: true, Categories: cats}, nil
+        }
+        metricModeration.WithLabelValues("error").Inc()
+        return nil, errdefs.ProviderStatus("azure", resp.StatusCode, string(b))
+    }
+    out, err := parseModeration(b)
+    if err != nil {
+        metricModeration.WithLabelValues("error").Inc()
+        return nil, errdefs.ProviderStatus("azure", http.StatusBadGateway, err.Error())
+    }
+    result := "clean"
+    if out.Flagged { result = "flagged" }
+    metricModeration.WithLabelValues(result).Inc()
+    return out, nil
+}
+
+// parseModeration reads the classifier's JSON verdict from a completion.
+func parseModeration(completion []byte) (*pb.ModerateResponse, error) {
+    var c struct {
+        Choices []struct {
+            Message struct{ Content string } `json:"message"`
+        } `json:"choices"`
+    }
+    if err := json.Unmarshal(completion, &c); err != nil || len(c.Choices) == 0 {
+        return nil, fmt.Errorf("moderation: unexpected completion")
+    }
+    var verdict struct {
+        Flagged    bool     `json:"flagged"`
+        Categories []string `json:"categories"`
+    }
+    content := strings.TrimSpace(c.Choices[0].Message.Content)
+    content = strings.TrimSuffix(strings.TrimPrefix(strings.TrimPrefix(content, "```json"), "```"), "```")
+    if err := json.Unmarshal([]byte(strings.TrimSpace(content)), &verdict); err != nil {
+        return nil, fmt.Errorf("moderation: verdict is not JSON: %q", content)
+    }
+    out := &pb.ModerateResponse{Flagged: verdict.Flagged}
+    if !verdict.Flagged { return out, nil }
+    for _, cat := range verdict.Categories {
+        cat = strings.ReplaceAll(strings.ToLower(strings.TrimSpace(cat)), "-", "_")
+        for _, known := range moderationCategories {
+            if cat == known { out.Categories = append(out.Categories, cat) }
+        }
+    }
+    return out, nil
+}
+
+// contentFilterCategories returns the categories Azure's content filter
+// tripped on, or nil when the error body is not a content filter rejection.
+func contentFilterCategories(body []byte) []string {
+    var e struct {
+        Error struct {
+            Code       string `json:"code"`
+            InnerError struct {
+                Result map[string]struct {
+                    Filtered bool `json:"filtered"`
+                } `json:"content_filter_result"`
+            } `json:"innererror"`
+        } `json:"error"`
+    }
+    if json.Unmarshal(body, &e) != nil || e.Error.Code != "content_filter" { return nil }
+    cats := []string{}
+    for name, r := range e.Error.InnerError.Result {
+        if r.Filtered { cats = append(cats, name) }
+    }
+    sort.Strings(cats)
+    return cats
+}
+
+func firstNonEmpty(vals ...string) string {
+    for _, v := range vals {
+        if v != "" { return v }
+    }
+    return ""
+}
@@ -0,0 +1,59 @@
+package llm
+
+import (
+    "context"
+    "encoding/json"
+    "fmt"
+    "net/http"
+    "net/http/httptest"
+    "strings"
+    "testing"
+
+    pb "yuzu/agent/internal/llm/pb"
+)
+
+func TestModerate(t *testing.T) {
+    ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        if !strings.Contains(r.URL.Path, "/deployments/mod-mini/") || r.Header.Get("api-key") != "k" {
+            http.Error(w, "bad request", http.StatusUnauthorized)
+            return
+        }
+        var body struct {
+            Messages []struct{ Content string } `json:"messages"`
+        }
+        json.NewDecoder(r.Body).Decode(&body)
+        switch text := body.Messages[1].Content; {
+        case strings.Contains(text, "filtered"):
+            w.WriteHeader(http.StatusBadRequest)
+            w.Write([]byte(`{"error":{"code":"content_filter","innererror":{"content_filter_result":{"hate":{"filtered":true},"violence":{"filtered":true},"sexual":{"filtered":false}}}}}`))
+        case strings.Contains(text, "idiot"):
+            w.Write([]byte(`{"choices":[{"message":{"content":"{\"flagged\": true, \"categories\": [\"Harassment\", \"made_up\"]}"}}]}`))
+        default:
+            w.Write([]byte(`{"choices":[{"message":{"content":"{\"flagged\": false, \"categories\": []}"}}]}`))
+        }
+    }))
+    defer ts.Close()
+    t.Setenv("AZURE_OPENAI_ENDPOINT", ts.URL)
+    t.Setenv("AZURE_OPENAI_API_KEY", "k")
+    t.Setenv("LLM_MODERATION_DEPLOYMENT", "mod-mini")
+    s := NewServer()
+
+    for text, want := range map[string]string{
+        "I led the payments migration":  "false []",
+        "you are an idiot":              "true [harassment]",
+        "something the filter filtered": "true [hate violence]",
+    } {
+        resp, err := s.Moderate(context.Background(), &pb.ModerateRequest{Text: text})
+        if err != nil {
+            t.Fatalf("%q: %v", text, err)
+        }
+        if got := fmt.Sprint(resp.GetFlagged(), resp.GetCategories()); got != want {
+            t.Errorf("%q = %s, want %s", text, got, want)
+        }
+    }
+
+    t.Setenv("AZURE_OPENAI_API_KEY", "")
+    if _, err := s.Moderate(context.Background(), &pb.ModerateRequest{Text: "hi"}); err == nil {
+        t.Error("moderation without credentials succeeded")
+    }
+}
@@ -84,7 +84,8 @@ type StartRequest struct {
 	Messages      []*ChatMessage         `protobuf:"bytes,5,rep,name=messages,proto3" json:"messages,omitempty"`
 	Stream        bool                   `protobuf:"varint,6,opt,name=stream,proto3" json:"stream,omitempty"`                        // should be true for streaming
 	MaxTokens     uint32                 `protobuf:"varint,7,opt,name=max_tokens,json=maxTokens,proto3" json:"max_tokens,omitempty"` // optional
-	Temperature   float64                `protobuf:"fixed64,8,opt,name=temperature,proto3" json:"temperature,omitempty"`             // optional
+	Temperature   *float64               `protobuf:"fixed64,8,opt,name=temperature,proto3,oneof" json:"temperature,omitempty"`       // unset leaves the provider default
+	NoLog         bool                   `protobuf:"varint,9,opt,name=no_log,json=noLog,proto3" json:"no_log,omitempty"`             // consent: never sample this request (see internal/llm/samplelog.go)
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -169,12 +170,19 @@ func (x *StartRequest) GetMaxTokens() uint32 {
 }
 
 func (x *StartRequest) GetTemperature() float64 {
-	if x != nil {
-		return x.Temperature
+	if x != nil && x.Temperature != nil {
+		return *x.Temperature
 	}
 	return 0
 }
 
+func (x *StartRequest) GetNoLog() bool {
+	if x != nil {
+		return x.NoLog
+	}
+	return false
+}
+
 type Cancel struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	RequestId     string                 `protobuf:"bytes,1,opt,name=request_id,json=requestId,proto3" json:"request_id,omitempty"`
@@ -302,9 +310,16 @@ func (*ClientMessage_Start) isClientMessage_Msg() {}
 func (*ClientMessage_Cancel) isClientMessage_Msg() {}
 
 // Server→Client
+// Connected is sent once the provider has accepted the request, describing
+// what serves it. Requests that fail before that get only an Error.
 type Connected struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	SessionId     string                 `protobuf:"bytes,1,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
+	Provider      string                 `protobuf:"bytes,2,opt,name=provider,proto3" json:"provider,omitempty"`                     // e.g., azure_openai
+	Model         string                 `protobuf:"bytes,3,opt,name=model,proto3" json:"model,omitempty"`                           // deployment name
+	Language      string                 `protobuf:"bytes,4,opt,name=language,proto3" json:"language,omitempty"`                     // empty: chat models aren't configured per language
+	ConnectMs     uint32                 `protobuf:"varint,5,opt,name=connect_ms,json=connectMs,proto3" json:"connect_ms,omitempty"` // time to the provider's response headers
+	RequestId     string                 `protobuf:"bytes,6,opt,name=request_id,json=requestId,proto3" json:"request_id,omitempty"`  // provider's request ID, for support tickets
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -346,6 +361,41 @@ func (x *Connected) GetSessionId() string {
 	return ""
 }
 
+func (x *Connected) GetProvider() string {
+	if x != nil {
+		return x.Provider
+	}
+	return ""
+}
+
+func (x *Connected) GetModel() string {
+	if x != nil {
+		return x.Model
+	}
+	return ""
+}
+
+func (x *Connected) GetLanguage() string {
+	if x != nil {
+		return x.Language
+	}
+	return ""
+}
+
+func (x *Connected) GetConnectMs() uint32 {
+	if x != nil {
+		return x.ConnectMs
+	}
+	return 0
+}
+
+func (x *Connected) GetRequestId() string {
+	if x != nil {
+		return x.RequestId
+	}
+	return ""
+}
+
 type Token struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Text          string                 `protobuf:"bytes,1,opt,name=text,proto3" json:"text,omitempty"`
@@ -676,6 +726,130 @@ func (*ServerMessage_Usage) isServerMessage_Msg() {}
 
 func (*ServerMessage_Error) isServerMessage_Msg() {}
 
+// Moderation check of candidate text before it reaches the model.
+// deployment and api_version fall back to the service's settings.
+type ModerateRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	SessionId     string                 `protobuf:"bytes,1,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
+	Text          string                 `protobuf:"bytes,2,opt,name=text,proto3" json:"text,omitempty"`
+	Deployment    string                 `protobuf:"bytes,3,opt,name=deployment,proto3" json:"deployment,omitempty"`
+	ApiVersion    string                 `protobuf:"bytes,4,opt,name=api_version,json=apiVersion,proto3" json:"api_version,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ModerateRequest) Reset() {
+	*x = ModerateRequest{}
+	mi := &file_llm_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ModerateRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ModerateRequest) ProtoMessage() {}
+
+func (x *ModerateRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_llm_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ModerateRequest.ProtoReflect.Descriptor instead.
+func (*ModerateRequest) Descriptor() ([]byte, []int) {
+	return file_llm_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *ModerateRequest) GetSessionId() string {
+	if x != nil {
+		return x.SessionId
+	}
+	return ""
+}
+
+func (x *ModerateRequest) GetText() string {
+	if x != nil {
+		return x.Text
+	}
+	return ""
+}
+
+func (x *ModerateRequest) GetDeployment() string {
+	if x != nil {
+		return x.Deployment
+	}
+	return ""
+}
+
+func (x *ModerateRequest) GetApiVersion() string {
+	if x != nil {
+		return x.ApiVersion
+	}
+	return ""
+}
+
+// flagged is set when the text breaks policy; categories name the policies
+// (e.g. "harassment", "hate", "violence", "sexual", "self_harm").
+type ModerateResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Flagged       bool                   `protobuf:"varint,1,opt,name=flagged,proto3" json:"flagged,omitempty"`
+	Categories    []string               `protobuf:"bytes,2,rep,name=categories,proto3" json:"categories,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ModerateResponse) Reset() {
+	*x = ModerateResponse{}
+	mi := &file_llm_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ModerateResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ModerateResponse) ProtoMessage() {}
+
+func (x *ModerateResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_llm_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ModerateResponse.ProtoReflect.Descriptor instead.
+func (*ModerateResponse) Descriptor() ([]byte, []int) {
+	return file_llm_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *ModerateResponse) GetFlagged() bool {
+	if x != nil {
+		return x.Flagged
+	}
+	return false
+}
+
+func (x *ModerateResponse) GetCategories() []string {
+	if x != nil {
+		return x.Categories
+	}
+	return nil
+}
+
 var File_llm_proto protoreflect.FileDescriptor
 
 const file_llm_proto_rawDesc = "" +
@@ -683,7 +857,7 @@ const file_llm_proto_rawDesc = "" +
 	"\tllm.proto\x12\x06llm.v1\";\n" +
 	"\vChatMessage\x12\x12\n" +
 	"\x04role\x18\x01 \x01(\tR\x04role\x12\x18\n" +
-	"\acontent\x18\x02 \x01(\tR\acontent\"\x97\x02\n" +
+	"\acontent\x18\x02 \x01(\tR\acontent\"\xc3\x02\n" +
 	"\fStartRequest\x12\x1d\n" +
 	"\n" +
 	"session_id\x18\x01 \x01(\tR\tsessionId\x12\x1d\n" +
@@ -697,18 +871,27 @@ const file_llm_proto_rawDesc = "" +
 	"\bmessages\x18\x05 \x03(\v2\x13.llm.v1.ChatMessageR\bmessages\x12\x16\n" +
 	"\x06stream\x18\x06 \x01(\bR\x06stream\x12\x1d\n" +
 	"\n" +
-	"max_tokens\x18\a \x01(\rR\tmaxTokens\x12 \n" +
-	"\vtemperature\x18\b \x01(\x01R\vtemperature\"'\n" +
+	"max_tokens\x18\a \x01(\rR\tmaxTokens\x12%\n" +
+	"\vtemperature\x18\b \x01(\x01H\x00R\vtemperature\x88\x01\x01\x12\x15\n" +
+	"\x06no_log\x18\t \x01(\bR\x05noLogB\x0e\n" +
+	"\f_temperature\"'\n" +
 	"\x06Cancel\x12\x1d\n" +
 	"\n" +
 	"request_id\x18\x01 \x01(\tR\trequestId\"n\n" +
 	"\rClientMessage\x12,\n" +
 	"\x05start\x18\x01 \x01(\v2\x14.llm.v1.StartRequestH\x00R\x05start\x12(\n" +
 	"\x06cancel\x18\x02 \x01(\v2\x0e.llm.v1.CancelH\x00R\x06cancelB\x05\n" +
-	"\x03msg\"*\n" +
+	"\x03msg\"\xb6\x01\n" +
 	"\tConnected\x12\x1d\n" +
 	"\n" +
-	"session_id\x18\x01 \x01(\tR\tsessionId\"\x1b\n" +
+	"session_id\x18\x01 \x01(\tR\tsessionId\x12\x1a\n" +
+	"\bprovider\x18\x02 \x01(\tR\bprovider\x12\x14\n" +
+	"\x05model\x18\x03 \x01(\tR\x05model\x12\x1a\n" +
+	"\blanguage\x18\x04 \x01(\tR\blanguage\x12\x1d\n" +
+	"\n" +
+	"connect_ms\x18\x05 \x01(\rR\tconnectMs\x12\x1d\n" +
+	"\n" +
+	"request_id\x18\x06 \x01(\tR\trequestId\"\x1b\n" +
 	"\x05Token\x12\x12\n" +
 	"\x04text\x18\x01 \x01(\tR\x04text\"\x1e\n" +
 	"\bSentence\x12\x12\n" +
@@ -726,9 +909,24 @@ const file_llm_proto_rawDesc = "" +
 	"\bsentence\x18\x03 \x01(\v2\x10.llm.v1.SentenceH\x00R\bsentence\x12%\n" +
 	"\x05usage\x18\x04 \x01(\v2\r.llm.v1.UsageH\x00R\x05usage\x12%\n" +
 	"\x05error\x18\x05 \x01(\v2\r.llm.v1.ErrorH\x00R\x05errorB\x05\n" +
-	"\x03msg2B\n" +
+	"\x03msg\"\x85\x01\n" +
+	"\x0fModerateRequest\x12\x1d\n" +
+	"\n" +
+	"session_id\x18\x01 \x01(\tR\tsessionId\x12\x12\n" +
+	"\x04text\x18\x02 \x01(\tR\x04text\x12\x1e\n" +
+	"\n" +
+	"deployment\x18\x03 \x01(\tR\n" +
+	"deployment\x12\x1f\n" +
+	"\vapi_version\x18\x04 \x01(\tR\n" +
+	"apiVersion\"L\n" +
+	"\x10ModerateResponse\x12\x18\n" +
+	"\aflagged\x18\x01 \x01(\bR\aflagged\x12\x1e\n" +
+	"\n" +
+	"categories\x18\x02 \x03(\tR\n" +
+	"categories2\x81\x01\n" +
 	"\x03LLM\x12;\n" +
-	"\aSession\x12\x15.llm.v1.ClientMessage\x1a\x15.llm.v1.ServerMessage(\x010\x01B\"Z yuzu/agent/internal/llm/pb;llmpbb\x06proto3"
+	"\aSession\x12\x15.llm.v1.ClientMessage\x1a\x15.llm.v1.ServerMessage(\x010\x01\x12=\n" +
+	"\bModerate\x12\x17.llm.v1.ModerateRequest\x1a\x18.llm.v1.ModerateResponseB\"Z yuzu/agent/internal/llm/pb;llmpbb\x06proto3"
 
 var (
 	file_llm_proto_rawDescOnce sync.Once
@@ -742,35 +940,39 @@ func file_llm_proto_rawDescGZIP() []byte {
 	return file_llm_proto_rawDescData
 }
 
-var file_llm_proto_msgTypes = make([]protoimpl.MessageInfo, 10)
+var file_llm_proto_msgTypes = make([]protoimpl.MessageInfo, 12)
 var file_llm_proto_goTypes = []any{
-	(*ChatMessage)(nil),   // 0: llm.v1.ChatMessage
-	(*StartRequest)(nil),  // 1: llm.v1.StartRequest
-	(*Cancel)(nil),        // 2: llm.v1.Cancel
-	(*ClientMessage)(nil), // 3: llm.v1.ClientMessage
-	(*Connected)(nil),     // 4: llm.v1.Connected
-	(*Token)(nil),         // 5: llm.v1.Token
-	(*Sentence)(nil),      // 6: llm.v1.Sentence
-	(*Usage)(nil),         // 7: llm.v1.Usage
-	(*Error)(nil),         // 8: llm.v1.Error
-	(*ServerMessage)(nil), // 9: llm.v1.ServerMessage
+	(*ChatMessage)(nil),      // 0: llm.v1.ChatMessage
+	(*StartRequest)(nil),     // 1: llm.v1.StartRequest
+	(*Cancel)(nil),           // 2: llm.v1.Cancel
+	(*ClientMessage)(nil),    // 3: llm.v1.ClientMessage
+	(*Connected)(nil),        // 4: llm.v1.Connected
+	(*Token)(nil),            // 5: llm.v1.Token
+	(*Sentence)(nil),         // 6: llm.v1.Sentence
+	(*Usage)(nil),            // 7: llm.v1.Usage
+	(*Error)(nil),            // 8: llm.v1.Error
+	(*ServerMessage)(nil),    // 9: llm.v1.ServerMessage
+	(*ModerateRequest)(nil),  // 10: llm.v1.ModerateRequest
+	(*ModerateResponse)(nil), // 11: llm.v1.ModerateResponse
 }
 var file_llm_proto_depIdxs = []int32{
-	0, // 0: llm.v1.StartRequest.messages:type_name -> llm.v1.ChatMessage
-	1, // 1: llm.v1.ClientMessage.start:type_name -> llm.v1.StartRequest
-	2, // 2: llm.v1.ClientMessage.cancel:type_name -> llm.v1.Cancel
-	4, // 3: llm.v1.ServerMessage.connected:type_name -> llm.v1.Connected
-	5, // 4: llm.v1.ServerMessage.token:type_name -> llm.v1.Token
-	6, // 5: llm.v1.ServerMessage.sentence:type_name -> llm.v1.Sentence
-	7, // 6: llm.v1.ServerMessage.usage:type_name -> llm.v1.Usage
-	8, // 7: llm.v1.ServerMessage.error:type_name -> llm.v1.Error
-	3, // 8: llm.v1.LLM.Session:input_type -> llm.v1.ClientMessage
-	9, // 9: llm.v1.LLM.Session:output_type -> llm.v1.ServerMessage
-	9, // [9:10] is the sub-list for method output_type
-	8, // [8:9] is the sub-list for method input_type
-	8, // [8:8] is the sub-list for extension type_name
-	8, // [8:8] is the sub-list for extension extendee
-	0, // [0:8] is the sub-list for field type_name
+	0,  // 0: llm.v1.StartRequest.messages:type_name -> llm.v1.ChatMessage
+	1,  // 1: llm.v1.ClientMessage.start:type_name -> llm.v1.StartRequest
+	2,  // 2: llm.v1.ClientMessage.cancel:type_name -> llm.v1.Cancel
+	4,  // 3: llm.v1.ServerMessage.connected:type_name -> llm.v1.Connected
+	5,  // 4: llm.v1.ServerMessage.token:type_name -> llm.v1.Token
+	6,  // 5: llm.v1.ServerMessage.sentence:type_name -> llm.v1.Sentence
+	7,  // 6: llm.v1.ServerMessage.usage:type_name -> llm.v1.Usage
+	8,  // 7: llm.v1.ServerMessage.error:type_name -> llm.v1.Error
+	3,  // 8: llm.v1.LLM.Session:input_type -> llm.v1.ClientMessage
+	10, // 9: llm.v1.LLM.Moderate:input_type -> llm.v1.ModerateRequest
+	9,  // 10: llm.v1.LLM.Session:output_type -> llm.v1.ServerMessage
+	11, // 11: llm.v1.LLM.Moderate:output_type -> llm.v1.ModerateResponse
+	10, // [10:12] is the sub-list for method output_type
+	8,  // [8:10] is the sub-list for method input_type
+	8,  // [8:8] is the sub-list for extension type_name
+	8,  // [8:8] is the sub-list for extension extendee
+	0,  // [0:8] is the sub-list for field type_name
 }
 
 func init() { file_llm_proto_init() }
@@ -778,6 +980,7 @@ func file_llm_proto_init() {
 	if File_llm_proto != nil {
 		return
 	}
+	file_llm_proto_msgTypes[1].OneofWrappers = []any{}
 	file_llm_proto_msgTypes[3].OneofWrappers = []any{
 		(*ClientMessage_Start)(nil),
 		(*ClientMessage_Cancel)(nil),
@@ -795,7 +998,7 @@ func file_llm_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_llm_proto_rawDesc), len(file_llm_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   10,
+			NumMessages:   12,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
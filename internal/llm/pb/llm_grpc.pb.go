@@ -19,7 +19,8 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	LLM_Session_FullMethodName = "/llm.v1.LLM/Session"
+	LLM_Session_FullMethodName  = "/llm.v1.LLM/Session"
+	LLM_Moderate_FullMethodName = "/llm.v1.LLM/Moderate"
 )
 
 // LLMClient is the client API for LLM service.
@@ -27,6 +28,7 @@ const (
 // For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
 type LLMClient interface {
 	Session(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[ClientMessage, ServerMessage], error)
+	Moderate(ctx context.Context, in *ModerateRequest, opts ...grpc.CallOption) (*ModerateResponse, error)
 }
 
 type lLMClient struct {
@@ -50,11 +52,22 @@ func (c *lLMClient) Session(ctx context.Context, opts ...grpc.CallOption) (grpc.
 // This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
 type LLM_SessionClient = grpc.BidiStreamingClient[ClientMessage, ServerMessage]
 
+func (c *lLMClient) Moderate(ctx context.Context, in *ModerateRequest, opts ...grpc.CallOption) (*ModerateResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ModerateResponse)
+	err := c.cc.Invoke(ctx, LLM_Moderate_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // LLMServer is the server API for LLM service.
 // All implementations must embed UnimplementedLLMServer
 // for forward compatibility.
 type LLMServer interface {
 	Session(grpc.BidiStreamingServer[ClientMessage, ServerMessage]) error
+	Moderate(context.Context, *ModerateRequest) (*ModerateResponse, error)
 	mustEmbedUnimplementedLLMServer()
 }
 
@@ -68,6 +81,9 @@ type UnimplementedLLMServer struct{}
 func (UnimplementedLLMServer) Session(grpc.BidiStreamingServer[ClientMessage, ServerMessage]) error {
 	return status.Error(codes.Unimplemented, "method Session not implemented")
 }
+func (UnimplementedLLMServer) Moderate(context.Context, *ModerateRequest) (*ModerateResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method Moderate not implemented")
+}
 func (UnimplementedLLMServer) mustEmbedUnimplementedLLMServer() {}
 func (UnimplementedLLMServer) testEmbeddedByValue()             {}
 
@@ -96,13 +112,36 @@ func _LLM_Session_Handler(srv interface{}, stream grpc.ServerStream) error {
 // This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
 type LLM_SessionServer = grpc.BidiStreamingServer[ClientMessage, ServerMessage]
 
+func _LLM_Moderate_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ModerateRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LLMServer).Moderate(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: LLM_Moderate_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LLMServer).Moderate(ctx, req.(*ModerateRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // LLM_ServiceDesc is the grpc.ServiceDesc for LLM service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
 var LLM_ServiceDesc = grpc.ServiceDesc{
 	ServiceName: "llm.v1.LLM",
 	HandlerType: (*LLMServer)(nil),
-	Methods:     []grpc.MethodDesc{},
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Moderate",
+			Handler:    _LLM_Moderate_Handler,
+		},
+	},
 	Streams: []grpc.StreamDesc{
 		{
 			StreamName:    "Session",
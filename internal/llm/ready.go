@@ -0,0 +1,40 @@
+package llm
+
+import (
+    "context"
+    "fmt"
+    "io"
+    "net/http"
+    "os"
+    "strings"
+
+    "yuzu/agent/internal/errdefs"
+    "yuzu/agent/internal/secrets"
+)
+
+// CheckProvider verifies Azure OpenAI config is present and, when ping is
+// set, that the endpoint accepts our key. The models listing is used because
+// it is free and does not depend on a deployment name.
+func CheckProvider(ctx context.Context, ping bool) error {
+    endpoint := os.Getenv("AZURE_OPENAI_ENDPOINT")
+    apiKey := secrets.Get("AZURE_OPENAI_API_KEY")
+    if endpoint == "" || apiKey == "" {
+        return &errdefs.ConfigError{Key: "AZURE_OPENAI_ENDPOINT/AZURE_OPENAI_API_KEY", Msg: "missing"}
+    }
+    if !ping { return nil }
+    apiVersion := os.Getenv("AZURE_OPENAI_API_VERSION")
+    if apiVersion == "" { apiVersion = "2024-02-15-preview" }
+    url := fmt.Sprintf("%s/openai/models?api-version=%s", strings.TrimRight(endpoint, "/"), apiVersion)
+    req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+    if err != nil { return err }
+    req.Header.Set("api-key", apiKey)
+    resp, err := http.DefaultClient.Do(req)
+    if err != nil { return errdefs.ProviderTransport("azure", err) }
+    defer resp.Body.Close()
+    if resp.StatusCode/100 != 2 {
+        b, _ := io.ReadAll(io.LimitReader(resp.Body, 256))
+        return errdefs.ProviderStatus("azure", resp.StatusCode, strings.TrimSpace(string(b)))
+    }
+    io.Copy(io.Discard, resp.Body)
+    return nil
+}
@@ -0,0 +1,246 @@
+package llm
+
+import (
+    "encoding/json"
+    "fmt"
+    "log"
+    "math/rand"
+    "os"
+    "regexp"
+    "strconv"
+    "strings"
+    "sync"
+    "time"
+
+    "github.com/prometheus/client_golang/prometheus"
+    "github.com/prometheus/client_golang/prometheus/promauto"
+
+    pb "yuzu/agent/internal/llm/pb"
+)
+
+// samplelog.go records full prompts and responses for a sample of requests,
+// for debugging prompt quality without logging every conversation. It is off
+// unless LLM_SAMPLE_PERCENT is set. Every string passes through a redaction
+// hook before it is written; the default masks emails, phone numbers and long
+// digit runs. Requests of sessions that opted out of logging
+// (StartRequest.no_log) are never recorded; those that would have been are
+// logged without content and counted in llm_samples_refused_total.
+
+var metricSamplesRefused = promauto.NewCounter(prometheus.CounterOpts{
+    Name: "llm_samples_refused_total",
+    Help: "Requests picked for sampling but not recorded because their session opted out of logging",
+})
+
+// SampleRecord is one sampled request and its response.
+type SampleRecord struct {
+    Time        time.Time         `json:"time"`
+    SessionID   string            `json:"session_id"`
+    RequestID   string            `json:"request_id"`
+    Deployment  string            `json:"deployment"`
+    Temperature *float64          `json:"temperature,omitempty"`
+    MaxTokens   uint32            `json:"max_tokens,omitempty"`
+    Messages    []SampleMessage   `json:"messages"`
+    Response    string            `json:"response"`
+    TTFTMs      int64             `json:"ttft_ms,omitempty"`
+    DurationMs  int64             `json:"duration_ms"`
+    Usage       map[string]uint32 `json:"usage,omitempty"`
+    Error       string            `json:"error,omitempty"`
+}
+
+// SampleMessage is a chat message as logged.
+type SampleMessage struct {
+    Role    string `json:"role"`
+    Content string `json:"content"`
+}
+
+// SampleSink stores sampled records. The file sink rotates by size; an
+// object-storage uploader can be plugged in by implementing this.
+type SampleSink interface {
+    Write(rec *SampleRecord) error
+}
+
+// Redactor rewrites text before it is logged.
+type Redactor func(string) string
+
+var (
+    emailRE  = regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`)
+    phoneRE  = regexp.MustCompile(`\+?\d[\d\s().-]{7,}\d`)
+    digitsRE = regexp.MustCompile(`\d{6,}`)
+)
+
+// RedactPII masks emails, phone numbers and long digit runs (card and
+// account numbers).
+func RedactPII(s string) string {
+    s = emailRE.ReplaceAllString(s, "[email]")
+    s = phoneRE.ReplaceAllString(s, "[phone]")
+    return digitsRE.ReplaceAllString(s, "[number]")
+}
+
+// SampleLogger decides which requests to record and writes them.
+type SampleLogger struct {
+    percent      float64
+    byDeployment map[string]float64 // overrides percent per Azure deployment
+    sink         SampleSink
+    redact  Redactor
+
+    mu  sync.Mutex
+    rnd *rand.Rand
+}
+
+// NewSampleLogger records percent (0-100) of requests to sink.
+func NewSampleLogger(percent float64, sink SampleSink) *SampleLogger {
+    return &SampleLogger{percent: percent, sink: sink, redact: RedactPII, rnd: rand.New(rand.NewSource(time.Now().UnixNano()))}
+}
+
+// SampleLoggerFromEnv builds the logger from LLM_SAMPLE_PERCENT,
+// LLM_SAMPLE_PERCENT_BY_DEPLOYMENT ("gpt-4o=5,gpt-4o-mini=0.5"),
+// LLM_SAMPLE_LOG_PATH, LLM_SAMPLE_LOG_MAX_MB and LLM_SAMPLE_LOG_BACKUPS. It
+// returns nil (sampling off) when no percentage is set.
+func SampleLoggerFromEnv() *SampleLogger {
+    pct, _ := strconv.ParseFloat(os.Getenv("LLM_SAMPLE_PERCENT"), 64)
+    byDep := parseDeploymentPercents(os.Getenv("LLM_SAMPLE_PERCENT_BY_DEPLOYMENT"))
+    if pct <= 0 && len(byDep) == 0 { return nil }
+    path := os.Getenv("LLM_SAMPLE_LOG_PATH")
+    if path == "" { path = "llm-samples.jsonl" }
+    maxMB, err := strconv.Atoi(os.Getenv("LLM_SAMPLE_LOG_MAX_MB"))
+    if err != nil || maxMB <= 0 { maxMB = 50 }
+    backups, err := strconv.Atoi(os.Getenv("LLM_SAMPLE_LOG_BACKUPS"))
+    if err != nil || backups < 0 { backups = 3 }
+    log.Printf("llm: sampling %.1f%% of requests (per deployment: %v) to %s", pct, byDep, path)
+    l := NewSampleLogger(pct, &RotatingFile{Path: path, MaxBytes: int64(maxMB) << 20, Backups: backups})
+    l.byDeployment = byDep
+    return l
+}
+
+// parseDeploymentPercents parses "name=pct,name=pct", skipping bad entries.
+func parseDeploymentPercents(v string) map[string]float64 {
+    out := map[string]float64{}
+    for _, kv := range strings.Split(v, ",") {
+        name, p, ok := strings.Cut(strings.TrimSpace(kv), "=")
+        if !ok || name == "" { continue }
+        if f, err := strconv.ParseFloat(strings.TrimSpace(p), 64); err == nil && f >= 0 {
+            out[strings.TrimSpace(name)] = f
+        }
+    }
+    return out
+}
+
+// newSampleRecord captures the request side of start.
+func newSampleRecord(start *pb.StartRequest) *SampleRecord {
+    rec := &SampleRecord{
+        Time:        time.Now(),
+        SessionID:   start.GetSessionId(),
+        RequestID:   start.GetRequestId(),
+        Deployment:  start.GetDeployment(),
+        Temperature: start.Temperature,
+        MaxTokens:   start.GetMaxTokens(),
+    }
+    for _, m := range start.GetMessages() {
+        rec.Messages = append(rec.Messages, SampleMessage{Role: m.GetRole(), Content: m.GetContent()})
+    }
+    return rec
+}
+
+// SetRedactor replaces the redaction hook; nil disables redaction.
+func (l *SampleLogger) SetRedactor(r Redactor) { l.redact = r }
+
+// Sample reports whether the next request to deployment should be recorded.
+func (l *SampleLogger) Sample(deployment string) bool {
+    if l == nil { return false }
+    pct := l.percent
+    if p, ok := l.byDeployment[deployment]; ok { pct = p }
+    if pct <= 0 { return false }
+    if pct >= 100 { return true }
+    l.mu.Lock()
+    defer l.mu.Unlock()
+    return l.rnd.Float64()*100 < pct
+}
+
+// SampleRequest is Sample for start, false when its session opted out.
+func (l *SampleLogger) SampleRequest(start *pb.StartRequest) bool {
+    if !l.Sample(start.GetDeployment()) { return false }
+    if start.GetNoLog() {
+        metricSamplesRefused.Inc()
+        log.Printf("llm: not sampling request %s of session %s: opted out of logging", start.GetRequestId(), start.GetSessionId())
+        return false
+    }
+    return true
+}
+
+// Record redacts rec and writes it. Failures are logged, never returned:
+// sampling must not affect the request.
+func (l *SampleLogger) Record(rec *SampleRecord) {
+    if l == nil { return }
+    if l.redact != nil {
+        for i := range rec.Messages {
+            rec.Messages[i].Content = l.redact(rec.Messages[i].Content)
+        }
+        rec.Response = l.redact(rec.Response)
+        rec.Error = l.redact(rec.Error)
+    }
+    if err := l.sink.Write(rec); err != nil {
+        log.Printf("llm: sample log write: %v", err)
+    }
+}
+
+// RotatingFile appends JSON lines to Path, moving it to Path.1 (and older
+// files up to Path.<Backups>) once it would exceed MaxBytes.
+type RotatingFile struct {
+    Path     string
+    MaxBytes int64
+    Backups  int
+
+    mu   sync.Mutex
+    f    *os.File
+    size int64
+}
+
+func (r *RotatingFile) Write(rec *SampleRecord) error {
+    b, err := json.Marshal(rec)
+    if err != nil { return err }
+    b = append(b, '\n')
+    r.mu.Lock()
+    defer r.mu.Unlock()
+    if r.f == nil {
+        if err := r.open(); err != nil { return err }
+    }
+    if r.MaxBytes > 0 && r.size > 0 && r.size+int64(len(b)) > r.MaxBytes {
+        if err := r.rotate(); err != nil { return err }
+    }
+    n, err := r.f.Write(b)
+    r.size += int64(n)
+    return err
+}
+
+func (r *RotatingFile) open() error {
+    f, err := os.OpenFile(r.Path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+    if err != nil { return err }
+    fi, err := f.Stat()
+    if err != nil { f.Close(); return err }
+    r.f, r.size = f, fi.Size()
+    return nil
+}
+
+func (r *RotatingFile) rotate() error {
+    r.f.Close()
+    r.f = nil
+    if r.Backups <= 0 {
+        if err := os.Remove(r.Path); err != nil && !os.IsNotExist(err) { return err }
+        return r.open()
+    }
+    for i := r.Backups - 1; i >= 1; i-- {
+        _ = os.Rename(fmt.Sprintf("%s.%d", r.Path, i), fmt.Sprintf("%s.%d", r.Path, i+1))
+    }
+    if err := os.Rename(r.Path, r.Path+".1"); err != nil && !os.IsNotExist(err) { return err }
+    return r.open()
+}
+
+// Close closes the current file.
+func (r *RotatingFile) Close() error {
+    r.mu.Lock()
+    defer r.mu.Unlock()
+    if r.f == nil { return nil }
+    err := r.f.Close()
+    r.f = nil
+    return err
+}
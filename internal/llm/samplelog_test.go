@@ -0,0 +1,87 @@
+package llm
+
+import (
+    "bufio"
+    "encoding/json"
+    "os"
+    "path/filepath"
+    "strings"
+    "testing"
+
+    pb "yuzu/agent/internal/llm/pb"
+)
+
+func TestRedactPII(t *testing.T) {
+    in := "Reach me at jane.doe@example.com or +1 (415) 555-0199, card 4111111111111111."
+    got := RedactPII(in)
+    for _, leak := range []string{"jane.doe", "555-0199", "4111"} {
+        if strings.Contains(got, leak) {
+            t.Errorf("RedactPII left %q in %q", leak, got)
+        }
+    }
+    if !strings.Contains(got, "[email]") || !strings.Contains(got, "Reach me at") {
+        t.Errorf("RedactPII = %q", got)
+    }
+}
+
+func TestSampleRates(t *testing.T) {
+    var nilLogger *SampleLogger
+    if nilLogger.Sample("gpt-4o") {
+        t.Error("nil logger sampled")
+    }
+    l := NewSampleLogger(100, nil)
+    l.byDeployment = parseDeploymentPercents("gpt-4o-mini=0, bad, gpt-4o = 100")
+    if !l.Sample("other") || !l.Sample("gpt-4o") || l.Sample("gpt-4o-mini") {
+        t.Error("per-deployment override not applied")
+    }
+    l = NewSampleLogger(10, nil)
+    n := 0
+    for i := 0; i < 10000; i++ {
+        if l.Sample("d") { n++ }
+    }
+    if n < 700 || n > 1300 {
+        t.Errorf("10%% sampling picked %d of 10000", n)
+    }
+    // A session's opt-out wins over any rate
+    l = NewSampleLogger(100, nil)
+    if !l.SampleRequest(&pb.StartRequest{Deployment: "d"}) || l.SampleRequest(&pb.StartRequest{Deployment: "d", NoLog: true}) {
+        t.Error("no_log not honored")
+    }
+}
+
+func TestSampleLogRedactsAndRotates(t *testing.T) {
+    path := filepath.Join(t.TempDir(), "samples.jsonl")
+    f := &RotatingFile{Path: path, MaxBytes: 400, Backups: 2}
+    defer f.Close()
+    l := NewSampleLogger(100, f)
+    for i := 0; i < 6; i++ {
+        l.Record(&SampleRecord{
+            SessionID: "s1",
+            Messages:  []SampleMessage{{Role: "user", Content: "my email is bob@example.com"}},
+            Response:  "Thanks, noted.",
+        })
+    }
+
+    for _, name := range []string{path, path + ".1", path + ".2"} {
+        if _, err := os.Stat(name); err != nil {
+            t.Fatalf("expected %s: %v", filepath.Base(name), err)
+        }
+    }
+    if _, err := os.Stat(path + ".3"); err == nil {
+        t.Error("kept more backups than configured")
+    }
+
+    r, err := os.Open(path)
+    if err != nil { t.Fatal(err) }
+    defer r.Close()
+    sc := bufio.NewScanner(r)
+    for sc.Scan() {
+        var rec SampleRecord
+        if err := json.Unmarshal(sc.Bytes(), &rec); err != nil {
+            t.Fatalf("bad line %q: %v", sc.Text(), err)
+        }
+        if c := rec.Messages[0].Content; c != "my email is [email]" {
+            t.Errorf("logged prompt %q, want redacted", c)
+        }
+    }
+}
@@ -12,18 +12,29 @@ import (
     "strings"
     "time"
 
+    "yuzu/agent/internal/chaos"
+    "yuzu/agent/internal/errdefs"
+    "yuzu/agent/internal/secrets"
+    "yuzu/agent/internal/warmup"
     pb "yuzu/agent/internal/llm/pb"
 )
 
 type Server struct {
     pb.UnimplementedLLMServer
-    httpc *http.Client
+    httpc    *http.Client
+    samples  *SampleLogger // nil unless LLM_SAMPLE_PERCENT is set (see samplelog.go)
+    breakers *breakers     // per-deployment circuit breakers (see breaker.go)
+    chaos    *chaos.Injector // nil unless CHAOS_ENABLED (see internal/chaos)
+    warm     *warmup.Warmer  // nil unless LLM_WARMUP (see warm.go)
 }
 
 func NewServer() *Server {
-    return &Server{httpc: &http.Client{Timeout: 0}}
+    return &Server{httpc: &http.Client{Timeout: 0}, samples: SampleLoggerFromEnv(), breakers: newBreakers(breakerConfigFromEnv()), chaos: chaos.FromEnv(chaos.TargetLLM)}
 }
 
+// SetSampleLogger replaces the request sampler; nil turns sampling off.
+func (s *Server) SetSampleLogger(l *SampleLogger) { s.samples = l }
+
 func (s *Server) Session(stream pb.LLM_SessionServer) error {
     parent := stream.Context()
     // Expect a StartRequest; support Cancel thereafter
@@ -31,12 +42,12 @@ func (s *Server) Session(stream pb.LLM_SessionServer) error {
     if err != nil { return err }
     start := msg.GetStart()
     if start == nil { return fmt.Errorf("expected start request") }
-    _ = stream.Send(&pb.ServerMessage{Msg: &pb.ServerMessage_Connected{Connected: &pb.Connected{SessionId: start.GetSessionId()}}})
+    s.warm.Touch()
 
     azureEndpoint := os.Getenv("AZURE_OPENAI_ENDPOINT")
-    apiKey := os.Getenv("AZURE_OPENAI_API_KEY")
+    apiKey := secrets.Get("AZURE_OPENAI_API_KEY")
     if azureEndpoint == "" || apiKey == "" {
-        _ = stream.Send(&pb.ServerMessage{Msg: &pb.ServerMessage_Error{Error: &pb.Error{Code: "config", Message: "missing AZURE_OPENAI_ENDPOINT or AZURE_OPENAI_API_KEY"}}})
+        sendError(stream, &errdefs.ConfigError{Key: "AZURE_OPENAI_ENDPOINT/AZURE_OPENAI_API_KEY", Msg: "missing"})
         return nil
     }
 
@@ -50,7 +61,15 @@ func (s *Server) Session(stream pb.LLM_SessionServer) error {
         "messages": toAzureMessages(start.GetMessages()),
     }
     if start.GetMaxTokens() > 0 { body["max_tokens"] = start.GetMaxTokens() }
-    if start.GetTemperature() > 0 { body["temperature"] = start.GetTemperature() }
+    // An explicit 0 is sent; only an unset temperature leaves the provider default
+    if start.Temperature != nil { body["temperature"] = start.GetTemperature() }
+
+    // Sampled requests are recorded in full once the response completes
+    var sample *SampleRecord
+    if s.samples.SampleRequest(start) {
+        sample = newSampleRecord(start)
+        defer func() { sample.DurationMs = time.Since(sample.Time).Milliseconds(); s.samples.Record(sample) }()
+    }
 
     url := fmt.Sprintf("%s/openai/deployments/%s/chat/completions?api-version=%s", strings.TrimRight(azureEndpoint, "/"), deployment, apiVersion)
     reqBytes, _ := json.Marshal(body)
@@ -74,15 +93,58 @@ func (s *Server) Session(stream pb.LLM_SessionServer) error {
     req.Header.Set("api-key", apiKey)
     req.Header.Set("Content-Type", "application/json")
     req.Header.Set("Accept", "text/event-stream")
+
+    // Fail fast while this deployment's breaker is open
+    brk := s.breakers.get(deployment)
+    if !brk.allow() {
+        cerr := errdefs.CircuitOpen("azure")
+        if sample != nil { sample.Error = cerr.Error() }
+        _ = stream.Send(&pb.ServerMessage{Msg: &pb.ServerMessage_Error{Error: &pb.Error{Code: errdefs.CodeCircuitOpen, Message: cerr.Error()}}})
+        return nil
+    }
+    result := callSucceeded
+    defer func() {
+        if ctx.Err() != nil { result = callIgnored }
+        brk.done(result)
+    }()
+
+    // Injected faults stand in for a hung or failing provider
+    switch s.chaos.Inject(ctx) {
+    case chaos.Drop:
+        <-ctx.Done()
+        return nil
+    case chaos.Error:
+        result = callFailed
+        perr := errdefs.ProviderStatus("azure", http.StatusServiceUnavailable, chaos.ErrInjected.Error())
+        if sample != nil { sample.Error = perr.Error() }
+        sendError(stream, perr)
+        return nil
+    }
+
     // Azure streams as text/event-stream
+    reqStart := time.Now()
     resp, err := s.httpc.Do(req)
-    if err != nil { return err }
+    if err != nil {
+        result = callFailed
+        err = errdefs.ProviderTransport("azure", err)
+        if sample != nil { sample.Error = err.Error() }
+        return err
+    }
     defer resp.Body.Close()
     if resp.StatusCode/100 != 2 {
         b, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
-        _ = stream.Send(&pb.ServerMessage{Msg: &pb.ServerMessage_Error{Error: &pb.Error{Code: "http", Message: fmt.Sprintf("status=%d body=%s", resp.StatusCode, string(b))}}})
+        perr := errdefs.ProviderStatus("azure", resp.StatusCode, string(b))
+        if perr.Retryable { result = callFailed } else { result = callIgnored }
+        // A rejected key may have been rotated; read it again next time
+        if resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden { secrets.Invalidate("AZURE_OPENAI_API_KEY") }
+        if sample != nil { sample.Error = perr.Error() }
+        sendError(stream, perr)
         return nil
     }
+    _ = stream.Send(&pb.ServerMessage{Msg: &pb.ServerMessage_Connected{Connected: &pb.Connected{
+        SessionId: start.GetSessionId(), Provider: "azure_openai", Model: deployment,
+        ConnectMs: uint32(time.Since(reqStart).Milliseconds()), RequestId: resp.Header.Get("apim-request-id"),
+    }}})
 
     br := bufio.NewReader(resp.Body)
     startTime := time.Now()
@@ -95,6 +157,8 @@ func (s *Server) Session(stream pb.LLM_SessionServer) error {
         if err != nil {
             if err == io.EOF { break }
             // non-fatal: send error and break
+            result = callFailed
+            if sample != nil { sample.Error = err.Error() }
             _ = stream.Send(&pb.ServerMessage{Msg: &pb.ServerMessage_Error{Error: &pb.Error{Code: "stream", Message: err.Error()}}})
             break
         }
@@ -112,9 +176,10 @@ func (s *Server) Session(stream pb.LLM_SessionServer) error {
             if !firstTokenSent {
                 ttft := time.Since(startTime).Milliseconds()
                 // Could export Prometheus here if desired
-                _ = ttft
+                if sample != nil { sample.TTFTMs = ttft }
                 firstTokenSent = true
             }
+            if sample != nil { sample.Response += content }
             sentBuf.WriteString(content)
             _ = stream.Send(&pb.ServerMessage{Msg: &pb.ServerMessage_Token{Token: &pb.Token{Text: content}}})
             // sentence segmentation
@@ -127,6 +192,7 @@ func (s *Server) Session(stream pb.LLM_SessionServer) error {
         // usage in final payload
         if usage, ok := m["usage"].(map[string]any); ok {
             pt := toInt(usage["prompt_tokens"]) ; ct := toInt(usage["completion_tokens"]) ; tt := toInt(usage["total_tokens"]) 
+            if sample != nil { sample.Usage = map[string]uint32{"prompt_tokens": uint32(pt), "completion_tokens": uint32(ct), "total_tokens": uint32(tt)} }
             _ = stream.Send(&pb.ServerMessage{Msg: &pb.ServerMessage_Usage{Usage: &pb.Usage{PromptTokens: uint32(pt), CompletionTokens: uint32(ct), TotalTokens: uint32(tt)}}})
         }
     }
@@ -137,6 +203,11 @@ func (s *Server) Session(stream pb.LLM_SessionServer) error {
     return nil
 }
 
+// sendError reports err in-band with its errdefs class as the code.
+func sendError(stream pb.LLM_SessionServer, err error) {
+    _ = stream.Send(&pb.ServerMessage{Msg: &pb.ServerMessage_Error{Error: &pb.Error{Code: errdefs.Class(err), Message: err.Error()}}})
+}
+
 func toAzureMessages(in []*pb.ChatMessage) []map[string]any {
     out := make([]map[string]any, 0, len(in))
     for _, m := range in {
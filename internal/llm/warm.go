@@ -0,0 +1,67 @@
+package llm
+
+import (
+    "bytes"
+    "context"
+    "encoding/json"
+    "fmt"
+    "io"
+    "net/http"
+    "os"
+    "strings"
+
+    "yuzu/agent/internal/errdefs"
+    "yuzu/agent/internal/secrets"
+    "yuzu/agent/internal/warmup"
+)
+
+// warm.go is the LLM service's warm-up request (see internal/warmup): a
+// one-token, non-streaming completion on LLM_WARMUP_DEPLOYMENT (default
+// LLM_DEPLOYMENT, then AZURE_OPENAI_DEPLOYMENT), sent through the client
+// real sessions use so its pooled connection stays open. It skips the
+// circuit breaker, request sampling and chaos injection.
+
+// SetWarmer has Session note real traffic on w, so warm-ups only fill
+// idle spells.
+func (s *Server) SetWarmer(w *warmup.Warmer) { s.warm = w }
+
+// Warm sends the warm-up completion.
+func (s *Server) Warm(ctx context.Context) error {
+    endpoint := os.Getenv("AZURE_OPENAI_ENDPOINT")
+    apiKey := secrets.Get("AZURE_OPENAI_API_KEY")
+    if endpoint == "" || apiKey == "" {
+        return &errdefs.ConfigError{Key: "AZURE_OPENAI_ENDPOINT/AZURE_OPENAI_API_KEY", Msg: "missing"}
+    }
+    deployment := warmDeployment()
+    if deployment == "" {
+        return &errdefs.ConfigError{Key: "LLM_WARMUP_DEPLOYMENT", Msg: "no deployment to warm"}
+    }
+    apiVersion := os.Getenv("LLM_API_VERSION")
+    if apiVersion == "" { apiVersion = "2024-02-15-preview" }
+    url := fmt.Sprintf("%s/openai/deployments/%s/chat/completions?api-version=%s", strings.TrimRight(endpoint, "/"), deployment, apiVersion)
+    body, _ := json.Marshal(map[string]any{
+        "messages":   []map[string]any{{"role": "user", "content": "ping"}},
+        "max_tokens": 1,
+    })
+    req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+    if err != nil { return err }
+    req.Header.Set("api-key", apiKey)
+    req.Header.Set("Content-Type", "application/json")
+    resp, err := s.httpc.Do(req)
+    if err != nil { return errdefs.ProviderTransport("azure", err) }
+    defer resp.Body.Close()
+    if resp.StatusCode/100 != 2 {
+        b, _ := io.ReadAll(io.LimitReader(resp.Body, 256))
+        return errdefs.ProviderStatus("azure", resp.StatusCode, strings.TrimSpace(string(b)))
+    }
+    // Drain the body so the connection goes back to the pool
+    io.Copy(io.Discard, resp.Body)
+    return nil
+}
+
+func warmDeployment() string {
+    for _, k := range []string{"LLM_WARMUP_DEPLOYMENT", "LLM_DEPLOYMENT", "AZURE_OPENAI_DEPLOYMENT"} {
+        if v := os.Getenv(k); v != "" { return v }
+    }
+    return ""
+}
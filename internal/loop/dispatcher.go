@@ -6,6 +6,10 @@ import (
     "time"
 
     "github.com/google/uuid"
+    "yuzu/agent/internal/api"
+    "yuzu/agent/internal/bot"
+    "yuzu/agent/internal/bus"
+    "yuzu/agent/internal/clock"
     "yuzu/agent/internal/floor"
     "yuzu/agent/internal/store"
     "yuzu/agent/internal/workerws"
@@ -13,15 +17,22 @@ import (
 
 type Dispatcher struct {
     reg   *workerws.Registry
-    store *store.Store
+    store store.Store
 
     ttsTimeoutSec int
+    clock         clock.Clock
+    policy        floor.Policy
 
-    mu       sync.Mutex
+    mu       sync.Mutex // guards sessions only
     sessions map[string]*sessState
 }
 
+// sessState is one session's floor state. The worker socket and the debug
+// endpoints can deliver a session's messages concurrently, so every field
+// is read and written under mu.
 type sessState struct {
+    mu            sync.Mutex
+    strategy      floor.Strategy
     fsm           *floor.Manager
     lastVADTsMs   int64
     lastVADRecvMs int64
@@ -29,37 +40,141 @@ type sessState struct {
     pendingCmdID  string
     ttsStartRecv  time.Time
     bargeInArmed  bool
+
+    // pause_tts / resume_tts (see Policy); paused once pause_tts was sent
+    paused   bool
+    pauseCmd pauseCmd
+
+    // When the agent last finished without being interrupted, while the
+    // candidate has stayed silent; zero otherwise (see CheckSilence)
+    idleSince time.Time
 }
 
-func New(reg *workerws.Registry, st *store.Store, ttsTimeoutSec int) *Dispatcher {
-    return &Dispatcher{reg: reg, store: st, ttsTimeoutSec: ttsTimeoutSec, sessions: make(map[string]*sessState)}
+// pauseCmd is the last pause_tts or resume_tts sent, for its ack.
+type pauseCmd struct {
+    id          string
+    typ         string
+    utteranceID string
+    vadTsMs     int64 // worker clock of the speech that caused it
+    sentMs      int64
+}
+
+// command is a worker command to send once the session is unlocked, and
+// the event recorded after it.
+type command struct {
+    msg   workerws.Message
+    event string
+    info  map[string]any
+}
+
+func New(reg *workerws.Registry, st store.Store, ttsTimeoutSec int) *Dispatcher {
+    return &Dispatcher{reg: reg, store: st, ttsTimeoutSec: ttsTimeoutSec, clock: clock.Real, sessions: make(map[string]*sessState)}
+}
+
+// SetClock replaces the time source (tests use clock.Fake).
+func (d *Dispatcher) SetClock(c clock.Clock) { d.clock = c }
+
+// SetPolicy sets how speech over the agent is handled for sessions that
+// start afterwards without a turn policy of their own; the default stops
+// the agent on any speech.
+func (d *Dispatcher) SetPolicy(p floor.Policy) { d.policy = p }
+
+// strategy is the turn-taking strategy for sessionID: its turn_policy, or
+// the deployment's Policy.
+func (d *Dispatcher) strategy(sessionID string) floor.Strategy {
+    if sess := d.store.GetSession(sessionID); sess != nil {
+        if s, ok := floor.StrategyFor(sess.TurnPolicy, d.policy); ok {
+            return s
+        }
+    }
+    return d.policy
 }
 
 func (d *Dispatcher) state(sessionID string) *sessState {
     d.mu.Lock()
-    defer d.mu.Unlock()
     s := d.sessions[sessionID]
-    if s == nil {
-        s = &sessState{fsm: floor.New()}
+    d.mu.Unlock()
+    if s != nil {
+        return s
+    }
+    strategy := d.strategy(sessionID)
+    d.mu.Lock()
+    defer d.mu.Unlock()
+    if s = d.sessions[sessionID]; s == nil {
+        s = &sessState{strategy: strategy, fsm: floor.NewWithStrategy(strategy)}
         d.sessions[sessionID] = s
     }
     return s
 }
 
+// lookup returns a session's state without creating it.
+func (d *Dispatcher) lookup(sessionID string) *sessState {
+    d.mu.Lock()
+    defer d.mu.Unlock()
+    return d.sessions[sessionID]
+}
+
+// Subscribe has the dispatcher follow worker messages and disconnects on
+// b, and end sessions whose bot exits or that complete. State can exist
+// without a worker connection (the debug endpoints), so a disconnect alone
+// would not free it.
+func (d *Dispatcher) Subscribe(b *bus.Bus) {
+    bus.On(b, workerws.TopicMessage, d.OnMessage)
+    bus.On(b, workerws.TopicDisconnected, func(sessionID string, ev workerws.Disconnect) { d.endConn(sessionID, ev.Conn) })
+    bus.On(b, bot.TopicExited, func(sessionID string, _ bot.Exited) { d.EndSession(sessionID) })
+    bus.On(b, api.TopicSessionCompleted, func(sessionID string, _ api.SessionCompleted) { d.EndSession(sessionID) })
+}
+
+// endConn drops a session's state for the disconnect of worker connection
+// conn, unless a newer connection holds the session by now: an old
+// socket's late disconnect must not wipe a reconnected worker's floor. The
+// check and the delete share d.mu, so a newer worker's first message
+// either comes after the delete or keeps its state.
+func (d *Dispatcher) endConn(sessionID string, conn uint64) {
+    d.mu.Lock()
+    defer d.mu.Unlock()
+    if d.reg.Superseded(sessionID, conn) {
+        return
+    }
+    delete(d.sessions, sessionID)
+}
+
+// EndSession drops a session's state once it is over; a worker that
+// reconnects starts from a fresh floor, as after worker_hello.
+func (d *Dispatcher) EndSession(sessionID string) {
+    d.mu.Lock()
+    delete(d.sessions, sessionID)
+    d.mu.Unlock()
+}
+
+// reset clears the floor after a new worker or a TTS timeout.
+func (d *Dispatcher) reset(s *sessState) {
+    s.fsm = floor.NewWithStrategy(s.strategy)
+    s.stopping = false
+    s.pendingCmdID = ""
+    s.paused = false
+    s.idleSince = time.Time{}
+}
+
 // OnMessage processes a worker message and may send commands to the worker.
 func (d *Dispatcher) OnMessage(sessionID string, msg workerws.Message) {
     s := d.state(sessionID)
-    nowRecvMs := time.Now().UnixMilli()
+    nowRecvMs := d.clock.Now().UnixMilli()
+    var cmd *command
+    s.mu.Lock()
 
     switch msg.Type {
     case "tts_started":
         s.fsm.OnTTSStarted(msg.UtteranceID, msg.TsMs)
-        s.ttsStartRecv = time.Now()
+        s.ttsStartRecv = d.clock.Now()
         s.bargeInArmed = false
+        s.paused = false
+        s.idleSince = time.Time{}
         d.store.AppendEvent(sessionID, "tts_started_backend_recv", map[string]any{"recv_ms": nowRecvMs})
     case "tts_first_audio":
         // Arm barge-in only after first audio is emitted, to avoid prebuffer cut-offs
         s.bargeInArmed = true
+        s.fsm.OnTTSFirstAudio(msg.TsMs)
         d.store.AppendEvent(sessionID, "tts_first_audio_backend_recv", map[string]any{"recv_ms": nowRecvMs})
     case "tts_stopped":
         reason := ""
@@ -68,6 +183,10 @@ func (d *Dispatcher) OnMessage(sessionID string, msg workerws.Message) {
         }
         s.fsm.OnTTSStopped(msg.UtteranceID, msg.TsMs, reason)
         s.bargeInArmed = false
+        s.paused = false
+        if reason != "interrupted" {
+            s.idleSince = d.clock.Now()
+        }
         // If interrupted, compute latency
         if reason == "interrupted" && s.lastVADTsMs > 0 {
             workerMs := msg.TsMs - s.lastVADTsMs
@@ -83,54 +202,181 @@ func (d *Dispatcher) OnMessage(sessionID string, msg workerws.Message) {
     case "vad_start":
         s.lastVADTsMs = msg.TsMs
         s.lastVADRecvMs = nowRecvMs
+        s.idleSince = time.Time{}
         // Only treat candidate_audio (or debug) as barge-in sources
         source := ""
         if msg.Payload != nil {
             if v, ok := msg.Payload["source"].(string); ok { source = v }
         }
         dec := s.fsm.OnVADStart(msg.TsMs)
-        if s.bargeInArmed && (source == "candidate_audio" || source == "debug") && dec.ShouldStop && !s.stopping {
-            s.stopping = true
-            cmdID := uuid.New().String()
-            s.pendingCmdID = cmdID
-            // Send stop_tts to worker
-            out := workerws.Message{
-                Type:        "stop_tts",
-                TsMs:        time.Now().UnixMilli(),
-                SessionID:   sessionID,
-                Seq:         0,
-                CommandID:   cmdID,
-                UtteranceID: dec.StopUtteranceID,
-                Payload:     map[string]any{"mode": "current"},
-            }
-            // Best-effort send; append event regardless
-            ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-            _ = d.reg.SendJSON(ctx, sessionID, out)
-            cancel()
-            d.store.AppendEvent(sessionID, "stop_tts_sent", map[string]any{"command_id": cmdID, "utterance_id": dec.StopUtteranceID})
+        if !s.bargeInArmed || (source != "candidate_audio" && source != "debug") {
+            break
+        }
+        switch {
+        case dec.ShouldStop:
+            cmd = d.stopCmd(sessionID, s, dec.StopUtteranceID)
+        case dec.Reason == "guard":
+            d.store.AppendEvent(sessionID, "barge_in_guarded", map[string]any{"vad_ts_ms": msg.TsMs})
+        case dec.Pause != "" && !s.stopping:
+            s.paused = true
+            cmd = d.pauseCmd(sessionID, s, "pause_tts", dec.UtteranceID, map[string]any{"mode": dec.Pause})
         }
     case "vad_end":
-        s.fsm.OnVADEnd(msg.TsMs)
+        dec := s.fsm.OnVADEnd(msg.TsMs)
+        if !s.paused {
+            break
+        }
+        switch {
+        case dec.ShouldStop:
+            cmd = d.stopCmd(sessionID, s, dec.StopUtteranceID)
+        case dec.Resume:
+            s.paused = false
+            cmd = d.pauseCmd(sessionID, s, "resume_tts", dec.UtteranceID, nil)
+        }
     case "cmd_ack":
-        if msg.CommandID != "" && msg.CommandID == s.pendingCmdID {
+        switch {
+        case msg.CommandID != "" && msg.CommandID == s.pendingCmdID:
             d.store.AppendEvent(sessionID, "cmd_ack", map[string]any{"command_id": msg.CommandID})
-        } else {
+        case msg.CommandID != "" && msg.CommandID == s.pauseCmd.id:
+            p := s.pauseCmd
+            d.store.AppendEvent(sessionID, "cmd_ack", map[string]any{"command_id": msg.CommandID})
+            // worker_ms runs on the worker's clock from the speech that
+            // caused the command to its ack; backend_ms is the round trip
+            d.store.AppendEvent(sessionID, "tts_pause_latency", map[string]any{
+                "command": p.typ, "command_id": p.id, "utterance_id": p.utteranceID,
+                "worker_ms": msg.TsMs - p.vadTsMs, "backend_ms": nowRecvMs - p.sentMs,
+                "error": ackError(msg),
+            })
+            s.pauseCmd = pauseCmd{}
+        default:
             d.store.AppendEvent(sessionID, "cmd_ack", map[string]any{"command_id": msg.CommandID, "note": "unexpected"})
         }
     case "worker_hello":
         // Reset speaking unless worker immediately restates playback
-        s.fsm = floor.New()
-        s.stopping = false
-        s.pendingCmdID = ""
+        d.reset(s)
     }
 
     // Safety timeout check
-    if !s.ttsStartRecv.IsZero() && time.Since(s.ttsStartRecv) > time.Duration(d.ttsTimeoutSec)*time.Second {
+    if !s.ttsStartRecv.IsZero() && d.clock.Since(s.ttsStartRecv) > time.Duration(d.ttsTimeoutSec)*time.Second {
         // Reset
-        s.fsm = floor.New()
-        s.stopping = false
-        s.pendingCmdID = ""
+        d.reset(s)
         s.ttsStartRecv = time.Time{}
         d.store.AppendEvent(sessionID, "tts_timeout_reset", nil)
     }
+    s.mu.Unlock()
+
+    if cmd != nil {
+        // Best-effort send; append event regardless
+        ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+        _ = d.reg.SendJSON(ctx, sessionID, cmd.msg)
+        cancel()
+        d.store.AppendEvent(sessionID, cmd.event, cmd.info)
+    }
+}
+
+// CheckSilence asks each session's strategy whether to re-prompt a
+// candidate who has stayed silent since the agent finished, and sends the
+// worker a reprompt with the text to say.
+func (d *Dispatcher) CheckSilence() {
+    d.mu.Lock()
+    states := make(map[string]*sessState, len(d.sessions))
+    for id, s := range d.sessions {
+        states[id] = s
+    }
+    d.mu.Unlock()
+    for id, s := range states {
+        // A session that ended meanwhile is not brought back
+        if d.lookup(id) != s {
+            continue
+        }
+        var cmd *command
+        s.mu.Lock()
+        if !s.idleSince.IsZero() && !s.stopping {
+            silentMs := d.clock.Since(s.idleSince).Milliseconds()
+            if dec := s.fsm.OnSilence(silentMs); dec.Reprompt != "" {
+                // Wait for the re-prompt itself to finish before timing again
+                s.idleSince = time.Time{}
+                cmdID := uuid.New().String()
+                cmd = &command{
+                    msg:   workerws.Message{Type: "reprompt", TsMs: d.clock.Now().UnixMilli(), SessionID: id, CommandID: cmdID, Payload: map[string]any{"text": dec.Reprompt}},
+                    event: "reprompt_sent",
+                    info:  map[string]any{"command_id": cmdID, "text": dec.Reprompt, "silent_ms": silentMs},
+                }
+            }
+        }
+        s.mu.Unlock()
+        if cmd != nil {
+            ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+            _ = d.reg.SendJSON(ctx, id, cmd.msg)
+            cancel()
+            d.store.AppendEvent(id, cmd.event, cmd.info)
+        }
+    }
+}
+
+// Run calls CheckSilence every interval until ctx ends.
+func (d *Dispatcher) Run(ctx context.Context, interval time.Duration) {
+    t := d.clock.NewTicker(interval)
+    defer t.Stop()
+    for {
+        select {
+        case <-ctx.Done():
+            return
+        case <-t.C():
+            d.CheckSilence()
+        }
+    }
+}
+
+// stopCmd builds the stop_tts for a barge-in. Callers hold s.mu.
+func (d *Dispatcher) stopCmd(sessionID string, s *sessState, utteranceID string) *command {
+    if s.stopping {
+        return nil
+    }
+    s.stopping = true
+    s.paused = false
+    cmdID := uuid.New().String()
+    s.pendingCmdID = cmdID
+    return &command{
+        msg: workerws.Message{
+            Type:        "stop_tts",
+            TsMs:        d.clock.Now().UnixMilli(),
+            SessionID:   sessionID,
+            Seq:         0,
+            CommandID:   cmdID,
+            UtteranceID: utteranceID,
+            Payload:     map[string]any{"mode": "current"},
+        },
+        event: "stop_tts_sent",
+        info:  map[string]any{"command_id": cmdID, "utterance_id": utteranceID},
+    }
+}
+
+// pauseCmd builds a pause_tts or resume_tts. Callers hold s.mu.
+func (d *Dispatcher) pauseCmd(sessionID string, s *sessState, typ, utteranceID string, payload map[string]any) *command {
+    cmdID := uuid.New().String()
+    now := d.clock.Now().UnixMilli()
+    s.pauseCmd = pauseCmd{id: cmdID, typ: typ, utteranceID: utteranceID, vadTsMs: s.lastVADTsMs, sentMs: now}
+    info := map[string]any{"command_id": cmdID, "utterance_id": utteranceID}
+    if mode, ok := payload["mode"]; ok {
+        info["mode"] = mode
+    }
+    return &command{
+        msg: workerws.Message{
+            Type:        typ,
+            TsMs:        now,
+            SessionID:   sessionID,
+            CommandID:   cmdID,
+            UtteranceID: utteranceID,
+            Payload:     payload,
+        },
+        event: typ + "_sent",
+        info:  info,
+    }
+}
+
+// ackError is the error a worker reported in cmd_ack, if any.
+func ackError(msg workerws.Message) string {
+    e, _ := msg.Payload["error"].(string)
+    return e
 }
@@ -0,0 +1,253 @@
+package loop
+
+import (
+    "sync"
+    "testing"
+    "time"
+
+    "yuzu/agent/internal/api"
+    "yuzu/agent/internal/bot"
+    "yuzu/agent/internal/bus"
+    "yuzu/agent/internal/clock"
+    "yuzu/agent/internal/floor"
+    "yuzu/agent/internal/store"
+    "yuzu/agent/internal/types"
+    "yuzu/agent/internal/workerws"
+)
+
+func countEvents(st store.Store, sid, typ string) int {
+    n := 0
+    for _, e := range st.ListEvents(sid) {
+        if e.Type == typ { n++ }
+    }
+    return n
+}
+
+func TestTTSTimeoutReset(t *testing.T) {
+    st := store.New()
+    d := New(workerws.NewRegistry(), st, 60)
+    clk := clock.NewFake(time.Unix(1700000000, 0))
+    d.SetClock(clk)
+
+    d.OnMessage("s1", workerws.Message{Type: "tts_started", UtteranceID: "u1", TsMs: 1})
+
+    clk.Advance(59 * time.Second)
+    d.OnMessage("s1", workerws.Message{Type: "vad_end", TsMs: 2})
+    if n := countEvents(st, "s1", "tts_timeout_reset"); n != 0 {
+        t.Fatalf("reset fired before timeout (%d)", n)
+    }
+
+    clk.Advance(2 * time.Second)
+    d.OnMessage("s1", workerws.Message{Type: "vad_end", TsMs: 3})
+    if n := countEvents(st, "s1", "tts_timeout_reset"); n != 1 {
+        t.Fatalf("expected one tts_timeout_reset after 61s, got %d", n)
+    }
+
+    // Timer is cleared after firing
+    clk.Advance(time.Hour)
+    d.OnMessage("s1", workerws.Message{Type: "vad_end", TsMs: 4})
+    if n := countEvents(st, "s1", "tts_timeout_reset"); n != 1 {
+        t.Fatalf("reset should fire once per TTS start, got %d", n)
+    }
+}
+
+func TestRecvTimestampsUseClock(t *testing.T) {
+    st := store.New()
+    d := New(workerws.NewRegistry(), st, 60)
+    clk := clock.NewFake(time.UnixMilli(1700000000123))
+    d.SetClock(clk)
+
+    d.OnMessage("s1", workerws.Message{Type: "tts_started", UtteranceID: "u1", TsMs: 1})
+    evs := st.ListEvents("s1")
+    if len(evs) != 1 || evs[0].Payload["recv_ms"] != int64(1700000000123) {
+        t.Fatalf("unexpected events %+v", evs)
+    }
+}
+
+func TestConcurrentMessagesAndEviction(t *testing.T) {
+    st := store.New()
+    d := New(workerws.NewRegistry(), st, 60)
+
+    var wg sync.WaitGroup
+    for i := 0; i < 8; i++ {
+        wg.Add(1)
+        go func() {
+            defer wg.Done()
+            for j := 0; j < 50; j++ {
+                d.OnMessage("s1", workerws.Message{Type: "tts_started", UtteranceID: "u1", TsMs: int64(j)})
+                d.OnMessage("s1", workerws.Message{Type: "tts_first_audio", UtteranceID: "u1", TsMs: int64(j)})
+                d.OnMessage("s1", workerws.Message{Type: "vad_start", TsMs: int64(j), Payload: map[string]any{"source": "candidate_audio"}})
+                d.OnMessage("s1", workerws.Message{Type: "tts_stopped", UtteranceID: "u1", TsMs: int64(j), Payload: map[string]any{"reason": "interrupted"}})
+            }
+        }()
+    }
+    wg.Wait()
+
+    d.EndSession("s1")
+    d.mu.Lock()
+    n := len(d.sessions)
+    d.mu.Unlock()
+    if n != 0 {
+        t.Fatalf("%d sessions kept after EndSession", n)
+    }
+}
+
+func TestStaleDisconnectKeepsReconnectedFloor(t *testing.T) {
+    st := store.New()
+    reg := workerws.NewRegistry()
+    d := New(reg, st, 60)
+    b := bus.New()
+    d.Subscribe(b)
+    sessions := func() int {
+        d.mu.Lock()
+        defer d.mu.Unlock()
+        return len(d.sessions)
+    }
+
+    old, _ := reg.Replace("s1", nil)
+    // The worker reconnects; its floor state exists before the old socket's
+    // read loop notices the close
+    cur, _ := reg.Replace("s1", nil)
+    d.OnMessage("s1", workerws.Message{Type: "tts_started", UtteranceID: "u1", TsMs: 1})
+    reg.Remove("s1", old)
+    bus.Publish(b, workerws.TopicDisconnected, "s1", workerws.Disconnect{Conn: old})
+    if n := sessions(); n != 1 {
+        t.Fatalf("stale disconnect dropped the reconnected floor (%d sessions)", n)
+    }
+
+    reg.Remove("s1", cur)
+    bus.Publish(b, workerws.TopicDisconnected, "s1", workerws.Disconnect{Conn: cur})
+    if n := sessions(); n != 0 {
+        t.Fatalf("%d sessions kept after the current worker left", n)
+    }
+}
+
+func TestSessionEndsWithoutAWorker(t *testing.T) {
+    d := New(workerws.NewRegistry(), store.New(), 60)
+    b := bus.New()
+    d.Subscribe(b)
+    sessions := func() int {
+        d.mu.Lock()
+        defer d.mu.Unlock()
+        return len(d.sessions)
+    }
+
+    // Debug endpoints reach the floor with no worker connection, so no
+    // disconnect ever comes
+    d.OnMessage("s1", workerws.Message{Type: "vad_start", TsMs: 1, Payload: map[string]any{"source": "candidate_audio"}})
+    d.OnMessage("s2", workerws.Message{Type: "vad_start", TsMs: 1, Payload: map[string]any{"source": "candidate_audio"}})
+    bus.Publish(b, bot.TopicExited, "s1", bot.Exited{})
+    if n := sessions(); n != 1 {
+        t.Fatalf("%d sessions kept after s1's bot exited, want 1", n)
+    }
+    bus.Publish(b, api.TopicSessionCompleted, "s2", api.SessionCompleted{Reason: "time_limit"})
+    if n := sessions(); n != 0 {
+        t.Fatalf("%d sessions kept after s2 completed", n)
+    }
+}
+
+func TestInterjectionPausesThenResumes(t *testing.T) {
+    st := store.New()
+    d := New(workerws.NewRegistry(), st, 60)
+    clk := clock.NewFake(time.UnixMilli(1700000000000))
+    d.SetClock(clk)
+    d.SetPolicy(floor.Policy{Interject: floor.InterjectHold, MaxInterjectMs: 1500})
+    speech := map[string]any{"source": "candidate_audio"}
+
+    d.OnMessage("s1", workerws.Message{Type: "tts_started", UtteranceID: "u1", TsMs: 1000})
+    d.OnMessage("s1", workerws.Message{Type: "tts_first_audio", UtteranceID: "u1", TsMs: 1100})
+    d.OnMessage("s1", workerws.Message{Type: "vad_start", TsMs: 2000, Payload: speech})
+    if countEvents(st, "s1", "pause_tts_sent") != 1 || countEvents(st, "s1", "stop_tts_sent") != 0 {
+        t.Fatalf("speech over the agent should pause it, events %+v", st.ListEvents("s1"))
+    }
+
+    // The ack reports the latency of the pause
+    var cmdID string
+    for _, e := range st.ListEvents("s1") {
+        if e.Type == "pause_tts_sent" {
+            cmdID, _ = e.Payload["command_id"].(string)
+        }
+    }
+    clk.Advance(40 * time.Millisecond)
+    d.OnMessage("s1", workerws.Message{Type: "cmd_ack", CommandID: cmdID, TsMs: 2060})
+    var lat map[string]any
+    for _, e := range st.ListEvents("s1") {
+        if e.Type == "tts_pause_latency" {
+            lat = e.Payload
+        }
+    }
+    if lat == nil || lat["command"] != "pause_tts" || lat["worker_ms"] != int64(60) || lat["backend_ms"] != int64(40) {
+        t.Fatalf("tts_pause_latency = %v", lat)
+    }
+
+    d.OnMessage("s1", workerws.Message{Type: "vad_end", TsMs: 2500, Payload: speech})
+    if countEvents(st, "s1", "resume_tts_sent") != 1 {
+        t.Fatalf("a short interjection should resume, events %+v", st.ListEvents("s1"))
+    }
+
+    // Speech that runs past the window stops the agent instead
+    d.OnMessage("s1", workerws.Message{Type: "vad_start", TsMs: 3000, Payload: speech})
+    d.OnMessage("s1", workerws.Message{Type: "vad_end", TsMs: 5000, Payload: speech})
+    if countEvents(st, "s1", "pause_tts_sent") != 2 || countEvents(st, "s1", "resume_tts_sent") != 1 || countEvents(st, "s1", "stop_tts_sent") != 1 {
+        t.Fatalf("a long interjection should stop, events %+v", st.ListEvents("s1"))
+    }
+}
+
+func TestSessionTurnPolicyGuardsAndReprompts(t *testing.T) {
+    st := store.New()
+    if err := st.CreateSession(&types.Session{ID: "s1", TurnPolicy: "polite"}); err != nil {
+        t.Fatal(err)
+    }
+    d := New(workerws.NewRegistry(), st, 60)
+    clk := clock.NewFake(time.UnixMilli(1700000000000))
+    d.SetClock(clk)
+    speech := map[string]any{"source": "candidate_audio"}
+
+    d.OnMessage("s1", workerws.Message{Type: "tts_started", UtteranceID: "u1", TsMs: 1000})
+    d.OnMessage("s1", workerws.Message{Type: "tts_first_audio", UtteranceID: "u1", TsMs: 1100})
+    d.OnMessage("s1", workerws.Message{Type: "vad_start", TsMs: 1300, Payload: speech})
+    if countEvents(st, "s1", "barge_in_guarded") != 1 || countEvents(st, "s1", "stop_tts_sent") != 0 {
+        t.Fatalf("speech in the guard window should be ignored, events %+v", st.ListEvents("s1"))
+    }
+    d.OnMessage("s1", workerws.Message{Type: "vad_end", TsMs: 1500, Payload: speech})
+    d.OnMessage("s1", workerws.Message{Type: "tts_stopped", UtteranceID: "u1", TsMs: 4000, Payload: map[string]any{"reason": "completed"}})
+
+    clk.Advance(9 * time.Second)
+    d.CheckSilence()
+    if n := countEvents(st, "s1", "reprompt_sent"); n != 0 {
+        t.Fatalf("re-prompted after 9s (%d)", n)
+    }
+    clk.Advance(2 * time.Second)
+    d.CheckSilence()
+    d.CheckSilence()
+    if n := countEvents(st, "s1", "reprompt_sent"); n != 1 {
+        t.Fatalf("expected one re-prompt after 11s of silence, got %d", n)
+    }
+
+    // Speech from the candidate cancels the wait
+    d.OnMessage("s1", workerws.Message{Type: "tts_stopped", UtteranceID: "r1", TsMs: 16000, Payload: map[string]any{"reason": "completed"}})
+    d.OnMessage("s1", workerws.Message{Type: "vad_start", TsMs: 17000, Payload: speech})
+    clk.Advance(time.Minute)
+    d.CheckSilence()
+    if n := countEvents(st, "s1", "reprompt_sent"); n != 1 {
+        t.Fatalf("re-prompted while the candidate was speaking (%d)", n)
+    }
+}
+
+func TestCheckSilenceLeavesEndedSessionsOut(t *testing.T) {
+    st := store.New()
+    if err := st.CreateSession(&types.Session{ID: "s1", TurnPolicy: "polite"}); err != nil {
+        t.Fatal(err)
+    }
+    d := New(workerws.NewRegistry(), st, 60)
+    d.SetClock(clock.NewFake(time.UnixMilli(1700000000000)))
+    d.OnMessage("s1", workerws.Message{Type: "tts_started", UtteranceID: "u1", TsMs: 1000})
+    d.EndSession("s1")
+    d.CheckSilence()
+    d.mu.Lock()
+    n := len(d.sessions)
+    d.mu.Unlock()
+    if n != 0 {
+        t.Fatalf("CheckSilence brought back %d ended sessions", n)
+    }
+}
@@ -0,0 +1,300 @@
+package orchestrator
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"yuzu/agent/internal/errdefs"
+)
+
+// admin.go is the runtime management API on the probes port. PUT
+// /admin/prompt and PUT /admin/flow swap the deployment system prompt and
+// interview flow for new sessions, and for live ones with apply_live. Every
+// change is a numbered version; POST .../rollback re-installs an old one as a
+// new version so the history stays linear. The API is off unless
+// ORCH_ADMIN_TOKEN is set and callers send it as a bearer token. GET
+// /admin/thresholds shows the reloadable thresholds' versions (see
+// thresholds.go), and GET /admin/sessions/{id}/prompt a live session's
+// prompt layers (see promptlayers.go).
+
+const (
+	maxAdminPromptLen = 4000
+	maxAdminVersions  = 50
+)
+
+// revision is one version of a managed value.
+type revision[T any] struct {
+	Version int       `json:"version"`
+	At      time.Time `json:"at"`
+	Note    string    `json:"note,omitempty"`
+	Value   T         `json:"value"`
+}
+
+// history keeps the versions of a managed value; the newest is current.
+// The zero value is empty.
+type history[T any] struct {
+	mu   sync.Mutex
+	next int
+	revs []revision[T]
+}
+
+// set installs v as a new current version.
+func (h *history[T]) set(v T, note string, at time.Time) revision[T] {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.setLocked(v, note, at)
+}
+
+// setUnless installs v as a new current version unless same reports the
+// version in force (the zero value and 0 before any set) as unchanged. The
+// check and the install happen under one lock. It reports whether v was
+// installed.
+func (h *history[T]) setUnless(v T, note string, at time.Time, same func(cur T, version int) bool) (revision[T], bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	var cur T
+	version := 0
+	if n := len(h.revs); n > 0 {
+		cur, version = h.revs[n-1].Value, h.revs[n-1].Version
+	}
+	if same(cur, version) {
+		return revision[T]{}, false
+	}
+	return h.setLocked(v, note, at), true
+}
+
+// setLocked installs v. Callers hold h.mu.
+func (h *history[T]) setLocked(v T, note string, at time.Time) revision[T] {
+	h.next++
+	r := revision[T]{Version: h.next, At: at, Note: note, Value: v}
+	h.revs = append(h.revs, r)
+	if n := len(h.revs) - maxAdminVersions; n > 0 {
+		h.revs = append(h.revs[:0:0], h.revs[n:]...)
+	}
+	return r
+}
+
+// current returns the value in force, or the zero value before any set.
+func (h *history[T]) current() (v T, version int) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if len(h.revs) == 0 {
+		return v, 0
+	}
+	r := h.revs[len(h.revs)-1]
+	return r.Value, r.Version
+}
+
+// rollback re-installs version as a new current version.
+func (h *history[T]) rollback(version int, at time.Time) (revision[T], error) {
+	h.mu.Lock()
+	var v T
+	found := false
+	for _, r := range h.revs {
+		if r.Version == version {
+			v, found = r.Value, true
+		}
+	}
+	h.mu.Unlock()
+	if !found {
+		return revision[T]{}, fmt.Errorf("version %d not found", version)
+	}
+	return h.set(v, fmt.Sprintf("rollback to v%d", version), at), nil
+}
+
+func (h *history[T]) list() []revision[T] {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return append([]revision[T](nil), h.revs...)
+}
+
+// applyAdminDefaults gives a new session the current prompt and flow.
+// Callers hold st.mu.
+func (s *Server) applyAdminDefaults(st *sessionState) {
+	st.adminPrompt, _ = s.prompts.current()
+	f, _ := s.flows.current()
+	st.flowState.setFlow(f, s.clock.Now())
+}
+
+// applyLive pushes the current prompt or flow to every open session.
+func (s *Server) applyLive(kind string) int {
+	prompt, _ := s.prompts.current()
+	f, _ := s.flows.current()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, st := range s.sess {
+		st.mu.Lock()
+		if kind == "prompt" {
+			st.adminPrompt = prompt
+		} else if !st.ownFlow {
+			st.flowState.setFlow(f, s.clock.Now())
+		}
+		st.mu.Unlock()
+	}
+	return len(s.sess)
+}
+
+// AdminHandler serves /admin/prompt, /admin/flow and their /rollback
+// endpoints.
+func (s *Server) AdminHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if s.adminToken == "" {
+			http.NotFound(w, r)
+			return
+		}
+		if !adminAuthorized(r, s.adminToken) {
+			errdefs.WriteHTTP(w, &errdefs.AuthError{Reason: "admin token required"})
+			return
+		}
+		path := strings.TrimSuffix(r.URL.Path, "/")
+		switch path {
+		case "/admin/prompt":
+			s.handleAdminPrompt(w, r)
+		case "/admin/flow":
+			s.handleAdminFlow(w, r)
+		case "/admin/thresholds":
+			// Read-only: thresholds change through ORCH_THRESHOLDS_FILE
+			if r.Method != http.MethodGet {
+				http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+				return
+			}
+			writeAdminState(w, &s.thresholds, 0)
+		case "/admin/prompt/rollback", "/admin/flow/rollback":
+			s.handleAdminRollback(w, r, strings.TrimSuffix(strings.TrimPrefix(path, "/admin/"), "/rollback"))
+		default:
+			// /admin/sessions/{id}/prompt
+			rest, _ := strings.CutPrefix(path, "/admin/sessions/")
+			if sid, ok := strings.CutSuffix(rest, "/prompt"); ok && rest != path && sid != "" && !strings.Contains(sid, "/") {
+				s.handleAdminSessionPrompt(w, r, sid)
+				return
+			}
+			http.NotFound(w, r)
+		}
+	})
+}
+
+func adminAuthorized(r *http.Request, token string) bool {
+	a := r.Header.Get("Authorization")
+	if len(a) < 7 || !strings.EqualFold(a[:7], "bearer ") {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(strings.TrimSpace(a[7:])), []byte(token)) == 1
+}
+
+func (s *Server) handleAdminPrompt(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		writeAdminState(w, &s.prompts, 0)
+	case http.MethodPut:
+		var req struct {
+			Prompt    string `json:"prompt"` // empty restores the built prompt
+			Note      string `json:"note"`
+			ApplyLive bool   `json:"apply_live"`
+		}
+		if err := decodeAdmin(r, &req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if len(req.Prompt) > maxAdminPromptLen {
+			http.Error(w, fmt.Sprintf("prompt exceeds %d characters", maxAdminPromptLen), http.StatusBadRequest)
+			return
+		}
+		rev := s.prompts.set(strings.TrimSpace(req.Prompt), req.Note, s.clock.Now())
+		adminChanged(s, w, "prompt", "set", rev.Version, req.ApplyLive, &s.prompts)
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func (s *Server) handleAdminFlow(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		writeAdminState(w, &s.flows, 0)
+	case http.MethodPut:
+		var req struct {
+			Flow      *Flow  `json:"flow"` // null removes the flow
+			Note      string `json:"note"`
+			ApplyLive bool   `json:"apply_live"`
+		}
+		if err := decodeAdmin(r, &req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if req.Flow != nil {
+			if err := req.Flow.Validate(); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+		}
+		rev := s.flows.set(req.Flow, req.Note, s.clock.Now())
+		adminChanged(s, w, "flow", "set", rev.Version, req.ApplyLive, &s.flows)
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func (s *Server) handleAdminRollback(w http.ResponseWriter, r *http.Request, kind string) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	var req struct {
+		Version   int  `json:"version"`
+		ApplyLive bool `json:"apply_live"`
+	}
+	if err := decodeAdmin(r, &req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if kind == "prompt" {
+		adminRollback(s, w, kind, req.Version, req.ApplyLive, &s.prompts)
+	} else {
+		adminRollback(s, w, kind, req.Version, req.ApplyLive, &s.flows)
+	}
+}
+
+func adminRollback[T any](s *Server, w http.ResponseWriter, kind string, version int, live bool, h *history[T]) {
+	rev, err := h.rollback(version, s.clock.Now())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	adminChanged(s, w, kind, "rollback", rev.Version, live, h)
+}
+
+// adminChanged applies a new version, records it and answers with the
+// resulting history.
+func adminChanged[T any](s *Server, w http.ResponseWriter, kind, action string, version int, live bool, h *history[T]) {
+	n := 0
+	if live {
+		n = s.applyLive(kind)
+	}
+	metricAdminChanges.WithLabelValues(kind, action).Inc()
+	log.Printf("[orch] admin %s %s -> v%d apply_live=%t live_sessions=%d", kind, action, version, live, n)
+	writeAdminState(w, h, n)
+}
+
+func writeAdminState[T any](w http.ResponseWriter, h *history[T], liveUpdated int) {
+	_, cur := h.current()
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]any{
+		"current":       cur,
+		"versions":      h.list(),
+		"live_sessions": liveUpdated,
+	})
+}
+
+func decodeAdmin(r *http.Request, v any) error {
+	dec := json.NewDecoder(http.MaxBytesReader(nil, r.Body, 64<<10))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		return fmt.Errorf("invalid body: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,100 @@
+package orchestrator
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"yuzu/agent/internal/clock"
+)
+
+func adminDo(t *testing.T, h http.Handler, method, path, token, body string) (int, map[string]any) {
+	t.Helper()
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	var out map[string]any
+	_ = json.Unmarshal(rec.Body.Bytes(), &out)
+	return rec.Code, out
+}
+
+func TestAdminPromptVersionsAndRollback(t *testing.T) {
+	s := &Server{sess: map[string]*sessionState{}, clock: clock.Real, adminToken: "admin-secret"}
+	h := s.AdminHandler()
+	live := &sessionState{id: "live"}
+	s.sess["live"] = live
+
+	if code, _ := adminDo(t, (&Server{}).AdminHandler(), http.MethodGet, "/admin/prompt", "", ""); code != http.StatusNotFound {
+		t.Errorf("admin API without ORCH_ADMIN_TOKEN = %d, want 404", code)
+	}
+	if code, _ := adminDo(t, h, http.MethodGet, "/admin/prompt", "wrong", ""); code != http.StatusUnauthorized {
+		t.Errorf("wrong token = %d, want 401", code)
+	}
+
+	if code, out := adminDo(t, h, http.MethodPut, "/admin/prompt", "admin-secret", `{"prompt":"You are v1."}`); code != 200 || out["current"] != 1.0 {
+		t.Fatalf("PUT v1 = %d %v", code, out)
+	}
+	if live.adminPrompt != "" {
+		t.Error("prompt reached a live session without apply_live")
+	}
+	if code, out := adminDo(t, h, http.MethodPut, "/admin/prompt", "admin-secret", `{"prompt":"You are v2.","apply_live":true}`); code != 200 || out["live_sessions"] != 1.0 {
+		t.Fatalf("PUT v2 = %d %v", code, out)
+	}
+	if live.adminPrompt != "You are v2." {
+		t.Errorf("live prompt = %q after apply_live", live.adminPrompt)
+	}
+
+	code, out := adminDo(t, h, http.MethodPost, "/admin/prompt/rollback", "admin-secret", `{"version":1}`)
+	if code != 200 || out["current"] != 3.0 || len(out["versions"].([]any)) != 3 {
+		t.Fatalf("rollback = %d %v", code, out)
+	}
+	fresh := &sessionState{id: "new"}
+	s.applyAdminDefaults(fresh)
+	if fresh.adminPrompt != "You are v1." {
+		t.Errorf("new session prompt = %q, want the rolled-back v1", fresh.adminPrompt)
+	}
+	if code, _ := adminDo(t, h, http.MethodPost, "/admin/prompt/rollback", "admin-secret", `{"version":42}`); code != http.StatusNotFound {
+		t.Errorf("rollback to unknown version = %d, want 404", code)
+	}
+}
+
+func TestAdminFlow(t *testing.T) {
+	s := &Server{sess: map[string]*sessionState{}, clock: clock.Real, adminToken: "admin-secret"}
+	h := s.AdminHandler()
+	if code, _ := adminDo(t, h, http.MethodPut, "/admin/flow", "admin-secret", `{"flow":{"name":"screen","stages":[{"id":"a"},{"id":"a"}]}}`); code != http.StatusBadRequest {
+		t.Errorf("duplicate stage ids = %d, want 400", code)
+	}
+	body := `{"flow":{"name":"screen","stages":[{"id":"intro","instructions":"Greet the candidate.","max_turns":1},{"id":"deep","instructions":"Ask about system design."}]}}`
+	if code, out := adminDo(t, h, http.MethodPut, "/admin/flow", "admin-secret", body); code != 200 || out["current"] != 1.0 {
+		t.Fatalf("PUT flow = %d %v", code, out)
+	}
+
+	st := &sessionState{id: "s1"}
+	s.applyAdminDefaults(st)
+	if got := st.flowState.instructions(); got != "Greet the candidate." {
+		t.Fatalf("first stage = %q", got)
+	}
+	s.advanceFlow(st)
+	if got := st.flowState.instructions(); got != "Ask about system design." {
+		t.Errorf("after one answer stage = %q, want deep", got)
+	}
+	s.advanceFlow(st)
+	if st.flowState.stage != 1 {
+		t.Errorf("last stage should not advance, stage = %d", st.flowState.stage)
+	}
+
+	// Removing the flow leaves sessions with plain prompts
+	if code, _ := adminDo(t, h, http.MethodPut, "/admin/flow", "admin-secret", `{"flow":null,"apply_live":true}`); code != 200 {
+		t.Fatalf("PUT null flow = %d", code)
+	}
+	fresh := &sessionState{id: "s2"}
+	s.applyAdminDefaults(fresh)
+	if fresh.flowState.instructions() != "" {
+		t.Error("removed flow still applied")
+	}
+}
@@ -0,0 +1,113 @@
+package orchestrator
+
+import (
+	"log"
+	"time"
+
+	gw "yuzu/agent/internal/orchestrator/pb"
+)
+
+// armack.go checks that the gateway applied an ArmBargeIn as sent. Every
+// ArmBargeIn carries the session's next arm generation, and gateways that
+// list "arm_barge_in_ack" in SessionOpen.capabilities answer with
+// ArmBargeInAck echoing the guard and min RMS they have in force. Values
+// that differ from the ones sent, as when an older gateway clamps them, are
+// logged: barge-in then fires at thresholds the orchestrator didn't pick.
+// An arm without an ack after ORCH_ARM_ACK_TIMEOUT_MS (default 500, 0
+// disables) is re-sent, up to ORCH_ARM_ACK_RETRIES times (default 2); a
+// newer arm replaces the pending one. Acks are counted in
+// orch_arm_barge_in_acks_total{result} and re-sends in
+// orch_arm_barge_in_resends_total{outcome}.
+
+const capArmAck = "arm_barge_in_ack"
+
+// armState is embedded in sessionState.
+type armState struct {
+	armGen     uint64
+	armAcks    bool           // gateway sends ArmBargeInAck
+	armSent    *gw.ArmBargeIn // latest arm, which acks are checked against
+	armUnacked *pendingArm
+}
+
+// pendingArm is an ArmBargeIn awaiting its ack.
+type pendingArm struct {
+	arm     *gw.ArmBargeIn
+	resends int
+	timer   *time.Timer
+}
+
+// newArm issues the next ArmBargeIn and, when the gateway acks arms, arms
+// its re-send. Callers hold st.mu.
+func (s *Server) newArm(st *sessionState, guardMs, minRMS uint32) *gw.ArmBargeIn {
+	st.armGen++
+	arm := &gw.ArmBargeIn{GuardMs: guardMs, MinRms: minRMS, Generation: st.armGen}
+	st.armSent = arm
+	st.clearUnackedArm()
+	if st.armAcks && s.armRetry > 0 {
+		p := &pendingArm{arm: arm}
+		p.timer = time.AfterFunc(s.armRetry, func() { s.resendArm(st, p) })
+		st.armUnacked = p
+	}
+	return arm
+}
+
+// clearUnackedArm forgets the pending arm. Callers hold st.mu.
+func (st *sessionState) clearUnackedArm() {
+	if st.armUnacked != nil {
+		st.armUnacked.timer.Stop()
+		st.armUnacked = nil
+	}
+}
+
+// resendArm sends p again if it is still unacknowledged.
+func (s *Server) resendArm(st *sessionState, p *pendingArm) {
+	st.mu.Lock()
+	if st.armUnacked != p || st.send == nil {
+		st.mu.Unlock()
+		return
+	}
+	if p.resends >= s.armRetries {
+		st.armUnacked = nil
+		st.mu.Unlock()
+		metricArmResends.WithLabelValues("gave_up").Inc()
+		log.Printf("[orch] ArmBargeIn never acked sid=%s gen=%d", st.id, p.arm.GetGeneration())
+		return
+	}
+	p.resends++
+	p.timer = time.AfterFunc(s.armRetry, func() { s.resendArm(st, p) })
+	send := st.send
+	st.mu.Unlock()
+
+	metricArmResends.WithLabelValues("resent").Inc()
+	log.Printf("[orch] re-sending ArmBargeIn sid=%s gen=%d attempt=%d", st.id, p.arm.GetGeneration(), p.resends+1)
+	send(&gw.OrchestratorCommand{SessionId: st.id, Cmd: &gw.OrchestratorCommand_ArmBargeIn{ArmBargeIn: p.arm}})
+}
+
+// handleArmAck compares what the gateway applied with what was sent. Only
+// the latest generation is checked; older ones were superseded anyway.
+func (s *Server) handleArmAck(st *sessionState, ack *gw.ArmBargeInAck) {
+	st.mu.Lock()
+	sent := st.armSent
+	if st.armUnacked != nil && st.armUnacked.arm.GetGeneration() == ack.GetGeneration() {
+		st.clearUnackedArm()
+	}
+	st.mu.Unlock()
+
+	result := "applied"
+	switch {
+	case sent == nil || sent.GetGeneration() != ack.GetGeneration():
+		result = "stale"
+	case !armMatches(sent, ack):
+		result = "mismatch"
+		log.Printf("[orch] ArmBargeIn applied differently sid=%s gen=%d sent guard_ms=%d min_rms=%d applied guard_ms=%d min_rms=%d",
+			st.id, ack.GetGeneration(), sent.GetGuardMs(), sent.GetMinRms(), ack.GetGuardMs(), ack.GetMinRms())
+	}
+	metricArmAcks.WithLabelValues(result).Inc()
+}
+
+// armMatches reports whether ack applied arm's thresholds; zero fields in
+// arm leave the gateway's value alone and match anything.
+func armMatches(arm *gw.ArmBargeIn, ack *gw.ArmBargeInAck) bool {
+	return (arm.GetGuardMs() == 0 || arm.GetGuardMs() == ack.GetGuardMs()) &&
+		(arm.GetMinRms() == 0 || arm.GetMinRms() == ack.GetMinRms())
+}
@@ -0,0 +1,73 @@
+package orchestrator
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	"yuzu/agent/internal/clock"
+	gw "yuzu/agent/internal/orchestrator/pb"
+)
+
+func TestArmBargeInResentUntilAcked(t *testing.T) {
+	s := &Server{sess: map[string]*sessionState{}, clock: clock.Real, armRetry: 10 * time.Millisecond, armRetries: 1}
+	st := s.getOrCreateSession("s1")
+	cmds := make(chan *gw.OrchestratorCommand, 4)
+	st.send = func(c *gw.OrchestratorCommand) { cmds <- c }
+	st.armAcks = true
+
+	st.mu.Lock()
+	arm := s.newArm(st, 1200, 900)
+	st.mu.Unlock()
+	select {
+	case c := <-cmds:
+		if c.GetArmBargeIn() != arm || arm.GetGeneration() != 1 {
+			t.Fatalf("re-sent %v, want %v", c, arm)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("unacked ArmBargeIn not re-sent")
+	}
+
+	s.handleArmAck(st, &gw.ArmBargeInAck{Generation: 1, GuardMs: 1200, MinRms: 900})
+	st.mu.Lock()
+	pending := st.armUnacked
+	st.mu.Unlock()
+	if pending != nil {
+		t.Fatal("ack left the arm pending")
+	}
+	select {
+	case c := <-cmds:
+		t.Fatalf("re-sent %v after the ack", c)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestArmBargeInAckMismatch(t *testing.T) {
+	s := &Server{sess: map[string]*sessionState{}, clock: clock.Real}
+	st := s.getOrCreateSession("s1")
+	st.mu.Lock()
+	s.newArm(st, 1200, 900)
+	s.newArm(st, 0, 2500)
+	st.mu.Unlock()
+
+	mismatch := testutil.ToFloat64(metricArmAcks.WithLabelValues("mismatch"))
+	stale := testutil.ToFloat64(metricArmAcks.WithLabelValues("stale"))
+	applied := testutil.ToFloat64(metricArmAcks.WithLabelValues("applied"))
+
+	// An older gateway clamped min_rms to 2000
+	s.handleArmAck(st, &gw.ArmBargeInAck{Generation: 2, GuardMs: 1200, MinRms: 2000})
+	// The first arm was superseded, and a zero guard keeps whatever is in force
+	s.handleArmAck(st, &gw.ArmBargeInAck{Generation: 1, GuardMs: 1200, MinRms: 900})
+	s.handleArmAck(st, &gw.ArmBargeInAck{Generation: 2, GuardMs: 700, MinRms: 2500})
+
+	if d := testutil.ToFloat64(metricArmAcks.WithLabelValues("mismatch")) - mismatch; d != 1 {
+		t.Errorf("mismatches = %v, want 1", d)
+	}
+	if d := testutil.ToFloat64(metricArmAcks.WithLabelValues("stale")) - stale; d != 1 {
+		t.Errorf("stale acks = %v, want 1", d)
+	}
+	if d := testutil.ToFloat64(metricArmAcks.WithLabelValues("applied")) - applied; d != 1 {
+		t.Errorf("applied acks = %v, want 1", d)
+	}
+}
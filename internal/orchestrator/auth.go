@@ -0,0 +1,86 @@
+package orchestrator
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc/metadata"
+
+	"yuzu/agent/internal/auth"
+	"yuzu/agent/internal/errdefs"
+	"yuzu/agent/internal/secrets"
+)
+
+// auth.go authenticates gateways on the GatewayControl stream. A gateway
+// presents the per-session worker token minted by the API server (the same
+// HMAC token it uses for /ws/worker) as "authorization: Bearer <token>"
+// metadata; SessionOpen binds the stream to the session the token was issued
+// for, and events for any other session are dropped.
+
+// gatewayAuth validates stream metadata. required=false (ORCH_REQUIRE_AUTH)
+// lets local tools connect without a token.
+type gatewayAuth struct {
+	secret   string
+	required bool
+	skewSecs int
+	now      func() time.Time
+}
+
+func gatewayAuthFromEnv() gatewayAuth {
+	secret := secrets.Get("ORCH_AUTH_SECRET")
+	if secret == "" {
+		secret = secrets.Get("WORKER_TOKEN_SECRET")
+	}
+	return gatewayAuth{
+		secret:   secret,
+		required: envBool("ORCH_REQUIRE_AUTH", true),
+		skewSecs: envInt("WORKER_TOKEN_SKEW_SECONDS", 60),
+		now:      time.Now,
+	}
+}
+
+// check validates the caller's token for sid and records the outcome.
+func (a gatewayAuth) check(ctx context.Context, sid string) error {
+	if !a.required {
+		return nil
+	}
+	reason := a.reject(ctx, sid)
+	metricGatewayAuth.WithLabelValues(reason).Inc()
+	if reason == "ok" {
+		return nil
+	}
+	log.Printf("[orch] gateway auth rejected sid=%s reason=%s", sid, reason)
+	return &errdefs.AuthError{Reason: reason}
+}
+
+// reject returns "ok" or the metric label for why the token was refused.
+func (a gatewayAuth) reject(ctx context.Context, sid string) string {
+	if a.secret == "" {
+		return "not_configured"
+	}
+	md, _ := metadata.FromIncomingContext(ctx)
+	var token string
+	for _, v := range md.Get("authorization") {
+		if t, ok := strings.CutPrefix(v, "Bearer "); ok {
+			token = t
+		}
+	}
+	if token == "" {
+		return "missing_token"
+	}
+	_, _, err := auth.ValidateWorkerToken(a.secret, token, sid, a.now(), a.skewSecs)
+	switch {
+	case err == nil:
+		return "ok"
+	case errors.Is(err, auth.ErrTokenSID):
+		return "session_mismatch"
+	case errors.Is(err, auth.ErrTokenExp):
+		return "expired"
+	case errors.Is(err, auth.ErrTokenSig):
+		return "bad_signature"
+	}
+	return "bad_format"
+}
@@ -0,0 +1,92 @@
+package orchestrator
+
+import (
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"yuzu/agent/internal/auth"
+	gw "yuzu/agent/internal/orchestrator/pb"
+)
+
+// scriptStream replays events on a context carrying the given metadata.
+type scriptStream struct {
+	grpc.ServerStream
+	ctx    context.Context
+	events []*gw.GatewayEvent
+	sent   []*gw.OrchestratorCommand
+}
+
+func (f *scriptStream) Context() context.Context             { return f.ctx }
+func (f *scriptStream) Send(c *gw.OrchestratorCommand) error { f.sent = append(f.sent, c); return nil }
+func (f *scriptStream) Recv() (*gw.GatewayEvent, error) {
+	if len(f.events) == 0 {
+		return nil, io.EOF
+	}
+	ev := f.events[0]
+	f.events = f.events[1:]
+	return ev, nil
+}
+
+func openEvent(sid string) *gw.GatewayEvent {
+	return &gw.GatewayEvent{SessionId: sid, Evt: &gw.GatewayEvent_SessionOpen{SessionOpen: &gw.SessionOpen{SessionId: sid}}}
+}
+
+func TestGatewayAuth(t *testing.T) {
+	const secret = "test-secret"
+	now := time.Unix(1_700_000_000, 0)
+	tok, _ := auth.GenerateWorkerToken(secret, "s1", now.Add(time.Hour).Unix())
+	withToken := func(tok string) context.Context {
+		return metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", "Bearer "+tok))
+	}
+	newServer := func() *Server {
+		s := NewServer()
+		s.auth = gatewayAuth{secret: secret, required: true, skewSecs: 60, now: func() time.Time { return now }}
+		return s
+	}
+
+	for name, ctx := range map[string]context.Context{
+		"missing token": context.Background(),
+		"wrong session": withToken(tok),
+	} {
+		sid := "s1"
+		if name == "wrong session" {
+			sid = "s2"
+		}
+		s := newServer()
+		err := s.Session(&scriptStream{ctx: ctx, events: []*gw.GatewayEvent{openEvent(sid)}})
+		if status.Code(err) != codes.Unauthenticated {
+			t.Errorf("%s: err = %v, want Unauthenticated", name, err)
+		}
+		if len(s.sess) != 0 {
+			t.Errorf("%s: session state created for rejected stream", name)
+		}
+	}
+
+	// A valid token binds the stream to its session; events for others are dropped
+	s := newServer()
+	feature := &gw.GatewayEvent{SessionId: "s2", Evt: &gw.GatewayEvent_Feature{Feature: &gw.Feature{}}}
+	st := &scriptStream{ctx: withToken(tok), events: []*gw.GatewayEvent{openEvent("s1"), feature}}
+	if err := s.Session(st); err != io.EOF {
+		t.Fatalf("Session = %v, want io.EOF after script", err)
+	}
+	if _, ok := s.sess["s1"]; !ok {
+		t.Error("authenticated session not opened")
+	}
+	if _, ok := s.sess["s2"]; ok {
+		t.Error("event for another session was processed")
+	}
+
+	// Dev toggle: no token needed
+	s = newServer()
+	s.auth.required = false
+	if err := s.Session(&scriptStream{ctx: context.Background(), events: []*gw.GatewayEvent{openEvent("s3")}}); err != io.EOF {
+		t.Errorf("auth disabled: Session = %v, want io.EOF", err)
+	}
+}
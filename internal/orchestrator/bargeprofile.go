@@ -0,0 +1,122 @@
+package orchestrator
+
+import (
+	"log"
+	"slices"
+	"strconv"
+	"time"
+
+	gw "yuzu/agent/internal/orchestrator/pb"
+	"yuzu/agent/internal/types"
+)
+
+// bargeprofile.go suggests the barge-in profile (types.BargeInProfiles)
+// that fits the candidate's audio setup. For the first
+// ORCH_PROFILE_SUGGEST_MS of features (default 8000, 0 disables) the
+// orchestrator keeps the RMS of every frame, split by whether the agent was
+// playing. The quiet frames' low percentile is the noise floor; the median
+// of the playing frames is how loud the agent comes back through the mic.
+// Loud echo means speakers next to the mic, a loud floor a phone in a noisy
+// room, and neither a headset.
+//
+// The suggestion goes to the gateway as BargeInProfileSuggestion, which the
+// API logs as barge_in_profile_suggested. A session created with
+// barge_in_profile "auto" adopts it: its thresholds switch to the
+// profile's, are kept across threshold reloads, and ArmBargeIn is re-sent.
+// Counted in orch_barge_in_profile_suggestions_total{profile,applied}.
+
+const (
+	// Enough quiet audio to trust the floor: one second of frames
+	profileMinQuietFrames = 50
+	// Frames kept per kind; the window is 400 frames at the default length
+	profileMaxFrames = 1000
+
+	echoLaptopRMS   = 900 // agent audible in the mic above this
+	floorPhoneRMS   = 350 // background above this
+	floorPercentile = 0.2
+)
+
+// profileState is embedded in sessionState.
+type profileState struct {
+	bargeProfile string    // SessionStyle.barge_in_profile; "" if none
+	ownHangover  bool      // hangover set by the session; kept on threshold reloads
+	sampleFrom   time.Time // first feature sampled
+	quiet        []float64 // RMS while the agent was silent
+	echo         []float64 // RMS while the agent was playing
+	suggested    bool
+}
+
+// openProfile applies the session's profile settings. Callers hold st.mu.
+func (st *sessionState) openProfile(style *gw.SessionStyle) {
+	st.bargeProfile = style.GetBargeInProfile()
+	if v := style.GetBargeInHangover(); v > 0 {
+		st.hangover = int(v)
+		st.ownHangover = true
+	}
+}
+
+// sampleProfile records one feature and, once the window is over, returns
+// the suggestion to send (and the thresholds to arm, for "auto" sessions).
+// Callers hold st.mu.
+func (s *Server) sampleProfile(st *sessionState, rms float64, now time.Time) []*gw.OrchestratorCommand {
+	if s.profileWindow <= 0 || st.suggested {
+		return nil
+	}
+	if st.sampleFrom.IsZero() {
+		st.sampleFrom = now
+	}
+	if st.playing != "" {
+		if len(st.echo) < profileMaxFrames {
+			st.echo = append(st.echo, rms)
+		}
+	} else if len(st.quiet) < profileMaxFrames {
+		st.quiet = append(st.quiet, rms)
+	}
+	if now.Sub(st.sampleFrom) < s.profileWindow || len(st.quiet) < profileMinQuietFrames {
+		return nil
+	}
+
+	floor := percentile(st.quiet, floorPercentile)
+	echo := percentile(st.echo, 0.5)
+	st.suggested = true
+	st.quiet, st.echo = nil, nil
+	sug := &gw.BargeInProfileSuggestion{
+		Profile:       suggestProfile(floor, echo),
+		Current:       st.bargeProfile,
+		NoiseFloorRms: float32(floor),
+		EchoRms:       float32(echo),
+		Applied:       st.bargeProfile == types.BargeInProfileAuto,
+	}
+	cmds := []*gw.OrchestratorCommand{{SessionId: st.id, Cmd: &gw.OrchestratorCommand_ProfileSuggestion{ProfileSuggestion: sug}}}
+	if sug.Applied {
+		p := types.BargeInProfiles[sug.Profile]
+		st.minRMS, st.guardMs, st.hangover = float64(p.MinRMS), uint32(p.GuardMs), p.Hangover
+		st.ownMinRMS, st.ownGuard, st.ownHangover = true, true, true
+		cmds = append(cmds, &gw.OrchestratorCommand{SessionId: st.id, Cmd: &gw.OrchestratorCommand_ArmBargeIn{ArmBargeIn: s.newArm(st, st.guardMs, uint32(p.MinRMS))}})
+	}
+	metricBargeInProfileSuggestions.WithLabelValues(sug.Profile, strconv.FormatBool(sug.Applied)).Inc()
+	log.Printf("[orch] barge-in profile suggested sid=%s profile=%s current=%q floor=%.1f echo=%.1f applied=%t", st.id, sug.Profile, sug.Current, floor, echo, sug.Applied)
+	return cmds
+}
+
+// suggestProfile maps the measured floor and echo to a profile name.
+func suggestProfile(floor, echo float64) string {
+	switch {
+	case echo >= echoLaptopRMS:
+		return "laptop-speakers"
+	case floor >= floorPhoneRMS:
+		return "phone"
+	default:
+		return "headset"
+	}
+}
+
+// percentile returns the p-th (0..1) value of xs, 0 if xs is empty. xs is
+// sorted in place.
+func percentile(xs []float64, p float64) float64 {
+	if len(xs) == 0 {
+		return 0
+	}
+	slices.Sort(xs)
+	return xs[int(p*float64(len(xs)-1))]
+}
@@ -0,0 +1,85 @@
+package orchestrator
+
+import (
+	"testing"
+	"time"
+
+	"yuzu/agent/internal/clock"
+	gw "yuzu/agent/internal/orchestrator/pb"
+)
+
+func TestBargeInProfileSuggestion(t *testing.T) {
+	t0 := time.Unix(1700000000, 0)
+	s := &Server{sess: map[string]*sessionState{}, clock: clock.NewFake(t0), vadSource: "gateway", profileWindow: 8 * time.Second}
+	// feed sends 10 s of 20 ms features, the agent playing during the first
+	// half of each second at echo and silent at floor otherwise.
+	feed := func(st *sessionState, floor, echo float64) *fakeStream {
+		fs := &fakeStream{}
+		for i := 0; i < 500; i++ {
+			rms := floor
+			st.mu.Lock()
+			st.playing = ""
+			if i%50 < 25 && echo > 0 {
+				st.playing, rms = "a1", echo
+			}
+			st.mu.Unlock()
+			s.processFeature(st, rms, t0.Add(time.Duration(i)*20*time.Millisecond), st.id, fs)
+		}
+		return fs
+	}
+	suggestions := func(fs *fakeStream) (out []*gw.BargeInProfileSuggestion) {
+		for _, c := range fs.sent {
+			if p := c.GetProfileSuggestion(); p != nil {
+				out = append(out, p)
+			}
+		}
+		return out
+	}
+
+	// An auto session adopts the suggestion
+	auto := s.getOrCreateSession("auto")
+	s.handleSessionOpen(auto, "auto", "", &gw.SessionStyle{BargeInProfile: "auto"}, &fakeStream{})
+	fs := feed(auto, 100, 1500)
+	got := suggestions(fs)
+	if len(got) != 1 || got[0].GetProfile() != "laptop-speakers" || !got[0].GetApplied() || got[0].GetNoiseFloorRms() != 100 {
+		t.Fatalf("auto suggestions = %v", got)
+	}
+	if auto.minRMS != 1800 || auto.guardMs != 1200 || auto.hangover != 25 {
+		t.Errorf("auto thresholds = %v/%d/%d", auto.minRMS, auto.guardMs, auto.hangover)
+	}
+	if arm := fs.sent[len(fs.sent)-1].GetArmBargeIn(); arm.GetMinRms() != 1800 || arm.GetGuardMs() != 1200 {
+		t.Errorf("last command = %v, want the profile's ArmBargeIn", fs.sent[len(fs.sent)-1])
+	}
+
+	// A chosen profile is only told it may be wrong
+	head := s.getOrCreateSession("head")
+	s.handleSessionOpen(head, "head", "", &gw.SessionStyle{BargeInProfile: "headset", BargeInMinRms: 600, BargeInHangover: 15}, &fakeStream{})
+	if head.hangover != 15 {
+		t.Errorf("hangover = %d, want the session's 15", head.hangover)
+	}
+	got = suggestions(feed(head, 500, 0))
+	if len(got) != 1 || got[0].GetProfile() != "phone" || got[0].GetApplied() || got[0].GetCurrent() != "headset" {
+		t.Fatalf("headset suggestions = %v", got)
+	}
+	if head.minRMS != 600 {
+		t.Errorf("minRMS = %v, want the session's 600", head.minRMS)
+	}
+}
+
+func TestSuggestProfile(t *testing.T) {
+	cases := []struct {
+		floor, echo float64
+		want        string
+	}{
+		{80, 0, "headset"},
+		{80, 200, "headset"},
+		{400, 0, "phone"},
+		{80, 1200, "laptop-speakers"},
+		{400, 1200, "laptop-speakers"},
+	}
+	for _, c := range cases {
+		if got := suggestProfile(c.floor, c.echo); got != c.want {
+			t.Errorf("suggestProfile(%v, %v) = %s, want %s", c.floor, c.echo, got, c.want)
+		}
+	}
+}
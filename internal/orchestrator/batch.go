@@ -0,0 +1,125 @@
+package orchestrator
+
+import (
+	"log"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	gw "yuzu/agent/internal/orchestrator/pb"
+)
+
+// batch.go groups the commands sent on one gateway stream within
+// ORCH_COMMAND_BATCH_MS (default 0, off) into a single CommandBatch, so a
+// long reply's captions, token deltas and StartTTS cost fewer stream writes.
+// Only gateways that list "command_batch" in SessionOpen.capabilities get
+// batches; others see one command per message as before. A batch is sent
+// when the window closes or it holds ORCH_COMMAND_BATCH_MAX (default 32)
+// commands. Commands that must act at once (StopTTS, StopAll, Ack, and a
+// BeginListening that stops TTS) flush what is pending and go out right
+// behind it, so order is kept. When the stream's handler returns, what is
+// still pending is sent if the stream takes it and dropped otherwise, the
+// window timer is stopped, and later sends fail.
+
+const capCommandBatch = "command_batch"
+
+func hasCapability(caps []string, want string) bool {
+	for _, c := range caps {
+		if c == want {
+			return true
+		}
+	}
+	return false
+}
+
+// urgentCommand reports whether cmd must not wait for the batch window.
+func urgentCommand(cmd *gw.OrchestratorCommand) bool {
+	switch c := cmd.Cmd.(type) {
+	case *gw.OrchestratorCommand_StopTts, *gw.OrchestratorCommand_StopAll, *gw.OrchestratorCommand_Ack:
+		return true
+	case *gw.OrchestratorCommand_BeginListening:
+		return c.BeginListening.GetStopTts() != nil
+	}
+	return false
+}
+
+// errStreamEnded is returned by sends after the stream's handler returned.
+var errStreamEnded = status.Error(codes.Unavailable, "gateway stream ended")
+
+// end flushes or drops the pending batch, stops its timer, and refuses
+// further sends. Session calls it on return.
+func (ss *serialStream) end() {
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+	if ss.ended {
+		return
+	}
+	ss.ended = true
+	n := len(ss.pending)
+	if err := ss.flush(); err != nil && n > 0 {
+		log.Printf("[orch] dropping %d batched command(s) at stream end: %v", n, err)
+	}
+}
+
+// enableBatching starts batching commands sent on the stream.
+func (ss *serialStream) enableBatching(window time.Duration, max int) {
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+	if max < 2 {
+		max = 2
+	}
+	ss.batchWindow, ss.batchMax = window, max
+}
+
+// queue adds cmd to the pending batch. An error is from sending an earlier
+// batch or cmd itself; commands left waiting report theirs in the log.
+// Callers hold ss.mu.
+func (ss *serialStream) queue(cmd *gw.OrchestratorCommand) error {
+	if urgentCommand(cmd) {
+		err := ss.flush()
+		if serr := ss.GatewayControl_SessionServer.Send(cmd); serr != nil {
+			return serr
+		}
+		return err
+	}
+	ss.pending = append(ss.pending, cmd)
+	if len(ss.pending) >= ss.batchMax {
+		return ss.flush()
+	}
+	if ss.flushTimer == nil {
+		ss.flushTimer = time.AfterFunc(ss.batchWindow, ss.flushWindow)
+	}
+	return nil
+}
+
+// flushWindow sends the pending batch when its window closes.
+func (ss *serialStream) flushWindow() {
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+	if err := ss.flush(); err != nil {
+		log.Printf("[orch] send batch failed: %v", err)
+	}
+}
+
+// flush sends the pending commands: one alone as itself, more as a
+// CommandBatch. Callers hold ss.mu.
+func (ss *serialStream) flush() error {
+	if ss.flushTimer != nil {
+		ss.flushTimer.Stop()
+		ss.flushTimer = nil
+	}
+	pending := ss.pending
+	ss.pending = nil
+	switch len(pending) {
+	case 0:
+		return nil
+	case 1:
+		return ss.GatewayControl_SessionServer.Send(pending[0])
+	}
+	metricCommandBatch.Observe(float64(len(pending)))
+	return ss.GatewayControl_SessionServer.Send(&gw.OrchestratorCommand{
+		SessionId: pending[0].GetSessionId(),
+		Cmd:       &gw.OrchestratorCommand_Batch{Batch: &gw.CommandBatch{Commands: pending}},
+	})
+}
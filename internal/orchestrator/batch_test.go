@@ -0,0 +1,97 @@
+package orchestrator
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"yuzu/agent/internal/chaos"
+	gw "yuzu/agent/internal/orchestrator/pb"
+)
+
+func TestCommandBatching(t *testing.T) {
+	fs := &fakeStream{}
+	ss := &serialStream{GatewayControl_SessionServer: fs}
+	caption := func(text string) *gw.OrchestratorCommand {
+		return &gw.OrchestratorCommand{SessionId: "s1", Cmd: &gw.OrchestratorCommand_Caption{Caption: &gw.Caption{Text: text}}}
+	}
+
+	// Off until enabled: one message per command
+	_ = ss.Send(caption("a"))
+	if len(fs.sent) != 1 || fs.sent[0].GetCaption() == nil {
+		t.Fatalf("unbatched: %v", fs.sent)
+	}
+
+	// An urgent command flushes what is pending ahead of itself
+	fs.sent = nil
+	ss.enableBatching(time.Hour, 3)
+	_ = ss.Send(caption("b"))
+	_ = ss.Send(caption("c"))
+	if len(fs.sent) != 0 {
+		t.Fatalf("sent before the window closed: %v", fs.sent)
+	}
+	_ = ss.Send(&gw.OrchestratorCommand{SessionId: "s1", Cmd: &gw.OrchestratorCommand_StopTts{StopTts: &gw.StopTTS{Reason: "barge_in"}}})
+	if len(fs.sent) != 2 || len(fs.sent[0].GetBatch().GetCommands()) != 2 || fs.sent[0].GetSessionId() != "s1" ||
+		fs.sent[0].GetBatch().GetCommands()[1].GetCaption().GetText() != "c" || fs.sent[1].GetStopTts() == nil {
+		t.Fatalf("urgent flush: %v", fs.sent)
+	}
+
+	// A full batch goes at once
+	fs.sent = nil
+	for _, text := range []string{"d", "e", "f"} {
+		_ = ss.Send(caption(text))
+	}
+	if len(fs.sent) != 1 || len(fs.sent[0].GetBatch().GetCommands()) != 3 {
+		t.Fatalf("full batch: %v", fs.sent)
+	}
+
+	// The window closing sends a lone command as itself
+	fs.sent = nil
+	ss.enableBatching(5*time.Millisecond, 3)
+	_ = ss.Send(caption("g"))
+	time.Sleep(50 * time.Millisecond)
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+	if len(fs.sent) != 1 || fs.sent[0].GetCaption().GetText() != "g" {
+		t.Fatalf("window flush: %v", fs.sent)
+	}
+}
+
+func TestBatchEndsWithTheStream(t *testing.T) {
+	fs := &fakeStream{}
+	ss := &serialStream{GatewayControl_SessionServer: fs}
+	ss.enableBatching(time.Hour, 10)
+	_ = ss.Send(&gw.OrchestratorCommand{SessionId: "s1", Cmd: &gw.OrchestratorCommand_Caption{Caption: &gw.Caption{Text: "a"}}})
+
+	// What is pending goes out, the window timer stops
+	ss.end()
+	if len(fs.sent) != 1 || ss.flushTimer != nil || len(ss.pending) != 0 {
+		t.Fatalf("at end sent %v, timer %v, pending %v", fs.sent, ss.flushTimer, ss.pending)
+	}
+	// Late senders (LLM, timers) get an error instead of a dead stream
+	if err := ss.Send(&gw.OrchestratorCommand{SessionId: "s1", Cmd: &gw.OrchestratorCommand_Ack{Ack: &gw.Ack{}}}); err != errStreamEnded || len(fs.sent) != 1 {
+		t.Fatalf("send after end: %v, sent %v", err, fs.sent)
+	}
+}
+
+// ctxStream gives fakeStream the context chaos delays wait on.
+type ctxStream struct{ *fakeStream }
+
+func (ctxStream) Context() context.Context { return context.Background() }
+
+func TestSendChaos(t *testing.T) {
+	fs := &fakeStream{}
+	cmd := &gw.OrchestratorCommand{SessionId: "s1", Cmd: &gw.OrchestratorCommand_Caption{Caption: &gw.Caption{Text: "a"}}}
+
+	dropping := &serialStream{GatewayControl_SessionServer: ctxStream{fs}, chaos: chaos.New(chaos.TargetOrchSend, 0, 1, 0, 0)}
+	if err := dropping.Send(cmd); err != nil || len(fs.sent) != 0 {
+		t.Fatalf("dropped send: err=%v sent=%v", err, fs.sent)
+	}
+	failing := &serialStream{GatewayControl_SessionServer: ctxStream{fs}, chaos: chaos.New(chaos.TargetOrchSend, 0, 0, 1, 0)}
+	if err := failing.Send(cmd); status.Code(err) != codes.Unavailable || len(fs.sent) != 0 {
+		t.Fatalf("failed send: err=%v sent=%v", err, fs.sent)
+	}
+}
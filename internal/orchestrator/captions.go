@@ -0,0 +1,47 @@
+package orchestrator
+
+import (
+	gw "yuzu/agent/internal/orchestrator/pb"
+)
+
+// captions.go streams live captions to sessions that asked for them
+// (SessionStyle.captions, or ORCH_CAPTIONS for every session). The gateway
+// relays each Caption to the room so the front-end can render the
+// candidate's words as they are recognised and the agent's sentences as
+// they are sent. Finals dropped as playback tail are echo and get none.
+// Interim captions carry STT's split of the text into a committed prefix,
+// which only grows, and a volatile suffix, so the front-end can draw the
+// settled words steadily.
+
+func captionCmd(sid, role, text string, final bool, turnID, utteranceID string) *gw.OrchestratorCommand {
+	metricCaptions.WithLabelValues(role).Inc()
+	return &gw.OrchestratorCommand{SessionId: sid, Cmd: &gw.OrchestratorCommand_Caption{
+		Caption: &gw.Caption{Role: role, Text: text, Final: final, TurnId: turnID, UtteranceId: utteranceID},
+	}}
+}
+
+// captionInterim sends the candidate's interim as a Caption when the
+// session has captions on. Callers must not hold st.mu.
+func (s *Server) captionInterim(st *sessionState, in *gw.TranscriptInterim, send func(*gw.OrchestratorCommand)) {
+	st.mu.Lock()
+	on := st.captions
+	st.mu.Unlock()
+	if !on || in.GetText() == "" {
+		return
+	}
+	cmd := captionCmd(st.id, roleCandidate, in.GetText(), false, in.GetTurnId(), in.GetUtteranceId())
+	cmd.GetCaption().CommittedText = in.GetCommittedText()
+	cmd.GetCaption().VolatileText = in.GetVolatileText()
+	send(cmd)
+}
+
+// caption sends a Caption when the session has captions on. Callers must
+// not hold st.mu.
+func (s *Server) caption(st *sessionState, role, text string, final bool, turnID, utteranceID string, send func(*gw.OrchestratorCommand)) {
+	st.mu.Lock()
+	on := st.captions
+	st.mu.Unlock()
+	if on && text != "" {
+		send(captionCmd(st.id, role, text, final, turnID, utteranceID))
+	}
+}
@@ -0,0 +1,66 @@
+package orchestrator
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"yuzu/agent/internal/clock"
+	gw "yuzu/agent/internal/orchestrator/pb"
+)
+
+func TestCaptionsFollowSessionStyle(t *testing.T) {
+	s := &Server{sess: map[string]*sessionState{}, clock: clock.NewFake(time.Unix(1700000000, 0)), speakerPolicy: "1"}
+	off := &sessionState{id: "off"}
+	s.handleSessionOpen(off, "off", "", &gw.SessionStyle{}, &fakeStream{})
+	on := &sessionState{id: "on"}
+	s.handleSessionOpen(on, "on", "", &gw.SessionStyle{Captions: true}, &fakeStream{})
+
+	var sent []*gw.OrchestratorCommand
+	send := func(c *gw.OrchestratorCommand) { sent = append(sent, c) }
+	s.caption(off, roleCandidate, "hello", false, "t1", "t1-u", send)
+	if len(sent) != 0 {
+		t.Fatalf("captions off sent %v", sent)
+	}
+
+	s.caption(on, roleCandidate, "hello wor", false, "t1", "t1-u", send)
+	// A diarized panelist's final is captioned but not answered
+	s.routeTranscriptFinal(context.Background(), on, "on", &gw.TranscriptFinal{Text: "Over to you.", TurnId: "t1", UtteranceId: "t1-u", Speaker: 2}, send)
+	on.mu.Lock()
+	cmds := s.agentSpeech(on, &gw.StartTTS{Text: "Thanks.", TurnId: "t1", UtteranceId: "t1-a1"})
+	on.mu.Unlock()
+	sent = append(sent, cmds...)
+
+	want := []struct {
+		role, text string
+		final      bool
+	}{
+		{roleCandidate, "hello wor", false},
+		{roleInterviewer, "Over to you.", true},
+		{roleAgent, "Thanks.", true},
+	}
+	if len(sent) != len(want)+1 || sent[len(sent)-1].GetStartTts() == nil {
+		t.Fatalf("sent %v, want %d captions then StartTTS", sent, len(want))
+	}
+	for i, w := range want {
+		c := sent[i].GetCaption()
+		if c.GetRole() != w.role || c.GetText() != w.text || c.GetFinal() != w.final || c.GetTurnId() != "t1" {
+			t.Errorf("caption %d = %v, want %+v", i, c, w)
+		}
+	}
+}
+
+func TestInterimCaptionCarriesCommittedText(t *testing.T) {
+	s := &Server{sess: map[string]*sessionState{}, clock: clock.NewFake(time.Unix(1700000000, 0))}
+	on := &sessionState{id: "on", captions: true}
+	var sent []*gw.OrchestratorCommand
+	s.captionInterim(on, &gw.TranscriptInterim{Text: "tell me about", CommittedText: "tell me", VolatileText: "about", TurnId: "t1", UtteranceId: "t1-u"},
+		func(c *gw.OrchestratorCommand) { sent = append(sent, c) })
+	if len(sent) != 1 {
+		t.Fatalf("sent %v, want one caption", sent)
+	}
+	c := sent[0].GetCaption()
+	if c.GetText() != "tell me about" || c.GetCommittedText() != "tell me" || c.GetVolatileText() != "about" || c.GetFinal() {
+		t.Fatalf("caption = %v", c)
+	}
+}
@@ -0,0 +1,288 @@
+package orchestrator
+
+import (
+	"encoding/json"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	gw "yuzu/agent/internal/orchestrator/pb"
+)
+
+// close.go ends sessions. The gateway sends SessionClose when the bot leaves,
+// after flushing STT so the last final has already arrived. A stream that
+// drops without one gets closeGrace to reconnect (the gateway re-sends
+// SessionOpen) before the session is finalized the same way, so sessions
+// neither linger in memory nor vanish without a record.
+//
+// A closed session's ID is remembered for closedTTL. Events the gateway
+// still had in flight for it are dropped (orch_events_after_close_total)
+// rather than bringing the session back; only a new SessionOpen starts it
+// again.
+
+const (
+	reasonStreamLost = "stream_lost"
+	ackSessionClosed = "session_closed"
+
+	closedTTL = 10 * time.Minute
+)
+
+// closeReasons bounds the metric label; anything else counts as "other".
+var closeReasons = map[string]bool{
+	"idle_exit":        true,
+	"participant_left": true,
+	"shutdown":         true,
+	"error":            true,
+	reasonStreamLost:   true,
+
+	reasonHeartbeatTimeout: true,
+}
+
+// sessionSummary is the record persisted when a session ends.
+type sessionSummary struct {
+	SessionID    string             `json:"session_id"`
+	Reason       string             `json:"reason"`
+	OpenedAt     time.Time          `json:"opened_at,omitempty"`
+	ClosedAt     time.Time          `json:"closed_at"`
+	DurationMs   int64              `json:"duration_ms"`
+	Turns        int                `json:"turns"`
+	FinalsByRole map[string]int     `json:"finals_by_role"`
+	WordsByRole  map[string]int     `json:"words_by_role"`
+	UserPaceWPM  float64            `json:"user_pace_wpm,omitempty"`
+	Style        string             `json:"style"`
+	TTSDegraded  bool               `json:"tts_degraded,omitempty"` // fell back to text at some point
+	LLMDegraded  bool               `json:"llm_degraded,omitempty"` // spoke canned lines at some point
+	CannedCount  int                `json:"canned_replies,omitempty"`
+	MaxDuration  bool               `json:"max_duration_reached,omitempty"` // ended by the time limit
+	Phases       []phaseTiming      `json:"phases,omitempty"`
+	Moderation   []moderationRecord `json:"moderation,omitempty"` // flagged candidate finals
+	Transcript   []transcriptEntry  `json:"transcript"`
+	// Entries kept without text by the session's consent (see consent.go)
+	TranscriptRedacted bool `json:"transcript_redacted,omitempty"`
+}
+
+// summarize builds the session summary. Callers hold st.mu.
+func (st *sessionState) summarize(reason string, now time.Time) sessionSummary {
+	sum := sessionSummary{
+		SessionID:    st.id,
+		Reason:       reason,
+		OpenedAt:     st.openedAt,
+		ClosedAt:     now,
+		Turns:        st.turnSeq,
+		FinalsByRole: map[string]int{},
+		WordsByRole:  map[string]int{},
+		UserPaceWPM:  st.pace.wpm,
+		Style:        st.style.Persona + "/" + st.style.Verbosity,
+		TTSDegraded:  st.tts.everDegraded,
+		LLMDegraded:  st.llm.everDegraded,
+		CannedCount:  st.llm.canned,
+		MaxDuration:  st.timeUp,
+		Transcript:   append([]transcriptEntry(nil), st.transcript...),
+		Phases:       append([]phaseTiming(nil), st.phases...),
+		Moderation:   append([]moderationRecord(nil), st.moderation.flags...),
+	}
+	if !st.openedAt.IsZero() {
+		sum.DurationMs = now.Sub(st.openedAt).Milliseconds()
+	}
+	// The last turn is still listening for user speech and never completed
+	if st.turnSeq > 0 && st.userUtteranceID != "" {
+		sum.Turns--
+	}
+	for _, e := range st.transcript {
+		sum.FinalsByRole[e.Role]++
+		sum.WordsByRole[e.Role] += len(strings.Fields(e.Text))
+	}
+	if st.noTranscripts.Load() {
+		redactSummary(&sum)
+	}
+	return sum
+}
+
+// closeSession finalizes sid: cancels the LLM, sends StopAll, persists the
+// summary and drops the session. send may be nil when the stream is gone;
+// otherwise the gateway gets a final Ack once everything is done. Closing a
+// session twice is a no-op.
+func (s *Server) closeSession(sid, reason string, send func(*gw.OrchestratorCommand)) {
+	s.mu.Lock()
+	st := s.sess[sid]
+	if st == nil {
+		s.mu.Unlock()
+		if send != nil {
+			send(&gw.OrchestratorCommand{SessionId: sid, Cmd: &gw.OrchestratorCommand_Ack{Ack: &gw.Ack{Info: ackSessionClosed}}})
+		}
+		return
+	}
+	delete(s.sess, sid)
+	s.tombstone(sid)
+	st.mu.Lock()
+	s.mu.Unlock()
+	st.cancelScheduledClose()
+	st.stopMaxDuration()
+	st.clearUnacked()
+	st.clearUnackedArm()
+	st.clearFiller()
+	st.stopRequeues()
+	// Nothing reaches the gateway after this, so stop replies at the source
+	s.cancelLLM(st)
+	st.mu.Unlock()
+
+	// Sent whatever the state: queued or buffered speech may still be
+	// waiting to play even when the session isn't SPEAKING
+	if send != nil {
+		send(&gw.OrchestratorCommand{SessionId: sid, Cmd: &gw.OrchestratorCommand_StopAll{StopAll: &gw.StopAll{Reason: reason}}})
+	}
+
+	st.mu.Lock()
+	s.setState(st, stateClosed, triggerClose)
+	st.flowState.endPhase(phaseEndSession, s.clock.Now())
+	sum := st.summarize(reason, s.clock.Now())
+	sendState, states := st.takeTurnStates()
+	// Re-sends and late LLM sentences find no stream to send on
+	st.send = nil
+	st.mu.Unlock()
+	for _, c := range states {
+		sendState(c)
+	}
+
+	if err := s.persistSummary(sum); err != nil {
+		log.Printf("[orch] persist session summary sid=%s: %v", sid, err)
+	}
+	label := reason
+	if !closeReasons[label] {
+		label = "other"
+	}
+	metricSessionsClosed.WithLabelValues(label).Inc()
+	metricSessionDuration.Observe(float64(sum.DurationMs) / 1000)
+	log.Printf("[orch] session_close sid=%s reason=%s turns=%d duration_ms=%d transcript=%d", sid, reason, sum.Turns, sum.DurationMs, len(sum.Transcript))
+
+	if send != nil {
+		send(&gw.OrchestratorCommand{SessionId: sid, Cmd: &gw.OrchestratorCommand_Ack{Ack: &gw.Ack{Info: ackSessionClosed}}})
+	}
+}
+
+// tombstone remembers that sid was closed and forgets IDs closed more than
+// closedTTL ago. Callers hold s.mu.
+func (s *Server) tombstone(sid string) {
+	now := s.clock.Now()
+	if s.closed == nil {
+		s.closed = map[string]time.Time{}
+	}
+	for id, at := range s.closed {
+		if now.Sub(at) > closedTTL {
+			delete(s.closed, id)
+		}
+	}
+	s.closed[sid] = now
+}
+
+// sessionFor returns the session an event from the gateway is for,
+// creating it if needed, or nil when sid was closed and the event is not a
+// new SessionOpen.
+func (s *Server) sessionFor(sid string, opening bool) *sessionState {
+	s.mu.Lock()
+	if _, ok := s.closed[sid]; ok {
+		if !opening {
+			s.mu.Unlock()
+			return nil
+		}
+		delete(s.closed, sid)
+	}
+	s.mu.Unlock()
+	return s.getOrCreateSession(sid)
+}
+
+// CloseAll closes every open session with reason, sending each StopAll on
+// the stream that opened it. Used on shutdown so no bot keeps talking into
+// a session the orchestrator is about to abandon.
+func (s *Server) CloseAll(reason string) {
+	s.mu.Lock()
+	open := make(map[string]*sessionState, len(s.sess))
+	for sid, st := range s.sess {
+		open[sid] = st
+	}
+	s.mu.Unlock()
+	for sid, st := range open {
+		st.mu.Lock()
+		send := st.send
+		st.mu.Unlock()
+		s.closeSession(sid, reason, send)
+	}
+}
+
+// persistSummary writes sum to <stateDir>/<session>.json. It is a no-op when
+// ORCH_STATE_DIR is unset.
+func (s *Server) persistSummary(sum sessionSummary) error {
+	if s.stateDir == "" {
+		return nil
+	}
+	// Session IDs come from the gateway; never let one escape the directory
+	name := sum.SessionID
+	if name == "" || name != filepath.Base(name) || strings.HasPrefix(name, ".") {
+		return &os.PathError{Op: "persist", Path: name, Err: os.ErrInvalid}
+	}
+	b, err := json.MarshalIndent(sum, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(s.stateDir, 0o755); err != nil {
+		return err
+	}
+	// Write then rename so readers never see a partial file
+	tmp, err := os.CreateTemp(s.stateDir, name+".*.tmp")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(b); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), filepath.Join(s.stateDir, name+".json"))
+}
+
+// scheduleClose finalizes sid after closeGrace unless a reconnect re-opens
+// it first (see cancelScheduledClose). Called when a stream ends without
+// SessionClose; gen is the SessionOpen count the stream saw, so a stream the
+// gateway already replaced doesn't close the session under its successor.
+// Like a heartbeat timeout, the close sends StopAll on the session's stream,
+// which reaches a gateway that is still there.
+func (s *Server) scheduleClose(sid string, gen int) {
+	st := s.lookup(sid)
+	if st == nil {
+		return
+	}
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	if st.opens != gen {
+		return
+	}
+	st.cancelScheduledClose()
+	log.Printf("[orch] stream lost sid=%s, closing in %s unless it reconnects", sid, s.closeGrace)
+	st.closeTimer = time.AfterFunc(s.closeGrace, func() {
+		// A reconnect may have re-opened the session or replaced its state
+		open := s.lookup(sid) == st
+		st.mu.Lock()
+		current := open && st.closeTimer != nil
+		send := st.send
+		st.mu.Unlock()
+		if current {
+			s.closeSession(sid, reasonStreamLost, send)
+		}
+	})
+}
+
+// cancelScheduledClose keeps a reconnecting session alive. Callers hold st.mu.
+func (st *sessionState) cancelScheduledClose() bool {
+	if st.closeTimer == nil {
+		return false
+	}
+	st.closeTimer.Stop()
+	st.closeTimer = nil
+	return true
+}
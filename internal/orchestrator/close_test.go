@@ -0,0 +1,188 @@
+package orchestrator
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"yuzu/agent/internal/clock"
+	gw "yuzu/agent/internal/orchestrator/pb"
+)
+
+func TestSessionCloseFlushesAndPersists(t *testing.T) {
+	dir := t.TempDir()
+	fc := clock.NewFake(time.Unix(1700000000, 0))
+	s := &Server{sess: map[string]*sessionState{}, clock: fc, stateDir: dir}
+	st := &sessionState{id: "s1", openedAt: fc.Now(), opens: 1}
+	s.sess["s1"] = st
+	st.openTurn()
+	st.record(fc.Now(), roleCandidate, 0, "I led the payments team")
+	st.record(fc.Now(), roleAgent, 0, "Tell me more about that.")
+	st.openTurn()
+	st.state = stateSpeaking
+	cancelled := false
+	st.llmActive, st.llmCancel = true, func() { cancelled = true }
+	fc.Advance(90 * time.Second)
+
+	var cmds []*gw.OrchestratorCommand
+	s.closeSession("s1", "participant_left", func(c *gw.OrchestratorCommand) { cmds = append(cmds, c) })
+
+	if !cancelled {
+		t.Error("in-flight LLM stream not cancelled")
+	}
+	if len(cmds) != 2 || cmds[0].GetStopAll().GetReason() != "participant_left" || cmds[1].GetAck().GetInfo() != ackSessionClosed {
+		t.Fatalf("commands = %v, want StopAll, Ack", cmds)
+	}
+	if s.sess["s1"] != nil {
+		t.Error("session still registered after close")
+	}
+
+	b, err := os.ReadFile(filepath.Join(dir, "s1.json"))
+	if err != nil {
+		t.Fatalf("summary not persisted: %v", err)
+	}
+	var sum sessionSummary
+	if err := json.Unmarshal(b, &sum); err != nil {
+		t.Fatal(err)
+	}
+	if sum.Reason != "participant_left" || sum.Turns != 1 || sum.DurationMs != 90000 || len(sum.Transcript) != 2 || sum.WordsByRole[roleCandidate] != 5 {
+		t.Errorf("summary = %+v", sum)
+	}
+
+	// A repeated close still acks so the gateway isn't left waiting
+	cmds = nil
+	s.closeSession("s1", "participant_left", func(c *gw.OrchestratorCommand) { cmds = append(cmds, c) })
+	if len(cmds) != 1 || cmds[0].GetAck() == nil {
+		t.Errorf("second close commands = %v, want a lone Ack", cmds)
+	}
+}
+
+func TestCloseAllStopsEverySession(t *testing.T) {
+	s := &Server{sess: map[string]*sessionState{}, clock: clock.NewFake(time.Unix(1700000000, 0))}
+	sent := map[string][]*gw.OrchestratorCommand{}
+	for _, sid := range []string{"s1", "s2"} {
+		sid := sid
+		s.sess[sid] = &sessionState{id: sid, state: stateListening, send: func(c *gw.OrchestratorCommand) { sent[sid] = append(sent[sid], c) }}
+	}
+	// Not yet opened on any stream: closed without commands
+	s.sess["s3"] = &sessionState{id: "s3"}
+
+	s.CloseAll("shutdown")
+
+	if len(s.sess) != 0 {
+		t.Fatalf("%d sessions left open", len(s.sess))
+	}
+	for _, sid := range []string{"s1", "s2"} {
+		if cmds := sent[sid]; len(cmds) != 3 || cmds[0].GetStopAll().GetReason() != "shutdown" ||
+			cmds[1].GetTurnState().GetToState() != string(stateClosed) || cmds[2].GetAck() == nil {
+			t.Errorf("%s commands = %v, want StopAll, TurnState, Ack", sid, cmds)
+		}
+	}
+}
+
+func TestStreamLossClosesAfterGrace(t *testing.T) {
+	s := &Server{sess: map[string]*sessionState{}, clock: clock.Real, closeGrace: 20 * time.Millisecond}
+	closed := func() bool {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		return s.sess["s1"] == nil
+	}
+
+	// Reconnect within the grace period keeps the session
+	s.sess["s1"] = &sessionState{id: "s1", opens: 1}
+	s.scheduleClose("s1", 1)
+	st := s.lookup("s1")
+	st.mu.Lock()
+	st.cancelScheduledClose()
+	st.opens++
+	st.mu.Unlock()
+	// The replaced stream ending late must not schedule a close either
+	s.scheduleClose("s1", 1)
+	time.Sleep(60 * time.Millisecond)
+	if closed() {
+		t.Fatal("session closed despite reconnect")
+	}
+
+	// The close stops the bot as any other does
+	var mu sync.Mutex
+	var cmds []*gw.OrchestratorCommand
+	st.mu.Lock()
+	st.send = func(c *gw.OrchestratorCommand) {
+		mu.Lock()
+		defer mu.Unlock()
+		cmds = append(cmds, c)
+	}
+	st.mu.Unlock()
+	s.scheduleClose("s1", 2)
+	deadline := time.Now().Add(time.Second)
+	for !closed() {
+		if time.Now().After(deadline) {
+			t.Fatal("session not closed after grace period")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	// The Ack comes last, once the close is done
+	for time.Now().Before(deadline) {
+		mu.Lock()
+		n := len(cmds)
+		mu.Unlock()
+		if n > 0 && cmds[n-1].GetAck() != nil {
+			break
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(cmds) == 0 || cmds[0].GetStopAll().GetReason() != reasonStreamLost {
+		t.Fatalf("commands at stream_lost close = %v", cmds)
+	}
+}
+
+func TestPersistSummaryRejectsPathIDs(t *testing.T) {
+	s := &Server{stateDir: t.TempDir()}
+	for _, id := range []string{"../escape", "a/b", ".hidden", ""} {
+		if err := s.persistSummary(sessionSummary{SessionID: id}); err == nil {
+			t.Errorf("persistSummary(%q) succeeded", id)
+		}
+	}
+}
+
+func TestClosedSessionsStayClosed(t *testing.T) {
+	fc := clock.NewFake(time.Unix(1700000000, 0))
+	s := &Server{sess: map[string]*sessionState{}, clock: fc, fillerAfter: time.Hour, stopRetry: time.Hour, armRetry: time.Hour}
+	st := s.getOrCreateSession("s1")
+	st.stopAcks, st.armAcks = true, true
+	s.armFiller(st, "t1", func(*gw.OrchestratorCommand) {})
+	st.mu.Lock()
+	s.newStop(st, "barge_in", "t1-a1")
+	s.newArm(st, 300, 900)
+	st.mu.Unlock()
+
+	s.closeSession("s1", "participant_left", nil)
+	if st.filler.timer != nil || st.unacked != nil || st.armUnacked != nil {
+		t.Fatalf("timers left armed: filler=%v stop=%v arm=%v", st.filler.timer, st.unacked, st.armUnacked)
+	}
+
+	// A straggling event doesn't bring the session back; a SessionOpen does
+	if got := s.sessionFor("s1", false); got != nil || s.lookup("s1") != nil {
+		t.Fatal("late event recreated a closed session")
+	}
+	if got := s.sessionFor("s1", true); got == nil || got == st {
+		t.Fatal("SessionOpen did not start the session again")
+	}
+
+	// Tombstones are forgotten after closedTTL
+	s.closeSession("s1", "participant_left", nil)
+	fc.Advance(closedTTL + time.Second)
+	s.getOrCreateSession("s2")
+	s.closeSession("s2", "participant_left", nil)
+	s.mu.Lock()
+	_, kept := s.closed["s1"]
+	s.mu.Unlock()
+	if kept {
+		t.Error("tombstone outlived closedTTL")
+	}
+}
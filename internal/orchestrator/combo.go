@@ -0,0 +1,61 @@
+package orchestrator
+
+import (
+	"log"
+	"time"
+)
+
+// combo.go measures the latency a candidate actually hears: from the
+// TranscriptFinal that ends their answer to the first audio of the reply,
+// labeled by the STT, LLM and TTS providers that served the turn so provider
+// combinations can be compared straight from Prometheus. STT and LLM are
+// fixed per deployment (ORCH_STT_PROVIDER, ORCH_LLM_BACKEND); the TTS label
+// follows the StartTTS provider, so re-routed turns are counted under the
+// fallback. Labels outside the known sets are reported as "other".
+
+var (
+	knownSTTProviders = map[string]bool{"deepgram": true}
+	knownLLMBackends  = map[string]bool{"azure_openai": true, "openai": true}
+	// StartTTS.provider -> label; "" is the gateway's streaming default
+	ttsProviderLabels = map[string]string{"": "elevenlabs_stream", "service": "tts_service"}
+)
+
+// providerCombo is the deployment's fixed part of the label set.
+type providerCombo struct {
+	stt string
+	llm string
+}
+
+func comboFromEnv() providerCombo {
+	return providerCombo{
+		stt: boundedLabel(envString("ORCH_STT_PROVIDER", "deepgram"), knownSTTProviders),
+		llm: boundedLabel(envString("ORCH_LLM_BACKEND", "azure_openai"), knownLLMBackends),
+	}
+}
+
+func boundedLabel(v string, known map[string]bool) string {
+	if known[v] {
+		return v
+	}
+	log.Printf("[orch] unknown provider label %q, reporting as other", v)
+	return "other"
+}
+
+func ttsProviderLabel(provider string) string {
+	if l, ok := ttsProviderLabels[provider]; ok {
+		return l
+	}
+	return "other"
+}
+
+// observeTurnLatency records the reply's first audio once per turn. played
+// are the sentences that first audio acknowledged; fillers are not tracked
+// there, so they never end the measurement. Callers hold st.mu.
+func (s *Server) observeTurnLatency(st *sessionState, played []pendingTTS, now time.Time) {
+	if !st.turnLatencyPending || len(played) == 0 || st.lastTranscriptFinal.IsZero() {
+		return
+	}
+	st.turnLatencyPending = false
+	ms := float64(now.Sub(st.lastTranscriptFinal).Milliseconds())
+	metricTurnLatency.WithLabelValues(s.combo.stt, s.combo.llm, ttsProviderLabel(played[0].provider)).Observe(ms)
+}
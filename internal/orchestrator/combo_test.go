@@ -0,0 +1,51 @@
+package orchestrator
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+
+	"yuzu/agent/internal/clock"
+	gw "yuzu/agent/internal/orchestrator/pb"
+)
+
+func turnLatencyCount(t *testing.T, tts string) uint64 {
+	t.Helper()
+	var m dto.Metric
+	if err := metricTurnLatency.WithLabelValues("deepgram", "azure_openai", tts).(prometheus.Histogram).Write(&m); err != nil {
+		t.Fatal(err)
+	}
+	return m.GetHistogram().GetSampleCount()
+}
+
+func TestTurnLatencyObservedOncePerReply(t *testing.T) {
+	fc := clock.NewFake(time.Unix(1700000000, 0))
+	s := &Server{sess: map[string]*sessionState{}, clock: fc, combo: providerCombo{stt: "deepgram", llm: "azure_openai"}}
+	st := &sessionState{id: "s1", lastTranscriptFinal: fc.Now(), turnLatencyPending: true}
+	before := turnLatencyCount(t, "tts_service")
+
+	// Rerouted sentences are counted under the provider that played them
+	st.trackTTS(&gw.StartTTS{TurnId: "t1", UtteranceId: "t1-a1", Provider: "service"})
+	st.trackTTS(&gw.StartTTS{TurnId: "t1", UtteranceId: "t1-a2", Provider: "service"})
+	fc.Advance(900 * time.Millisecond)
+	s.handleTTSEvent(st, "first_audio", 0, "t1-a1", "", func(*gw.OrchestratorCommand) {})
+	s.handleTTSEvent(st, "first_audio", 0, "t1-a2", "", func(*gw.OrchestratorCommand) {})
+
+	if got := turnLatencyCount(t, "tts_service") - before; got != 1 {
+		t.Errorf("observations = %d, want 1 per reply", got)
+	}
+	if st.turnLatencyPending {
+		t.Error("turn latency still pending after first audio")
+	}
+}
+
+func TestProviderLabelsAreBounded(t *testing.T) {
+	if got := boundedLabel("whisper-local", knownSTTProviders); got != "other" {
+		t.Errorf("unknown stt label = %q", got)
+	}
+	if ttsProviderLabel("") != "elevenlabs_stream" || ttsProviderLabel("xyz") != "other" {
+		t.Error("tts provider labels not bounded")
+	}
+}
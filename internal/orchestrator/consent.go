@@ -0,0 +1,34 @@
+package orchestrator
+
+import (
+	"log"
+)
+
+// consent.go keeps transcript text of sessions that opted out of transcript
+// retention (SessionStyle.no_transcript_retention, set by the API server
+// from the session's consent) out of what the orchestrator keeps: the
+// summary written to ORCH_STATE_DIR has its transcript entries without
+// text, and log lines show the length of a final or sentence instead of
+// its words. The text still drives the conversation while the session is
+// live. Each summary written without its text is counted in
+// orch_recording_rejected_total{what="session_summary"}.
+
+const redactedText = "[redacted]"
+
+// logText is text as it may appear in the log.
+func logText(st *sessionState, text string) string {
+	if st != nil && st.noTranscripts.Load() {
+		return redactedText
+	}
+	return text
+}
+
+// redactSummary strips the transcript text from sum.
+func redactSummary(sum *sessionSummary) {
+	for i := range sum.Transcript {
+		sum.Transcript[i].Text = ""
+	}
+	sum.TranscriptRedacted = true
+	metricRecordingRejected.WithLabelValues("session_summary").Inc()
+	log.Printf("[orch] recording rejected sid=%s what=session_summary (consent)", sum.SessionID)
+}
@@ -0,0 +1,38 @@
+package orchestrator
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"yuzu/agent/internal/clock"
+)
+
+func TestNoTranscriptRetentionSummary(t *testing.T) {
+	dir := t.TempDir()
+	fc := clock.NewFake(time.Unix(1700000000, 0))
+	s := &Server{sess: map[string]*sessionState{}, clock: fc, stateDir: dir}
+	st := &sessionState{id: "s1", openedAt: fc.Now(), opens: 1}
+	st.noTranscripts.Store(true)
+	s.sess["s1"] = st
+	st.openTurn()
+	st.record(fc.Now(), roleCandidate, 0, "My salary was confidential")
+	s.closeSession("s1", "participant_left", nil)
+
+	b, err := os.ReadFile(filepath.Join(dir, "s1.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(b), "confidential") || !strings.Contains(string(b), `"transcript_redacted": true`) {
+		t.Fatalf("summary = %s", b)
+	}
+	// Counts survive without the words
+	if !strings.Contains(string(b), `"candidate": 4`) {
+		t.Errorf("summary lost the word counts: %s", b)
+	}
+	if got := logText(st, "secret"); got != redactedText {
+		t.Errorf("logText = %q", got)
+	}
+}
@@ -2,59 +2,191 @@ package orchestrator
 
 import (
     "context"
+    "io"
     "log"
     "os"
     "time"
 
-    llmpb "yuzu/agent/internal/llm/pb"
-    gw "yuzu/agent/internal/orchestrator/pb"
     "google.golang.org/grpc/codes"
     "google.golang.org/grpc/status"
+
+    "yuzu/agent/internal/errdefs"
+    llmpb "yuzu/agent/internal/llm/pb"
+    gw "yuzu/agent/internal/orchestrator/pb"
 )
 
 // handleTTSEvent processes TTS lifecycle events from the gateway.
-func (s *Server) handleTTSEvent(st *sessionState, ttsType string, firstAudioMs uint32) {
-	log.Printf("[orch] TTS event received type=%s sid=%s", ttsType, st.id)
+func (s *Server) handleTTSEvent(st *sessionState, ttsType string, firstAudioMs uint32, utteranceID string, reason string, send func(*gw.OrchestratorCommand)) {
+	log.Printf("[orch] TTS event received type=%s sid=%s utterance=%s", ttsType, st.id, utteranceID)
+	st.mu.Lock()
+	m := st.checkAgentUtterance(utteranceID)
+	st.mu.Unlock()
+	recordIDEcho(st.id, "tts_"+ttsType, utteranceID, m)
 	switch ttsType {
 	case "started":
 		// Just reset VAD state and mark speaking - don't arm barge-in yet
 		// Barge-in will be armed on first_audio when audio actually plays
+		st.mu.Lock()
 		s.resetVADState(st)
-		s.setState(st, "SPEAKING")
+		st.tail = tailState{}
+		st.playing = utteranceID
+		s.setState(st, stateSpeaking, triggerTTSStarted)
+		st.mu.Unlock()
+		s.flushTurnStates(st)
 		log.Printf("[orch] TTS started, waiting for first_audio to arm barge-in sid=%s", st.id)
 
 	case "first_audio":
 		// NOW arm barge-in - audio is actually playing
-		guardMs := uint32(envInt("LOCAL_STOP_GUARD_MS", 1000))
-		log.Printf("[orch] TTS first_audio, arming barge-in guard=%dms minRMS=%.0f sid=%s", guardMs, st.minRMS, st.id)
-		s.armBargeIn(st, guardMs, uint32(st.minRMS))
+		st.mu.Lock()
+		guardMs := st.guardMs
+		if guardMs == 0 {
+			guardMs = s.currentThresholds().GuardMs
+		}
+		minRMS := st.minRMS
+		s.armBargeIn(st, guardMs, uint32(minRMS))
+		// Audio is flowing, so these sentences no longer need recovery
+		s.observeTurnLatency(st, st.takeTTS(utteranceID), s.clock.Now())
+		s.ttsHeard(st)
+		st.mu.Unlock()
+		log.Printf("[orch] TTS first_audio, arming barge-in guard=%dms minRMS=%.0f sid=%s", guardMs, minRMS, st.id)
+		s.gateMic(st, send)
 		if firstAudioMs > 0 {
 			metricTTSFirstAudio.Observe(float64(firstAudioMs))
 		}
 
 	case "stopped":
-		s.setState(st, "LISTENING")
+		st.mu.Lock()
+		if st.playing == utteranceID {
+			st.playing = ""
+		}
+		s.setState(st, stateListening, triggerTTSStopped)
+		s.armTail(st, reason, s.clock.Now())
+		st.mu.Unlock()
+		s.flushTurnStates(st)
+		s.ungateMic(st, send)
+
+	case "failed":
+		st.mu.Lock()
+		if st.playing == utteranceID {
+			st.playing = ""
+		}
+		st.mu.Unlock()
+		s.handleTTSFailed(st, utteranceID, reason, send)
 	}
 }
 
 // handleTranscriptFinal processes final transcript and starts LLM.
-func (s *Server) handleTranscriptFinal(ctx context.Context, st *sessionState, sid string, text string, send func(*gw.OrchestratorCommand)) {
-	log.Printf("[orch] TRANSCRIPT_FINAL received sid=%s text_len=%d text=%q state=%s", sid, len(text), text, st.state)
-	s.setState(st, "PROCESSING")
+func (s *Server) handleTranscriptFinal(ctx context.Context, st *sessionState, sid string, utteranceID string, text string, send func(*gw.OrchestratorCommand)) {
+	st.mu.Lock()
+	state := st.state
+	m := st.checkUserUtterance(utteranceID)
+	want := st.userUtteranceID
+	st.mu.Unlock()
+	log.Printf("[orch] TRANSCRIPT_FINAL received sid=%s utterance=%s text_len=%d text=%q state=%s", sid, utteranceID, len(text), logText(st, text), state)
+	recordIDEcho(sid, "transcript_final", utteranceID, m)
+	if m == idMismatch && s.requireIDs {
+		log.Printf("[orch] dropping TranscriptFinal with stale utterance sid=%s got=%s want=%s", sid, utteranceID, want)
+		return
+	}
+	s.answerFinal(ctx, st, sid, utteranceID, text, send)
+}
+
+// answerFinal opens the next turn and replies to a final that passed the ID
+// check.
+func (s *Server) answerFinal(ctx context.Context, st *sessionState, sid string, utteranceID string, text string, send func(*gw.OrchestratorCommand)) {
+	st.mu.Lock()
+	state := st.state
+	// Finals held before a barge-in go in front of its final (see pipeline.go)
+	text = s.mergeBargeIn(st, utteranceID, text)
+	if st.timeUp {
+		// The closing statement is out; keep the words, don't reply
+		st.record(s.clock.Now(), roleCandidate, st.speakers.candidate, text)
+		st.mu.Unlock()
+		log.Printf("[orch] final after the time limit sid=%s utterance=%s: not replying", sid, utteranceID)
+		return
+	}
+	if s.pipelineTurns && state == stateSpeaking {
+		// Answered when playback ends or a barge-in fires (see pipeline.go)
+		s.holdFinal(st, utteranceID, text)
+		st.mu.Unlock()
+		return
+	}
+	if !s.setState(st, stateProcessing, triggerFinal) {
+		// SPEAKING without a barge-in: not the candidate's turn
+		st.mu.Unlock()
+		s.flushTurnStates(st)
+		log.Printf("[orch] dropping TranscriptFinal outside the candidate's turn sid=%s utterance=%s state=%s", sid, utteranceID, state)
+		return
+	}
+	st.record(s.clock.Now(), roleCandidate, st.speakers.candidate, text)
 	// Mark transcript final time for LLMSentence latency
-	st.lastTranscriptFinal = time.Now()
+	st.lastTranscriptFinal = s.clock.Now()
 	st.llmFirstSentence = false
-	log.Printf("[orch] Starting LLM for sid=%s", sid)
-	go s.startLLM(ctx, sid, text, send)
+
+	// The reply belongs to the turn that produced this final; listening moves
+	// on to a new turn so the next user utterance gets its own ID.
+	st.turnLatencyPending = true
+	turnID := st.turnID
+	nextTurn, nextUtt := st.openTurn()
+	listen := st.listenCmds(nil, nil, &gw.StartMicToSTT{TurnId: nextTurn, UtteranceId: nextUtt})
+	if st.echoTest {
+		// Diagnostics: repeat the final instead of replying (see echo.go)
+		echo := s.echoFinal(st, turnID, text)
+		st.mu.Unlock()
+		s.flushTurnStates(st)
+		for _, c := range append(listen, echo...) {
+			send(c)
+		}
+		return
+	}
+	// The reply is written under the current flow stage; this answer may
+	// complete it. A stage that ran out of time hands over right away and
+	// the reply introduces the next one.
+	stage := st.flowState.instructions()
+	if transition := s.advanceFlow(st); transition != "" {
+		stage = st.flowState.instructions() + "\n\n" + transition
+	}
+	// Near the time limit the reply steers toward a close (see maxduration.go)
+	if wrap := s.wrapUpPrompt(st, s.clock.Now()); wrap != "" {
+		if stage != "" {
+			stage += "\n\n"
+		}
+		stage += wrap
+	}
+	st.mu.Unlock()
+	s.flushTurnStates(st)
+	for _, c := range listen {
+		send(c)
+	}
+	if in := matchIntent(text, s.intents); in != "" && s.handleIntent(ctx, st, sid, turnID, in, stage, send) {
+		return
+	}
+	if s.moderation.enabled {
+		s.armFiller(st, turnID, send)
+		go s.moderateThenReply(ctx, st, sid, turnID, utteranceID, text, stage, send)
+		return
+	}
+	log.Printf("[orch] Starting LLM for sid=%s turn=%s", sid, turnID)
+	go s.startLLM(ctx, sid, turnID, text, stage, send)
+	s.armFiller(st, turnID, send)
 }
 
 // startLLM starts an LLM streaming request and forwards sentences to Gateway as StartTTS.
-func (s *Server) startLLM(parent context.Context, sessionID string, userText string, send func(*gw.OrchestratorCommand)) {
-    // Resolve deployment and API version with Azure fallbacks
+// stage holds the flow's prompt layer for this reply (see promptlayers.go).
+func (s *Server) startLLM(parent context.Context, sessionID string, turnID string, userText string, stage string, send func(*gw.OrchestratorCommand)) {
+    if !s.admitLLM(sessionID, turnID, send) {
+        return
+    }
+    // Resolve deployment with Azure fallbacks
     deployment := os.Getenv("LLM_DEPLOYMENT")
     if deployment == "" {
         deployment = os.Getenv("AZURE_OPENAI_DEPLOYMENT")
     }
+    s.startLLMOn(parent, sessionID, turnID, userText, stage, deployment, send)
+}
+
+// startLLMOn is startLLM against a given deployment.
+func (s *Server) startLLMOn(parent context.Context, sessionID string, turnID string, userText string, stage string, deployment string, send func(*gw.OrchestratorCommand)) {
     apiVersion := os.Getenv("LLM_API_VERSION")
     if apiVersion == "" {
         apiVersion = os.Getenv("AZURE_OPENAI_API_VERSION")
@@ -62,31 +194,42 @@ func (s *Server) startLLM(parent context.Context, sessionID string, userText str
     if apiVersion == "" {
         apiVersion = "2024-02-15-preview"
     }
-	sys := os.Getenv("LLM_SYSTEM_PROMPT")
-	if sys == "" {
-		// Default TTS-friendly prompt: concise, conversational, no formatting
-		sys = "You are a friendly voice assistant. Respond in 1-2 short sentences. " +
-			"Be conversational and natural. Never use bullet points, lists, markdown, " +
-			"or special formatting. Your responses will be spoken aloud via text-to-speech."
+	// Per-session style; sessions that never sent SessionOpen get defaults
+	style := defaultStyle()
+	var admin, docs string
+	noLog := false
+	if st := s.lookup(sessionID); st != nil {
+		st.mu.Lock()
+		if st.style.Persona != "" {
+			style = st.style
+		}
+		noLog = st.noLLMLogging
+		admin = st.adminPrompt
+		// Uploaded documents relevant to this turn (see retrieval.go)
+		docs = s.contextPrompt(st, userText)
+		st.mu.Unlock()
 	}
+	// Org, flow and session layers (see promptlayers.go)
+	// Over-long prompts are cut before the request (see promptbudget.go)
+	sys, prompt := s.fitPrompt(sessionID, promptLayers(style, admin, stage, layerFlow), docs, userText)
 
 	msgs := []*llmpb.ChatMessage{}
 	msgs = append(msgs, &llmpb.ChatMessage{Role: "system", Content: sys})
-	msgs = append(msgs, &llmpb.ChatMessage{Role: "user", Content: userText})
+	msgs = append(msgs, &llmpb.ChatMessage{Role: "user", Content: prompt})
 
 	ctx, cancel := context.WithCancel(parent)
 	client, err := s.getLLMClient(ctx)
 	if err != nil {
 		log.Printf("[orch] llm dial: %v", err)
 		cancel()
+		s.llmFailed(parent, sessionID, turnID, userText, llmFailUnavailable, send)
 		return
 	}
 
     stream, err := client.Session(ctx)
     if err != nil {
         // Reconnect only on connection-level failures
-        st, _ := status.FromError(err)
-        if st != nil && (st.Code() == codes.Unavailable || st.Code() == codes.ResourceExhausted) {
+        if errdefs.IsRetryable(errdefs.FromGRPC("llm", err)) {
             if rerr := s.reconnectLLM(ctx, 1); rerr == nil {
                 if client, r2 := s.getLLMClient(ctx); r2 == nil {
                     if stream, err = client.Session(ctx); err == nil {
@@ -97,6 +240,7 @@ func (s *Server) startLLM(parent context.Context, sessionID string, userText str
         }
         log.Printf("[orch] llm session: %v", err)
         cancel()
+        s.llmFailed(parent, sessionID, turnID, userText, llmFailUnavailable, send)
         return
     }
 STREAM:
@@ -107,12 +251,15 @@ STREAM:
 	err = stream.Send(&llmpb.ClientMessage{
 		Msg: &llmpb.ClientMessage_Start{
 			Start: &llmpb.StartRequest{
-				SessionId:  sessionID,
-				RequestId:  time.Now().Format("20060102150405.000"),
-				Deployment: deployment,
-				ApiVersion: apiVersion,
-				Messages:   msgs,
-				Stream:     true,
+				SessionId:   sessionID,
+				RequestId:   time.Now().Format("20060102150405.000"),
+				Deployment:  deployment,
+				ApiVersion:  apiVersion,
+				Messages:    msgs,
+				Stream:      true,
+				MaxTokens:   uint32(style.MaxTokens),
+				Temperature: style.Temperature,
+				NoLog:       noLog,
 			},
 		},
 	})
@@ -120,49 +267,107 @@ STREAM:
 		log.Printf("[orch] llm send start: %v", err)
 		cancel()
 		s.detachLLM(sessionID)
+		s.llmFailed(parent, sessionID, turnID, userText, llmFailUnavailable, send)
 		return
 	}
 
 	// Read responses in background
-    go s.streamLLMResponses(stream, sessionID, send, cancel)
+	onFail := func(reason string) {
+		if reason == llmFailCircuitOpen {
+			s.llmCircuitOpen(parent, sessionID, turnID, userText, stage, deployment, send)
+			return
+		}
+		s.llmFailed(parent, sessionID, turnID, userText, reason, send)
+	}
+    go s.streamLLMResponses(stream, sessionID, turnID, send, cancel, onFail)
 }
 
 // streamLLMResponses reads LLM stream and forwards sentences to TTS.
-func (s *Server) streamLLMResponses(stream llmpb.LLM_SessionClient, sessionID string, send func(*gw.OrchestratorCommand), cancel context.CancelFunc) {
+// onFail, when set, runs after the stream is torn down if the reply failed
+// before any sentence, with the reason (see playbook.go).
+func (s *Server) streamLLMResponses(stream llmpb.LLM_SessionClient, sessionID string, turnID string, send func(*gw.OrchestratorCommand), cancel context.CancelFunc, onFail func(reason string)) {
+	// TokenDelta numbering for this reply (see tokenstream.go)
+	var tokenSeq uint32
+	streaming := true
+	sentences := 0
+	failed := ""
 	defer func() {
 		cancel()
 		s.detachLLM(sessionID)
+		if streaming {
+			s.tokenDelta(sessionID, turnID, "", tokenSeq+1, true, send)
+		}
+		if failed != "" && sentences == 0 && onFail != nil {
+			onFail(failed)
+		}
 	}()
 
 	for {
 		resp, err := stream.Recv()
         if err != nil {
-            // Stream closed (normal or cancelled)
+            // Stream closed (normal or cancelled); a broken one fails the reply
+            if failed == "" && err != io.EOF && status.Code(err) != codes.Canceled {
+                log.Printf("[orch] llm stream: %v", err)
+                failed = llmFailUnavailable
+            }
             return
         }
 
 		switch m := resp.Msg.(type) {
+		case *llmpb.ServerMessage_Connected:
+			c := m.Connected
+			log.Printf("[orch] llm connected sid=%s turn=%s provider=%s model=%s connect_ms=%d request_id=%s",
+				sessionID, turnID, c.GetProvider(), c.GetModel(), c.GetConnectMs(), c.GetRequestId())
+
+		case *llmpb.ServerMessage_Token:
+			if streaming && m.Token.GetText() != "" {
+				tokenSeq++
+				streaming = s.tokenDelta(sessionID, turnID, m.Token.GetText(), tokenSeq, false, send)
+			}
+
         case *llmpb.ServerMessage_Sentence:
             text := m.Sentence.GetText()
             if text != "" {
-                log.Printf("[orch] LLM sentence received sid=%s text_len=%d text=%q", sessionID, len(text), text)
+                sentences++
+                log.Printf("[orch] LLM sentence received sid=%s text_len=%d text=%q", sessionID, len(text), logText(s.lookup(sessionID), text))
                 // Observe LLMSentence latency on first sentence since final
-                s.mu.Lock()
-                if st, ok := s.sess[sessionID]; ok && !st.llmFirstSentence && !st.lastTranscriptFinal.IsZero() {
-                    d := time.Since(st.lastTranscriptFinal)
-                    if d > 0 { metricLLMSentenceLatency.Observe(float64(d.Milliseconds())) }
-                    st.llmFirstSentence = true
+                cmd := &gw.StartTTS{Text: text, TurnId: turnID}
+                var cmds []*gw.OrchestratorCommand
+                filler := ""
+                st := s.lookup(sessionID)
+                if st != nil {
+                    st.mu.Lock()
+                    if !st.llmFirstSentence && !st.lastTranscriptFinal.IsZero() {
+                        d := s.clock.Since(st.lastTranscriptFinal)
+                        if d > 0 { metricLLMSentenceLatency.Observe(float64(d.Milliseconds())) }
+                        st.llmFirstSentence = true
+                    }
+                    filler = st.takeFiller()
+                    cmd.UtteranceId = st.nextAgentUtterance(turnID)
+                    st.record(s.clock.Now(), roleAgent, 0, text)
+                    st.intents.noteReply(turnID, text)
+                    s.sentenceProsody(st, turnID, cmd)
+                    cmds = s.agentSpeech(st, cmd)
+                    if recovered := s.llmAnswered(st, turnID); recovered != nil {
+                        cmds = append([]*gw.OrchestratorCommand{recovered}, cmds...)
+                    }
+                    st.mu.Unlock()
+                    s.flushTurnStates(st)
+                }
+                s.stopFiller(st, filler, send)
+                log.Printf("[orch] Sending agent sentence to gateway sid=%s turn=%s utterance=%s text_len=%d rate=%.2f pause_ms=%d intonation=%s cmds=%d", sessionID, turnID, cmd.UtteranceId, len(text), cmd.SpeakingRate, cmd.PauseMs, cmd.Intonation, len(cmds))
+                for _, c := range cmds {
+                    send(c)
                 }
-                s.mu.Unlock()
-                log.Printf("[orch] Sending StartTTS command to gateway sid=%s text_len=%d", sessionID, len(text))
-                send(&gw.OrchestratorCommand{
-                    SessionId: sessionID,
-                    Cmd:       &gw.OrchestratorCommand_StartTts{StartTts: &gw.StartTTS{Text: text}},
-                })
             }
 
 		case *llmpb.ServerMessage_Error:
 			log.Printf("[orch] llm error: %s", m.Error.GetMessage())
+			if m.Error.GetCode() == errdefs.CodeCircuitOpen && sentences == 0 {
+				failed = llmFailCircuitOpen
+				return
+			}
+			failed = llmFailError
 
 		case *llmpb.ServerMessage_Usage:
 			// Could emit metrics here
@@ -0,0 +1,97 @@
+package orchestrator
+
+import (
+	"log"
+	"time"
+
+	gw "yuzu/agent/internal/orchestrator/pb"
+)
+
+// degrade.go falls back to text when TTS keeps failing. A batch that
+// ttsretry.go drops after spending its re-send budget is a TTS failure;
+// ttsDegradeAfter of them in a row (no first_audio in between) switch the
+// session to text-only. Agent sentences then go out as DisplayText, which
+// the gateway renders as chat, and fillers are skipped. Every ttsProbeEvery
+// one sentence is also sent as StartTTS: its first_audio restores speech,
+// while a failed probe is not re-sent and waits for the next one.
+
+// ttsHealth is embedded in sessionState.
+type ttsHealth struct {
+	failures     int  // consecutive dropped batches
+	degraded     bool // text-only
+	everDegraded bool
+	probeAt      time.Time // while degraded, the next sentence after this is also spoken
+}
+
+func displayText(sid, text, turnID, utteranceID string) *gw.OrchestratorCommand {
+	return &gw.OrchestratorCommand{SessionId: sid, Cmd: &gw.OrchestratorCommand_DisplayText{
+		DisplayText: &gw.DisplayText{Text: text, TurnId: turnID, UtteranceId: utteranceID},
+	}}
+}
+
+// agentSpeech tracks cmd and returns the commands that deliver it: the
+// StartTTS normally, DisplayText while degraded, followed by the StartTTS
+// when a recovery probe is due. A Caption goes first when the session has
+// captions on (see captions.go). Callers hold st.mu.
+func (s *Server) agentSpeech(st *sessionState, cmd *gw.StartTTS) []*gw.OrchestratorCommand {
+	start := &gw.OrchestratorCommand{SessionId: st.id, Cmd: &gw.OrchestratorCommand_StartTts{StartTts: cmd}}
+	var out []*gw.OrchestratorCommand
+	if st.captions {
+		out = append(out, captionCmd(st.id, roleAgent, cmd.GetText(), true, cmd.GetTurnId(), cmd.GetUtteranceId()))
+	}
+	if !st.tts.degraded {
+		st.trackTTS(cmd)
+		return append(out, start)
+	}
+	out = append(out, displayText(st.id, cmd.GetText(), cmd.GetTurnId(), cmd.GetUtteranceId()))
+	if now := s.clock.Now(); !now.Before(st.tts.probeAt) {
+		st.tts.probeAt = now.Add(s.ttsProbeEvery)
+		st.trackTTS(cmd)
+		st.ttsPending[len(st.ttsPending)-1].shown = true
+		metricTTSDegrade.WithLabelValues("probe").Inc()
+		return append(out, start)
+	}
+	// No playback will move the turn on
+	if st.state == stateProcessing {
+		s.setState(st, stateListening, triggerTextOnly)
+	}
+	return out
+}
+
+// ttsDropped counts a batch dropped after its re-sends and switches the
+// session to text-only once ttsDegradeAfter batches failed in a row. It
+// reports whether the session is degraded; the batch is then shown as text.
+// Callers hold st.mu.
+func (s *Server) ttsDropped(st *sessionState) bool {
+	st.tts.failures++
+	if st.tts.degraded || s.ttsDegradeAfter <= 0 || st.tts.failures < s.ttsDegradeAfter {
+		return st.tts.degraded
+	}
+	st.tts.degraded, st.tts.everDegraded = true, true
+	st.tts.probeAt = s.clock.Now().Add(s.ttsProbeEvery)
+	metricTTSDegrade.WithLabelValues("degraded").Inc()
+	log.Printf("[orch] TTS failed %d times in a row sid=%s; switching to text-only, probing every %s", st.tts.failures, st.id, s.ttsProbeEvery)
+	return true
+}
+
+// showDropped sends the sentences of batch the candidate has not seen yet
+// as DisplayText.
+func showDropped(sid string, batch []pendingTTS, send func(*gw.OrchestratorCommand)) {
+	for _, p := range batch {
+		if !p.shown && p.text != "" {
+			send(displayText(sid, p.text, p.turnID, p.utteranceID))
+		}
+	}
+}
+
+// ttsHeard resets the failure count on first audio and ends text-only mode.
+// Callers hold st.mu.
+func (s *Server) ttsHeard(st *sessionState) {
+	st.tts.failures = 0
+	if !st.tts.degraded {
+		return
+	}
+	st.tts.degraded = false
+	metricTTSDegrade.WithLabelValues("recovered").Inc()
+	log.Printf("[orch] TTS recovered sid=%s; speaking again", st.id)
+}
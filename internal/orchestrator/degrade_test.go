@@ -0,0 +1,87 @@
+package orchestrator
+
+import (
+	"testing"
+	"time"
+
+	"yuzu/agent/internal/clock"
+	gw "yuzu/agent/internal/orchestrator/pb"
+)
+
+func TestTTSDegradesToTextAndRecovers(t *testing.T) {
+	clk := clock.NewFake(time.Unix(1700000000, 0))
+	s := &Server{sess: map[string]*sessionState{}, clock: clk, ttsDegradeAfter: 2, ttsProbeEvery: 30 * time.Second}
+	st := &sessionState{id: "s1", state: stateProcessing}
+	s.sess["s1"] = st
+
+	var sent []*gw.OrchestratorCommand
+	send := func(c *gw.OrchestratorCommand) { sent = append(sent, c) }
+	say := func(utt, text string) {
+		st.mu.Lock()
+		for _, c := range s.agentSpeech(st, &gw.StartTTS{Text: text, TurnId: "t1", UtteranceId: utt}) {
+			send(c)
+		}
+		st.mu.Unlock()
+	}
+
+	// No re-send budget: each failure drops its batch at once
+	say("a1", "First.")
+	say("a2", "Second.")
+	sent = nil
+	s.handleTTSEvent(st, "failed", 0, "a1", "no_audio", send)
+	if len(sent) != 0 || st.tts.degraded {
+		t.Fatalf("one failure should not degrade: sent %v", sent)
+	}
+	s.handleTTSEvent(st, "failed", 0, "a2", "no_audio", send)
+	if !st.tts.degraded || len(sent) != 1 || sent[0].GetDisplayText().GetText() != "Second." {
+		t.Fatalf("second failure: degraded=%v sent %v, want the dropped sentence as text", st.tts.degraded, sent)
+	}
+
+	// Text only until the probe is due
+	sent = nil
+	say("a3", "Third.")
+	if len(sent) != 1 || sent[0].GetDisplayText().GetUtteranceId() != "a3" {
+		t.Fatalf("degraded sentence sent %v, want DisplayText only", sent)
+	}
+	if st.state != stateListening {
+		t.Errorf("state = %s, want LISTENING without playback", st.state)
+	}
+
+	clk.Advance(30 * time.Second)
+	sent = nil
+	say("a4", "Fourth.")
+	if len(sent) != 2 || sent[0].GetDisplayText() == nil || sent[1].GetStartTts().GetUtteranceId() != "a4" {
+		t.Fatalf("probe sent %v, want DisplayText then StartTTS", sent)
+	}
+	// A failed probe is neither re-sent nor shown twice
+	sent = nil
+	s.handleTTSEvent(st, "failed", 0, "a4", "no_audio", send)
+	if len(sent) != 0 || !st.tts.degraded {
+		t.Fatalf("failed probe sent %v", sent)
+	}
+
+	clk.Advance(30 * time.Second)
+	say("a5", "Fifth.")
+	s.handleTTSEvent(st, "first_audio", 0, "a5", "", func(*gw.OrchestratorCommand) {})
+	if st.tts.degraded || st.tts.failures != 0 {
+		t.Fatalf("first_audio should restore speech: %+v", st.tts)
+	}
+	sent = nil
+	say("a6", "Sixth.")
+	if len(sent) != 1 || sent[0].GetStartTts() == nil {
+		t.Fatalf("recovered sentence sent %v, want StartTTS", sent)
+	}
+	if sum := st.summarize("done", clk.Now()); !sum.TTSDegraded {
+		t.Error("summary should record the text-only period")
+	}
+}
+
+func TestTTSDegradeDisabled(t *testing.T) {
+	s := &Server{sess: map[string]*sessionState{}, clock: clock.Real}
+	st := &sessionState{id: "s1"}
+	for i := 0; i < 5; i++ {
+		if s.ttsDropped(st) {
+			t.Fatal("ORCH_TTS_DEGRADE_AFTER=0 should never degrade")
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package orchestrator
+
+import (
+	"log"
+	"strings"
+	"time"
+
+	gw "yuzu/agent/internal/orchestrator/pb"
+)
+
+// dupturn.go keeps a final the gateway sends twice from becoming two
+// turns. After a reconnect the gateway re-sends the finals it wrote just
+// before the stream broke, since it can't tell whether they arrived, and
+// STT can transcribe re-sent audio a second time. A final is dropped as a
+// repeat when, within ORCH_DUP_FINAL_WINDOW_MS (default 10000, 0 disables)
+// of an earlier one, it has the same text (ignoring case and spacing) and
+// either the same utterance ID or the same non-zero audio_fingerprint from
+// STT. A candidate saying the same thing again is new audio under a new
+// utterance, so it still gets an answer. Counted in
+// orch_duplicate_finals_total{match} (utterance, fingerprint).
+
+// maxFinalPrints bounds the finals remembered per session.
+const maxFinalPrints = 8
+
+// finalPrint identifies one final the session has handled.
+type finalPrint struct {
+	utteranceID string
+	text        string // normalized
+	audio       uint64
+	at          time.Time
+}
+
+// dupFinals is embedded in sessionState.
+type dupFinals struct {
+	prints []finalPrint
+}
+
+// repeatFinal reports whether tf repeats a final seen within the window,
+// and otherwise remembers it. Callers hold st.mu.
+func (s *Server) repeatFinal(st *sessionState, tf *gw.TranscriptFinal, now time.Time) bool {
+	if s.dupFinalWindow <= 0 {
+		return false
+	}
+	p := finalPrint{utteranceID: tf.GetUtteranceId(), text: strings.Join(strings.Fields(strings.ToLower(tf.GetText())), " "), audio: tf.GetAudioFingerprint(), at: now}
+	kept := st.prints[:0]
+	match := ""
+	for _, old := range st.prints {
+		if now.Sub(old.at) > s.dupFinalWindow {
+			continue
+		}
+		kept = append(kept, old)
+		if match != "" || old.text != p.text {
+			continue
+		}
+		switch {
+		case p.utteranceID != "" && old.utteranceID == p.utteranceID:
+			match = "utterance"
+		case p.audio != 0 && old.audio == p.audio:
+			match = "fingerprint"
+		}
+	}
+	st.prints = kept
+	if match != "" {
+		metricDuplicateFinals.WithLabelValues(match).Inc()
+		log.Printf("[orch] dropping repeated final sid=%s utterance=%s match=%s text=%q", st.id, p.utteranceID, match, logText(st, tf.GetText()))
+		return true
+	}
+	if len(st.prints) == maxFinalPrints {
+		st.prints = st.prints[1:]
+	}
+	st.prints = append(st.prints, p)
+	return false
+}
@@ -0,0 +1,49 @@
+package orchestrator
+
+import (
+	"testing"
+	"time"
+
+	"yuzu/agent/internal/clock"
+	gw "yuzu/agent/internal/orchestrator/pb"
+)
+
+func TestRepeatFinal(t *testing.T) {
+	t0 := time.Unix(1700000000, 0)
+	s := &Server{sess: map[string]*sessionState{}, clock: clock.NewFake(t0), dupFinalWindow: 10 * time.Second}
+	st := s.getOrCreateSession("s1")
+	final := func(utt, text string, audio uint64) *gw.TranscriptFinal {
+		return &gw.TranscriptFinal{UtteranceId: utt, Text: text, AudioFingerprint: audio}
+	}
+	at := func(ms int) time.Time { return t0.Add(time.Duration(ms) * time.Millisecond) }
+
+	if s.repeatFinal(st, final("u1", "I led the migration.", 0xbeef), at(0)) {
+		t.Fatal("first final dropped")
+	}
+	cases := []struct {
+		name string
+		tf   *gw.TranscriptFinal
+		at   int
+		want bool
+	}{
+		{"re-sent after reconnect", final("u1", "I led the migration.", 0xbeef), 2000, true},
+		{"re-transcribed audio under a new utterance", final("u2", "i led  the migration.", 0xbeef), 3000, true},
+		{"same words, new audio", final("u3", "I led the migration.", 0xcafe), 4000, false},
+		{"same words, no fingerprint", final("u4", "I led the migration.", 0), 5000, false},
+		{"same audio, different words", final("u5", "I led the rollout.", 0xbeef), 6000, false},
+		{"after the window", final("u1", "I led the migration.", 0xbeef), 11000, false},
+	}
+	for _, c := range cases {
+		if got := s.repeatFinal(st, c.tf, at(c.at)); got != c.want {
+			t.Errorf("%s: repeat = %v, want %v", c.name, got, c.want)
+		}
+	}
+	if len(st.prints) > maxFinalPrints {
+		t.Errorf("kept %d prints", len(st.prints))
+	}
+
+	s.dupFinalWindow = 0
+	if s.repeatFinal(st, final("u1", "I led the migration.", 0xbeef), at(11500)) {
+		t.Error("window 0 should disable the check")
+	}
+}
@@ -0,0 +1,44 @@
+package orchestrator
+
+import (
+	"log"
+	"strings"
+
+	gw "yuzu/agent/internal/orchestrator/pb"
+)
+
+// echo.go is a diagnostics mode for deployment bring-up. A session opened
+// with SessionStyle.echo_test answers every final with "You said: …" and
+// the transcript, straight to TTS: no LLM, flow, intents or moderation. One
+// spoken sentence then exercises the whole loop (mic, VAD, STT, the
+// orchestrator, TTS, playback and barge-in) and the reply shows what STT
+// heard. Replies are counted in orch_echo_replies_total.
+
+const (
+	echoPrefix = "You said: "
+	// Said when the final carried no words
+	echoNothing = "I heard you, but couldn't make out any words."
+)
+
+// echoText is the reply to a final transcript in echo mode.
+func echoText(text string) string {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return echoNothing
+	}
+	return echoPrefix + text
+}
+
+// echoFinal speaks text back for turnID. Callers hold st.mu; the commands
+// are returned for sending once it is released.
+func (s *Server) echoFinal(st *sessionState, turnID, text string) []*gw.OrchestratorCommand {
+	reply := echoText(text)
+	// There is no LLM to time, so the turn doesn't count toward latency
+	st.turnLatencyPending = false
+	cmd := &gw.StartTTS{Text: reply, TurnId: turnID, UtteranceId: st.nextAgentUtterance(turnID)}
+	st.record(s.clock.Now(), roleAgent, 0, reply)
+	cmd.SpeakingRate, cmd.PauseMs = s.prosody(st)
+	metricEchoReplies.Inc()
+	log.Printf("[orch] echo test sid=%s turn=%s text=%q", st.id, turnID, logText(st, text))
+	return s.agentSpeech(st, cmd)
+}
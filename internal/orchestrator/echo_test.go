@@ -0,0 +1,49 @@
+package orchestrator
+
+import (
+	"context"
+	"testing"
+
+	"yuzu/agent/internal/clock"
+	gw "yuzu/agent/internal/orchestrator/pb"
+)
+
+func TestEchoTestRepeatsFinals(t *testing.T) {
+	s := &Server{sess: map[string]*sessionState{}, clock: clock.Real, intents: parseIntents(defaultIntents)}
+	st := &sessionState{id: "s1", echoTest: true}
+	s.sess["s1"] = st
+	st.openTurn()
+	var cmds []*gw.OrchestratorCommand
+	send := func(c *gw.OrchestratorCommand) { cmds = append(cmds, c) }
+
+	// Even a command is repeated rather than handled
+	s.handleTranscriptFinal(context.Background(), st, "s1", st.userUtteranceID, " Repeat that, please. ", send)
+	var texts []string
+	mic := false
+	for _, c := range cmds {
+		if tts := c.GetStartTts(); tts != nil {
+			texts = append(texts, tts.GetText())
+		}
+		mic = mic || c.GetStartMicToStt() != nil
+	}
+	if len(texts) != 1 || texts[0] != "You said: Repeat that, please." {
+		t.Fatalf("StartTTS texts = %q, want the final echoed back", texts)
+	}
+	if !mic {
+		t.Error("mic not reopened for the next turn")
+	}
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	if st.llmActive || st.turnLatencyPending {
+		t.Errorf("llmActive=%v turnLatencyPending=%v, want no LLM turn", st.llmActive, st.turnLatencyPending)
+	}
+}
+
+func TestEchoText(t *testing.T) {
+	if got := echoText("  "); got != echoNothing {
+		t.Errorf("echoText(blank) = %q", got)
+	}
+	if got := echoText("hello"); got != "You said: hello" {
+		t.Errorf("echoText = %q", got)
+	}
+}
@@ -0,0 +1,105 @@
+package orchestrator
+
+import (
+	"log"
+	"strings"
+	"time"
+
+	gw "yuzu/agent/internal/orchestrator/pb"
+)
+
+// filler.go masks slow LLM replies. When no sentence has arrived fillerAfter
+// into a turn, the gateway is asked to play a short filler phrase ("Hmm, let
+// me think.") or, with no phrases configured, a soft ambient sound. The
+// filler is stopped as soon as the first real sentence is ready, so it never
+// delays the answer.
+
+const reasonFillerSuperseded = "filler_superseded"
+
+// fillerState is embedded in sessionState.
+type fillerState struct {
+	timer       *time.Timer
+	utteranceID string // filler StartTTS sent and not yet superseded
+	played      int    // rotates through the phrases
+}
+
+// parseFillerPhrases splits ORCH_FILLER_PHRASES on "|".
+func parseFillerPhrases(v string) []string {
+	var out []string
+	for _, p := range strings.Split(v, "|") {
+		if p = strings.TrimSpace(p); p != "" {
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+// armFiller schedules a filler for turnID. Callers must not hold st.mu.
+func (s *Server) armFiller(st *sessionState, turnID string, send func(*gw.OrchestratorCommand)) {
+	if s.fillerAfter <= 0 {
+		return
+	}
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	st.clearFiller()
+	var t *time.Timer
+	t = time.AfterFunc(s.fillerAfter, func() {
+		open := s.lookup(st.id) == st
+		st.mu.Lock()
+		// Superseded, cancelled or the session closed in the meantime
+		// or TTS is down and the filler would not be heard
+		if st.filler.timer != t || st.llmFirstSentence || !open || st.tts.degraded {
+			st.mu.Unlock()
+			return
+		}
+		st.filler.timer = nil
+		cmd := &gw.StartTTS{TurnId: turnID, UtteranceId: st.nextAgentUtterance(turnID), Filler: true}
+		if n := len(s.fillerPhrases); n > 0 {
+			cmd.Text = s.fillerPhrases[st.filler.played%n]
+		}
+		// Match the user's tempo but play without the inter-sentence pause
+		cmd.SpeakingRate, _ = s.prosody(st)
+		st.filler.played++
+		st.filler.utteranceID = cmd.UtteranceId
+		st.mu.Unlock()
+
+		kind := "phrase"
+		if cmd.Text == "" {
+			kind = "ambient"
+		}
+		metricFillers.WithLabelValues(kind).Inc()
+		log.Printf("[orch] LLM slow, playing %s filler sid=%s turn=%s utterance=%s after=%s", kind, st.id, turnID, cmd.UtteranceId, s.fillerAfter)
+		send(&gw.OrchestratorCommand{SessionId: st.id, Cmd: &gw.OrchestratorCommand_StartTts{StartTts: cmd}})
+	})
+	st.filler.timer = t
+}
+
+// takeFiller cancels a pending filler and returns the utterance ID of one
+// already playing, which the caller should stop. Callers hold st.mu.
+func (st *sessionState) takeFiller() string {
+	st.clearFiller()
+	id := st.filler.utteranceID
+	st.filler.utteranceID = ""
+	return id
+}
+
+// clearFiller cancels a pending filler. Callers hold st.mu.
+func (st *sessionState) clearFiller() {
+	if st.filler.timer != nil {
+		st.filler.timer.Stop()
+		st.filler.timer = nil
+	}
+}
+
+// stopFiller tells the gateway to cut the filler for utteranceID short.
+func (s *Server) stopFiller(st *sessionState, utteranceID string, send func(*gw.OrchestratorCommand)) {
+	if utteranceID == "" {
+		return
+	}
+	metricFillers.WithLabelValues("superseded").Inc()
+	log.Printf("[orch] first sentence ready, stopping filler sid=%s utterance=%s", st.id, utteranceID)
+	st.mu.Lock()
+	stop := s.newStop(st, reasonFillerSuperseded, utteranceID)
+	st.mu.Unlock()
+	send(&gw.OrchestratorCommand{SessionId: st.id, Cmd: &gw.OrchestratorCommand_StopTts{StopTts: stop}})
+}
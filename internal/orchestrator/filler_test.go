@@ -0,0 +1,71 @@
+package orchestrator
+
+import (
+	"testing"
+	"time"
+
+	"yuzu/agent/internal/clock"
+	gw "yuzu/agent/internal/orchestrator/pb"
+)
+
+func TestFillerPlaysWhenLLMIsSlowAndStopsOnFirstSentence(t *testing.T) {
+	s := &Server{sess: map[string]*sessionState{}, clock: clock.Real, fillerAfter: 10 * time.Millisecond, fillerPhrases: []string{"Hmm.", "One moment."}}
+	st := &sessionState{id: "s1"}
+	s.sess["s1"] = st
+	turnID, _ := st.openTurn()
+	cmds := make(chan *gw.OrchestratorCommand, 4)
+	send := func(c *gw.OrchestratorCommand) { cmds <- c }
+
+	s.armFiller(st, turnID, send)
+	var filler *gw.StartTTS
+	select {
+	case c := <-cmds:
+		filler = c.GetStartTts()
+	case <-time.After(time.Second):
+		t.Fatal("no filler after the threshold")
+	}
+	if filler == nil || !filler.Filler || filler.Text != "Hmm." || filler.UtteranceId != "t1-a1" {
+		t.Fatalf("filler = %v, want StartTTS{filler, Hmm., t1-a1}", filler)
+	}
+
+	// The first real sentence supersedes it
+	st.mu.Lock()
+	id := st.takeFiller()
+	st.mu.Unlock()
+	s.stopFiller(st, id, send)
+	if c := <-cmds; c.GetStopTts().GetReason() != reasonFillerSuperseded || c.GetStopTts().GetUtteranceId() != "t1-a1" {
+		t.Fatalf("command = %v, want StopTTS %s", c, reasonFillerSuperseded)
+	}
+
+	// A reply that beats the threshold plays no filler at all; the next
+	// one that doesn't rotates to the second phrase
+	s.armFiller(st, turnID, send)
+	st.mu.Lock()
+	if id := st.takeFiller(); id != "" {
+		t.Errorf("takeFiller before the threshold = %q, want none", id)
+	}
+	st.mu.Unlock()
+	s.armFiller(st, turnID, send)
+	select {
+	case c := <-cmds:
+		if c.GetStartTts().GetText() != "One moment." {
+			t.Errorf("second filler = %v, want the next phrase", c)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("no second filler")
+	}
+	select {
+	case c := <-cmds:
+		t.Errorf("unexpected command %v", c)
+	case <-time.After(30 * time.Millisecond):
+	}
+}
+
+func TestParseFillerPhrases(t *testing.T) {
+	if got := parseFillerPhrases(" Hmm. | |Let me think. "); len(got) != 2 || got[0] != "Hmm." || got[1] != "Let me think." {
+		t.Errorf("parseFillerPhrases = %q", got)
+	}
+	if got := parseFillerPhrases(""); got != nil {
+		t.Errorf("empty = %q, want nil (ambient)", got)
+	}
+}
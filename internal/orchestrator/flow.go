@@ -0,0 +1,238 @@
+package orchestrator
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"yuzu/agent/internal/errdefs"
+)
+
+// flow.go runs an interview flow: an ordered list of stages whose
+// instructions are appended to the system prompt. A stage with max_turns
+// hands over to the next one after that many candidate answers; one with
+// max_seconds hands over at the first answer after its time budget ran
+// out, and that reply also carries a transition prompt so the agent says it
+// is moving on rather than switching topic abruptly. The last stage runs
+// until the session ends. Each stage's time and answers are recorded as a
+// phase in the session summary (see close.go). Sessions snapshot the flow
+// at SessionOpen, so a flow swapped in through the admin API (see admin.go)
+// only reaches live sessions when asked to.
+
+// Flow is an interview flow definition. Instructions apply in every stage,
+// ahead of the stage's own.
+type Flow struct {
+	Name         string      `json:"name"`
+	Instructions string      `json:"instructions,omitempty"`
+	Stages       []FlowStage `json:"stages"`
+}
+
+// FlowStage is one step of a flow.
+type FlowStage struct {
+	ID           string `json:"id"`
+	Instructions string `json:"instructions"`
+	MaxTurns     int    `json:"max_turns,omitempty"`   // 0 stays in the stage
+	MaxSeconds   int    `json:"max_seconds,omitempty"` // time budget; 0 is unlimited
+	// Transition is added to the prompt of the reply that leaves the stage
+	// because its time ran out; empty uses defaultTransition.
+	Transition string `json:"transition,omitempty"`
+	// Fallbacks are spoken instead of a reply while the LLM can't answer
+	// (see playbook.go); empty uses ORCH_LLM_CANNED.
+	Fallbacks []CannedReply `json:"fallbacks,omitempty"`
+}
+
+const (
+	maxFlowStages = 32
+
+	defaultTransition = "Time for the previous topic is up. Do not follow up on the last answer; briefly say something like \"Let's move to the next topic\" and continue with the instructions above."
+)
+
+// Why a phase ended, as recorded in the summary and the phase histogram
+const (
+	phaseEndTurns       = "turns"
+	phaseEndTime        = "time"
+	phaseEndFlowChanged = "flow_changed"
+	phaseEndSession     = "session_end"
+)
+
+// Validate checks a flow before it is installed.
+func (f *Flow) Validate() error {
+	if f.Name == "" {
+		return fmt.Errorf("flow: name is required")
+	}
+	if len(f.Stages) == 0 || len(f.Stages) > maxFlowStages {
+		return fmt.Errorf("flow %q: needs 1-%d stages", f.Name, maxFlowStages)
+	}
+	seen := map[string]bool{}
+	total := len(f.Instructions)
+	for i, s := range f.Stages {
+		if s.ID == "" || seen[s.ID] {
+			return fmt.Errorf("flow %q: stage %d needs a unique id", f.Name, i)
+		}
+		seen[s.ID] = true
+		if s.MaxTurns < 0 || s.MaxSeconds < 0 {
+			return fmt.Errorf("flow %q: stage %s: max_turns and max_seconds must be >= 0", f.Name, s.ID)
+		}
+		if err := validateCanned(s.Fallbacks); err != nil {
+			return fmt.Errorf("flow %q: stage %s: %v", f.Name, s.ID, err)
+		}
+		total += len(s.Instructions) + len(s.Transition)
+		for _, c := range s.Fallbacks {
+			total += len(c.Text)
+		}
+	}
+	if total > 4*maxAdminPromptLen {
+		return fmt.Errorf("flow %q: instructions exceed %d characters", f.Name, 4*maxAdminPromptLen)
+	}
+	return nil
+}
+
+// loadFlowFile reads the initial flow from ORCH_FLOW_FILE; nil when unset.
+func loadFlowFile(path string) (*Flow, error) {
+	if path == "" {
+		return nil, nil
+	}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, &errdefs.ConfigError{Key: "ORCH_FLOW_FILE", Msg: err.Error()}
+	}
+	var f Flow
+	if err := json.Unmarshal(b, &f); err != nil {
+		return nil, &errdefs.ConfigError{Key: "ORCH_FLOW_FILE", Msg: "invalid JSON: " + err.Error()}
+	}
+	if err := f.Validate(); err != nil {
+		return nil, &errdefs.ConfigError{Key: "ORCH_FLOW_FILE", Msg: err.Error()}
+	}
+	return &f, nil
+}
+
+// sessionFlow parses the flow a session preset sent in SessionOpen; nil
+// when none was sent or it is invalid, leaving the deployment flow in place.
+func sessionFlow(sid, raw string) *Flow {
+	if raw == "" {
+		return nil
+	}
+	var f Flow
+	err := json.Unmarshal([]byte(raw), &f)
+	if err == nil {
+		err = f.Validate()
+	}
+	if err != nil {
+		log.Printf("[orch] invalid session flow sid=%s: %v; using the deployment flow", sid, err)
+		return nil
+	}
+	return &f
+}
+
+// flowState is embedded in sessionState.
+type flowState struct {
+	flow       *Flow
+	ownFlow    bool // from the session's preset; admin flow changes skip it
+	stage      int
+	stageTurns int       // candidate answers in the current stage
+	stageStart time.Time // when the current stage began
+	phases     []phaseTiming
+}
+
+// phaseTiming is one stage's time in the session.
+type phaseTiming struct {
+	Stage      string    `json:"stage"`
+	StartedAt  time.Time `json:"started_at"`
+	EndedAt    time.Time `json:"ended_at"`
+	Seconds    float64   `json:"seconds"`
+	Turns      int       `json:"turns"`
+	BudgetSecs int       `json:"budget_seconds,omitempty"`
+	EndedBy    string    `json:"ended_by"` // turns, time, flow_changed, session_end
+}
+
+// setFlow switches to f at now, keeping the stage position where it still
+// exists.
+func (fs *flowState) setFlow(f *Flow, now time.Time) {
+	if f == fs.flow {
+		return
+	}
+	fs.endPhase(phaseEndFlowChanged, now)
+	fs.flow = f
+	if f == nil {
+		fs.stage, fs.stageTurns = 0, 0
+		return
+	}
+	if fs.stage >= len(f.Stages) {
+		fs.stage, fs.stageTurns = len(f.Stages)-1, 0
+	}
+	fs.stageStart = now
+}
+
+// instructions returns the flow's prompt section for the current stage, or "".
+func (fs *flowState) instructions() string {
+	if fs.flow == nil {
+		return ""
+	}
+	return joinSections(fs.flow.Instructions, fs.flow.Stages[fs.stage].Instructions)
+}
+
+// answered counts a candidate answer at now and moves on when the stage is
+// done. It returns the new stage ID when the stage changed, and the
+// transition prompt when it changed because the time budget ran out.
+func (fs *flowState) answered(now time.Time) (next, transition string) {
+	if fs.flow == nil {
+		return "", ""
+	}
+	fs.stageTurns++
+	cur := fs.flow.Stages[fs.stage]
+	if fs.stage == len(fs.flow.Stages)-1 {
+		return "", ""
+	}
+	overTime := cur.MaxSeconds > 0 && now.Sub(fs.stageStart) >= time.Duration(cur.MaxSeconds)*time.Second
+	overTurns := cur.MaxTurns > 0 && fs.stageTurns >= cur.MaxTurns
+	switch {
+	case overTurns:
+		fs.endPhase(phaseEndTurns, now)
+	case overTime:
+		fs.endPhase(phaseEndTime, now)
+		transition = cur.Transition
+		if transition == "" {
+			transition = defaultTransition
+		}
+	default:
+		return "", ""
+	}
+	fs.stage++
+	fs.stageTurns = 0
+	fs.stageStart = now
+	return fs.flow.Stages[fs.stage].ID, transition
+}
+
+// endPhase records the current stage as a finished phase.
+func (fs *flowState) endPhase(endedBy string, now time.Time) {
+	if fs.flow == nil || fs.stageStart.IsZero() {
+		return
+	}
+	cur := fs.flow.Stages[fs.stage]
+	p := phaseTiming{
+		Stage:      cur.ID,
+		StartedAt:  fs.stageStart,
+		EndedAt:    now,
+		Seconds:    now.Sub(fs.stageStart).Seconds(),
+		Turns:      fs.stageTurns,
+		BudgetSecs: cur.MaxSeconds,
+		EndedBy:    endedBy,
+	}
+	fs.phases = append(fs.phases, p)
+	fs.stageStart = time.Time{}
+	metricFlowPhaseSeconds.WithLabelValues(endedBy).Observe(p.Seconds)
+}
+
+// advanceFlow counts a candidate answer towards the current stage. It
+// returns the transition prompt for the reply when the stage's time ran out.
+// Callers hold st.mu.
+func (s *Server) advanceFlow(st *sessionState) string {
+	next, transition := st.flowState.answered(s.clock.Now())
+	if next != "" {
+		metricFlowStageChanges.Inc()
+		log.Printf("[orch] flow stage -> %s sid=%s timed_out=%t", next, st.id, transition != "")
+	}
+	return transition
+}
@@ -0,0 +1,112 @@
+package orchestrator
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"yuzu/agent/internal/clock"
+	gw "yuzu/agent/internal/orchestrator/pb"
+)
+
+func TestFlowTimeBudgetTransitions(t *testing.T) {
+	fc := clock.NewFake(time.Unix(1700000000, 0))
+	s := &Server{sess: map[string]*sessionState{}, clock: fc}
+	f := &Flow{Name: "screen", Stages: []FlowStage{
+		{ID: "intro", Instructions: "Greet.", MaxSeconds: 60},
+		{ID: "deep", Instructions: "Go deep.", MaxSeconds: 120, MaxTurns: 5, Transition: "Wrap up and move on."},
+		{ID: "close", Instructions: "Close."},
+	}}
+	if err := f.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	st := &sessionState{id: "s1"}
+	st.flowState.setFlow(f, fc.Now())
+
+	// Within budget the stage keeps going
+	fc.Advance(30 * time.Second)
+	if tr := s.advanceFlow(st); tr != "" || st.stage != 0 {
+		t.Fatalf("in budget: transition %q stage %d", tr, st.stage)
+	}
+	// The first answer after the budget hands over with the default prompt
+	fc.Advance(45 * time.Second)
+	if tr := s.advanceFlow(st); tr != defaultTransition || st.instructions() != "Go deep." {
+		t.Fatalf("over budget: transition %q stage %q", tr, st.instructions())
+	}
+	fc.Advance(121 * time.Second)
+	if tr := s.advanceFlow(st); tr != "Wrap up and move on." || st.instructions() != "Close." {
+		t.Fatalf("custom transition %q stage %q", tr, st.instructions())
+	}
+	// The last stage has nowhere to go
+	fc.Advance(time.Hour)
+	if tr := s.advanceFlow(st); tr != "" || st.stage != 2 {
+		t.Fatalf("last stage: transition %q stage %d", tr, st.stage)
+	}
+
+	st.flowState.endPhase(phaseEndSession, fc.Now())
+	sum := st.summarize("idle_exit", fc.Now())
+	var got []string
+	for _, p := range sum.Phases {
+		got = append(got, p.Stage+":"+p.EndedBy)
+	}
+	if strings.Join(got, ",") != "intro:time,deep:time,close:session_end" {
+		t.Fatalf("phases = %v", got)
+	}
+	if p := sum.Phases[0]; p.Seconds != 75 || p.Turns != 2 || p.BudgetSecs != 60 {
+		t.Errorf("intro phase = %+v, want 75s, 2 turns, budget 60", p)
+	}
+}
+
+func TestFlowTurnLimitWinsOverTime(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	var fs flowState
+	fs.setFlow(&Flow{Name: "f", Stages: []FlowStage{{ID: "a", MaxTurns: 1, MaxSeconds: 10}, {ID: "b"}}}, now)
+	next, tr := fs.answered(now.Add(time.Minute))
+	if next != "b" || tr != "" {
+		t.Fatalf("answered = %q, %q; want b without a transition prompt", next, tr)
+	}
+	if len(fs.phases) != 1 || fs.phases[0].EndedBy != phaseEndTurns {
+		t.Errorf("phases = %+v", fs.phases)
+	}
+}
+
+func TestSessionPresetFlowAndBargeIn(t *testing.T) {
+	s := &Server{sess: map[string]*sessionState{}, clock: clock.Real, adminToken: "admin-secret"}
+	st := s.getOrCreateSession("s1")
+	fs := &fakeStream{}
+	style := &gw.SessionStyle{
+		FlowJson:       `{"name":"screen","stages":[{"id":"intro","instructions":"Keep it short."}]}`,
+		BargeInMinRms:  900,
+		BargeInGuardMs: 400,
+	}
+	s.handleSessionOpen(st, "s1", "", style, fs)
+	if got := st.flowState.instructions(); got != "Keep it short." {
+		t.Fatalf("preset flow not applied, stage = %q", got)
+	}
+	var arm *gw.ArmBargeIn
+	for _, c := range fs.sent {
+		if a := c.GetArmBargeIn(); a != nil {
+			arm = a
+		}
+	}
+	if arm == nil || arm.MinRms != 900 || arm.GuardMs != 400 || st.minRMS != 900 {
+		t.Fatalf("ArmBargeIn = %v, minRMS %.0f; want the preset thresholds", arm, st.minRMS)
+	}
+
+	// A deployment flow pushed live leaves the session's own flow alone
+	body := `{"flow":{"name":"deploy","stages":[{"id":"x","instructions":"Deployment flow."}]},"apply_live":true}`
+	if code, _ := adminDo(t, s.AdminHandler(), http.MethodPut, "/admin/flow", "admin-secret", body); code != 200 {
+		t.Fatalf("PUT flow = %d", code)
+	}
+	if got := st.flowState.instructions(); got != "Keep it short." {
+		t.Errorf("apply_live replaced the preset flow: %q", got)
+	}
+
+	// An invalid flow falls back to the deployment flow
+	bad := s.getOrCreateSession("s2")
+	s.handleSessionOpen(bad, "s2", "", &gw.SessionStyle{FlowJson: `{"name":"x","stages":[]}`}, &fakeStream{})
+	if got := bad.flowState.instructions(); got != "Deployment flow." {
+		t.Errorf("invalid preset flow: stage = %q, want the deployment flow", got)
+	}
+}
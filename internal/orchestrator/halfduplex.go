@@ -0,0 +1,66 @@
+package orchestrator
+
+import (
+	"log"
+
+	gw "yuzu/agent/internal/orchestrator/pb"
+)
+
+// halfduplex.go keeps the agent from transcribing itself when the gateway
+// has neither ducking nor echo cancellation. With ORCH_HALF_DUPLEX set, the
+// mic stops streaming to STT once TTS audio is actually playing
+// (first_audio) and resumes when playback stops. Barge-in keeps working
+// meanwhile: it runs on the gateway's energy features, not on STT, and when
+// it fires the mic is reopened at once with ORCH_HALF_DUPLEX_PREROLL_MS of
+// buffered audio so the words that interrupted the agent reach STT. A
+// natural stop reopens without pre-roll, since that buffer holds the
+// agent's own voice. ORCH_PIPELINE_TURNS keeps the mic open instead (see
+// pipeline.go).
+
+// gateMic stops mic-to-STT for the playing TTS. Callers must not hold st.mu.
+func (s *Server) gateMic(st *sessionState, send func(*gw.OrchestratorCommand)) {
+	if !s.halfDuplex || s.pipelineTurns {
+		return
+	}
+	st.mu.Lock()
+	already := st.micGated
+	st.micGated = true
+	st.mu.Unlock()
+	if already {
+		return
+	}
+	metricHalfDuplex.WithLabelValues("gate").Inc()
+	log.Printf("[orch] half-duplex: mic to STT stopped during TTS sid=%s", st.id)
+	send(&gw.OrchestratorCommand{SessionId: st.id, Cmd: &gw.OrchestratorCommand_StopMicToStt{StopMicToStt: &gw.StopMicToSTT{}}})
+}
+
+// ungateMic resumes mic-to-STT under the current turn when playback
+// stops. It is a no-op unless gateMic stopped the mic. Callers must not
+// hold st.mu.
+func (s *Server) ungateMic(st *sessionState, send func(*gw.OrchestratorCommand)) {
+	st.mu.Lock()
+	cmds := st.listenCmds(nil, nil, s.reopenMic(st, false))
+	st.mu.Unlock()
+	for _, c := range cmds {
+		send(c)
+	}
+}
+
+// reopenMic clears the gate and returns the StartMicToSTT that resumes the
+// current turn, nil when the mic is not gated; bargeIn adds the pre-roll.
+// Callers hold st.mu.
+func (s *Server) reopenMic(st *sessionState, bargeIn bool) *gw.StartMicToSTT {
+	if !st.micGated {
+		return nil
+	}
+	st.micGated = false
+	cmd := &gw.StartMicToSTT{TurnId: st.turnID, UtteranceId: st.userUtteranceID}
+	event := "resume"
+	if bargeIn {
+		cmd.PrerollMs = uint32(s.halfDuplexPreroll.Milliseconds())
+		event = "resume_barge_in"
+	}
+	metricHalfDuplex.WithLabelValues(event).Inc()
+	log.Printf("[orch] half-duplex: mic to STT resumed sid=%s barge_in=%t preroll_ms=%d", st.id, bargeIn, cmd.PrerollMs)
+	return cmd
+}
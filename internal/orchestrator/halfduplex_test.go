@@ -0,0 +1,54 @@
+package orchestrator
+
+import (
+	"testing"
+	"time"
+
+	"yuzu/agent/internal/clock"
+	gw "yuzu/agent/internal/orchestrator/pb"
+)
+
+func TestHalfDuplexGatesMicDuringPlayback(t *testing.T) {
+	s := &Server{sess: map[string]*sessionState{}, clock: clock.Real, halfDuplex: true, halfDuplexPreroll: 300 * time.Millisecond}
+	st := &sessionState{id: "s1"}
+	s.sess["s1"] = st
+	st.openTurn()
+	var cmds []*gw.OrchestratorCommand
+	send := func(c *gw.OrchestratorCommand) { cmds = append(cmds, c) }
+
+	s.handleTTSEvent(st, "started", 0, "", "", send)
+	if len(cmds) != 0 {
+		t.Fatalf("started sent %v; the mic stays open until audio plays", cmds)
+	}
+	s.handleTTSEvent(st, "first_audio", 120, "", "", send)
+	s.handleTTSEvent(st, "first_audio", 0, "", "", send) // next sentence
+	if len(cmds) != 1 || cmds[0].GetStopMicToStt() == nil {
+		t.Fatalf("first_audio sent %v, want one StopMicToSTT", cmds)
+	}
+	s.handleTTSEvent(st, "stopped", 0, "", "", send)
+	start := cmds[len(cmds)-1].GetStartMicToStt()
+	if len(cmds) != 2 || start == nil || start.UtteranceId != "t1-u" || start.PrerollMs != 0 {
+		t.Fatalf("stopped sent %v, want StartMicToSTT for t1-u without pre-roll", cmds)
+	}
+
+	// A barge-in reopens the mic at once, with pre-roll; the stop that
+	// follows has nothing left to do
+	cmds = nil
+	s.handleTTSEvent(st, "first_audio", 0, "", "", send)
+	st.mu.Lock()
+	cmds = append(cmds, s.bargeInCmds(st)...)
+	st.mu.Unlock()
+	s.handleTTSEvent(st, "stopped", 0, "", "", send)
+	if len(cmds) != 3 || cmds[1].GetStopTts() == nil || cmds[2].GetStartMicToStt().GetPrerollMs() != 300 {
+		t.Fatalf("barge-in sent %v, want StopMicToSTT, StopTTS, then StartMicToSTT with 300ms pre-roll", cmds)
+	}
+
+	// Off by default
+	s.halfDuplex = false
+	cmds = nil
+	s.handleTTSEvent(st, "first_audio", 0, "", "", send)
+	s.handleTTSEvent(st, "stopped", 0, "", "", send)
+	if len(cmds) != 0 {
+		t.Errorf("half-duplex off sent %v", cmds)
+	}
+}
@@ -0,0 +1,188 @@
+package orchestrator
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+	"sort"
+	"time"
+
+	"yuzu/agent/internal/errdefs"
+	gw "yuzu/agent/internal/orchestrator/pb"
+)
+
+// heartbeat.go tracks whether each session's gateway is still there. Any
+// event counts as a sign of life; gateways also send a Heartbeat every few
+// seconds so a session where nothing else is happening isn't mistaken for
+// a dead one. A session silent for ORCH_SESSION_SILENT_MS (default 15000)
+// is listed on /livez/sessions on the probe port (behind the admin
+// token, like /admin/, since it names live sessions), and one silent for
+// ORCH_SESSION_DEAD_MS (default 90000, 0 disables) is closed with reason
+// heartbeat_timeout. Only sessions whose gateway has sent a heartbeat are
+// closed: older gateways don't send them, and their sessions can be quiet
+// for long stretches without anything being wrong. A stream that drops is
+// handled by close.go; this covers streams that stay open with nobody on
+// the other end. The check runs from WatchLiveness, which cmd/orchestrator
+// starts and stops on shutdown.
+//
+// The silent count is exported as orch_sessions_silent; closed sessions
+// show up in orch_sessions_closed_total{reason="heartbeat_timeout"}.
+
+const reasonHeartbeatTimeout = "heartbeat_timeout"
+
+// liveness is embedded in sessionState.
+type liveness struct {
+	lastEvent     time.Time // zero until the gateway sends anything
+	lastHeartbeat time.Time
+	heartbeatSeq  uint64
+}
+
+// sawEvent records an event from the gateway; hb is nil unless it was a
+// Heartbeat. Callers must not hold st.mu.
+func (s *Server) sawEvent(st *sessionState, hb *gw.Heartbeat) {
+	now := s.clock.Now()
+	st.mu.Lock()
+	st.lastEvent = now
+	if hb != nil {
+		st.lastHeartbeat, st.heartbeatSeq = now, hb.GetSeq()
+	}
+	st.mu.Unlock()
+}
+
+// silentSession is one entry of /livez/sessions.
+type silentSession struct {
+	SessionID     string     `json:"session_id"`
+	State         string     `json:"state"`
+	LastEvent     time.Time  `json:"last_event_at"`
+	SilentMs      int64      `json:"silent_ms"`
+	LastHeartbeat *time.Time `json:"last_heartbeat_at,omitempty"`
+	HeartbeatSeq  uint64     `json:"heartbeat_seq,omitempty"`
+	Silent        bool       `json:"silent"`
+	ClosesInMs    *int64     `json:"closes_in_ms,omitempty"` // when the session will be closed
+}
+
+// livenessReport lists tracked sessions, most silent first, and the ones
+// past the dead timeout.
+func (s *Server) livenessReport(now time.Time) (all []silentSession, dead []string) {
+	s.mu.Lock()
+	open := make([]*sessionState, 0, len(s.sess))
+	for _, st := range s.sess {
+		open = append(open, st)
+	}
+	s.mu.Unlock()
+
+	for _, st := range open {
+		st.mu.Lock()
+		if st.lastEvent.IsZero() {
+			st.mu.Unlock()
+			continue
+		}
+		silent := now.Sub(st.lastEvent)
+		e := silentSession{
+			SessionID: st.id,
+			State:     string(st.state),
+			LastEvent: st.lastEvent,
+			SilentMs:  silent.Milliseconds(),
+			Silent:    s.sessionSilentAfter > 0 && silent >= s.sessionSilentAfter,
+		}
+		if !st.lastHeartbeat.IsZero() {
+			hb := st.lastHeartbeat
+			e.LastHeartbeat, e.HeartbeatSeq = &hb, st.heartbeatSeq
+			if s.sessionDeadAfter > 0 {
+				left := (s.sessionDeadAfter - silent).Milliseconds()
+				if left <= 0 {
+					left = 0
+					dead = append(dead, st.id)
+				}
+				e.ClosesInMs = &left
+			}
+		}
+		st.mu.Unlock()
+		all = append(all, e)
+	}
+	sort.Slice(all, func(i, j int) bool { return all[i].SilentMs > all[j].SilentMs })
+	return all, dead
+}
+
+// reapSilent closes sessions past the dead timeout and updates the silent
+// gauge. It returns the sessions closed.
+func (s *Server) reapSilent() []string {
+	all, dead := s.livenessReport(s.clock.Now())
+	n := 0
+	for _, e := range all {
+		if e.Silent {
+			n++
+		}
+	}
+	metricSessionsSilent.Set(float64(n))
+	for _, sid := range dead {
+		st := s.lookup(sid)
+		if st == nil {
+			continue
+		}
+		st.mu.Lock()
+		send := st.send
+		st.mu.Unlock()
+		log.Printf("[orch] no word from gateway sid=%s in %s; closing", sid, s.sessionDeadAfter)
+		s.closeSession(sid, reasonHeartbeatTimeout, send)
+	}
+	return dead
+}
+
+// WatchLiveness runs reapSilent every interval until ctx is done. It
+// returns at once when neither liveness timeout is set.
+func (s *Server) WatchLiveness(ctx context.Context, every time.Duration) {
+	if s.sessionSilentAfter <= 0 && s.sessionDeadAfter <= 0 {
+		return
+	}
+	t := s.clock.NewTicker(every)
+	defer t.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C():
+			s.reapSilent()
+		}
+	}
+}
+
+// LivenessHandler serves /livez/sessions: the sessions that have gone
+// silent, or every tracked session with ?all=1. Like AdminHandler it is a
+// 404 unless ORCH_ADMIN_TOKEN is set and needs it as a bearer token.
+func (s *Server) LivenessHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if s.adminToken == "" {
+			http.NotFound(w, r)
+			return
+		}
+		if !adminAuthorized(r, s.adminToken) {
+			errdefs.WriteHTTP(w, &errdefs.AuthError{Reason: "admin token required"})
+			return
+		}
+		if r.Method != http.MethodGet {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		all, _ := s.livenessReport(s.clock.Now())
+		list := []silentSession{}
+		silent := 0
+		for _, e := range all {
+			if e.Silent {
+				silent++
+			}
+			if e.Silent || r.URL.Query().Get("all") == "1" {
+				list = append(list, e)
+			}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]any{
+			"tracked":         len(all),
+			"silent":          silent,
+			"silent_after_ms": s.sessionSilentAfter.Milliseconds(),
+			"dead_after_ms":   s.sessionDeadAfter.Milliseconds(),
+			"sessions":        list,
+		})
+	})
+}
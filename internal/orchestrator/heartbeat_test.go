@@ -0,0 +1,94 @@
+package orchestrator
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"yuzu/agent/internal/clock"
+	gw "yuzu/agent/internal/orchestrator/pb"
+)
+
+func livezRequest(target string) *http.Request {
+	r := httptest.NewRequest("GET", target, nil)
+	r.Header.Set("Authorization", "Bearer admin-secret")
+	return r
+}
+
+func TestSilentSessionsListedAndReaped(t *testing.T) {
+	// Without the admin token the list of sessions is not served
+	rec := httptest.NewRecorder()
+	(&Server{}).LivenessHandler().ServeHTTP(rec, livezRequest("/livez/sessions"))
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("no admin token: status %d", rec.Code)
+	}
+
+	clk := clock.NewFake(time.Unix(1700000000, 0))
+	s := &Server{sess: map[string]*sessionState{}, clock: clk, adminToken: "admin-secret",
+		sessionSilentAfter: 15 * time.Second, sessionDeadAfter: 60 * time.Second}
+
+	// hb sends heartbeats, old is a gateway that never does, quiet has only
+	// been created by a test helper and is not tracked
+	hb, old := s.getOrCreateSession("hb"), s.getOrCreateSession("old")
+	s.getOrCreateSession("quiet")
+	var sent []*gw.OrchestratorCommand
+	hb.send = func(c *gw.OrchestratorCommand) { sent = append(sent, c) }
+	s.sawEvent(hb, &gw.Heartbeat{Seq: 1})
+	s.sawEvent(old, nil)
+
+	clk.Advance(20 * time.Second)
+	rec = httptest.NewRecorder()
+	s.LivenessHandler().ServeHTTP(rec, httptest.NewRequest("GET", "/livez/sessions", nil))
+	if rec.Code != http.StatusUnauthorized {
+		t.Fatalf("no bearer: status %d", rec.Code)
+	}
+	rec = httptest.NewRecorder()
+	s.LivenessHandler().ServeHTTP(rec, livezRequest("/livez/sessions"))
+	var got struct {
+		Tracked, Silent int
+		Sessions        []silentSession
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	if got.Tracked != 2 || got.Silent != 2 || len(got.Sessions) != 2 {
+		t.Fatalf("livez = %s", rec.Body.String())
+	}
+	for _, e := range got.Sessions {
+		if e.SessionID == "hb" && (e.HeartbeatSeq != 1 || e.ClosesInMs == nil || *e.ClosesInMs != 40000) {
+			t.Errorf("hb entry = %+v", e)
+		}
+		if e.SessionID == "old" && e.ClosesInMs != nil {
+			t.Errorf("old gateway scheduled for closing: %+v", e)
+		}
+	}
+
+	// A heartbeat brings a session back; only ?all=1 still lists it
+	s.sawEvent(hb, &gw.Heartbeat{Seq: 2})
+	rec = httptest.NewRecorder()
+	s.LivenessHandler().ServeHTTP(rec, livezRequest("/livez/sessions"))
+	json.Unmarshal(rec.Body.Bytes(), &got)
+	if got.Silent != 1 || len(got.Sessions) != 1 || got.Sessions[0].SessionID != "old" {
+		t.Fatalf("after heartbeat: %s", rec.Body.String())
+	}
+	rec = httptest.NewRecorder()
+	s.LivenessHandler().ServeHTTP(rec, livezRequest("/livez/sessions?all=1"))
+	json.Unmarshal(rec.Body.Bytes(), &got)
+	if len(got.Sessions) != 2 {
+		t.Fatalf("all=1: %s", rec.Body.String())
+	}
+
+	// Past the dead timeout only the heartbeating session is closed
+	clk.Advance(61 * time.Second)
+	if dead := s.reapSilent(); len(dead) != 1 || dead[0] != "hb" {
+		t.Fatalf("reaped %v", dead)
+	}
+	if s.lookup("hb") != nil || s.lookup("old") == nil {
+		t.Error("wrong sessions left open")
+	}
+	if len(sent) == 0 || sent[0].GetStopAll().GetReason() != reasonHeartbeatTimeout || sent[len(sent)-1].GetAck() == nil {
+		t.Errorf("sent %v", sent)
+	}
+}
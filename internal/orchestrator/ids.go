@@ -0,0 +1,100 @@
+package orchestrator
+
+import (
+	"fmt"
+	"log"
+	"strings"
+)
+
+// ids.go makes the orchestrator the single authority for turn and utterance
+// IDs. A turn is one user utterance plus the agent reply to it:
+//
+//	turn:             t<N>
+//	user utterance:   t<N>-u      (STT may append ".<k>" when it rolls over)
+//	agent utterance:  t<N>-a<k>   (one per StartTTS)
+//
+// Gateways echo these IDs on transcripts and TTS events so logs, the store and
+// metrics from every component can be joined on (session_id, turn_id).
+
+// maxRecentAgentUtterances bounds how many issued agent IDs are remembered
+// for validating late TTS events.
+const maxRecentAgentUtterances = 32
+
+// idState is embedded in sessionState.
+type idState struct {
+	turnSeq         int
+	turnID          string // turn currently listening for user speech
+	userUtteranceID string
+	agentSeq        int // session-wide so IDs stay unique across overlapping turns
+	recentAgent     []string
+}
+
+// openTurn issues the next turn and its user utterance ID.
+func (st *sessionState) openTurn() (turnID, utteranceID string) {
+	st.turnSeq++
+	st.turnID = fmt.Sprintf("t%d", st.turnSeq)
+	st.userUtteranceID = st.turnID + "-u"
+	return st.turnID, st.userUtteranceID
+}
+
+// nextAgentUtterance issues an agent utterance ID within turnID.
+func (st *sessionState) nextAgentUtterance(turnID string) string {
+	st.agentSeq++
+	id := fmt.Sprintf("%s-a%d", turnID, st.agentSeq)
+	st.recentAgent = append(st.recentAgent, id)
+	if n := len(st.recentAgent); n > maxRecentAgentUtterances {
+		st.recentAgent = append([]string(nil), st.recentAgent[n-maxRecentAgentUtterances:]...)
+	}
+	return id
+}
+
+// idMatch classifies an echoed ID against the ones we issued.
+type idMatch string
+
+const (
+	idOK       idMatch = "ok"
+	idMissing  idMatch = "missing"
+	idMismatch idMatch = "mismatch"
+)
+
+// checkUserUtterance validates an echoed transcript utterance ID. It only
+// compares: interims and the final of one utterance all match, and the ID is
+// used up when answerFinal opens the next turn. Callers hold st.mu.
+func (st *sessionState) checkUserUtterance(got string) idMatch {
+	if got == "" {
+		return idMissing
+	}
+	if matchesIssued(st.userUtteranceID, got) {
+		return idOK
+	}
+	return idMismatch
+}
+
+// checkAgentUtterance validates an echoed TTS utterance ID.
+func (st *sessionState) checkAgentUtterance(got string) idMatch {
+	if got == "" {
+		return idMissing
+	}
+	for _, id := range st.recentAgent {
+		if id == got {
+			return idOK
+		}
+	}
+	return idMismatch
+}
+
+// matchesIssued reports whether got is issued or an STT rollover of it.
+func matchesIssued(issued, got string) bool {
+	if issued == "" {
+		return false
+	}
+	return got == issued || strings.HasPrefix(got, issued+".")
+}
+
+// recordIDEcho updates metrics and logs non-ok echoes.
+func recordIDEcho(sid, event, got string, m idMatch) {
+	metricIDEcho.WithLabelValues(event, string(m)).Inc()
+	if m != idOK {
+		log.Printf("[orch] id echo %s sid=%s event=%s utterance_id=%q", m, sid, event, got)
+	}
+}
@@ -0,0 +1,80 @@
+package orchestrator
+
+import (
+	"context"
+	"testing"
+
+	"yuzu/agent/internal/clock"
+	gw "yuzu/agent/internal/orchestrator/pb"
+)
+
+func TestIssuedIDs(t *testing.T) {
+	st := &sessionState{}
+
+	turn, utt := st.openTurn()
+	if turn != "t1" || utt != "t1-u" {
+		t.Fatalf("openTurn = %q %q, want t1 t1-u", turn, utt)
+	}
+	if a := st.nextAgentUtterance(turn); a != "t1-a1" {
+		t.Errorf("nextAgentUtterance = %q, want t1-a1", a)
+	}
+
+	// STT rollovers keep the issued ID as prefix
+	for got, want := range map[string]idMatch{
+		"t1-u":   idOK,
+		"t1-u.2": idOK,
+		"t1-u2":  idMismatch,
+		"utt-1":  idMismatch,
+		"":       idMissing,
+	} {
+		if m := st.checkUserUtterance(got); m != want {
+			t.Errorf("checkUserUtterance(%q) = %s, want %s", got, m, want)
+		}
+	}
+
+	// Agent sequence is session-wide so overlapping turns never collide
+	turn, _ = st.openTurn()
+	if a := st.nextAgentUtterance(turn); a != "t2-a2" {
+		t.Errorf("nextAgentUtterance = %q, want t2-a2", a)
+	}
+	if m := st.checkAgentUtterance("t1-a1"); m != idOK {
+		t.Errorf("late event for earlier turn = %s, want ok", m)
+	}
+	if m := st.checkUserUtterance("t1-u"); m != idMismatch {
+		t.Errorf("stale user utterance = %s, want mismatch", m)
+	}
+}
+
+func TestInterimsLeaveTheUtteranceID(t *testing.T) {
+	s := &Server{sess: map[string]*sessionState{}, clock: clock.Real, requireIDs: true}
+	st := &sessionState{id: "s1", echoTest: true}
+	s.sess["s1"] = st
+	st.openTurn()
+	var replies []string
+	send := func(c *gw.OrchestratorCommand) {
+		if tts := c.GetStartTts(); tts != nil {
+			replies = append(replies, tts.GetText())
+		}
+	}
+
+	for i := 0; i < 3; i++ {
+		st.mu.Lock()
+		m := st.checkUserUtterance("t1-u")
+		st.mu.Unlock()
+		if m != idOK {
+			t.Fatalf("interim %d = %s, want ok", i, m)
+		}
+	}
+	s.handleTranscriptFinal(context.Background(), st, "s1", "t1-u", "Hello.", send)
+	if len(replies) != 1 || replies[0] != "You said: Hello." {
+		t.Fatalf("replies = %q, want the final answered", replies)
+	}
+
+	// Answered: the next final under the old ID is stale
+	st.mu.Lock()
+	m := st.checkUserUtterance("t1-u")
+	st.mu.Unlock()
+	if m != idMismatch {
+		t.Errorf("after the final = %s, want mismatch", m)
+	}
+}
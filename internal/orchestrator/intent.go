@@ -0,0 +1,199 @@
+package orchestrator
+
+import (
+	"context"
+	"log"
+	"regexp"
+	"strings"
+
+	gw "yuzu/agent/internal/orchestrator/pb"
+)
+
+// intent.go answers a few short spoken commands without an LLM round trip.
+// A final transcript that is nothing but a command ("repeat that", "louder",
+// "skip this question", "end the interview"), optionally wrapped in "please"
+// or "could you", is matched against anchored patterns and handled here:
+//
+//	repeat   replays the last LLM reply
+//	louder   raises the gateway's playback gain (SetVolume) and confirms
+//	quieter  lowers it
+//	skip     acknowledges at once, then asks the LLM for the next question
+//	end      says goodbye and sends EndInterview
+//
+// Longer utterances always go to the LLM, so an answer that merely mentions
+// "repeat" is never mistaken for a command. ORCH_INTENTS lists the enabled
+// intents ("none" disables the fast path).
+
+type intent string
+
+const (
+	intentRepeat  intent = "repeat"
+	intentLouder  intent = "louder"
+	intentQuieter intent = "quieter"
+	intentSkip    intent = "skip"
+	intentEnd     intent = "end"
+)
+
+const (
+	defaultIntents = "repeat,louder,quieter,skip,end"
+	// Commands are short; anything longer is treated as an answer
+	maxIntentWords = 8
+
+	volumeStep    = 1.25
+	minVolumeGain = 0.5
+	maxVolumeGain = 2.0
+
+	// Sent to the LLM in place of the candidate's words after a skip
+	skipPrompt = "(The candidate asked to skip the last question. Do not return to it or comment on it; ask the next question.)"
+)
+
+// Filler words allowed around a command
+const (
+	intentPrefix = `^(?:(?:please|sorry|um|uh|okay|ok|so|hey|can you|could you|would you|will you|can we|could we|let's)\s+)*`
+	intentSuffix = `(?:\s+(?:please|again|for me|a bit|a little|now))*$`
+)
+
+var intentPatterns = []struct {
+	intent intent
+	re     *regexp.Regexp
+}{
+	{intentRepeat, regexp.MustCompile(intentPrefix + `(?:repeat(?: that| the question| it| yourself| what you said)?|say (?:that|it) (?:again|one more time)|come again|pardon(?: me)?|what did you say)` + intentSuffix)},
+	{intentLouder, regexp.MustCompile(intentPrefix + `(?:(?:speak|talk) up|(?:be |speak |talk )?louder|i can't hear you(?: well)?|turn (?:it|the volume|yourself) up)` + intentSuffix)},
+	{intentQuieter, regexp.MustCompile(intentPrefix + `(?:(?:be |speak |talk )?(?:quieter|softer|more quietly)|turn (?:it|the volume|yourself) down|you're too loud)` + intentSuffix)},
+	{intentSkip, regexp.MustCompile(intentPrefix + `(?:skip(?: this| that| the)?(?: question)?|(?:move on to )?(?:the )?next question|move on)` + intentSuffix)},
+	{intentEnd, regexp.MustCompile(intentPrefix + `(?:(?:i(?: want| would like|'d like) to )?(?:end|stop|finish) (?:the|this) (?:interview|call|session)|i'm done with (?:the|this) interview)` + intentSuffix)},
+}
+
+var intentPunct = regexp.MustCompile(`[^a-z' ]+`)
+
+// parseIntents reads ORCH_INTENTS; unknown names are logged and ignored.
+func parseIntents(v string) map[intent]bool {
+	out := map[intent]bool{}
+	for _, name := range strings.Split(v, ",") {
+		name = strings.TrimSpace(strings.ToLower(name))
+		if name == "" {
+			continue
+		}
+		known := false
+		for _, p := range intentPatterns {
+			if string(p.intent) == name {
+				out[p.intent], known = true, true
+			}
+		}
+		if !known {
+			log.Printf("[orch] ORCH_INTENTS: unknown intent %q ignored", name)
+		}
+	}
+	return out
+}
+
+// matchIntent returns the command in text, or "" when it is not one of the
+// enabled intents.
+func matchIntent(text string, enabled map[intent]bool) intent {
+	if len(enabled) == 0 {
+		return ""
+	}
+	norm := strings.ToLower(strings.ReplaceAll(text, "’", "'"))
+	norm = strings.Join(strings.Fields(intentPunct.ReplaceAllString(norm, " ")), " ")
+	if norm == "" || len(strings.Fields(norm)) > maxIntentWords {
+		return ""
+	}
+	for _, p := range intentPatterns {
+		if enabled[p.intent] && p.re.MatchString(norm) {
+			return p.intent
+		}
+	}
+	return ""
+}
+
+// intentState is embedded in sessionState.
+type intentState struct {
+	volume    float32  // playback gain last sent; 0 means unity
+	replyTurn string   // turn of lastReply
+	lastReply []string // sentences of the last LLM reply, for "repeat"
+}
+
+// noteReply remembers an LLM sentence for turnID.
+func (is *intentState) noteReply(turnID, text string) {
+	if turnID != is.replyTurn {
+		is.replyTurn, is.lastReply = turnID, nil
+	}
+	is.lastReply = append(is.lastReply, text)
+}
+
+// stepVolume scales the gain by volumeStep (up) or its inverse, clamped.
+// It returns false when the gain is already at the limit.
+func (is *intentState) stepVolume(up bool) (float32, bool) {
+	cur := float64(is.volume)
+	if cur == 0 {
+		cur = 1
+	}
+	next := cur / volumeStep
+	if up {
+		next = cur * volumeStep
+	}
+	next = clampf(next, minVolumeGain, maxVolumeGain)
+	if next == cur {
+		return is.volume, false
+	}
+	is.volume = float32(next)
+	return is.volume, true
+}
+
+// handleIntent answers in for turnID and reports whether it was handled; when false the utterance goes to the LLM as usual. Callers
+// must not hold st.mu.
+func (s *Server) handleIntent(ctx context.Context, st *sessionState, sid, turnID string, in intent, stage string, send func(*gw.OrchestratorCommand)) bool {
+	var say []string
+	var volume *gw.SetVolume
+	st.mu.Lock()
+	switch in {
+	case intentRepeat:
+		say = append(say, st.intents.lastReply...)
+	case intentLouder, intentQuieter:
+		gain, changed := st.intents.stepVolume(in == intentLouder)
+		switch {
+		case changed:
+			volume = &gw.SetVolume{Gain: gain}
+			say = []string{"Is this better?"}
+		case in == intentLouder:
+			say = []string{"I'm already at my loudest. You may need to turn up your speakers."}
+		default:
+			say = []string{"I'm already at my quietest setting."}
+		}
+	case intentSkip:
+		say = []string{"Sure, let's move on."}
+	case intentEnd:
+		say = []string{"Thank you for your time today. Goodbye!"}
+	}
+	if len(say) == 0 {
+		st.mu.Unlock()
+		return false
+	}
+	// Only the skip path waits on the LLM; the rest would skew turn latency
+	st.turnLatencyPending = in == intentSkip
+	var cmds []*gw.OrchestratorCommand
+	for _, text := range say {
+		cmd := &gw.StartTTS{Text: text, TurnId: turnID, UtteranceId: st.nextAgentUtterance(turnID)}
+		st.record(s.clock.Now(), roleAgent, 0, text)
+		cmd.SpeakingRate, cmd.PauseMs = s.prosody(st)
+		cmds = append(cmds, s.agentSpeech(st, cmd)...)
+	}
+	st.mu.Unlock()
+	s.flushTurnStates(st)
+
+	metricIntents.WithLabelValues(string(in)).Inc()
+	log.Printf("[orch] intent %s sid=%s turn=%s", in, sid, turnID)
+	if volume != nil {
+		send(&gw.OrchestratorCommand{SessionId: sid, Cmd: &gw.OrchestratorCommand_SetVolume{SetVolume: volume}})
+	}
+	for _, cmd := range cmds {
+		send(cmd)
+	}
+	switch in {
+	case intentSkip:
+		go s.startLLM(ctx, sid, turnID, skipPrompt, stage, send)
+	case intentEnd:
+		send(&gw.OrchestratorCommand{SessionId: sid, Cmd: &gw.OrchestratorCommand_EndInterview{EndInterview: &gw.EndInterview{Reason: "candidate_request"}}})
+	}
+	return true
+}
@@ -0,0 +1,102 @@
+package orchestrator
+
+import (
+	"context"
+	"testing"
+
+	"yuzu/agent/internal/clock"
+	gw "yuzu/agent/internal/orchestrator/pb"
+)
+
+func TestMatchIntent(t *testing.T) {
+	all := parseIntents(defaultIntents)
+	cases := []struct {
+		text string
+		want intent
+	}{
+		{"Repeat that, please.", intentRepeat},
+		{"Sorry, could you say that again?", intentRepeat},
+		{"Pardon?", intentRepeat},
+		{"Louder please", intentLouder},
+		{"I can’t hear you.", intentLouder},
+		{"Could you speak a little quieter", ""}, // "a little" only trails
+		{"Turn it down a bit.", intentQuieter},
+		{"Can we skip this question?", intentSkip},
+		{"Next question.", intentSkip},
+		{"I'd like to end the interview.", intentEnd},
+		{"Okay, end the call.", intentEnd},
+		// Answers that mention a command word go to the LLM
+		{"I had to repeat that experiment three times before it worked", ""},
+		{"We decided to skip the migration", ""},
+		{"Goodbye", ""},
+		{"", ""},
+	}
+	for _, c := range cases {
+		if got := matchIntent(c.text, all); got != c.want {
+			t.Errorf("matchIntent(%q) = %q, want %q", c.text, got, c.want)
+		}
+	}
+	if got := matchIntent("repeat that", parseIntents("louder,bogus")); got != "" {
+		t.Errorf("disabled intent matched: %q", got)
+	}
+	if got := matchIntent("repeat that", nil); got != "" {
+		t.Errorf("no intents enabled, matched %q", got)
+	}
+}
+
+func TestIntentVolumeSteps(t *testing.T) {
+	var is intentState
+	g, ok := is.stepVolume(true)
+	if !ok || g != 1.25 {
+		t.Fatalf("first louder = %v, %v; want 1.25", g, ok)
+	}
+	for i := 0; i < 5; i++ {
+		g, _ = is.stepVolume(true)
+	}
+	if g != maxVolumeGain {
+		t.Fatalf("gain = %v, want clamped to %v", g, maxVolumeGain)
+	}
+	if _, ok := is.stepVolume(true); ok {
+		t.Error("step past the maximum reported a change")
+	}
+}
+
+func TestTranscriptFinalIntentSkipsLLM(t *testing.T) {
+	s := &Server{sess: map[string]*sessionState{}, clock: clock.Real, intents: parseIntents(defaultIntents)}
+	st := &sessionState{id: "s1"}
+	s.sess["s1"] = st
+	st.openTurn()
+	var cmds []*gw.OrchestratorCommand
+	send := func(c *gw.OrchestratorCommand) { cmds = append(cmds, c) }
+
+	// "repeat" replays the last LLM reply, sentence by sentence
+	st.mu.Lock()
+	st.intents.noteReply("t0", "What is a goroutine?")
+	st.intents.noteReply("t0", "Take your time.")
+	st.mu.Unlock()
+	s.handleTranscriptFinal(context.Background(), st, "s1", st.userUtteranceID, "Could you repeat the question?", send)
+	var texts []string
+	for _, c := range cmds {
+		if tts := c.GetStartTts(); tts != nil {
+			texts = append(texts, tts.Text)
+		}
+	}
+	if len(texts) != 2 || texts[0] != "What is a goroutine?" || texts[1] != "Take your time." {
+		t.Fatalf("repeat spoke %q, want the last reply", texts)
+	}
+	if st.llmActive {
+		t.Error("repeat started the LLM")
+	}
+
+	cmds = nil
+	s.handleTranscriptFinal(context.Background(), st, "s1", st.userUtteranceID, "Louder, please.", send)
+	if len(cmds) != 3 || cmds[1].GetSetVolume().GetGain() != 1.25 || cmds[2].GetStartTts() == nil {
+		t.Fatalf("louder sent %v, want StartMicToSTT, SetVolume 1.25, StartTTS", cmds)
+	}
+
+	cmds = nil
+	s.handleTranscriptFinal(context.Background(), st, "s1", st.userUtteranceID, "I want to end the interview.", send)
+	if last := cmds[len(cmds)-1]; last.GetEndInterview().GetReason() != "candidate_request" {
+		t.Fatalf("end sent %v last, want EndInterview", last)
+	}
+}
@@ -0,0 +1,83 @@
+package orchestrator
+
+import (
+	"strings"
+	"unicode"
+
+	gw "yuzu/agent/internal/orchestrator/pb"
+)
+
+// intonation.go keeps multi-sentence replies from sounding flat and run-on.
+// Each LLM sentence's StartTTS carries intonation, "question" or
+// "exclamation" from its closing punctuation (quotes and brackets after it
+// don't count), or "" for a statement; the TTS service and the gateway turn
+// it into provider voice settings (see internal/tts/prosody.go). With
+// ORCH_TTS_INTONATION=false it is never set.
+//
+// The pause before a reply's second and later sentences depends on how the
+// one before ended: ORCH_PAUSE_AFTER_STATEMENT_MS (default 250),
+// ORCH_PAUSE_AFTER_QUESTION_MS (default 450) and
+// ORCH_PAUSE_AFTER_EXCLAMATION_MS (default 350). Pace mirroring (see
+// pace.go) scales them as it does ORCH_PACE_PAUSE_MS. A kind set to 0 gets
+// the pace pause alone, as does every reply's first sentence.
+
+const (
+	intonationStatement   = ""
+	intonationQuestion    = "question"
+	intonationExclamation = "exclamation"
+)
+
+// sentenceIntonation classifies a sentence by its closing punctuation.
+func sentenceIntonation(text string) string {
+	t := strings.TrimRightFunc(text, func(r rune) bool {
+		return unicode.IsSpace(r) || strings.ContainsRune(`"'”’)]`, r)
+	})
+	switch {
+	case strings.HasSuffix(t, "?"), strings.HasSuffix(t, "?!"), strings.HasSuffix(t, "!?"):
+		return intonationQuestion
+	case strings.HasSuffix(t, "!"):
+		return intonationExclamation
+	}
+	return intonationStatement
+}
+
+// intonationState is embedded in sessionState: how the last agent sentence
+// of lastTurn ended.
+type intonationState struct {
+	lastTurn string
+	last     string
+}
+
+// sentenceProsody sets the speaking rate, pause and intonation of an LLM
+// sentence of turnID. Callers hold st.mu.
+func (s *Server) sentenceProsody(st *sessionState, turnID string, cmd *gw.StartTTS) {
+	rate, pace := s.prosody(st)
+	cmd.SpeakingRate, cmd.PauseMs = rate, pace
+	kind := sentenceIntonation(cmd.GetText())
+	if s.intonation {
+		cmd.Intonation = kind
+	}
+	prev, sameTurn := st.intonation.last, st.intonation.lastTurn == turnID
+	st.intonation = intonationState{lastTurn: turnID, last: kind}
+	if !sameTurn {
+		return
+	}
+	base := s.pauseAfter[prev]
+	if base <= 0 {
+		return
+	}
+	// Pace mirroring stretches or shortens every pause alike
+	if pace > 0 && s.pacePauseMs > 0 {
+		base = base * int(pace) / s.pacePauseMs
+	}
+	cmd.PauseMs = uint32(base)
+}
+
+// pauseAfterFromEnv reads the ORCH_PAUSE_AFTER_* settings.
+func pauseAfterFromEnv() map[string]int {
+	return map[string]int{
+		intonationStatement:   envInt("ORCH_PAUSE_AFTER_STATEMENT_MS", 250),
+		intonationQuestion:    envInt("ORCH_PAUSE_AFTER_QUESTION_MS", 450),
+		intonationExclamation: envInt("ORCH_PAUSE_AFTER_EXCLAMATION_MS", 350),
+	}
+}
@@ -0,0 +1,67 @@
+package orchestrator
+
+import (
+	"testing"
+	"time"
+
+	gw "yuzu/agent/internal/orchestrator/pb"
+)
+
+func TestSentenceIntonation(t *testing.T) {
+	for text, want := range map[string]string{
+		"Tell me about your last project.": intonationStatement,
+		"What did you build?":              intonationQuestion,
+		`You said "why not?"`:              intonationQuestion,
+		"Really?! ":                        intonationQuestion,
+		"That's great!":                    intonationExclamation,
+		"(That's a first!)":                intonationExclamation,
+		"Is that right? Let me think":      intonationStatement,
+		"":                                 intonationStatement,
+	} {
+		if got := sentenceIntonation(text); got != want {
+			t.Errorf("sentenceIntonation(%q) = %q, want %q", text, got, want)
+		}
+	}
+}
+
+func TestSentenceProsodyPauses(t *testing.T) {
+	s := &Server{paceAdapt: true, paceBaselineWPM: 160, pacePauseMs: 250, intonation: true,
+		pauseAfter: map[string]int{intonationStatement: 250, intonationQuestion: 450, intonationExclamation: 0}}
+	st := &sessionState{}
+	say := func(turn, text string) *gw.StartTTS {
+		cmd := &gw.StartTTS{Text: text, TurnId: turn}
+		s.sentenceProsody(st, turn, cmd)
+		return cmd
+	}
+
+	// A reply's first sentence gets only the pace pause, unknown yet
+	if c := say("t1", "Great!"); c.PauseMs != 0 || c.Intonation != intonationExclamation {
+		t.Fatalf("first sentence = %+v", c)
+	}
+	// After an exclamation, whose pause is 0, still none
+	if c := say("t1", "Why Go?"); c.PauseMs != 0 || c.Intonation != intonationQuestion {
+		t.Fatalf("after exclamation = %+v", c)
+	}
+	if c := say("t1", "Take your time."); c.PauseMs != 450 || c.Intonation != intonationStatement {
+		t.Fatalf("after question = %+v", c)
+	}
+	if c := say("t1", "Go on."); c.PauseMs != 250 {
+		t.Fatalf("after statement = %+v", c)
+	}
+	// A new turn starts over
+	if c := say("t2", "Thanks."); c.PauseMs != 0 {
+		t.Fatalf("first sentence of a new turn = %+v", c)
+	}
+
+	// A slow speaker stretches the pause with the pace pause
+	st.pace.observe(20, 12*time.Second)
+	_, pace := s.prosody(st)
+	if c := say("t2", "Next question."); c.PauseMs != uint32(250*int(pace)/250) || c.PauseMs <= 250 {
+		t.Fatalf("slow speaker after statement = %+v (pace pause %d)", c, pace)
+	}
+
+	s.intonation = false
+	if c := say("t2", "Ready?"); c.Intonation != "" {
+		t.Fatalf("intonation with ORCH_TTS_INTONATION=false = %q", c.Intonation)
+	}
+}
@@ -0,0 +1,55 @@
+package orchestrator
+
+import (
+	"time"
+
+	gw "yuzu/agent/internal/orchestrator/pb"
+)
+
+// listen.go builds the commands that hand the turn to the candidate: up to
+// a StopTTS when the agent is cut off, an ArmBargeIn with the session's
+// thresholds and a StartMicToSTT under the candidate's turn. Sent one by
+// one, a gateway can act on the first before the rest arrive, e.g. stop
+// playback while the mic is still closed and miss the start of what the
+// candidate said. Gateways that list "begin_listening" in
+// SessionOpen.capabilities get two or more of them as one BeginListening,
+// which they apply together; a single command goes out as itself either way.
+//
+// Every hand-over goes through listenCmds: session open (thresholds and
+// mic), barge-in (stop, and the mic when half-duplex closed it), playback
+// ending under half-duplex, a final opening the next turn, and an STT
+// restart.
+
+const capBeginListening = "begin_listening"
+
+// listenCmds returns the commands for a hand-over; nil parts are left out.
+// Callers hold st.mu.
+func (st *sessionState) listenCmds(stop *gw.StopTTS, arm *gw.ArmBargeIn, mic *gw.StartMicToSTT) []*gw.OrchestratorCommand {
+	var out []*gw.OrchestratorCommand
+	if stop != nil {
+		out = append(out, &gw.OrchestratorCommand{SessionId: st.id, Cmd: &gw.OrchestratorCommand_StopTts{StopTts: stop}})
+	}
+	if arm != nil {
+		out = append(out, &gw.OrchestratorCommand{SessionId: st.id, Cmd: &gw.OrchestratorCommand_ArmBargeIn{ArmBargeIn: arm}})
+	}
+	if mic != nil {
+		out = append(out, &gw.OrchestratorCommand{SessionId: st.id, Cmd: &gw.OrchestratorCommand_StartMicToStt{StartMicToStt: mic}})
+	}
+	if !st.beginListening || len(out) < 2 {
+		return out
+	}
+	metricBeginListening.Inc()
+	return []*gw.OrchestratorCommand{{SessionId: st.id, Cmd: &gw.OrchestratorCommand_BeginListening{
+		BeginListening: &gw.BeginListening{StopTts: stop, ArmBargeIn: arm, Mic: mic},
+	}}}
+}
+
+// bargeInCmds stops the agent's speech, at a word boundary with a fade when
+// so configured, and, when half-duplex closed the mic, reopens it with
+// pre-roll. Callers hold st.mu.
+func (s *Server) bargeInCmds(st *sessionState) []*gw.OrchestratorCommand {
+	stop := s.newStop(st, "barge_in", st.playing)
+	stop.Boundary = s.bargeInBoundary
+	stop.FadeMs = uint32(s.bargeInFade / time.Millisecond)
+	return st.listenCmds(stop, nil, s.reopenMic(st, true))
+}
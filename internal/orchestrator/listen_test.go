@@ -0,0 +1,56 @@
+package orchestrator
+
+import (
+	"testing"
+	"time"
+
+	"yuzu/agent/internal/clock"
+	gw "yuzu/agent/internal/orchestrator/pb"
+)
+
+func TestBeginListeningComposite(t *testing.T) {
+	s := &Server{sess: map[string]*sessionState{}, clock: clock.NewFake(time.Unix(1700000000, 0)), halfDuplexPreroll: 300 * time.Millisecond}
+
+	// Session open: thresholds and mic in one command
+	st := s.getOrCreateSession("s1")
+	st.beginListening = true
+	fs := &fakeStream{}
+	s.handleSessionOpen(st, "s1", "", &gw.SessionStyle{BargeInGuardMs: 700}, fs)
+	bl := fs.sent[0].GetBeginListening()
+	if len(fs.sent) != 1 || bl == nil || bl.GetStopTts() != nil || bl.GetArmBargeIn().GetGuardMs() != 700 || bl.GetMic().GetUtteranceId() != "t1-u" {
+		t.Fatalf("session open sent %v", fs.sent)
+	}
+
+	// Barge-in with the mic gated: stop and reopen together, and urgent
+	st.mu.Lock()
+	st.micGated = true
+	cmds := s.bargeInCmds(st)
+	st.mu.Unlock()
+	bl = cmds[0].GetBeginListening()
+	if len(cmds) != 1 || bl.GetStopTts().GetReason() != "barge_in" || bl.GetMic().GetPrerollMs() != 300 || !urgentCommand(cmds[0]) {
+		t.Fatalf("barge-in sent %v", cmds)
+	}
+
+	// A single command is sent as itself
+	st.mu.Lock()
+	cmds = s.bargeInCmds(st)
+	st.mu.Unlock()
+	if len(cmds) != 1 || cmds[0].GetStopTts() == nil {
+		t.Fatalf("ungated barge-in sent %v", cmds)
+	}
+
+	// Gateways without the capability get the parts in order
+	st = s.getOrCreateSession("s2")
+	fs = &fakeStream{}
+	s.handleSessionOpen(st, "s2", "", nil, fs)
+	if len(fs.sent) != 2 || fs.sent[0].GetArmBargeIn() == nil || fs.sent[1].GetStartMicToStt() == nil {
+		t.Fatalf("plain session open sent %v", fs.sent)
+	}
+	st.mu.Lock()
+	st.micGated = true
+	cmds = s.bargeInCmds(st)
+	st.mu.Unlock()
+	if len(cmds) != 2 || cmds[0].GetStopTts() == nil || cmds[1].GetStartMicToStt().GetPrerollMs() != 300 {
+		t.Fatalf("plain barge-in sent %v", cmds)
+	}
+}
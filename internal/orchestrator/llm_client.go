@@ -6,6 +6,8 @@ import (
     "os"
     "time"
 
+    "yuzu/agent/internal/discovery"
+    "yuzu/agent/internal/grpcmw"
     llmpb "yuzu/agent/internal/llm/pb"
     "google.golang.org/grpc"
     "google.golang.org/grpc/credentials/insecure"
@@ -27,9 +29,9 @@ func (s *Server) getLLMClient(ctx context.Context) (llmpb.LLMClient, error) {
         return s.llmClient, nil
     }
 
-    addr := os.Getenv("LLM_ADDR")
-    if addr == "" { addr = ":9092" }
-    conn, err := grpc.DialContext(ctx, addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+    dial := s.llmDial
+    if dial == nil { dial = dialLLMAddr }
+    conn, err := dial(ctx)
     if err != nil { return nil, err }
     client := llmpb.NewLLMClient(conn)
     s.llmConn = conn
@@ -37,6 +39,22 @@ func (s *Server) getLLMClient(ctx context.Context) (llmpb.LLMClient, error) {
     return client, nil
 }
 
+// dialLLMAddr connects to the LLM service at LLM_ADDR, which may also name
+// several replicas, a DNS SRV record or a Consul service (see discovery).
+func dialLLMAddr(ctx context.Context) (*grpc.ClientConn, error) {
+    addr := os.Getenv("LLM_ADDR")
+    if addr == "" { addr = ":9092" }
+    target, dopts, err := discovery.Target("llm", addr)
+    if err != nil { return nil, err }
+    opts := append(grpcmw.TransportFromEnv().DialOptions(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+    opts = append(opts, dopts...)
+    return grpc.DialContext(ctx, target, opts...)
+}
+
+// SetLLMDialer replaces how the LLM connection is made; tests use it to
+// reach an in-process server. Call before serving.
+func (s *Server) SetLLMDialer(dial func(ctx context.Context) (*grpc.ClientConn, error)) { s.llmDial = dial }
+
 // reconnectLLM closes the existing connection and re-dials with exponential backoff.
 func (s *Server) reconnectLLM(ctx context.Context, attempt int) error {
     s.llmMu.Lock()
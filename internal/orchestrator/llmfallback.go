@@ -0,0 +1,64 @@
+package orchestrator
+
+import (
+	"context"
+	"log"
+
+	gw "yuzu/agent/internal/orchestrator/pb"
+)
+
+// llmfallback.go handles replies the LLM service refuses because its
+// circuit breaker for the deployment is open (in-band code "circuit_open").
+// With ORCH_LLM_FALLBACK_DEPLOYMENT set the same request is retried once on
+// that deployment; otherwise, or when the fallback's circuit is open too, the
+// agent speaks a canned line (see playbook.go), or ORCH_LLM_APOLOGY (none
+// stays silent) with ORCH_LLM_PLAYBOOK=false, so the candidate is not left
+// waiting.
+
+const defaultLLMApology = "Sorry, I'm having a little trouble on my end. Could you say that again?"
+
+// llmCircuitOpen retries a refused reply on the fallback deployment or
+// falls back to a canned line or the apology. Callers must not hold st.mu.
+func (s *Server) llmCircuitOpen(ctx context.Context, sid, turnID, userText, stage, deployment string, send func(*gw.OrchestratorCommand)) {
+	if ctx.Err() != nil {
+		return
+	}
+	if fb := s.llmFallbackDeployment; fb != "" && fb != deployment {
+		metricLLMCircuitOpen.WithLabelValues("fallback").Inc()
+		log.Printf("[orch] llm circuit open sid=%s turn=%s deployment=%s; retrying on %s", sid, turnID, deployment, fb)
+		s.startLLMOn(ctx, sid, turnID, userText, stage, fb, send)
+		return
+	}
+	if s.llmPlaybook {
+		metricLLMCircuitOpen.WithLabelValues("canned").Inc()
+		log.Printf("[orch] llm circuit open sid=%s turn=%s deployment=%s; no fallback deployment", sid, turnID, deployment)
+		s.llmFailed(ctx, sid, turnID, userText, llmFailCircuitOpen, send)
+		return
+	}
+	st := s.lookup(sid)
+	if st == nil || s.llmApology == "" {
+		return
+	}
+	metricLLMCircuitOpen.WithLabelValues("apology").Inc()
+	log.Printf("[orch] llm circuit open sid=%s turn=%s deployment=%s; apologizing", sid, turnID, deployment)
+	s.speakCanned(st, turnID, s.llmApology, send)
+}
+
+// speakCanned says text as the agent's reply to turnID in place of an LLM
+// answer, cutting short any filler. Callers must not hold st.mu.
+func (s *Server) speakCanned(st *sessionState, turnID, text string, send func(*gw.OrchestratorCommand)) {
+	st.mu.Lock()
+	filler := st.takeFiller()
+	st.turnLatencyPending = false
+	cmd := &gw.StartTTS{Text: text, TurnId: turnID, UtteranceId: st.nextAgentUtterance(turnID)}
+	st.record(s.clock.Now(), roleAgent, 0, text)
+	cmd.SpeakingRate, cmd.PauseMs = s.prosody(st)
+	cmds := s.agentSpeech(st, cmd)
+	st.mu.Unlock()
+	s.flushTurnStates(st)
+
+	s.stopFiller(st, filler, send)
+	for _, c := range cmds {
+		send(c)
+	}
+}
@@ -0,0 +1,57 @@
+package orchestrator
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+
+	"yuzu/agent/internal/clock"
+	"yuzu/agent/internal/errdefs"
+	llmpb "yuzu/agent/internal/llm/pb"
+	gw "yuzu/agent/internal/orchestrator/pb"
+)
+
+func TestCircuitOpenFallsBackThenApologizes(t *testing.T) {
+	s := &Server{sess: map[string]*sessionState{}, clock: clock.NewFake(time.Unix(1700000000, 0)), llmApology: defaultLLMApology}
+	dials := 0
+	s.llmDial = func(context.Context) (*grpc.ClientConn, error) {
+		dials++
+		return nil, errors.New("no llm in this test")
+	}
+	s.sess["s1"] = &sessionState{id: "s1"}
+	var sent []*gw.OrchestratorCommand
+	send := func(c *gw.OrchestratorCommand) { sent = append(sent, c) }
+	refused := func() *fakeLLMStream {
+		return &fakeLLMStream{msgs: []*llmpb.ServerMessage{
+			{Msg: &llmpb.ServerMessage_Error{Error: &llmpb.Error{Code: errdefs.CodeCircuitOpen, Message: "circuit open"}}},
+		}}
+	}
+	circuitOpen := func(deployment string) func(string) {
+		return func(string) { s.llmCircuitOpen(context.Background(), "s1", "t1", "hello", "", deployment, send) }
+	}
+
+	// A fallback deployment gets the request instead of the candidate hearing anything
+	s.llmFallbackDeployment = "gpt-4o-mini"
+	s.streamLLMResponses(refused(), "s1", "t1", send, func() {}, circuitOpen("gpt-4o"))
+	if dials != 1 || len(sent) != 0 {
+		t.Fatalf("fallback: dials %d, sent %v", dials, sent)
+	}
+
+	// The fallback's own circuit is open: apologize
+	s.streamLLMResponses(refused(), "s1", "t1", send, func() {}, circuitOpen("gpt-4o-mini"))
+	if dials != 1 || len(sent) != 1 || sent[0].GetStartTts().GetText() != defaultLLMApology || sent[0].GetStartTts().GetTurnId() != "t1" {
+		t.Fatalf("apology: dials %d, sent %v", dials, sent)
+	}
+
+	// Once a sentence is out, a later circuit error does not add an apology
+	sent = nil
+	stream := replyStream()
+	stream.msgs = append(stream.msgs, refused().msgs...)
+	s.streamLLMResponses(stream, "s1", "t2", send, func() {}, circuitOpen("gpt-4o-mini"))
+	if len(sent) != 1 || sent[0].GetStartTts().GetText() != "Tell me more." {
+		t.Fatalf("mid-reply error: sent %v", sent)
+	}
+}
@@ -0,0 +1,97 @@
+package orchestrator
+
+import (
+	"log"
+	"time"
+
+	gw "yuzu/agent/internal/orchestrator/pb"
+)
+
+// llmlimit.go caps how hard one session can drive the LLM, so a stuck
+// gateway replaying finals in a loop can't run up the bill or starve other
+// sessions. A session may start ORCH_LLM_TURNS_PER_MIN (default 20) LLM
+// requests in any minute and have ORCH_LLM_MAX_CONCURRENT (default 2)
+// streams open at once; 0 turns either check off. A final over the limit is
+// not sent to the LLM. The first one in a minute gets ORCH_LLM_LIMIT_PHRASE
+// spoken instead (none stays silent); the rest are dropped, so a replay loop
+// doesn't become a speech loop. Counted in
+// orch_llm_limited_total{reason,action}.
+
+const defaultLLMLimitPhrase = "Let's take that one step at a time. Go ahead whenever you're ready."
+
+// llmLimits is read once at startup.
+type llmLimits struct {
+	perMinute     int
+	maxConcurrent int
+	phrase        string
+}
+
+func llmLimitsFromEnv() llmLimits {
+	return llmLimits{
+		perMinute:     envInt("ORCH_LLM_TURNS_PER_MIN", 20),
+		maxConcurrent: envInt("ORCH_LLM_MAX_CONCURRENT", 2),
+		phrase:        envString("ORCH_LLM_LIMIT_PHRASE", defaultLLMLimitPhrase),
+	}
+}
+
+// llmLimitState is one session's LLM usage.
+type llmLimitState struct {
+	started  []time.Time // request starts within the last minute
+	streams  int         // streams attached and not yet finished
+	notified time.Time   // when the limit phrase was last spoken
+}
+
+// admitLLM reports whether the session may start an LLM request for
+// turnID, recording it if so. Otherwise it speaks or drops the overflow.
+// Callers must not hold st.mu.
+func (s *Server) admitLLM(sid, turnID string, send func(*gw.OrchestratorCommand)) bool {
+	st := s.lookup(sid)
+	if st == nil {
+		return true
+	}
+	now := s.clock.Now()
+	st.mu.Lock()
+	l := &st.llmLimit
+	cutoff := now.Add(-time.Minute)
+	i := 0
+	for i < len(l.started) && !l.started[i].After(cutoff) {
+		i++
+	}
+	l.started = l.started[i:]
+	reason := ""
+	switch {
+	case s.llmLimits.perMinute > 0 && len(l.started) >= s.llmLimits.perMinute:
+		reason = "rate"
+	case s.llmLimits.maxConcurrent > 0 && l.streams >= s.llmLimits.maxConcurrent:
+		reason = "concurrency"
+	}
+	if reason == "" {
+		l.started = append(l.started, now)
+		st.mu.Unlock()
+		return true
+	}
+	speak := s.llmLimits.phrase != "" && now.Sub(l.notified) >= time.Minute
+	if speak {
+		l.notified = now
+	}
+	n, streams := len(l.started), l.streams
+	st.mu.Unlock()
+
+	action := "dropped"
+	if speak {
+		action = "canned"
+	}
+	metricLLMLimited.WithLabelValues(reason, action).Inc()
+	log.Printf("[orch] llm limit (%s) sid=%s turn=%s started_last_min=%d streams=%d; %s", reason, sid, turnID, n, streams, action)
+	if speak {
+		s.speakCanned(st, turnID, s.llmLimits.phrase, send)
+	} else {
+		// Nothing will be said, so no filler either
+		st.mu.Lock()
+		filler := st.takeFiller()
+		st.turnLatencyPending = false
+		st.mu.Unlock()
+		s.stopFiller(st, filler, send)
+	}
+	return false
+}
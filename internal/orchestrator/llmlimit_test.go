@@ -0,0 +1,60 @@
+package orchestrator
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+
+	"yuzu/agent/internal/clock"
+	gw "yuzu/agent/internal/orchestrator/pb"
+)
+
+func TestLLMRateLimitSpeaksOnceThenDrops(t *testing.T) {
+	clk := clock.NewFake(time.Unix(1700000000, 0))
+	s := &Server{sess: map[string]*sessionState{}, clock: clk}
+	s.llmLimits = llmLimits{perMinute: 3, phrase: defaultLLMLimitPhrase}
+	dials := 0
+	s.llmDial = func(context.Context) (*grpc.ClientConn, error) {
+		dials++
+		return nil, errors.New("no llm in this test")
+	}
+	s.sess["s1"] = &sessionState{id: "s1"}
+	var sent []*gw.OrchestratorCommand
+	send := func(c *gw.OrchestratorCommand) { sent = append(sent, c) }
+
+	// A replay loop: the first three reach the LLM, the fourth hears the
+	// phrase and the rest are dropped
+	for i := 0; i < 6; i++ {
+		s.startLLM(context.Background(), "s1", "t1", "hello", "", send)
+		clk.Advance(time.Second)
+	}
+	if dials != 3 || len(sent) != 1 || sent[0].GetStartTts().GetText() != defaultLLMLimitPhrase {
+		t.Fatalf("dials %d, sent %v", dials, sent)
+	}
+
+	// A minute on, the window has room again
+	clk.Advance(time.Minute)
+	s.startLLM(context.Background(), "s1", "t2", "hello", "", send)
+	if dials != 4 || len(sent) != 1 {
+		t.Fatalf("after a minute: dials %d, sent %v", dials, sent)
+	}
+}
+
+func TestLLMConcurrencyLimit(t *testing.T) {
+	s := &Server{sess: map[string]*sessionState{}, clock: clock.NewFake(time.Unix(1700000000, 0))}
+	s.llmLimits = llmLimits{maxConcurrent: 1}
+	s.sess["s1"] = &sessionState{id: "s1"}
+	send := func(*gw.OrchestratorCommand) {}
+
+	s.attachLLM("s1", func() {})
+	if s.admitLLM("s1", "t2", send) {
+		t.Fatal("second stream admitted while one is open")
+	}
+	s.detachLLM("s1")
+	if !s.admitLLM("s1", "t3", send) {
+		t.Fatal("stream refused after the first finished")
+	}
+}
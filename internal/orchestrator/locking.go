@@ -0,0 +1,69 @@
+package orchestrator
+
+import (
+	"sync"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"yuzu/agent/internal/chaos"
+	gw "yuzu/agent/internal/orchestrator/pb"
+)
+
+// locking.go documents how session state is shared. Each gateway stream
+// is read by one goroutine, but LLM replies, filler and re-send timers and
+// the admin API touch the same session from their own goroutines. Two
+// mutexes keep that safe:
+//
+//	Server.mu        guards the sess and closed maps only
+//	sessionState.mu  guards every field of one session but its id
+//
+// When both are needed, Server.mu is taken first. Neither is held while
+// sending to the gateway or calling out to the LLM; helpers say in their
+// doc comment whether callers hold st.mu. The stream itself is shared by
+// all of these goroutines and gRPC forbids concurrent Send, so Session
+// wraps it in a serialStream.
+
+// lookup returns the session sid, or nil when it is not open.
+func (s *Server) lookup(sid string) *sessionState {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.sess[sid]
+}
+
+// serialStream serializes Send on a gateway stream.
+type serialStream struct {
+	gw.GatewayControl_SessionServer
+	mu sync.Mutex
+
+	// Command batching, once the gateway asks for it (see batch.go)
+	batchWindow time.Duration
+	batchMax    int
+	pending     []*gw.OrchestratorCommand
+	flushTimer  *time.Timer
+	ended       bool // the handler returned; see end
+
+	// Faults injected into sends; nil unless CHAOS_ENABLED (see internal/chaos)
+	chaos *chaos.Injector
+}
+
+func (ss *serialStream) Send(cmd *gw.OrchestratorCommand) error {
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+	if ss.ended {
+		return errStreamEnded
+	}
+	if ss.chaos != nil {
+		switch ss.chaos.Inject(ss.Context()) {
+		case chaos.Drop:
+			return nil
+		case chaos.Error:
+			return status.Error(codes.Unavailable, chaos.ErrInjected.Error())
+		}
+	}
+	if ss.batchWindow > 0 {
+		return ss.queue(cmd)
+	}
+	return ss.GatewayControl_SessionServer.Send(cmd)
+}
@@ -0,0 +1,108 @@
+package orchestrator
+
+import (
+	"log"
+	"time"
+
+	gw "yuzu/agent/internal/orchestrator/pb"
+)
+
+// maxduration.go ends interviews that run out of time. ORCH_MAX_SESSION_MS
+// (default 0, off) caps every session; SessionStyle.max_duration_s
+// overrides it per session. The clock starts at the first SessionOpen and
+// keeps running across reconnects.
+//
+// ORCH_WRAPUP_MS (default 120000, at most half the session) before the end,
+// replies are written with ORCH_WRAPUP_PROMPT appended to the system prompt
+// so the agent stops opening topics and heads for a close. At the end the
+// reply in flight is cancelled, ORCH_CLOSING_STATEMENT is spoken and
+// EndInterview{reason: "max_duration"} follows; the gateway leaves once it
+// has played, and the API marks the session completed. Finals arriving
+// after that are recorded but get no reply. The summary records
+// max_duration_reached. Counted in orch_max_duration_total{event}.
+
+const (
+	reasonMaxDuration = "max_duration"
+
+	defaultWrapUpPrompt = "The interview is almost out of time. Don't open new topics or ask follow-ups; " +
+		"acknowledge the answer briefly and ask at most one short closing question, such as whether the candidate has any questions."
+	defaultClosingStatement = "We've reached the end of our time. Thank you so much for speaking with me today; " +
+		"the team will be in touch about next steps. Goodbye!"
+)
+
+// interviewClock is embedded in sessionState.
+type interviewClock struct {
+	maxDuration time.Duration // 0 for no limit
+	endTimer    *time.Timer
+	wrappingUp  bool
+	timeUp      bool // closing statement sent; no more replies
+}
+
+// armMaxDuration starts the session's clock on its first SessionOpen.
+// Callers hold st.mu.
+func (s *Server) armMaxDuration(st *sessionState, style *gw.SessionStyle) {
+	st.maxDuration = s.maxDuration
+	if v := style.GetMaxDurationS(); v > 0 {
+		st.maxDuration = time.Duration(v) * time.Second
+	}
+	if st.maxDuration <= 0 {
+		return
+	}
+	log.Printf("[orch] session_open id=%s ends after %s", st.id, st.maxDuration)
+	st.endTimer = time.AfterFunc(st.maxDuration, func() { s.timeUp(st) })
+}
+
+// stopMaxDuration cancels the clock of a closing session. Callers hold st.mu.
+func (st *sessionState) stopMaxDuration() {
+	if st.endTimer != nil {
+		st.endTimer.Stop()
+		st.endTimer = nil
+	}
+}
+
+// wrapUpPrompt returns the wrap-up instruction once the session is in its
+// last stretch, or "". Callers hold st.mu.
+func (s *Server) wrapUpPrompt(st *sessionState, now time.Time) string {
+	if st.maxDuration <= 0 || st.openedAt.IsZero() || s.wrapUpPromptText == "" {
+		return ""
+	}
+	window := s.wrapUpBefore
+	if window > st.maxDuration/2 {
+		window = st.maxDuration / 2
+	}
+	if now.Sub(st.openedAt) < st.maxDuration-window {
+		return ""
+	}
+	if !st.wrappingUp {
+		st.wrappingUp = true
+		metricMaxDuration.WithLabelValues("wrap_up").Inc()
+		log.Printf("[orch] wrapping up sid=%s: %s left", st.id, st.maxDuration-now.Sub(st.openedAt))
+	}
+	return s.wrapUpPromptText
+}
+
+// timeUp ends the interview when the session's time has run out.
+func (s *Server) timeUp(st *sessionState) {
+	if s.lookup(st.id) != st {
+		return
+	}
+	st.mu.Lock()
+	send := st.send
+	if st.timeUp || send == nil {
+		// Already ended, or the stream is gone and close.go finalizes it
+		st.mu.Unlock()
+		return
+	}
+	st.timeUp = true
+	st.endTimer = nil
+	s.cancelLLM(st)
+	turnID := st.turnID
+	st.mu.Unlock()
+
+	metricMaxDuration.WithLabelValues("ended").Inc()
+	log.Printf("[orch] max duration reached sid=%s after %s; ending the interview", st.id, st.maxDuration)
+	if s.closingStatement != "" {
+		s.speakCanned(st, turnID, s.closingStatement, send)
+	}
+	send(&gw.OrchestratorCommand{SessionId: st.id, Cmd: &gw.OrchestratorCommand_EndInterview{EndInterview: &gw.EndInterview{Reason: reasonMaxDuration}}})
+}
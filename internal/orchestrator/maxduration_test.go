@@ -0,0 +1,68 @@
+package orchestrator
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"yuzu/agent/internal/clock"
+	gw "yuzu/agent/internal/orchestrator/pb"
+)
+
+func TestMaxDurationWrapUpAndEnd(t *testing.T) {
+	clk := clock.NewFake(time.Unix(1700000000, 0))
+	s := &Server{sess: map[string]*sessionState{}, clock: clk, maxDuration: time.Hour,
+		wrapUpBefore: 2 * time.Minute, wrapUpPromptText: "wrap up", closingStatement: "Time's up, goodbye!"}
+	st := s.getOrCreateSession("s1")
+	fs := &fakeStream{}
+	s.handleSessionOpen(st, "s1", "", &gw.SessionStyle{MaxDurationS: 600}, fs)
+	var sent []*gw.OrchestratorCommand
+	st.mu.Lock()
+	st.send = func(c *gw.OrchestratorCommand) { sent = append(sent, c) }
+	if st.maxDuration != 10*time.Minute {
+		t.Fatalf("maxDuration = %s, want the session's 10m", st.maxDuration)
+	}
+
+	// The wrap-up instruction joins the prompt in the last two minutes
+	if p := s.wrapUpPrompt(st, clk.Now().Add(7*time.Minute)); p != "" {
+		t.Errorf("wrap-up at 7m: %q", p)
+	}
+	if p := s.wrapUpPrompt(st, clk.Now().Add(8*time.Minute)); p != "wrap up" || !st.wrappingUp {
+		t.Errorf("wrap-up at 8m: %q", p)
+	}
+	st.mu.Unlock()
+
+	// The IDLE of the open waited for the stream
+	s.flushTurnStates(st)
+	if len(sent) != 1 || sent[0].GetTurnState().GetToState() != string(stateIdle) {
+		t.Fatalf("after the stream was set sent %v", sent)
+	}
+	sent = nil
+
+	// Time's up: closing statement, then EndInterview
+	s.timeUp(st)
+	if len(sent) != 2 || !strings.Contains(sent[0].GetStartTts().GetText(), "goodbye") ||
+		sent[1].GetEndInterview().GetReason() != reasonMaxDuration {
+		t.Fatalf("time up sent %v", sent)
+	}
+	// Later finals get no reply and a second timeUp is a no-op
+	sent = nil
+	s.handleTranscriptFinal(context.Background(), st, "s1", "", "One more thing", st.send)
+	s.timeUp(st)
+	if len(sent) != 0 {
+		t.Fatalf("after time up sent %v", sent)
+	}
+	st.mu.Lock()
+	sum := st.summarize("end_requested", clk.Now())
+	st.mu.Unlock()
+	if !sum.MaxDuration || sum.Transcript[len(sum.Transcript)-1].Text != "One more thing" {
+		t.Errorf("summary max_duration_reached=%v transcript=%v", sum.MaxDuration, sum.Transcript)
+	}
+
+	// Closing stops the clock
+	s.closeSession("s1", "end_requested", nil)
+	if st.endTimer != nil {
+		t.Error("end timer left running")
+	}
+}
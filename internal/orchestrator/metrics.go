@@ -43,11 +43,75 @@ var (
         Buckets: prometheus.ExponentialBuckets(50, 1.6, 10),
     })
 
+    metricTurnLatency = promauto.NewHistogramVec(prometheus.HistogramOpts{
+        Name:    "orch_turn_latency_ms",
+        Help:    "Latency from the candidate's final transcript to the reply's first audio, by provider combination",
+        Buckets: prometheus.ExponentialBuckets(200, 1.4, 12),
+    }, []string{"stt_provider", "llm_backend", "tts_provider"})
+
+    metricTTSRecovery = promauto.NewCounterVec(prometheus.CounterOpts{
+        Name: "orch_tts_recovery_total",
+        Help: "Failed TTS utterances by outcome (rerouted, requeued, dropped, unknown)",
+    }, []string{"outcome"})
+
+    metricUserPaceWPM = promauto.NewHistogram(prometheus.HistogramOpts{
+        Name:    "orch_user_pace_wpm",
+        Help:    "User speaking pace per final transcript, words per minute",
+        Buckets: prometheus.LinearBuckets(60, 20, 11),
+    })
+
+    metricGatewayAuth = promauto.NewCounterVec(prometheus.CounterOpts{
+        Name: "orch_gateway_auth_total",
+        Help: "GatewayControl authentication results (ok or rejection reason)",
+    }, []string{"result"})
+
+    metricDiarizedFinals = promauto.NewCounterVec(prometheus.CounterOpts{
+        Name: "orch_transcript_finals_by_role_total",
+        Help: "Transcript finals by speaker role; only candidate finals reach the LLM",
+    }, []string{"role"})
+
+    metricFillers = promauto.NewCounterVec(prometheus.CounterOpts{
+        Name: "orch_fillers_total",
+        Help: "Filler StartTTS sent while the LLM was slow (phrase, ambient) and fillers cut short by the reply (superseded)",
+    }, []string{"event"})
+
+    metricAdminChanges = promauto.NewCounterVec(prometheus.CounterOpts{
+        Name: "orch_admin_changes_total",
+        Help: "Runtime prompt/flow changes through the admin API, by kind and action (set, rollback)",
+    }, []string{"kind", "action"})
+
+    metricFlowStageChanges = promauto.NewCounter(prometheus.CounterOpts{
+        Name: "orch_flow_stage_changes_total",
+        Help: "Sessions moving to the next interview flow stage",
+    })
+
+    metricFlowPhaseSeconds = promauto.NewHistogramVec(prometheus.HistogramOpts{
+        Name:    "orch_flow_phase_seconds",
+        Help:    "Time spent in an interview flow stage, by what ended it (turns, time, flow_changed, session_end)",
+        Buckets: prometheus.ExponentialBuckets(15, 2, 9),
+    }, []string{"ended_by"})
+
+    metricSessionsClosed = promauto.NewCounterVec(prometheus.CounterOpts{
+        Name: "orch_sessions_closed_total",
+        Help: "Sessions finalized, by close reason (stream_lost when the gateway never sent SessionClose)",
+    }, []string{"reason"})
+
+    metricSessionDuration = promauto.NewHistogram(prometheus.HistogramOpts{
+        Name:    "orch_session_duration_seconds",
+        Help:    "Session length from SessionOpen to close",
+        Buckets: prometheus.ExponentialBuckets(30, 2, 8),
+    })
+
     metricStateTransitions = promauto.NewCounterVec(prometheus.CounterOpts{
         Name: "orch_state_transitions_total",
         Help: "Orchestrator state transitions",
     }, []string{"from","to"})
 
+    metricStateRejected = promauto.NewCounterVec(prometheus.CounterOpts{
+        Name: "orch_state_rejected_total",
+        Help: "Illegal state transitions refused, by from, to and trigger",
+    }, []string{"from", "to", "trigger"})
+
     // Agreement histograms (no labels to avoid cardinality):
     // feature primary, gateway agrees after X ms
     metricVADAgreeGatewayMS = promauto.NewHistogram(prometheus.HistogramOpts{
@@ -77,4 +141,178 @@ var (
         Help:    "Latency from transcript final to first LLM sentence emitted",
         Buckets: prometheus.ExponentialBuckets(50, 1.6, 12),
     })
+
+    metricHalfDuplex = promauto.NewCounterVec(prometheus.CounterOpts{
+        Name: "orch_half_duplex_total",
+        Help: "Half-duplex mic gating commands (gate, resume, resume_barge_in)",
+    }, []string{"event"})
+
+    metricSTTErrors = promauto.NewCounterVec(prometheus.CounterOpts{
+        Name: "orch_stt_errors_total",
+        Help: "STT errors relayed by the gateway, by taxonomy code and reaction (restart, wait, log)",
+    }, []string{"code", "action"})
+
+    metricTTSDegrade = promauto.NewCounterVec(prometheus.CounterOpts{
+        Name: "orch_tts_degrade_total",
+        Help: "Text-only fallback while TTS is down (degraded, probe, probe_failed, recovered)",
+    }, []string{"event"})
+
+    metricCaptions = promauto.NewCounterVec(prometheus.CounterOpts{
+        Name: "orch_captions_total",
+        Help: "Caption commands sent, by role (candidate, interviewer, agent)",
+    }, []string{"role"})
+
+    metricThresholdReloads = promauto.NewCounterVec(prometheus.CounterOpts{
+        Name: "orch_threshold_reloads_total",
+        Help: "ORCH_THRESHOLDS_FILE reloads by result (applied, unchanged, error)",
+    }, []string{"result"})
+
+    metricTokenDeltas = promauto.NewCounter(prometheus.CounterOpts{
+        Name: "orch_token_deltas_total",
+        Help: "TokenDelta commands sent to sessions that stream tokens",
+    })
+
+    metricCommandBatch = promauto.NewHistogram(prometheus.HistogramOpts{
+        Name:    "orch_command_batch_size",
+        Help:    "Commands per CommandBatch sent to the gateway",
+        Buckets: []float64{2, 3, 4, 6, 8, 12, 16, 24, 32},
+    })
+
+    metricLLMLimited = promauto.NewCounterVec(prometheus.CounterOpts{
+        Name: "orch_llm_limited_total",
+        Help: "Finals kept from the LLM by the per-session limits, by reason (rate, concurrency) and action (canned, dropped)",
+    }, []string{"reason", "action"})
+
+    metricLLMCircuitOpen = promauto.NewCounterVec(prometheus.CounterOpts{
+        Name: "orch_llm_circuit_open_total",
+        Help: "Replies refused by an open LLM circuit, by what was done instead (fallback, canned, apology)",
+    }, []string{"action"})
+
+    metricBeginListening = promauto.NewCounter(prometheus.CounterOpts{
+        Name: "orch_begin_listening_total",
+        Help: "Turn hand-overs sent as one BeginListening command",
+    })
+
+    metricLLMDegraded = promauto.NewCounterVec(prometheus.CounterOpts{
+        Name: "orch_llm_degraded_total",
+        Help: "Degraded-mode events while the LLM can't answer (degraded, recovered, canned, apology, silent)",
+    }, []string{"event"})
+
+    metricPostTTSTail = promauto.NewCounterVec(prometheus.CounterOpts{
+        Name: "orch_post_tts_tail_total",
+        Help: "Playback tail suppression after TTS stops (onset_blocked, final_dropped)",
+    }, []string{"event"})
+
+    metricIntents = promauto.NewCounterVec(prometheus.CounterOpts{
+        Name: "orch_intents_total",
+        Help: "Spoken commands handled without an LLM round trip, by intent",
+    }, []string{"intent"})
+
+    metricModeration = promauto.NewCounterVec(prometheus.CounterOpts{
+        Name: "orch_moderation_total",
+        Help: "Moderation checks of candidate finals by outcome (clean, error, or the action taken: warn, end, flag)",
+    }, []string{"result"})
+
+    metricIDEcho = promauto.NewCounterVec(prometheus.CounterOpts{
+        Name: "orch_id_echo_total",
+        Help: "Gateway events by whether they echoed orchestrator-issued IDs (ok, missing, mismatch)",
+    }, []string{"event", "result"})
+
+    metricContextRetrievals = promauto.NewCounterVec(prometheus.CounterOpts{
+        Name: "orch_context_retrievals_total",
+        Help: "Session context added to LLM prompts (matched: chunks relevant to the turn; lead: opening chunks, nothing matched)",
+    }, []string{"result"})
+
+    metricSessionsSilent = promauto.NewGauge(prometheus.GaugeOpts{
+        Name: "orch_sessions_silent",
+        Help: "Sessions whose gateway has sent nothing for ORCH_SESSION_SILENT_MS",
+    })
+
+    metricMaxDuration = promauto.NewCounterVec(prometheus.CounterOpts{
+        Name: "orch_max_duration_total",
+        Help: "Sessions reaching their time limit (wrap_up: the wrap-up prompt started, ended: the closing statement was spoken)",
+    }, []string{"event"})
+
+    metricStopTTSAcks = promauto.NewCounterVec(prometheus.CounterOpts{
+        Name: "orch_stop_tts_acks_total",
+        Help: "StopTTSAck results from gateways (stopped, not_playing, stale, duplicate)",
+    }, []string{"result"})
+
+    metricStopTTSResends = promauto.NewCounterVec(prometheus.CounterOpts{
+        Name: "orch_stop_tts_resends_total",
+        Help: "Unacknowledged StopTTS commands re-sent (resent) or given up on (gave_up)",
+    }, []string{"outcome"})
+
+    // ArmBargeIn acks (see armack.go)
+    metricArmAcks = promauto.NewCounterVec(prometheus.CounterOpts{
+        Name: "orch_arm_barge_in_acks_total",
+        Help: "ArmBargeInAck results from gateways (applied, mismatch: other thresholds in force, stale: a superseded arm)",
+    }, []string{"result"})
+
+    metricArmResends = promauto.NewCounterVec(prometheus.CounterOpts{
+        Name: "orch_arm_barge_in_resends_total",
+        Help: "Unacknowledged ArmBargeIn commands re-sent (resent) or given up on (gave_up)",
+    }, []string{"outcome"})
+
+    metricBargeInProfileSuggestions = promauto.NewCounterVec(prometheus.CounterOpts{
+        Name: "orch_barge_in_profile_suggestions_total",
+        Help: "Barge-in profiles suggested from a session's first seconds of audio, and whether an auto session adopted them",
+    }, []string{"profile", "applied"})
+
+    metricLLMPromptTokens = promauto.NewHistogramVec(prometheus.HistogramOpts{
+        Name:    "orch_llm_prompt_tokens",
+        Help:    "Estimated tokens of each reply's prompt as requested, by part (system, documents, user, total)",
+        Buckets: prometheus.ExponentialBuckets(16, 2, 11),
+    }, []string{"part"})
+
+    metricLLMPromptPreflight = promauto.NewCounterVec(prometheus.CounterOpts{
+        Name: "orch_llm_prompt_preflight_total",
+        Help: "Reply prompts checked against ORCH_LLM_PROMPT_BUDGET (within, shrunk, trimmed)",
+    }, []string{"outcome"})
+
+    metricDuplicateFinals = promauto.NewCounterVec(prometheus.CounterOpts{
+        Name: "orch_duplicate_finals_total",
+        Help: "Finals dropped as repeats of one already handled, by what matched (utterance, fingerprint)",
+    }, []string{"match"})
+
+    // Finals echoed back in diagnostics mode (see echo.go)
+    metricEchoReplies = promauto.NewCounter(prometheus.CounterOpts{
+        Name: "orch_echo_replies_total",
+        Help: "Finals repeated back through TTS by sessions in echo test mode",
+    })
+
+    // Finals held during playback for the next turn (see pipeline.go)
+    metricPipelinedFinals = promauto.NewCounterVec(prometheus.CounterOpts{
+        Name: "orch_pipelined_finals_total",
+        Help: "Finals heard while the agent spoke with ORCH_PIPELINE_TURNS set: held, committed_tts_end, committed_barge_in, stale when the turn moved on, or foreign when not of the turn's utterance",
+    }, []string{"outcome"})
+
+    metricPipelinedWait = promauto.NewHistogram(prometheus.HistogramOpts{
+        Name:    "orch_pipelined_wait_ms",
+        Help:    "How long held finals waited for playback to end or a barge-in's final before being answered",
+        Buckets: prometheus.ExponentialBuckets(50, 2, 10),
+    })
+
+    // Recordings refused by a session's consent (see consent.go)
+    metricRecordingRejected = promauto.NewCounterVec(prometheus.CounterOpts{
+        Name: "orch_recording_rejected_total",
+        Help: "Things a session opted out of that the orchestrator kept without transcript text, by what (session_summary)",
+    }, []string{"what"})
+
+    // Events for sessions already closed (see close.go)
+    metricEventsAfterClose = promauto.NewCounter(prometheus.CounterOpts{
+        Name: "orch_events_after_close_total",
+        Help: "Gateway events dropped because their session had already been closed",
+    })
+
+    // Reloadable thresholds in force (see thresholds.go)
+    metricThresholdsVersion = promauto.NewGauge(prometheus.GaugeOpts{
+        Name: "orch_thresholds_version",
+        Help: "Version of the barge-in/VAD thresholds in force; 0 while the env defaults apply",
+    })
+
+    metricVADThreshold = promauto.NewGaugeVec(prometheus.GaugeOpts{
+        Name: "orch_vad_threshold",
+        Help: "Barge-in/VAD threshold in force for new sessions, by name (guard_ms, min_rms, hangover, min_start), once a thresholds file is loaded",
+    }, []string{"name"})
 )
@@ -0,0 +1,158 @@
+package orchestrator
+
+import (
+	"context"
+	"log"
+	"strings"
+	"time"
+
+	llmpb "yuzu/agent/internal/llm/pb"
+	gw "yuzu/agent/internal/orchestrator/pb"
+)
+
+// moderation.go screens the candidate's finals before they reach the LLM.
+// With ORCH_MODERATION=true each final is checked by the LLM service's
+// Moderate RPC, waiting at most ORCH_MODERATION_TIMEOUT_MS (default 1500);
+// errors and timeouts let the text through. ORCH_MODERATION_ACTION picks
+// what a flagged final does:
+//
+//	warn  speak ORCH_MODERATION_WARNING instead of replying (default)
+//	end   say goodbye and send EndInterview
+//	flag  only record it; the reply goes ahead
+//
+// Whatever the action, the ORCH_MODERATION_END_AFTER'th flagged final
+// (default 3, 0 never) ends the interview. Each flag is logged as an AUDIT
+// line, sent to the gateway as ModerationFlag (which lands in the API event
+// log as moderation_flagged) and listed in the session summary.
+
+const (
+	moderationWarn = "warn"
+	moderationEnd  = "end"
+	moderationFlag = "flag"
+
+	defaultModerationWarning = "I'd like to keep our conversation respectful. Let's continue with the interview."
+	moderationGoodbye        = "I'm going to end the interview here. Thank you for your time."
+)
+
+// moderationConfig is read once at startup.
+type moderationConfig struct {
+	enabled  bool
+	action   string
+	warning  string
+	endAfter int
+	timeout  time.Duration
+}
+
+func moderationFromEnv() moderationConfig {
+	c := moderationConfig{
+		enabled:  envBool("ORCH_MODERATION", false),
+		action:   strings.ToLower(envString("ORCH_MODERATION_ACTION", moderationWarn)),
+		warning:  envString("ORCH_MODERATION_WARNING", defaultModerationWarning),
+		endAfter: envInt("ORCH_MODERATION_END_AFTER", 3),
+		timeout:  time.Duration(envInt("ORCH_MODERATION_TIMEOUT_MS", 1500)) * time.Millisecond,
+	}
+	switch c.action {
+	case moderationWarn, moderationEnd, moderationFlag:
+	default:
+		log.Printf("[orch] ORCH_MODERATION_ACTION=%q unknown; using %s", c.action, moderationWarn)
+		c.action = moderationWarn
+	}
+	return c
+}
+
+// moderationState is embedded in sessionState.
+type moderationState struct {
+	flags []moderationRecord
+}
+
+// moderationRecord is one flagged final, kept for the session summary.
+type moderationRecord struct {
+	At         time.Time `json:"at"`
+	TurnID     string    `json:"turn_id"`
+	Categories []string  `json:"categories,omitempty"`
+	Action     string    `json:"action"`
+}
+
+// moderateText asks the LLM service about text.
+func (s *Server) moderateText(ctx context.Context, sid, text string) (*llmpb.ModerateResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.moderation.timeout)
+	defer cancel()
+	if s.moderate != nil {
+		return s.moderate(ctx, sid, text)
+	}
+	client, err := s.getLLMClient(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return client.Moderate(ctx, &llmpb.ModerateRequest{SessionId: sid, Text: text})
+}
+
+// moderateThenReply checks the candidate's final and starts the reply
+// unless it was flagged. It runs on its own goroutine so the gateway stream
+// keeps flowing while the check is out.
+func (s *Server) moderateThenReply(ctx context.Context, st *sessionState, sid, turnID, utteranceID, text, stage string, send func(*gw.OrchestratorCommand)) {
+	verdict, err := s.moderateText(ctx, sid, text)
+	switch {
+	case err != nil:
+		metricModeration.WithLabelValues("error").Inc()
+		log.Printf("[orch] moderation failed sid=%s turn=%s: %v; replying anyway", sid, turnID, err)
+	case !verdict.GetFlagged():
+		metricModeration.WithLabelValues("clean").Inc()
+	default:
+		if !s.handleModerationFlag(st, sid, turnID, utteranceID, verdict.GetCategories(), send) {
+			return
+		}
+	}
+	// The session may have closed while the check was out
+	if s.lookup(sid) != st {
+		return
+	}
+	log.Printf("[orch] Starting LLM for sid=%s turn=%s", sid, turnID)
+	s.startLLM(ctx, sid, turnID, text, stage, send)
+}
+
+// handleModerationFlag records a flagged final and carries out the
+// configured action. It reports whether the reply should still go ahead.
+// Callers must not hold st.mu.
+func (s *Server) handleModerationFlag(st *sessionState, sid, turnID, utteranceID string, categories []string, send func(*gw.OrchestratorCommand)) bool {
+	st.mu.Lock()
+	action := s.moderation.action
+	violations := len(st.moderation.flags) + 1
+	if s.moderation.endAfter > 0 && violations >= s.moderation.endAfter {
+		action = moderationEnd
+	}
+	st.moderation.flags = append(st.moderation.flags, moderationRecord{At: s.clock.Now(), TurnID: turnID, Categories: categories, Action: action})
+	var filler string
+	var cmds []*gw.OrchestratorCommand
+	if action != moderationFlag {
+		filler = st.takeFiller()
+		st.turnLatencyPending = false
+		text := s.moderation.warning
+		if action == moderationEnd {
+			text = moderationGoodbye
+		}
+		cmd := &gw.StartTTS{Text: text, TurnId: turnID, UtteranceId: st.nextAgentUtterance(turnID)}
+		st.record(s.clock.Now(), roleAgent, 0, text)
+		cmd.SpeakingRate, cmd.PauseMs = s.prosody(st)
+		cmds = s.agentSpeech(st, cmd)
+	}
+	st.mu.Unlock()
+	s.flushTurnStates(st)
+
+	metricModeration.WithLabelValues(action).Inc()
+	log.Printf("[orch] AUDIT moderation flagged sid=%s turn=%s utterance=%s categories=%v action=%s violations=%d", sid, turnID, utteranceID, categories, action, violations)
+	send(&gw.OrchestratorCommand{SessionId: sid, Cmd: &gw.OrchestratorCommand_ModerationFlag{ModerationFlag: &gw.ModerationFlag{
+		TurnId: turnID, UtteranceId: utteranceID, Categories: categories, Action: action, Violations: uint32(violations),
+	}}})
+	if action == moderationFlag {
+		return true
+	}
+	s.stopFiller(st, filler, send)
+	for _, cmd := range cmds {
+		send(cmd)
+	}
+	if action == moderationEnd {
+		send(&gw.OrchestratorCommand{SessionId: sid, Cmd: &gw.OrchestratorCommand_EndInterview{EndInterview: &gw.EndInterview{Reason: "moderation"}}})
+	}
+	return false
+}
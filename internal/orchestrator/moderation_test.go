@@ -0,0 +1,79 @@
+package orchestrator
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+
+	"yuzu/agent/internal/clock"
+	llmpb "yuzu/agent/internal/llm/pb"
+	gw "yuzu/agent/internal/orchestrator/pb"
+)
+
+func TestModerationWarnsThenEnds(t *testing.T) {
+	s := &Server{sess: map[string]*sessionState{}, clock: clock.NewFake(time.Unix(1700000000, 0))}
+	s.moderation = moderationConfig{enabled: true, action: moderationWarn, warning: "Let's keep it respectful.", endAfter: 2, timeout: time.Second}
+	s.moderate = func(_ context.Context, _, text string) (*llmpb.ModerateResponse, error) {
+		if strings.Contains(text, "idiot") {
+			return &llmpb.ModerateResponse{Flagged: true, Categories: []string{"harassment"}}, nil
+		}
+		if strings.Contains(text, "down") {
+			return nil, errors.New("unavailable")
+		}
+		return &llmpb.ModerateResponse{}, nil
+	}
+	llmCalls := 0
+	s.llmDial = func(context.Context) (*grpc.ClientConn, error) { llmCalls++; return nil, errors.New("no llm in this test") }
+	st := &sessionState{id: "s1"}
+	s.sess["s1"] = st
+	var cmds []*gw.OrchestratorCommand
+	send := func(c *gw.OrchestratorCommand) { cmds = append(cmds, c) }
+
+	s.moderateThenReply(context.Background(), st, "s1", "t1", "t1-u", "You are an idiot", "", send)
+	if llmCalls != 0 || len(cmds) != 2 || cmds[0].GetModerationFlag().GetAction() != moderationWarn ||
+		cmds[0].GetModerationFlag().GetViolations() != 1 || cmds[1].GetStartTts().GetText() != "Let's keep it respectful." {
+		t.Fatalf("first flag: llm calls %d, cmds %v", llmCalls, cmds)
+	}
+
+	// Clean text and a failed check both reach the LLM
+	cmds = nil
+	s.moderateThenReply(context.Background(), st, "s1", "t2", "t2-u", "I built the billing service", "", send)
+	s.moderateThenReply(context.Background(), st, "s1", "t3", "t3-u", "moderation is down", "", send)
+	if llmCalls != 2 || len(cmds) != 0 {
+		t.Fatalf("clean and failed checks: llm calls %d, cmds %v", llmCalls, cmds)
+	}
+
+	// The second flag reaches ORCH_MODERATION_END_AFTER
+	s.moderateThenReply(context.Background(), st, "s1", "t4", "t4-u", "idiot", "", send)
+	if len(cmds) != 3 || cmds[0].GetModerationFlag().GetAction() != moderationEnd || cmds[1].GetStartTts().GetText() != moderationGoodbye ||
+		cmds[2].GetEndInterview().GetReason() != "moderation" {
+		t.Fatalf("second flag: %v", cmds)
+	}
+	st.mu.Lock()
+	sum := st.summarize("end_requested", s.clock.Now())
+	st.mu.Unlock()
+	if len(sum.Moderation) != 2 || sum.Moderation[0].Categories[0] != "harassment" || sum.Moderation[1].Action != moderationEnd {
+		t.Fatalf("summary moderation = %+v", sum.Moderation)
+	}
+}
+
+func TestModerationFlagOnlyStillReplies(t *testing.T) {
+	s := &Server{sess: map[string]*sessionState{}, clock: clock.Real}
+	s.moderation = moderationConfig{enabled: true, action: moderationFlag, timeout: time.Second}
+	s.moderate = func(context.Context, string, string) (*llmpb.ModerateResponse, error) {
+		return &llmpb.ModerateResponse{Flagged: true}, nil
+	}
+	llmCalls := 0
+	s.llmDial = func(context.Context) (*grpc.ClientConn, error) { llmCalls++; return nil, errors.New("no llm in this test") }
+	st := &sessionState{id: "s1"}
+	s.sess["s1"] = st
+	var cmds []*gw.OrchestratorCommand
+	s.moderateThenReply(context.Background(), st, "s1", "t1", "t1-u", "whatever", "", func(c *gw.OrchestratorCommand) { cmds = append(cmds, c) })
+	if llmCalls != 1 || len(cmds) != 1 || cmds[0].GetModerationFlag().GetAction() != moderationFlag {
+		t.Fatalf("flag-only: llm calls %d, cmds %v", llmCalls, cmds)
+	}
+}
@@ -0,0 +1,69 @@
+package orchestrator
+
+import (
+	"time"
+)
+
+// pace.go mirrors the user's speaking pace in the agent's speech. Word
+// timestamps on each final give a words-per-minute sample; a moving average
+// of those nudges the TTS speaking rate and the pause between sentences
+// toward the user's tempo, so slow speakers aren't rushed and fast ones
+// aren't kept waiting.
+
+const (
+	paceAlpha      = 0.3 // weight of the newest sample
+	paceMinWords   = 3   // shorter finals ("yes", "okay") say little about pace
+	paceMinSpeech  = 600 * time.Millisecond
+	paceMinWPM     = 60
+	paceMaxWPM     = 260
+	paceRateMin    = 0.85
+	paceRateMax    = 1.15
+	paceRateFollow = 0.5 // fraction of the user's deviation from baseline we mirror
+	pacePauseMinMs = 100
+	pacePauseMaxMs = 600
+)
+
+// paceState is embedded in sessionState.
+type paceState struct {
+	wpm     float64 // moving average, 0 until the first usable sample
+	samples int
+}
+
+// observe folds one final's word timing into the average.
+func (p *paceState) observe(words int, speech time.Duration) {
+	if words < paceMinWords || speech < paceMinSpeech {
+		return
+	}
+	wpm := float64(words) / speech.Minutes()
+	wpm = clampf(wpm, paceMinWPM, paceMaxWPM)
+	metricUserPaceWPM.Observe(wpm)
+	if p.samples == 0 {
+		p.wpm = wpm
+	} else {
+		p.wpm = paceAlpha*wpm + (1-paceAlpha)*p.wpm
+	}
+	p.samples++
+}
+
+// prosody returns the speaking rate and inter-sentence pause for the next
+// StartTTS, or zeros (provider defaults) when adaptation is off or the pace
+// is still unknown. Callers hold st.mu.
+func (s *Server) prosody(st *sessionState) (rate float32, pauseMs uint32) {
+	if !s.paceAdapt || st.pace.samples == 0 || s.paceBaselineWPM <= 0 {
+		return 0, 0
+	}
+	ratio := st.pace.wpm / s.paceBaselineWPM
+	r := clampf(1+paceRateFollow*(ratio-1), paceRateMin, paceRateMax)
+	p := clampf(float64(s.pacePauseMs)/ratio, pacePauseMinMs, pacePauseMaxMs)
+	return float32(r), uint32(p)
+}
+
+func clampf(v, lo, hi float64) float64 {
+	if v < lo {
+		return lo
+	}
+	if v > hi {
+		return hi
+	}
+	return v
+}
@@ -0,0 +1,48 @@
+package orchestrator
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPaceProsody(t *testing.T) {
+	s := &Server{paceAdapt: true, paceBaselineWPM: 160, pacePauseMs: 250}
+	st := &sessionState{}
+
+	if r, p := s.prosody(st); r != 0 || p != 0 {
+		t.Fatalf("prosody before samples = %v %d, want defaults", r, p)
+	}
+
+	// Too short to say anything about pace
+	st.pace.observe(2, 400*time.Millisecond)
+	if st.pace.samples != 0 {
+		t.Fatalf("short final counted as sample")
+	}
+
+	// Slow speaker: 20 words in 12s = 100 wpm
+	st.pace.observe(20, 12*time.Second)
+	r, p := s.prosody(st)
+	if r >= 1 || r < paceRateMin {
+		t.Errorf("rate for slow speaker = %v, want in [%v, 1)", r, paceRateMin)
+	}
+	if p <= 250 {
+		t.Errorf("pause for slow speaker = %d, want > 250", p)
+	}
+
+	// Very fast speech pulls the average up but stays within bounds
+	for i := 0; i < 10; i++ {
+		st.pace.observe(40, 6*time.Second)
+	}
+	r, p = s.prosody(st)
+	if r <= 1 || r > paceRateMax {
+		t.Errorf("rate for fast speaker = %v, want in (1, %v]", r, paceRateMax)
+	}
+	if p >= 250 || p < pacePauseMinMs {
+		t.Errorf("pause for fast speaker = %d, want in [%d, 250)", p, pacePauseMinMs)
+	}
+
+	s.paceAdapt = false
+	if r, p := s.prosody(st); r != 0 || p != 0 {
+		t.Errorf("prosody with adaptation off = %v %d, want defaults", r, p)
+	}
+}
@@ -22,9 +22,13 @@ const (
 )
 
 type SessionOpen struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	SessionId     string                 `protobuf:"bytes,1,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
-	RoomUrl       string                 `protobuf:"bytes,2,opt,name=room_url,json=roomUrl,proto3" json:"room_url,omitempty"`
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	SessionId string                 `protobuf:"bytes,1,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
+	RoomUrl   string                 `protobuf:"bytes,2,opt,name=room_url,json=roomUrl,proto3" json:"room_url,omitempty"`
+	Style     *SessionStyle          `protobuf:"bytes,3,opt,name=style,proto3" json:"style,omitempty"` // optional; orchestrator defaults apply when unset
+	// What the gateway can handle beyond the base protocol, e.g.
+	// "command_batch" (the orchestrator may send CommandBatch)
+	Capabilities  []string `protobuf:"bytes,4,rep,name=capabilities,proto3" json:"capabilities,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -73,6 +77,202 @@ func (x *SessionOpen) GetRoomUrl() string {
 	return ""
 }
 
+func (x *SessionOpen) GetStyle() *SessionStyle {
+	if x != nil {
+		return x.Style
+	}
+	return nil
+}
+
+func (x *SessionOpen) GetCapabilities() []string {
+	if x != nil {
+		return x.Capabilities
+	}
+	return nil
+}
+
+// SessionStyle shapes the agent's replies. Empty/zero fields use defaults.
+type SessionStyle struct {
+	state        protoimpl.MessageState `protogen:"open.v1"`
+	Persona      string                 `protobuf:"bytes,1,opt,name=persona,proto3" json:"persona,omitempty"`                 // friendly | formal | technical-interviewer
+	Verbosity    string                 `protobuf:"bytes,2,opt,name=verbosity,proto3" json:"verbosity,omitempty"`             // brief | normal | detailed
+	Temperature  *float64               `protobuf:"fixed64,3,opt,name=temperature,proto3,oneof" json:"temperature,omitempty"` // unset keeps the default; 0 is a setting
+	MaxTokens    uint32                 `protobuf:"varint,4,opt,name=max_tokens,json=maxTokens,proto3" json:"max_tokens,omitempty"`
+	SystemPrompt string                 `protobuf:"bytes,5,opt,name=system_prompt,json=systemPrompt,proto3" json:"system_prompt,omitempty"` // tenant override; replaces the built prompt
+	// From a session preset; unset keeps the orchestrator's flow and barge-in settings
+	FlowJson              string `protobuf:"bytes,6,opt,name=flow_json,json=flowJson,proto3" json:"flow_json,omitempty"`                                            // interview flow, same shape as ORCH_FLOW_FILE
+	BargeInMinRms         uint32 `protobuf:"varint,7,opt,name=barge_in_min_rms,json=bargeInMinRms,proto3" json:"barge_in_min_rms,omitempty"`                        // overrides LOCAL_STOP_MIN_RMS
+	BargeInGuardMs        uint32 `protobuf:"varint,8,opt,name=barge_in_guard_ms,json=bargeInGuardMs,proto3" json:"barge_in_guard_ms,omitempty"`                     // overrides LOCAL_STOP_GUARD_MS
+	Captions              bool   `protobuf:"varint,9,opt,name=captions,proto3" json:"captions,omitempty"`                                                           // stream Caption commands for this session
+	TokenStream           bool   `protobuf:"varint,10,opt,name=token_stream,json=tokenStream,proto3" json:"token_stream,omitempty"`                                 // stream TokenDelta commands for this session
+	ContextJson           string `protobuf:"bytes,11,opt,name=context_json,json=contextJson,proto3" json:"context_json,omitempty"`                                  // uploaded reference documents, [{name, kind, text}]
+	MaxDurationS          uint32 `protobuf:"varint,12,opt,name=max_duration_s,json=maxDurationS,proto3" json:"max_duration_s,omitempty"`                            // overrides ORCH_MAX_SESSION_MS; the agent wraps up and ends the interview
+	BargeInHangover       uint32 `protobuf:"varint,13,opt,name=barge_in_hangover,json=bargeInHangover,proto3" json:"barge_in_hangover,omitempty"`                   // quiet frames that end speech; overrides the orchestrator's
+	BargeInProfile        string `protobuf:"bytes,14,opt,name=barge_in_profile,json=bargeInProfile,proto3" json:"barge_in_profile,omitempty"`                       // headset | laptop-speakers | phone | auto (adopt the suggestion)
+	Instructions          string `protobuf:"bytes,15,opt,name=instructions,proto3" json:"instructions,omitempty"`                                                   // the session's own prompt section, after the tenant and flow sections
+	EchoTest              bool   `protobuf:"varint,16,opt,name=echo_test,json=echoTest,proto3" json:"echo_test,omitempty"`                                          // diagnostics: repeat each final back ("You said: ...") instead of calling the LLM
+	NoLlmLogging          bool   `protobuf:"varint,17,opt,name=no_llm_logging,json=noLlmLogging,proto3" json:"no_llm_logging,omitempty"`                            // consent: the LLM service must not log this session's requests
+	NoTranscriptRetention bool   `protobuf:"varint,18,opt,name=no_transcript_retention,json=noTranscriptRetention,proto3" json:"no_transcript_retention,omitempty"` // consent: no transcript text in summaries or logs
+	unknownFields         protoimpl.UnknownFields
+	sizeCache             protoimpl.SizeCache
+}
+
+func (x *SessionStyle) Reset() {
+	*x = SessionStyle{}
+	mi := &file_gateway_control_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SessionStyle) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SessionStyle) ProtoMessage() {}
+
+func (x *SessionStyle) ProtoReflect() protoreflect.Message {
+	mi := &file_gateway_control_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SessionStyle.ProtoReflect.Descriptor instead.
+func (*SessionStyle) Descriptor() ([]byte, []int) {
+	return file_gateway_control_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *SessionStyle) GetPersona() string {
+	if x != nil {
+		return x.Persona
+	}
+	return ""
+}
+
+func (x *SessionStyle) GetVerbosity() string {
+	if x != nil {
+		return x.Verbosity
+	}
+	return ""
+}
+
+func (x *SessionStyle) GetTemperature() float64 {
+	if x != nil && x.Temperature != nil {
+		return *x.Temperature
+	}
+	return 0
+}
+
+func (x *SessionStyle) GetMaxTokens() uint32 {
+	if x != nil {
+		return x.MaxTokens
+	}
+	return 0
+}
+
+func (x *SessionStyle) GetSystemPrompt() string {
+	if x != nil {
+		return x.SystemPrompt
+	}
+	return ""
+}
+
+func (x *SessionStyle) GetFlowJson() string {
+	if x != nil {
+		return x.FlowJson
+	}
+	return ""
+}
+
+func (x *SessionStyle) GetBargeInMinRms() uint32 {
+	if x != nil {
+		return x.BargeInMinRms
+	}
+	return 0
+}
+
+func (x *SessionStyle) GetBargeInGuardMs() uint32 {
+	if x != nil {
+		return x.BargeInGuardMs
+	}
+	return 0
+}
+
+func (x *SessionStyle) GetCaptions() bool {
+	if x != nil {
+		return x.Captions
+	}
+	return false
+}
+
+func (x *SessionStyle) GetTokenStream() bool {
+	if x != nil {
+		return x.TokenStream
+	}
+	return false
+}
+
+func (x *SessionStyle) GetContextJson() string {
+	if x != nil {
+		return x.ContextJson
+	}
+	return ""
+}
+
+func (x *SessionStyle) GetMaxDurationS() uint32 {
+	if x != nil {
+		return x.MaxDurationS
+	}
+	return 0
+}
+
+func (x *SessionStyle) GetBargeInHangover() uint32 {
+	if x != nil {
+		return x.BargeInHangover
+	}
+	return 0
+}
+
+func (x *SessionStyle) GetBargeInProfile() string {
+	if x != nil {
+		return x.BargeInProfile
+	}
+	return ""
+}
+
+func (x *SessionStyle) GetInstructions() string {
+	if x != nil {
+		return x.Instructions
+	}
+	return ""
+}
+
+func (x *SessionStyle) GetEchoTest() bool {
+	if x != nil {
+		return x.EchoTest
+	}
+	return false
+}
+
+func (x *SessionStyle) GetNoLlmLogging() bool {
+	if x != nil {
+		return x.NoLlmLogging
+	}
+	return false
+}
+
+func (x *SessionStyle) GetNoTranscriptRetention() bool {
+	if x != nil {
+		return x.NoTranscriptRetention
+	}
+	return false
+}
+
 type VADStart struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	TsMs          uint64                 `protobuf:"varint,1,opt,name=ts_ms,json=tsMs,proto3" json:"ts_ms,omitempty"`
@@ -82,7 +282,7 @@ type VADStart struct {
 
 func (x *VADStart) Reset() {
 	*x = VADStart{}
-	mi := &file_gateway_control_proto_msgTypes[1]
+	mi := &file_gateway_control_proto_msgTypes[2]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -94,7 +294,7 @@ func (x *VADStart) String() string {
 func (*VADStart) ProtoMessage() {}
 
 func (x *VADStart) ProtoReflect() protoreflect.Message {
-	mi := &file_gateway_control_proto_msgTypes[1]
+	mi := &file_gateway_control_proto_msgTypes[2]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -107,7 +307,7 @@ func (x *VADStart) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use VADStart.ProtoReflect.Descriptor instead.
 func (*VADStart) Descriptor() ([]byte, []int) {
-	return file_gateway_control_proto_rawDescGZIP(), []int{1}
+	return file_gateway_control_proto_rawDescGZIP(), []int{2}
 }
 
 func (x *VADStart) GetTsMs() uint64 {
@@ -126,7 +326,7 @@ type VADEnd struct {
 
 func (x *VADEnd) Reset() {
 	*x = VADEnd{}
-	mi := &file_gateway_control_proto_msgTypes[2]
+	mi := &file_gateway_control_proto_msgTypes[3]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -138,7 +338,7 @@ func (x *VADEnd) String() string {
 func (*VADEnd) ProtoMessage() {}
 
 func (x *VADEnd) ProtoReflect() protoreflect.Message {
-	mi := &file_gateway_control_proto_msgTypes[2]
+	mi := &file_gateway_control_proto_msgTypes[3]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -151,7 +351,7 @@ func (x *VADEnd) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use VADEnd.ProtoReflect.Descriptor instead.
 func (*VADEnd) Descriptor() ([]byte, []int) {
-	return file_gateway_control_proto_rawDescGZIP(), []int{2}
+	return file_gateway_control_proto_rawDescGZIP(), []int{3}
 }
 
 func (x *VADEnd) GetTsMs() uint64 {
@@ -162,16 +362,21 @@ func (x *VADEnd) GetTsMs() uint64 {
 }
 
 type TranscriptInterim struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	UtteranceId   string                 `protobuf:"bytes,1,opt,name=utterance_id,json=utteranceId,proto3" json:"utterance_id,omitempty"`
-	Text          string                 `protobuf:"bytes,2,opt,name=text,proto3" json:"text,omitempty"`
+	state       protoimpl.MessageState `protogen:"open.v1"`
+	UtteranceId string                 `protobuf:"bytes,1,opt,name=utterance_id,json=utteranceId,proto3" json:"utterance_id,omitempty"` // echoed from StartMicToSTT (STT may append ".N" on rollover)
+	Text        string                 `protobuf:"bytes,2,opt,name=text,proto3" json:"text,omitempty"`
+	TurnId      string                 `protobuf:"bytes,3,opt,name=turn_id,json=turnId,proto3" json:"turn_id,omitempty"` // echoed from StartMicToSTT
+	// Relayed from STT: text as a committed prefix that only grows within
+	// the utterance plus a volatile suffix; both empty when STT doesn't split
+	CommittedText string `protobuf:"bytes,4,opt,name=committed_text,json=committedText,proto3" json:"committed_text,omitempty"`
+	VolatileText  string `protobuf:"bytes,5,opt,name=volatile_text,json=volatileText,proto3" json:"volatile_text,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *TranscriptInterim) Reset() {
 	*x = TranscriptInterim{}
-	mi := &file_gateway_control_proto_msgTypes[3]
+	mi := &file_gateway_control_proto_msgTypes[4]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -183,7 +388,7 @@ func (x *TranscriptInterim) String() string {
 func (*TranscriptInterim) ProtoMessage() {}
 
 func (x *TranscriptInterim) ProtoReflect() protoreflect.Message {
-	mi := &file_gateway_control_proto_msgTypes[3]
+	mi := &file_gateway_control_proto_msgTypes[4]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -196,7 +401,7 @@ func (x *TranscriptInterim) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use TranscriptInterim.ProtoReflect.Descriptor instead.
 func (*TranscriptInterim) Descriptor() ([]byte, []int) {
-	return file_gateway_control_proto_rawDescGZIP(), []int{3}
+	return file_gateway_control_proto_rawDescGZIP(), []int{4}
 }
 
 func (x *TranscriptInterim) GetUtteranceId() string {
@@ -213,17 +418,44 @@ func (x *TranscriptInterim) GetText() string {
 	return ""
 }
 
+func (x *TranscriptInterim) GetTurnId() string {
+	if x != nil {
+		return x.TurnId
+	}
+	return ""
+}
+
+func (x *TranscriptInterim) GetCommittedText() string {
+	if x != nil {
+		return x.CommittedText
+	}
+	return ""
+}
+
+func (x *TranscriptInterim) GetVolatileText() string {
+	if x != nil {
+		return x.VolatileText
+	}
+	return ""
+}
+
 type TranscriptFinal struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	UtteranceId   string                 `protobuf:"bytes,1,opt,name=utterance_id,json=utteranceId,proto3" json:"utterance_id,omitempty"`
-	Text          string                 `protobuf:"bytes,2,opt,name=text,proto3" json:"text,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	UtteranceId      string                 `protobuf:"bytes,1,opt,name=utterance_id,json=utteranceId,proto3" json:"utterance_id,omitempty"` // echoed from StartMicToSTT (STT may append ".N" on rollover)
+	Text             string                 `protobuf:"bytes,2,opt,name=text,proto3" json:"text,omitempty"`
+	TurnId           string                 `protobuf:"bytes,3,opt,name=turn_id,json=turnId,proto3" json:"turn_id,omitempty"`           // echoed from StartMicToSTT
+	WordCount        uint32                 `protobuf:"varint,4,opt,name=word_count,json=wordCount,proto3" json:"word_count,omitempty"` // relayed from STT word timestamps, 0 if unknown
+	SpeechMs         uint32                 `protobuf:"varint,5,opt,name=speech_ms,json=speechMs,proto3" json:"speech_ms,omitempty"`
+	Speaker          uint32                 `protobuf:"varint,6,opt,name=speaker,proto3" json:"speaker,omitempty"`                                            // relayed from STT diarization, 1-based; 0 if off
+	Source           string                 `protobuf:"bytes,7,opt,name=source,proto3" json:"source,omitempty"`                                               // relayed from STT: "" (provider), "drain" or "early"
+	AudioFingerprint uint64                 `protobuf:"fixed64,8,opt,name=audio_fingerprint,json=audioFingerprint,proto3" json:"audio_fingerprint,omitempty"` // relayed from STT; 0 if unknown
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
 }
 
 func (x *TranscriptFinal) Reset() {
 	*x = TranscriptFinal{}
-	mi := &file_gateway_control_proto_msgTypes[4]
+	mi := &file_gateway_control_proto_msgTypes[5]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -235,7 +467,7 @@ func (x *TranscriptFinal) String() string {
 func (*TranscriptFinal) ProtoMessage() {}
 
 func (x *TranscriptFinal) ProtoReflect() protoreflect.Message {
-	mi := &file_gateway_control_proto_msgTypes[4]
+	mi := &file_gateway_control_proto_msgTypes[5]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -248,7 +480,7 @@ func (x *TranscriptFinal) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use TranscriptFinal.ProtoReflect.Descriptor instead.
 func (*TranscriptFinal) Descriptor() ([]byte, []int) {
-	return file_gateway_control_proto_rawDescGZIP(), []int{4}
+	return file_gateway_control_proto_rawDescGZIP(), []int{5}
 }
 
 func (x *TranscriptFinal) GetUtteranceId() string {
@@ -265,18 +497,62 @@ func (x *TranscriptFinal) GetText() string {
 	return ""
 }
 
+func (x *TranscriptFinal) GetTurnId() string {
+	if x != nil {
+		return x.TurnId
+	}
+	return ""
+}
+
+func (x *TranscriptFinal) GetWordCount() uint32 {
+	if x != nil {
+		return x.WordCount
+	}
+	return 0
+}
+
+func (x *TranscriptFinal) GetSpeechMs() uint32 {
+	if x != nil {
+		return x.SpeechMs
+	}
+	return 0
+}
+
+func (x *TranscriptFinal) GetSpeaker() uint32 {
+	if x != nil {
+		return x.Speaker
+	}
+	return 0
+}
+
+func (x *TranscriptFinal) GetSource() string {
+	if x != nil {
+		return x.Source
+	}
+	return ""
+}
+
+func (x *TranscriptFinal) GetAudioFingerprint() uint64 {
+	if x != nil {
+		return x.AudioFingerprint
+	}
+	return 0
+}
+
 type TTSEvent struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Type          string                 `protobuf:"bytes,1,opt,name=type,proto3" json:"type,omitempty"`                                        // started | first_audio | stopped
-	Reason        string                 `protobuf:"bytes,2,opt,name=reason,proto3" json:"reason,omitempty"`                                    // if stopped
+	Type          string                 `protobuf:"bytes,1,opt,name=type,proto3" json:"type,omitempty"`                                        // started | first_audio | stopped | failed
+	Reason        string                 `protobuf:"bytes,2,opt,name=reason,proto3" json:"reason,omitempty"`                                    // if stopped or failed
 	FirstAudioMs  uint32                 `protobuf:"varint,3,opt,name=first_audio_ms,json=firstAudioMs,proto3" json:"first_audio_ms,omitempty"` // optional, only for first_audio
+	TurnId        string                 `protobuf:"bytes,4,opt,name=turn_id,json=turnId,proto3" json:"turn_id,omitempty"`                      // echoed from StartTTS
+	UtteranceId   string                 `protobuf:"bytes,5,opt,name=utterance_id,json=utteranceId,proto3" json:"utterance_id,omitempty"`       // echoed from StartTTS
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *TTSEvent) Reset() {
 	*x = TTSEvent{}
-	mi := &file_gateway_control_proto_msgTypes[5]
+	mi := &file_gateway_control_proto_msgTypes[6]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -288,7 +564,7 @@ func (x *TTSEvent) String() string {
 func (*TTSEvent) ProtoMessage() {}
 
 func (x *TTSEvent) ProtoReflect() protoreflect.Message {
-	mi := &file_gateway_control_proto_msgTypes[5]
+	mi := &file_gateway_control_proto_msgTypes[6]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -301,7 +577,7 @@ func (x *TTSEvent) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use TTSEvent.ProtoReflect.Descriptor instead.
 func (*TTSEvent) Descriptor() ([]byte, []int) {
-	return file_gateway_control_proto_rawDescGZIP(), []int{5}
+	return file_gateway_control_proto_rawDescGZIP(), []int{6}
 }
 
 func (x *TTSEvent) GetType() string {
@@ -325,17 +601,33 @@ func (x *TTSEvent) GetFirstAudioMs() uint32 {
 	return 0
 }
 
+func (x *TTSEvent) GetTurnId() string {
+	if x != nil {
+		return x.TurnId
+	}
+	return ""
+}
+
+func (x *TTSEvent) GetUtteranceId() string {
+	if x != nil {
+		return x.UtteranceId
+	}
+	return ""
+}
+
 type GatewayError struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Code          string                 `protobuf:"bytes,1,opt,name=code,proto3" json:"code,omitempty"`
-	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// STT failures relayed from the sidecar use "stt." plus the ErrorCode's
+	// taxonomy name, e.g. "stt.auth_failed", "stt.socket_closed".
+	Code          string `protobuf:"bytes,1,opt,name=code,proto3" json:"code,omitempty"`
+	Message       string `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *GatewayError) Reset() {
 	*x = GatewayError{}
-	mi := &file_gateway_control_proto_msgTypes[6]
+	mi := &file_gateway_control_proto_msgTypes[7]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -347,7 +639,7 @@ func (x *GatewayError) String() string {
 func (*GatewayError) ProtoMessage() {}
 
 func (x *GatewayError) ProtoReflect() protoreflect.Message {
-	mi := &file_gateway_control_proto_msgTypes[6]
+	mi := &file_gateway_control_proto_msgTypes[7]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -360,7 +652,7 @@ func (x *GatewayError) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use GatewayError.ProtoReflect.Descriptor instead.
 func (*GatewayError) Descriptor() ([]byte, []int) {
-	return file_gateway_control_proto_rawDescGZIP(), []int{6}
+	return file_gateway_control_proto_rawDescGZIP(), []int{7}
 }
 
 func (x *GatewayError) GetCode() string {
@@ -377,28 +669,35 @@ func (x *GatewayError) GetMessage() string {
 	return ""
 }
 
-type FrameTap struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Pcm48K        []byte                 `protobuf:"bytes,1,opt,name=pcm48k,proto3" json:"pcm48k,omitempty"` // 20ms PCM16 mono at 48kHz
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+// StopTTSAck says what the gateway did with a StopTTS: "stopped" (and
+// stopped_utterance_id, what was actually playing), "not_playing" (nothing
+// was), "stale" (another utterance was playing) or "duplicate" (the
+// generation was already applied).
+type StopTTSAck struct {
+	state              protoimpl.MessageState `protogen:"open.v1"`
+	Generation         uint64                 `protobuf:"varint,1,opt,name=generation,proto3" json:"generation,omitempty"`
+	UtteranceId        string                 `protobuf:"bytes,2,opt,name=utterance_id,json=utteranceId,proto3" json:"utterance_id,omitempty"` // echoed from StopTTS
+	Result             string                 `protobuf:"bytes,3,opt,name=result,proto3" json:"result,omitempty"`
+	StoppedUtteranceId string                 `protobuf:"bytes,4,opt,name=stopped_utterance_id,json=stoppedUtteranceId,proto3" json:"stopped_utterance_id,omitempty"`
+	unknownFields      protoimpl.UnknownFields
+	sizeCache          protoimpl.SizeCache
 }
 
-func (x *FrameTap) Reset() {
-	*x = FrameTap{}
-	mi := &file_gateway_control_proto_msgTypes[7]
+func (x *StopTTSAck) Reset() {
+	*x = StopTTSAck{}
+	mi := &file_gateway_control_proto_msgTypes[8]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *FrameTap) String() string {
+func (x *StopTTSAck) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*FrameTap) ProtoMessage() {}
+func (*StopTTSAck) ProtoMessage() {}
 
-func (x *FrameTap) ProtoReflect() protoreflect.Message {
-	mi := &file_gateway_control_proto_msgTypes[7]
+func (x *StopTTSAck) ProtoReflect() protoreflect.Message {
+	mi := &file_gateway_control_proto_msgTypes[8]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -409,31 +708,158 @@ func (x *FrameTap) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use FrameTap.ProtoReflect.Descriptor instead.
-func (*FrameTap) Descriptor() ([]byte, []int) {
-	return file_gateway_control_proto_rawDescGZIP(), []int{7}
+// Deprecated: Use StopTTSAck.ProtoReflect.Descriptor instead.
+func (*StopTTSAck) Descriptor() ([]byte, []int) {
+	return file_gateway_control_proto_rawDescGZIP(), []int{8}
 }
 
-func (x *FrameTap) GetPcm48K() []byte {
+func (x *StopTTSAck) GetGeneration() uint64 {
 	if x != nil {
-		return x.Pcm48K
+		return x.Generation
 	}
-	return nil
+	return 0
 }
 
-type Feature struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Rms           float32                `protobuf:"fixed32,1,opt,name=rms,proto3" json:"rms,omitempty"` // root-mean-square energy for the 20ms frame
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+func (x *StopTTSAck) GetUtteranceId() string {
+	if x != nil {
+		return x.UtteranceId
+	}
+	return ""
 }
 
-func (x *Feature) Reset() {
-	*x = Feature{}
-	mi := &file_gateway_control_proto_msgTypes[8]
-	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-	ms.StoreMessageInfo(mi)
-}
+func (x *StopTTSAck) GetResult() string {
+	if x != nil {
+		return x.Result
+	}
+	return ""
+}
+
+func (x *StopTTSAck) GetStoppedUtteranceId() string {
+	if x != nil {
+		return x.StoppedUtteranceId
+	}
+	return ""
+}
+
+// ArmBargeInAck echoes the thresholds the gateway has in force after an
+// ArmBargeIn, which differ from the ones sent when it clamped them.
+type ArmBargeInAck struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Generation    uint64                 `protobuf:"varint,1,opt,name=generation,proto3" json:"generation,omitempty"`
+	GuardMs       uint32                 `protobuf:"varint,2,opt,name=guard_ms,json=guardMs,proto3" json:"guard_ms,omitempty"`
+	MinRms        uint32                 `protobuf:"varint,3,opt,name=min_rms,json=minRms,proto3" json:"min_rms,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ArmBargeInAck) Reset() {
+	*x = ArmBargeInAck{}
+	mi := &file_gateway_control_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ArmBargeInAck) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ArmBargeInAck) ProtoMessage() {}
+
+func (x *ArmBargeInAck) ProtoReflect() protoreflect.Message {
+	mi := &file_gateway_control_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ArmBargeInAck.ProtoReflect.Descriptor instead.
+func (*ArmBargeInAck) Descriptor() ([]byte, []int) {
+	return file_gateway_control_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *ArmBargeInAck) GetGeneration() uint64 {
+	if x != nil {
+		return x.Generation
+	}
+	return 0
+}
+
+func (x *ArmBargeInAck) GetGuardMs() uint32 {
+	if x != nil {
+		return x.GuardMs
+	}
+	return 0
+}
+
+func (x *ArmBargeInAck) GetMinRms() uint32 {
+	if x != nil {
+		return x.MinRms
+	}
+	return 0
+}
+
+type FrameTap struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Pcm48K        []byte                 `protobuf:"bytes,1,opt,name=pcm48k,proto3" json:"pcm48k,omitempty"` // 20ms PCM16 mono at 48kHz
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FrameTap) Reset() {
+	*x = FrameTap{}
+	mi := &file_gateway_control_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FrameTap) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FrameTap) ProtoMessage() {}
+
+func (x *FrameTap) ProtoReflect() protoreflect.Message {
+	mi := &file_gateway_control_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FrameTap.ProtoReflect.Descriptor instead.
+func (*FrameTap) Descriptor() ([]byte, []int) {
+	return file_gateway_control_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *FrameTap) GetPcm48K() []byte {
+	if x != nil {
+		return x.Pcm48K
+	}
+	return nil
+}
+
+type Feature struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Rms           float32                `protobuf:"fixed32,1,opt,name=rms,proto3" json:"rms,omitempty"` // root-mean-square energy for the 20ms frame
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Feature) Reset() {
+	*x = Feature{}
+	mi := &file_gateway_control_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
 
 func (x *Feature) String() string {
 	return protoimpl.X.MessageStringOf(x)
@@ -442,7 +868,7 @@ func (x *Feature) String() string {
 func (*Feature) ProtoMessage() {}
 
 func (x *Feature) ProtoReflect() protoreflect.Message {
-	mi := &file_gateway_control_proto_msgTypes[8]
+	mi := &file_gateway_control_proto_msgTypes[11]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -455,7 +881,7 @@ func (x *Feature) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Feature.ProtoReflect.Descriptor instead.
 func (*Feature) Descriptor() ([]byte, []int) {
-	return file_gateway_control_proto_rawDescGZIP(), []int{8}
+	return file_gateway_control_proto_rawDescGZIP(), []int{11}
 }
 
 func (x *Feature) GetRms() float32 {
@@ -465,6 +891,108 @@ func (x *Feature) GetRms() float32 {
 	return 0
 }
 
+// SessionClose ends the session deliberately. The gateway sends it after
+// flushing STT; the orchestrator cancels in-flight work, persists the
+// session summary and answers with Ack{info: "session_closed"}.
+type SessionClose struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Reason        string                 `protobuf:"bytes,1,opt,name=reason,proto3" json:"reason,omitempty"` // e.g. idle_exit, participant_left, shutdown
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SessionClose) Reset() {
+	*x = SessionClose{}
+	mi := &file_gateway_control_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SessionClose) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SessionClose) ProtoMessage() {}
+
+func (x *SessionClose) ProtoReflect() protoreflect.Message {
+	mi := &file_gateway_control_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SessionClose.ProtoReflect.Descriptor instead.
+func (*SessionClose) Descriptor() ([]byte, []int) {
+	return file_gateway_control_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *SessionClose) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+// Heartbeat tells the orchestrator the gateway is still alive while nothing
+// else is flowing. Sessions silent for too long are reported on
+// /livez/sessions and eventually closed.
+type Heartbeat struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Seq           uint64                 `protobuf:"varint,1,opt,name=seq,proto3" json:"seq,omitempty"`               // increments per heartbeat on the session
+	TsMs          int64                  `protobuf:"varint,2,opt,name=ts_ms,json=tsMs,proto3" json:"ts_ms,omitempty"` // gateway wall clock, unix ms
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Heartbeat) Reset() {
+	*x = Heartbeat{}
+	mi := &file_gateway_control_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Heartbeat) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Heartbeat) ProtoMessage() {}
+
+func (x *Heartbeat) ProtoReflect() protoreflect.Message {
+	mi := &file_gateway_control_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Heartbeat.ProtoReflect.Descriptor instead.
+func (*Heartbeat) Descriptor() ([]byte, []int) {
+	return file_gateway_control_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *Heartbeat) GetSeq() uint64 {
+	if x != nil {
+		return x.Seq
+	}
+	return 0
+}
+
+func (x *Heartbeat) GetTsMs() int64 {
+	if x != nil {
+		return x.TsMs
+	}
+	return 0
+}
+
 type GatewayEvent struct {
 	state     protoimpl.MessageState `protogen:"open.v1"`
 	SessionId string                 `protobuf:"bytes,1,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
@@ -479,6 +1007,10 @@ type GatewayEvent struct {
 	//	*GatewayEvent_Error
 	//	*GatewayEvent_FrameTap
 	//	*GatewayEvent_Feature
+	//	*GatewayEvent_SessionClose
+	//	*GatewayEvent_Heartbeat
+	//	*GatewayEvent_StopAck
+	//	*GatewayEvent_ArmAck
 	Evt           isGatewayEvent_Evt `protobuf_oneof:"evt"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
@@ -486,7 +1018,7 @@ type GatewayEvent struct {
 
 func (x *GatewayEvent) Reset() {
 	*x = GatewayEvent{}
-	mi := &file_gateway_control_proto_msgTypes[9]
+	mi := &file_gateway_control_proto_msgTypes[14]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -498,7 +1030,7 @@ func (x *GatewayEvent) String() string {
 func (*GatewayEvent) ProtoMessage() {}
 
 func (x *GatewayEvent) ProtoReflect() protoreflect.Message {
-	mi := &file_gateway_control_proto_msgTypes[9]
+	mi := &file_gateway_control_proto_msgTypes[14]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -511,7 +1043,7 @@ func (x *GatewayEvent) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use GatewayEvent.ProtoReflect.Descriptor instead.
 func (*GatewayEvent) Descriptor() ([]byte, []int) {
-	return file_gateway_control_proto_rawDescGZIP(), []int{9}
+	return file_gateway_control_proto_rawDescGZIP(), []int{14}
 }
 
 func (x *GatewayEvent) GetSessionId() string {
@@ -609,6 +1141,42 @@ func (x *GatewayEvent) GetFeature() *Feature {
 	return nil
 }
 
+func (x *GatewayEvent) GetSessionClose() *SessionClose {
+	if x != nil {
+		if x, ok := x.Evt.(*GatewayEvent_SessionClose); ok {
+			return x.SessionClose
+		}
+	}
+	return nil
+}
+
+func (x *GatewayEvent) GetHeartbeat() *Heartbeat {
+	if x != nil {
+		if x, ok := x.Evt.(*GatewayEvent_Heartbeat); ok {
+			return x.Heartbeat
+		}
+	}
+	return nil
+}
+
+func (x *GatewayEvent) GetStopAck() *StopTTSAck {
+	if x != nil {
+		if x, ok := x.Evt.(*GatewayEvent_StopAck); ok {
+			return x.StopAck
+		}
+	}
+	return nil
+}
+
+func (x *GatewayEvent) GetArmAck() *ArmBargeInAck {
+	if x != nil {
+		if x, ok := x.Evt.(*GatewayEvent_ArmAck); ok {
+			return x.ArmAck
+		}
+	}
+	return nil
+}
+
 type isGatewayEvent_Evt interface {
 	isGatewayEvent_Evt()
 }
@@ -649,6 +1217,22 @@ type GatewayEvent_Feature struct {
 	Feature *Feature `protobuf:"bytes,10,opt,name=feature,proto3,oneof"`
 }
 
+type GatewayEvent_SessionClose struct {
+	SessionClose *SessionClose `protobuf:"bytes,11,opt,name=session_close,json=sessionClose,proto3,oneof"`
+}
+
+type GatewayEvent_Heartbeat struct {
+	Heartbeat *Heartbeat `protobuf:"bytes,12,opt,name=heartbeat,proto3,oneof"`
+}
+
+type GatewayEvent_StopAck struct {
+	StopAck *StopTTSAck `protobuf:"bytes,13,opt,name=stop_ack,json=stopAck,proto3,oneof"`
+}
+
+type GatewayEvent_ArmAck struct {
+	ArmAck *ArmBargeInAck `protobuf:"bytes,14,opt,name=arm_ack,json=armAck,proto3,oneof"`
+}
+
 func (*GatewayEvent_SessionOpen) isGatewayEvent_Evt() {}
 
 func (*GatewayEvent_VadStart) isGatewayEvent_Evt() {}
@@ -667,6 +1251,14 @@ func (*GatewayEvent_FrameTap) isGatewayEvent_Evt() {}
 
 func (*GatewayEvent_Feature) isGatewayEvent_Evt() {}
 
+func (*GatewayEvent_SessionClose) isGatewayEvent_Evt() {}
+
+func (*GatewayEvent_Heartbeat) isGatewayEvent_Evt() {}
+
+func (*GatewayEvent_StopAck) isGatewayEvent_Evt() {}
+
+func (*GatewayEvent_ArmAck) isGatewayEvent_Evt() {}
+
 type JoinRoom struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	RoomUrl       string                 `protobuf:"bytes,1,opt,name=room_url,json=roomUrl,proto3" json:"room_url,omitempty"`
@@ -677,7 +1269,7 @@ type JoinRoom struct {
 
 func (x *JoinRoom) Reset() {
 	*x = JoinRoom{}
-	mi := &file_gateway_control_proto_msgTypes[10]
+	mi := &file_gateway_control_proto_msgTypes[15]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -689,7 +1281,7 @@ func (x *JoinRoom) String() string {
 func (*JoinRoom) ProtoMessage() {}
 
 func (x *JoinRoom) ProtoReflect() protoreflect.Message {
-	mi := &file_gateway_control_proto_msgTypes[10]
+	mi := &file_gateway_control_proto_msgTypes[15]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -702,7 +1294,7 @@ func (x *JoinRoom) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use JoinRoom.ProtoReflect.Descriptor instead.
 func (*JoinRoom) Descriptor() ([]byte, []int) {
-	return file_gateway_control_proto_rawDescGZIP(), []int{10}
+	return file_gateway_control_proto_rawDescGZIP(), []int{15}
 }
 
 func (x *JoinRoom) GetRoomUrl() string {
@@ -719,15 +1311,22 @@ func (x *JoinRoom) GetToken() string {
 	return ""
 }
 
+// turn_id/utterance_id are issued by the orchestrator and must be echoed on
+// the transcripts produced while the mic is streaming. preroll_ms asks the
+// gateway to also send that much of the audio buffered while the mic was
+// stopped, so speech that reopened it is not clipped.
 type StartMicToSTT struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
+	TurnId        string                 `protobuf:"bytes,1,opt,name=turn_id,json=turnId,proto3" json:"turn_id,omitempty"`
+	UtteranceId   string                 `protobuf:"bytes,2,opt,name=utterance_id,json=utteranceId,proto3" json:"utterance_id,omitempty"`
+	PrerollMs     uint32                 `protobuf:"varint,3,opt,name=preroll_ms,json=prerollMs,proto3" json:"preroll_ms,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *StartMicToSTT) Reset() {
 	*x = StartMicToSTT{}
-	mi := &file_gateway_control_proto_msgTypes[11]
+	mi := &file_gateway_control_proto_msgTypes[16]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -739,7 +1338,7 @@ func (x *StartMicToSTT) String() string {
 func (*StartMicToSTT) ProtoMessage() {}
 
 func (x *StartMicToSTT) ProtoReflect() protoreflect.Message {
-	mi := &file_gateway_control_proto_msgTypes[11]
+	mi := &file_gateway_control_proto_msgTypes[16]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -752,67 +1351,929 @@ func (x *StartMicToSTT) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use StartMicToSTT.ProtoReflect.Descriptor instead.
 func (*StartMicToSTT) Descriptor() ([]byte, []int) {
-	return file_gateway_control_proto_rawDescGZIP(), []int{11}
+	return file_gateway_control_proto_rawDescGZIP(), []int{16}
 }
 
-type StopMicToSTT struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+func (x *StartMicToSTT) GetTurnId() string {
+	if x != nil {
+		return x.TurnId
+	}
+	return ""
 }
 
-func (x *StopMicToSTT) Reset() {
-	*x = StopMicToSTT{}
-	mi := &file_gateway_control_proto_msgTypes[12]
-	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-	ms.StoreMessageInfo(mi)
+func (x *StartMicToSTT) GetUtteranceId() string {
+	if x != nil {
+		return x.UtteranceId
+	}
+	return ""
+}
+
+func (x *StartMicToSTT) GetPrerollMs() uint32 {
+	if x != nil {
+		return x.PrerollMs
+	}
+	return 0
+}
+
+type StopMicToSTT struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StopMicToSTT) Reset() {
+	*x = StopMicToSTT{}
+	mi := &file_gateway_control_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StopMicToSTT) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StopMicToSTT) ProtoMessage() {}
+
+func (x *StopMicToSTT) ProtoReflect() protoreflect.Message {
+	mi := &file_gateway_control_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StopMicToSTT.ProtoReflect.Descriptor instead.
+func (*StopMicToSTT) Descriptor() ([]byte, []int) {
+	return file_gateway_control_proto_rawDescGZIP(), []int{17}
+}
+
+// turn_id/utterance_id must be echoed on the resulting TTSEvents.
+// provider selects the synthesis path: empty for the gateway default,
+// "service" for the TTS gRPC service. Set when re-routing after a failure.
+// speaking_rate (1.0 = provider default) and pause_ms (gap before this
+// sentence) mirror the user's pace; zero means unset. pause_ms also
+// depends on how the previous sentence ended, and intonation ("question",
+// "exclamation", or "" for a statement) is passed on to the TTS provider
+// as a prosody hint.
+// filler marks a stand-in played while the LLM is slow: gateways play it at
+// once instead of batching it with sentences, and StopTTS{reason:
+// "filler_superseded"} stops only a filler. Empty filler text means a soft
+// ambient sound rather than speech.
+type StartTTS struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Text          string                 `protobuf:"bytes,1,opt,name=text,proto3" json:"text,omitempty"`
+	TurnId        string                 `protobuf:"bytes,2,opt,name=turn_id,json=turnId,proto3" json:"turn_id,omitempty"`
+	UtteranceId   string                 `protobuf:"bytes,3,opt,name=utterance_id,json=utteranceId,proto3" json:"utterance_id,omitempty"`
+	Provider      string                 `protobuf:"bytes,4,opt,name=provider,proto3" json:"provider,omitempty"`
+	SpeakingRate  float32                `protobuf:"fixed32,5,opt,name=speaking_rate,json=speakingRate,proto3" json:"speaking_rate,omitempty"`
+	PauseMs       uint32                 `protobuf:"varint,6,opt,name=pause_ms,json=pauseMs,proto3" json:"pause_ms,omitempty"`
+	Filler        bool                   `protobuf:"varint,7,opt,name=filler,proto3" json:"filler,omitempty"`
+	Intonation    string                 `protobuf:"bytes,8,opt,name=intonation,proto3" json:"intonation,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StartTTS) Reset() {
+	*x = StartTTS{}
+	mi := &file_gateway_control_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StartTTS) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StartTTS) ProtoMessage() {}
+
+func (x *StartTTS) ProtoReflect() protoreflect.Message {
+	mi := &file_gateway_control_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StartTTS.ProtoReflect.Descriptor instead.
+func (*StartTTS) Descriptor() ([]byte, []int) {
+	return file_gateway_control_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *StartTTS) GetText() string {
+	if x != nil {
+		return x.Text
+	}
+	return ""
+}
+
+func (x *StartTTS) GetTurnId() string {
+	if x != nil {
+		return x.TurnId
+	}
+	return ""
+}
+
+func (x *StartTTS) GetUtteranceId() string {
+	if x != nil {
+		return x.UtteranceId
+	}
+	return ""
+}
+
+func (x *StartTTS) GetProvider() string {
+	if x != nil {
+		return x.Provider
+	}
+	return ""
+}
+
+func (x *StartTTS) GetSpeakingRate() float32 {
+	if x != nil {
+		return x.SpeakingRate
+	}
+	return 0
+}
+
+func (x *StartTTS) GetPauseMs() uint32 {
+	if x != nil {
+		return x.PauseMs
+	}
+	return 0
+}
+
+func (x *StartTTS) GetFiller() bool {
+	if x != nil {
+		return x.Filler
+	}
+	return false
+}
+
+func (x *StartTTS) GetIntonation() string {
+	if x != nil {
+		return x.Intonation
+	}
+	return ""
+}
+
+// StopTTS cuts agent speech. utterance_id names the agent utterance meant
+// (the one last reported started, or the filler); the gateway leaves
+// anything else playing alone. generation counts stops within the session:
+// a generation the gateway has already applied is a duplicate or a re-send
+// and is ignored. Gateways that list "stop_tts_ack" in capabilities answer
+// every StopTTS with a StopTTSAck. Empty/zero fields stop whatever plays.
+// boundary "word" asks the gateway to play on to the next gap between words
+// (within a short cap) rather than cut mid-word; "" or "immediate" stops at
+// the next frame. fade_ms then ramps the audio down before it ends, so the
+// cut doesn't click; 0 cuts dead. Gateways that predate these fields stop
+// at once, as before.
+type StopTTS struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Reason        string                 `protobuf:"bytes,1,opt,name=reason,proto3" json:"reason,omitempty"`
+	UtteranceId   string                 `protobuf:"bytes,2,opt,name=utterance_id,json=utteranceId,proto3" json:"utterance_id,omitempty"`
+	Generation    uint64                 `protobuf:"varint,3,opt,name=generation,proto3" json:"generation,omitempty"`
+	FadeMs        uint32                 `protobuf:"varint,4,opt,name=fade_ms,json=fadeMs,proto3" json:"fade_ms,omitempty"`
+	Boundary      string                 `protobuf:"bytes,5,opt,name=boundary,proto3" json:"boundary,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StopTTS) Reset() {
+	*x = StopTTS{}
+	mi := &file_gateway_control_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StopTTS) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StopTTS) ProtoMessage() {}
+
+func (x *StopTTS) ProtoReflect() protoreflect.Message {
+	mi := &file_gateway_control_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StopTTS.ProtoReflect.Descriptor instead.
+func (*StopTTS) Descriptor() ([]byte, []int) {
+	return file_gateway_control_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *StopTTS) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+func (x *StopTTS) GetUtteranceId() string {
+	if x != nil {
+		return x.UtteranceId
+	}
+	return ""
+}
+
+func (x *StopTTS) GetGeneration() uint64 {
+	if x != nil {
+		return x.Generation
+	}
+	return 0
+}
+
+func (x *StopTTS) GetFadeMs() uint32 {
+	if x != nil {
+		return x.FadeMs
+	}
+	return 0
+}
+
+func (x *StopTTS) GetBoundary() string {
+	if x != nil {
+		return x.Boundary
+	}
+	return ""
+}
+
+// TokenDelta carries the reply as the LLM generates it, for text clients.
+// seq counts deltas within the turn from 1; the last one has done set and
+// no text. Speech still follows the sentence-level StartTTS.
+type TokenDelta struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	TurnId        string                 `protobuf:"bytes,1,opt,name=turn_id,json=turnId,proto3" json:"turn_id,omitempty"`
+	Text          string                 `protobuf:"bytes,2,opt,name=text,proto3" json:"text,omitempty"`
+	Seq           uint32                 `protobuf:"varint,3,opt,name=seq,proto3" json:"seq,omitempty"`
+	Done          bool                   `protobuf:"varint,4,opt,name=done,proto3" json:"done,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TokenDelta) Reset() {
+	*x = TokenDelta{}
+	mi := &file_gateway_control_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TokenDelta) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TokenDelta) ProtoMessage() {}
+
+func (x *TokenDelta) ProtoReflect() protoreflect.Message {
+	mi := &file_gateway_control_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TokenDelta.ProtoReflect.Descriptor instead.
+func (*TokenDelta) Descriptor() ([]byte, []int) {
+	return file_gateway_control_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *TokenDelta) GetTurnId() string {
+	if x != nil {
+		return x.TurnId
+	}
+	return ""
+}
+
+func (x *TokenDelta) GetText() string {
+	if x != nil {
+		return x.Text
+	}
+	return ""
+}
+
+func (x *TokenDelta) GetSeq() uint32 {
+	if x != nil {
+		return x.Seq
+	}
+	return 0
+}
+
+func (x *TokenDelta) GetDone() bool {
+	if x != nil {
+		return x.Done
+	}
+	return false
+}
+
+// StopAll is the last word on a session: stop playback, drop queued and
+// buffered speech and stop sending mic audio to STT. Sent when the session
+// closes for any reason and when the orchestrator shuts down.
+type StopAll struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Reason        string                 `protobuf:"bytes,1,opt,name=reason,proto3" json:"reason,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StopAll) Reset() {
+	*x = StopAll{}
+	mi := &file_gateway_control_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StopAll) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StopAll) ProtoMessage() {}
+
+func (x *StopAll) ProtoReflect() protoreflect.Message {
+	mi := &file_gateway_control_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StopAll.ProtoReflect.Descriptor instead.
+func (*StopAll) Descriptor() ([]byte, []int) {
+	return file_gateway_control_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *StopAll) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+// ArmBargeIn sets the gateway's barge-in thresholds. generation counts arms
+// within the session; gateways that list "arm_barge_in_ack" in
+// capabilities answer each one with an ArmBargeInAck.
+type ArmBargeIn struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	GuardMs       uint32                 `protobuf:"varint,1,opt,name=guard_ms,json=guardMs,proto3" json:"guard_ms,omitempty"`
+	MinRms        uint32                 `protobuf:"varint,2,opt,name=min_rms,json=minRms,proto3" json:"min_rms,omitempty"`
+	Generation    uint64                 `protobuf:"varint,3,opt,name=generation,proto3" json:"generation,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ArmBargeIn) Reset() {
+	*x = ArmBargeIn{}
+	mi := &file_gateway_control_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ArmBargeIn) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ArmBargeIn) ProtoMessage() {}
+
+func (x *ArmBargeIn) ProtoReflect() protoreflect.Message {
+	mi := &file_gateway_control_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ArmBargeIn.ProtoReflect.Descriptor instead.
+func (*ArmBargeIn) Descriptor() ([]byte, []int) {
+	return file_gateway_control_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *ArmBargeIn) GetGuardMs() uint32 {
+	if x != nil {
+		return x.GuardMs
+	}
+	return 0
+}
+
+func (x *ArmBargeIn) GetMinRms() uint32 {
+	if x != nil {
+		return x.MinRms
+	}
+	return 0
+}
+
+func (x *ArmBargeIn) GetGeneration() uint64 {
+	if x != nil {
+		return x.Generation
+	}
+	return 0
+}
+
+type Ack struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Info          string                 `protobuf:"bytes,1,opt,name=info,proto3" json:"info,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Ack) Reset() {
+	*x = Ack{}
+	mi := &file_gateway_control_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Ack) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Ack) ProtoMessage() {}
+
+func (x *Ack) ProtoReflect() protoreflect.Message {
+	mi := &file_gateway_control_proto_msgTypes[23]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Ack.ProtoReflect.Descriptor instead.
+func (*Ack) Descriptor() ([]byte, []int) {
+	return file_gateway_control_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *Ack) GetInfo() string {
+	if x != nil {
+		return x.Info
+	}
+	return ""
+}
+
+// SetVolume scales the agent's playback; gain 1.0 is unity.
+type SetVolume struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Gain          float32                `protobuf:"fixed32,1,opt,name=gain,proto3" json:"gain,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SetVolume) Reset() {
+	*x = SetVolume{}
+	mi := &file_gateway_control_proto_msgTypes[24]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SetVolume) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetVolume) ProtoMessage() {}
+
+func (x *SetVolume) ProtoReflect() protoreflect.Message {
+	mi := &file_gateway_control_proto_msgTypes[24]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SetVolume.ProtoReflect.Descriptor instead.
+func (*SetVolume) Descriptor() ([]byte, []int) {
+	return file_gateway_control_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *SetVolume) GetGain() float32 {
+	if x != nil {
+		return x.Gain
+	}
+	return 0
+}
+
+// EndInterview asks the gateway to leave once the current speech has
+// played, then close the session as usual.
+type EndInterview struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Reason        string                 `protobuf:"bytes,1,opt,name=reason,proto3" json:"reason,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *EndInterview) Reset() {
+	*x = EndInterview{}
+	mi := &file_gateway_control_proto_msgTypes[25]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *EndInterview) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EndInterview) ProtoMessage() {}
+
+func (x *EndInterview) ProtoReflect() protoreflect.Message {
+	mi := &file_gateway_control_proto_msgTypes[25]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EndInterview.ProtoReflect.Descriptor instead.
+func (*EndInterview) Descriptor() ([]byte, []int) {
+	return file_gateway_control_proto_rawDescGZIP(), []int{25}
+}
+
+func (x *EndInterview) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+// DisplayText shows an agent sentence as chat/captions instead of speaking
+// it. The orchestrator sends it while TTS is degraded; ids mirror StartTTS.
+type DisplayText struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Text          string                 `protobuf:"bytes,1,opt,name=text,proto3" json:"text,omitempty"`
+	TurnId        string                 `protobuf:"bytes,2,opt,name=turn_id,json=turnId,proto3" json:"turn_id,omitempty"`
+	UtteranceId   string                 `protobuf:"bytes,3,opt,name=utterance_id,json=utteranceId,proto3" json:"utterance_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DisplayText) Reset() {
+	*x = DisplayText{}
+	mi := &file_gateway_control_proto_msgTypes[26]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DisplayText) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DisplayText) ProtoMessage() {}
+
+func (x *DisplayText) ProtoReflect() protoreflect.Message {
+	mi := &file_gateway_control_proto_msgTypes[26]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DisplayText.ProtoReflect.Descriptor instead.
+func (*DisplayText) Descriptor() ([]byte, []int) {
+	return file_gateway_control_proto_rawDescGZIP(), []int{26}
+}
+
+func (x *DisplayText) GetText() string {
+	if x != nil {
+		return x.Text
+	}
+	return ""
+}
+
+func (x *DisplayText) GetTurnId() string {
+	if x != nil {
+		return x.TurnId
+	}
+	return ""
+}
+
+func (x *DisplayText) GetUtteranceId() string {
+	if x != nil {
+		return x.UtteranceId
+	}
+	return ""
+}
+
+// Caption carries live captions for the room when the session asked for
+// them: the candidate's interims and finals (final=false/true) and each
+// agent sentence as it is sent (always final). role is "candidate",
+// "interviewer" (another diarized speaker) or "agent".
+type Caption struct {
+	state       protoimpl.MessageState `protogen:"open.v1"`
+	Role        string                 `protobuf:"bytes,1,opt,name=role,proto3" json:"role,omitempty"`
+	Text        string                 `protobuf:"bytes,2,opt,name=text,proto3" json:"text,omitempty"`
+	Final       bool                   `protobuf:"varint,3,opt,name=final,proto3" json:"final,omitempty"`
+	TurnId      string                 `protobuf:"bytes,4,opt,name=turn_id,json=turnId,proto3" json:"turn_id,omitempty"`
+	UtteranceId string                 `protobuf:"bytes,5,opt,name=utterance_id,json=utteranceId,proto3" json:"utterance_id,omitempty"`
+	// Interim candidate captions: text split into a settled prefix and a
+	// suffix that may still change (see TranscriptInterim)
+	CommittedText string `protobuf:"bytes,6,opt,name=committed_text,json=committedText,proto3" json:"committed_text,omitempty"`
+	VolatileText  string `protobuf:"bytes,7,opt,name=volatile_text,json=volatileText,proto3" json:"volatile_text,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Caption) Reset() {
+	*x = Caption{}
+	mi := &file_gateway_control_proto_msgTypes[27]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Caption) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Caption) ProtoMessage() {}
+
+func (x *Caption) ProtoReflect() protoreflect.Message {
+	mi := &file_gateway_control_proto_msgTypes[27]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Caption.ProtoReflect.Descriptor instead.
+func (*Caption) Descriptor() ([]byte, []int) {
+	return file_gateway_control_proto_rawDescGZIP(), []int{27}
+}
+
+func (x *Caption) GetRole() string {
+	if x != nil {
+		return x.Role
+	}
+	return ""
+}
+
+func (x *Caption) GetText() string {
+	if x != nil {
+		return x.Text
+	}
+	return ""
+}
+
+func (x *Caption) GetFinal() bool {
+	if x != nil {
+		return x.Final
+	}
+	return false
+}
+
+func (x *Caption) GetTurnId() string {
+	if x != nil {
+		return x.TurnId
+	}
+	return ""
+}
+
+func (x *Caption) GetUtteranceId() string {
+	if x != nil {
+		return x.UtteranceId
+	}
+	return ""
+}
+
+func (x *Caption) GetCommittedText() string {
+	if x != nil {
+		return x.CommittedText
+	}
+	return ""
+}
+
+func (x *Caption) GetVolatileText() string {
+	if x != nil {
+		return x.VolatileText
+	}
+	return ""
+}
+
+// ModerationFlag reports a candidate utterance that failed moderation so
+// the gateway can record it in the session's event log. action is what the
+// orchestrator did: "warn", "end" or "flag" (recorded only); violations
+// counts flagged utterances so far in the session.
+type ModerationFlag struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	TurnId        string                 `protobuf:"bytes,1,opt,name=turn_id,json=turnId,proto3" json:"turn_id,omitempty"`
+	UtteranceId   string                 `protobuf:"bytes,2,opt,name=utterance_id,json=utteranceId,proto3" json:"utterance_id,omitempty"`
+	Categories    []string               `protobuf:"bytes,3,rep,name=categories,proto3" json:"categories,omitempty"`
+	Action        string                 `protobuf:"bytes,4,opt,name=action,proto3" json:"action,omitempty"`
+	Violations    uint32                 `protobuf:"varint,5,opt,name=violations,proto3" json:"violations,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ModerationFlag) Reset() {
+	*x = ModerationFlag{}
+	mi := &file_gateway_control_proto_msgTypes[28]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ModerationFlag) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ModerationFlag) ProtoMessage() {}
+
+func (x *ModerationFlag) ProtoReflect() protoreflect.Message {
+	mi := &file_gateway_control_proto_msgTypes[28]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ModerationFlag.ProtoReflect.Descriptor instead.
+func (*ModerationFlag) Descriptor() ([]byte, []int) {
+	return file_gateway_control_proto_rawDescGZIP(), []int{28}
+}
+
+func (x *ModerationFlag) GetTurnId() string {
+	if x != nil {
+		return x.TurnId
+	}
+	return ""
+}
+
+func (x *ModerationFlag) GetUtteranceId() string {
+	if x != nil {
+		return x.UtteranceId
+	}
+	return ""
+}
+
+func (x *ModerationFlag) GetCategories() []string {
+	if x != nil {
+		return x.Categories
+	}
+	return nil
+}
+
+func (x *ModerationFlag) GetAction() string {
+	if x != nil {
+		return x.Action
+	}
+	return ""
+}
+
+func (x *ModerationFlag) GetViolations() uint32 {
+	if x != nil {
+		return x.Violations
+	}
+	return 0
+}
+
+// TurnState reports a turn state machine transition for the session's event
+// log. rejected marks an illegal one that left the state at from_state.
+type TurnState struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	FromState     string                 `protobuf:"bytes,1,opt,name=from_state,json=fromState,proto3" json:"from_state,omitempty"`
+	ToState       string                 `protobuf:"bytes,2,opt,name=to_state,json=toState,proto3" json:"to_state,omitempty"`
+	Trigger       string                 `protobuf:"bytes,3,opt,name=trigger,proto3" json:"trigger,omitempty"`
+	TurnId        string                 `protobuf:"bytes,4,opt,name=turn_id,json=turnId,proto3" json:"turn_id,omitempty"`
+	Rejected      bool                   `protobuf:"varint,5,opt,name=rejected,proto3" json:"rejected,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TurnState) Reset() {
+	*x = TurnState{}
+	mi := &file_gateway_control_proto_msgTypes[29]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TurnState) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TurnState) ProtoMessage() {}
+
+func (x *TurnState) ProtoReflect() protoreflect.Message {
+	mi := &file_gateway_control_proto_msgTypes[29]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TurnState.ProtoReflect.Descriptor instead.
+func (*TurnState) Descriptor() ([]byte, []int) {
+	return file_gateway_control_proto_rawDescGZIP(), []int{29}
+}
+
+func (x *TurnState) GetFromState() string {
+	if x != nil {
+		return x.FromState
+	}
+	return ""
 }
 
-func (x *StopMicToSTT) String() string {
-	return protoimpl.X.MessageStringOf(x)
+func (x *TurnState) GetToState() string {
+	if x != nil {
+		return x.ToState
+	}
+	return ""
 }
 
-func (*StopMicToSTT) ProtoMessage() {}
+func (x *TurnState) GetTrigger() string {
+	if x != nil {
+		return x.Trigger
+	}
+	return ""
+}
 
-func (x *StopMicToSTT) ProtoReflect() protoreflect.Message {
-	mi := &file_gateway_control_proto_msgTypes[12]
+func (x *TurnState) GetTurnId() string {
 	if x != nil {
-		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-		if ms.LoadMessageInfo() == nil {
-			ms.StoreMessageInfo(mi)
-		}
-		return ms
+		return x.TurnId
 	}
-	return mi.MessageOf(x)
+	return ""
 }
 
-// Deprecated: Use StopMicToSTT.ProtoReflect.Descriptor instead.
-func (*StopMicToSTT) Descriptor() ([]byte, []int) {
-	return file_gateway_control_proto_rawDescGZIP(), []int{12}
+func (x *TurnState) GetRejected() bool {
+	if x != nil {
+		return x.Rejected
+	}
+	return false
 }
 
-type StartTTS struct {
+// LLMStatus reports the session entering or leaving degraded mode, where
+// the agent speaks canned lines because the LLM could not answer, so the
+// gateway can record it in the session's event log. reason is why the
+// reply failed ("unavailable", "error", "circuit_open"), or "recovered";
+// failures counts failed replies in a row.
+type LLMStatus struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Text          string                 `protobuf:"bytes,1,opt,name=text,proto3" json:"text,omitempty"`
+	Degraded      bool                   `protobuf:"varint,1,opt,name=degraded,proto3" json:"degraded,omitempty"`
+	Reason        string                 `protobuf:"bytes,2,opt,name=reason,proto3" json:"reason,omitempty"`
+	TurnId        string                 `protobuf:"bytes,3,opt,name=turn_id,json=turnId,proto3" json:"turn_id,omitempty"`
+	Failures      uint32                 `protobuf:"varint,4,opt,name=failures,proto3" json:"failures,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *StartTTS) Reset() {
-	*x = StartTTS{}
-	mi := &file_gateway_control_proto_msgTypes[13]
+func (x *LLMStatus) Reset() {
+	*x = LLMStatus{}
+	mi := &file_gateway_control_proto_msgTypes[30]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *StartTTS) String() string {
+func (x *LLMStatus) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*StartTTS) ProtoMessage() {}
+func (*LLMStatus) ProtoMessage() {}
 
-func (x *StartTTS) ProtoReflect() protoreflect.Message {
-	mi := &file_gateway_control_proto_msgTypes[13]
+func (x *LLMStatus) ProtoReflect() protoreflect.Message {
+	mi := &file_gateway_control_proto_msgTypes[30]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -823,40 +2284,70 @@ func (x *StartTTS) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use StartTTS.ProtoReflect.Descriptor instead.
-func (*StartTTS) Descriptor() ([]byte, []int) {
-	return file_gateway_control_proto_rawDescGZIP(), []int{13}
+// Deprecated: Use LLMStatus.ProtoReflect.Descriptor instead.
+func (*LLMStatus) Descriptor() ([]byte, []int) {
+	return file_gateway_control_proto_rawDescGZIP(), []int{30}
 }
 
-func (x *StartTTS) GetText() string {
+func (x *LLMStatus) GetDegraded() bool {
 	if x != nil {
-		return x.Text
+		return x.Degraded
+	}
+	return false
+}
+
+func (x *LLMStatus) GetReason() string {
+	if x != nil {
+		return x.Reason
 	}
 	return ""
 }
 
-type StopTTS struct {
+func (x *LLMStatus) GetTurnId() string {
+	if x != nil {
+		return x.TurnId
+	}
+	return ""
+}
+
+func (x *LLMStatus) GetFailures() uint32 {
+	if x != nil {
+		return x.Failures
+	}
+	return 0
+}
+
+// BargeInProfileSuggestion names the barge-in profile that fits the audio
+// heard in the session's first seconds: the candidate's noise floor and how
+// loud the agent's own speech comes back through their mic (echo_rms, 0 if
+// the agent didn't speak). applied is set when the session asked for
+// "auto" and now uses the profile's thresholds.
+type BargeInProfileSuggestion struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Reason        string                 `protobuf:"bytes,1,opt,name=reason,proto3" json:"reason,omitempty"`
+	Profile       string                 `protobuf:"bytes,1,opt,name=profile,proto3" json:"profile,omitempty"`
+	Current       string                 `protobuf:"bytes,2,opt,name=current,proto3" json:"current,omitempty"` // the session's profile, "" if none
+	NoiseFloorRms float32                `protobuf:"fixed32,3,opt,name=noise_floor_rms,json=noiseFloorRms,proto3" json:"noise_floor_rms,omitempty"`
+	EchoRms       float32                `protobuf:"fixed32,4,opt,name=echo_rms,json=echoRms,proto3" json:"echo_rms,omitempty"`
+	Applied       bool                   `protobuf:"varint,5,opt,name=applied,proto3" json:"applied,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *StopTTS) Reset() {
-	*x = StopTTS{}
-	mi := &file_gateway_control_proto_msgTypes[14]
+func (x *BargeInProfileSuggestion) Reset() {
+	*x = BargeInProfileSuggestion{}
+	mi := &file_gateway_control_proto_msgTypes[31]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *StopTTS) String() string {
+func (x *BargeInProfileSuggestion) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*StopTTS) ProtoMessage() {}
+func (*BargeInProfileSuggestion) ProtoMessage() {}
 
-func (x *StopTTS) ProtoReflect() protoreflect.Message {
-	mi := &file_gateway_control_proto_msgTypes[14]
+func (x *BargeInProfileSuggestion) ProtoReflect() protoreflect.Message {
+	mi := &file_gateway_control_proto_msgTypes[31]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -867,41 +2358,76 @@ func (x *StopTTS) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use StopTTS.ProtoReflect.Descriptor instead.
-func (*StopTTS) Descriptor() ([]byte, []int) {
-	return file_gateway_control_proto_rawDescGZIP(), []int{14}
+// Deprecated: Use BargeInProfileSuggestion.ProtoReflect.Descriptor instead.
+func (*BargeInProfileSuggestion) Descriptor() ([]byte, []int) {
+	return file_gateway_control_proto_rawDescGZIP(), []int{31}
 }
 
-func (x *StopTTS) GetReason() string {
+func (x *BargeInProfileSuggestion) GetProfile() string {
 	if x != nil {
-		return x.Reason
+		return x.Profile
 	}
 	return ""
 }
 
-type ArmBargeIn struct {
+func (x *BargeInProfileSuggestion) GetCurrent() string {
+	if x != nil {
+		return x.Current
+	}
+	return ""
+}
+
+func (x *BargeInProfileSuggestion) GetNoiseFloorRms() float32 {
+	if x != nil {
+		return x.NoiseFloorRms
+	}
+	return 0
+}
+
+func (x *BargeInProfileSuggestion) GetEchoRms() float32 {
+	if x != nil {
+		return x.EchoRms
+	}
+	return 0
+}
+
+func (x *BargeInProfileSuggestion) GetApplied() bool {
+	if x != nil {
+		return x.Applied
+	}
+	return false
+}
+
+// BeginListening hands the turn to the candidate in one command: the
+// gateway stops agent speech (stop_tts), applies the barge-in thresholds
+// (arm_barge_in), then starts mic-to-STT (mic), before acting on anything
+// sent after it. Unset parts are skipped. Only sent to gateways that
+// advertise "begin_listening"; others get the separate commands in the
+// same order.
+type BeginListening struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	GuardMs       uint32                 `protobuf:"varint,1,opt,name=guard_ms,json=guardMs,proto3" json:"guard_ms,omitempty"`
-	MinRms        uint32                 `protobuf:"varint,2,opt,name=min_rms,json=minRms,proto3" json:"min_rms,omitempty"`
+	StopTts       *StopTTS               `protobuf:"bytes,1,opt,name=stop_tts,json=stopTts,proto3" json:"stop_tts,omitempty"`
+	ArmBargeIn    *ArmBargeIn            `protobuf:"bytes,2,opt,name=arm_barge_in,json=armBargeIn,proto3" json:"arm_barge_in,omitempty"`
+	Mic           *StartMicToSTT         `protobuf:"bytes,3,opt,name=mic,proto3" json:"mic,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *ArmBargeIn) Reset() {
-	*x = ArmBargeIn{}
-	mi := &file_gateway_control_proto_msgTypes[15]
+func (x *BeginListening) Reset() {
+	*x = BeginListening{}
+	mi := &file_gateway_control_proto_msgTypes[32]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *ArmBargeIn) String() string {
+func (x *BeginListening) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ArmBargeIn) ProtoMessage() {}
+func (*BeginListening) ProtoMessage() {}
 
-func (x *ArmBargeIn) ProtoReflect() protoreflect.Message {
-	mi := &file_gateway_control_proto_msgTypes[15]
+func (x *BeginListening) ProtoReflect() protoreflect.Message {
+	mi := &file_gateway_control_proto_msgTypes[32]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -912,47 +2438,56 @@ func (x *ArmBargeIn) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ArmBargeIn.ProtoReflect.Descriptor instead.
-func (*ArmBargeIn) Descriptor() ([]byte, []int) {
-	return file_gateway_control_proto_rawDescGZIP(), []int{15}
+// Deprecated: Use BeginListening.ProtoReflect.Descriptor instead.
+func (*BeginListening) Descriptor() ([]byte, []int) {
+	return file_gateway_control_proto_rawDescGZIP(), []int{32}
 }
 
-func (x *ArmBargeIn) GetGuardMs() uint32 {
+func (x *BeginListening) GetStopTts() *StopTTS {
 	if x != nil {
-		return x.GuardMs
+		return x.StopTts
 	}
-	return 0
+	return nil
 }
 
-func (x *ArmBargeIn) GetMinRms() uint32 {
+func (x *BeginListening) GetArmBargeIn() *ArmBargeIn {
 	if x != nil {
-		return x.MinRms
+		return x.ArmBargeIn
 	}
-	return 0
+	return nil
 }
 
-type Ack struct {
+func (x *BeginListening) GetMic() *StartMicToSTT {
+	if x != nil {
+		return x.Mic
+	}
+	return nil
+}
+
+// CommandBatch carries commands generated within a few milliseconds of each
+// other, in order. Only sent to gateways that advertise "command_batch".
+type CommandBatch struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Info          string                 `protobuf:"bytes,1,opt,name=info,proto3" json:"info,omitempty"`
+	Commands      []*OrchestratorCommand `protobuf:"bytes,1,rep,name=commands,proto3" json:"commands,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *Ack) Reset() {
-	*x = Ack{}
-	mi := &file_gateway_control_proto_msgTypes[16]
+func (x *CommandBatch) Reset() {
+	*x = CommandBatch{}
+	mi := &file_gateway_control_proto_msgTypes[33]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *Ack) String() string {
+func (x *CommandBatch) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*Ack) ProtoMessage() {}
+func (*CommandBatch) ProtoMessage() {}
 
-func (x *Ack) ProtoReflect() protoreflect.Message {
-	mi := &file_gateway_control_proto_msgTypes[16]
+func (x *CommandBatch) ProtoReflect() protoreflect.Message {
+	mi := &file_gateway_control_proto_msgTypes[33]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -963,16 +2498,16 @@ func (x *Ack) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use Ack.ProtoReflect.Descriptor instead.
-func (*Ack) Descriptor() ([]byte, []int) {
-	return file_gateway_control_proto_rawDescGZIP(), []int{16}
+// Deprecated: Use CommandBatch.ProtoReflect.Descriptor instead.
+func (*CommandBatch) Descriptor() ([]byte, []int) {
+	return file_gateway_control_proto_rawDescGZIP(), []int{33}
 }
 
-func (x *Ack) GetInfo() string {
+func (x *CommandBatch) GetCommands() []*OrchestratorCommand {
 	if x != nil {
-		return x.Info
+		return x.Commands
 	}
-	return ""
+	return nil
 }
 
 type OrchestratorCommand struct {
@@ -987,6 +2522,18 @@ type OrchestratorCommand struct {
 	//	*OrchestratorCommand_StopTts
 	//	*OrchestratorCommand_ArmBargeIn
 	//	*OrchestratorCommand_Ack
+	//	*OrchestratorCommand_SetVolume
+	//	*OrchestratorCommand_EndInterview
+	//	*OrchestratorCommand_DisplayText
+	//	*OrchestratorCommand_Caption
+	//	*OrchestratorCommand_ModerationFlag
+	//	*OrchestratorCommand_StopAll
+	//	*OrchestratorCommand_TokenDelta
+	//	*OrchestratorCommand_TurnState
+	//	*OrchestratorCommand_Batch
+	//	*OrchestratorCommand_LlmStatus
+	//	*OrchestratorCommand_BeginListening
+	//	*OrchestratorCommand_ProfileSuggestion
 	Cmd           isOrchestratorCommand_Cmd `protobuf_oneof:"cmd"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
@@ -994,7 +2541,7 @@ type OrchestratorCommand struct {
 
 func (x *OrchestratorCommand) Reset() {
 	*x = OrchestratorCommand{}
-	mi := &file_gateway_control_proto_msgTypes[17]
+	mi := &file_gateway_control_proto_msgTypes[34]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1006,7 +2553,7 @@ func (x *OrchestratorCommand) String() string {
 func (*OrchestratorCommand) ProtoMessage() {}
 
 func (x *OrchestratorCommand) ProtoReflect() protoreflect.Message {
-	mi := &file_gateway_control_proto_msgTypes[17]
+	mi := &file_gateway_control_proto_msgTypes[34]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1019,7 +2566,7 @@ func (x *OrchestratorCommand) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use OrchestratorCommand.ProtoReflect.Descriptor instead.
 func (*OrchestratorCommand) Descriptor() ([]byte, []int) {
-	return file_gateway_control_proto_rawDescGZIP(), []int{17}
+	return file_gateway_control_proto_rawDescGZIP(), []int{34}
 }
 
 func (x *OrchestratorCommand) GetSessionId() string {
@@ -1099,6 +2646,114 @@ func (x *OrchestratorCommand) GetAck() *Ack {
 	return nil
 }
 
+func (x *OrchestratorCommand) GetSetVolume() *SetVolume {
+	if x != nil {
+		if x, ok := x.Cmd.(*OrchestratorCommand_SetVolume); ok {
+			return x.SetVolume
+		}
+	}
+	return nil
+}
+
+func (x *OrchestratorCommand) GetEndInterview() *EndInterview {
+	if x != nil {
+		if x, ok := x.Cmd.(*OrchestratorCommand_EndInterview); ok {
+			return x.EndInterview
+		}
+	}
+	return nil
+}
+
+func (x *OrchestratorCommand) GetDisplayText() *DisplayText {
+	if x != nil {
+		if x, ok := x.Cmd.(*OrchestratorCommand_DisplayText); ok {
+			return x.DisplayText
+		}
+	}
+	return nil
+}
+
+func (x *OrchestratorCommand) GetCaption() *Caption {
+	if x != nil {
+		if x, ok := x.Cmd.(*OrchestratorCommand_Caption); ok {
+			return x.Caption
+		}
+	}
+	return nil
+}
+
+func (x *OrchestratorCommand) GetModerationFlag() *ModerationFlag {
+	if x != nil {
+		if x, ok := x.Cmd.(*OrchestratorCommand_ModerationFlag); ok {
+			return x.ModerationFlag
+		}
+	}
+	return nil
+}
+
+func (x *OrchestratorCommand) GetStopAll() *StopAll {
+	if x != nil {
+		if x, ok := x.Cmd.(*OrchestratorCommand_StopAll); ok {
+			return x.StopAll
+		}
+	}
+	return nil
+}
+
+func (x *OrchestratorCommand) GetTokenDelta() *TokenDelta {
+	if x != nil {
+		if x, ok := x.Cmd.(*OrchestratorCommand_TokenDelta); ok {
+			return x.TokenDelta
+		}
+	}
+	return nil
+}
+
+func (x *OrchestratorCommand) GetTurnState() *TurnState {
+	if x != nil {
+		if x, ok := x.Cmd.(*OrchestratorCommand_TurnState); ok {
+			return x.TurnState
+		}
+	}
+	return nil
+}
+
+func (x *OrchestratorCommand) GetBatch() *CommandBatch {
+	if x != nil {
+		if x, ok := x.Cmd.(*OrchestratorCommand_Batch); ok {
+			return x.Batch
+		}
+	}
+	return nil
+}
+
+func (x *OrchestratorCommand) GetLlmStatus() *LLMStatus {
+	if x != nil {
+		if x, ok := x.Cmd.(*OrchestratorCommand_LlmStatus); ok {
+			return x.LlmStatus
+		}
+	}
+	return nil
+}
+
+func (x *OrchestratorCommand) GetBeginListening() *BeginListening {
+	if x != nil {
+		if x, ok := x.Cmd.(*OrchestratorCommand_BeginListening); ok {
+			return x.BeginListening
+		}
+	}
+	return nil
+}
+
+func (x *OrchestratorCommand) GetProfileSuggestion() *BargeInProfileSuggestion {
+	if x != nil {
+		if x, ok := x.Cmd.(*OrchestratorCommand_ProfileSuggestion); ok {
+			return x.ProfileSuggestion
+		}
+	}
+	return nil
+}
+
 type isOrchestratorCommand_Cmd interface {
 	isOrchestratorCommand_Cmd()
 }
@@ -1131,6 +2786,54 @@ type OrchestratorCommand_Ack struct {
 	Ack *Ack `protobuf:"bytes,8,opt,name=ack,proto3,oneof"`
 }
 
+type OrchestratorCommand_SetVolume struct {
+	SetVolume *SetVolume `protobuf:"bytes,9,opt,name=set_volume,json=setVolume,proto3,oneof"`
+}
+
+type OrchestratorCommand_EndInterview struct {
+	EndInterview *EndInterview `protobuf:"bytes,10,opt,name=end_interview,json=endInterview,proto3,oneof"`
+}
+
+type OrchestratorCommand_DisplayText struct {
+	DisplayText *DisplayText `protobuf:"bytes,11,opt,name=display_text,json=displayText,proto3,oneof"`
+}
+
+type OrchestratorCommand_Caption struct {
+	Caption *Caption `protobuf:"bytes,12,opt,name=caption,proto3,oneof"`
+}
+
+type OrchestratorCommand_ModerationFlag struct {
+	ModerationFlag *ModerationFlag `protobuf:"bytes,13,opt,name=moderation_flag,json=moderationFlag,proto3,oneof"`
+}
+
+type OrchestratorCommand_StopAll struct {
+	StopAll *StopAll `protobuf:"bytes,14,opt,name=stop_all,json=stopAll,proto3,oneof"`
+}
+
+type OrchestratorCommand_TokenDelta struct {
+	TokenDelta *TokenDelta `protobuf:"bytes,15,opt,name=token_delta,json=tokenDelta,proto3,oneof"`
+}
+
+type OrchestratorCommand_TurnState struct {
+	TurnState *TurnState `protobuf:"bytes,16,opt,name=turn_state,json=turnState,proto3,oneof"`
+}
+
+type OrchestratorCommand_Batch struct {
+	Batch *CommandBatch `protobuf:"bytes,17,opt,name=batch,proto3,oneof"`
+}
+
+type OrchestratorCommand_LlmStatus struct {
+	LlmStatus *LLMStatus `protobuf:"bytes,18,opt,name=llm_status,json=llmStatus,proto3,oneof"`
+}
+
+type OrchestratorCommand_BeginListening struct {
+	BeginListening *BeginListening `protobuf:"bytes,19,opt,name=begin_listening,json=beginListening,proto3,oneof"`
+}
+
+type OrchestratorCommand_ProfileSuggestion struct {
+	ProfileSuggestion *BargeInProfileSuggestion `protobuf:"bytes,20,opt,name=profile_suggestion,json=profileSuggestion,proto3,oneof"`
+}
+
 func (*OrchestratorCommand_JoinRoom) isOrchestratorCommand_Cmd() {}
 
 func (*OrchestratorCommand_StartMicToStt) isOrchestratorCommand_Cmd() {}
@@ -1145,37 +2848,116 @@ func (*OrchestratorCommand_ArmBargeIn) isOrchestratorCommand_Cmd() {}
 
 func (*OrchestratorCommand_Ack) isOrchestratorCommand_Cmd() {}
 
+func (*OrchestratorCommand_SetVolume) isOrchestratorCommand_Cmd() {}
+
+func (*OrchestratorCommand_EndInterview) isOrchestratorCommand_Cmd() {}
+
+func (*OrchestratorCommand_DisplayText) isOrchestratorCommand_Cmd() {}
+
+func (*OrchestratorCommand_Caption) isOrchestratorCommand_Cmd() {}
+
+func (*OrchestratorCommand_ModerationFlag) isOrchestratorCommand_Cmd() {}
+
+func (*OrchestratorCommand_StopAll) isOrchestratorCommand_Cmd() {}
+
+func (*OrchestratorCommand_TokenDelta) isOrchestratorCommand_Cmd() {}
+
+func (*OrchestratorCommand_TurnState) isOrchestratorCommand_Cmd() {}
+
+func (*OrchestratorCommand_Batch) isOrchestratorCommand_Cmd() {}
+
+func (*OrchestratorCommand_LlmStatus) isOrchestratorCommand_Cmd() {}
+
+func (*OrchestratorCommand_BeginListening) isOrchestratorCommand_Cmd() {}
+
+func (*OrchestratorCommand_ProfileSuggestion) isOrchestratorCommand_Cmd() {}
+
 var File_gateway_control_proto protoreflect.FileDescriptor
 
 const file_gateway_control_proto_rawDesc = "" +
 	"\n" +
 	"\x15gateway_control.proto\x12\n" +
-	"gateway.v1\"G\n" +
+	"gateway.v1\"\x9b\x01\n" +
 	"\vSessionOpen\x12\x1d\n" +
 	"\n" +
 	"session_id\x18\x01 \x01(\tR\tsessionId\x12\x19\n" +
-	"\broom_url\x18\x02 \x01(\tR\aroomUrl\"\x1f\n" +
+	"\broom_url\x18\x02 \x01(\tR\aroomUrl\x12.\n" +
+	"\x05style\x18\x03 \x01(\v2\x18.gateway.v1.SessionStyleR\x05style\x12\"\n" +
+	"\fcapabilities\x18\x04 \x03(\tR\fcapabilities\"\xaf\x05\n" +
+	"\fSessionStyle\x12\x18\n" +
+	"\apersona\x18\x01 \x01(\tR\apersona\x12\x1c\n" +
+	"\tverbosity\x18\x02 \x01(\tR\tverbosity\x12%\n" +
+	"\vtemperature\x18\x03 \x01(\x01H\x00R\vtemperature\x88\x01\x01\x12\x1d\n" +
+	"\n" +
+	"max_tokens\x18\x04 \x01(\rR\tmaxTokens\x12#\n" +
+	"\rsystem_prompt\x18\x05 \x01(\tR\fsystemPrompt\x12\x1b\n" +
+	"\tflow_json\x18\x06 \x01(\tR\bflowJson\x12'\n" +
+	"\x10barge_in_min_rms\x18\a \x01(\rR\rbargeInMinRms\x12)\n" +
+	"\x11barge_in_guard_ms\x18\b \x01(\rR\x0ebargeInGuardMs\x12\x1a\n" +
+	"\bcaptions\x18\t \x01(\bR\bcaptions\x12!\n" +
+	"\ftoken_stream\x18\n" +
+	" \x01(\bR\vtokenStream\x12!\n" +
+	"\fcontext_json\x18\v \x01(\tR\vcontextJson\x12$\n" +
+	"\x0emax_duration_s\x18\f \x01(\rR\fmaxDurationS\x12*\n" +
+	"\x11barge_in_hangover\x18\r \x01(\rR\x0fbargeInHangover\x12(\n" +
+	"\x10barge_in_profile\x18\x0e \x01(\tR\x0ebargeInProfile\x12\"\n" +
+	"\finstructions\x18\x0f \x01(\tR\finstructions\x12\x1b\n" +
+	"\techo_test\x18\x10 \x01(\bR\bechoTest\x12$\n" +
+	"\x0eno_llm_logging\x18\x11 \x01(\bR\fnoLlmLogging\x126\n" +
+	"\x17no_transcript_retention\x18\x12 \x01(\bR\x15noTranscriptRetentionB\x0e\n" +
+	"\f_temperature\"\x1f\n" +
 	"\bVADStart\x12\x13\n" +
 	"\x05ts_ms\x18\x01 \x01(\x04R\x04tsMs\"\x1d\n" +
 	"\x06VADEnd\x12\x13\n" +
-	"\x05ts_ms\x18\x01 \x01(\x04R\x04tsMs\"J\n" +
+	"\x05ts_ms\x18\x01 \x01(\x04R\x04tsMs\"\xaf\x01\n" +
 	"\x11TranscriptInterim\x12!\n" +
 	"\futterance_id\x18\x01 \x01(\tR\vutteranceId\x12\x12\n" +
-	"\x04text\x18\x02 \x01(\tR\x04text\"H\n" +
+	"\x04text\x18\x02 \x01(\tR\x04text\x12\x17\n" +
+	"\aturn_id\x18\x03 \x01(\tR\x06turnId\x12%\n" +
+	"\x0ecommitted_text\x18\x04 \x01(\tR\rcommittedText\x12#\n" +
+	"\rvolatile_text\x18\x05 \x01(\tR\fvolatileText\"\xfc\x01\n" +
 	"\x0fTranscriptFinal\x12!\n" +
 	"\futterance_id\x18\x01 \x01(\tR\vutteranceId\x12\x12\n" +
-	"\x04text\x18\x02 \x01(\tR\x04text\"\\\n" +
+	"\x04text\x18\x02 \x01(\tR\x04text\x12\x17\n" +
+	"\aturn_id\x18\x03 \x01(\tR\x06turnId\x12\x1d\n" +
+	"\n" +
+	"word_count\x18\x04 \x01(\rR\twordCount\x12\x1b\n" +
+	"\tspeech_ms\x18\x05 \x01(\rR\bspeechMs\x12\x18\n" +
+	"\aspeaker\x18\x06 \x01(\rR\aspeaker\x12\x16\n" +
+	"\x06source\x18\a \x01(\tR\x06source\x12+\n" +
+	"\x11audio_fingerprint\x18\b \x01(\x06R\x10audioFingerprint\"\x98\x01\n" +
 	"\bTTSEvent\x12\x12\n" +
 	"\x04type\x18\x01 \x01(\tR\x04type\x12\x16\n" +
 	"\x06reason\x18\x02 \x01(\tR\x06reason\x12$\n" +
-	"\x0efirst_audio_ms\x18\x03 \x01(\rR\ffirstAudioMs\"<\n" +
+	"\x0efirst_audio_ms\x18\x03 \x01(\rR\ffirstAudioMs\x12\x17\n" +
+	"\aturn_id\x18\x04 \x01(\tR\x06turnId\x12!\n" +
+	"\futterance_id\x18\x05 \x01(\tR\vutteranceId\"<\n" +
 	"\fGatewayError\x12\x12\n" +
 	"\x04code\x18\x01 \x01(\tR\x04code\x12\x18\n" +
-	"\amessage\x18\x02 \x01(\tR\amessage\"\"\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\"\x99\x01\n" +
+	"\n" +
+	"StopTTSAck\x12\x1e\n" +
+	"\n" +
+	"generation\x18\x01 \x01(\x04R\n" +
+	"generation\x12!\n" +
+	"\futterance_id\x18\x02 \x01(\tR\vutteranceId\x12\x16\n" +
+	"\x06result\x18\x03 \x01(\tR\x06result\x120\n" +
+	"\x14stopped_utterance_id\x18\x04 \x01(\tR\x12stoppedUtteranceId\"c\n" +
+	"\rArmBargeInAck\x12\x1e\n" +
+	"\n" +
+	"generation\x18\x01 \x01(\x04R\n" +
+	"generation\x12\x19\n" +
+	"\bguard_ms\x18\x02 \x01(\rR\aguardMs\x12\x17\n" +
+	"\amin_rms\x18\x03 \x01(\rR\x06minRms\"\"\n" +
 	"\bFrameTap\x12\x16\n" +
 	"\x06pcm48k\x18\x01 \x01(\fR\x06pcm48k\"\x1b\n" +
 	"\aFeature\x12\x10\n" +
-	"\x03rms\x18\x01 \x01(\x02R\x03rms\"\xb2\x04\n" +
+	"\x03rms\x18\x01 \x01(\x02R\x03rms\"&\n" +
+	"\fSessionClose\x12\x16\n" +
+	"\x06reason\x18\x01 \x01(\tR\x06reason\"2\n" +
+	"\tHeartbeat\x12\x10\n" +
+	"\x03seq\x18\x01 \x01(\x04R\x03seq\x12\x13\n" +
+	"\x05ts_ms\x18\x02 \x01(\x03R\x04tsMs\"\x95\x06\n" +
 	"\fGatewayEvent\x12\x1d\n" +
 	"\n" +
 	"session_id\x18\x01 \x01(\tR\tsessionId\x12<\n" +
@@ -1188,23 +2970,108 @@ const file_gateway_control_proto_rawDesc = "" +
 	"\x05error\x18\b \x01(\v2\x18.gateway.v1.GatewayErrorH\x00R\x05error\x123\n" +
 	"\tframe_tap\x18\t \x01(\v2\x14.gateway.v1.FrameTapH\x00R\bframeTap\x12/\n" +
 	"\afeature\x18\n" +
-	" \x01(\v2\x13.gateway.v1.FeatureH\x00R\afeatureB\x05\n" +
+	" \x01(\v2\x13.gateway.v1.FeatureH\x00R\afeature\x12?\n" +
+	"\rsession_close\x18\v \x01(\v2\x18.gateway.v1.SessionCloseH\x00R\fsessionClose\x125\n" +
+	"\theartbeat\x18\f \x01(\v2\x15.gateway.v1.HeartbeatH\x00R\theartbeat\x123\n" +
+	"\bstop_ack\x18\r \x01(\v2\x16.gateway.v1.StopTTSAckH\x00R\astopAck\x124\n" +
+	"\aarm_ack\x18\x0e \x01(\v2\x19.gateway.v1.ArmBargeInAckH\x00R\x06armAckB\x05\n" +
 	"\x03evt\";\n" +
 	"\bJoinRoom\x12\x19\n" +
 	"\broom_url\x18\x01 \x01(\tR\aroomUrl\x12\x14\n" +
-	"\x05token\x18\x02 \x01(\tR\x05token\"\x0f\n" +
-	"\rStartMicToSTT\"\x0e\n" +
-	"\fStopMicToSTT\"\x1e\n" +
+	"\x05token\x18\x02 \x01(\tR\x05token\"j\n" +
+	"\rStartMicToSTT\x12\x17\n" +
+	"\aturn_id\x18\x01 \x01(\tR\x06turnId\x12!\n" +
+	"\futterance_id\x18\x02 \x01(\tR\vutteranceId\x12\x1d\n" +
+	"\n" +
+	"preroll_ms\x18\x03 \x01(\rR\tprerollMs\"\x0e\n" +
+	"\fStopMicToSTT\"\xee\x01\n" +
 	"\bStartTTS\x12\x12\n" +
-	"\x04text\x18\x01 \x01(\tR\x04text\"!\n" +
+	"\x04text\x18\x01 \x01(\tR\x04text\x12\x17\n" +
+	"\aturn_id\x18\x02 \x01(\tR\x06turnId\x12!\n" +
+	"\futterance_id\x18\x03 \x01(\tR\vutteranceId\x12\x1a\n" +
+	"\bprovider\x18\x04 \x01(\tR\bprovider\x12#\n" +
+	"\rspeaking_rate\x18\x05 \x01(\x02R\fspeakingRate\x12\x19\n" +
+	"\bpause_ms\x18\x06 \x01(\rR\apauseMs\x12\x16\n" +
+	"\x06filler\x18\a \x01(\bR\x06filler\x12\x1e\n" +
+	"\n" +
+	"intonation\x18\b \x01(\tR\n" +
+	"intonation\"\x99\x01\n" +
 	"\aStopTTS\x12\x16\n" +
-	"\x06reason\x18\x01 \x01(\tR\x06reason\"@\n" +
+	"\x06reason\x18\x01 \x01(\tR\x06reason\x12!\n" +
+	"\futterance_id\x18\x02 \x01(\tR\vutteranceId\x12\x1e\n" +
+	"\n" +
+	"generation\x18\x03 \x01(\x04R\n" +
+	"generation\x12\x17\n" +
+	"\afade_ms\x18\x04 \x01(\rR\x06fadeMs\x12\x1a\n" +
+	"\bboundary\x18\x05 \x01(\tR\bboundary\"_\n" +
+	"\n" +
+	"TokenDelta\x12\x17\n" +
+	"\aturn_id\x18\x01 \x01(\tR\x06turnId\x12\x12\n" +
+	"\x04text\x18\x02 \x01(\tR\x04text\x12\x10\n" +
+	"\x03seq\x18\x03 \x01(\rR\x03seq\x12\x12\n" +
+	"\x04done\x18\x04 \x01(\bR\x04done\"!\n" +
+	"\aStopAll\x12\x16\n" +
+	"\x06reason\x18\x01 \x01(\tR\x06reason\"`\n" +
 	"\n" +
 	"ArmBargeIn\x12\x19\n" +
 	"\bguard_ms\x18\x01 \x01(\rR\aguardMs\x12\x17\n" +
-	"\amin_rms\x18\x02 \x01(\rR\x06minRms\"\x19\n" +
+	"\amin_rms\x18\x02 \x01(\rR\x06minRms\x12\x1e\n" +
+	"\n" +
+	"generation\x18\x03 \x01(\x04R\n" +
+	"generation\"\x19\n" +
 	"\x03Ack\x12\x12\n" +
-	"\x04info\x18\x01 \x01(\tR\x04info\"\xc1\x03\n" +
+	"\x04info\x18\x01 \x01(\tR\x04info\"\x1f\n" +
+	"\tSetVolume\x12\x12\n" +
+	"\x04gain\x18\x01 \x01(\x02R\x04gain\"&\n" +
+	"\fEndInterview\x12\x16\n" +
+	"\x06reason\x18\x01 \x01(\tR\x06reason\"]\n" +
+	"\vDisplayText\x12\x12\n" +
+	"\x04text\x18\x01 \x01(\tR\x04text\x12\x17\n" +
+	"\aturn_id\x18\x02 \x01(\tR\x06turnId\x12!\n" +
+	"\futterance_id\x18\x03 \x01(\tR\vutteranceId\"\xcf\x01\n" +
+	"\aCaption\x12\x12\n" +
+	"\x04role\x18\x01 \x01(\tR\x04role\x12\x12\n" +
+	"\x04text\x18\x02 \x01(\tR\x04text\x12\x14\n" +
+	"\x05final\x18\x03 \x01(\bR\x05final\x12\x17\n" +
+	"\aturn_id\x18\x04 \x01(\tR\x06turnId\x12!\n" +
+	"\futterance_id\x18\x05 \x01(\tR\vutteranceId\x12%\n" +
+	"\x0ecommitted_text\x18\x06 \x01(\tR\rcommittedText\x12#\n" +
+	"\rvolatile_text\x18\a \x01(\tR\fvolatileText\"\xa4\x01\n" +
+	"\x0eModerationFlag\x12\x17\n" +
+	"\aturn_id\x18\x01 \x01(\tR\x06turnId\x12!\n" +
+	"\futterance_id\x18\x02 \x01(\tR\vutteranceId\x12\x1e\n" +
+	"\n" +
+	"categories\x18\x03 \x03(\tR\n" +
+	"categories\x12\x16\n" +
+	"\x06action\x18\x04 \x01(\tR\x06action\x12\x1e\n" +
+	"\n" +
+	"violations\x18\x05 \x01(\rR\n" +
+	"violations\"\x94\x01\n" +
+	"\tTurnState\x12\x1d\n" +
+	"\n" +
+	"from_state\x18\x01 \x01(\tR\tfromState\x12\x19\n" +
+	"\bto_state\x18\x02 \x01(\tR\atoState\x12\x18\n" +
+	"\atrigger\x18\x03 \x01(\tR\atrigger\x12\x17\n" +
+	"\aturn_id\x18\x04 \x01(\tR\x06turnId\x12\x1a\n" +
+	"\brejected\x18\x05 \x01(\bR\brejected\"t\n" +
+	"\tLLMStatus\x12\x1a\n" +
+	"\bdegraded\x18\x01 \x01(\bR\bdegraded\x12\x16\n" +
+	"\x06reason\x18\x02 \x01(\tR\x06reason\x12\x17\n" +
+	"\aturn_id\x18\x03 \x01(\tR\x06turnId\x12\x1a\n" +
+	"\bfailures\x18\x04 \x01(\rR\bfailures\"\xab\x01\n" +
+	"\x18BargeInProfileSuggestion\x12\x18\n" +
+	"\aprofile\x18\x01 \x01(\tR\aprofile\x12\x18\n" +
+	"\acurrent\x18\x02 \x01(\tR\acurrent\x12&\n" +
+	"\x0fnoise_floor_rms\x18\x03 \x01(\x02R\rnoiseFloorRms\x12\x19\n" +
+	"\becho_rms\x18\x04 \x01(\x02R\aechoRms\x12\x18\n" +
+	"\aapplied\x18\x05 \x01(\bR\aapplied\"\xa7\x01\n" +
+	"\x0eBeginListening\x12.\n" +
+	"\bstop_tts\x18\x01 \x01(\v2\x13.gateway.v1.StopTTSR\astopTts\x128\n" +
+	"\farm_barge_in\x18\x02 \x01(\v2\x16.gateway.v1.ArmBargeInR\n" +
+	"armBargeIn\x12+\n" +
+	"\x03mic\x18\x03 \x01(\v2\x19.gateway.v1.StartMicToSTTR\x03mic\"K\n" +
+	"\fCommandBatch\x12;\n" +
+	"\bcommands\x18\x01 \x03(\v2\x1f.gateway.v1.OrchestratorCommandR\bcommands\"\x9d\t\n" +
 	"\x13OrchestratorCommand\x12\x1d\n" +
 	"\n" +
 	"session_id\x18\x01 \x01(\tR\tsessionId\x123\n" +
@@ -1215,7 +3082,24 @@ const file_gateway_control_proto_rawDesc = "" +
 	"\bstop_tts\x18\x06 \x01(\v2\x13.gateway.v1.StopTTSH\x00R\astopTts\x12:\n" +
 	"\farm_barge_in\x18\a \x01(\v2\x16.gateway.v1.ArmBargeInH\x00R\n" +
 	"armBargeIn\x12#\n" +
-	"\x03ack\x18\b \x01(\v2\x0f.gateway.v1.AckH\x00R\x03ackB\x05\n" +
+	"\x03ack\x18\b \x01(\v2\x0f.gateway.v1.AckH\x00R\x03ack\x126\n" +
+	"\n" +
+	"set_volume\x18\t \x01(\v2\x15.gateway.v1.SetVolumeH\x00R\tsetVolume\x12?\n" +
+	"\rend_interview\x18\n" +
+	" \x01(\v2\x18.gateway.v1.EndInterviewH\x00R\fendInterview\x12<\n" +
+	"\fdisplay_text\x18\v \x01(\v2\x17.gateway.v1.DisplayTextH\x00R\vdisplayText\x12/\n" +
+	"\acaption\x18\f \x01(\v2\x13.gateway.v1.CaptionH\x00R\acaption\x12E\n" +
+	"\x0fmoderation_flag\x18\r \x01(\v2\x1a.gateway.v1.ModerationFlagH\x00R\x0emoderationFlag\x120\n" +
+	"\bstop_all\x18\x0e \x01(\v2\x13.gateway.v1.StopAllH\x00R\astopAll\x129\n" +
+	"\vtoken_delta\x18\x0f \x01(\v2\x16.gateway.v1.TokenDeltaH\x00R\n" +
+	"tokenDelta\x126\n" +
+	"\n" +
+	"turn_state\x18\x10 \x01(\v2\x15.gateway.v1.TurnStateH\x00R\tturnState\x120\n" +
+	"\x05batch\x18\x11 \x01(\v2\x18.gateway.v1.CommandBatchH\x00R\x05batch\x126\n" +
+	"\n" +
+	"llm_status\x18\x12 \x01(\v2\x15.gateway.v1.LLMStatusH\x00R\tllmStatus\x12E\n" +
+	"\x0fbegin_listening\x18\x13 \x01(\v2\x1a.gateway.v1.BeginListeningH\x00R\x0ebeginListening\x12U\n" +
+	"\x12profile_suggestion\x18\x14 \x01(\v2$.gateway.v1.BargeInProfileSuggestionH\x00R\x11profileSuggestionB\x05\n" +
 	"\x03cmd2Z\n" +
 	"\x0eGatewayControl\x12H\n" +
 	"\aSession\x12\x18.gateway.v1.GatewayEvent\x1a\x1f.gateway.v1.OrchestratorCommand(\x010\x01B/Z-yuzu/agent/internal/orchestrator/pb;gatewaypbb\x06proto3"
@@ -1232,51 +3116,89 @@ func file_gateway_control_proto_rawDescGZIP() []byte {
 	return file_gateway_control_proto_rawDescData
 }
 
-var file_gateway_control_proto_msgTypes = make([]protoimpl.MessageInfo, 18)
+var file_gateway_control_proto_msgTypes = make([]protoimpl.MessageInfo, 35)
 var file_gateway_control_proto_goTypes = []any{
-	(*SessionOpen)(nil),         // 0: gateway.v1.SessionOpen
-	(*VADStart)(nil),            // 1: gateway.v1.VADStart
-	(*VADEnd)(nil),              // 2: gateway.v1.VADEnd
-	(*TranscriptInterim)(nil),   // 3: gateway.v1.TranscriptInterim
-	(*TranscriptFinal)(nil),     // 4: gateway.v1.TranscriptFinal
-	(*TTSEvent)(nil),            // 5: gateway.v1.TTSEvent
-	(*GatewayError)(nil),        // 6: gateway.v1.GatewayError
-	(*FrameTap)(nil),            // 7: gateway.v1.FrameTap
-	(*Feature)(nil),             // 8: gateway.v1.Feature
-	(*GatewayEvent)(nil),        // 9: gateway.v1.GatewayEvent
-	(*JoinRoom)(nil),            // 10: gateway.v1.JoinRoom
-	(*StartMicToSTT)(nil),       // 11: gateway.v1.StartMicToSTT
-	(*StopMicToSTT)(nil),        // 12: gateway.v1.StopMicToSTT
-	(*StartTTS)(nil),            // 13: gateway.v1.StartTTS
-	(*StopTTS)(nil),             // 14: gateway.v1.StopTTS
-	(*ArmBargeIn)(nil),          // 15: gateway.v1.ArmBargeIn
-	(*Ack)(nil),                 // 16: gateway.v1.Ack
-	(*OrchestratorCommand)(nil), // 17: gateway.v1.OrchestratorCommand
+	(*SessionOpen)(nil),              // 0: gateway.v1.SessionOpen
+	(*SessionStyle)(nil),             // 1: gateway.v1.SessionStyle
+	(*VADStart)(nil),                 // 2: gateway.v1.VADStart
+	(*VADEnd)(nil),                   // 3: gateway.v1.VADEnd
+	(*TranscriptInterim)(nil),        // 4: gateway.v1.TranscriptInterim
+	(*TranscriptFinal)(nil),          // 5: gateway.v1.TranscriptFinal
+	(*TTSEvent)(nil),                 // 6: gateway.v1.TTSEvent
+	(*GatewayError)(nil),             // 7: gateway.v1.GatewayError
+	(*StopTTSAck)(nil),               // 8: gateway.v1.StopTTSAck
+	(*ArmBargeInAck)(nil),            // 9: gateway.v1.ArmBargeInAck
+	(*FrameTap)(nil),                 // 10: gateway.v1.FrameTap
+	(*Feature)(nil),                  // 11: gateway.v1.Feature
+	(*SessionClose)(nil),             // 12: gateway.v1.SessionClose
+	(*Heartbeat)(nil),                // 13: gateway.v1.Heartbeat
+	(*GatewayEvent)(nil),             // 14: gateway.v1.GatewayEvent
+	(*JoinRoom)(nil),                 // 15: gateway.v1.JoinRoom
+	(*StartMicToSTT)(nil),            // 16: gateway.v1.StartMicToSTT
+	(*StopMicToSTT)(nil),             // 17: gateway.v1.StopMicToSTT
+	(*StartTTS)(nil),                 // 18: gateway.v1.StartTTS
+	(*StopTTS)(nil),                  // 19: gateway.v1.StopTTS
+	(*TokenDelta)(nil),               // 20: gateway.v1.TokenDelta
+	(*StopAll)(nil),                  // 21: gateway.v1.StopAll
+	(*ArmBargeIn)(nil),               // 22: gateway.v1.ArmBargeIn
+	(*Ack)(nil),                      // 23: gateway.v1.Ack
+	(*SetVolume)(nil),                // 24: gateway.v1.SetVolume
+	(*EndInterview)(nil),             // 25: gateway.v1.EndInterview
+	(*DisplayText)(nil),              // 26: gateway.v1.DisplayText
+	(*Caption)(nil),                  // 27: gateway.v1.Caption
+	(*ModerationFlag)(nil),           // 28: gateway.v1.ModerationFlag
+	(*TurnState)(nil),                // 29: gateway.v1.TurnState
+	(*LLMStatus)(nil),                // 30: gateway.v1.LLMStatus
+	(*BargeInProfileSuggestion)(nil), // 31: gateway.v1.BargeInProfileSuggestion
+	(*BeginListening)(nil),           // 32: gateway.v1.BeginListening
+	(*CommandBatch)(nil),             // 33: gateway.v1.CommandBatch
+	(*OrchestratorCommand)(nil),      // 34: gateway.v1.OrchestratorCommand
 }
 var file_gateway_control_proto_depIdxs = []int32{
-	0,  // 0: gateway.v1.GatewayEvent.session_open:type_name -> gateway.v1.SessionOpen
-	1,  // 1: gateway.v1.GatewayEvent.vad_start:type_name -> gateway.v1.VADStart
-	2,  // 2: gateway.v1.GatewayEvent.vad_end:type_name -> gateway.v1.VADEnd
-	3,  // 3: gateway.v1.GatewayEvent.transcript_interim:type_name -> gateway.v1.TranscriptInterim
-	4,  // 4: gateway.v1.GatewayEvent.transcript_final:type_name -> gateway.v1.TranscriptFinal
-	5,  // 5: gateway.v1.GatewayEvent.tts:type_name -> gateway.v1.TTSEvent
-	6,  // 6: gateway.v1.GatewayEvent.error:type_name -> gateway.v1.GatewayError
-	7,  // 7: gateway.v1.GatewayEvent.frame_tap:type_name -> gateway.v1.FrameTap
-	8,  // 8: gateway.v1.GatewayEvent.feature:type_name -> gateway.v1.Feature
-	10, // 9: gateway.v1.OrchestratorCommand.join_room:type_name -> gateway.v1.JoinRoom
-	11, // 10: gateway.v1.OrchestratorCommand.start_mic_to_stt:type_name -> gateway.v1.StartMicToSTT
-	12, // 11: gateway.v1.OrchestratorCommand.stop_mic_to_stt:type_name -> gateway.v1.StopMicToSTT
-	13, // 12: gateway.v1.OrchestratorCommand.start_tts:type_name -> gateway.v1.StartTTS
-	14, // 13: gateway.v1.OrchestratorCommand.stop_tts:type_name -> gateway.v1.StopTTS
-	15, // 14: gateway.v1.OrchestratorCommand.arm_barge_in:type_name -> gateway.v1.ArmBargeIn
-	16, // 15: gateway.v1.OrchestratorCommand.ack:type_name -> gateway.v1.Ack
-	9,  // 16: gateway.v1.GatewayControl.Session:input_type -> gateway.v1.GatewayEvent
-	17, // 17: gateway.v1.GatewayControl.Session:output_type -> gateway.v1.OrchestratorCommand
-	17, // [17:18] is the sub-list for method output_type
-	16, // [16:17] is the sub-list for method input_type
-	16, // [16:16] is the sub-list for extension type_name
-	16, // [16:16] is the sub-list for extension extendee
-	0,  // [0:16] is the sub-list for field type_name
+	1,  // 0: gateway.v1.SessionOpen.style:type_name -> gateway.v1.SessionStyle
+	0,  // 1: gateway.v1.GatewayEvent.session_open:type_name -> gateway.v1.SessionOpen
+	2,  // 2: gateway.v1.GatewayEvent.vad_start:type_name -> gateway.v1.VADStart
+	3,  // 3: gateway.v1.GatewayEvent.vad_end:type_name -> gateway.v1.VADEnd
+	4,  // 4: gateway.v1.GatewayEvent.transcript_interim:type_name -> gateway.v1.TranscriptInterim
+	5,  // 5: gateway.v1.GatewayEvent.transcript_final:type_name -> gateway.v1.TranscriptFinal
+	6,  // 6: gateway.v1.GatewayEvent.tts:type_name -> gateway.v1.TTSEvent
+	7,  // 7: gateway.v1.GatewayEvent.error:type_name -> gateway.v1.GatewayError
+	10, // 8: gateway.v1.GatewayEvent.frame_tap:type_name -> gateway.v1.FrameTap
+	11, // 9: gateway.v1.GatewayEvent.feature:type_name -> gateway.v1.Feature
+	12, // 10: gateway.v1.GatewayEvent.session_close:type_name -> gateway.v1.SessionClose
+	13, // 11: gateway.v1.GatewayEvent.heartbeat:type_name -> gateway.v1.Heartbeat
+	8,  // 12: gateway.v1.GatewayEvent.stop_ack:type_name -> gateway.v1.StopTTSAck
+	9,  // 13: gateway.v1.GatewayEvent.arm_ack:type_name -> gateway.v1.ArmBargeInAck
+	19, // 14: gateway.v1.BeginListening.stop_tts:type_name -> gateway.v1.StopTTS
+	22, // 15: gateway.v1.BeginListening.arm_barge_in:type_name -> gateway.v1.ArmBargeIn
+	16, // 16: gateway.v1.BeginListening.mic:type_name -> gateway.v1.StartMicToSTT
+	34, // 17: gateway.v1.CommandBatch.commands:type_name -> gateway.v1.OrchestratorCommand
+	15, // 18: gateway.v1.OrchestratorCommand.join_room:type_name -> gateway.v1.JoinRoom
+	16, // 19: gateway.v1.OrchestratorCommand.start_mic_to_stt:type_name -> gateway.v1.StartMicToSTT
+	17, // 20: gateway.v1.OrchestratorCommand.stop_mic_to_stt:type_name -> gateway.v1.StopMicToSTT
+	18, // 21: gateway.v1.OrchestratorCommand.start_tts:type_name -> gateway.v1.StartTTS
+	19, // 22: gateway.v1.OrchestratorCommand.stop_tts:type_name -> gateway.v1.StopTTS
+	22, // 23: gateway.v1.OrchestratorCommand.arm_barge_in:type_name -> gateway.v1.ArmBargeIn
+	23, // 24: gateway.v1.OrchestratorCommand.ack:type_name -> gateway.v1.Ack
+	24, // 25: gateway.v1.OrchestratorCommand.set_volume:type_name -> gateway.v1.SetVolume
+	25, // 26: gateway.v1.OrchestratorCommand.end_interview:type_name -> gateway.v1.EndInterview
+	26, // 27: gateway.v1.OrchestratorCommand.display_text:type_name -> gateway.v1.DisplayText
+	27, // 28: gateway.v1.OrchestratorCommand.caption:type_name -> gateway.v1.Caption
+	28, // 29: gateway.v1.OrchestratorCommand.moderation_flag:type_name -> gateway.v1.ModerationFlag
+	21, // 30: gateway.v1.OrchestratorCommand.stop_all:type_name -> gateway.v1.StopAll
+	20, // 31: gateway.v1.OrchestratorCommand.token_delta:type_name -> gateway.v1.TokenDelta
+	29, // 32: gateway.v1.OrchestratorCommand.turn_state:type_name -> gateway.v1.TurnState
+	33, // 33: gateway.v1.OrchestratorCommand.batch:type_name -> gateway.v1.CommandBatch
+	30, // 34: gateway.v1.OrchestratorCommand.llm_status:type_name -> gateway.v1.LLMStatus
+	32, // 35: gateway.v1.OrchestratorCommand.begin_listening:type_name -> gateway.v1.BeginListening
+	31, // 36: gateway.v1.OrchestratorCommand.profile_suggestion:type_name -> gateway.v1.BargeInProfileSuggestion
+	14, // 37: gateway.v1.GatewayControl.Session:input_type -> gateway.v1.GatewayEvent
+	34, // 38: gateway.v1.GatewayControl.Session:output_type -> gateway.v1.OrchestratorCommand
+	38, // [38:39] is the sub-list for method output_type
+	37, // [37:38] is the sub-list for method input_type
+	37, // [37:37] is the sub-list for extension type_name
+	37, // [37:37] is the sub-list for extension extendee
+	0,  // [0:37] is the sub-list for field type_name
 }
 
 func init() { file_gateway_control_proto_init() }
@@ -1284,7 +3206,8 @@ func file_gateway_control_proto_init() {
 	if File_gateway_control_proto != nil {
 		return
 	}
-	file_gateway_control_proto_msgTypes[9].OneofWrappers = []any{
+	file_gateway_control_proto_msgTypes[1].OneofWrappers = []any{}
+	file_gateway_control_proto_msgTypes[14].OneofWrappers = []any{
 		(*GatewayEvent_SessionOpen)(nil),
 		(*GatewayEvent_VadStart)(nil),
 		(*GatewayEvent_VadEnd)(nil),
@@ -1294,8 +3217,12 @@ func file_gateway_control_proto_init() {
 		(*GatewayEvent_Error)(nil),
 		(*GatewayEvent_FrameTap)(nil),
 		(*GatewayEvent_Feature)(nil),
+		(*GatewayEvent_SessionClose)(nil),
+		(*GatewayEvent_Heartbeat)(nil),
+		(*GatewayEvent_StopAck)(nil),
+		(*GatewayEvent_ArmAck)(nil),
 	}
-	file_gateway_control_proto_msgTypes[17].OneofWrappers = []any{
+	file_gateway_control_proto_msgTypes[34].OneofWrappers = []any{
 		(*OrchestratorCommand_JoinRoom)(nil),
 		(*OrchestratorCommand_StartMicToStt)(nil),
 		(*OrchestratorCommand_StopMicToStt)(nil),
@@ -1303,6 +3230,18 @@ func file_gateway_control_proto_init() {
 		(*OrchestratorCommand_StopTts)(nil),
 		(*OrchestratorCommand_ArmBargeIn)(nil),
 		(*OrchestratorCommand_Ack)(nil),
+		(*OrchestratorCommand_SetVolume)(nil),
+		(*OrchestratorCommand_EndInterview)(nil),
+		(*OrchestratorCommand_DisplayText)(nil),
+		(*OrchestratorCommand_Caption)(nil),
+		(*OrchestratorCommand_ModerationFlag)(nil),
+		(*OrchestratorCommand_StopAll)(nil),
+		(*OrchestratorCommand_TokenDelta)(nil),
+		(*OrchestratorCommand_TurnState)(nil),
+		(*OrchestratorCommand_Batch)(nil),
+		(*OrchestratorCommand_LlmStatus)(nil),
+		(*OrchestratorCommand_BeginListening)(nil),
+		(*OrchestratorCommand_ProfileSuggestion)(nil),
 	}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
@@ -1310,7 +3249,7 @@ func file_gateway_control_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_gateway_control_proto_rawDesc), len(file_gateway_control_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   18,
+			NumMessages:   35,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
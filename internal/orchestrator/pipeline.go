@@ -0,0 +1,116 @@
+package orchestrator
+
+import (
+	"context"
+	"log"
+	"strings"
+	"time"
+
+	gw "yuzu/agent/internal/orchestrator/pb"
+)
+
+// pipeline.go overlaps the candidate's next turn with the agent's speech.
+// Normally a final that arrives while the agent is SPEAKING, without a
+// barge-in, is dropped (see turnstate.go), so whatever the candidate says
+// before playback ends is lost and they wait for the agent to finish before
+// being heard. With ORCH_PIPELINE_TURNS set, mic-to-STT stays open during
+// playback (it takes precedence over ORCH_HALF_DUPLEX) and such finals are
+// held under the provisional next turn, which StartMicToSTT already opened
+// when the previous final was answered. Only finals carrying that turn's
+// utterance ID are held; others (a late final of an earlier utterance) are
+// dropped as foreign. When TTS stops the turn is committed, and its held
+// text answered as one final, with no wait for the candidate to speak
+// again. A barge-in means the candidate is still talking, so the turn is
+// committed when the final of the utterance that barged in arrives, with
+// the held text in front of it. Held finals count in
+// orch_pipelined_finals_total{outcome} and the time they waited in
+// orch_pipelined_wait_ms.
+//
+// The mode relies on the gateway keeping the agent's voice out of STT
+// (echo cancellation or ducking): a held echo would be answered.
+
+// pipelineState is embedded in sessionState.
+type pipelineState struct {
+	heldTurn string    // provisional turn of the held finals; empty when none
+	heldUtt  string    // utterance ID of the latest
+	heldText []string  // in arrival order
+	heldAt   time.Time // when the first was held
+	bargedIn bool      // a barge-in fired; the next final commits the turn
+}
+
+// holdFinal keeps a final heard while the agent speaks. Callers hold st.mu.
+func (s *Server) holdFinal(st *sessionState, utteranceID, text string) {
+	if !matchesIssued(st.userUtteranceID, utteranceID) {
+		metricPipelinedFinals.WithLabelValues("foreign").Inc()
+		log.Printf("[orch] pipelining: dropping final during playback sid=%s utterance=%s (turn=%s wants %s)", st.id, utteranceID, st.turnID, st.userUtteranceID)
+		return
+	}
+	if st.heldTurn == "" {
+		st.heldTurn = st.turnID
+		st.heldAt = s.clock.Now()
+	}
+	st.heldUtt = utteranceID
+	st.heldText = append(st.heldText, text)
+	metricPipelinedFinals.WithLabelValues("held").Inc()
+	log.Printf("[orch] pipelining: final held for turn=%s during playback sid=%s utterance=%s", st.heldTurn, st.id, utteranceID)
+}
+
+// commitPipelined answers the held finals once the candidate has the floor;
+// by is "tts_end" or "barge_in". After a barge-in the turn waits for the
+// final of the utterance that barged in (see mergeBargeIn). Callers must not
+// hold st.mu.
+func (s *Server) commitPipelined(ctx context.Context, st *sessionState, by string, send func(*gw.OrchestratorCommand)) {
+	st.mu.Lock()
+	if st.heldTurn != "" && (by == "barge_in" || st.bargedIn) {
+		// Playback stopping after the barge-in changes nothing
+		if !st.bargedIn {
+			st.bargedIn = true
+			log.Printf("[orch] pipelining: barge-in on turn=%s sid=%s, waiting for its final", st.heldTurn, st.id)
+		}
+		st.mu.Unlock()
+		return
+	}
+	held := st.pipelineState
+	st.pipelineState = pipelineState{}
+	state, turnID := st.state, st.turnID
+	st.mu.Unlock()
+	if held.heldTurn == "" {
+		return
+	}
+	if held.heldTurn != turnID || state != stateListening {
+		// The turn moved on some other way; its words were not for this reply
+		metricPipelinedFinals.WithLabelValues("stale").Inc()
+		log.Printf("[orch] pipelining: dropping finals held for turn=%s sid=%s (turn=%s state=%s)", held.heldTurn, st.id, turnID, state)
+		return
+	}
+	wait := s.clock.Now().Sub(held.heldAt)
+	metricPipelinedFinals.WithLabelValues("committed_" + by).Inc()
+	metricPipelinedWait.Observe(float64(wait.Milliseconds()))
+	log.Printf("[orch] pipelining: committing turn=%s on %s sid=%s finals=%d waited=%dms", held.heldTurn, by, st.id, len(held.heldText), wait.Milliseconds())
+	s.answerFinal(ctx, st, st.id, held.heldUtt, strings.Join(held.heldText, " "), send)
+}
+
+// mergeBargeIn puts the finals held before a barge-in in front of text, the
+// final of the utterance that barged in, and commits the turn. A final of
+// another utterance leaves them held; if the turn moved on they are dropped
+// as stale. Callers hold st.mu.
+func (s *Server) mergeBargeIn(st *sessionState, utteranceID, text string) string {
+	if !st.bargedIn {
+		return text
+	}
+	if st.heldTurn == st.turnID && !matchesIssued(st.userUtteranceID, utteranceID) {
+		return text
+	}
+	held := st.pipelineState
+	st.pipelineState = pipelineState{}
+	if held.heldTurn != st.turnID {
+		metricPipelinedFinals.WithLabelValues("stale").Inc()
+		log.Printf("[orch] pipelining: dropping finals held for turn=%s sid=%s (turn=%s)", held.heldTurn, st.id, st.turnID)
+		return text
+	}
+	wait := s.clock.Now().Sub(held.heldAt)
+	metricPipelinedFinals.WithLabelValues("committed_barge_in").Inc()
+	metricPipelinedWait.Observe(float64(wait.Milliseconds()))
+	log.Printf("[orch] pipelining: committing turn=%s on the barge-in's final sid=%s finals=%d waited=%dms", held.heldTurn, st.id, len(held.heldText)+1, wait.Milliseconds())
+	return strings.Join(append(held.heldText, text), " ")
+}
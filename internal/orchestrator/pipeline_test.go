@@ -0,0 +1,98 @@
+package orchestrator
+
+import (
+	"context"
+	"testing"
+
+	"yuzu/agent/internal/clock"
+	gw "yuzu/agent/internal/orchestrator/pb"
+)
+
+func TestPipelinedFinalsWaitForPlayback(t *testing.T) {
+	// Echo mode answers without an LLM, so replies show up as StartTTS
+	s := &Server{sess: map[string]*sessionState{}, clock: clock.Real, pipelineTurns: true, halfDuplex: true}
+	st := &sessionState{id: "s1", echoTest: true}
+	s.sess["s1"] = st
+	st.openTurn()
+	var cmds []*gw.OrchestratorCommand
+	send := func(c *gw.OrchestratorCommand) { cmds = append(cmds, c) }
+	replies := func() []string {
+		var out []string
+		for _, c := range cmds {
+			if tts := c.GetStartTts(); tts != nil {
+				out = append(out, tts.GetText())
+			}
+		}
+		return out
+	}
+	ctx := context.Background()
+
+	s.handleTranscriptFinal(ctx, st, "s1", "t1-u", "Hello.", send)
+	s.handleTTSEvent(st, "started", 0, "t1-a1", "", send)
+	s.handleTTSEvent(st, "first_audio", 0, "t1-a1", "", send)
+	for _, c := range cmds {
+		if c.GetStopMicToStt() != nil {
+			t.Fatal("mic gated during playback with pipelining on")
+		}
+	}
+
+	// Heard over the agent: held under t2, not answered yet
+	cmds = nil
+	s.handleTranscriptFinal(ctx, st, "s1", "t2-u", "I have a question", send)
+	s.handleTranscriptFinal(ctx, st, "s1", "t1-u", "Hello again.", send) // late, of the answered turn
+	s.handleTranscriptFinal(ctx, st, "s1", "t2-u.1", "about the role.", send)
+	if r := replies(); len(r) != 0 {
+		t.Fatalf("answered during playback: %q", r)
+	}
+	st.mu.Lock()
+	if st.heldTurn != "t2" || len(st.heldText) != 2 {
+		t.Fatalf("held turn=%q text=%q", st.heldTurn, st.heldText)
+	}
+	st.mu.Unlock()
+
+	// Playback ends: the turn is committed as one final
+	s.handleTTSEvent(st, "stopped", 0, "t1-a1", "completed", send)
+	s.commitPipelined(ctx, st, "tts_end", send)
+	if r := replies(); len(r) != 1 || r[0] != "You said: I have a question about the role." {
+		t.Fatalf("replies after playback = %q", r)
+	}
+	st.mu.Lock()
+	if st.heldTurn != "" || st.turnID != "t3" {
+		t.Fatalf("after commit held=%q turn=%q", st.heldTurn, st.turnID)
+	}
+	st.mu.Unlock()
+
+	// A barge-in commits with the final of the utterance that barged in
+	cmds = nil
+	s.handleTTSEvent(st, "started", 0, "t2-a2", "", send)
+	s.handleTranscriptFinal(ctx, st, "s1", "t3-u", "Wait.", send)
+	st.mu.Lock()
+	s.setState(st, stateListening, triggerBargeIn)
+	st.mu.Unlock()
+	s.commitPipelined(ctx, st, "barge_in", send)
+	s.handleTTSEvent(st, "stopped", 0, "t2-a2", "interrupted", send)
+	s.commitPipelined(ctx, st, "tts_end", send)
+	if r := replies(); len(r) != 0 {
+		t.Fatalf("answered before the barge-in's final: %q", r)
+	}
+	s.handleTranscriptFinal(ctx, st, "s1", "t3-u.1", "Let me finish.", send)
+	if r := replies(); len(r) != 1 || r[0] != "You said: Wait. Let me finish." {
+		t.Fatalf("replies after barge-in = %q", r)
+	}
+	st.mu.Lock()
+	if st.heldTurn != "" || st.bargedIn {
+		t.Fatalf("after barge-in commit held=%q barged=%v", st.heldTurn, st.bargedIn)
+	}
+	st.mu.Unlock()
+
+	// Off, a final during playback is dropped as before
+	s.pipelineTurns = false
+	cmds = nil
+	s.handleTTSEvent(st, "started", 0, "t3-a3", "", send)
+	s.handleTranscriptFinal(ctx, st, "s1", "t4-u", "Ignored.", send)
+	s.handleTTSEvent(st, "stopped", 0, "t3-a3", "completed", send)
+	s.commitPipelined(ctx, st, "tts_end", send)
+	if r := replies(); len(r) != 0 {
+		t.Fatalf("pipelining off answered %q", r)
+	}
+}
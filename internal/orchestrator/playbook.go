@@ -0,0 +1,196 @@
+package orchestrator
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"regexp"
+	"strings"
+
+	gw "yuzu/agent/internal/orchestrator/pb"
+)
+
+// playbook.go keeps the agent talking when the LLM can't answer. A reply
+// fails when the LLM service can't be reached (dialing, or opening the
+// stream after the reconnect), when the request can't be sent, or when the
+// stream reports an error or breaks before its first sentence; an open
+// circuit tries ORCH_LLM_FALLBACK_DEPLOYMENT first (see llmfallback.go).
+// The agent then speaks a canned line from the current flow stage's
+// fallbacks, else ORCH_LLM_CANNED, else ORCH_LLM_APOLOGY, and stays silent
+// when all are empty. Lines are tagged "question" (the candidate asked
+// something) or "answer"; lines tagged for the situation are preferred over
+// untagged ones, and successive canned replies rotate through them.
+//
+// The first failed reply puts the session in degraded mode. Later turns
+// still go to the LLM, and its first sentence ends degraded mode. Both
+// transitions are sent to the gateway as LLMStatus for the event log, and
+// the summary records llm_degraded and canned_replies. Counted in
+// orch_llm_degraded_total{event}.
+//
+// ORCH_LLM_CANNED lists lines separated by "|", each optionally prefixed
+// with its tag and a colon ("question:Good question..."); none leaves only
+// the apology. ORCH_LLM_PLAYBOOK=false (default true) turns all of this
+// off: failed replies are silent and an open circuit gets the apology.
+
+// CannedReply is a line spoken in place of an LLM reply.
+type CannedReply struct {
+	Text string `json:"text"`
+	When string `json:"when,omitempty"` // question, answer, or empty for either
+}
+
+const (
+	cannedQuestion = "question"
+	cannedAnswer   = "answer"
+
+	maxCannedReplies = 16
+
+	defaultLLMCanned = "answer:Thanks, that's helpful. Could you tell me a bit more about that?|" +
+		"answer:I see. Can you walk me through a specific example?|" +
+		"question:Good question. Let's come back to that in a moment. Could you tell me more about your most recent role?"
+)
+
+// Why a reply failed, as reported in LLMStatus
+const (
+	llmFailUnavailable = "unavailable"
+	llmFailError       = "error"
+	llmFailCircuitOpen = "circuit_open"
+	llmRecovered       = "recovered"
+)
+
+// llmHealth is embedded in sessionState.
+type llmHealth struct {
+	failures     int // failed replies in a row
+	degraded     bool
+	everDegraded bool
+	canned       int // canned lines spoken in the session
+}
+
+// validateCanned checks a flow stage's fallbacks.
+func validateCanned(cs []CannedReply) error {
+	if len(cs) > maxCannedReplies {
+		return fmt.Errorf("at most %d fallbacks", maxCannedReplies)
+	}
+	for i, c := range cs {
+		if strings.TrimSpace(c.Text) == "" {
+			return fmt.Errorf("fallback %d: text is required", i)
+		}
+		switch c.When {
+		case "", cannedQuestion, cannedAnswer:
+		default:
+			return fmt.Errorf("fallback %d: when must be %q, %q or empty", i, cannedQuestion, cannedAnswer)
+		}
+	}
+	return nil
+}
+
+// parseCanned reads ORCH_LLM_CANNED.
+func parseCanned(v string) []CannedReply {
+	var out []CannedReply
+	for _, line := range strings.Split(v, "|") {
+		c := CannedReply{Text: strings.TrimSpace(line)}
+		if tag, text, ok := strings.Cut(c.Text, ":"); ok && (tag == cannedQuestion || tag == cannedAnswer) {
+			c = CannedReply{Text: strings.TrimSpace(text), When: tag}
+		}
+		if c.Text != "" {
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+var questionStart = regexp.MustCompile(`(?i)^(?:(?:so|and|but|okay|ok|well|um|uh)\b[\s,]*)*(?:what|why|how|when|where|who|which|can|could|would|will|do|does|did|is|are|should)\b`)
+
+// cannedTag says whether the candidate asked a question or answered one.
+func cannedTag(userText string) string {
+	t := strings.TrimSpace(userText)
+	if strings.HasSuffix(t, "?") || questionStart.MatchString(t) {
+		return cannedQuestion
+	}
+	return cannedAnswer
+}
+
+// pickCanned returns the line to speak in place of a reply to userText and
+// the metric event for it; "" when there is nothing to say. Callers hold
+// st.mu.
+func (s *Server) pickCanned(st *sessionState, userText string) (text, event string) {
+	pool := s.llmCanned
+	if st.flow != nil {
+		if fb := st.flow.Stages[st.stage].Fallbacks; len(fb) > 0 {
+			pool = fb
+		}
+	}
+	tag := cannedTag(userText)
+	var tagged, untagged []string
+	for _, c := range pool {
+		switch c.When {
+		case tag:
+			tagged = append(tagged, c.Text)
+		case "":
+			untagged = append(untagged, c.Text)
+		}
+	}
+	if len(tagged) == 0 {
+		tagged = untagged
+	}
+	switch {
+	case len(tagged) > 0:
+		return tagged[st.llm.canned%len(tagged)], "canned"
+	case s.llmApology != "":
+		return s.llmApology, "apology"
+	}
+	return "", "silent"
+}
+
+func llmStatusCmd(sid string, degraded bool, reason, turnID string, failures int) *gw.OrchestratorCommand {
+	return &gw.OrchestratorCommand{SessionId: sid, Cmd: &gw.OrchestratorCommand_LlmStatus{
+		LlmStatus: &gw.LLMStatus{Degraded: degraded, Reason: reason, TurnId: turnID, Failures: uint32(failures)},
+	}}
+}
+
+// llmFailed covers a reply to turnID that failed for reason before its
+// first sentence with a canned line, entering degraded mode if the session
+// is not in it yet. Callers must not hold st.mu.
+func (s *Server) llmFailed(ctx context.Context, sid, turnID, userText, reason string, send func(*gw.OrchestratorCommand)) {
+	if !s.llmPlaybook || ctx.Err() != nil {
+		return
+	}
+	st := s.lookup(sid)
+	if st == nil {
+		return
+	}
+	st.mu.Lock()
+	st.llm.failures++
+	var status *gw.OrchestratorCommand
+	if !st.llm.degraded {
+		st.llm.degraded, st.llm.everDegraded = true, true
+		status = llmStatusCmd(sid, true, reason, turnID, st.llm.failures)
+		metricLLMDegraded.WithLabelValues("degraded").Inc()
+		log.Printf("[orch] llm reply failed sid=%s turn=%s reason=%s; degraded mode", sid, turnID, reason)
+	}
+	text, event := s.pickCanned(st, userText)
+	if text != "" {
+		st.llm.canned++
+	}
+	st.mu.Unlock()
+
+	metricLLMDegraded.WithLabelValues(event).Inc()
+	if status != nil {
+		send(status)
+	}
+	if text != "" {
+		s.speakCanned(st, turnID, text, send)
+	}
+}
+
+// llmAnswered notes a sentence from the LLM, which ends degraded mode. It
+// returns the LLMStatus to send, or nil. Callers hold st.mu.
+func (s *Server) llmAnswered(st *sessionState, turnID string) *gw.OrchestratorCommand {
+	st.llm.failures = 0
+	if !st.llm.degraded {
+		return nil
+	}
+	st.llm.degraded = false
+	metricLLMDegraded.WithLabelValues("recovered").Inc()
+	log.Printf("[orch] llm recovered sid=%s turn=%s", st.id, turnID)
+	return llmStatusCmd(st.id, false, llmRecovered, turnID, 0)
+}
@@ -0,0 +1,108 @@
+package orchestrator
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+
+	"yuzu/agent/internal/clock"
+	llmpb "yuzu/agent/internal/llm/pb"
+	gw "yuzu/agent/internal/orchestrator/pb"
+)
+
+func TestPlaybookSpeaksCannedLinesUntilRecovery(t *testing.T) {
+	clk := clock.NewFake(time.Unix(1700000000, 0))
+	s := &Server{sess: map[string]*sessionState{}, clock: clk, llmPlaybook: true,
+		llmCanned: parseCanned(defaultLLMCanned), llmApology: defaultLLMApology}
+	s.llmDial = func(context.Context) (*grpc.ClientConn, error) { return nil, errors.New("llm down") }
+	st := s.getOrCreateSession("s1")
+	st.flowState.setFlow(&Flow{Name: "f", Stages: []FlowStage{{ID: "intro", Fallbacks: []CannedReply{
+		{Text: "Let's keep going. What did you enjoy most there?", When: cannedAnswer},
+		{Text: "Tell me about a project you're proud of.", When: cannedAnswer},
+		{Text: "I'll note that question for later."},
+	}}}}, clk.Now())
+	var sent []*gw.OrchestratorCommand
+	send := func(c *gw.OrchestratorCommand) { sent = append(sent, c) }
+
+	// The first failure enters degraded mode and speaks the stage's answer line
+	s.startLLM(context.Background(), "s1", "t1", "I worked at Acme for three years.", "", send)
+	if len(sent) != 2 || !sent[0].GetLlmStatus().GetDegraded() || sent[0].GetLlmStatus().GetReason() != llmFailUnavailable ||
+		sent[1].GetStartTts().GetText() != "Let's keep going. What did you enjoy most there?" {
+		t.Fatalf("first failure: %v", sent)
+	}
+
+	// Later failures rotate lines without repeating the status; a question
+	// with no question-tagged line falls back to the untagged one
+	sent = nil
+	s.startLLM(context.Background(), "s1", "t2", "Mostly backend work.", "", send)
+	s.startLLM(context.Background(), "s1", "t3", "What does the team work on?", "", send)
+	if len(sent) != 2 || sent[0].GetStartTts().GetText() != "Tell me about a project you're proud of." ||
+		sent[1].GetStartTts().GetText() != "I'll note that question for later." {
+		t.Fatalf("later failures: %v", sent)
+	}
+
+	// The LLM's first sentence ends degraded mode
+	sent = nil
+	s.streamLLMResponses(replyStream(), "s1", "t4", send, func() {}, nil)
+	if len(sent) != 2 || sent[0].GetLlmStatus().GetDegraded() || sent[0].GetLlmStatus().GetReason() != llmRecovered ||
+		sent[1].GetStartTts().GetText() != "Tell me more." {
+		t.Fatalf("recovery: %v", sent)
+	}
+	st.mu.Lock()
+	sum := st.summarize("ended", clk.Now())
+	st.mu.Unlock()
+	if !sum.LLMDegraded || sum.CannedCount != 3 {
+		t.Errorf("summary llm_degraded=%v canned_replies=%d", sum.LLMDegraded, sum.CannedCount)
+	}
+}
+
+func TestPlaybookStreamFailures(t *testing.T) {
+	s := &Server{sess: map[string]*sessionState{}, clock: clock.NewFake(time.Unix(1700000000, 0)), llmPlaybook: true}
+	errStream := func() *fakeLLMStream {
+		return &fakeLLMStream{msgs: []*llmpb.ServerMessage{
+			{Msg: &llmpb.ServerMessage_Error{Error: &llmpb.Error{Code: "provider_error", Message: "500"}}},
+		}}
+	}
+	var failed []string
+	onFail := func(reason string) { failed = append(failed, reason) }
+
+	s.streamLLMResponses(errStream(), "s1", "t1", func(*gw.OrchestratorCommand) {}, func() {}, onFail)
+	// An error after a sentence does not fail the reply
+	stream := replyStream()
+	stream.msgs = append(stream.msgs, errStream().msgs...)
+	s.streamLLMResponses(stream, "s1", "t2", func(*gw.OrchestratorCommand) {}, func() {}, onFail)
+	if len(failed) != 1 || failed[0] != llmFailError {
+		t.Fatalf("failed = %v", failed)
+	}
+
+	// With nothing to say, degraded mode is still reported
+	s.getOrCreateSession("s2")
+	var sent []*gw.OrchestratorCommand
+	s.llmFailed(context.Background(), "s2", "t1", "hi", llmFailError, func(c *gw.OrchestratorCommand) { sent = append(sent, c) })
+	if len(sent) != 1 || sent[0].GetLlmStatus() == nil {
+		t.Fatalf("silent: %v", sent)
+	}
+}
+
+func TestParseCanned(t *testing.T) {
+	got := parseCanned(" answer:Go on. | question: Good question. |Note: untagged||")
+	want := []CannedReply{{Text: "Go on.", When: cannedAnswer}, {Text: "Good question.", When: cannedQuestion}, {Text: "Note: untagged"}}
+	if len(got) != len(want) {
+		t.Fatalf("parseCanned = %+v", got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("[%d] = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+	if cannedTag("so what's the team like") != cannedQuestion || cannedTag("I led it.") != cannedAnswer || cannedTag("right?") != cannedQuestion {
+		t.Error("cannedTag")
+	}
+	f := Flow{Name: "f", Stages: []FlowStage{{ID: "a", Fallbacks: []CannedReply{{Text: "Hi", When: "always"}}}}}
+	if err := f.Validate(); err == nil {
+		t.Error("unknown tag should not validate")
+	}
+}
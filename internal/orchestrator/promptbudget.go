@@ -0,0 +1,71 @@
+package orchestrator
+
+import (
+	"log"
+
+	"yuzu/agent/internal/tokens"
+)
+
+// promptbudget.go sizes each reply's prompt before it is sent, using the
+// estimates from internal/tokens, so an over-long request is cut here rather
+// than failing at the provider after a round trip. ORCH_LLM_PROMPT_BUDGET
+// (default 6000 estimated tokens, 0 disables) caps the prompt. Over budget,
+// the reference documents retrieved for the turn are cut first, then the
+// start of the candidate's words, keeping at least minUserTokens of their
+// end. An over-long prompt is the request's own doing, not a provider
+// failure, so a system prompt that leaves no room for those is trimmed
+// rather than rejected: its lowest-ranked layers (session, then flow; see
+// promptlayers.go) are dropped, and an org layer still too long is cut
+// from the end.
+//
+// Requested sizes are observed in orch_llm_prompt_tokens{part} (system,
+// documents, user, total) and outcomes counted in
+// orch_llm_prompt_preflight_total{outcome} (within, shrunk, trimmed).
+
+// The candidate's words are never cut below this
+const minUserTokens = 64
+
+// fitPrompt returns the system and user messages for one reply within the
+// budget: the system prompt composed from layers, with docs appended as
+// far as they fit, and the candidate's words user.
+func (s *Server) fitPrompt(sid string, layers []promptLayer, docs, user string) (system, prompt string) {
+	if docs != "" {
+		docs = "\n\n" + docs
+	}
+	sys := composePrompt(layers)
+	fixed := tokens.Chat(tokens.Message{Role: "system", Content: sys}, tokens.Message{Role: "user"})
+	docT, userT := tokens.Count(docs), tokens.Count(user)
+	metricLLMPromptTokens.WithLabelValues("system").Observe(float64(fixed))
+	metricLLMPromptTokens.WithLabelValues("documents").Observe(float64(docT))
+	metricLLMPromptTokens.WithLabelValues("user").Observe(float64(userT))
+	metricLLMPromptTokens.WithLabelValues("total").Observe(float64(fixed + docT + userT))
+	if s.promptBudget <= 0 || fixed+docT+userT <= s.promptBudget {
+		metricLLMPromptPreflight.WithLabelValues("within").Inc()
+		return sys + docs, user
+	}
+
+	outcome := "shrunk"
+	keepUser := min(userT, minUserTokens)
+	if fixed+keepUser > s.promptBudget {
+		outcome = "trimmed"
+		var dropped []string
+		for len(layers) > 1 && fixed+keepUser > s.promptBudget {
+			dropped = append(dropped, layers[len(layers)-1].Layer)
+			layers = layers[:len(layers)-1]
+			sys = composePrompt(layers)
+			fixed = tokens.Chat(tokens.Message{Role: "system", Content: sys}, tokens.Message{Role: "user"})
+		}
+		if over := fixed + keepUser - s.promptBudget; over > 0 {
+			dropped = append(dropped, "end of "+layers[0].Layer)
+			sys = tokens.Truncate(sys, max(0, tokens.Count(sys)-over))
+			fixed = tokens.Chat(tokens.Message{Role: "system", Content: sys}, tokens.Message{Role: "user"})
+		}
+		log.Printf("[orch] system prompt over budget sid=%s: dropped %v, system now %d tokens (budget %d)", sid, dropped, fixed, s.promptBudget)
+	}
+	keptDocs := tokens.Truncate(docs, max(0, s.promptBudget-fixed-userT))
+	prompt = tokens.TruncateStart(user, s.promptBudget-fixed-tokens.Count(keptDocs))
+	metricLLMPromptPreflight.WithLabelValues(outcome).Inc()
+	log.Printf("[orch] prompt over budget sid=%s: documents %d -> %d, user %d -> %d tokens (system %d, budget %d)",
+		sid, docT, tokens.Count(keptDocs), userT, tokens.Count(prompt), fixed, s.promptBudget)
+	return sys + keptDocs, prompt
+}
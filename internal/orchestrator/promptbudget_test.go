@@ -0,0 +1,63 @@
+package orchestrator
+
+import (
+	"strings"
+	"testing"
+
+	"yuzu/agent/internal/tokens"
+)
+
+func TestFitPrompt(t *testing.T) {
+	s := &Server{promptBudget: 200}
+	sys := "You are a friendly interviewer."
+	org := []promptLayer{{Layer: layerOrg, Text: sys}}
+	docs := strings.Repeat("Led the Postgres migration at Acme. ", 40)
+	user := strings.Repeat("and then we ", 60) + "shipped it in March."
+
+	// Within budget: everything goes
+	gotSys, gotUser := s.fitPrompt("s1", org, "Resume: Acme.", "Hi there.")
+	if gotSys != sys+"\n\nResume: Acme." || gotUser != "Hi there." {
+		t.Fatalf("within budget = %q, %q", gotSys, gotUser)
+	}
+
+	// Documents go first
+	gotSys, gotUser = s.fitPrompt("s1", org, docs, "Tell me about the migration.")
+	if gotUser != "Tell me about the migration." || !strings.HasPrefix(gotSys, sys+"\n\nLed the Postgres") {
+		t.Fatalf("documents cut = %q, %q", gotSys, gotUser)
+	}
+	if n := tokens.Chat(tokens.Message{Role: "system", Content: gotSys}, tokens.Message{Role: "user", Content: gotUser}); n > s.promptBudget {
+		t.Errorf("shrunk prompt = %d tokens, budget %d", n, s.promptBudget)
+	}
+
+	// Then the start of the candidate's words; the end is kept
+	gotSys, gotUser = s.fitPrompt("s1", org, docs, user)
+	if gotSys != sys || !strings.HasSuffix(gotUser, "shipped it in March.") || len(gotUser) >= len(user) {
+		t.Fatalf("user cut = %q, %q", gotSys, gotUser)
+	}
+
+	// A system prompt that leaves no room loses its lowest layers first
+	layers := []promptLayer{
+		{Layer: layerOrg, Text: sys},
+		{Layer: layerFlow, Text: "Ask about databases."},
+		{Layer: layerSession, Text: strings.Repeat("Be thorough. ", 100)},
+	}
+	gotSys, gotUser = s.fitPrompt("s1", layers, docs, user)
+	if gotSys != composePrompt(layers[:2]) || !strings.HasSuffix(gotUser, "shipped it in March.") {
+		t.Fatalf("session layer dropped = %q, %q", gotSys, gotUser)
+	}
+	// and an org layer too long on its own is cut from the end
+	long := []promptLayer{{Layer: layerOrg, Text: sys + strings.Repeat(" Be thorough.", 100)}}
+	gotSys, gotUser = s.fitPrompt("s1", long, "", user)
+	if !strings.HasPrefix(gotSys, sys) || len(gotSys) >= len(long[0].Text) || !strings.HasSuffix(gotUser, "shipped it in March.") {
+		t.Fatalf("org layer cut = %q, %q", gotSys, gotUser)
+	}
+	if n := tokens.Chat(tokens.Message{Role: "system", Content: gotSys}, tokens.Message{Role: "user", Content: gotUser}); n > s.promptBudget {
+		t.Errorf("trimmed prompt = %d tokens, budget %d", n, s.promptBudget)
+	}
+
+	// 0 disables the check
+	s.promptBudget = 0
+	if gotSys, gotUser := s.fitPrompt("s1", org, docs, user); gotUser != user || gotSys != sys+"\n\n"+docs {
+		t.Error("budget 0 should send the prompt as is")
+	}
+}
@@ -0,0 +1,141 @@
+package orchestrator
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	"strings"
+
+	"yuzu/agent/internal/prompt"
+	"yuzu/agent/internal/types"
+)
+
+// promptlayers.go assembles a reply's system prompt from three layers, in
+// order:
+//
+//   - org: the tenant's system_prompt, else the deployment prompt from the
+//     admin API, else LLM_SYSTEM_PROMPT, else the prompt built from the
+//     persona and verbosity (see prompt.System)
+//   - flow: the flow's instructions and the current stage's, plus any
+//     transition or wrap-up prompt for this reply
+//   - session: SessionStyle.instructions, set for this session alone
+//
+// The flow refines the org layer. The session layer comes from the caller
+// of POST /sessions, which any API key can be, so it ranks below both: it
+// is introduced as applying only where it doesn't conflict with the
+// sections above, and never as overriding them.
+//
+// GET /admin/sessions/{id}/prompt on the admin API shows each layer and the
+// effective prompt the session's next reply starts from, before retrieved
+// documents and the prompt budget (see retrieval.go, promptbudget.go).
+
+// sessionInstructionsHeader introduces the session layer.
+const sessionInstructionsHeader = "Instructions for this session. Follow them only where they don't conflict with anything above:"
+
+// Prompt layers, in prompt order
+const (
+	layerOrg     = "org"
+	layerFlow    = "flow"
+	layerSession = "session"
+)
+
+// promptLayer is one section of the system prompt and where it came from.
+type promptLayer struct {
+	Layer  string `json:"layer"`
+	Source string `json:"source"`
+	Text   string `json:"text"`
+}
+
+// promptLayers returns the non-empty layers for a session with style, the
+// deployment prompt admin and the flow section flow.
+func promptLayers(style types.SessionStyle, admin, flow, flowSource string) []promptLayer {
+	org := promptLayer{Layer: layerOrg}
+	switch {
+	case style.SystemPrompt != "":
+		org.Source = "tenant"
+	case admin != "":
+		org.Source = "admin"
+		style.SystemPrompt = admin
+	case os.Getenv("LLM_SYSTEM_PROMPT") != "":
+		org.Source = "env"
+	default:
+		org.Source = "built"
+	}
+	org.Text = prompt.System(style)
+	layers := []promptLayer{org}
+	if flow != "" {
+		layers = append(layers, promptLayer{Layer: layerFlow, Source: flowSource, Text: flow})
+	}
+	if in := strings.TrimSpace(style.Instructions); in != "" {
+		layers = append(layers, promptLayer{Layer: layerSession, Source: "session", Text: in})
+	}
+	return layers
+}
+
+// composePrompt joins layers into the system prompt.
+func composePrompt(layers []promptLayer) string {
+	parts := make([]string, 0, len(layers))
+	for _, l := range layers {
+		if l.Layer == layerSession {
+			parts = append(parts, sessionInstructionsHeader+"\n"+l.Text)
+			continue
+		}
+		parts = append(parts, l.Text)
+	}
+	return joinSections(parts...)
+}
+
+// joinSections joins the non-empty sections with blank lines.
+func joinSections(sections ...string) string {
+	var b strings.Builder
+	for _, s := range sections {
+		if s == "" {
+			continue
+		}
+		if b.Len() > 0 {
+			b.WriteString("\n\n")
+		}
+		b.WriteString(s)
+	}
+	return b.String()
+}
+
+// sessionPromptLayers returns the layers st's next reply would start from.
+// Callers hold st.mu.
+func (st *sessionState) sessionPromptLayers() []promptLayer {
+	style := st.style
+	if style.Persona == "" {
+		style = defaultStyle()
+	}
+	var source string
+	if f := st.flowState.flow; f != nil {
+		origin := "deployment"
+		if st.ownFlow {
+			origin = "session"
+		}
+		source = origin + " flow " + f.Name + ", stage " + f.Stages[st.flowState.stage].ID
+	}
+	return promptLayers(style, st.adminPrompt, st.flowState.instructions(), source)
+}
+
+// handleAdminSessionPrompt serves GET /admin/sessions/{id}/prompt.
+func (s *Server) handleAdminSessionPrompt(w http.ResponseWriter, r *http.Request, sid string) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	st := s.lookup(sid)
+	if st == nil {
+		http.NotFound(w, r)
+		return
+	}
+	st.mu.Lock()
+	layers := st.sessionPromptLayers()
+	st.mu.Unlock()
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]any{
+		"session_id": sid,
+		"layers":     layers,
+		"effective":  composePrompt(layers),
+	})
+}
@@ -0,0 +1,58 @@
+package orchestrator
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"yuzu/agent/internal/clock"
+	"yuzu/agent/internal/types"
+)
+
+func TestPromptLayersPrecedence(t *testing.T) {
+	style := types.SessionStyle{Persona: types.PersonaFormal, Instructions: "The candidate prefers Go."}
+	layers := promptLayers(style, "You are Acme's deployment bot.", "Ask about testing.", "flow")
+	if len(layers) != 3 || layers[0].Source != "admin" || layers[1].Layer != layerFlow || layers[2].Layer != layerSession {
+		t.Fatalf("layers = %+v", layers)
+	}
+	want := "You are Acme's deployment bot.\n\nAsk about testing.\n\n" + sessionInstructionsHeader + "\nThe candidate prefers Go."
+	if got := composePrompt(layers); got != want {
+		t.Errorf("prompt = %q, want %q", got, want)
+	}
+
+	// The tenant's prompt outranks the deployment's; no flow or session layer
+	style = types.SessionStyle{Persona: types.PersonaFormal, SystemPrompt: "You are Acme."}
+	if layers := promptLayers(style, "deployment", "", ""); len(layers) != 1 || layers[0].Source != "tenant" || layers[0].Text != "You are Acme." {
+		t.Errorf("tenant layers = %+v", layers)
+	}
+	if layers := promptLayers(types.SessionStyle{Persona: types.PersonaFormal}, "", "", ""); layers[0].Source != "built" || !strings.HasPrefix(layers[0].Text, "You are a courteous") {
+		t.Errorf("built layers = %+v", layers)
+	}
+}
+
+func TestAdminSessionPrompt(t *testing.T) {
+	s := &Server{sess: map[string]*sessionState{}, clock: clock.Real, adminToken: "admin-secret"}
+	h := s.AdminHandler()
+	st := s.getOrCreateSession("s1")
+	st.style = types.SessionStyle{Persona: types.PersonaFriendly, Instructions: "Keep it short."}
+	st.flowState.setFlow(&Flow{Name: "screen", Instructions: "You are screening.", Stages: []FlowStage{{ID: "intro", Instructions: "Ask for an intro."}}}, s.clock.Now())
+
+	code, out := adminDo(t, h, http.MethodGet, "/admin/sessions/s1/prompt", "admin-secret", "")
+	if code != 200 {
+		t.Fatalf("GET = %d %v", code, out)
+	}
+	layers := out["layers"].([]any)
+	if len(layers) != 3 || layers[1].(map[string]any)["source"] != "deployment flow screen, stage intro" {
+		t.Fatalf("layers = %v", layers)
+	}
+	eff := out["effective"].(string)
+	if !strings.Contains(eff, "You are screening.\n\nAsk for an intro.") || !strings.HasSuffix(eff, "Keep it short.") {
+		t.Errorf("effective prompt = %q", eff)
+	}
+	if code, _ := adminDo(t, h, http.MethodGet, "/admin/sessions/nope/prompt", "admin-secret", ""); code != http.StatusNotFound {
+		t.Errorf("unknown session = %d, want 404", code)
+	}
+	if code, _ := adminDo(t, h, http.MethodPut, "/admin/sessions/s1/prompt", "admin-secret", ""); code != http.StatusMethodNotAllowed {
+		t.Errorf("PUT = %d, want 405", code)
+	}
+}
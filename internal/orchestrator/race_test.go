@@ -0,0 +1,77 @@
+package orchestrator
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+
+	gw "yuzu/agent/internal/orchestrator/pb"
+)
+
+// TestSessionConcurrentAccess drives one session from the gateway stream
+// while LLM-reply, timer and admin goroutines work on it. It asserts little
+// on its own; run it with -race.
+func TestSessionConcurrentAccess(t *testing.T) {
+	s := NewServer()
+	s.halfDuplex = true
+	const sid = "s1"
+	tts := func(typ, utt string) *gw.GatewayEvent {
+		return &gw.GatewayEvent{SessionId: sid, Evt: &gw.GatewayEvent_Tts{Tts: &gw.TTSEvent{Type: typ, UtteranceId: utt, Reason: "completed"}}}
+	}
+	events := []*gw.GatewayEvent{openEvent(sid)}
+	for i := 0; i < 200; i++ {
+		utt := fmt.Sprintf("t1-a%d", i)
+		events = append(events,
+			tts("started", utt),
+			tts("first_audio", utt),
+			&gw.GatewayEvent{SessionId: sid, Evt: &gw.GatewayEvent_Feature{Feature: &gw.Feature{Rms: float32(500 + 100*(i%20))}}},
+			&gw.GatewayEvent{SessionId: sid, Evt: &gw.GatewayEvent_VadStart{VadStart: &gw.VADStart{}}},
+			&gw.GatewayEvent{SessionId: sid, Evt: &gw.GatewayEvent_TranscriptInterim{TranscriptInterim: &gw.TranscriptInterim{Text: "speak"}}},
+			// An intent, so the final is answered without an LLM
+			&gw.GatewayEvent{SessionId: sid, Evt: &gw.GatewayEvent_TranscriptFinal{TranscriptFinal: &gw.TranscriptFinal{Text: "speak up please", WordCount: 3}}},
+			&gw.GatewayEvent{SessionId: sid, Evt: &gw.GatewayEvent_Error{Error: &gw.GatewayError{Code: "stt.timeout"}}},
+			tts("stopped", utt),
+		)
+	}
+	// The session must exist before the other goroutines look it up
+	st := s.getOrCreateSession(sid)
+
+	var mu sync.Mutex
+	var sent int
+	send := func(*gw.OrchestratorCommand) { mu.Lock(); sent++; mu.Unlock() }
+
+	var wg sync.WaitGroup
+	run := func(fn func(i int)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 200; i++ {
+				fn(i)
+			}
+		}()
+	}
+	run(func(int) { _ = s.Session(&scriptStream{ctx: context.Background(), events: events}) })
+	run(func(i int) {
+		// What streamLLMResponses does per sentence
+		s.attachLLM(sid, func() {})
+		if st := s.lookup(sid); st != nil {
+			st.mu.Lock()
+			cmds := s.agentSpeech(st, &gw.StartTTS{Text: "Next question.", TurnId: "t1", UtteranceId: st.nextAgentUtterance("t1")})
+			st.mu.Unlock()
+			for _, c := range cmds {
+				send(c)
+			}
+		}
+		s.detachLLM(sid)
+	})
+	run(func(i int) { s.handleTTSFailed(st, fmt.Sprintf("t1-a%d", i), "provider_error", send) })
+	run(func(int) { s.caption(st, roleAgent, "hello", true, "t1", "t1-a1", send) })
+	run(func(int) { s.applyLive("prompt") })
+	wg.Wait()
+
+	s.closeSession(sid, "participant_left", send)
+	if s.lookup(sid) != nil {
+		t.Fatal("session still open after close")
+	}
+}
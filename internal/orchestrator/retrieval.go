@@ -0,0 +1,78 @@
+package orchestrator
+
+import (
+	"encoding/json"
+	"log"
+
+	"yuzu/agent/internal/retrieval"
+	"yuzu/agent/internal/types"
+)
+
+// retrieval.go grounds replies in the documents uploaded to the session
+// (POST /sessions/{id}/context: a job description, the candidate's resume),
+// which arrive as SessionStyle.context_json. They are split into chunks of
+// ORCH_CONTEXT_CHUNK_CHARS (default 600) once, at SessionOpen. Each LLM
+// request then carries the ORCH_CONTEXT_TOP_K (default 3) chunks that best
+// match the candidate's final and the question it answers, at most
+// ORCH_CONTEXT_MAX_CHARS (default 1800) in all, after the system prompt;
+// when nothing matches, the documents' opening chunks go in instead.
+// ORCH_CONTEXT_TOP_K=0 turns retrieval off. Counted in
+// orch_context_retrievals_total{result}.
+
+// contextConfig is read once at startup.
+type contextConfig struct {
+	chunkChars int
+	topK       int
+	maxChars   int
+}
+
+func contextConfigFromEnv() contextConfig {
+	return contextConfig{
+		chunkChars: envInt("ORCH_CONTEXT_CHUNK_CHARS", 600),
+		topK:       envInt("ORCH_CONTEXT_TOP_K", 3),
+		maxChars:   envInt("ORCH_CONTEXT_MAX_CHARS", 1800),
+	}
+}
+
+// sessionContext indexes the documents a session sent in SessionOpen; nil
+// when there are none or they don't parse.
+func (s *Server) sessionContext(sid, raw string) *retrieval.Index {
+	if raw == "" || s.contextCfg.topK <= 0 {
+		return nil
+	}
+	var docs []types.ContextDoc
+	if err := json.Unmarshal([]byte(raw), &docs); err != nil {
+		log.Printf("[orch] invalid session context sid=%s: %v; replying without it", sid, err)
+		return nil
+	}
+	ix := retrieval.New(docs, s.contextCfg.chunkChars)
+	log.Printf("[orch] session context sid=%s docs=%d chunks=%d", sid, len(docs), ix.Len())
+	return ix
+}
+
+// contextPrompt returns the prompt section for a reply to userText, or ""
+// when the session has no documents. Callers hold st.mu.
+func (s *Server) contextPrompt(st *sessionState, userText string) string {
+	if st.contextIndex.Len() == 0 {
+		return ""
+	}
+	// The candidate's answer often leaves out the topic the agent's question
+	// named ("I used it for two years"), so the query includes that question
+	query := userText
+	for i := len(st.transcript) - 1; i >= 0; i-- {
+		if e := st.transcript[i]; e.Role == roleAgent {
+			query = e.Text + " " + userText
+			break
+		}
+	}
+	chunks, matched := st.contextIndex.Select(query, s.contextCfg.topK, s.contextCfg.maxChars)
+	if len(chunks) == 0 {
+		return ""
+	}
+	if matched {
+		metricContextRetrievals.WithLabelValues("matched").Inc()
+	} else {
+		metricContextRetrievals.WithLabelValues("lead").Inc()
+	}
+	return retrieval.Format(chunks)
+}
@@ -0,0 +1,45 @@
+package orchestrator
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"yuzu/agent/internal/clock"
+	gw "yuzu/agent/internal/orchestrator/pb"
+)
+
+func TestSessionContextRetrieval(t *testing.T) {
+	s := &Server{sess: map[string]*sessionState{}, clock: clock.NewFake(time.Unix(1700000000, 0)),
+		contextCfg: contextConfig{chunkChars: 64, topK: 1, maxChars: 400}}
+	st := s.getOrCreateSession("s1")
+	docs := `[{"name":"jd","kind":"job","text":"Own the Postgres clusters that back every product.\n\nShip Go services behind our public API."},
+		{"name":"resume","text":"Ten years at Acme, most recently as staff engineer.\n\nMigrated billing from MySQL to Postgres in 2021."}]`
+	s.handleSessionOpen(st, "s1", "", &gw.SessionStyle{ContextJson: docs}, &fakeStream{})
+
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	if st.contextIndex.Len() != 4 {
+		t.Fatalf("chunks = %d, want 4", st.contextIndex.Len())
+	}
+	got := s.contextPrompt(st, "I did the billing migration")
+	if !strings.Contains(got, "[resume] Migrated billing") || strings.Contains(got, "Ship Go") {
+		t.Errorf("contextPrompt = %q", got)
+	}
+	// A vague answer is matched through the question it answers
+	st.record(s.clock.Now(), roleAgent, 0, "How would you run Go services in production?")
+	st.record(s.clock.Now(), roleCandidate, 0, "Mostly with containers.")
+	if got := s.contextPrompt(st, "Mostly with containers."); !strings.Contains(got, "[jd (job)] Ship Go services") {
+		t.Errorf("contextPrompt after a question = %q", got)
+	}
+	// Nothing matches: the documents' openings stand in
+	s.contextCfg.topK = 2
+	if got := s.contextPrompt(&sessionState{contextIndex: st.contextIndex}, "hmm"); !strings.Contains(got, "Own the Postgres") || !strings.Contains(got, "Ten years") {
+		t.Errorf("contextPrompt fallback = %q", got)
+	}
+
+	// Sessions without documents, or with a broken upload, get nothing
+	if s.sessionContext("s2", "not json") != nil || s.contextPrompt(&sessionState{}, "postgres") != "" {
+		t.Error("expected no context")
+	}
+}
@@ -6,18 +6,38 @@ import (
 	"os"
 	"strconv"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"google.golang.org/grpc"
 
+	"yuzu/agent/internal/chaos"
+	"yuzu/agent/internal/clock"
 	llmpb "yuzu/agent/internal/llm/pb"
 	gw "yuzu/agent/internal/orchestrator/pb"
+	"yuzu/agent/internal/retrieval"
+	"yuzu/agent/internal/types"
 )
 
-// sessionState holds per-session state.
+// sessionState holds per-session state. mu guards every field but id (see
+// locking.go).
 type sessionState struct {
+	mu    sync.Mutex
 	id    string
-	state string // IDLE, LISTENING, PROCESSING, SPEAKING
+	state turnState // see turnstate.go
+	// TurnState commands not yet sent (see turnstate.go)
+	turnStates []*gw.OrchestratorCommand
+
+	// Orchestrator-issued turn/utterance IDs (see ids.go)
+	idState
+
+	// Response style from SessionOpen (see style.go)
+	style types.SessionStyle
+
+	// User speaking pace from transcript word timing (see pace.go)
+	pace paceState
+	// How the last agent sentence ended (see intonation.go)
+	intonation intonationState
 
 	// VAD state
 	speaking     bool
@@ -28,6 +48,62 @@ type sessionState struct {
 	minRMS       float64
 	guardUntil   time.Time
 	armedAt      time.Time
+	guardMs      uint32 // guard armed on first_audio; 0 uses the current thresholds
+	ownGuard     bool   // guard set by the session preset; kept on threshold reloads
+	ownMinRMS    bool   // likewise for minRMS (see thresholds.go)
+
+	// Barge-in hysteresis: loud frames in the trailing window (see vad.go)
+	speechWin     speechWindow
+	lastFeatureAt time.Time
+
+	// Mic-to-STT stopped while TTS plays (see halfduplex.go)
+	micGated bool
+
+	// Finals held for the next turn while TTS plays (see pipeline.go)
+	pipelineState
+
+	// Gateway applies BeginListening (see listen.go)
+	beginListening bool
+
+	// Targeted StopTTS and their acks (see stoptts.go)
+	stopState
+
+	// ArmBargeIn generations and their acks (see armack.go)
+	armState
+
+	// Barge-in profile and the noise floor it is suggested from (see bargeprofile.go)
+	profileState
+
+	// Re-arm window after playback ends (see tail.go)
+	tail tailState
+
+	// Finals already handled, to drop repeats (see dupturn.go)
+	dupFinals
+
+	// STT outage being waited out, e.g. "auth_failed" (see stterror.go)
+	sttDown string
+
+	// Text-only fallback while TTS keeps failing (see degrade.go)
+	tts ttsHealth
+
+	// Canned replies while the LLM can't answer (see playbook.go)
+	llm llmHealth
+
+	// Caption commands requested for this session (see captions.go)
+	captions bool
+
+	// TokenDelta commands requested for this session (see tokenstream.go)
+	tokenStream bool
+
+	// Finals are echoed back instead of answered (see echo.go)
+	echoTest bool
+
+	// The session opted out of LLM request logging (SessionStyle.no_llm_logging)
+	noLLMLogging bool
+
+	// The session opted out of transcript retention (see consent.go); read
+	// without st.mu by log lines
+	noTranscripts atomic.Bool
 
 	// Agreement tracking
 	lastFeatureStart time.Time
@@ -37,22 +113,203 @@ type sessionState struct {
     llmCancel context.CancelFunc
     llmActive bool
 
+    // LLM requests per minute and in flight (see llmlimit.go)
+    llmLimit llmLimitState
+
     // LLM latency tracking
     lastTranscriptFinal time.Time
     llmFirstSentence    bool
+    turnLatencyPending  bool // reply's first audio not yet seen (see combo.go)
+
+    // StartTTS commands awaiting first audio, and delayed re-sends (see ttsretry.go)
+    ttsPending  []pendingTTS
+    ttsRequeues []*time.Timer
+
+    // Filler played while the LLM is slow (see filler.go)
+    filler fillerState
+
+    // Spoken commands handled without the LLM (see intent.go)
+    intents intentState
+
+    // Finals flagged by moderation (see moderation.go)
+    moderation moderationState
+
+    // Reference documents from SessionOpen, chunked (see retrieval.go)
+    contextIndex *retrieval.Index
+
+    // Runtime prompt and flow from the admin API (see admin.go, flow.go)
+    adminPrompt string
+    flowState
+
+    // Diarized speaker roles and the session transcript (see speakers.go)
+    speakers   speakerMap
+    transcript []transcriptEntry
+
+    // Session lifetime (see close.go)
+    openedAt   time.Time
+    opens      int         // SessionOpens seen; identifies the owning stream
+    closeTimer *time.Timer // pending finalize after the stream dropped
+    send       func(*gw.OrchestratorCommand) // owning stream, for CloseAll
+
+    // Last word from the gateway (see heartbeat.go)
+    liveness
+
+    // Interview time limit (see maxduration.go)
+    interviewClock
 }
 
 // Server implements the GatewayControl gRPC service.
 type Server struct {
 	gw.UnimplementedGatewayControlServer
-	mu        sync.Mutex
+	mu        sync.Mutex // guards sess and closed only; see locking.go
 	sess      map[string]*sessionState
+	closed    map[string]time.Time // recently closed sessions (see close.go)
 	vadSource string // "feature" | "gateway"
+	clock     clock.Clock
+
+	// Barge-in needs bargeInMinSpeech of loud audio within bargeInWindow;
+	// featurePeriod caps how much audio a single feature frame can stand for.
+	// Defaults fit the gateway's feature cadence (100ms idle, 300ms while the
+	// agent speaks) so two loud frames in a row still trigger during TTS.
+	bargeInWindow    time.Duration
+	bargeInMinSpeech time.Duration
+	featurePeriod    time.Duration
+
+	// requireIDs drops transcripts whose echoed utterance ID does not match
+	// the one issued in StartMicToSTT. Events without IDs are still accepted.
+	requireIDs bool
+
+	// TTS failure recovery (see ttsretry.go): sentences are re-sent at most
+	// ttsRequeueMax times, first via ttsFallbackProvider when set.
+	ttsFallbackProvider string
+	ttsRequeueMax       int
+	ttsRequeueDelay     time.Duration
+
+	// Text-only fallback (see degrade.go): ttsDegradeAfter dropped batches
+	// in a row switch a session over, 0 disables; ttsProbeEvery paces
+	// recovery attempts.
+	ttsDegradeAfter int
+	ttsProbeEvery   time.Duration
+
+	// Pace mirroring (see pace.go): paceBaselineWPM is the pace at which the
+	// provider's default rate and pacePauseMs are used unchanged.
+	paceAdapt       bool
+	paceBaselineWPM float64
+	pacePauseMs     int
+
+	// Sentence intonation hints and pauses (see intonation.go)
+	intonation bool
+	pauseAfter map[string]int
+
+	// Gateway authentication on SessionOpen (see auth.go)
+	auth gatewayAuth
+
+	// speakerPolicy picks the candidate among diarized speakers (see speakers.go)
+	speakerPolicy string
+
+	// Filler audio (see filler.go): after fillerAfter without a sentence
+	// the next phrase is played; no phrases means ambient sound. 0 disables.
+	fillerAfter   time.Duration
+	fillerPhrases []string
+
+	// Spoken commands answered without the LLM (see intent.go)
+	intents map[intent]bool
+
+	// Moderation of candidate finals (see moderation.go); moderate replaces
+	// the LLM service's Moderate RPC in tests.
+	moderation moderationConfig
+	moderate   func(ctx context.Context, sessionID, text string) (*llmpb.ModerateResponse, error)
+
+	// Runtime prompt/flow management (see admin.go)
+	adminToken string
+	prompts    history[string]
+	flows      history[*Flow]
+
+	// Reloadable barge-in/VAD thresholds (see thresholds.go)
+	thresholds      history[vadThresholds]
+	thresholdsFile  string
+	thresholdsWatch time.Duration // see WatchThresholds
+
+	// Half-duplex echo gating (see halfduplex.go)
+	halfDuplex        bool
+	halfDuplexPreroll time.Duration
+
+	// pipelineTurns holds finals heard during playback for the next turn (see pipeline.go)
+	pipelineTurns bool
+
+	// postTTSRearm ignores playback tail after TTS stops (see tail.go); 0 disables
+	postTTSRearm time.Duration
+
+	// captionsDefault turns captions on for every session (see captions.go)
+	captionsDefault bool
+
+	// tokenStreamDefault streams tokens to every session (see tokenstream.go)
+	tokenStreamDefault bool
+
+	// Command batching for gateways that support it (see batch.go)
+	commandBatch    time.Duration
+	commandBatchMax int
+
+	// Per-session LLM request limits (see llmlimit.go)
+	llmLimits llmLimits
+
+	// Session context retrieval (see retrieval.go)
+	contextCfg contextConfig
+
+	// What to do when the LLM's circuit is open (see llmfallback.go)
+	llmFallbackDeployment string
+	llmApology            string
+
+	// Lines spoken while the LLM can't answer (see playbook.go)
+	llmPlaybook bool
+	llmCanned   []CannedReply
+
+	// Provider labels for the turn-latency histogram (see combo.go)
+	combo providerCombo
+
+	// Session end (see close.go): closeGrace is how long a dropped stream
+	// has to reconnect; summaries are written to stateDir when set.
+	closeGrace time.Duration
+	stateDir   string
+
+	// Interview time limit (see maxduration.go); maxDuration 0 disables
+	maxDuration      time.Duration
+	wrapUpBefore     time.Duration
+	wrapUpPromptText string
+	closingStatement string
+
+	// Silent-session detection (see heartbeat.go); 0 disables each
+	sessionSilentAfter time.Duration
+	sessionDeadAfter   time.Duration
+
+	// Unacked StopTTS re-sends (see stoptts.go); stopRetry 0 disables
+	stopRetry   time.Duration
+	stopRetries int
+	// How a barge-in stop ends the agent's speech (see stoptts.go)
+	bargeInBoundary string
+	bargeInFade     time.Duration
+
+	// Unacked ArmBargeIn re-sends (see armack.go); armRetry 0 disables
+	armRetry   time.Duration
+	armRetries int
+
+	// Repeated finals are dropped within this window (see dupturn.go); 0 disables
+	dupFinalWindow time.Duration
+
+	// Estimated prompt tokens allowed per reply (see promptbudget.go); 0 disables
+	promptBudget int
+
+	// Barge-in profile suggestion (see bargeprofile.go); 0 disables
+	profileWindow time.Duration
+
+	// Faults injected into gateway sends; nil unless CHAOS_ENABLED
+	sendChaos *chaos.Injector
 
 	// Persistent LLM client
 	llmMu     sync.RWMutex
 	llmConn   *grpc.ClientConn
 	llmClient llmpb.LLMClient
+	llmDial   func(ctx context.Context) (*grpc.ClientConn, error) // nil dials LLM_ADDR
 }
 
 // NewServer creates a new orchestrator server.
@@ -61,21 +318,133 @@ func NewServer() *Server {
 	if src == "" {
 		src = "feature"
 	}
-	return &Server{
-		sess:      make(map[string]*sessionState),
-		vadSource: src,
+	s := &Server{
+		sess:       make(map[string]*sessionState),
+		vadSource:  src,
+		clock:      clock.Real,
+		requireIDs: envBool("ORCH_REQUIRE_ECHOED_IDS", true),
+
+		bargeInWindow:    time.Duration(envInt("ORCH_BARGE_IN_WINDOW_MS", 400)) * time.Millisecond,
+		bargeInMinSpeech: time.Duration(envInt("ORCH_BARGE_IN_MIN_SPEECH_MS", 240)) * time.Millisecond,
+		featurePeriod:    time.Duration(envInt("ORCH_FEATURE_PERIOD_MS", 300)) * time.Millisecond,
+
+		ttsFallbackProvider: envString("ORCH_TTS_FALLBACK_PROVIDER", "service"),
+		ttsRequeueMax:       envInt("ORCH_TTS_REQUEUE_MAX", 2),
+		ttsRequeueDelay:     time.Duration(envInt("ORCH_TTS_REQUEUE_DELAY_MS", 500)) * time.Millisecond,
+		ttsDegradeAfter:     envInt("ORCH_TTS_DEGRADE_AFTER", 2),
+		ttsProbeEvery:       time.Duration(envInt("ORCH_TTS_PROBE_MS", 30000)) * time.Millisecond,
+
+		paceAdapt:       envBool("ORCH_PACE_ADAPT", true),
+		paceBaselineWPM: float64(envInt("ORCH_PACE_BASELINE_WPM", 160)),
+		pacePauseMs:     envInt("ORCH_PACE_PAUSE_MS", 250),
+
+		intonation: envBool("ORCH_TTS_INTONATION", true),
+		pauseAfter: pauseAfterFromEnv(),
+
+		auth: gatewayAuthFromEnv(),
+
+		speakerPolicy: envString("ORCH_SPEAKER_POLICY", "first"),
+
+		fillerAfter:   time.Duration(envInt("ORCH_FILLER_AFTER_MS", 0)) * time.Millisecond,
+		fillerPhrases: parseFillerPhrases(envString("ORCH_FILLER_PHRASES", "Hmm, let me think.|Good question, one moment.|Let me think about that.")),
+
+		intents: parseIntents(envString("ORCH_INTENTS", defaultIntents)),
+
+		moderation: moderationFromEnv(),
+
+		closeGrace: time.Duration(envInt("ORCH_SESSION_GRACE_MS", 60000)) * time.Millisecond,
+		stateDir:   os.Getenv("ORCH_STATE_DIR"),
+
+		maxDuration:      time.Duration(envInt("ORCH_MAX_SESSION_MS", 0)) * time.Millisecond,
+		wrapUpBefore:     time.Duration(envInt("ORCH_WRAPUP_MS", 120000)) * time.Millisecond,
+		wrapUpPromptText: envString("ORCH_WRAPUP_PROMPT", defaultWrapUpPrompt),
+		closingStatement: envString("ORCH_CLOSING_STATEMENT", defaultClosingStatement),
+
+		sessionSilentAfter: time.Duration(envInt("ORCH_SESSION_SILENT_MS", 15000)) * time.Millisecond,
+		sessionDeadAfter:   time.Duration(envInt("ORCH_SESSION_DEAD_MS", 90000)) * time.Millisecond,
+
+		stopRetry:   time.Duration(envInt("ORCH_STOP_TTS_RETRY_MS", 300)) * time.Millisecond,
+		stopRetries: envInt("ORCH_STOP_TTS_RETRIES", 2),
+
+		bargeInBoundary: stopBoundary(envString("ORCH_BARGE_IN_BOUNDARY", stopBoundaryWord)),
+		bargeInFade:     time.Duration(envInt("ORCH_BARGE_IN_FADE_MS", 30)) * time.Millisecond,
+
+		armRetry:   time.Duration(envInt("ORCH_ARM_ACK_TIMEOUT_MS", 500)) * time.Millisecond,
+		armRetries: envInt("ORCH_ARM_ACK_RETRIES", 2),
+
+		promptBudget: envInt("ORCH_LLM_PROMPT_BUDGET", 6000),
+
+		dupFinalWindow: time.Duration(envInt("ORCH_DUP_FINAL_WINDOW_MS", 10000)) * time.Millisecond,
+
+		profileWindow: time.Duration(envInt("ORCH_PROFILE_SUGGEST_MS", 8000)) * time.Millisecond,
+
+		sendChaos: chaos.FromEnv(chaos.TargetOrchSend),
+
+		adminToken: os.Getenv("ORCH_ADMIN_TOKEN"),
+
+		halfDuplex:        envBool("ORCH_HALF_DUPLEX", false),
+		halfDuplexPreroll: time.Duration(envInt("ORCH_HALF_DUPLEX_PREROLL_MS", 300)) * time.Millisecond,
+
+		pipelineTurns: envBool("ORCH_PIPELINE_TURNS", false),
+
+		postTTSRearm: time.Duration(envInt("ORCH_POST_TTS_REARM_MS", 0)) * time.Millisecond,
+
+		captionsDefault:    envBool("ORCH_CAPTIONS", false),
+		tokenStreamDefault: envBool("ORCH_TOKEN_STREAM", false),
+
+		commandBatch:    time.Duration(envInt("ORCH_COMMAND_BATCH_MS", 0)) * time.Millisecond,
+		commandBatchMax: envInt("ORCH_COMMAND_BATCH_MAX", 32),
+
+		llmLimits: llmLimitsFromEnv(),
+
+		contextCfg: contextConfigFromEnv(),
+
+		llmFallbackDeployment: envString("ORCH_LLM_FALLBACK_DEPLOYMENT", ""),
+		llmApology:            envString("ORCH_LLM_APOLOGY", defaultLLMApology),
+		llmPlaybook:           envBool("ORCH_LLM_PLAYBOOK", true),
+		llmCanned:             parseCanned(envString("ORCH_LLM_CANNED", defaultLLMCanned)),
+
+		combo: comboFromEnv(),
+
+		thresholdsFile:  envString("ORCH_THRESHOLDS_FILE", ""),
+		thresholdsWatch: time.Duration(envInt("ORCH_THRESHOLDS_WATCH_MS", 0)) * time.Millisecond,
+	}
+	if f, err := loadFlowFile(os.Getenv("ORCH_FLOW_FILE")); err != nil {
+		log.Printf("[orch] %v; starting without a flow", err)
+	} else if f != nil {
+		s.flows.set(f, "ORCH_FLOW_FILE", s.clock.Now())
 	}
+	if s.thresholdsFile != "" {
+		if t, _, err := loadThresholdsFile(s.thresholdsFile); err != nil {
+			log.Printf("[orch] %v; using env thresholds", err)
+		} else {
+			rev := s.thresholds.set(t, "ORCH_THRESHOLDS_FILE", s.clock.Now())
+			observeThresholds(t, rev.Version)
+		}
+	}
+	return s
 }
 
 // Session handles the bidirectional gRPC stream with the gateway.
-func (s *Server) Session(stream gw.GatewayControl_SessionServer) error {
+func (s *Server) Session(gs gw.GatewayControl_SessionServer) error {
+	// LLM goroutines and timers send on this stream too
+	stream := &serialStream{GatewayControl_SessionServer: gs, chaos: s.sendChaos}
+	defer stream.end()
 	ctx := stream.Context()
 	send := func(cmd *gw.OrchestratorCommand) { _ = stream.Send(cmd) }
+	// Session the stream's token was validated for; empty until SessionOpen
+	authedSID := ""
+	// Session opened on this stream and not yet closed; finalized after a
+	// grace period if the stream ends without SessionClose
+	openSID, openGen := "", 0
 
 	for {
 		ev, err := stream.Recv()
 		if err != nil {
 			log.Printf("[orch] session stream.Recv error: %v", err)
+			if openSID != "" {
+				s.scheduleClose(openSID, openGen)
+			}
 			return err
 		}
 
@@ -84,35 +453,97 @@ func (s *Server) Session(stream gw.GatewayControl_SessionServer) error {
 			sid = "unknown"
 		}
 
-		st := s.getOrCreateSession(sid)
+		if s.auth.required {
+			if _, open := ev.Evt.(*gw.GatewayEvent_SessionOpen); open {
+				if err := s.auth.check(ctx, sid); err != nil {
+					return err
+				}
+				authedSID = sid
+			} else if sid != authedSID {
+				metricGatewayAuth.WithLabelValues("unbound_event").Inc()
+				log.Printf("[orch] dropping %T for sid=%s: stream authenticated for %q", ev.Evt, sid, authedSID)
+				continue
+			}
+		}
+
+		if x, ok := ev.Evt.(*gw.GatewayEvent_SessionClose); ok {
+			s.closeSession(sid, x.SessionClose.GetReason(), send)
+			if sid == openSID {
+				openSID = ""
+			}
+			continue
+		}
+
+		_, opening := ev.Evt.(*gw.GatewayEvent_SessionOpen)
+		st := s.sessionFor(sid, opening)
+		if st == nil {
+			metricEventsAfterClose.Inc()
+			continue
+		}
+		s.sawEvent(st, ev.GetHeartbeat())
 
 		switch x := ev.Evt.(type) {
 		case *gw.GatewayEvent_SessionOpen:
-			s.handleSessionOpen(st, sid, x.SessionOpen.GetRoomUrl(), stream)
+			st.mu.Lock()
+			st.beginListening = hasCapability(x.SessionOpen.GetCapabilities(), capBeginListening)
+			st.stopAcks = hasCapability(x.SessionOpen.GetCapabilities(), capStopTTSAck)
+			st.armAcks = hasCapability(x.SessionOpen.GetCapabilities(), capArmAck)
+			st.mu.Unlock()
+			s.handleSessionOpen(st, sid, x.SessionOpen.GetRoomUrl(), x.SessionOpen.GetStyle(), stream)
+			if s.commandBatch > 0 && hasCapability(x.SessionOpen.GetCapabilities(), capCommandBatch) {
+				stream.enableBatching(s.commandBatch, s.commandBatchMax)
+				log.Printf("[orch] session_open id=%s batching commands window=%s max=%d", sid, s.commandBatch, s.commandBatchMax)
+			}
+			st.mu.Lock()
+			openSID, openGen = sid, st.opens
+			st.send = send
+			st.mu.Unlock()
+			// Including the IDLE of the first open, queued before there was a stream
+			s.flushTurnStates(st)
 
 		case *gw.GatewayEvent_Feature:
 			rms := float64(x.Feature.GetRms())
-			s.processFeature(st, rms, time.Now(), sid, stream)
+			if s.processFeature(st, rms, s.clock.Now(), sid, stream) {
+				s.commitPipelined(ctx, st, "barge_in", send)
+			}
 
 		case *gw.GatewayEvent_VadStart:
-			s.processGatewayVAD(st, time.Now(), sid, stream)
+			if s.processGatewayVAD(st, s.clock.Now(), sid, stream) {
+				s.commitPipelined(ctx, st, "barge_in", send)
+			}
 
 		case *gw.GatewayEvent_VadEnd:
 			// No-op for now
 
 		case *gw.GatewayEvent_Tts:
-			s.handleTTSEvent(st, x.Tts.GetType(), x.Tts.GetFirstAudioMs())
+			s.handleTTSEvent(st, x.Tts.GetType(), x.Tts.GetFirstAudioMs(), x.Tts.GetUtteranceId(), x.Tts.GetReason(), send)
+			if x.Tts.GetType() == "stopped" {
+				s.commitPipelined(ctx, st, "tts_end", send)
+			}
 
 		case *gw.GatewayEvent_TranscriptInterim:
-			// Could log or update UI
+			s.sttRecovered(st)
+			st.mu.Lock()
+			m := st.checkUserUtterance(x.TranscriptInterim.GetUtteranceId())
+			st.mu.Unlock()
+			recordIDEcho(sid, "transcript_interim", x.TranscriptInterim.GetUtteranceId(), m)
+			s.captionInterim(st, x.TranscriptInterim, send)
 
 		case *gw.GatewayEvent_TranscriptFinal:
-			log.Printf("[orch] Received TranscriptFinal event sid=%s text=%q", sid, x.TranscriptFinal.GetText())
-			s.handleTranscriptFinal(ctx, st, sid, x.TranscriptFinal.GetText(), send)
+			log.Printf("[orch] Received TranscriptFinal event sid=%s utterance=%s speaker=%d source=%s text=%q", sid, x.TranscriptFinal.GetUtteranceId(), x.TranscriptFinal.GetSpeaker(), x.TranscriptFinal.GetSource(), logText(st, x.TranscriptFinal.GetText()))
+			s.sttRecovered(st)
+			s.routeTranscriptFinal(ctx, st, sid, x.TranscriptFinal, send)
 
 		case *gw.GatewayEvent_Error:
-			log.Printf("[orch] gateway error sid=%s code=%s msg=%s",
-				sid, x.Error.GetCode(), x.Error.GetMessage())
+			s.handleGatewayError(st, x.Error.GetCode(), x.Error.GetMessage(), send)
+
+		case *gw.GatewayEvent_Heartbeat:
+			// Recorded by sawEvent
+
+		case *gw.GatewayEvent_StopAck:
+			s.handleStopAck(st, x.StopAck)
+		case *gw.GatewayEvent_ArmAck:
+			s.handleArmAck(st, x.ArmAck)
 
 		default:
 			// Ignore unknown events for forward compatibility
@@ -121,35 +552,68 @@ func (s *Server) Session(stream gw.GatewayControl_SessionServer) error {
 }
 
 // handleSessionOpen initializes a new session.
-func (s *Server) handleSessionOpen(st *sessionState, sid string, roomURL string, stream gw.GatewayControl_SessionServer) {
+func (s *Server) handleSessionOpen(st *sessionState, sid string, roomURL string, style *gw.SessionStyle, stream gw.GatewayControl_SessionServer) {
 	log.Printf("[orch] session_open id=%s room=%s", sid, roomURL)
 
-	if st.state == "" {
-		s.setState(st, "IDLE")
+	resolved := resolveStyle(sid, style)
+	// Configure barge-in thresholds but don't arm yet - wait for TTS first_audio
+	// A session preset may override the thresholds
+	t := s.currentThresholds()
+	guardMs := t.GuardMs
+	if v := style.GetBargeInGuardMs(); v > 0 {
+		guardMs = v
+	}
+	minRms := t.MinRMS
+	if v := style.GetBargeInMinRms(); v > 0 {
+		minRms = v
 	}
 
-	// Configure barge-in thresholds but don't arm yet - wait for TTS first_audio
-	guardMs := uint32(envInt("LOCAL_STOP_GUARD_MS", 1000))
-	minRms := uint32(envInt("LOCAL_STOP_MIN_RMS", 1200))
-	// Store minRMS in session state so it's available when first_audio arms barge-in
+	st.mu.Lock()
+	st.style = resolved
+	st.captions = s.captionsDefault || style.GetCaptions()
+	st.tokenStream = s.tokenStreamDefault || style.GetTokenStream()
+	st.echoTest = style.GetEchoTest()
+	st.noLLMLogging = style.GetNoLlmLogging()
+	st.noTranscripts.Store(style.GetNoTranscriptRetention())
+	if st.cancelScheduledClose() {
+		log.Printf("[orch] session_open id=%s reconnected within grace", sid)
+	}
+	if st.openedAt.IsZero() {
+		st.openedAt = s.clock.Now()
+		s.applyAdminDefaults(st)
+		if f := sessionFlow(sid, style.GetFlowJson()); f != nil {
+			st.flowState.setFlow(f, st.openedAt)
+			st.ownFlow = true
+		}
+		st.contextIndex = s.sessionContext(sid, style.GetContextJson())
+		s.armMaxDuration(st, style)
+	}
+	st.opens++
+	if st.state == stateNone {
+		s.setState(st, stateIdle, triggerOpen)
+	}
+	// Store minRMS and the guard in session state so they're available when first_audio arms barge-in
 	st.minRMS = float64(minRms)
+	st.guardMs = guardMs
+	st.ownGuard, st.ownMinRMS = style.GetBargeInGuardMs() > 0, style.GetBargeInMinRms() > 0
+	st.openProfile(style)
 	// Set guard to distant future - will be properly armed on first_audio
-	st.guardUntil = time.Now().Add(24 * time.Hour)
-	log.Printf("[orch] session_open configured minRMS=%.0f, barge-in will arm on first_audio", st.minRMS)
-
-	// Notify gateway of barge-in config
-	s.sendCmd(stream, &gw.OrchestratorCommand{
-		SessionId: sid,
-		Cmd: &gw.OrchestratorCommand_ArmBargeIn{
-			ArmBargeIn: &gw.ArmBargeIn{GuardMs: guardMs, MinRms: minRms},
-		},
-	})
-
-	// Enable mic to STT
-	s.sendCmd(stream, &gw.OrchestratorCommand{
-		SessionId: sid,
-		Cmd:       &gw.OrchestratorCommand_StartMicToStt{StartMicToStt: &gw.StartMicToSTT{}},
-	})
+	st.guardUntil = s.clock.Now().Add(24 * time.Hour)
+	// Enable mic to STT under a freshly issued turn
+	turnID, uttID := st.openTurn()
+	cmds := st.listenCmds(nil, s.newArm(st, guardMs, minRms), &gw.StartMicToSTT{TurnId: turnID, UtteranceId: uttID})
+	st.mu.Unlock()
+	temp := "default"
+	if t := resolved.Temperature; t != nil {
+		temp = strconv.FormatFloat(*t, 'f', 2, 64)
+	}
+	log.Printf("[orch] session_open style persona=%s verbosity=%s temperature=%s max_tokens=%d", resolved.Persona, resolved.Verbosity, temp, resolved.MaxTokens)
+	log.Printf("[orch] session_open configured minRMS=%d, barge-in will arm on first_audio", minRms)
+
+	// Notify gateway of barge-in config and start listening
+	for _, c := range cmds {
+		s.sendCmd(stream, c)
+	}
 }
 
 // getOrCreateSession returns existing session or creates a new one.
@@ -159,27 +623,18 @@ func (s *Server) getOrCreateSession(sid string) *sessionState {
 
 	st := s.sess[sid]
 	if st == nil {
+		t := s.currentThresholds()
 		st = &sessionState{
 			id:       sid,
-			minStart: 2,
-			hangover: 20,
-			minRMS:   1200.0,
+			minStart: t.MinStart,
+			hangover: t.Hangover,
+			minRMS:   float64(t.MinRMS),
 		}
 		s.sess[sid] = st
 	}
 	return st
 }
 
-// setState transitions session state and records metric.
-func (s *Server) setState(st *sessionState, to string) {
-	from := st.state
-	if from == to {
-		return
-	}
-	metricStateTransitions.WithLabelValues(from, to).Inc()
-	st.state = to
-}
-
 // sendCmd sends a command to the gateway, logging on failure.
 func (s *Server) sendCmd(stream gw.GatewayControl_SessionServer, cmd *gw.OrchestratorCommand) bool {
 	if err := stream.Send(cmd); err != nil {
@@ -189,6 +644,32 @@ func (s *Server) sendCmd(stream gw.GatewayControl_SessionServer, cmd *gw.Orchest
 	return true
 }
 
+// envBool reads an environment variable as bool, returning def if not set or invalid.
+func envBool(key string, def bool) bool {
+	v := os.Getenv(key)
+	if v == "" {
+		return def
+	}
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		return def
+	}
+	return b
+}
+
+// envString reads an environment variable, returning def if not set.
+// Set it to "none" to get an empty value.
+func envString(key, def string) string {
+	v := os.Getenv(key)
+	switch v {
+	case "":
+		return def
+	case "none":
+		return ""
+	}
+	return v
+}
+
 // envInt reads an environment variable as int, returning def if not set or invalid.
 func envInt(key string, def int) int {
 	v := os.Getenv(key)
@@ -6,21 +6,27 @@ import "context"
 
 // attachLLM stores cancel and flags on the session state safely.
 func (s *Server) attachLLM(sessionID string, cancel context.CancelFunc) {
-    s.mu.Lock()
-    if st := s.sess[sessionID]; st != nil {
+    if st := s.lookup(sessionID); st != nil {
+        st.mu.Lock()
         st.llmCancel = cancel
         st.llmActive = true
+        st.llmLimit.streams++
+        st.mu.Unlock()
     }
-    s.mu.Unlock()
 }
 
 // detachLLM clears LLM flags after stream finishes.
 func (s *Server) detachLLM(sessionID string) {
-    s.mu.Lock()
-    if st := s.sess[sessionID]; st != nil {
+    if st := s.lookup(sessionID); st != nil {
+        st.mu.Lock()
         st.llmActive = false
         st.llmCancel = nil
+        if st.llmLimit.streams > 0 {
+            st.llmLimit.streams--
+        }
+        // A reply that ended without a sentence needs no filler
+        st.clearFiller()
+        st.mu.Unlock()
     }
-    s.mu.Unlock()
 }
 
@@ -0,0 +1,114 @@
+package orchestrator
+
+import (
+	"context"
+	"log"
+	"strconv"
+	"time"
+
+	gw "yuzu/agent/internal/orchestrator/pb"
+)
+
+// speakers.go routes diarized transcripts in panel interviews. With Deepgram
+// diarization on, each final carries the speaker of most of its words. One
+// speaker is the candidate and only their finals start LLM turns; the other
+// panelists are recorded in the session transcript but never answered.
+// Without diarization (speaker 0) every final is the candidate's.
+
+const (
+	roleCandidate   = "candidate"
+	roleInterviewer = "interviewer"
+	roleAgent       = "agent"
+
+	maxTranscriptEntries = 200
+)
+
+// speakerMap is embedded in sessionState.
+type speakerMap struct {
+	candidate int         // 1-based speaker index; 0 until identified
+	words     map[int]int // words heard per speaker, for the dominant policy
+}
+
+// assign records a final from speaker and returns its role. policy is
+// "first" (the first diarized speaker is the candidate), "dominant" (the
+// speaker with the most words so far) or a fixed 1-based speaker index.
+func (m *speakerMap) assign(policy string, speaker, words int) string {
+	if speaker == 0 {
+		return roleCandidate
+	}
+	if m.words == nil {
+		m.words = map[int]int{}
+	}
+	m.words[speaker] += max(words, 1)
+	switch policy {
+	case "dominant":
+		// Ties keep the current candidate so the role doesn't flap
+		if m.candidate == 0 || m.words[speaker] > m.words[m.candidate] {
+			m.candidate = speaker
+		}
+	case "", "first":
+		if m.candidate == 0 {
+			m.candidate = speaker
+		}
+	default:
+		if n, err := strconv.Atoi(policy); err == nil && n > 0 {
+			m.candidate = n
+		} else if m.candidate == 0 {
+			m.candidate = speaker
+		}
+	}
+	if speaker == m.candidate {
+		return roleCandidate
+	}
+	return roleInterviewer
+}
+
+// transcriptEntry is one line of the session transcript.
+type transcriptEntry struct {
+	At      time.Time `json:"at"`
+	Role    string    `json:"role"` // candidate | interviewer | agent
+	Speaker int       `json:"speaker,omitempty"`
+	Text    string    `json:"text"`
+}
+
+// record appends to the session transcript, keeping the newest entries.
+// Callers hold st.mu.
+func (st *sessionState) record(at time.Time, role string, speaker int, text string) {
+	st.transcript = append(st.transcript, transcriptEntry{At: at, Role: role, Speaker: speaker, Text: text})
+	if n := len(st.transcript) - maxTranscriptEntries; n > 0 {
+		st.transcript = append(st.transcript[:0:0], st.transcript[n:]...)
+	}
+}
+
+// routeTranscriptFinal sends the candidate's finals on to the turn pipeline
+// and records everyone else's.
+func (s *Server) routeTranscriptFinal(ctx context.Context, st *sessionState, sid string, tf *gw.TranscriptFinal, send func(*gw.OrchestratorCommand)) {
+	st.mu.Lock()
+	if s.repeatFinal(st, tf, s.clock.Now()) {
+		st.mu.Unlock()
+		return
+	}
+	tail := s.dropTailFinal(st, s.clock.Now())
+	st.mu.Unlock()
+	if tail {
+		log.Printf("[orch] dropping final as post-TTS playback tail sid=%s utterance=%s text=%q", sid, tf.GetUtteranceId(), logText(st, tf.GetText()))
+		return
+	}
+	speaker := int(tf.GetSpeaker())
+	st.mu.Lock()
+	role := st.speakers.assign(s.speakerPolicy, speaker, int(tf.GetWordCount()))
+	if role == roleCandidate {
+		st.pace.observe(int(tf.GetWordCount()), time.Duration(tf.GetSpeechMs())*time.Millisecond)
+	} else {
+		st.record(s.clock.Now(), role, speaker, tf.GetText())
+	}
+	st.mu.Unlock()
+	metricDiarizedFinals.WithLabelValues(role).Inc()
+	s.caption(st, role, tf.GetText(), true, tf.GetTurnId(), tf.GetUtteranceId(), send)
+
+	if role != roleCandidate {
+		log.Printf("[orch] recording %s final sid=%s speaker=%d text=%q (not routed to LLM)", role, sid, speaker, logText(st, tf.GetText()))
+		return
+	}
+	s.handleTranscriptFinal(ctx, st, sid, tf.GetUtteranceId(), tf.GetText(), send)
+}
@@ -0,0 +1,64 @@
+package orchestrator
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"google.golang.org/grpc"
+
+	"yuzu/agent/internal/clock"
+	gw "yuzu/agent/internal/orchestrator/pb"
+)
+
+func TestSpeakerPolicies(t *testing.T) {
+	for _, c := range []struct {
+		policy string
+		finals [][2]int // speaker, words
+		want   []string
+	}{
+		{"first", [][2]int{{2, 5}, {1, 20}, {2, 3}}, []string{roleCandidate, roleInterviewer, roleCandidate}},
+		{"dominant", [][2]int{{2, 5}, {1, 20}, {2, 3}}, []string{roleCandidate, roleCandidate, roleInterviewer}},
+		{"1", [][2]int{{2, 5}, {1, 4}}, []string{roleInterviewer, roleCandidate}},
+		{"first", [][2]int{{0, 5}, {0, 5}}, []string{roleCandidate, roleCandidate}}, // diarization off
+	} {
+		var m speakerMap
+		for i, f := range c.finals {
+			if got := m.assign(c.policy, f[0], f[1]); got != c.want[i] {
+				t.Errorf("%s: final %d from speaker %d = %s, want %s", c.policy, i, f[0], got, c.want[i])
+			}
+		}
+	}
+}
+
+func TestInterviewerFinalsAreRecordedNotAnswered(t *testing.T) {
+	s := &Server{sess: map[string]*sessionState{}, clock: clock.Real, speakerPolicy: "first",
+		llmDial: func(context.Context) (*grpc.ClientConn, error) { return nil, errors.New("no llm in test") }}
+	st := &sessionState{id: "s1", state: stateListening}
+	s.sess["s1"] = st
+	st.openTurn()
+
+	var cmds []*gw.OrchestratorCommand
+	send := func(c *gw.OrchestratorCommand) { cmds = append(cmds, c) }
+
+	s.routeTranscriptFinal(context.Background(), st, "s1", &gw.TranscriptFinal{UtteranceId: "t1-u", Text: "Tell us about yourself.", Speaker: 2}, send)
+	s.routeTranscriptFinal(context.Background(), st, "s1", &gw.TranscriptFinal{UtteranceId: "t1-u", Text: "Please go ahead.", Speaker: 1}, send)
+	if len(cmds) != 1 || cmds[0].GetStartMicToStt() == nil {
+		t.Fatalf("commands = %v, want one StartMicToSTT for the candidate's final", cmds)
+	}
+
+	cmds = nil
+	s.routeTranscriptFinal(context.Background(), st, "s1", &gw.TranscriptFinal{UtteranceId: "t2-u", Text: "Thanks, next question.", Speaker: 1}, send)
+	if len(cmds) != 0 {
+		t.Fatalf("interviewer final produced commands %v", cmds)
+	}
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	var roles []string
+	for _, e := range st.transcript {
+		roles = append(roles, e.Role)
+	}
+	if len(roles) != 3 || roles[0] != roleCandidate || roles[1] != roleInterviewer || roles[2] != roleInterviewer {
+		t.Errorf("transcript roles = %v", roles)
+	}
+}
@@ -0,0 +1,121 @@
+package orchestrator
+
+import (
+	"log"
+	"strings"
+	"time"
+
+	gw "yuzu/agent/internal/orchestrator/pb"
+)
+
+// stoptts.go makes StopTTS targeted and idempotent. Every StopTTS names the
+// agent utterance it means to cut, the one the gateway last reported
+// started (or the filler being superseded), and carries the session's next
+// stop generation. The gateway applies a generation once, and only while
+// that utterance is playing, so a stop that arrives late or twice can't cut
+// the sentence that started in the meantime.
+//
+// Gateways that list "stop_tts_ack" in SessionOpen.capabilities answer with
+// StopTTSAck saying what they stopped. A StopTTS without an ack after
+// ORCH_STOP_TTS_RETRY_MS (default 300, 0 disables) is re-sent unchanged, up
+// to ORCH_STOP_TTS_RETRIES times (default 2); a newer stop replaces the
+// pending one. Acks are counted in orch_stop_tts_acks_total{result} and
+// re-sends in orch_stop_tts_resends_total{outcome}.
+//
+// A barge-in stop also says how to end the speech, so the candidate doesn't
+// hear a click or half a word: ORCH_BARGE_IN_BOUNDARY "word" (default) lets
+// the gateway play on to the next gap between words, "immediate" stops at
+// the next frame, and ORCH_BARGE_IN_FADE_MS (default 30, 0 cuts dead) fades
+// the last of it out. Other stops are immediate.
+
+const capStopTTSAck = "stop_tts_ack"
+
+const (
+	stopBoundaryWord      = "word"
+	stopBoundaryImmediate = "immediate"
+)
+
+// stopBoundary validates an ORCH_BARGE_IN_BOUNDARY value.
+func stopBoundary(v string) string {
+	switch v = strings.ToLower(strings.TrimSpace(v)); v {
+	case stopBoundaryWord, stopBoundaryImmediate:
+		return v
+	}
+	log.Printf("[orch] unknown stop boundary %q, stopping immediately", v)
+	return stopBoundaryImmediate
+}
+
+// stopState is embedded in sessionState.
+type stopState struct {
+	playing  string // agent utterance last reported started; "" once it ended
+	stopGen  uint64
+	stopAcks bool // gateway sends StopTTSAck
+	unacked  *pendingStop
+}
+
+// pendingStop is a StopTTS awaiting its ack.
+type pendingStop struct {
+	stop    *gw.StopTTS
+	resends int
+	timer   *time.Timer
+}
+
+// newStop issues the next StopTTS for target and, when the gateway acks
+// stops, arms its re-send. Callers hold st.mu.
+func (s *Server) newStop(st *sessionState, reason, target string) *gw.StopTTS {
+	st.stopGen++
+	stop := &gw.StopTTS{Reason: reason, UtteranceId: target, Generation: st.stopGen}
+	st.clearUnacked()
+	if st.stopAcks && s.stopRetry > 0 {
+		p := &pendingStop{stop: stop}
+		p.timer = time.AfterFunc(s.stopRetry, func() { s.resendStop(st, p) })
+		st.unacked = p
+	}
+	return stop
+}
+
+// clearUnacked forgets the pending stop. Callers hold st.mu.
+func (st *sessionState) clearUnacked() {
+	if st.unacked != nil {
+		st.unacked.timer.Stop()
+		st.unacked = nil
+	}
+}
+
+// resendStop sends p again if it is still unacknowledged.
+func (s *Server) resendStop(st *sessionState, p *pendingStop) {
+	st.mu.Lock()
+	if st.unacked != p || st.send == nil {
+		st.mu.Unlock()
+		return
+	}
+	if p.resends >= s.stopRetries {
+		st.unacked = nil
+		st.mu.Unlock()
+		metricStopTTSResends.WithLabelValues("gave_up").Inc()
+		log.Printf("[orch] StopTTS never acked sid=%s gen=%d utterance=%s", st.id, p.stop.GetGeneration(), p.stop.GetUtteranceId())
+		return
+	}
+	p.resends++
+	p.timer = time.AfterFunc(s.stopRetry, func() { s.resendStop(st, p) })
+	send := st.send
+	st.mu.Unlock()
+
+	metricStopTTSResends.WithLabelValues("resent").Inc()
+	log.Printf("[orch] re-sending StopTTS sid=%s gen=%d utterance=%s attempt=%d", st.id, p.stop.GetGeneration(), p.stop.GetUtteranceId(), p.resends+1)
+	send(&gw.OrchestratorCommand{SessionId: st.id, Cmd: &gw.OrchestratorCommand_StopTts{StopTts: p.stop}})
+}
+
+// handleStopAck records what the gateway did with a StopTTS.
+func (s *Server) handleStopAck(st *sessionState, ack *gw.StopTTSAck) {
+	st.mu.Lock()
+	if st.unacked != nil && st.unacked.stop.GetGeneration() == ack.GetGeneration() {
+		st.clearUnacked()
+	}
+	if ack.GetResult() == "stopped" && ack.GetStoppedUtteranceId() == st.playing {
+		st.playing = ""
+	}
+	st.mu.Unlock()
+	metricStopTTSAcks.WithLabelValues(ack.GetResult()).Inc()
+	log.Printf("[orch] StopTTS ack sid=%s gen=%d target=%s result=%s stopped=%s", st.id, ack.GetGeneration(), ack.GetUtteranceId(), ack.GetResult(), ack.GetStoppedUtteranceId())
+}
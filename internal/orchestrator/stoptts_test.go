@@ -0,0 +1,88 @@
+package orchestrator
+
+import (
+	"testing"
+	"time"
+
+	"yuzu/agent/internal/clock"
+	gw "yuzu/agent/internal/orchestrator/pb"
+)
+
+func TestBargeInStopTargetsPlayingUtterance(t *testing.T) {
+	s := &Server{sess: map[string]*sessionState{}, clock: clock.NewFake(time.Unix(1700000000, 0))}
+	st := s.getOrCreateSession("s1")
+	send := func(*gw.OrchestratorCommand) {}
+
+	s.handleTTSEvent(st, "started", 0, "t1-a1", "", send)
+	st.mu.Lock()
+	first := s.bargeInCmds(st)[0].GetStopTts()
+	second := s.bargeInCmds(st)[0].GetStopTts()
+	st.mu.Unlock()
+	if first.GetUtteranceId() != "t1-a1" || first.GetGeneration() != 1 || second.GetGeneration() != 2 {
+		t.Fatalf("stops = %v, %v; want t1-a1 at generations 1 and 2", first, second)
+	}
+
+	// Once it has stopped, a barge-in targets nothing
+	s.handleTTSEvent(st, "stopped", 0, "t1-a1", "barge_in", send)
+	st.mu.Lock()
+	stop := s.bargeInCmds(st)[0].GetStopTts()
+	st.mu.Unlock()
+	if stop.GetUtteranceId() != "" || stop.GetGeneration() != 3 {
+		t.Fatalf("stop after playback ended = %v", stop)
+	}
+}
+
+func TestStopTTSResentUntilAcked(t *testing.T) {
+	s := &Server{sess: map[string]*sessionState{}, clock: clock.Real, stopRetry: 10 * time.Millisecond, stopRetries: 2}
+	st := s.getOrCreateSession("s1")
+	cmds := make(chan *gw.OrchestratorCommand, 4)
+	st.send = func(c *gw.OrchestratorCommand) { cmds <- c }
+	st.stopAcks = true
+
+	st.mu.Lock()
+	stop := s.newStop(st, "barge_in", "t1-a1")
+	st.mu.Unlock()
+	select {
+	case c := <-cmds:
+		if c.GetStopTts() != stop {
+			t.Fatalf("re-sent %v, want %v", c, stop)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("unacked StopTTS not re-sent")
+	}
+
+	s.handleStopAck(st, &gw.StopTTSAck{Generation: stop.GetGeneration(), UtteranceId: "t1-a1", Result: "stopped", StoppedUtteranceId: "t1-a1"})
+	st.mu.Lock()
+	pending := st.unacked
+	st.mu.Unlock()
+	if pending != nil {
+		t.Fatal("ack left the stop pending")
+	}
+	select {
+	case c := <-cmds:
+		t.Fatalf("re-sent %v after the ack", c)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestBargeInStopEndsAtWordWithFade(t *testing.T) {
+	s := &Server{sess: map[string]*sessionState{}, clock: clock.NewFake(time.Unix(1700000000, 0)),
+		bargeInBoundary: stopBoundaryWord, bargeInFade: 30 * time.Millisecond}
+	st := s.getOrCreateSession("s1")
+	st.mu.Lock()
+	stop := s.bargeInCmds(st)[0].GetStopTts()
+	filler := s.newStop(st, reasonFillerSuperseded, "f1")
+	st.mu.Unlock()
+	if stop.GetBoundary() != "word" || stop.GetFadeMs() != 30 {
+		t.Fatalf("barge-in stop = %v, want word boundary with 30ms fade", stop)
+	}
+	if filler.GetBoundary() != "" || filler.GetFadeMs() != 0 {
+		t.Fatalf("filler stop = %v, want immediate", filler)
+	}
+
+	for in, want := range map[string]string{" Word ": "word", "immediate": "immediate", "sentence": "immediate"} {
+		if got := stopBoundary(in); got != want {
+			t.Errorf("stopBoundary(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
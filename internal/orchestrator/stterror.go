@@ -0,0 +1,75 @@
+package orchestrator
+
+import (
+	"log"
+	"strings"
+
+	gw "yuzu/agent/internal/orchestrator/pb"
+)
+
+// stterror.go reacts to STT failures the gateway relays as GatewayError
+// "stt.<code>" (the taxonomy of ErrorCode in proto/stt.proto). The sidecar
+// already reconnects, re-reads its key after auth_failed (and stops dialing
+// while the key stays rejected; see internal/stt/authfail.go) and backs off
+// after rate_limited; the orchestrator's part differs by class:
+//
+//	socket_closed, timeout   the open utterance died with the stream, so
+//	                         StartMicToSTT re-opens it on the new stream
+//	auth_failed, rate_limited the candidate is unheard until the provider
+//	                         recovers; logged once, not retried here
+//	anything else            counted and logged
+
+// STT error codes relayed by the gateway
+const (
+	sttErrAuth         = "auth_failed"
+	sttErrRateLimited  = "rate_limited"
+	sttErrTimeout      = "timeout"
+	sttErrSocketClosed = "socket_closed"
+)
+
+// handleGatewayError handles a GatewayError. Callers must not hold st.mu.
+func (s *Server) handleGatewayError(st *sessionState, code, msg string, send func(*gw.OrchestratorCommand)) {
+	stt, ok := strings.CutPrefix(code, "stt.")
+	if !ok {
+		log.Printf("[orch] gateway error sid=%s code=%s msg=%s", st.id, code, msg)
+		return
+	}
+	action, repeat := "log", false
+	st.mu.Lock()
+	switch stt {
+	case sttErrSocketClosed, sttErrTimeout:
+		// A half-duplex gate re-opens the mic itself when playback stops
+		if !st.micGated {
+			action = "restart"
+		}
+	case sttErrAuth, sttErrRateLimited:
+		action, repeat = "wait", st.sttDown == stt
+		st.sttDown = stt
+	}
+	listen := st.listenCmds(nil, nil, &gw.StartMicToSTT{TurnId: st.turnID, UtteranceId: st.userUtteranceID})
+	st.mu.Unlock()
+
+	metricSTTErrors.WithLabelValues(stt, action).Inc()
+	switch {
+	case action == "restart":
+		log.Printf("[orch] STT %s sid=%s; re-opening the candidate's utterance: %s", stt, st.id, msg)
+		for _, c := range listen {
+			send(c)
+		}
+	case action == "wait" && !repeat:
+		log.Printf("[orch] STT unavailable (%s) sid=%s; the candidate is not transcribed until it recovers: %s", stt, st.id, msg)
+	case action == "log":
+		log.Printf("[orch] STT error %s sid=%s msg=%s", stt, st.id, msg)
+	}
+}
+
+// sttRecovered clears an auth/rate-limit outage once transcripts flow again.
+func (s *Server) sttRecovered(st *sessionState) {
+	st.mu.Lock()
+	down := st.sttDown
+	st.sttDown = ""
+	st.mu.Unlock()
+	if down != "" {
+		log.Printf("[orch] STT recovered from %s sid=%s", down, st.id)
+	}
+}
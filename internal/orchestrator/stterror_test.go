@@ -0,0 +1,43 @@
+package orchestrator
+
+import (
+	"testing"
+
+	"yuzu/agent/internal/clock"
+	gw "yuzu/agent/internal/orchestrator/pb"
+)
+
+func TestGatewaySTTErrorReactions(t *testing.T) {
+	s := &Server{sess: map[string]*sessionState{}, clock: clock.Real}
+	st := &sessionState{id: "s1"}
+	s.sess["s1"] = st
+	st.openTurn()
+	var cmds []*gw.OrchestratorCommand
+	send := func(c *gw.OrchestratorCommand) { cmds = append(cmds, c) }
+
+	// A dropped stream re-opens the current utterance
+	s.handleGatewayError(st, "stt.socket_closed", "EOF", send)
+	if len(cmds) != 1 || cmds[0].GetStartMicToStt().GetUtteranceId() != "t1-u" {
+		t.Fatalf("socket_closed sent %v, want StartMicToSTT for t1-u", cmds)
+	}
+	// ...unless half-duplex has the mic gated during playback
+	st.micGated = true
+	s.handleGatewayError(st, "stt.timeout", "NET-0001", send)
+	st.micGated = false
+	if len(cmds) != 1 {
+		t.Errorf("timeout while gated sent %v", cmds[1:])
+	}
+
+	// Auth and rate-limit failures are waited out, not retried
+	cmds = nil
+	s.handleGatewayError(st, "stt.auth_failed", "401", send)
+	s.handleGatewayError(st, "stt.bad_audio", "DATA-0000", send)
+	s.handleGatewayError(st, "gateway_crash", "", send)
+	if len(cmds) != 0 || st.sttDown != "auth_failed" {
+		t.Fatalf("sent %v, sttDown=%q", cmds, st.sttDown)
+	}
+	s.sttRecovered(st)
+	if st.sttDown != "" {
+		t.Error("transcript did not clear the outage")
+	}
+}
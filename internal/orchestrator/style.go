@@ -0,0 +1,57 @@
+package orchestrator
+
+import (
+	"log"
+	"os"
+	"strconv"
+
+	gw "yuzu/agent/internal/orchestrator/pb"
+	"yuzu/agent/internal/prompt"
+	"yuzu/agent/internal/types"
+)
+
+// style.go resolves the per-session response style (persona, verbosity,
+// sampling) for the LLM system prompt and StartRequest.
+
+// defaultStyle is the orchestrator-side fallback, from the same env vars the
+// API server uses for its defaults.
+func defaultStyle() types.SessionStyle {
+	st := types.SessionStyle{
+		Persona:   os.Getenv("LLM_PERSONA"),
+		Verbosity: os.Getenv("LLM_VERBOSITY"),
+		MaxTokens: envInt("LLM_MAX_TOKENS", 0),
+	}
+	if v, err := strconv.ParseFloat(os.Getenv("LLM_TEMPERATURE"), 64); err == nil {
+		st.Temperature = &v
+	}
+	return st.Merge(types.SessionStyle{Persona: types.PersonaFriendly, Verbosity: types.VerbosityNormal})
+}
+
+// resolveStyle merges a SessionOpen style over defaults, discarding invalid
+// input rather than failing the session.
+func resolveStyle(sid string, in *gw.SessionStyle) types.SessionStyle {
+	def := defaultStyle()
+	if in == nil {
+		return def
+	}
+	st := types.SessionStyle{
+		Persona:      in.GetPersona(),
+		Verbosity:    in.GetVerbosity(),
+		MaxTokens:    int(in.GetMaxTokens()),
+		SystemPrompt: in.GetSystemPrompt(),
+		Instructions: in.GetInstructions(),
+	}
+	if in.Temperature != nil {
+		t := in.GetTemperature()
+		st.Temperature = &t
+	}
+	st = st.Merge(def)
+	if err := st.Validate(); err != nil {
+		log.Printf("[orch] invalid session style sid=%s: %v; using defaults", sid, err)
+		return def
+	}
+	return st
+}
+
+// systemPrompt builds the system prompt for a style.
+func systemPrompt(st types.SessionStyle) string { return prompt.System(st) }
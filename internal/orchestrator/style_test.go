@@ -0,0 +1,42 @@
+package orchestrator
+
+import (
+	"strings"
+	"testing"
+
+	"google.golang.org/protobuf/proto"
+
+	gw "yuzu/agent/internal/orchestrator/pb"
+	"yuzu/agent/internal/prompt"
+)
+
+func TestResolveStyle(t *testing.T) {
+	t.Setenv("LLM_SYSTEM_PROMPT", "")
+	t.Setenv("LLM_PERSONA", "")
+
+	def := resolveStyle("s", nil)
+	want := "You are a friendly voice assistant. Respond in 1-2 short sentences. " + prompt.SpeechFormatting
+	if got := systemPrompt(def); got != want {
+		t.Errorf("default prompt changed:\n got %q\nwant %q", got, want)
+	}
+
+	st := resolveStyle("s", &gw.SessionStyle{Persona: "technical-interviewer", Verbosity: "brief", MaxTokens: 80})
+	if st.MaxTokens != 80 || !strings.HasPrefix(systemPrompt(st), "You are a technical interviewer.") {
+		t.Errorf("unexpected style %+v prompt %q", st, systemPrompt(st))
+	}
+
+	if bad := resolveStyle("s", &gw.SessionStyle{Persona: "pirate"}); bad != def {
+		t.Errorf("invalid style should fall back to defaults, got %+v", bad)
+	}
+}
+
+func TestResolveStyleKeepsExplicitZeroTemperature(t *testing.T) {
+	t.Setenv("LLM_TEMPERATURE", "0.7")
+
+	if st := resolveStyle("s", &gw.SessionStyle{Temperature: proto.Float64(0)}); st.Temperature == nil || *st.Temperature != 0 {
+		t.Errorf("explicit 0 temperature = %v, want 0", st.Temperature)
+	}
+	if st := resolveStyle("s", &gw.SessionStyle{}); st.Temperature == nil || *st.Temperature != 0.7 {
+		t.Errorf("unset temperature = %v, want the 0.7 default", st.Temperature)
+	}
+}
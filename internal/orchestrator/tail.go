@@ -0,0 +1,70 @@
+package orchestrator
+
+import (
+	"log"
+	"time"
+)
+
+// tail.go covers the end of the agent's playback. When TTS stops on its own,
+// the last words can still be coming out of the candidate's speakers (and
+// the room) for a few hundred ms, which is enough for VAD to fire and STT to
+// produce a final of the agent's own question. For ORCH_POST_TTS_REARM_MS
+// after a natural stop, speech onsets do not count as barge-in and a final
+// that arrives inside the window, or whose speech began inside it, is
+// dropped instead of opening a new turn. This is separate from the guard
+// window (LOCAL_STOP_GUARD_MS), which protects the start of playback. An
+// interrupted stop arms nothing: the candidate is already talking.
+
+// tailState is embedded in sessionState.
+type tailState struct {
+	rearmAt time.Time // end of the post-TTS window; zero when none
+	onset   bool      // speech began inside the window
+}
+
+// armTail starts the window after a TTS stop.
+func (s *Server) armTail(st *sessionState, reason string, now time.Time) {
+	st.tail = tailState{}
+	if s.postTTSRearm <= 0 || reason == "interrupted" {
+		return
+	}
+	st.tail.rearmAt = now.Add(s.postTTSRearm)
+}
+
+// inTail reports whether now falls in the window. A speech onset inside it
+// (onset true) marks the utterance as echo; one after it is a new turn.
+func (s *Server) inTail(st *sessionState, onset bool, now time.Time) bool {
+	if st.tail.rearmAt.IsZero() {
+		return false
+	}
+	if !now.Before(st.tail.rearmAt) {
+		if onset {
+			st.tail = tailState{}
+		}
+		return false
+	}
+	if onset && !st.tail.onset {
+		st.tail.onset = true
+		metricPostTTSTail.WithLabelValues("onset_blocked").Inc()
+		log.Printf("[orch] speech onset %dms before post-TTS re-arm, treated as playback tail sid=%s", st.tail.rearmAt.Sub(now).Milliseconds(), st.id)
+	}
+	return true
+}
+
+// dropTailFinal reports whether a final is playback tail and consumes the
+// onset mark, so only the echoed utterance is dropped.
+func (s *Server) dropTailFinal(st *sessionState, now time.Time) bool {
+	if st.tail.rearmAt.IsZero() {
+		return false
+	}
+	early := now.Before(st.tail.rearmAt)
+	if !early && !st.tail.onset {
+		st.tail = tailState{}
+		return false
+	}
+	st.tail.onset = false
+	if !early {
+		st.tail = tailState{}
+	}
+	metricPostTTSTail.WithLabelValues("final_dropped").Inc()
+	return true
+}
@@ -0,0 +1,64 @@
+package orchestrator
+
+import (
+	"testing"
+	"time"
+
+	"yuzu/agent/internal/clock"
+	gw "yuzu/agent/internal/orchestrator/pb"
+)
+
+func TestPostTTSTailSuppressesEcho(t *testing.T) {
+	fc := clock.NewFake(time.Unix(1700000000, 0))
+	s := &Server{sess: map[string]*sessionState{}, clock: fc, vadSource: "gateway", postTTSRearm: 400 * time.Millisecond}
+	st := &sessionState{id: "s1", minRMS: 500}
+	s.sess["s1"] = st
+	send := func(*gw.OrchestratorCommand) {}
+
+	// Echo starts inside the window: no barge-in, and its final is dropped
+	// even though STT finalizes it after the window closed
+	s.handleTTSEvent(st, "stopped", 0, "", "completed", send)
+	fc.Advance(150 * time.Millisecond)
+	if s.processGatewayVAD(st, fc.Now(), "s1", nil) {
+		t.Fatal("vad_start in the tail triggered barge-in")
+	}
+	fc.Advance(600 * time.Millisecond)
+	if !s.dropTailFinal(st, fc.Now()) {
+		t.Fatal("final of speech that began in the tail was accepted")
+	}
+	if s.dropTailFinal(st, fc.Now()) {
+		t.Error("a second final after the window was dropped")
+	}
+
+	// A final arriving inside the window is dropped; speech after it counts
+	s.handleTTSEvent(st, "stopped", 0, "", "completed", send)
+	fc.Advance(100 * time.Millisecond)
+	if !s.dropTailFinal(st, fc.Now()) {
+		t.Error("final 100ms after stop was accepted")
+	}
+	fc.Advance(400 * time.Millisecond)
+	if s.inTail(st, true, fc.Now()) || s.dropTailFinal(st, fc.Now()) {
+		t.Error("speech after the window was treated as tail")
+	}
+
+	// A quiet feature inside the window is not an onset
+	s.handleTTSEvent(st, "stopped", 0, "", "completed", send)
+	s.processFeature(st, 100, fc.Now(), "s1", nil)
+	fc.Advance(time.Second)
+	if s.dropTailFinal(st, fc.Now()) {
+		t.Error("quiet tail marked the next utterance as echo")
+	}
+
+	// An interrupted stop means the candidate is talking; nothing is armed
+	s.handleTTSEvent(st, "stopped", 0, "", "interrupted", send)
+	if s.inTail(st, true, fc.Now()) || s.dropTailFinal(st, fc.Now()) {
+		t.Error("interrupted stop armed the tail")
+	}
+
+	// Off when the delay is 0
+	s.postTTSRearm = 0
+	s.handleTTSEvent(st, "stopped", 0, "", "completed", send)
+	if s.dropTailFinal(st, fc.Now()) {
+		t.Error("tail armed with ORCH_POST_TTS_REARM_MS=0")
+	}
+}
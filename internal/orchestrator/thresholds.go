@@ -0,0 +1,202 @@
+package orchestrator
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"yuzu/agent/internal/errdefs"
+	gw "yuzu/agent/internal/orchestrator/pb"
+)
+
+// thresholds.go lets the barge-in and VAD thresholds change without a
+// restart. The defaults come from LOCAL_STOP_GUARD_MS, LOCAL_STOP_MIN_RMS
+// and the built-in frame counts; ORCH_THRESHOLDS_FILE may override any of
+// them with a JSON object:
+//
+//	{"guard_ms": 800, "min_rms": 1500, "hangover": 20, "min_start": 2, "apply_live": true}
+//
+// The file is re-read on SIGHUP, and every ORCH_THRESHOLDS_WATCH_MS
+// (default 0, off) when its modification time changes. Each change that
+// passes validation becomes a new version, visible at GET /admin/thresholds,
+// and new sessions start with it. With apply_live the open sessions switch
+// too, except for values their preset set, and the gateway gets a fresh
+// ArmBargeIn. A file that fails to load leaves the current values in force.
+// The values in force and their version are exported as
+// orch_vad_threshold{name} and orch_thresholds_version, so a change shows
+// up next to the barge-in metrics it affects.
+
+// vadThresholds are the tunable barge-in and VAD values.
+type vadThresholds struct {
+	GuardMs  uint32 `json:"guard_ms"`  // barge-in guard after first_audio
+	MinRMS   uint32 `json:"min_rms"`   // speech energy floor
+	Hangover int    `json:"hangover"`  // quiet frames that end speech
+	MinStart int    `json:"min_start"` // loud frames that start speech
+}
+
+func thresholdsFromEnv() vadThresholds {
+	return vadThresholds{
+		GuardMs:  uint32(envInt("LOCAL_STOP_GUARD_MS", 1000)),
+		MinRMS:   uint32(envInt("LOCAL_STOP_MIN_RMS", 1200)),
+		Hangover: 20,
+		MinStart: 2,
+	}
+}
+
+func (t vadThresholds) validate() error {
+	switch {
+	case t.GuardMs > 60000:
+		return fmt.Errorf("guard_ms %d exceeds 60000", t.GuardMs)
+	case t.MinRMS == 0 || t.MinRMS > 32767:
+		return fmt.Errorf("min_rms %d outside 1..32767", t.MinRMS)
+	case t.Hangover < 1 || t.Hangover > 500:
+		return fmt.Errorf("hangover %d outside 1..500", t.Hangover)
+	case t.MinStart < 1 || t.MinStart > 100:
+		return fmt.Errorf("min_start %d outside 1..100", t.MinStart)
+	}
+	return nil
+}
+
+// loadThresholdsFile reads path over the env defaults. Fields the file
+// leaves out keep their default.
+func loadThresholdsFile(path string) (t vadThresholds, applyLive bool, err error) {
+	t = thresholdsFromEnv()
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return t, false, &errdefs.ConfigError{Key: "ORCH_THRESHOLDS_FILE", Msg: err.Error()}
+	}
+	var f struct {
+		vadThresholds
+		ApplyLive bool `json:"apply_live"`
+	}
+	f.vadThresholds = t
+	if err := json.Unmarshal(b, &f); err != nil {
+		return t, false, &errdefs.ConfigError{Key: "ORCH_THRESHOLDS_FILE", Msg: "invalid JSON: " + err.Error()}
+	}
+	if err := f.vadThresholds.validate(); err != nil {
+		return t, false, &errdefs.ConfigError{Key: "ORCH_THRESHOLDS_FILE", Msg: err.Error()}
+	}
+	return f.vadThresholds, f.ApplyLive, nil
+}
+
+// currentThresholds returns the thresholds in force; before any version is
+// installed that is the env defaults.
+func (s *Server) currentThresholds() vadThresholds {
+	if t, v := s.thresholds.current(); v > 0 {
+		return t
+	}
+	return thresholdsFromEnv()
+}
+
+// ReloadThresholds re-reads ORCH_THRESHOLDS_FILE. source names what
+// triggered the reload ("SIGHUP", "watch") in the version history. It is a
+// no-op when the file is unset or unchanged.
+func (s *Server) ReloadThresholds(source string) error {
+	if s.thresholdsFile == "" {
+		return nil
+	}
+	t, live, err := loadThresholdsFile(s.thresholdsFile)
+	if err != nil {
+		metricThresholdReloads.WithLabelValues("error").Inc()
+		log.Printf("[orch] thresholds reload (%s) failed, keeping v%d: %v", source, s.thresholdsVersion(), err)
+		return err
+	}
+	// Compared and installed under the history's lock, so a SIGHUP and the
+	// watcher can't both install the same change
+	var old vadThresholds
+	rev, changed := s.thresholds.setUnless(t, source, s.clock.Now(), func(cur vadThresholds, version int) bool {
+		if version == 0 {
+			cur = thresholdsFromEnv()
+		}
+		old = cur
+		return cur == t
+	})
+	if !changed {
+		metricThresholdReloads.WithLabelValues("unchanged").Inc()
+		return nil
+	}
+	observeThresholds(t, rev.Version)
+	n := 0
+	if live {
+		n = s.applyThresholdsLive(t)
+	}
+	metricThresholdReloads.WithLabelValues("applied").Inc()
+	log.Printf("[orch] AUDIT thresholds v%d from %s: %+v -> %+v apply_live=%t live_sessions=%d", rev.Version, source, old, t, live, n)
+	return nil
+}
+
+// observeThresholds exports the thresholds in force as metrics.
+func observeThresholds(t vadThresholds, version int) {
+	metricThresholdsVersion.Set(float64(version))
+	metricVADThreshold.WithLabelValues("guard_ms").Set(float64(t.GuardMs))
+	metricVADThreshold.WithLabelValues("min_rms").Set(float64(t.MinRMS))
+	metricVADThreshold.WithLabelValues("hangover").Set(float64(t.Hangover))
+	metricVADThreshold.WithLabelValues("min_start").Set(float64(t.MinStart))
+}
+
+func (s *Server) thresholdsVersion() int {
+	_, v := s.thresholds.current()
+	return v
+}
+
+// applyThresholdsLive moves the open sessions to t and re-sends their
+// barge-in settings to the gateway. It returns how many sessions changed.
+func (s *Server) applyThresholdsLive(t vadThresholds) int {
+	s.mu.Lock()
+	open := make([]*sessionState, 0, len(s.sess))
+	for _, st := range s.sess {
+		open = append(open, st)
+	}
+	s.mu.Unlock()
+	for _, st := range open {
+		st.mu.Lock()
+		st.minStart = t.MinStart
+		if !st.ownHangover {
+			st.hangover = t.Hangover
+		}
+		if !st.ownGuard {
+			st.guardMs = t.GuardMs
+		}
+		if !st.ownMinRMS {
+			st.minRMS = float64(t.MinRMS)
+		}
+		arm := s.newArm(st, st.guardMs, uint32(st.minRMS))
+		send := st.send
+		st.mu.Unlock()
+		if send != nil {
+			send(&gw.OrchestratorCommand{SessionId: st.id, Cmd: &gw.OrchestratorCommand_ArmBargeIn{ArmBargeIn: arm}})
+		}
+	}
+	return len(open)
+}
+
+// WatchThresholds reloads the file every ORCH_THRESHOLDS_WATCH_MS when its
+// modification time changed, until ctx is done. It returns at once when
+// the file or the interval is unset.
+func (s *Server) WatchThresholds(ctx context.Context) {
+	if s.thresholdsFile == "" || s.thresholdsWatch <= 0 {
+		return
+	}
+	var last time.Time
+	if fi, err := os.Stat(s.thresholdsFile); err == nil {
+		last = fi.ModTime()
+	}
+	t := s.clock.NewTicker(s.thresholdsWatch)
+	defer t.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C():
+		}
+		fi, err := os.Stat(s.thresholdsFile)
+		if err != nil || fi.ModTime().Equal(last) {
+			continue
+		}
+		last = fi.ModTime()
+		_ = s.ReloadThresholds("watch")
+	}
+}
@@ -0,0 +1,128 @@
+package orchestrator
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	"yuzu/agent/internal/clock"
+	gw "yuzu/agent/internal/orchestrator/pb"
+)
+
+func TestThresholdReload(t *testing.T) {
+	t.Setenv("LOCAL_STOP_GUARD_MS", "")
+	t.Setenv("LOCAL_STOP_MIN_RMS", "")
+	path := filepath.Join(t.TempDir(), "thresholds.json")
+	write := func(body string) {
+		if err := os.WriteFile(path, []byte(body), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	s := &Server{sess: map[string]*sessionState{}, clock: clock.NewFake(time.Unix(1700000000, 0)), thresholdsFile: path}
+
+	var sent []*gw.OrchestratorCommand
+	send := func(c *gw.OrchestratorCommand) { sent = append(sent, c) }
+	plain := s.getOrCreateSession("plain")
+	s.handleSessionOpen(plain, "plain", "", &gw.SessionStyle{}, &fakeStream{})
+	preset := s.getOrCreateSession("preset")
+	s.handleSessionOpen(preset, "preset", "", &gw.SessionStyle{BargeInMinRms: 900}, &fakeStream{})
+	plain.send, preset.send = send, send
+
+	// New sessions only
+	write(`{"min_rms": 1500, "hangover": 30}`)
+	if err := s.ReloadThresholds("SIGHUP"); err != nil {
+		t.Fatal(err)
+	}
+	if plain.minRMS != 1200 || len(sent) != 0 {
+		t.Fatalf("without apply_live: live minRMS %v, sent %v", plain.minRMS, sent)
+	}
+	if st := s.getOrCreateSession("new"); st.minRMS != 1500 || st.hangover != 30 || st.minStart != 2 {
+		t.Errorf("new session thresholds = %v/%d/%d", st.minRMS, st.hangover, st.minStart)
+	}
+
+	// Invalid files keep the current version
+	write(`{"min_rms": 0}`)
+	if err := s.ReloadThresholds("SIGHUP"); err == nil || s.currentThresholds().MinRMS != 1500 {
+		t.Fatalf("invalid reload: err %v, thresholds %+v", err, s.currentThresholds())
+	}
+
+	write(`{"min_rms": 1800, "guard_ms": 500, "apply_live": true}`)
+	if err := s.ReloadThresholds("watch"); err != nil {
+		t.Fatal(err)
+	}
+	if plain.minRMS != 1800 || plain.guardMs != 500 || plain.hangover != 20 {
+		t.Errorf("plain session = %v/%d/%d", plain.minRMS, plain.guardMs, plain.hangover)
+	}
+	// The preset's minRMS survives; its guard follows the reload
+	if preset.minRMS != 900 || preset.guardMs != 500 {
+		t.Errorf("preset session = %v/%d", preset.minRMS, preset.guardMs)
+	}
+	armed := 0
+	for _, c := range sent {
+		if c.GetArmBargeIn().GetGuardMs() == 500 {
+			armed++
+		}
+	}
+	// "new" was never opened on a stream
+	if armed != 2 {
+		t.Errorf("ArmBargeIn re-sent %d times, want 2: %v", armed, sent)
+	}
+	if revs := s.thresholds.list(); len(revs) != 2 || revs[1].Note != "watch" {
+		t.Errorf("versions = %+v", revs)
+	}
+}
+
+func TestThresholdReloadsInstallOnce(t *testing.T) {
+	t.Setenv("LOCAL_STOP_GUARD_MS", "")
+	t.Setenv("LOCAL_STOP_MIN_RMS", "")
+	path := filepath.Join(t.TempDir(), "thresholds.json")
+	if err := os.WriteFile(path, []byte(`{"min_rms": 1700}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	s := &Server{sess: map[string]*sessionState{}, clock: clock.NewFake(time.Unix(1700000000, 0)), thresholdsFile: path}
+
+	// A SIGHUP racing the watcher installs the change once
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_ = s.ReloadThresholds("SIGHUP")
+		}()
+	}
+	wg.Wait()
+	if revs := s.thresholds.list(); len(revs) != 1 {
+		t.Fatalf("versions = %+v, want one", revs)
+	}
+	if v := testutil.ToFloat64(metricThresholdsVersion); v != 1 {
+		t.Errorf("orch_thresholds_version = %v", v)
+	}
+	if v := testutil.ToFloat64(metricVADThreshold.WithLabelValues("min_rms")); v != 1700 {
+		t.Errorf("orch_vad_threshold{min_rms} = %v", v)
+	}
+}
+
+func TestWatchThresholdsStops(t *testing.T) {
+	s := &Server{clock: clock.NewFake(time.Unix(1700000000, 0)), thresholdsFile: "unused.json", thresholdsWatch: time.Second}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		s.WatchThresholds(ctx)
+		close(done)
+	}()
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("WatchThresholds kept running after its context ended")
+	}
+
+	// Nothing to watch: returns at once
+	s.thresholdsWatch = 0
+	s.WatchThresholds(context.Background())
+}
@@ -0,0 +1,32 @@
+package orchestrator
+
+import (
+	gw "yuzu/agent/internal/orchestrator/pb"
+)
+
+// tokenstream.go forwards the LLM's tokens to sessions that asked for them
+// (SessionStyle.token_stream, or ORCH_TOKEN_STREAM for every session) so a
+// text client can render the reply as it is generated. Each reply's deltas
+// are numbered from 1 and end with a done delta, sent when the LLM stream
+// ends for any reason, barge-in included. Speech is unaffected: TTS still
+// gets whole sentences.
+
+// tokenDelta sends one TokenDelta when the session streams tokens. It
+// reports whether the session does. Callers must not hold st.mu.
+func (s *Server) tokenDelta(sid, turnID, text string, seq uint32, done bool, send func(*gw.OrchestratorCommand)) bool {
+	st := s.lookup(sid)
+	if st == nil {
+		return false
+	}
+	st.mu.Lock()
+	on := st.tokenStream
+	st.mu.Unlock()
+	if !on {
+		return false
+	}
+	metricTokenDeltas.Inc()
+	send(&gw.OrchestratorCommand{SessionId: sid, Cmd: &gw.OrchestratorCommand_TokenDelta{
+		TokenDelta: &gw.TokenDelta{TurnId: turnID, Text: text, Seq: seq, Done: done},
+	}})
+	return true
+}
@@ -0,0 +1,79 @@
+package orchestrator
+
+import (
+	"io"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+
+	"yuzu/agent/internal/clock"
+	llmpb "yuzu/agent/internal/llm/pb"
+	gw "yuzu/agent/internal/orchestrator/pb"
+)
+
+// fakeLLMStream replays msgs, then ends the stream.
+type fakeLLMStream struct {
+	grpc.ClientStream
+	msgs []*llmpb.ServerMessage
+}
+
+func (f *fakeLLMStream) Send(*llmpb.ClientMessage) error { return nil }
+
+func (f *fakeLLMStream) Recv() (*llmpb.ServerMessage, error) {
+	if len(f.msgs) == 0 {
+		return nil, io.EOF
+	}
+	m := f.msgs[0]
+	f.msgs = f.msgs[1:]
+	return m, nil
+}
+
+func replyStream() *fakeLLMStream {
+	token := func(t string) *llmpb.ServerMessage {
+		return &llmpb.ServerMessage{Msg: &llmpb.ServerMessage_Token{Token: &llmpb.Token{Text: t}}}
+	}
+	return &fakeLLMStream{msgs: []*llmpb.ServerMessage{
+		token("Tell"), token(" me"), token(" more."),
+		{Msg: &llmpb.ServerMessage_Sentence{Sentence: &llmpb.Sentence{Text: "Tell me more."}}},
+	}}
+}
+
+func TestTokenDeltasFollowSessionStyle(t *testing.T) {
+	s := &Server{sess: map[string]*sessionState{}, clock: clock.NewFake(time.Unix(1700000000, 0)), speakerPolicy: "1"}
+	for sid, style := range map[string]*gw.SessionStyle{"off": {}, "on": {TokenStream: true}} {
+		st := s.getOrCreateSession(sid)
+		s.handleSessionOpen(st, sid, "", style, &fakeStream{})
+	}
+
+	var sent []*gw.OrchestratorCommand
+	send := func(c *gw.OrchestratorCommand) { sent = append(sent, c) }
+	s.streamLLMResponses(replyStream(), "off", "t1", send, func() {}, nil)
+	for _, c := range sent {
+		if c.GetTokenDelta() != nil {
+			t.Fatalf("token stream off sent %v", c)
+		}
+	}
+
+	sent = nil
+	s.streamLLMResponses(replyStream(), "on", "t1", send, func() {}, nil)
+	var text string
+	var deltas []*gw.TokenDelta
+	for _, c := range sent {
+		if d := c.GetTokenDelta(); d != nil {
+			deltas = append(deltas, d)
+			text += d.GetText()
+		}
+	}
+	if len(deltas) != 4 || text != "Tell me more." || sent[len(sent)-1].GetTokenDelta() == nil {
+		t.Fatalf("deltas %v (text %q), want 3 tokens and done last", deltas, text)
+	}
+	for i, d := range deltas {
+		if d.GetSeq() != uint32(i+1) || d.GetTurnId() != "t1" || d.GetDone() != (i == 3) {
+			t.Errorf("delta %d = %v", i, d)
+		}
+	}
+	if sent[3].GetStartTts().GetText() != "Tell me more." {
+		t.Errorf("sentence not spoken after its tokens: %v", sent)
+	}
+}
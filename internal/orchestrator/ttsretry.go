@@ -0,0 +1,160 @@
+package orchestrator
+
+import (
+	"log"
+	"time"
+
+	gw "yuzu/agent/internal/orchestrator/pb"
+)
+
+// ttsretry.go recovers sentences whose synthesis failed. The orchestrator
+// remembers every StartTTS it issued until the gateway reports audio for it;
+// on a "failed" TTSEvent the unacknowledged sentences of that turn are sent
+// again, re-routed to the fallback provider first and then re-queued on the
+// same provider after a delay, until ttsRequeueMax re-sends are used up.
+// A delayed re-send is cancelled when the session closes.
+
+// pendingTTS is a StartTTS awaiting first audio.
+type pendingTTS struct {
+	turnID      string
+	utteranceID string
+	text        string
+	provider    string
+	rate        float32
+	pauseMs     uint32
+	intonation  string
+	resends     int
+	shown       bool // already sent as DisplayText (see degrade.go)
+}
+
+// trackTTS records an issued StartTTS. Callers hold st.mu.
+func (st *sessionState) trackTTS(cmd *gw.StartTTS) {
+	st.ttsPending = append(st.ttsPending, pendingTTS{
+		turnID: cmd.GetTurnId(), utteranceID: cmd.GetUtteranceId(), text: cmd.GetText(), provider: cmd.GetProvider(),
+		rate: cmd.GetSpeakingRate(), pauseMs: cmd.GetPauseMs(), intonation: cmd.GetIntonation(),
+	})
+	if n := len(st.ttsPending); n > maxRecentAgentUtterances {
+		st.ttsPending = append([]pendingTTS(nil), st.ttsPending[n-maxRecentAgentUtterances:]...)
+	}
+}
+
+// takeTTS removes and returns the pending sentences of utteranceID's turn up
+// to and including utteranceID. The gateway batches sentences and reports
+// only the last one, so earlier sentences share its fate. Callers hold st.mu.
+func (st *sessionState) takeTTS(utteranceID string) []pendingTTS {
+	last := -1
+	for i, p := range st.ttsPending {
+		if p.utteranceID == utteranceID {
+			last = i
+		}
+	}
+	if last < 0 {
+		return nil
+	}
+	turn := st.ttsPending[last].turnID
+	var taken, kept []pendingTTS
+	for i, p := range st.ttsPending {
+		if i <= last && p.turnID == turn {
+			taken = append(taken, p)
+		} else {
+			kept = append(kept, p)
+		}
+	}
+	st.ttsPending = kept
+	return taken
+}
+
+// handleTTSFailed re-sends the sentences covered by a failed utterance, or
+// drops them once the re-send budget is spent.
+func (s *Server) handleTTSFailed(st *sessionState, utteranceID, reason string, send func(*gw.OrchestratorCommand)) {
+	st.mu.Lock()
+	batch := st.takeTTS(utteranceID)
+	st.mu.Unlock()
+	if len(batch) == 0 {
+		log.Printf("[orch] TTS failed for unknown utterance sid=%s utterance=%s reason=%s", st.id, utteranceID, reason)
+		metricTTSRecovery.WithLabelValues("unknown").Inc()
+		return
+	}
+	failed := batch[len(batch)-1]
+	st.mu.Lock()
+	degraded := st.tts.degraded
+	st.mu.Unlock()
+	if degraded {
+		// A failed probe or a sentence that was in flight when TTS went down
+		log.Printf("[orch] TTS still failing sid=%s utterance=%s reason=%s; staying text-only", st.id, utteranceID, reason)
+		metricTTSDegrade.WithLabelValues("probe_failed").Inc()
+		showDropped(st.id, batch, send)
+		return
+	}
+	if failed.resends >= s.ttsRequeueMax {
+		log.Printf("[orch] TTS failed, dropping %d sentence(s) sid=%s utterance=%s reason=%s resends=%d", len(batch), st.id, utteranceID, reason, failed.resends)
+		metricTTSRecovery.WithLabelValues("dropped").Inc()
+		st.mu.Lock()
+		if st.state == stateSpeaking {
+			s.setState(st, stateListening, triggerTTSDropped)
+		}
+		degraded = s.ttsDropped(st)
+		st.mu.Unlock()
+		s.flushTurnStates(st)
+		if degraded {
+			showDropped(st.id, batch, send)
+		}
+		return
+	}
+
+	// Re-route once to the fallback provider; after that, or without one,
+	// wait and try the same provider again.
+	provider, delay, outcome := failed.provider, s.ttsRequeueDelay, "requeued"
+	if s.ttsFallbackProvider != "" && failed.provider != s.ttsFallbackProvider {
+		provider, delay, outcome = s.ttsFallbackProvider, 0, "rerouted"
+	}
+	metricTTSRecovery.WithLabelValues(outcome).Inc()
+	log.Printf("[orch] TTS failed, %s %d sentence(s) sid=%s utterance=%s reason=%s provider=%q delay=%s", outcome, len(batch), st.id, utteranceID, reason, provider, delay)
+
+	resend := func() {
+		for _, p := range batch {
+			cmd := &gw.StartTTS{Text: p.text, TurnId: p.turnID, UtteranceId: p.utteranceID, Provider: provider, SpeakingRate: p.rate, PauseMs: p.pauseMs, Intonation: p.intonation}
+			st.mu.Lock()
+			st.trackTTS(cmd)
+			st.ttsPending[len(st.ttsPending)-1].resends = failed.resends + 1
+			st.mu.Unlock()
+			send(&gw.OrchestratorCommand{SessionId: st.id, Cmd: &gw.OrchestratorCommand_StartTts{StartTts: cmd}})
+		}
+	}
+	if delay > 0 {
+		st.mu.Lock()
+		var t *time.Timer
+		t = time.AfterFunc(delay, func() {
+			st.mu.Lock()
+			live := st.forgetRequeue(t)
+			st.mu.Unlock()
+			if live {
+				resend()
+			}
+		})
+		st.ttsRequeues = append(st.ttsRequeues, t)
+		st.mu.Unlock()
+		return
+	}
+	resend()
+}
+
+// forgetRequeue removes t from the pending re-sends and reports whether it
+// was still pending. Callers hold st.mu.
+func (st *sessionState) forgetRequeue(t *time.Timer) bool {
+	for i, p := range st.ttsRequeues {
+		if p == t {
+			st.ttsRequeues = append(st.ttsRequeues[:i], st.ttsRequeues[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// stopRequeues cancels the pending re-sends. Callers hold st.mu.
+func (st *sessionState) stopRequeues() {
+	for _, t := range st.ttsRequeues {
+		t.Stop()
+	}
+	st.ttsRequeues = nil
+}
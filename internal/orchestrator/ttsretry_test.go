@@ -0,0 +1,79 @@
+package orchestrator
+
+import (
+	"testing"
+	"time"
+
+	"yuzu/agent/internal/clock"
+	gw "yuzu/agent/internal/orchestrator/pb"
+)
+
+func TestTTSFailedReroutesThenDrops(t *testing.T) {
+	s := &Server{sess: map[string]*sessionState{}, ttsFallbackProvider: "service", ttsRequeueMax: 1}
+	st := &sessionState{id: "s1", state: stateSpeaking}
+	st.trackTTS(&gw.StartTTS{Text: "Hello.", TurnId: "t1", UtteranceId: "t1-a1"})
+	st.trackTTS(&gw.StartTTS{Text: "How are you?", TurnId: "t1", UtteranceId: "t1-a2"})
+
+	var sent []*gw.StartTTS
+	send := func(c *gw.OrchestratorCommand) { sent = append(sent, c.GetStartTts()) }
+
+	// The gateway reports the batch under its last sentence
+	s.handleTTSEvent(st, "failed", 0, "t1-a2", "no_audio", send)
+	if len(sent) != 2 || sent[0].GetUtteranceId() != "t1-a1" || sent[1].GetText() != "How are you?" {
+		t.Fatalf("resent = %v, want both sentences in order", sent)
+	}
+	if sent[1].GetProvider() != "service" {
+		t.Errorf("provider = %q, want fallback", sent[1].GetProvider())
+	}
+
+	// Budget spent: the second failure drops the sentences
+	sent = nil
+	s.handleTTSEvent(st, "failed", 0, "t1-a2", "no_audio", send)
+	if len(sent) != 0 || len(st.ttsPending) != 0 {
+		t.Fatalf("resent = %v pending = %d, want dropped", sent, len(st.ttsPending))
+	}
+	if st.state != stateListening {
+		t.Errorf("state = %s, want LISTENING", st.state)
+	}
+}
+
+func TestTTSFirstAudioClearsPending(t *testing.T) {
+	st := &sessionState{id: "s1"}
+	st.trackTTS(&gw.StartTTS{TurnId: "t1", UtteranceId: "t1-a1"})
+	st.trackTTS(&gw.StartTTS{TurnId: "t1", UtteranceId: "t1-a2"})
+	st.trackTTS(&gw.StartTTS{TurnId: "t2", UtteranceId: "t2-a3"})
+	if got := st.takeTTS("t1-a2"); len(got) != 2 {
+		t.Fatalf("took %d, want 2", len(got))
+	}
+	if len(st.ttsPending) != 1 || st.ttsPending[0].utteranceID != "t2-a3" {
+		t.Fatalf("pending = %v, want only t2-a3", st.ttsPending)
+	}
+}
+
+func TestTTSRequeueStopsAtClose(t *testing.T) {
+	s := &Server{sess: map[string]*sessionState{}, clock: clock.Real, ttsRequeueMax: 1, ttsRequeueDelay: 20 * time.Millisecond}
+	st := &sessionState{id: "s1", state: stateSpeaking}
+	s.sess["s1"] = st
+	st.trackTTS(&gw.StartTTS{Text: "Hello.", TurnId: "t1", UtteranceId: "t1-a1"})
+
+	sent := make(chan *gw.StartTTS, 1)
+	send := func(c *gw.OrchestratorCommand) {
+		if tts := c.GetStartTts(); tts != nil {
+			sent <- tts
+		}
+	}
+	s.handleTTSEvent(st, "failed", 0, "t1-a1", "no_audio", send)
+	s.closeSession("s1", "participant_left", nil)
+
+	st.mu.Lock()
+	n := len(st.ttsRequeues)
+	st.mu.Unlock()
+	if n != 0 {
+		t.Fatalf("%d re-sends pending after close", n)
+	}
+	select {
+	case tts := <-sent:
+		t.Fatalf("re-sent %q after close", tts.GetText())
+	case <-time.After(60 * time.Millisecond):
+	}
+}
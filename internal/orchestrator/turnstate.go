@@ -0,0 +1,121 @@
+package orchestrator
+
+import (
+	"log"
+
+	gw "yuzu/agent/internal/orchestrator/pb"
+)
+
+// turnstate.go is the per-session turn state machine. Every change goes
+// through setState, which checks it against turnTransitions and names what
+// caused it. An illegal transition leaves the state alone, is logged and
+// counted in orch_state_rejected_total{from,to,trigger}; the caller decides
+// what to drop. The one that matters in practice is a TranscriptFinal while
+// the agent is SPEAKING: a barge-in moves the session to LISTENING first, so
+// a final that arrives without one is the agent's own echo or a candidate
+// who never got the floor, and is not answered (ORCH_PIPELINE_TURNS holds
+// it for the next turn instead; see pipeline.go).
+//
+// Each transition, rejected or not, is sent to the gateway as TurnState,
+// which lands in the session's event log as turn_state. setState only
+// queues it, since callers hold st.mu; whoever releases the lock sends the
+// queue with flushTurnStates. The queue waits for the session's stream, so
+// the IDLE of the first SessionOpen goes out once the stream is recorded.
+// A session that never gets one keeps only its last maxQueuedTurnStates.
+
+const maxQueuedTurnStates = 16
+
+// turnState is where a session is in the turn cycle.
+type turnState string
+
+const (
+	stateNone       turnState = ""           // not opened yet
+	stateIdle       turnState = "IDLE"       // opened, nothing said yet
+	stateListening  turnState = "LISTENING"  // the candidate has the floor
+	stateProcessing turnState = "PROCESSING" // a final is waiting on its reply
+	stateSpeaking   turnState = "SPEAKING"   // agent audio is playing
+	stateClosed     turnState = "CLOSED"
+)
+
+// What caused a transition.
+const (
+	triggerOpen       = "session_open"
+	triggerFinal      = "transcript_final"
+	triggerTTSStarted = "tts_started"
+	triggerTTSStopped = "tts_stopped"
+	triggerTTSDropped = "tts_dropped"
+	triggerTextOnly   = "text_only"
+	triggerBargeIn    = "barge_in"
+	triggerClose      = "close"
+)
+
+// turnTransitions lists the legal moves out of each state. Staying in the
+// same state is always allowed and is not a transition. A gateway that never
+// sent SessionOpen may start anywhere.
+var turnTransitions = map[turnState][]turnState{
+	stateNone:       {stateIdle, stateListening, stateProcessing, stateSpeaking, stateClosed},
+	stateIdle:       {stateListening, stateProcessing, stateSpeaking, stateClosed},
+	stateListening:  {stateProcessing, stateSpeaking, stateClosed},
+	stateProcessing: {stateListening, stateSpeaking, stateClosed},
+	stateSpeaking:   {stateListening, stateClosed},
+	stateClosed:     {},
+}
+
+// canTransition reports whether from → to is legal.
+func canTransition(from, to turnState) bool {
+	if from == to {
+		return true
+	}
+	for _, s := range turnTransitions[from] {
+		if s == to {
+			return true
+		}
+	}
+	return false
+}
+
+// setState moves the session to `to` if the transition is legal and
+// reports whether the session is now there. Callers hold st.mu.
+func (s *Server) setState(st *sessionState, to turnState, trigger string) bool {
+	from := st.state
+	if from == to {
+		return true
+	}
+	ok := canTransition(from, to)
+	if ok {
+		metricStateTransitions.WithLabelValues(string(from), string(to)).Inc()
+		st.state = to
+	} else {
+		metricStateRejected.WithLabelValues(string(from), string(to), trigger).Inc()
+		log.Printf("[orch] illegal state transition sid=%s %s -> %s on %s; staying %s", st.id, from, to, trigger, from)
+	}
+	if len(st.turnStates) >= maxQueuedTurnStates {
+		st.turnStates = st.turnStates[1:]
+	}
+	st.turnStates = append(st.turnStates, &gw.OrchestratorCommand{SessionId: st.id, Cmd: &gw.OrchestratorCommand_TurnState{TurnState: &gw.TurnState{
+		FromState: string(from), ToState: string(to), Trigger: trigger, TurnId: st.turnID, Rejected: !ok,
+	}}})
+	return ok
+}
+
+// takeTurnStates empties the TurnState queue and returns it with the
+// stream to send it on, or nothing while the session has no stream.
+// Callers hold st.mu.
+func (st *sessionState) takeTurnStates() (func(*gw.OrchestratorCommand), []*gw.OrchestratorCommand) {
+	if st.send == nil {
+		return nil, nil
+	}
+	q := st.turnStates
+	st.turnStates = nil
+	return st.send, q
+}
+
+// flushTurnStates sends the queued TurnStates. Callers must not hold st.mu.
+func (s *Server) flushTurnStates(st *sessionState) {
+	st.mu.Lock()
+	send, q := st.takeTurnStates()
+	st.mu.Unlock()
+	for _, c := range q {
+		send(c)
+	}
+}
@@ -0,0 +1,72 @@
+package orchestrator
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"yuzu/agent/internal/clock"
+	gw "yuzu/agent/internal/orchestrator/pb"
+)
+
+func TestTurnTransitions(t *testing.T) {
+	cases := []struct {
+		from, to turnState
+		ok       bool
+	}{
+		{stateNone, stateIdle, true},
+		{stateIdle, stateProcessing, true},
+		{stateListening, stateProcessing, true},
+		{stateProcessing, stateSpeaking, true},
+		{stateSpeaking, stateListening, true},
+		{stateSpeaking, stateSpeaking, true},
+		{stateSpeaking, stateProcessing, false},
+		{stateProcessing, stateIdle, false},
+		{stateClosed, stateListening, false},
+	}
+	for _, c := range cases {
+		if got := canTransition(c.from, c.to); got != c.ok {
+			t.Errorf("%s -> %s = %t, want %t", c.from, c.to, got, c.ok)
+		}
+	}
+}
+
+func TestFinalWhileSpeakingNeedsBargeIn(t *testing.T) {
+	s := &Server{sess: map[string]*sessionState{}, clock: clock.NewFake(time.Unix(1700000000, 0))}
+	var events []*gw.TurnState
+	st := &sessionState{id: "s1", state: stateSpeaking, send: func(c *gw.OrchestratorCommand) {
+		if ts := c.GetTurnState(); ts != nil {
+			events = append(events, ts)
+		}
+	}}
+	st.turnID = "t1"
+	s.sess["s1"] = st
+	var cmds []*gw.OrchestratorCommand
+	send := func(c *gw.OrchestratorCommand) { cmds = append(cmds, c) }
+
+	// Echo of the agent's own speech: refused, nothing recorded or sent
+	s.handleTranscriptFinal(context.Background(), st, "s1", "", "tell me about yourself", send)
+	if st.state != stateSpeaking || len(cmds) != 0 || len(st.transcript) != 0 {
+		t.Fatalf("final while speaking: state %s, cmds %v, transcript %v", st.state, cmds, st.transcript)
+	}
+	if len(events) != 1 || !events[0].GetRejected() || events[0].GetToState() != string(stateProcessing) || events[0].GetTrigger() != triggerFinal {
+		t.Fatalf("rejection events = %v", events)
+	}
+
+	// After a barge-in the candidate has the floor
+	st.mu.Lock()
+	s.setState(st, stateListening, triggerBargeIn)
+	st.mu.Unlock()
+	s.flushTurnStates(st)
+	events = nil
+	st.mu.Lock()
+	ok := s.setState(st, stateProcessing, triggerFinal)
+	if len(events) != 0 {
+		t.Error("TurnState sent while holding st.mu")
+	}
+	st.mu.Unlock()
+	s.flushTurnStates(st)
+	if !ok || st.state != stateProcessing || len(events) != 1 || events[0].GetFromState() != string(stateListening) || events[0].GetRejected() {
+		t.Fatalf("after barge-in: ok %t, state %s, events %v", ok, st.state, events)
+	}
+}
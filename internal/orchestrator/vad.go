@@ -8,9 +8,30 @@ import (
 )
 
 // processFeature handles GatewayEvent_Feature based on vadSource config.
+// Commands are collected under st.mu and sent after it is released.
 // Returns true if barge-in was triggered.
 func (s *Server) processFeature(st *sessionState, rms float64, now time.Time, sid string, stream gw.GatewayControl_SessionServer) bool {
 	metricVADFeatures.Inc()
+	st.mu.Lock()
+	cmds := s.sampleProfile(st, rms, now)
+	bargeIn := s.featureVAD(st, rms, now, sid, &cmds)
+	st.mu.Unlock()
+
+	for _, c := range cmds {
+		s.sendCmd(stream, c)
+	}
+	s.flushTurnStates(st)
+	return bargeIn
+}
+
+// featureVAD runs one feature through the tail check and VAD. Callers hold
+// st.mu.
+func (s *Server) featureVAD(st *sessionState, rms float64, now time.Time, sid string, out *[]*gw.OrchestratorCommand) bool {
+	// Echo of the agent's last words right after TTS stopped
+	if s.inTail(st, rms >= st.minRMS, now) {
+		st.lastFeatureAt = now
+		return false
+	}
 
 	if s.vadSource != "feature" {
 		// Secondary: record for agreement timing only
@@ -19,38 +40,45 @@ func (s *Server) processFeature(st *sessionState, rms float64, now time.Time, si
 	}
 
 	// Primary path: feature drives VAD
-	return s.handleFeaturePrimary(st, rms, now, sid, stream)
+	return s.handleFeaturePrimary(st, rms, now, sid, out)
 }
 
 // handleFeaturePrimary drives VAD from feature (RMS) as primary source.
+// Speech starts once at least bargeInMinSpeech of loud audio (and minStart
+// loud frames) falls within the trailing bargeInWindow, so sporadic loud
+// frames spread over seconds no longer add up to a barge-in.
+// The barge-in commands are appended to out. Callers hold st.mu.
 // Returns true if barge-in was triggered.
-func (s *Server) handleFeaturePrimary(st *sessionState, rms float64, now time.Time, sid string, stream gw.GatewayControl_SessionServer) bool {
+func (s *Server) handleFeaturePrimary(st *sessionState, rms float64, now time.Time, sid string, out *[]*gw.OrchestratorCommand) bool {
 	if !st.speaking {
 		if now.Before(st.guardUntil) && rms >= st.minRMS {
+			st.lastFeatureAt = now
 			metricBargeInGuardBlocks.Inc()
 			log.Printf("[orch] barge-in guard blocked sid=%s rms=%.1f minRMS=%.1f guard_remaining=%dms", sid, rms, st.minRMS, st.guardUntil.Sub(now).Milliseconds())
 			return false
 		}
+		credit := s.frameCredit(st, now)
 		if rms >= st.minRMS {
 			st.consecSpeech++
-			if st.consecSpeech >= st.minStart {
+			st.speechWin.add(now, credit)
+			voiced, frames := st.speechWin.total(now, s.bargeInWindow)
+			if frames >= st.minStart && voiced >= s.bargeInMinSpeech {
 				st.speaking = true
 				st.nonSpeech = 0
 				st.lastFeatureStart = now
+				st.speechWin.reset()
 				metricVADStarts.Inc()
 
-				log.Printf("[orch] BARGE-IN TRIGGERED sid=%s rms=%.1f minRMS=%.1f consec=%d", sid, rms, st.minRMS, st.consecSpeech)
+				log.Printf("[orch] BARGE-IN TRIGGERED sid=%s rms=%.1f minRMS=%.1f voiced=%dms/%dms frames=%d", sid, rms, st.minRMS, voiced.Milliseconds(), s.bargeInWindow.Milliseconds(), frames)
 
-                // Barge-in: stop TTS
-                s.sendCmd(stream, &gw.OrchestratorCommand{
-                    SessionId: sid,
-                    Cmd:       &gw.OrchestratorCommand_StopTts{StopTts: &gw.StopTTS{Reason: "barge_in"}},
-                })
+                // Barge-in: stop TTS, reopening a half-duplex mic
+                *out = append(*out, s.bargeInCmds(st)...)
                 metricBargeIn.Inc()
                 metricBargeInTotal.Inc()
 
 				// Cancel active LLM
 				s.cancelLLM(st)
+				s.setState(st, stateListening, triggerBargeIn)
 
 				// Record latency
 				if !st.guardUntil.IsZero() && now.After(st.guardUntil) {
@@ -74,6 +102,7 @@ func (s *Server) handleFeaturePrimary(st *sessionState, rms float64, now time.Ti
 	}
 
 	// Currently speaking - check for end of speech
+	st.lastFeatureAt = now
 	if rms < st.minRMS {
 		st.nonSpeech++
 		if st.nonSpeech >= st.hangover {
@@ -90,6 +119,55 @@ func (s *Server) handleFeaturePrimary(st *sessionState, rms float64, now time.Ti
 	return false
 }
 
+// frameCredit returns how much audio the current feature stands for: the gap
+// since the previous feature, capped at one feature period so a frame after
+// a long silence (or a dropped stretch) is not credited as long speech. The
+// very first feature of a session has no reference and counts for nothing.
+func (s *Server) frameCredit(st *sessionState, now time.Time) time.Duration {
+	var credit time.Duration
+	if !st.lastFeatureAt.IsZero() {
+		credit = now.Sub(st.lastFeatureAt)
+		if credit < 0 {
+			credit = 0
+		}
+		if credit > s.featurePeriod {
+			credit = s.featurePeriod
+		}
+	}
+	st.lastFeatureAt = now
+	return credit
+}
+
+// speechWindow keeps recent loud frames and the audio each one covers.
+type speechWindow struct {
+	at  []time.Time
+	dur []time.Duration
+}
+
+func (w *speechWindow) add(now time.Time, d time.Duration) {
+	w.at = append(w.at, now)
+	w.dur = append(w.dur, d)
+}
+
+// total drops frames older than window and returns the voiced time and
+// frame count remaining.
+func (w *speechWindow) total(now time.Time, window time.Duration) (time.Duration, int) {
+	cut := 0
+	for cut < len(w.at) && now.Sub(w.at[cut]) >= window {
+		cut++
+	}
+	w.at, w.dur = w.at[cut:], w.dur[cut:]
+	var sum time.Duration
+	for _, d := range w.dur {
+		sum += d
+	}
+	return sum, len(w.at)
+}
+
+func (w *speechWindow) reset() {
+	w.at, w.dur = w.at[:0], w.dur[:0]
+}
+
 // recordFeatureAgreement records feature VAD timing when gateway is primary.
 func (s *Server) recordFeatureAgreement(st *sessionState, rms float64, now time.Time) {
 	if rms >= st.minRMS && st.lastFeatureStart.IsZero() {
@@ -98,33 +176,43 @@ func (s *Server) recordFeatureAgreement(st *sessionState, rms float64, now time.
 }
 
 // processGatewayVAD handles GatewayEvent_VadStart based on vadSource config.
+// Commands are sent after st.mu is released.
 // Returns true if barge-in was triggered.
 func (s *Server) processGatewayVAD(st *sessionState, now time.Time, sid string, stream gw.GatewayControl_SessionServer) bool {
+	var cmds []*gw.OrchestratorCommand
+	bargeIn := false
+	st.mu.Lock()
 	st.lastGatewayStart = now
-
-	if s.vadSource == "gateway" {
+	switch {
+	case s.inTail(st, true, now):
+	case s.vadSource == "gateway":
 		// Primary: gateway drives VAD
-		return s.handleGatewayVADPrimary(st, now, sid, stream)
+		bargeIn = s.handleGatewayVADPrimary(st, now, sid, &cmds)
+	default:
+		// Secondary: just record agreement
+		s.recordGatewayAgreement(st, now)
 	}
+	st.mu.Unlock()
 
-	// Secondary: just record agreement
-	s.recordGatewayAgreement(st, now)
-	return false
+	for _, c := range cmds {
+		s.sendCmd(stream, c)
+	}
+	s.flushTurnStates(st)
+	return bargeIn
 }
 
 // handleGatewayVADPrimary drives VAD from gateway events as primary source.
+// The barge-in commands are appended to out. Callers hold st.mu.
 // Returns true (always triggers barge-in when called as primary).
-func (s *Server) handleGatewayVADPrimary(st *sessionState, now time.Time, sid string, stream gw.GatewayControl_SessionServer) bool {
-    // Stop TTS
-    s.sendCmd(stream, &gw.OrchestratorCommand{
-        SessionId: sid,
-        Cmd:       &gw.OrchestratorCommand_StopTts{StopTts: &gw.StopTTS{Reason: "barge_in"}},
-    })
+func (s *Server) handleGatewayVADPrimary(st *sessionState, now time.Time, sid string, out *[]*gw.OrchestratorCommand) bool {
+    // Stop TTS, reopening a half-duplex mic
+    *out = append(*out, s.bargeInCmds(st)...)
     metricBargeIn.Inc()
     metricBargeInTotal.Inc()
 
 	// Cancel active LLM
 	s.cancelLLM(st)
+	s.setState(st, stateListening, triggerBargeIn)
 
 	// Log agreement with feature VAD
 	if !st.lastFeatureStart.IsZero() {
@@ -148,10 +236,11 @@ func (s *Server) recordGatewayAgreement(st *sessionState, now time.Time) {
 	}
 }
 
-// cancelLLM cancels any active LLM stream for the session.
+// cancelLLM cancels any active LLM stream for the session. Callers hold
+// st.mu.
 func (s *Server) cancelLLM(st *sessionState) {
-	s.mu.Lock()
-	defer s.mu.Unlock()
+	// A barge-in or close also makes any filler moot
+	st.takeFiller()
 	if st.llmActive && st.llmCancel != nil {
 		st.llmCancel()
 		st.llmActive = false
@@ -162,7 +251,7 @@ func (s *Server) cancelLLM(st *sessionState) {
 // armBargeIn sets up the barge-in guard window for a session.
 func (s *Server) armBargeIn(st *sessionState, guardMs uint32, minRms uint32) {
 	st.minRMS = float64(minRms)
-	st.armedAt = time.Now()
+	st.armedAt = s.clock.Now()
 	st.guardUntil = st.armedAt.Add(time.Duration(guardMs) * time.Millisecond)
 }
 
@@ -171,4 +260,5 @@ func (s *Server) resetVADState(st *sessionState) {
 	st.speaking = false
 	st.consecSpeech = 0
 	st.nonSpeech = 0
+	st.speechWin.reset()
 }
@@ -4,8 +4,22 @@ import (
 	"context"
 	"testing"
 	"time"
+
+	"google.golang.org/grpc"
+
+	"yuzu/agent/internal/clock"
+	gw "yuzu/agent/internal/orchestrator/pb"
 )
 
+// fakeStream records commands sent to the gateway.
+type fakeStream struct {
+	grpc.ServerStream
+	sent []*gw.OrchestratorCommand
+}
+
+func (f *fakeStream) Send(c *gw.OrchestratorCommand) error { f.sent = append(f.sent, c); return nil }
+func (f *fakeStream) Recv() (*gw.GatewayEvent, error)     { return nil, context.Canceled }
+
 func TestVADThresholds(t *testing.T) {
 	s := NewServer()
 	st := &sessionState{
@@ -16,7 +30,7 @@ func TestVADThresholds(t *testing.T) {
 
 	// Below threshold - no speech start
 	for i := 0; i < 5; i++ {
-		s.handleFeaturePrimary(st, 500.0, time.Now(), "test", nil)
+		s.handleFeaturePrimary(st, 500.0, time.Now(), "test", &[]*gw.OrchestratorCommand{})
 	}
 	if st.speaking {
 		t.Error("should not be speaking with RMS below threshold")
@@ -35,7 +49,7 @@ func TestVADSpeechStart(t *testing.T) {
 	}
 
 	// First frame above threshold
-	s.handleFeaturePrimary(st, 1500.0, time.Now(), "test", nil)
+	s.handleFeaturePrimary(st, 1500.0, time.Now(), "test", &[]*gw.OrchestratorCommand{})
 	if st.speaking {
 		t.Error("should not be speaking after just 1 frame")
 	}
@@ -44,7 +58,7 @@ func TestVADSpeechStart(t *testing.T) {
 	}
 
 	// Second frame above threshold - still not speaking
-	s.handleFeaturePrimary(st, 1500.0, time.Now(), "test", nil)
+	s.handleFeaturePrimary(st, 1500.0, time.Now(), "test", &[]*gw.OrchestratorCommand{})
 	if st.speaking {
 		t.Error("should not be speaking after 2 frames (minStart=3)")
 	}
@@ -64,7 +78,7 @@ func TestVADSpeechEnd(t *testing.T) {
 
 	// Frames below threshold
 	for i := 0; i < 2; i++ {
-		s.handleFeaturePrimary(st, 500.0, time.Now(), "test", nil)
+		s.handleFeaturePrimary(st, 500.0, time.Now(), "test", &[]*gw.OrchestratorCommand{})
 	}
 	if !st.speaking {
 		t.Error("should still be speaking (hangover not reached)")
@@ -74,7 +88,7 @@ func TestVADSpeechEnd(t *testing.T) {
 	}
 
 	// Third frame below threshold - should end speech
-	s.handleFeaturePrimary(st, 500.0, time.Now(), "test", nil)
+	s.handleFeaturePrimary(st, 500.0, time.Now(), "test", &[]*gw.OrchestratorCommand{})
 	if st.speaking {
 		t.Error("should stop speaking after hangover frames")
 	}
@@ -91,7 +105,7 @@ func TestVADGuardBlock(t *testing.T) {
 	}
 
 	// During guard window, high RMS should be blocked
-	triggered := s.handleFeaturePrimary(st, 1500.0, now, "test", nil)
+	triggered := s.handleFeaturePrimary(st, 1500.0, now, "test", &[]*gw.OrchestratorCommand{})
 	if triggered {
 		t.Error("should not trigger barge-in during guard window")
 	}
@@ -109,14 +123,14 @@ func TestVADConsecSpeechReset(t *testing.T) {
 	}
 
 	// Two frames above threshold
-	s.handleFeaturePrimary(st, 1500.0, time.Now(), "test", nil)
-	s.handleFeaturePrimary(st, 1500.0, time.Now(), "test", nil)
+	s.handleFeaturePrimary(st, 1500.0, time.Now(), "test", &[]*gw.OrchestratorCommand{})
+	s.handleFeaturePrimary(st, 1500.0, time.Now(), "test", &[]*gw.OrchestratorCommand{})
 	if st.consecSpeech != 2 {
 		t.Errorf("consecSpeech should be 2, got %d", st.consecSpeech)
 	}
 
 	// One frame below threshold - should reset
-	s.handleFeaturePrimary(st, 500.0, time.Now(), "test", nil)
+	s.handleFeaturePrimary(st, 500.0, time.Now(), "test", &[]*gw.OrchestratorCommand{})
 	if st.consecSpeech != 0 {
 		t.Errorf("consecSpeech should reset to 0, got %d", st.consecSpeech)
 	}
@@ -130,7 +144,9 @@ func TestCancelLLM(t *testing.T) {
 		llmCancel: func() { cancelled = true },
 	}
 
+	st.mu.Lock()
 	s.cancelLLM(st)
+	st.mu.Unlock()
 
 	if !cancelled {
 		t.Error("cancel function should have been called")
@@ -151,7 +167,9 @@ func TestCancelLLMNoOp(t *testing.T) {
 	}
 
 	// Should not panic
+	st.mu.Lock()
 	s.cancelLLM(st)
+	st.mu.Unlock()
 }
 
 func TestAttachDetachLLM(t *testing.T) {
@@ -222,3 +240,145 @@ func TestResetVADState(t *testing.T) {
 		t.Error("nonSpeech should be 0 after reset")
 	}
 }
+
+func TestVADSustainedSpeechTriggers(t *testing.T) {
+	s := NewServer()
+	st := &sessionState{minStart: 2, hangover: 3, minRMS: 1000.0}
+	t0 := time.Now()
+
+	// Feature cadence while the agent speaks: one frame per 300ms
+	s.handleFeaturePrimary(st, 500.0, t0, "test", &[]*gw.OrchestratorCommand{})
+	if s.handleFeaturePrimary(st, 1500.0, t0.Add(300*time.Millisecond), "test", &[]*gw.OrchestratorCommand{}) {
+		t.Fatal("one loud frame should not trigger")
+	}
+	if !s.handleFeaturePrimary(st, 1500.0, t0.Add(600*time.Millisecond), "test", &[]*gw.OrchestratorCommand{}) {
+		t.Fatal("two back-to-back loud frames should trigger")
+	}
+}
+
+func TestVADSparseLoudFramesDoNotTrigger(t *testing.T) {
+	s := NewServer()
+	st := &sessionState{minStart: 2, hangover: 3, minRMS: 1000.0}
+	t0 := time.Now()
+
+	// Loud frames spaced a second apart with no features in between: the old
+	// consecutive counter treated these as back-to-back speech.
+	for i := 0; i < 5; i++ {
+		if s.handleFeaturePrimary(st, 1500.0, t0.Add(time.Duration(i)*time.Second), "test", &[]*gw.OrchestratorCommand{}) {
+			t.Fatalf("sparse frame %d triggered barge-in", i)
+		}
+	}
+
+	// Isolated loud frames among quiet ones at 10Hz do not add up either
+	st = &sessionState{minStart: 2, hangover: 3, minRMS: 1000.0}
+	for i := 0; i < 30; i++ {
+		rms := 500.0
+		if i%3 == 0 {
+			rms = 1500.0
+		}
+		if s.handleFeaturePrimary(st, rms, t0.Add(time.Duration(i)*100*time.Millisecond), "test", &[]*gw.OrchestratorCommand{}) {
+			t.Fatalf("intermittent frame %d triggered barge-in", i)
+		}
+	}
+}
+
+func TestVADShortDipKeepsWindow(t *testing.T) {
+	s := NewServer()
+	st := &sessionState{minStart: 2, hangover: 3, minRMS: 1000.0}
+	t0 := time.Now()
+
+	// 10Hz: loud, dip, loud, loud -> 300ms voiced within 400ms despite the dip
+	rms := []float64{500, 1500, 500, 1500, 1500}
+	triggered := false
+	for i, r := range rms {
+		triggered = s.handleFeaturePrimary(st, r, t0.Add(time.Duration(i)*100*time.Millisecond), "test", &[]*gw.OrchestratorCommand{})
+	}
+	if !triggered {
+		t.Fatal("speech with a one-frame dip should still trigger")
+	}
+}
+
+func TestGuardWindowFakeClock(t *testing.T) {
+	s := NewServer()
+	clk := clock.NewFake(time.Unix(1700000000, 0))
+	s.clock = clk
+	st := &sessionState{minStart: 2, hangover: 3}
+	var sent []*gw.OrchestratorCommand
+
+	s.armBargeIn(st, 500, 1000)
+	feed := func(rms float64) bool {
+		clk.Advance(100 * time.Millisecond)
+		return s.handleFeaturePrimary(st, rms, clk.Now(), "test", &sent)
+	}
+
+	// Loud speech throughout the guard window is ignored
+	for i := 0; i < 4; i++ {
+		if feed(1500.0) {
+			t.Fatalf("barge-in inside guard window at +%dms", (i+1)*100)
+		}
+	}
+	if len(st.speechWin.at) != 0 {
+		t.Fatal("guarded frames should not count toward speech")
+	}
+
+	// Guard expires at +500ms; sustained speech after that triggers
+	triggered := false
+	for i := 0; i < 4 && !triggered; i++ {
+		triggered = feed(1500.0)
+	}
+	if !triggered {
+		t.Fatal("expected barge-in once the guard expired")
+	}
+	if len(sent) != 1 || sent[0].GetStopTts().GetReason() != "barge_in" {
+		t.Fatalf("expected one StopTTS(barge_in), got %v", sent)
+	}
+
+	// Hangover: speech ends after `hangover` quiet frames
+	for i := 0; i < 2; i++ {
+		feed(100.0)
+	}
+	if !st.speaking {
+		t.Fatal("speech ended before hangover")
+	}
+	feed(100.0)
+	if st.speaking {
+		t.Fatal("speech should end after hangover frames")
+	}
+}
+
+// lockedStream fails the test when a command is sent under st.mu.
+type lockedStream struct {
+	fakeStream
+	t  *testing.T
+	st *sessionState
+}
+
+func (l *lockedStream) Send(c *gw.OrchestratorCommand) error {
+	if !l.st.mu.TryLock() {
+		l.t.Errorf("%T sent while holding st.mu", c.Cmd)
+	} else {
+		l.st.mu.Unlock()
+	}
+	return l.fakeStream.Send(c)
+}
+
+func TestBargeInSendsAfterUnlock(t *testing.T) {
+	clk := clock.NewFake(time.Unix(1700000000, 0))
+	for _, src := range []string{"gateway", "feature"} {
+		s := &Server{sess: map[string]*sessionState{}, clock: clk, vadSource: src, bargeInWindow: time.Second}
+		st := &sessionState{id: "s1", state: stateSpeaking, minStart: 1, hangover: 3, minRMS: 1000.0}
+		ls := &lockedStream{t: t, st: st}
+		triggered := false
+		for i := 0; i < 5 && !triggered; i++ {
+			clk.Advance(100 * time.Millisecond)
+			if src == "gateway" {
+				triggered = s.processGatewayVAD(st, clk.Now(), "s1", ls)
+			} else {
+				triggered = s.processFeature(st, 1500.0, clk.Now(), "s1", ls)
+			}
+		}
+		if !triggered || len(ls.sent) == 0 || ls.sent[0].GetStopTts().GetReason() != "barge_in" {
+			t.Fatalf("%s: triggered=%v sent %v, want StopTTS(barge_in)", src, triggered, ls.sent)
+		}
+	}
+}
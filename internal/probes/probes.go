@@ -0,0 +1,80 @@
+// Package probes serves a service's health, readiness and metrics endpoints
+// on their own port. Start listens before it returns, so a port conflict is
+// an error for main to act on rather than a goroutine dying quietly, and a
+// server that fails later reports it on Err. Shutdown drains it with the
+// rest of the service.
+package probes
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Addr returns the bind address in the environment variable env, or def.
+func Addr(env, def string) string {
+	if v := os.Getenv(env); v != "" {
+		return v
+	}
+	return def
+}
+
+// NewMux returns a mux serving /healthz and /metrics; services add /readyz
+// and their own endpoints.
+func NewMux() *http.ServeMux {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) { w.Write([]byte("ok\n")) })
+	mux.Handle("/metrics", promhttp.Handler())
+	return mux
+}
+
+// Server is a running probe server.
+type Server struct {
+	name string
+	srv  *http.Server
+	ln   net.Listener
+	err  chan error
+}
+
+// Start listens on addr and serves h in the background.
+func Start(name, addr string, h http.Handler) (*Server, error) {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	s := &Server{
+		name: name,
+		srv:  &http.Server{Handler: h, ReadHeaderTimeout: 5 * time.Second},
+		ln:   ln,
+		err:  make(chan error, 1),
+	}
+	go func() {
+		err := s.srv.Serve(ln)
+		if errors.Is(err, http.ErrServerClosed) {
+			err = nil
+		}
+		s.err <- err
+		close(s.err)
+	}()
+	log.Printf("%s probes/metrics on %s", name, ln.Addr())
+	return s, nil
+}
+
+// Addr is the address the server is listening on.
+func (s *Server) Addr() string { return s.ln.Addr().String() }
+
+// Err delivers the error the server stopped with, nil after Shutdown, and
+// is closed once it has stopped.
+func (s *Server) Err() <-chan error { return s.err }
+
+// Shutdown stops accepting probes and waits for those in flight until ctx
+// is done.
+func (s *Server) Shutdown(ctx context.Context) error {
+	return s.srv.Shutdown(ctx)
+}
@@ -0,0 +1,44 @@
+package probes
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestStartServesAndShutsDown(t *testing.T) {
+	s, err := Start("test", "127.0.0.1:0", NewMux())
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := http.Get("http://" + s.Addr() + "/healthz")
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || string(body) != "ok\n" {
+		t.Fatalf("/healthz = %d %q", resp.StatusCode, body)
+	}
+
+	// The port is taken: the second server fails to start instead of dying later
+	if _, err := Start("dup", s.Addr(), NewMux()); err == nil {
+		t.Fatal("second server on the same address started")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := s.Shutdown(ctx); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case err := <-s.Err():
+		if err != nil {
+			t.Fatalf("Err after Shutdown = %v, want nil", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("server still running after Shutdown")
+	}
+}
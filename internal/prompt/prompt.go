@@ -0,0 +1,46 @@
+// Package prompt builds the LLM system prompt from a session's response
+// style. It is shared by the orchestrator (live turns) and the API server
+// (exports) so both see the same text.
+package prompt
+
+import (
+	"os"
+
+	"yuzu/agent/internal/types"
+)
+
+var personas = map[string]string{
+	types.PersonaFriendly:             "You are a friendly voice assistant.",
+	types.PersonaFormal:               "You are a courteous, professional voice assistant. Use a formal register and avoid slang.",
+	types.PersonaTechnicalInterviewer: "You are a technical interviewer. Ask one focused question at a time, probe for depth and reasoning, and stay neutral about answers.",
+}
+
+var verbosity = map[string]string{
+	types.VerbosityBrief:    "Respond in one short sentence.",
+	types.VerbosityNormal:   "Respond in 1-2 short sentences.",
+	types.VerbosityDetailed: "Respond in at most 4 sentences.",
+}
+
+// SpeechFormatting is appended to every built prompt: replies are spoken aloud.
+const SpeechFormatting = "Be conversational and natural. Never use bullet points, lists, markdown, " +
+	"or special formatting. Your responses will be spoken aloud via text-to-speech."
+
+// System returns the system prompt for a style. A tenant's SystemPrompt,
+// then an operator-supplied LLM_SYSTEM_PROMPT, take precedence verbatim.
+func System(st types.SessionStyle) string {
+	if st.SystemPrompt != "" {
+		return st.SystemPrompt
+	}
+	if sys := os.Getenv("LLM_SYSTEM_PROMPT"); sys != "" {
+		return sys
+	}
+	p := personas[st.Persona]
+	if p == "" {
+		p = personas[types.PersonaFriendly]
+	}
+	v := verbosity[st.Verbosity]
+	if v == "" {
+		v = verbosity[types.VerbosityNormal]
+	}
+	return p + " " + v + " " + SpeechFormatting
+}
@@ -0,0 +1,242 @@
+// Package retrieval picks the parts of a session's reference documents (a
+// job description, the candidate's resume) that matter for the current
+// turn, so the prompt carries a few relevant paragraphs instead of every
+// document in full.
+//
+// Documents are split into chunks of about a paragraph. Chunks are ranked
+// by the query terms they contain, each weighted by how rare it is across
+// the session's chunks. There are no embeddings: interview context is a few
+// pages, and lexical overlap needs no model call on the reply path. When
+// nothing matches, the opening chunk of each document stands in, which is
+// where the summary of a resume or job description usually is.
+package retrieval
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"yuzu/agent/internal/types"
+)
+
+// Chunk is a piece of one document.
+type Chunk struct {
+	Doc  string // document name
+	Kind string
+	Seq  int // position within the document
+	Text string
+
+	terms map[string]int
+}
+
+// Index holds one session's chunks. It is read-only once built and safe
+// for concurrent use.
+type Index struct {
+	chunks []Chunk
+	df     map[string]int // chunks containing each term
+}
+
+// New chunks docs into pieces of at most chunkChars bytes (paragraphs are
+// kept whole when they fit; 0 means 600, the floor is 64).
+func New(docs []types.ContextDoc, chunkChars int) *Index {
+	if chunkChars <= 0 {
+		chunkChars = 600
+	}
+	chunkChars = max(chunkChars, 64)
+	ix := &Index{df: map[string]int{}}
+	for _, d := range docs {
+		for i, text := range split(d.Text, chunkChars) {
+			c := Chunk{Doc: d.Name, Kind: d.Kind, Seq: i, Text: text, terms: map[string]int{}}
+			for _, t := range terms(text) {
+				c.terms[t]++
+			}
+			for t := range c.terms {
+				ix.df[t]++
+			}
+			ix.chunks = append(ix.chunks, c)
+		}
+	}
+	return ix
+}
+
+// Len is the number of chunks.
+func (ix *Index) Len() int {
+	if ix == nil {
+		return 0
+	}
+	return len(ix.chunks)
+}
+
+// Select returns up to k chunks relevant to query, best first, within
+// maxChars bytes in total (0 is unbounded); the last one is cut short to
+// fit. matched is false when no chunk shares a term with the query and the
+// documents' opening chunks were returned instead.
+func (ix *Index) Select(query string, k, maxChars int) (out []Chunk, matched bool) {
+	if ix.Len() == 0 || k <= 0 {
+		return nil, false
+	}
+	type scored struct {
+		i     int
+		score float64
+	}
+	var hits []scored
+	q := unique(terms(query))
+	n := float64(len(ix.chunks))
+	for i, c := range ix.chunks {
+		score := 0.0
+		for _, t := range q {
+			if tf := c.terms[t]; tf > 0 {
+				score += math.Log(1+n/float64(ix.df[t])) * (1 + math.Log(float64(tf)))
+			}
+		}
+		if score > 0 {
+			hits = append(hits, scored{i, score})
+		}
+	}
+	sort.SliceStable(hits, func(a, b int) bool { return hits[a].score > hits[b].score })
+	for _, h := range hits {
+		out = append(out, ix.chunks[h.i])
+	}
+	matched = len(out) > 0
+	if !matched {
+		for _, c := range ix.chunks {
+			if c.Seq == 0 {
+				out = append(out, c)
+			}
+		}
+	}
+	if len(out) > k {
+		out = out[:k]
+	}
+	return budget(out, maxChars), matched
+}
+
+// Format renders chunks for the system prompt.
+func Format(chunks []Chunk) string {
+	if len(chunks) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteString("Background documents for this conversation. Use them when relevant and never read them out verbatim.")
+	for _, c := range chunks {
+		b.WriteString("\n\n[")
+		b.WriteString(c.Doc)
+		if c.Kind != "" && c.Kind != c.Doc {
+			b.WriteString(" (" + c.Kind + ")")
+		}
+		b.WriteString("] ")
+		b.WriteString(c.Text)
+	}
+	return b.String()
+}
+
+// budget trims chunks to maxChars bytes of text in total.
+func budget(chunks []Chunk, maxChars int) []Chunk {
+	if maxChars <= 0 {
+		return chunks
+	}
+	left := maxChars
+	for i := range chunks {
+		if len(chunks[i].Text) <= left {
+			left -= len(chunks[i].Text)
+			continue
+		}
+		if left < 40 {
+			return chunks[:i]
+		}
+		chunks[i].Text = cut(chunks[i].Text, left)
+		return chunks[:i+1]
+	}
+	return chunks
+}
+
+// split breaks text into paragraphs and packs them into chunks of at most
+// n bytes, cutting paragraphs that are longer on their own.
+func split(text string, n int) []string {
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	var out []string
+	cur := ""
+	flush := func() {
+		if cur != "" {
+			out = append(out, cur)
+			cur = ""
+		}
+	}
+	for _, p := range strings.Split(text, "\n\n") {
+		p = strings.Join(strings.Fields(p), " ")
+		if p == "" {
+			continue
+		}
+		if cur != "" && len(cur)+1+len(p) <= n {
+			cur += "\n" + p
+			continue
+		}
+		flush()
+		for len(p) > n {
+			head := cut(p, n)
+			out = append(out, head)
+			p = strings.TrimSpace(p[len(head):])
+		}
+		cur = p
+	}
+	flush()
+	return out
+}
+
+// cut returns a prefix of s of at most n bytes, ending after a sentence or
+// at a word boundary when one is close enough.
+func cut(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	head := s[:n]
+	if i := strings.LastIndexAny(head, ".!?"); i >= n/2 {
+		return head[:i+1]
+	}
+	if i := strings.LastIndexByte(head, ' '); i >= n/2 {
+		return head[:i]
+	}
+	return head
+}
+
+var stopwords = map[string]bool{}
+
+func init() {
+	for _, w := range strings.Fields(`a about an and are as at be been but by can could did do does for from
+		had has have how if in is it its just me my of on or our so than that the their them then there
+		they this to too um uh us was we were what when where which who why will with would yeah you your`) {
+		stopwords[w] = true
+	}
+}
+
+// terms lowercases s and splits it into words, dropping stopwords and
+// single characters.
+func terms(s string) []string {
+	var out []string
+	for _, w := range strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '+' && r != '#'
+	}) {
+		if utf8.RuneCountInString(w) < 2 || stopwords[w] {
+			continue
+		}
+		out = append(out, w)
+	}
+	return out
+}
+
+func unique(ws []string) []string {
+	seen := map[string]bool{}
+	out := ws[:0]
+	for _, w := range ws {
+		if !seen[w] {
+			seen[w] = true
+			out = append(out, w)
+		}
+	}
+	return out
+}
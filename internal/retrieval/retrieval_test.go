@@ -0,0 +1,77 @@
+package retrieval
+
+import (
+	"strings"
+	"testing"
+
+	"yuzu/agent/internal/types"
+)
+
+var docs = []types.ContextDoc{
+	{Name: "jd", Kind: "job", Text: "Senior backend engineer.\n\nYou will own our Postgres clusters and the Go services on top of them.\n\nExperience with Kubernetes is a plus."},
+	{Name: "resume", Kind: "resume", Text: "Jane Doe, eight years in payments.\r\n\r\nLed the migration from MySQL to Postgres at Acme.\n\nWrote the React dashboard for merchants."},
+}
+
+func TestSelectRanksByOverlap(t *testing.T) {
+	ix := New(docs, 64)
+	if ix.Len() < 4 {
+		t.Fatalf("Len = %d, want paragraphs split at 64 bytes", ix.Len())
+	}
+	got, matched := ix.Select("Tell me about that Postgres migration you did", 2, 0)
+	if !matched || len(got) != 2 {
+		t.Fatalf("Select = %+v matched=%v", got, matched)
+	}
+	// "migration" only appears in the resume, so that chunk wins
+	if got[0].Doc != "resume" || !strings.Contains(got[0].Text, "MySQL to Postgres") {
+		t.Errorf("best chunk = %+v", got[0])
+	}
+	if !strings.Contains(got[1].Text, "Postgres") {
+		t.Errorf("second chunk = %+v", got[1])
+	}
+}
+
+func TestSelectFallsBackToLeads(t *testing.T) {
+	ix := New(docs, 64)
+	got, matched := ix.Select("um yeah so", 3, 0)
+	if matched || len(got) != 2 || got[0].Doc != "jd" || got[0].Seq != 0 || got[1].Doc != "resume" || got[1].Seq != 0 {
+		t.Fatalf("Select = %+v matched=%v, want each document's opening chunk", got, matched)
+	}
+}
+
+func TestSelectBudget(t *testing.T) {
+	ix := New(docs, 600)
+	got, _ := ix.Select("postgres go kubernetes react", 5, 60)
+	total := 0
+	for _, c := range got {
+		total += len(c.Text)
+	}
+	if len(got) == 0 || total > 60 {
+		t.Fatalf("Select = %+v (%d bytes), want at most 60", got, total)
+	}
+	if got, _ := New(nil, 0).Select("postgres", 3, 0); got != nil {
+		t.Errorf("empty index = %+v", got)
+	}
+}
+
+func TestSplitLongParagraph(t *testing.T) {
+	p := strings.Repeat("Built a thing that scaled well. ", 20)
+	chunks := split(p, 100)
+	if len(chunks) < 6 {
+		t.Fatalf("split = %d chunks", len(chunks))
+	}
+	for _, c := range chunks {
+		if len(c) > 100 || !strings.HasSuffix(c, ".") {
+			t.Errorf("chunk %q not cut at a sentence within 100 bytes", c)
+		}
+	}
+}
+
+func TestFormat(t *testing.T) {
+	if Format(nil) != "" {
+		t.Error("Format(nil) should be empty")
+	}
+	out := Format([]Chunk{{Doc: "resume", Kind: "resume", Text: "Ten years of Go."}, {Doc: "jd", Kind: "job", Text: "Go role."}})
+	if !strings.Contains(out, "[resume] Ten years of Go.") || !strings.Contains(out, "[jd (job)] Go role.") {
+		t.Errorf("Format = %q", out)
+	}
+}
@@ -0,0 +1,147 @@
+package secrets
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// AWSSecretsManager reads secrets from one Secrets Manager secret whose
+// SecretString is a JSON object of names to values. It is configured by
+// SECRETS_AWS_SECRET_ID, AWS_REGION and the usual AWS_ACCESS_KEY_ID,
+// AWS_SECRET_ACCESS_KEY and AWS_SESSION_TOKEN; requests are signed with
+// SigV4 directly rather than through the SDK.
+type AWSSecretsManager struct {
+	Region       string
+	SecretID     string
+	AccessKey    string
+	SecretKey    string
+	SessionToken string
+	Endpoint     string // default https://secretsmanager.<region>.amazonaws.com
+	HTTP         *http.Client
+	now          func() time.Time
+}
+
+func AWSFromEnv() *AWSSecretsManager {
+	region := os.Getenv("AWS_REGION")
+	if region == "" {
+		region = os.Getenv("AWS_DEFAULT_REGION")
+	}
+	return &AWSSecretsManager{
+		Region:       region,
+		SecretID:     os.Getenv("SECRETS_AWS_SECRET_ID"),
+		AccessKey:    os.Getenv("AWS_ACCESS_KEY_ID"),
+		SecretKey:    os.Getenv("AWS_SECRET_ACCESS_KEY"),
+		SessionToken: os.Getenv("AWS_SESSION_TOKEN"),
+		Endpoint:     os.Getenv("SECRETS_AWS_ENDPOINT"),
+		HTTP:         &http.Client{Timeout: 5 * time.Second},
+	}
+}
+
+func (a *AWSSecretsManager) Get(ctx context.Context, name string) (string, error) {
+	if a.Region == "" || a.SecretID == "" || a.AccessKey == "" || a.SecretKey == "" {
+		return "", fmt.Errorf("aws: AWS_REGION, SECRETS_AWS_SECRET_ID and credentials are required")
+	}
+	endpoint := a.Endpoint
+	if endpoint == "" {
+		endpoint = "https://secretsmanager." + a.Region + ".amazonaws.com"
+	}
+	payload, _ := json.Marshal(map[string]string{"SecretId": a.SecretID})
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimRight(endpoint, "/")+"/", bytes.NewReader(payload))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-amz-json-1.1")
+	req.Header.Set("X-Amz-Target", "secretsmanager.GetSecretValue")
+	now := time.Now
+	if a.now != nil {
+		now = a.now
+	}
+	a.sign(req, payload, now().UTC())
+	resp, err := a.HTTP.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("aws: %w", err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if resp.StatusCode/100 != 2 {
+		return "", fmt.Errorf("aws: status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	var out struct {
+		SecretString string `json:"SecretString"`
+	}
+	if err := json.Unmarshal(body, &out); err != nil {
+		return "", fmt.Errorf("aws: %w", err)
+	}
+	var values map[string]any
+	if err := json.Unmarshal([]byte(out.SecretString), &values); err != nil {
+		return "", fmt.Errorf("aws: secret %s is not a JSON object", a.SecretID)
+	}
+	s, ok := values[name].(string)
+	if !ok || s == "" {
+		return "", ErrNotFound
+	}
+	return s, nil
+}
+
+// sign adds a SigV4 Authorization header for the secretsmanager service.
+func (a *AWSSecretsManager) sign(req *http.Request, payload []byte, t time.Time) {
+	amzDate := t.Format("20060102T150405Z")
+	day := t.Format("20060102")
+	req.Header.Set("X-Amz-Date", amzDate)
+	if a.SessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", a.SessionToken)
+	}
+	payloadHash := sha256Hex(payload)
+	signed := []string{"content-type", "host", "x-amz-date", "x-amz-target"}
+	headers := map[string]string{
+		"content-type": req.Header.Get("Content-Type"),
+		"host":         req.URL.Host,
+		"x-amz-date":   amzDate,
+		"x-amz-target": req.Header.Get("X-Amz-Target"),
+	}
+	if a.SessionToken != "" {
+		signed = append(signed, "x-amz-security-token")
+		headers["x-amz-security-token"] = a.SessionToken
+	}
+	sort.Strings(signed)
+	var canonHeaders strings.Builder
+	for _, h := range signed {
+		canonHeaders.WriteString(h + ":" + strings.TrimSpace(headers[h]) + "\n")
+	}
+	signedHeaders := strings.Join(signed, ";")
+	path := req.URL.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	canonical := strings.Join([]string{req.Method, path, req.URL.RawQuery, canonHeaders.String(), signedHeaders, payloadHash}, "\n")
+	scope := day + "/" + a.Region + "/secretsmanager/aws4_request"
+	toSign := strings.Join([]string{"AWS4-HMAC-SHA256", amzDate, scope, sha256Hex([]byte(canonical))}, "\n")
+	key := hmacSHA256([]byte("AWS4"+a.SecretKey), day)
+	key = hmacSHA256(key, a.Region)
+	key = hmacSHA256(key, "secretsmanager")
+	key = hmacSHA256(key, "aws4_request")
+	sig := hex.EncodeToString(hmacSHA256(key, toSign))
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s", a.AccessKey, scope, signedHeaders, sig))
+}
+
+func sha256Hex(b []byte) string {
+	h := sha256.Sum256(b)
+	return hex.EncodeToString(h[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	m := hmac.New(sha256.New, key)
+	m.Write([]byte(data))
+	return m.Sum(nil)
+}
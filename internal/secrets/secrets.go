@@ -0,0 +1,196 @@
+// Package secrets resolves API keys and other credentials from a
+// configurable backend, so they need not sit in the process environment.
+//
+// SECRETS_PROVIDER picks the backend:
+//
+//	env    os.Getenv (default)
+//	file   one file per secret in SECRETS_DIR (e.g. a mounted Kubernetes
+//	       secret); NAME_FILE, when set, points at one secret's file
+//	vault  a HashiCorp Vault KV v2 secret (see vault.go)
+//	aws    an AWS Secrets Manager secret holding a JSON object (see aws.go)
+//
+// A name the backend doesn't have falls back to the environment. Values from
+// file, vault and aws are cached for SECRETS_TTL_S (default 300) and then
+// re-read, so a rotated key is picked up without a redeploy; callers that see
+// an auth failure call Invalidate to re-read at once. When a refresh fails
+// the last good value stays in use.
+package secrets
+
+import (
+	"context"
+	"errors"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrNotFound reports that a provider has no secret by that name.
+var ErrNotFound = errors.New("secret not found")
+
+// Provider looks up one secret by name (the env var it replaces, e.g.
+// "AZURE_OPENAI_API_KEY").
+type Provider interface {
+	Get(ctx context.Context, name string) (string, error)
+}
+
+// Env reads secrets from the process environment.
+type Env struct{}
+
+func (Env) Get(_ context.Context, name string) (string, error) {
+	if v := os.Getenv(name); v != "" {
+		return v, nil
+	}
+	return "", ErrNotFound
+}
+
+// File reads name from the file named by NAME_FILE, else from Dir/name.
+type File struct {
+	Dir string
+}
+
+func (f File) Get(_ context.Context, name string) (string, error) {
+	path := os.Getenv(name + "_FILE")
+	if path == "" {
+		if f.Dir == "" {
+			return "", ErrNotFound
+		}
+		path = filepath.Join(f.Dir, name)
+	}
+	b, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return "", ErrNotFound
+	}
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(b)), nil
+}
+
+// Chain asks each provider in turn until one has the secret.
+type Chain []Provider
+
+func (c Chain) Get(ctx context.Context, name string) (string, error) {
+	for _, p := range c {
+		v, err := p.Get(ctx, name)
+		if !errors.Is(err, ErrNotFound) {
+			return v, err
+		}
+	}
+	return "", ErrNotFound
+}
+
+// Cache remembers values from a Provider for a TTL. A TTL of zero disables
+// caching.
+type Cache struct {
+	p   Provider
+	ttl time.Duration
+	now func() time.Time
+
+	mu sync.Mutex
+	m  map[string]cached
+}
+
+type cached struct {
+	value string
+	at    time.Time
+}
+
+func NewCache(p Provider, ttl time.Duration) *Cache {
+	return &Cache{p: p, ttl: ttl, now: time.Now, m: map[string]cached{}}
+}
+
+// Get returns name, from the cache while it is fresh.
+func (c *Cache) Get(ctx context.Context, name string) (string, error) {
+	if c.ttl <= 0 {
+		return c.p.Get(ctx, name)
+	}
+	c.mu.Lock()
+	e, ok := c.m[name]
+	c.mu.Unlock()
+	if ok && c.now().Sub(e.at) < c.ttl {
+		return e.value, nil
+	}
+	v, err := c.p.Get(ctx, name)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err != nil {
+		if !ok || errors.Is(err, ErrNotFound) {
+			delete(c.m, name)
+			return "", err
+		}
+		// Keep serving the last good value; try again after another TTL
+		log.Printf("[secrets] refresh %s failed, keeping cached value: %v", name, err)
+		c.m[name] = cached{value: e.value, at: c.now()}
+		return e.value, nil
+	}
+	if ok && v != e.value {
+		log.Printf("[secrets] %s rotated", name)
+	}
+	c.m[name] = cached{value: v, at: c.now()}
+	return v, nil
+}
+
+// Invalidate drops name so the next Get reads it from the provider.
+func (c *Cache) Invalidate(name string) {
+	c.mu.Lock()
+	delete(c.m, name)
+	c.mu.Unlock()
+}
+
+// FromEnv builds the cache SECRETS_PROVIDER describes.
+func FromEnv() *Cache {
+	ttl := 300 * time.Second
+	if n, err := strconv.Atoi(os.Getenv("SECRETS_TTL_S")); err == nil && n >= 0 {
+		ttl = time.Duration(n) * time.Second
+	}
+	var p Provider
+	switch kind := strings.ToLower(strings.TrimSpace(os.Getenv("SECRETS_PROVIDER"))); kind {
+	case "", "env":
+		// The environment is read directly; caching would only hide changes
+		return NewCache(Env{}, 0)
+	case "file":
+		p = File{Dir: os.Getenv("SECRETS_DIR")}
+	case "vault":
+		p = VaultFromEnv()
+	case "aws":
+		p = AWSFromEnv()
+	default:
+		log.Printf("[secrets] unknown SECRETS_PROVIDER=%q, using env", kind)
+		return NewCache(Env{}, 0)
+	}
+	return NewCache(Chain{p, Env{}}, ttl)
+}
+
+var (
+	defaultOnce  sync.Once
+	defaultCache *Cache
+)
+
+// Default is the process-wide cache, built by FromEnv on first use.
+func Default() *Cache {
+	defaultOnce.Do(func() { defaultCache = FromEnv() })
+	return defaultCache
+}
+
+// Get returns the secret name from the default cache, or "" when it is not
+// set anywhere. It is the drop-in for os.Getenv at call sites.
+func Get(name string) string {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	v, err := Default().Get(ctx, name)
+	if err != nil {
+		if !errors.Is(err, ErrNotFound) {
+			log.Printf("[secrets] %s: %v", name, err)
+		}
+		return ""
+	}
+	return v
+}
+
+// Invalidate drops name from the default cache, e.g. after the provider
+// rejected it.
+func Invalidate(name string) { Default().Invalidate(name) }
@@ -0,0 +1,126 @@
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+type countingProvider struct {
+	values map[string]string
+	err    error
+	calls  int
+}
+
+func (p *countingProvider) Get(_ context.Context, name string) (string, error) {
+	p.calls++
+	if p.err != nil {
+		return "", p.err
+	}
+	if v, ok := p.values[name]; ok {
+		return v, nil
+	}
+	return "", ErrNotFound
+}
+
+func TestCacheRotatesAndKeepsLastGood(t *testing.T) {
+	p := &countingProvider{values: map[string]string{"KEY": "v1"}}
+	c := NewCache(p, time.Minute)
+	now := time.Unix(1700000000, 0)
+	c.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	if v, _ := c.Get(ctx, "KEY"); v != "v1" {
+		t.Fatalf("first get = %q", v)
+	}
+	p.values["KEY"] = "v2"
+	if v, _ := c.Get(ctx, "KEY"); v != "v1" || p.calls != 1 {
+		t.Fatalf("within TTL = %q after %d calls, want cached v1", v, p.calls)
+	}
+	now = now.Add(time.Minute)
+	if v, _ := c.Get(ctx, "KEY"); v != "v2" {
+		t.Fatalf("after TTL = %q, want rotated v2", v)
+	}
+
+	// The backend going away keeps the last good value
+	p.err = errors.New("connection refused")
+	now = now.Add(time.Minute)
+	if v, err := c.Get(ctx, "KEY"); v != "v2" || err != nil {
+		t.Fatalf("failed refresh = %q, %v", v, err)
+	}
+
+	// Invalidate forces a read
+	p.err = nil
+	p.values["KEY"] = "v3"
+	c.Invalidate("KEY")
+	if v, _ := c.Get(ctx, "KEY"); v != "v3" {
+		t.Fatalf("after Invalidate = %q", v)
+	}
+	if _, err := c.Get(ctx, "OTHER"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("missing name err = %v", err)
+	}
+}
+
+func TestFileAndChain(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "DEEPGRAM_API_KEY"), []byte("dg-file\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("AZURE_OPENAI_API_KEY", "az-env")
+	c := Chain{File{Dir: dir}, Env{}}
+	ctx := context.Background()
+	if v, _ := c.Get(ctx, "DEEPGRAM_API_KEY"); v != "dg-file" {
+		t.Errorf("file secret = %q", v)
+	}
+	if v, _ := c.Get(ctx, "AZURE_OPENAI_API_KEY"); v != "az-env" {
+		t.Errorf("env fallback = %q", v)
+	}
+	if _, err := c.Get(ctx, "ELEVENLABS_API_KEY"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("missing err = %v", err)
+	}
+}
+
+func TestVaultKV2(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/secret/data/yuzu/prod" || r.Header.Get("X-Vault-Token") != "root" {
+			http.NotFound(w, r)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"data": map[string]any{"data": map[string]any{"AZURE_OPENAI_API_KEY": "az-vault"}}})
+	}))
+	defer srv.Close()
+	v := &Vault{Addr: srv.URL, Token: "root", Mount: "secret", Path: "yuzu/prod", HTTP: srv.Client()}
+	if got, err := v.Get(context.Background(), "AZURE_OPENAI_API_KEY"); got != "az-vault" || err != nil {
+		t.Fatalf("vault get = %q, %v", got, err)
+	}
+	if _, err := v.Get(context.Background(), "DEEPGRAM_API_KEY"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("vault missing err = %v", err)
+	}
+}
+
+func TestAWSSecretsManager(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth := r.Header.Get("Authorization")
+		if r.Header.Get("X-Amz-Target") != "secretsmanager.GetSecretValue" ||
+			!strings.HasPrefix(auth, "AWS4-HMAC-SHA256 Credential=AKID/20231114/us-east-1/secretsmanager/aws4_request, SignedHeaders=content-type;host;x-amz-date;x-amz-security-token;x-amz-target, Signature=") {
+			http.Error(w, "bad signature: "+auth, http.StatusForbidden)
+			return
+		}
+		var in struct{ SecretId string }
+		_ = json.NewDecoder(r.Body).Decode(&in)
+		_ = json.NewEncoder(w).Encode(map[string]string{"SecretString": `{"ELEVENLABS_API_KEY":"el-aws"}`, "Name": in.SecretId})
+	}))
+	defer srv.Close()
+	a := &AWSSecretsManager{Region: "us-east-1", SecretID: "yuzu/prod", AccessKey: "AKID", SecretKey: "secret", SessionToken: "tok",
+		Endpoint: srv.URL, HTTP: srv.Client(), now: func() time.Time { return time.Unix(1700000000, 0) }}
+	if got, err := a.Get(context.Background(), "ELEVENLABS_API_KEY"); got != "el-aws" || err != nil {
+		t.Fatalf("aws get = %q, %v", got, err)
+	}
+}
@@ -0,0 +1,75 @@
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// Vault reads secrets from one KV version 2 secret: VAULT_ADDR, VAULT_TOKEN,
+// SECRETS_VAULT_MOUNT (default "secret") and SECRETS_VAULT_PATH (e.g.
+// "yuzu/prod"). Each key of the secret is one name, e.g.
+// {"AZURE_OPENAI_API_KEY": "..."}.
+type Vault struct {
+	Addr  string
+	Token string
+	Mount string
+	Path  string
+	HTTP  *http.Client
+}
+
+func VaultFromEnv() *Vault {
+	mount := os.Getenv("SECRETS_VAULT_MOUNT")
+	if mount == "" {
+		mount = "secret"
+	}
+	return &Vault{
+		Addr:  os.Getenv("VAULT_ADDR"),
+		Token: os.Getenv("VAULT_TOKEN"),
+		Mount: mount,
+		Path:  os.Getenv("SECRETS_VAULT_PATH"),
+		HTTP:  &http.Client{Timeout: 5 * time.Second},
+	}
+}
+
+func (v *Vault) Get(ctx context.Context, name string) (string, error) {
+	if v.Addr == "" || v.Path == "" {
+		return "", fmt.Errorf("vault: VAULT_ADDR and SECRETS_VAULT_PATH are required")
+	}
+	url := fmt.Sprintf("%s/v1/%s/data/%s", strings.TrimRight(v.Addr, "/"), strings.Trim(v.Mount, "/"), strings.Trim(v.Path, "/"))
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("X-Vault-Token", v.Token)
+	resp, err := v.HTTP.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("vault: %w", err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if resp.StatusCode == http.StatusNotFound {
+		return "", ErrNotFound
+	}
+	if resp.StatusCode/100 != 2 {
+		return "", fmt.Errorf("vault: status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	var out struct {
+		Data struct {
+			Data map[string]any `json:"data"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(body, &out); err != nil {
+		return "", fmt.Errorf("vault: %w", err)
+	}
+	s, ok := out.Data.Data[name].(string)
+	if !ok || s == "" {
+		return "", ErrNotFound
+	}
+	return s, nil
+}
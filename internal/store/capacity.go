@@ -0,0 +1,130 @@
+package store
+
+import (
+	"container/list"
+	"time"
+
+	"yuzu/agent/internal/errdefs"
+	"yuzu/agent/internal/types"
+)
+
+// capacity.go bounds the store so a burst of sessions cannot exhaust memory.
+// Sessions are kept in least-recently-active order; when the cap is reached
+// the oldest ended session is evicted with its events. A session has ended
+// once it is completed or its bot has exited; one just created, whose bot
+// has not started yet, is still live. If no session has ended, creation
+// fails with errdefs.Backpressure.
+
+// ErrStoreFull is returned by CreateSession when no session can be evicted.
+var ErrStoreFull = &errdefs.Backpressure{Resource: "sessions", RetryAfter: 30 * time.Second}
+
+// Fixed per-object overheads for the memory estimate; payload strings are
+// counted by length, other values by a flat guess.
+const (
+	sessionOverheadBytes = 512
+	eventOverheadBytes   = 96
+	valueOverheadBytes   = 16
+	networkSampleBytes   = 112
+)
+
+// touch marks id as most recently active. Callers hold s.mu.
+func (s *Memory) touch(id string) {
+	if e, ok := s.lru[id]; ok {
+		s.order.MoveToFront(e)
+		return
+	}
+	s.lru[id] = s.order.PushFront(id)
+}
+
+// evictOne drops the least recently active ended session. Callers hold s.mu.
+func (s *Memory) evictOne() bool {
+	for e := s.order.Back(); e != nil; e = e.Prev() {
+		id := e.Value.(string)
+		if !s.ended(id) {
+			continue
+		}
+		s.remove(id, e)
+		metricEvictions.Inc()
+		return true
+	}
+	return false
+}
+
+// ended reports whether id is done with: completed, or its bot has exited
+// and not been restarted. Callers hold s.mu.
+func (s *Memory) ended(id string) bool {
+	sess, ok := s.sessions[id]
+	if !ok || s.botRunning[id] {
+		return false
+	}
+	return sess.Status == "completed" || sess.BotLastExitAt != nil
+}
+
+// remove deletes everything held for id. Callers hold s.mu.
+func (s *Memory) remove(id string, e *list.Element) {
+	s.order.Remove(e)
+	delete(s.lru, id)
+	s.eventCount -= len(s.events[id])
+	s.memBytes -= s.sessBytes[id]
+	delete(s.sessions, id)
+	delete(s.events, id)
+	delete(s.appended, id)
+	delete(s.seqs, id)
+	delete(s.retainedBy, id)
+	delete(s.netStats, id)
+	delete(s.contextDocs, id)
+	delete(s.sessBytes, id)
+	delete(s.botRunning, id)
+	delete(s.workerState, id)
+	s.search.dropSession(id, false)
+	s.updateGauges()
+}
+
+// updateGauges publishes the current totals. Callers hold s.mu.
+func (s *Memory) updateGauges() {
+	metricSessions.Set(float64(len(s.sessions)))
+	metricEvents.Set(float64(s.eventCount))
+	metricMemoryBytes.Set(float64(s.memBytes))
+}
+
+// Stats is a snapshot of store usage.
+type Stats struct {
+	Sessions    int   `json:"sessions"`
+	Events      int   `json:"events"`
+	MemoryBytes int64 `json:"memory_bytes_estimate"`
+	MaxSessions int   `json:"max_sessions"`
+}
+
+func (s *Memory) Stats() Stats {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return Stats{Sessions: len(s.sessions), Events: s.eventCount, MemoryBytes: s.memBytes, MaxSessions: s.maxSessions}
+}
+
+func estimateEvent(e types.Event) int64 {
+	n := int64(eventOverheadBytes + len(e.Type))
+	for k, v := range e.Payload {
+		n += int64(len(k)) + valueSize(v)
+	}
+	return n
+}
+
+func valueSize(v any) int64 {
+	switch t := v.(type) {
+	case string:
+		return int64(len(t)) + valueOverheadBytes
+	case map[string]any:
+		var n int64 = valueOverheadBytes
+		for k, x := range t {
+			n += int64(len(k)) + valueSize(x)
+		}
+		return n
+	case []any:
+		var n int64 = valueOverheadBytes
+		for _, x := range t {
+			n += valueSize(x)
+		}
+		return n
+	}
+	return valueOverheadBytes
+}
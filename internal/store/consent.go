@@ -0,0 +1,76 @@
+package store
+
+import (
+	"log"
+
+	"yuzu/agent/internal/types"
+)
+
+// Consent is a session's own setting (types.Consent), read by every
+// subsystem that would keep something of the call. A subsystem that is
+// asked to keep what the session opted out of calls Reject, which leaves a
+// recording_rejected event in the session's log as the audit trail and
+// counts store_recording_rejected_total{what}.
+
+// GetConsent returns what the session opted out of; the zero Consent for
+// unknown sessions.
+func (s *Memory) GetConsent(sessionID string) types.Consent {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if sess, ok := s.sessions[sessionID]; ok {
+		return sess.Consent
+	}
+	return types.Consent{}
+}
+
+// RedactTranscripts strips the text from the session's kept transcript
+// events and returns how many it changed. Nothing of the session stays in
+// the search index.
+func (s *Memory) RedactTranscripts(sessionID string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	n := 0
+	for i, e := range s.events[sessionID] {
+		if !types.TranscriptEvent(e.Type) || e.Payload["redacted"] == true {
+			continue
+		}
+		red := e
+		red.Payload = RedactPayload(e.Payload)
+		diff := estimateEvent(red) - estimateEvent(e)
+		s.events[sessionID][i] = red
+		s.sessBytes[sessionID] += diff
+		s.memBytes += diff
+		n++
+	}
+	// Summary lines go from the search index with the event text
+	for k := range s.search.bySession[sessionID] {
+		if k.id < 0 {
+			size := estimateUtterance(*s.search.docs[k])
+			s.sessBytes[sessionID] -= size
+			s.memBytes -= size
+		}
+	}
+	s.search.dropSession(sessionID, false)
+	s.updateGauges()
+	return n
+}
+
+// RedactPayload copies a transcript event's payload without its text.
+func RedactPayload(p map[string]any) map[string]any {
+	out := make(map[string]any, len(p)+1)
+	for k, v := range p {
+		if k != "text" {
+			out[k] = v
+		}
+	}
+	out["redacted"] = true
+	return out
+}
+
+// Reject records that sessionID's consent refused what, e.g.
+// "transcript_export".
+func Reject(st Store, sessionID, what string) {
+	log.Printf("[store] recording rejected session=%s what=%s (consent)", sessionID, what)
+	metricRecordingRejected.WithLabelValues(what).Inc()
+	st.AppendEvent(sessionID, "recording_rejected", map[string]any{"what": what, "reason": "consent"})
+}
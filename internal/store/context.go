@@ -0,0 +1,57 @@
+package store
+
+import (
+	"errors"
+
+	"yuzu/agent/internal/types"
+)
+
+// Context documents belong to their session: they count towards its memory
+// estimate and are evicted with it. The session limits
+// (types.CheckContextDocs) are checked against the documents the put would
+// leave, under the same lock as the put, so concurrent uploads can't
+// overshoot them.
+
+// ErrNoSession is returned for a session the store doesn't hold.
+var ErrNoSession = errors.New("session not found")
+
+func (s *Memory) PutContextDoc(sessionID string, doc types.ContextDoc) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.sessions[sessionID]; !ok {
+		return ErrNoSession
+	}
+	size := estimateContextDoc(doc)
+	docs := append([]types.ContextDoc(nil), s.contextDocs[sessionID]...)
+	replaced := false
+	for i, d := range docs {
+		if d.Name == doc.Name {
+			size -= estimateContextDoc(d)
+			docs[i] = doc
+			replaced = true
+			break
+		}
+	}
+	if !replaced {
+		docs = append(docs, doc)
+	}
+	if err := types.CheckContextDocs(docs); err != nil {
+		return err
+	}
+	s.contextDocs[sessionID] = docs
+	s.sessBytes[sessionID] += size
+	s.memBytes += size
+	s.touch(sessionID)
+	s.updateGauges()
+	return nil
+}
+
+func (s *Memory) ListContextDocs(sessionID string) []types.ContextDoc {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return append([]types.ContextDoc(nil), s.contextDocs[sessionID]...)
+}
+
+func estimateContextDoc(d types.ContextDoc) int64 {
+	return valueOverheadBytes + int64(len(d.Name)+len(d.Kind)+len(d.Text))
+}
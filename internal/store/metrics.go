@@ -0,0 +1,73 @@
+package store
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	metricSessions = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "store_sessions",
+		Help: "Sessions held in the in-memory store",
+	})
+
+	metricEvents = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "store_events",
+		Help: "Events held across all sessions",
+	})
+
+	metricMemoryBytes = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "store_memory_bytes_estimate",
+		Help: "Rough estimate of memory held by sessions and their events",
+	})
+
+	metricEvictions = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "store_evictions_total",
+		Help: "Ended sessions evicted to stay under the session cap",
+	})
+
+	metricRejections = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "store_session_rejections_total",
+		Help: "Sessions refused because the store was full of active sessions",
+	})
+
+	// Write-behind persistence (see writebehind.go)
+	metricWriteBehindQueue = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "store_writebehind_queue_depth",
+		Help: "Records waiting to be written to the persistence backend",
+	})
+
+	metricWriteBehindRecords = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "store_writebehind_records_total",
+		Help: "Records handled by the write-behind buffer (written, failed: kept for a retry, dropped: queue full or closed)",
+	}, []string{"outcome"})
+
+	metricWriteBehindFlush = promauto.NewHistogram(prometheus.HistogramOpts{
+		Name:    "store_writebehind_flush_seconds",
+		Help:    "Time to write one batch to the persistence backend",
+		Buckets: prometheus.ExponentialBuckets(0.0005, 2, 14),
+	})
+
+	metricWriteBehindSync = promauto.NewHistogram(prometheus.HistogramOpts{
+		Name:    "store_writebehind_sync_seconds",
+		Help:    "Time to make written batches durable (fsync, commit)",
+		Buckets: prometheus.ExponentialBuckets(0.0005, 2, 14),
+	})
+
+	// Recordings refused by a session's consent (see consent.go)
+	metricRecordingRejected = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "store_recording_rejected_total",
+		Help: "Attempts to keep something a session opted out of, by what (transcript_persist, transcript_export, ...)",
+	}, []string{"what"})
+
+	// Per-class event retention and spill (see retention.go)
+	metricEventsTruncated = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "store_events_truncated_total",
+		Help: "Events dropped from sessions' logs by STORE_EVENT_RETENTION, by class or event type",
+	}, []string{"class"})
+
+	metricEventsSpilled = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "store_events_spilled_total",
+		Help: "Dropped events written to STORE_EVENT_SPILL_PATH (written), lost to a write error (failed) or to a full spill queue (dropped)",
+	}, []string{"outcome"})
+)
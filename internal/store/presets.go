@@ -0,0 +1,69 @@
+package store
+
+import (
+	"errors"
+	"sort"
+	"time"
+
+	"yuzu/agent/internal/types"
+)
+
+// Presets are small and operator-managed, so they are kept outside the
+// session capacity accounting and are never evicted.
+
+var ErrPresetExists = errors.New("preset already exists")
+
+func (s *Memory) PutPreset(tenantID string, p types.Preset) types.Preset {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.putPreset(tenantID, p)
+}
+
+func (s *Memory) CreatePreset(tenantID string, p types.Preset) (types.Preset, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.presets[tenantID][p.Name]; ok {
+		return types.Preset{}, ErrPresetExists
+	}
+	return s.putPreset(tenantID, p), nil
+}
+
+func (s *Memory) putPreset(tenantID string, p types.Preset) types.Preset {
+	if s.presets[tenantID] == nil {
+		s.presets[tenantID] = make(map[string]types.Preset)
+	}
+	p.UpdatedAt = time.Now().UTC()
+	p.Flow = append([]byte(nil), p.Flow...)
+	s.presets[tenantID][p.Name] = p
+	return p
+}
+
+func (s *Memory) GetPreset(tenantID, name string) (types.Preset, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	p, ok := s.presets[tenantID][name]
+	p.Flow = append([]byte(nil), p.Flow...)
+	return p, ok
+}
+
+func (s *Memory) ListPresets(tenantID string) []types.Preset {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	out := make([]types.Preset, 0, len(s.presets[tenantID]))
+	for _, p := range s.presets[tenantID] {
+		p.Flow = append([]byte(nil), p.Flow...)
+		out = append(out, p)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out
+}
+
+func (s *Memory) DeletePreset(tenantID, name string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.presets[tenantID][name]; !ok {
+		return false
+	}
+	delete(s.presets[tenantID], name)
+	return true
+}
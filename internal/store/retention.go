@@ -0,0 +1,267 @@
+package store
+
+import (
+	"context"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"yuzu/agent/internal/types"
+)
+
+// retention.go decides how much of a session's event log Memory keeps.
+// Limits are per class of event, set by STORE_EVENT_RETENTION as
+// "class=limit,..." over DefaultRetention: finals and barge-ins are kept
+// whole, while the high-rate classes (bot logs, audio features) keep their
+// latest 200, so a long call loses its chatter rather than its transcript.
+// A key may also name a single event type, which then gets its own limit
+// apart from its class. A limit of "all" (or 0) keeps every event.
+//
+// When a class is over its limit, its oldest events are dropped and one
+// events_truncated event per class ({class, dropped, kept}) stands in for
+// them. It is replaced, with a new index, each time the class drops more,
+// so the class's kept events plus the warning stay within its limit.
+// Event indexes (ListEventsSince) are not affected. With
+// STORE_EVENT_SPILL_PATH set, dropped events are appended there as JSON
+// lines (the write-behind Record format, without fsync) instead of being
+// lost. The writes happen on a goroutine of their own, fed by a queue of
+// spillQueueLen batches, so AppendEvent never waits on the disk; a batch
+// that finds the queue full is dropped. Transcripts of sessions that opted
+// out of retention are spilled without their text. Dropped events are
+// counted in store_events_truncated_total{class}, and spilled ones in
+// store_events_spilled_total{outcome}.
+
+// Event classes with a retention limit of their own.
+const (
+	ClassFinal   = "final"    // transcript_final, agent_text
+	ClassBargeIn = "barge_in" // barge_in_*
+	ClassLog     = "log"      // bot_log and other *_log
+	ClassFeature = "feature"  // feature, vad_start/vad_end, webrtc_stats
+	ClassDefault = "default"  // everything else
+)
+
+// DefaultRetention is the limit for each class unless overridden.
+const DefaultRetention = "final=all,barge_in=all,log=200,feature=200,default=200"
+
+const eventsTruncated = "events_truncated"
+
+// spillQueueLen bounds the batches waiting for the spill writer.
+const spillQueueLen = 256
+
+// Retention maps a class or event type to the events a session keeps of
+// it; 0 keeps all.
+type Retention map[string]int
+
+// ParseRetention parses "class=limit,..." over DefaultRetention. Bad
+// entries are logged and skipped.
+func ParseRetention(v string) Retention {
+	out := Retention{}
+	for _, src := range []string{DefaultRetention, v} {
+		for _, kv := range strings.Split(src, ",") {
+			if strings.TrimSpace(kv) == "" {
+				continue
+			}
+			key, lim, ok := strings.Cut(strings.TrimSpace(kv), "=")
+			key, lim = strings.TrimSpace(key), strings.TrimSpace(lim)
+			if lim == "all" {
+				lim = "0"
+			}
+			n, err := strconv.Atoi(lim)
+			if !ok || key == "" || key == eventsTruncated || err != nil || n < 0 {
+				log.Printf("[store] STORE_EVENT_RETENTION: ignoring %q", kv)
+				continue
+			}
+			out[key] = n
+		}
+	}
+	return out
+}
+
+// eventClass groups event types that share a limit.
+func eventClass(typ string) string {
+	switch {
+	case typ == "transcript_final" || typ == "agent_text":
+		return ClassFinal
+	case strings.HasPrefix(typ, "barge_in"):
+		return ClassBargeIn
+	case typ == "bot_log" || strings.HasSuffix(typ, "_log"):
+		return ClassLog
+	case typ == "feature" || typ == "vad_start" || typ == "vad_end" || typ == "webrtc_stats":
+		return ClassFeature
+	}
+	return ClassDefault
+}
+
+// limit returns the key typ's events are counted under and its limit.
+func (r Retention) limit(typ string) (string, int) {
+	if n, ok := r[typ]; ok {
+		return typ, n
+	}
+	class := eventClass(typ)
+	if n, ok := r[class]; ok {
+		return class, n
+	}
+	return class, r[ClassDefault]
+}
+
+// retained is what a session's log holds of one retention key.
+type retained struct {
+	kept    int // events kept, not counting the warning
+	dropped int
+	warnSeq int // index of the events_truncated event; -1 when none
+}
+
+// SetRetention replaces the per-class limits and where dropped events
+// spill to; a nil spill discards them. Call StopSpill before closing spill.
+func (s *Memory) SetRetention(r Retention, spill Persister) {
+	s.mu.Lock()
+	s.retention = r
+	old := s.spill
+	s.spill = nil
+	if spill != nil {
+		s.spill = newSpiller(spill)
+	}
+	s.mu.Unlock()
+	if old != nil {
+		old.stop()
+	}
+}
+
+// StopSpill writes the batches still queued for the spill and stops its
+// writer, or gives up when ctx ends first. Later dropped events are
+// discarded.
+func (s *Memory) StopSpill(ctx context.Context) error {
+	s.mu.Lock()
+	sp := s.spill
+	s.spill = nil
+	s.mu.Unlock()
+	if sp == nil {
+		return nil
+	}
+	close(sp.queue)
+	select {
+	case <-sp.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// retain enforces the limit for the event of type typ just appended,
+// returning the change in the session's size estimate and the events to
+// spill. Callers hold s.mu.
+func (s *Memory) retain(sessionID, typ string) (int64, []Record) {
+	key, limit := s.retention.limit(typ)
+	byKey := s.retainedBy[sessionID]
+	if byKey == nil {
+		byKey = map[string]*retained{}
+		s.retainedBy[sessionID] = byKey
+	}
+	r := byKey[key]
+	if r == nil {
+		r = &retained{warnSeq: -1}
+		byKey[key] = r
+	}
+	r.kept++
+	held := r.kept
+	if r.warnSeq >= 0 {
+		held++
+	}
+	if limit <= 0 || held <= limit {
+		return 0, nil
+	}
+
+	// Drop the oldest down to limit-1, leaving room for the warning
+	dropped := r.kept - (limit - 1)
+	n := dropped
+	evs, seqs := s.events[sessionID], s.seqs[sessionID]
+	var size int64
+	var spill []Record
+	noText := false
+	if sess, ok := s.sessions[sessionID]; ok {
+		noText = sess.Consent.NoTranscriptRetention
+	}
+	j := 0
+	for i, e := range evs {
+		drop := n > 0 && e.Type != eventsTruncated && s.retention.keyOf(e.Type) == key
+		if r.warnSeq >= 0 && seqs[i] == r.warnSeq {
+			drop = true
+		} else if drop {
+			n--
+			if s.spill != nil {
+				if noText && types.TranscriptEvent(e.Type) {
+					e.Payload = RedactPayload(e.Payload)
+				}
+				spill = append(spill, Record{Kind: "event", SessionID: sessionID, Event: &e})
+			}
+		}
+		if drop {
+			size -= estimateEvent(evs[i])
+			s.search.remove(docKey{sessionID, seqs[i]})
+			continue
+		}
+		evs[j], seqs[j] = evs[i], seqs[i]
+		j++
+	}
+	r.kept -= dropped
+	r.dropped += dropped
+	metricEventsTruncated.WithLabelValues(key).Add(float64(dropped))
+
+	warn := types.Event{Type: eventsTruncated, Ts: time.Now().UTC(), Payload: map[string]any{
+		"session_id": sessionID, "class": key, "dropped": r.dropped, "kept": r.kept,
+	}}
+	r.warnSeq = s.appended[sessionID]
+	s.appended[sessionID]++
+	s.events[sessionID] = append(evs[:j], warn)
+	s.seqs[sessionID] = append(seqs[:j], r.warnSeq)
+	size += estimateEvent(warn)
+	return size, spill
+}
+
+// keyOf is the retention key typ's events are counted under.
+func (r Retention) keyOf(typ string) string {
+	key, _ := r.limit(typ)
+	return key
+}
+
+// spiller writes dropped events to a Persister off the AppendEvent path.
+type spiller struct {
+	p     Persister
+	queue chan []Record
+	done  chan struct{}
+}
+
+func newSpiller(p Persister) *spiller {
+	sp := &spiller{p: p, queue: make(chan []Record, spillQueueLen), done: make(chan struct{})}
+	go sp.run()
+	return sp
+}
+
+// add queues recs for writing, dropping them when the queue is full.
+// Callers hold s.mu, which keeps add and StopSpill apart.
+func (sp *spiller) add(recs []Record) {
+	select {
+	case sp.queue <- recs:
+	default:
+		metricEventsSpilled.WithLabelValues("dropped").Add(float64(len(recs)))
+	}
+}
+
+func (sp *spiller) run() {
+	defer close(sp.done)
+	for recs := range sp.queue {
+		if err := sp.p.WriteBatch(recs); err != nil {
+			metricEventsSpilled.WithLabelValues("failed").Add(float64(len(recs)))
+			log.Printf("[store] spilling %d events: %v", len(recs), err)
+			continue
+		}
+		metricEventsSpilled.WithLabelValues("written").Add(float64(len(recs)))
+	}
+}
+
+// stop drains the queue and waits for the writer.
+func (sp *spiller) stop() {
+	close(sp.queue)
+	<-sp.done
+}
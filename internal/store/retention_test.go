@@ -0,0 +1,146 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"yuzu/agent/internal/types"
+)
+
+func TestParseRetention(t *testing.T) {
+	r := ParseRetention("log=50, bot_log=all, feature=-1, bogus, =3")
+	if r[ClassLog] != 50 || r["bot_log"] != 0 || r[ClassFeature] != 200 || r[ClassFinal] != 0 || r[ClassDefault] != 200 {
+		t.Fatalf("retention = %v", r)
+	}
+	for typ, want := range map[string]string{"bot_log": "bot_log", "gateway_log": ClassLog, "transcript_final": ClassFinal,
+		"barge_in_guarded": ClassBargeIn, "vad_start": ClassFeature, "worker_connected": ClassDefault} {
+		if got, _ := r.limit(typ); got != want {
+			t.Errorf("%s counted under %q, want %q", typ, got, want)
+		}
+	}
+}
+
+func TestRetentionKeepsFinalsAndSpillsLogs(t *testing.T) {
+	st := New()
+	p := &memPersister{}
+	st.SetRetention(ParseRetention("log=3,final=all"), p)
+	st.CreateSession(&types.Session{ID: "a"})
+	for i := 0; i < 300; i++ {
+		st.AppendEvent("a", "transcript_final", map[string]any{"text": fmt.Sprint("final ", i)})
+		if i < 10 {
+			st.AppendEvent("a", "bot_log", map[string]any{"line": fmt.Sprint("log ", i)})
+		}
+	}
+
+	var finals, logs, warnings int
+	for _, e := range st.ListEvents("a") {
+		switch e.Type {
+		case "transcript_final":
+			finals++
+		case "bot_log":
+			logs++
+		case "events_truncated":
+			warnings++
+			if e.Payload["class"] != ClassLog || e.Payload["dropped"] != 8 || e.Payload["kept"] != 2 {
+				t.Errorf("warning = %v", e.Payload)
+			}
+		}
+	}
+	if finals != 300 || logs != 2 || warnings != 1 {
+		t.Fatalf("kept %d finals, %d logs, %d warnings; want 300, 2 and 1", finals, logs, warnings)
+	}
+
+	// The dropped logs went to the spill, oldest first
+	if err := st.StopSpill(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	var spilled []string
+	for _, b := range p.batches {
+		for _, r := range b {
+			spilled = append(spilled, r.Event.Payload["line"].(string))
+		}
+	}
+	if len(spilled) != 8 || spilled[0] != "log 0" || spilled[7] != "log 7" {
+		t.Fatalf("spilled %q", spilled)
+	}
+
+	// Every log past the third appended a warning, which took an index
+	evs, next := st.ListEventsSince("a", 0)
+	if next != 310+7 || len(evs) != 303 {
+		t.Fatalf("next = %d with %d kept", next, len(evs))
+	}
+	var want int64 = sessionOverheadBytes
+	for _, e := range evs {
+		want += estimateEvent(e)
+	}
+	if got := st.Stats().MemoryBytes; got != want {
+		t.Fatalf("memory estimate = %d, want %d", got, want)
+	}
+}
+
+func TestRetentionSpillsRedactedTranscripts(t *testing.T) {
+	st := New()
+	p := &memPersister{}
+	st.SetRetention(ParseRetention("final=2"), p)
+	st.CreateSession(&types.Session{ID: "a", Consent: types.Consent{NoTranscriptRetention: true}})
+	for i := 0; i < 3; i++ {
+		st.AppendEvent("a", "transcript_final", map[string]any{"text": "secret"})
+	}
+	if err := st.StopSpill(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if len(p.batches) != 1 || len(p.batches[0]) != 2 {
+		t.Fatalf("spilled %v", p.batches)
+	}
+	for _, r := range p.batches[0] {
+		if _, ok := r.Event.Payload["text"]; ok || r.Event.Payload["redacted"] != true {
+			t.Fatalf("spilled payload %v", r.Event.Payload)
+		}
+	}
+}
+
+// stuckPersister blocks WriteBatch until release is closed.
+type stuckPersister struct {
+	memPersister
+	release chan struct{}
+}
+
+func (p *stuckPersister) WriteBatch(recs []Record) error {
+	<-p.release
+	return p.memPersister.WriteBatch(recs)
+}
+
+func TestSpillDoesNotBlockAppends(t *testing.T) {
+	st := New()
+	p := &stuckPersister{release: make(chan struct{})}
+	st.SetRetention(ParseRetention("log=2"), p)
+	st.CreateSession(&types.Session{ID: "a"})
+
+	appended := make(chan struct{})
+	go func() {
+		for i := 0; i < 10; i++ {
+			st.AppendEvent("a", "bot_log", map[string]any{"line": fmt.Sprint("log ", i)})
+		}
+		close(appended)
+	}()
+	select {
+	case <-appended:
+	case <-time.After(2 * time.Second):
+		t.Fatal("AppendEvent waited on the spill write")
+	}
+
+	close(p.release)
+	if err := st.StopSpill(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	n := 0
+	for _, b := range p.batches {
+		n += len(b)
+	}
+	// log=2 keeps the latest log and the warning
+	if n != 9 {
+		t.Fatalf("spilled %d events, want 9", n)
+	}
+}
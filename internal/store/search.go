@@ -0,0 +1,275 @@
+package store
+
+import (
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"yuzu/agent/internal/types"
+)
+
+// search.go keeps a word index over what was said in each session, so
+// GET /search finds utterances without reading every event log. It holds
+// the text of transcript events (types.TranscriptEvent) as they are
+// appended, and of the orchestrator's session summary once IndexSummary
+// is given it; summary lines the session's events still hold are left out
+// so that an utterance is found once. Index entries go with their event
+// when retention truncates it, with the session when it is evicted, and
+// all at once when its transcripts are redacted. Sessions that opted out
+// of transcript retention are never indexed. Words are the runs of letters
+// and digits in the text (WordSpans), lowercased.
+
+// Utterance is one indexed line of a session's transcript.
+type Utterance struct {
+	SessionID string
+	// "event" for a transcript event, "summary" for a line of the
+	// orchestrator's session summary
+	Source string
+	Role   string // candidate | agent | interviewer
+	TurnID string // empty for summary lines
+	Ts     time.Time
+	Text   string
+}
+
+// SearchTerm is one lowercased query word; Prefix matches any word it
+// begins.
+type SearchTerm struct {
+	Word   string
+	Prefix bool
+}
+
+// docKey names an indexed utterance: an event by its index in the
+// session's log, a summary line by -(line+1).
+type docKey struct {
+	session string
+	id      int
+}
+
+type searchIndex struct {
+	docs      map[docKey]*Utterance
+	words     map[string]map[docKey]struct{}
+	vocab     []string // the keys of words, sorted, for prefix terms
+	bySession map[string]map[docKey]struct{}
+}
+
+func newSearchIndex() *searchIndex {
+	return &searchIndex{
+		docs:      map[docKey]*Utterance{},
+		words:     map[string]map[docKey]struct{}{},
+		bySession: map[string]map[docKey]struct{}{},
+	}
+}
+
+func (x *searchIndex) add(k docKey, u *Utterance) {
+	x.docs[k] = u
+	if x.bySession[k.session] == nil {
+		x.bySession[k.session] = map[docKey]struct{}{}
+	}
+	x.bySession[k.session][k] = struct{}{}
+	for _, w := range indexWords(u.Text) {
+		set := x.words[w]
+		if set == nil {
+			set = map[docKey]struct{}{}
+			x.words[w] = set
+			i := sort.SearchStrings(x.vocab, w)
+			x.vocab = append(x.vocab, "")
+			copy(x.vocab[i+1:], x.vocab[i:])
+			x.vocab[i] = w
+		}
+		set[k] = struct{}{}
+	}
+}
+
+func (x *searchIndex) remove(k docKey) {
+	u, ok := x.docs[k]
+	if !ok {
+		return
+	}
+	delete(x.docs, k)
+	if keys := x.bySession[k.session]; len(keys) <= 1 {
+		delete(x.bySession, k.session)
+	} else {
+		delete(keys, k)
+	}
+	for _, w := range indexWords(u.Text) {
+		set := x.words[w]
+		delete(set, k)
+		if len(set) == 0 {
+			delete(x.words, w)
+			if i := sort.SearchStrings(x.vocab, w); i < len(x.vocab) && x.vocab[i] == w {
+				x.vocab = append(x.vocab[:i], x.vocab[i+1:]...)
+			}
+		}
+	}
+}
+
+// dropSession removes the session's entries; summaryOnly keeps its events.
+func (x *searchIndex) dropSession(id string, summaryOnly bool) {
+	for k := range x.bySession[id] {
+		if !summaryOnly || k.id < 0 {
+			x.remove(k)
+		}
+	}
+}
+
+// postings returns the entries holding a word that matches t.
+func (x *searchIndex) postings(t SearchTerm) map[docKey]struct{} {
+	if !t.Prefix {
+		return x.words[t.Word]
+	}
+	out := map[docKey]struct{}{}
+	for i := sort.SearchStrings(x.vocab, t.Word); i < len(x.vocab) && strings.HasPrefix(x.vocab[i], t.Word); i++ {
+		for k := range x.words[x.vocab[i]] {
+			out[k] = struct{}{}
+		}
+	}
+	return out
+}
+
+// indexEvent indexes a transcript event appended at index id. Callers
+// hold s.mu.
+func (s *Memory) indexEvent(sessionID string, id int, evt types.Event) {
+	sess, ok := s.sessions[sessionID]
+	if !ok || sess.Consent.NoTranscriptRetention || !types.TranscriptEvent(evt.Type) {
+		return
+	}
+	text, _ := evt.Payload["text"].(string)
+	if text == "" {
+		return
+	}
+	role := "candidate"
+	if evt.Type == "agent_text" {
+		role = "agent"
+	}
+	turn, _ := evt.Payload["turn_id"].(string)
+	s.search.add(docKey{sessionID, id}, &Utterance{SessionID: sessionID, Source: "event", Role: role, TurnID: turn, Ts: evt.Ts, Text: text})
+}
+
+// IndexSummary replaces the session's summary lines in the search index,
+// leaving out those its events still hold. Summary lines count towards the
+// session's memory estimate.
+func (s *Memory) IndexSummary(sessionID string, lines []Utterance) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	sess, ok := s.sessions[sessionID]
+	if !ok || sess.Consent.NoTranscriptRetention {
+		return
+	}
+	var size int64
+	held := map[string]bool{}
+	for k := range s.search.bySession[sessionID] {
+		if u := s.search.docs[k]; k.id < 0 {
+			size -= estimateUtterance(*u)
+		} else {
+			held[u.Text] = true
+		}
+	}
+	s.search.dropSession(sessionID, true)
+	for i, u := range lines {
+		if u.Text == "" || held[u.Text] {
+			continue
+		}
+		u.SessionID, u.Source = sessionID, "summary"
+		s.search.add(docKey{sessionID, -(i + 1)}, &u)
+		size += estimateUtterance(u)
+	}
+	s.sessBytes[sessionID] += size
+	s.memBytes += size
+	s.updateGauges()
+}
+
+// SearchUtterances returns tenantID's indexed utterances that hold every
+// term, newest session first and in time order within a session. It looks
+// only at the entries of the rarest term and stops after limit matches,
+// reporting whether there were more.
+func (s *Memory) SearchUtterances(tenantID string, terms []SearchTerm, limit int) ([]Utterance, bool) {
+	if len(terms) == 0 || limit <= 0 {
+		return nil, false
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	sets := make([]map[docKey]struct{}, len(terms))
+	for i, t := range terms {
+		sets[i] = s.search.postings(t)
+		if len(sets[i]) == 0 {
+			return nil, false
+		}
+	}
+	sort.Slice(sets, func(i, j int) bool { return len(sets[i]) < len(sets[j]) })
+	keys := make([]docKey, 0, len(sets[0]))
+	for k := range sets[0] {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		a, b := keys[i], keys[j]
+		if a.session != b.session {
+			sa, sb := s.sessions[a.session], s.sessions[b.session]
+			if sa != nil && sb != nil && !sa.CreatedAt.Equal(sb.CreatedAt) {
+				return sa.CreatedAt.After(sb.CreatedAt)
+			}
+			return a.session < b.session
+		}
+		ua, ub := s.search.docs[a], s.search.docs[b]
+		if !ua.Ts.Equal(ub.Ts) {
+			return ua.Ts.Before(ub.Ts)
+		}
+		return a.id < b.id
+	})
+	var out []Utterance
+next:
+	for _, k := range keys {
+		if sess := s.sessions[k.session]; sess == nil || sess.TenantID != tenantID {
+			continue
+		}
+		for _, set := range sets[1:] {
+			if _, ok := set[k]; !ok {
+				continue next
+			}
+		}
+		if len(out) == limit {
+			return out, true
+		}
+		out = append(out, *s.search.docs[k])
+	}
+	return out, false
+}
+
+// WordSpans returns the byte ranges of the letter and digit runs in s, the
+// words the search index holds.
+func WordSpans(s string) [][2]int {
+	var out [][2]int
+	start := -1
+	for i, r := range s {
+		in := unicode.IsLetter(r) || unicode.IsDigit(r)
+		switch {
+		case in && start < 0:
+			start = i
+		case !in && start >= 0:
+			out = append(out, [2]int{start, i})
+			start = -1
+		}
+	}
+	if start >= 0 {
+		out = append(out, [2]int{start, len(s)})
+	}
+	return out
+}
+
+// indexWords returns the distinct lowercased words of text.
+func indexWords(text string) []string {
+	seen := map[string]bool{}
+	var out []string
+	for _, sp := range WordSpans(text) {
+		w := strings.ToLower(text[sp[0]:sp[1]])
+		if !seen[w] {
+			seen[w] = true
+			out = append(out, w)
+		}
+	}
+	return out
+}
+
+func estimateUtterance(u Utterance) int64 {
+	return eventOverheadBytes + int64(len(u.Role)+len(u.Text))
+}
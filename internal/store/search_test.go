@@ -0,0 +1,56 @@
+package store
+
+import (
+	"fmt"
+	"testing"
+
+	"yuzu/agent/internal/types"
+)
+
+func TestSearchIndexFollowsRetentionAndEviction(t *testing.T) {
+	st := NewWithCap(2)
+	st.SetRetention(ParseRetention("final=3"), nil)
+	st.CreateSession(&types.Session{ID: "a"})
+	for i := 0; i < 5; i++ {
+		st.AppendEvent("a", "transcript_final", map[string]any{"text": fmt.Sprint("answer number", i)})
+	}
+	// Only the finals the log still holds are found
+	got, _ := st.SearchUtterances("", []SearchTerm{{Word: "answer"}}, 10)
+	if len(got) != 2 || got[0].Text != "answer number3" || got[1].Text != "answer number4" {
+		t.Fatalf("search after truncation = %+v", got)
+	}
+	if got, _ := st.SearchUtterances("", []SearchTerm{{Word: "number0"}}, 10); len(got) != 0 {
+		t.Errorf("truncated final still found: %+v", got)
+	}
+
+	// A summary brings back what was truncated, and counts towards memory
+	before := st.Stats().MemoryBytes
+	st.IndexSummary("a", []Utterance{{Role: "candidate", Text: "answer number0"}, {Role: "candidate", Text: "answer number4"}})
+	if got, _ := st.SearchUtterances("", []SearchTerm{{Word: "number", Prefix: true}}, 10); len(got) != 3 {
+		t.Errorf("search with the summary = %+v", got)
+	}
+	if st.Stats().MemoryBytes <= before {
+		t.Error("summary lines not in the memory estimate")
+	}
+
+	// An evicted session leaves nothing in the index
+	st.SetStatus("a", "completed")
+	st.CreateSession(&types.Session{ID: "b"})
+	st.CreateSession(&types.Session{ID: "c"})
+	if st.GetSession("a") != nil {
+		t.Fatal("a should have been evicted")
+	}
+	if len(st.search.docs) != 0 || len(st.search.words) != 0 || len(st.search.vocab) != 0 || len(st.search.bySession) != 0 {
+		t.Errorf("index after eviction: %d docs, %d words, %d vocab, %d sessions", len(st.search.docs), len(st.search.words), len(st.search.vocab), len(st.search.bySession))
+	}
+}
+
+func TestSearchSkipsOptedOutSessions(t *testing.T) {
+	st := New()
+	st.CreateSession(&types.Session{ID: "a", Consent: types.Consent{NoTranscriptRetention: true}})
+	st.AppendEvent("a", "transcript_final", map[string]any{"text": "my account is 1234"})
+	st.IndexSummary("a", []Utterance{{Role: "candidate", Text: "my account is 1234"}})
+	if got, _ := st.SearchUtterances("", []SearchTerm{{Word: "account"}}, 10); len(got) != 0 {
+		t.Errorf("opted-out session found: %+v", got)
+	}
+}
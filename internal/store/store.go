@@ -1,7 +1,9 @@
 package store
 
 import (
+	"container/list"
 	"errors"
+	"sort"
 	"sync"
 	"time"
 
@@ -10,21 +12,144 @@ import (
 
 var ErrSessionExists = errors.New("session already exists")
 
-type Store struct {
+// Store holds sessions, their event logs and bot/worker state. Handlers,
+// the floor dispatcher and the worker WebSocket depend only on this
+// interface; Memory is the in-process implementation. Every implementation
+// must pass storetest.Run.
+type Store interface {
+    // CreateSession adds sess; ErrSessionExists when the ID is taken,
+    // ErrStoreFull when the store is at capacity.
+    CreateSession(sess *types.Session) error
+    // GetSession returns the session or nil.
+    GetSession(id string) *types.Session
+    // ListSessions returns copies of tenantID's sessions, newest first.
+    ListSessions(tenantID string) []types.Session
+    ListSessionIDs() []string
+
+    // AppendEvent timestamps and appends an event to the session's log.
+    AppendEvent(sessionID, typ string, payload map[string]any) types.Event
+    // ListEvents returns a copy of the session's log, oldest first.
+    ListEvents(sessionID string) []types.Event
+    // ListEventsSince returns the kept events with index >= since and the
+    // index the next event will get. Indexes count every event appended to
+    // the session, so they stay put when old events are truncated.
+    ListEventsSince(sessionID string, since int) ([]types.Event, int)
+
+    // AppendNetworkStats records a WebRTC stats sample for an existing
+    // session; only the most recent samples are kept.
+    AppendNetworkStats(sessionID string, s types.NetworkStats)
+    // ListNetworkStats returns a copy of the kept samples, oldest first.
+    ListNetworkStats(sessionID string) []types.NetworkStats
+
+    SetBotRunning(sessionID string, running bool)
+    IsBotRunning(sessionID string) bool
+    SetBotPID(sessionID string, pid int)
+    SetBotExit(sessionID string, code int, at time.Time)
+    // SetStatus replaces the session's status, e.g. "completed".
+    SetStatus(sessionID, status string)
+    // CompareAndSetStatus sets the session's status to status if it is
+    // still old, and reports whether it did; a check of GetSession followed
+    // by SetStatus would let two callers both make the change.
+    CompareAndSetStatus(sessionID, old, status string) bool
+    // SetClockSkew replaces the session's clock skew report.
+    SetClockSkew(sessionID string, r types.ClockSkew)
+    // SetWorkerDevice replaces the environment the session's worker reported.
+    SetWorkerDevice(sessionID string, d types.WorkerDevice)
+    // GetConsent returns what the session opted out of recording (see
+    // consent.go); the zero Consent for unknown sessions.
+    GetConsent(sessionID string) types.Consent
+    // RedactTranscripts strips the text from the session's kept transcript
+    // events and returns how many it changed; the session's lines leave
+    // the search index.
+    RedactTranscripts(sessionID string) int
+    // CountRunning returns how many of tenantID's sessions have a running bot.
+    CountRunning(tenantID string) int
+
+    SetLocalStopCapable(sessionID string, capable bool)
+    SetLocalStopEnabled(sessionID string, enabled bool)
+    GetWorkerState(sessionID string) WorkerState
+
+    // PutContextDoc adds the session's context document doc.Name, replacing
+    // one with the same name in place. It fails with ErrNoSession when the
+    // session is unknown, or with the types.CheckContextDocs error when the
+    // documents would exceed the session limits.
+    PutContextDoc(sessionID string, doc types.ContextDoc) error
+    // ListContextDocs returns a copy of the session's documents in upload order.
+    ListContextDocs(sessionID string) []types.ContextDoc
+    // IndexSummary adds the transcript lines of the session's summary to the
+    // search index, replacing any it had (see search.go).
+    IndexSummary(sessionID string, lines []Utterance)
+    // SearchUtterances returns up to limit of tenantID's transcript lines
+    // that hold every term, and whether there were more.
+    SearchUtterances(tenantID string, terms []SearchTerm, limit int) ([]Utterance, bool)
+
+    // PutPreset creates or replaces tenantID's preset p.Name and stamps
+    // UpdatedAt; CreatePreset fails with ErrPresetExists instead of replacing.
+    PutPreset(tenantID string, p types.Preset) types.Preset
+    CreatePreset(tenantID string, p types.Preset) (types.Preset, error)
+    // GetPreset returns a copy of the preset.
+    GetPreset(tenantID, name string) (types.Preset, bool)
+    // ListPresets returns tenantID's presets sorted by name.
+    ListPresets(tenantID string) []types.Preset
+    // DeletePreset reports whether the preset existed.
+    DeletePreset(tenantID, name string) bool
+
+    Stats() Stats
+}
+
+var _ Store = (*Memory)(nil)
+
+// Memory is an in-process Store.
+type Memory struct {
     mu         sync.RWMutex
     sessions   map[string]*types.Session
     events     map[string][]types.Event
+    appended   map[string]int // events ever appended, by session
+    seqs       map[string][]int // index of each kept event, by session
+    // Per-class event limits and what each session holds (see retention.go)
+    retention  Retention
+    retainedBy map[string]map[string]*retained
+    spill      *spiller
+    netStats   map[string][]types.NetworkStats
+    contextDocs map[string][]types.ContextDoc // see context.go
+    search     *searchIndex // see search.go
     botRunning map[string]bool
     // worker state per session
     workerState map[string]WorkerState
+    // presets by tenant, then name (see presets.go)
+    presets map[string]map[string]types.Preset
+
+    // Capacity guard (see capacity.go); maxSessions <= 0 means unbounded
+    maxSessions int
+    order       *list.List // session IDs, most recently active first
+    lru         map[string]*list.Element
+    eventCount  int
+    memBytes    int64
+    sessBytes   map[string]int64
 }
 
-func New() *Store {
-    return &Store{
+// New returns an unbounded in-memory store.
+func New() *Memory { return NewWithCap(0) }
+
+// NewWithCap returns an in-memory store holding at most maxSessions sessions.
+func NewWithCap(maxSessions int) *Memory {
+    return &Memory{
         sessions:   make(map[string]*types.Session),
         events:     make(map[string][]types.Event),
+        appended:   make(map[string]int),
+        seqs:       make(map[string][]int),
+        retention:  ParseRetention(""),
+        retainedBy: make(map[string]map[string]*retained),
+        netStats:   make(map[string][]types.NetworkStats),
+        contextDocs: make(map[string][]types.ContextDoc),
+        search:      newSearchIndex(),
         botRunning: make(map[string]bool),
         workerState: make(map[string]WorkerState),
+        presets:     make(map[string]map[string]types.Preset),
+        maxSessions: maxSessions,
+        order:       list.New(),
+        lru:         make(map[string]*list.Element),
+        sessBytes:   make(map[string]int64),
     }
 }
 
@@ -34,49 +159,57 @@ type WorkerState struct {
     LocalStopEnabled bool
 }
 
-func (s *Store) CreateSession(sess *types.Session) error {
+func (s *Memory) CreateSession(sess *types.Session) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 	if _, ok := s.sessions[sess.ID]; ok {
 		return ErrSessionExists
 	}
+	if s.maxSessions > 0 && len(s.sessions) >= s.maxSessions && !s.evictOne() {
+		metricRejections.Inc()
+		return ErrStoreFull
+	}
 	s.sessions[sess.ID] = sess
 	s.events[sess.ID] = []types.Event{}
+	s.sessBytes[sess.ID] = sessionOverheadBytes
+	s.memBytes += sessionOverheadBytes
+	s.touch(sess.ID)
+	s.updateGauges()
 	return nil
 }
 
-func (s *Store) GetSession(id string) *types.Session {
+func (s *Memory) GetSession(id string) *types.Session {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 	return s.sessions[id]
 }
 
-func (s *Store) AppendEvent(sessionID, typ string, payload map[string]any) types.Event {
+func (s *Memory) AppendEvent(sessionID, typ string, payload map[string]any) types.Event {
     evt := types.Event{Type: typ, Ts: time.Now().UTC(), Payload: payload}
     s.mu.Lock()
-    defer s.mu.Unlock()
+    before := len(s.events[sessionID])
     s.events[sessionID] = append(s.events[sessionID], evt)
-    // Cap total events per session to avoid unbounded growth
-    const maxEvents = 200
-    if l := len(s.events[sessionID]); l > maxEvents {
-        // Keep space for a single truncation warning so the total stays at maxEvents
-        keep := maxEvents - 1
-        if keep < 0 { keep = 0 }
-        dropped := l - keep
-        if dropped < 0 { dropped = 0 }
-        if keep > 0 {
-            s.events[sessionID] = append([]types.Event(nil), s.events[sessionID][l-keep:]...)
-        } else {
-            s.events[sessionID] = []types.Event{}
-        }
-        // Append warning event
-        warn := types.Event{Type: "events_truncated", Ts: time.Now().UTC(), Payload: map[string]any{"session_id": sessionID, "dropped": dropped, "kept": keep}}
-        s.events[sessionID] = append(s.events[sessionID], warn)
+    s.seqs[sessionID] = append(s.seqs[sessionID], s.appended[sessionID])
+    s.indexEvent(sessionID, s.appended[sessionID], evt)
+    s.appended[sessionID]++
+    // Per-class caps keep long calls bounded (see retention.go)
+    trimmed, spill := s.retain(sessionID, typ)
+    size := estimateEvent(evt) + trimmed
+    s.eventCount += len(s.events[sessionID]) - before
+    s.sessBytes[sessionID] += size
+    s.memBytes += size
+    if _, ok := s.sessions[sessionID]; ok {
+        s.touch(sessionID)
+    }
+    s.updateGauges()
+    if len(spill) > 0 {
+        s.spill.add(spill)
     }
+    s.mu.Unlock()
     return evt
 }
 
-func (s *Store) ListEvents(sessionID string) []types.Event {
+func (s *Memory) ListEvents(sessionID string) []types.Event {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 	src := s.events[sessionID]
@@ -85,19 +218,61 @@ func (s *Store) ListEvents(sessionID string) []types.Event {
 	return out
 }
 
-func (s *Store) SetBotRunning(sessionID string, running bool) {
+func (s *Memory) ListEventsSince(sessionID string, since int) ([]types.Event, int) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	src := s.events[sessionID]
+	next := s.appended[sessionID]
+	// Kept indexes ascend, with gaps where events were dropped
+	skip := sort.SearchInts(s.seqs[sessionID], since)
+	out := make([]types.Event, len(src)-skip)
+	copy(out, src[skip:])
+	return out, next
+}
+
+// maxNetworkSamples keeps ten minutes of stats at the gateway's 1 Hz.
+const maxNetworkSamples = 600
+
+func (s *Memory) AppendNetworkStats(sessionID string, ns types.NetworkStats) {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+    if _, ok := s.sessions[sessionID]; !ok {
+        return
+    }
+    samples := append(s.netStats[sessionID], ns)
+    if len(samples) > maxNetworkSamples {
+        samples = append([]types.NetworkStats(nil), samples[len(samples)-maxNetworkSamples:]...)
+    } else {
+        s.sessBytes[sessionID] += networkSampleBytes
+        s.memBytes += networkSampleBytes
+    }
+    s.netStats[sessionID] = samples
+    s.touch(sessionID)
+    s.updateGauges()
+}
+
+func (s *Memory) ListNetworkStats(sessionID string) []types.NetworkStats {
+    s.mu.RLock()
+    defer s.mu.RUnlock()
+    return append([]types.NetworkStats(nil), s.netStats[sessionID]...)
+}
+
+func (s *Memory) SetBotRunning(sessionID string, running bool) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 	s.botRunning[sessionID] = running
+	if _, ok := s.sessions[sessionID]; ok {
+		s.touch(sessionID)
+	}
 }
 
-func (s *Store) IsBotRunning(sessionID string) bool {
+func (s *Memory) IsBotRunning(sessionID string) bool {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 	return s.botRunning[sessionID]
 }
 
-func (s *Store) SetBotPID(sessionID string, pid int) {
+func (s *Memory) SetBotPID(sessionID string, pid int) {
 	s.mu.Lock()
 	if sess, ok := s.sessions[sessionID]; ok {
 		sess.BotPID = pid
@@ -105,7 +280,7 @@ func (s *Store) SetBotPID(sessionID string, pid int) {
 	s.mu.Unlock()
 }
 
-func (s *Store) SetBotExit(sessionID string, code int, at time.Time) {
+func (s *Memory) SetBotExit(sessionID string, code int, at time.Time) {
 	s.mu.Lock()
 	if sess, ok := s.sessions[sessionID]; ok {
 		sess.BotLastExitCode = code
@@ -114,7 +289,57 @@ func (s *Store) SetBotExit(sessionID string, code int, at time.Time) {
 	s.mu.Unlock()
 }
 
-func (s *Store) ListSessionIDs() []string {
+func (s *Memory) SetStatus(sessionID, status string) {
+	s.mu.Lock()
+	if sess, ok := s.sessions[sessionID]; ok {
+		sess.Status = status
+		s.touch(sessionID)
+	}
+	s.mu.Unlock()
+}
+
+func (s *Memory) CompareAndSetStatus(sessionID, old, status string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	sess, ok := s.sessions[sessionID]
+	if !ok || sess.Status != old {
+		return false
+	}
+	sess.Status = status
+	s.touch(sessionID)
+	return true
+}
+
+func (s *Memory) SetClockSkew(sessionID string, r types.ClockSkew) {
+	s.mu.Lock()
+	if sess, ok := s.sessions[sessionID]; ok {
+		sess.ClockSkew = &r
+	}
+	s.mu.Unlock()
+}
+
+func (s *Memory) SetWorkerDevice(sessionID string, d types.WorkerDevice) {
+	s.mu.Lock()
+	if sess, ok := s.sessions[sessionID]; ok {
+		sess.Device = &d
+	}
+	s.mu.Unlock()
+}
+
+// CountRunning returns how many of tenantID's sessions have a running bot.
+func (s *Memory) CountRunning(tenantID string) int {
+    s.mu.RLock()
+    defer s.mu.RUnlock()
+    n := 0
+    for id, sess := range s.sessions {
+        if sess.TenantID == tenantID && s.botRunning[id] {
+            n++
+        }
+    }
+    return n
+}
+
+func (s *Memory) ListSessionIDs() []string {
     s.mu.RLock()
     defer s.mu.RUnlock()
     out := make([]string, 0, len(s.sessions))
@@ -124,8 +349,22 @@ func (s *Store) ListSessionIDs() []string {
     return out
 }
 
+// ListSessions returns copies of tenantID's sessions, newest first.
+func (s *Memory) ListSessions(tenantID string) []types.Session {
+    s.mu.RLock()
+    out := make([]types.Session, 0, len(s.sessions))
+    for _, sess := range s.sessions {
+        if sess.TenantID == tenantID {
+            out = append(out, *sess)
+        }
+    }
+    s.mu.RUnlock()
+    sort.Slice(out, func(i, j int) bool { return out[i].CreatedAt.After(out[j].CreatedAt) })
+    return out
+}
+
 // Worker state helpers
-func (s *Store) SetLocalStopCapable(sessionID string, capable bool) {
+func (s *Memory) SetLocalStopCapable(sessionID string, capable bool) {
     s.mu.Lock()
     st := s.workerState[sessionID]
     st.LocalStopCapable = capable
@@ -133,7 +372,7 @@ func (s *Store) SetLocalStopCapable(sessionID string, capable bool) {
     s.mu.Unlock()
 }
 
-func (s *Store) SetLocalStopEnabled(sessionID string, enabled bool) {
+func (s *Memory) SetLocalStopEnabled(sessionID string, enabled bool) {
     s.mu.Lock()
     st := s.workerState[sessionID]
     st.LocalStopEnabled = enabled
@@ -141,7 +380,7 @@ func (s *Store) SetLocalStopEnabled(sessionID string, enabled bool) {
     s.mu.Unlock()
 }
 
-func (s *Store) GetWorkerState(sessionID string) WorkerState {
+func (s *Memory) GetWorkerState(sessionID string) WorkerState {
     s.mu.RLock(); defer s.mu.RUnlock()
     return s.workerState[sessionID]
 }
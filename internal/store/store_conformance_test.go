@@ -0,0 +1,31 @@
+package store_test
+
+import (
+	"context"
+	"testing"
+
+	"yuzu/agent/internal/store"
+	"yuzu/agent/internal/store/storetest"
+)
+
+func TestMemoryConformance(t *testing.T) {
+	storetest.Run(t, func(*testing.T) store.Store { return store.New() })
+}
+
+func TestCappedMemoryConformance(t *testing.T) {
+	storetest.Run(t, func(*testing.T) store.Store { return store.NewWithCap(16) })
+}
+
+type discard struct{}
+
+func (discard) WriteBatch([]store.Record) error { return nil }
+func (discard) Sync() error                     { return nil }
+func (discard) Close() error                    { return nil }
+
+func TestWriteBehindConformance(t *testing.T) {
+	storetest.Run(t, func(t *testing.T) store.Store {
+		w := store.NewWriteBehind(store.New(), discard{}, store.WriteBehindConfig{})
+		t.Cleanup(func() { w.Close(context.Background()) })
+		return w
+	})
+}
@@ -1,6 +1,7 @@
 package store
 
 import (
+	"fmt"
 	"testing"
 	"time"
 	"yuzu/agent/internal/types"
@@ -17,3 +18,92 @@ func TestCreateAndGetSession(t *testing.T) {
 		t.Fatalf("expected session %q, got %#v", s.ID, got)
 	}
 }
+
+func TestSessionCapEvictsEndedLRU(t *testing.T) {
+	st := NewWithCap(2)
+	for _, id := range []string{"a", "b"} {
+		if err := st.CreateSession(&types.Session{ID: id}); err != nil {
+			t.Fatalf("create %s: %v", id, err)
+		}
+	}
+	st.AppendEvent("b", "x", map[string]any{"text": "hello"})
+	st.AppendEvent("a", "x", nil) // a is now the most recent
+
+	// Neither has ended: a just-created session waiting on its bot is live
+	if err := st.CreateSession(&types.Session{ID: "c"}); err != ErrStoreFull {
+		t.Fatalf("create c = %v, want ErrStoreFull", err)
+	}
+
+	// b completes; a ends when its bot exits, but is more recently active
+	st.SetBotRunning("a", true)
+	st.SetBotRunning("a", false)
+	st.SetBotExit("a", 0, time.Now())
+	st.SetStatus("b", "completed")
+	st.AppendEvent("a", "x", nil)
+
+	// b is the least recently active ended session, so it makes room for c
+	if err := st.CreateSession(&types.Session{ID: "c"}); err != nil {
+		t.Fatalf("create c: %v", err)
+	}
+	if st.GetSession("b") != nil || len(st.ListEvents("b")) != 0 {
+		t.Fatal("b should have been evicted with its events")
+	}
+	if st.GetSession("a") == nil {
+		t.Fatal("a should survive")
+	}
+
+	// A bot that exited and was restarted is running again; c has not
+	// started, so nothing can go
+	st.SetBotRunning("a", true)
+	if err := st.CreateSession(&types.Session{ID: "d"}); err != ErrStoreFull {
+		t.Fatalf("create d = %v, want ErrStoreFull", err)
+	}
+
+	stats := st.Stats()
+	if stats.Sessions != 2 || stats.Events != 2 || stats.MemoryBytes <= 0 {
+		t.Fatalf("stats = %+v, want 2 sessions, 2 events", stats)
+	}
+}
+
+func TestMemoryEstimateAfterTruncation(t *testing.T) {
+	st := New()
+	st.CreateSession(&types.Session{ID: "a"})
+	for i := 0; i < 500; i++ {
+		st.AppendEvent("a", "x", map[string]any{"text": "some words"})
+	}
+	stats := st.Stats()
+	if stats.Events != 200 {
+		t.Fatalf("events = %d, want 200 after truncation", stats.Events)
+	}
+	var want int64 = sessionOverheadBytes
+	for _, e := range st.ListEvents("a") {
+		want += estimateEvent(e)
+	}
+	if stats.MemoryBytes != want {
+		t.Fatalf("memory estimate = %d, want %d", stats.MemoryBytes, want)
+	}
+}
+
+func TestEventIndexesSurviveTruncation(t *testing.T) {
+	st := New()
+	st.CreateSession(&types.Session{ID: "a"})
+	for i := 0; i < 250; i++ {
+		st.AppendEvent("a", fmt.Sprint("e", i), nil)
+	}
+	all, next := st.ListEventsSince("a", 0)
+	if len(all) != 200 {
+		t.Fatalf("kept %d events, want 200", len(all))
+	}
+	// Each append past the cap replaces the class's truncation warning with
+	// a new one, which takes an index and leaves a gap where the old one was
+	since := next - 4
+	evs, _ := st.ListEventsSince("a", since)
+	if len(evs) != 3 || evs[2].Type != all[199].Type || evs[0].Type != all[197].Type {
+		t.Fatalf("since %d = %+v, want the last three kept events", since, evs)
+	}
+	st.AppendEvent("a", "later", nil)
+	evs, after := st.ListEventsSince("a", next)
+	if len(evs) == 0 || evs[0].Type != "later" || after <= next {
+		t.Fatalf("since %d after one more append = %+v, next %d", next, evs, after)
+	}
+}
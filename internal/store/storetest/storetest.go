@@ -0,0 +1,412 @@
+// Package storetest is the conformance suite for store.Store
+// implementations. A backend passes when Run succeeds against it:
+//
+//	func TestConformance(t *testing.T) {
+//		storetest.Run(t, func(t *testing.T) store.Store { return mybackend.New(t.TempDir()) })
+//	}
+package storetest
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"yuzu/agent/internal/store"
+	"yuzu/agent/internal/types"
+)
+
+// Run checks the store.Store contract. newStore must return an empty store
+// with room for at least 16 sessions; it is called once per subtest.
+func Run(t *testing.T, newStore func(t *testing.T) store.Store) {
+	t.Helper()
+	for _, tc := range []struct {
+		name string
+		fn   func(t *testing.T, st store.Store)
+	}{
+		{"Sessions", testSessions},
+		{"ListSessions", testListSessions},
+		{"Events", testEvents},
+		{"EventsSince", testEventsSince},
+		{"NetworkStats", testNetworkStats},
+		{"Presets", testPresets},
+		{"Bot", testBot},
+		{"WorkerState", testWorkerState},
+		{"ContextDocs", testContextDocs},
+		{"Consent", testConsent},
+		{"Search", testSearch},
+		{"Stats", testStats},
+		{"ConcurrentAppend", testConcurrentAppend},
+	} {
+		t.Run(tc.name, func(t *testing.T) { tc.fn(t, newStore(t)) })
+	}
+}
+
+var t0 = time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+func create(t *testing.T, st store.Store, id, tenant string, at time.Time) {
+	t.Helper()
+	if err := st.CreateSession(&types.Session{ID: id, TenantID: tenant, RoomName: "room-" + id, CreatedAt: at}); err != nil {
+		t.Fatalf("CreateSession(%s): %v", id, err)
+	}
+}
+
+func testSessions(t *testing.T, st store.Store) {
+	if st.GetSession("missing") != nil {
+		t.Fatal("GetSession of an unknown ID should be nil")
+	}
+	create(t, st, "a", "t1", t0)
+	got := st.GetSession("a")
+	if got == nil || got.ID != "a" || got.TenantID != "t1" || got.RoomName != "room-a" || !got.CreatedAt.Equal(t0) {
+		t.Fatalf("GetSession(a) = %+v", got)
+	}
+	if err := st.CreateSession(&types.Session{ID: "a"}); !errors.Is(err, store.ErrSessionExists) {
+		t.Fatalf("duplicate CreateSession = %v, want ErrSessionExists", err)
+	}
+	create(t, st, "b", "t1", t0)
+	ids := map[string]bool{}
+	for _, id := range st.ListSessionIDs() {
+		ids[id] = true
+	}
+	if len(ids) != 2 || !ids["a"] || !ids["b"] {
+		t.Fatalf("ListSessionIDs = %v, want a and b", ids)
+	}
+}
+
+func testListSessions(t *testing.T, st store.Store) {
+	create(t, st, "old", "t1", t0)
+	create(t, st, "new", "t1", t0.Add(time.Minute))
+	create(t, st, "other", "t2", t0.Add(2*time.Minute))
+	list := st.ListSessions("t1")
+	if len(list) != 2 || list[0].ID != "new" || list[1].ID != "old" {
+		t.Fatalf("ListSessions(t1) = %+v, want new then old", list)
+	}
+	// Results are copies
+	list[0].RoomName = "changed"
+	if st.GetSession("new").RoomName != "room-new" {
+		t.Error("modifying a listed session changed the store")
+	}
+	if n := len(st.ListSessions("nobody")); n != 0 {
+		t.Errorf("ListSessions of an unknown tenant = %d sessions", n)
+	}
+}
+
+func testEvents(t *testing.T, st store.Store) {
+	create(t, st, "a", "t1", t0)
+	if n := len(st.ListEvents("a")); n != 0 {
+		t.Fatalf("new session has %d events", n)
+	}
+	before := time.Now().Add(-time.Second)
+	ev := st.AppendEvent("a", "first", map[string]any{"k": "v"})
+	if ev.Type != "first" || ev.Ts.Before(before) || ev.Payload["k"] != "v" {
+		t.Fatalf("AppendEvent returned %+v", ev)
+	}
+	st.AppendEvent("a", "second", nil)
+	evs := st.ListEvents("a")
+	if len(evs) != 2 || evs[0].Type != "first" || evs[1].Type != "second" {
+		t.Fatalf("ListEvents = %+v, want first, second", evs)
+	}
+	if evs[1].Ts.Before(evs[0].Ts) {
+		t.Error("events out of time order")
+	}
+	// Results are copies
+	evs[0].Type = "changed"
+	if st.ListEvents("a")[0].Type != "first" {
+		t.Error("modifying a listed event changed the store")
+	}
+	if n := len(st.ListEvents("missing")); n != 0 {
+		t.Errorf("unknown session has %d events", n)
+	}
+}
+
+func testEventsSince(t *testing.T, st store.Store) {
+	create(t, st, "a", "t1", t0)
+	if evs, next := st.ListEventsSince("a", 0); len(evs) != 0 || next != 0 {
+		t.Fatalf("new session: %d events, next %d", len(evs), next)
+	}
+	for _, typ := range []string{"e0", "e1", "e2"} {
+		st.AppendEvent("a", typ, nil)
+	}
+	evs, next := st.ListEventsSince("a", 1)
+	if next != 3 || len(evs) != 2 || evs[0].Type != "e1" || evs[1].Type != "e2" {
+		t.Fatalf("since 1 = %+v, next %d; want e1, e2 and 3", evs, next)
+	}
+	if evs, next := st.ListEventsSince("a", 3); len(evs) != 0 || next != 3 {
+		t.Fatalf("since next = %+v, next %d", evs, next)
+	}
+	if evs, _ := st.ListEventsSince("a", 99); len(evs) != 0 {
+		t.Fatalf("since past the end = %+v", evs)
+	}
+	if evs, _ := st.ListEventsSince("a", -1); len(evs) != 3 {
+		t.Fatalf("negative since returned %d events, want all", len(evs))
+	}
+}
+
+func testNetworkStats(t *testing.T, st store.Store) {
+	create(t, st, "a", "t1", t0)
+	rtt, loss := 42.0, 0.0
+	st.AppendNetworkStats("a", types.NetworkStats{Ts: t0, RTTMs: &rtt, PacketLossPct: &loss})
+	st.AppendNetworkStats("a", types.NetworkStats{Ts: t0.Add(time.Second)})
+	got := st.ListNetworkStats("a")
+	if len(got) != 2 || got[0].RTTMs == nil || *got[0].RTTMs != 42 || got[0].PacketLossPct == nil || *got[0].PacketLossPct != 0 || got[1].RTTMs != nil {
+		t.Fatalf("ListNetworkStats = %+v", got)
+	}
+	if !got[1].Ts.Equal(t0.Add(time.Second)) {
+		t.Errorf("samples out of order: %+v", got)
+	}
+	// Samples for unknown sessions are dropped
+	st.AppendNetworkStats("missing", types.NetworkStats{Ts: t0})
+	if n := len(st.ListNetworkStats("missing")); n != 0 {
+		t.Errorf("unknown session kept %d samples", n)
+	}
+}
+
+func testPresets(t *testing.T, st store.Store) {
+	p := types.Preset{Name: "phone-screen", Style: types.SessionStyle{Persona: types.PersonaFriendly}, Flow: []byte(`{"name":"f","stages":[{"id":"a"}]}`)}
+	got, err := st.CreatePreset("t1", p)
+	if err != nil || got.UpdatedAt.IsZero() {
+		t.Fatalf("CreatePreset = %+v, %v", got, err)
+	}
+	if _, err := st.CreatePreset("t1", p); !errors.Is(err, store.ErrPresetExists) {
+		t.Errorf("second CreatePreset err = %v, want ErrPresetExists", err)
+	}
+	// Presets are per tenant
+	st.PutPreset("t2", types.Preset{Name: "phone-screen"})
+	st.PutPreset("t1", types.Preset{Name: "deep-dive"})
+	if l := st.ListPresets("t1"); len(l) != 2 || l[0].Name != "deep-dive" || l[1].Name != "phone-screen" {
+		t.Fatalf("ListPresets(t1) = %+v", l)
+	}
+	g, ok := st.GetPreset("t1", "phone-screen")
+	if !ok || g.Style.Persona != types.PersonaFriendly || string(g.Flow) != string(p.Flow) {
+		t.Fatalf("GetPreset = %+v, %v", g, ok)
+	}
+	// Callers get copies
+	g.Flow[0] = 'X'
+	if g2, _ := st.GetPreset("t1", "phone-screen"); g2.Flow[0] != '{' {
+		t.Error("GetPreset returned shared flow bytes")
+	}
+	if !st.DeletePreset("t1", "phone-screen") || st.DeletePreset("t1", "phone-screen") {
+		t.Error("DeletePreset should report existence")
+	}
+	if _, ok := st.GetPreset("t2", "phone-screen"); !ok {
+		t.Error("delete reached another tenant")
+	}
+}
+
+func testBot(t *testing.T, st store.Store) {
+	create(t, st, "a", "t1", t0)
+	create(t, st, "b", "t1", t0)
+	create(t, st, "c", "t2", t0)
+	if st.IsBotRunning("a") {
+		t.Fatal("bot running before SetBotRunning")
+	}
+	st.SetBotRunning("a", true)
+	st.SetBotRunning("b", true)
+	st.SetBotRunning("c", true)
+	st.SetBotRunning("b", false)
+	if !st.IsBotRunning("a") || st.IsBotRunning("b") {
+		t.Fatal("IsBotRunning does not reflect SetBotRunning")
+	}
+	if n := st.CountRunning("t1"); n != 1 {
+		t.Errorf("CountRunning(t1) = %d, want 1", n)
+	}
+
+	exit := t0.Add(time.Hour)
+	st.SetBotPID("a", 4242)
+	st.SetBotExit("a", 3, exit)
+	got := st.GetSession("a")
+	if got.BotPID != 4242 || got.BotLastExitCode != 3 || got.BotLastExitAt == nil || !got.BotLastExitAt.Equal(exit) {
+		t.Errorf("bot fields = pid %d code %d at %v", got.BotPID, got.BotLastExitCode, got.BotLastExitAt)
+	}
+	st.SetStatus("a", "created")
+	// Only one of two callers racing to complete the session wins
+	if !st.CompareAndSetStatus("a", "created", "completed") || st.CompareAndSetStatus("a", "created", "completed") {
+		t.Error("CompareAndSetStatus let the change through twice")
+	}
+	if got := st.GetSession("a"); got.Status != "completed" {
+		t.Errorf("status = %q, want completed", got.Status)
+	}
+	st.SetClockSkew("a", types.ClockSkew{Messages: 12, Assessment: "clock_offset"})
+	if got := st.GetSession("a").ClockSkew; got == nil || got.Messages != 12 || got.Assessment != "clock_offset" {
+		t.Errorf("clock skew = %+v", got)
+	}
+	aec := false
+	st.SetWorkerDevice("a", types.WorkerDevice{OS: "Linux-6.1", AECEnabled: &aec})
+	if got := st.GetSession("a").Device; got == nil || got.OS != "Linux-6.1" || got.AECEnabled == nil || *got.AECEnabled {
+		t.Errorf("device = %+v", got)
+	}
+	// Unknown sessions are ignored
+	st.SetBotPID("missing", 1)
+	st.SetBotExit("missing", 1, exit)
+	st.SetStatus("missing", "completed")
+	if st.CompareAndSetStatus("missing", "", "completed") {
+		t.Error("CompareAndSetStatus changed an unknown session")
+	}
+	st.SetClockSkew("missing", types.ClockSkew{})
+	st.SetWorkerDevice("missing", types.WorkerDevice{})
+}
+
+func testConsent(t *testing.T, st store.Store) {
+	if err := st.CreateSession(&types.Session{ID: "a", Consent: types.Consent{NoTranscriptRetention: true}}); err != nil {
+		t.Fatal(err)
+	}
+	if c := st.GetConsent("a"); !c.NoTranscriptRetention || c.NoAudioArchive {
+		t.Fatalf("consent = %+v", c)
+	}
+	if c := st.GetConsent("missing"); c.Any() {
+		t.Fatalf("unknown session consent = %+v", c)
+	}
+	st.AppendEvent("a", "transcript_final", map[string]any{"text": "my account is 1234", "turn_id": "t1"})
+	st.AppendEvent("a", "vad_start", map[string]any{"source": "candidate_audio"})
+	st.AppendEvent("a", "agent_text", map[string]any{"text": "thanks"})
+	if n := st.RedactTranscripts("a"); n != 2 {
+		t.Fatalf("redacted %d events, want 2", n)
+	}
+	// Persisting stores may log their own rejections in between
+	for _, e := range st.ListEvents("a") {
+		switch e.Type {
+		case "transcript_final":
+			if _, ok := e.Payload["text"]; ok || e.Payload["turn_id"] != "t1" || e.Payload["redacted"] != true {
+				t.Errorf("redacted final = %v", e.Payload)
+			}
+		case "vad_start":
+			if e.Payload["source"] != "candidate_audio" {
+				t.Errorf("non-transcript event changed: %v", e.Payload)
+			}
+		}
+	}
+	if n := st.RedactTranscripts("a"); n != 0 {
+		t.Errorf("second redaction changed %d events", n)
+	}
+}
+
+func testSearch(t *testing.T, st store.Store) {
+	create(t, st, "a", "t1", t0)
+	create(t, st, "b", "t1", t0.Add(time.Hour))
+	create(t, st, "c", "t2", t0)
+	st.AppendEvent("a", "transcript_final", map[string]any{"text": "I migrated Postgres", "turn_id": "t1"})
+	st.AppendEvent("a", "agent_text", map[string]any{"text": "Why Postgres?"})
+	st.AppendEvent("a", "bot_log", map[string]any{"text": "postgres"})
+	st.AppendEvent("b", "transcript_final", map[string]any{"text": "postgres, then MySQL"})
+	st.AppendEvent("c", "transcript_final", map[string]any{"text": "postgres"})
+
+	got, more := st.SearchUtterances("t1", []store.SearchTerm{{Word: "postgres"}}, 10)
+	if more || len(got) != 3 || got[0].SessionID != "b" || got[1].Text != "I migrated Postgres" || got[1].Role != "candidate" || got[1].TurnID != "t1" || got[2].Role != "agent" {
+		t.Fatalf("search postgres = %+v, %v", got, more)
+	}
+	if got, _ := st.SearchUtterances("t1", []store.SearchTerm{{Word: "postgres"}, {Word: "migr", Prefix: true}}, 10); len(got) != 1 || got[0].SessionID != "a" {
+		t.Errorf("search postgres migr* = %+v", got)
+	}
+	if got, more := st.SearchUtterances("t1", []store.SearchTerm{{Word: "postgres"}}, 2); len(got) != 2 || !more {
+		t.Errorf("search capped at 2 = %d hits, more %v", len(got), more)
+	}
+
+	// Summary lines the events don't hold are found too
+	st.IndexSummary("a", []store.Utterance{
+		{Role: "candidate", Text: "I migrated Postgres"},
+		{Role: "candidate", Text: "Then we sharded Postgres", Ts: t0},
+	})
+	got, _ = st.SearchUtterances("t1", []store.SearchTerm{{Word: "postgres"}}, 10)
+	if len(got) != 4 {
+		t.Fatalf("search postgres with a summary = %+v", got)
+	}
+	if got, _ := st.SearchUtterances("t1", []store.SearchTerm{{Word: "sharded"}}, 10); len(got) != 1 || got[0].Source != "summary" || got[0].SessionID != "a" {
+		t.Errorf("search sharded = %+v", got)
+	}
+
+	// Redaction takes the session out of the index
+	st.RedactTranscripts("a")
+	if got, _ := st.SearchUtterances("t1", []store.SearchTerm{{Word: "postgres"}}, 10); len(got) != 1 || got[0].SessionID != "b" {
+		t.Errorf("search after redaction = %+v", got)
+	}
+}
+
+func testWorkerState(t *testing.T, st store.Store) {
+	create(t, st, "a", "t1", t0)
+	if ws := st.GetWorkerState("a"); ws.LocalStopCapable || ws.LocalStopEnabled {
+		t.Fatalf("initial worker state = %+v", ws)
+	}
+	st.SetLocalStopCapable("a", true)
+	st.SetLocalStopEnabled("a", true)
+	st.SetLocalStopEnabled("a", false)
+	if ws := st.GetWorkerState("a"); !ws.LocalStopCapable || ws.LocalStopEnabled {
+		t.Errorf("worker state = %+v, want capable, not enabled", ws)
+	}
+}
+
+func testContextDocs(t *testing.T, st store.Store) {
+	create(t, st, "a", "t1", t0)
+	create(t, st, "b", "t1", t0)
+	if docs := st.ListContextDocs("a"); len(docs) != 0 {
+		t.Fatalf("initial docs = %+v", docs)
+	}
+	st.PutContextDoc("a", types.ContextDoc{Name: "jd", Kind: "job", Text: "Go engineer"})
+	st.PutContextDoc("a", types.ContextDoc{Name: "resume", Text: "Ten years of Python"})
+	// Replacing keeps the upload position
+	st.PutContextDoc("a", types.ContextDoc{Name: "jd", Kind: "job", Text: "Senior Go engineer"})
+	got := st.ListContextDocs("a")
+	if len(got) != 2 || got[0].Name != "jd" || got[0].Text != "Senior Go engineer" || got[0].Kind != "job" || got[1].Name != "resume" {
+		t.Fatalf("ListContextDocs(a) = %+v", got)
+	}
+	// The result is a copy
+	got[0].Text = "changed"
+	if st.ListContextDocs("a")[0].Text != "Senior Go engineer" {
+		t.Error("ListContextDocs returned the stored slice")
+	}
+	if docs := st.ListContextDocs("b"); len(docs) != 0 {
+		t.Errorf("docs leaked to another session: %+v", docs)
+	}
+	if err := st.PutContextDoc("missing", types.ContextDoc{Name: "jd", Text: "x"}); !errors.Is(err, store.ErrNoSession) {
+		t.Errorf("PutContextDoc(missing) = %v, want ErrNoSession", err)
+	}
+	// The limit is on the encoded size: quotes escape to twice their length
+	quotes := strings.Repeat(`"`, types.MaxContextDocLen)
+	if err := st.PutContextDoc("b", types.ContextDoc{Name: "q1", Text: quotes}); err == nil {
+		t.Error("escaped documents over the total were accepted")
+	}
+	if docs := st.ListContextDocs("b"); len(docs) != 0 {
+		t.Errorf("a rejected put kept %d docs", len(docs))
+	}
+	if docs := st.ListContextDocs("missing"); len(docs) != 0 {
+		t.Errorf("unknown session kept %d docs", len(docs))
+	}
+}
+
+func testStats(t *testing.T, st store.Store) {
+	create(t, st, "a", "t1", t0)
+	create(t, st, "b", "t1", t0)
+	st.AppendEvent("a", "x", nil)
+	st.AppendEvent("b", "x", map[string]any{"text": "hello"})
+	st.AppendEvent("b", "y", nil)
+	stats := st.Stats()
+	if stats.Sessions != 2 || stats.Events != 3 {
+		t.Errorf("Stats = %+v, want 2 sessions, 3 events", stats)
+	}
+}
+
+func testConcurrentAppend(t *testing.T, st store.Store) {
+	const writers, each = 8, 20
+	for i := 0; i < writers; i++ {
+		create(t, st, fmt.Sprint("s", i), "t1", t0)
+	}
+	var wg sync.WaitGroup
+	for i := 0; i < writers; i++ {
+		wg.Add(1)
+		go func(sid string) {
+			defer wg.Done()
+			for j := 0; j < each; j++ {
+				st.AppendEvent(sid, "x", map[string]any{"j": j})
+				st.ListEvents(sid)
+				st.SetBotRunning(sid, j%2 == 0)
+			}
+		}(fmt.Sprint("s", i))
+	}
+	wg.Wait()
+	for i := 0; i < writers; i++ {
+		if n := len(st.ListEvents(fmt.Sprint("s", i))); n != each {
+			t.Errorf("s%d has %d events, want %d", i, n, each)
+		}
+	}
+}
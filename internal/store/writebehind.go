@@ -0,0 +1,319 @@
+package store
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"log"
+	"os"
+	"sync"
+	"time"
+
+	"yuzu/agent/internal/types"
+)
+
+// writebehind.go keeps a persistence backend off the request path. The
+// high-rate writes, events (worker messages, bot logs) and network stats,
+// are applied to the wrapped Store as before, so reads see them at once,
+// and queued for the backend. A background loop writes the queue in
+// batches, one Persister.WriteBatch per MaxBatch records (a multi-row
+// INSERT, one buffered file write), every FlushEvery or as soon as a batch
+// is full. Durability is a separate, slower loop: Persister.Sync (fsync,
+// commit) runs every SyncEvery after new writes, so neither requests nor
+// batch writes wait on the disk.
+//
+// The queue holds at most MaxQueue records; past that new records are
+// dropped and counted rather than growing memory. A batch that fails is
+// kept and retried at the next flush, so the backend may see a record
+// twice. Close writes what is queued, retrying a failed batch a couple of
+// times, and syncs before closing the backend.
+// Transcript events of a session that opted out of transcript retention
+// (types.Consent) are persisted without their text; the first one is
+// rejected as transcript_persist (see consent.go).
+// Queue depth is exported as store_writebehind_queue_depth, flush and sync
+// latency as store_writebehind_flush_seconds and
+// store_writebehind_sync_seconds, and records as
+// store_writebehind_records_total{outcome}.
+
+// closeFlushTries bounds the attempts to write the queue on Close.
+const closeFlushTries = 3
+
+// Record is one write queued for the persistence backend: an event or a
+// network stats sample of a session.
+type Record struct {
+	Kind      string              `json:"kind"` // "event" or "network_stats"
+	SessionID string              `json:"session_id"`
+	Event     *types.Event        `json:"event,omitempty"`
+	Stats     *types.NetworkStats `json:"stats,omitempty"`
+}
+
+// Persister is the durable side of a disk or SQL backend. WriteBatch
+// writes records in order; Sync makes everything written so far durable.
+// WriteBatch and Sync are called from different goroutines.
+type Persister interface {
+	WriteBatch(recs []Record) error
+	Sync() error
+	Close() error
+}
+
+// WriteBehindConfig sizes the write-behind buffer; zero fields use the
+// defaults.
+type WriteBehindConfig struct {
+	MaxBatch   int           // records per WriteBatch (500)
+	MaxQueue   int           // records held before dropping (50000)
+	FlushEvery time.Duration // longest a record waits to be written (200ms)
+	SyncEvery  time.Duration // how often written records are synced (1s)
+}
+
+func (c WriteBehindConfig) withDefaults() WriteBehindConfig {
+	if c.MaxBatch <= 0 {
+		c.MaxBatch = 500
+	}
+	if c.MaxQueue <= 0 {
+		c.MaxQueue = 50000
+	}
+	if c.FlushEvery <= 0 {
+		c.FlushEvery = 200 * time.Millisecond
+	}
+	if c.SyncEvery <= 0 {
+		c.SyncEvery = time.Second
+	}
+	return c
+}
+
+// WriteBehind is a Store that also persists its high-rate writes through a
+// Persister, in the background.
+type WriteBehind struct {
+	Store
+	p   Persister
+	cfg WriteBehindConfig
+
+	mu     sync.Mutex
+	queue  []Record
+	closed bool
+	dirty  bool // written since the last sync
+	// sessions whose transcript_persist rejection is on record
+	rejected map[string]bool
+
+	kick     chan struct{}
+	stop     chan struct{}
+	done     chan struct{}
+	closeErr error
+}
+
+var _ Store = (*WriteBehind)(nil)
+
+// NewWriteBehind wraps st and starts writing to p; call Close on shutdown.
+func NewWriteBehind(st Store, p Persister, cfg WriteBehindConfig) *WriteBehind {
+	w := &WriteBehind{
+		Store:    st,
+		p:        p,
+		cfg:      cfg.withDefaults(),
+		rejected: make(map[string]bool),
+		kick:     make(chan struct{}, 1),
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+	go w.run()
+	return w
+}
+
+// AppendEvent appends to the wrapped store and queues the event.
+func (w *WriteBehind) AppendEvent(sessionID, typ string, payload map[string]any) types.Event {
+	ev := w.Store.AppendEvent(sessionID, typ, payload)
+	rec := ev
+	if types.TranscriptEvent(typ) && w.Store.GetConsent(sessionID).NoTranscriptRetention {
+		rec.Payload = RedactPayload(ev.Payload)
+		w.mu.Lock()
+		first := !w.rejected[sessionID]
+		w.rejected[sessionID] = true
+		w.mu.Unlock()
+		if first {
+			Reject(w, sessionID, "transcript_persist")
+		}
+	}
+	w.enqueue(Record{Kind: "event", SessionID: sessionID, Event: &rec})
+	return ev
+}
+
+// AppendNetworkStats records to the wrapped store and queues the sample.
+func (w *WriteBehind) AppendNetworkStats(sessionID string, ns types.NetworkStats) {
+	w.Store.AppendNetworkStats(sessionID, ns)
+	w.enqueue(Record{Kind: "network_stats", SessionID: sessionID, Stats: &ns})
+}
+
+func (w *WriteBehind) enqueue(r Record) {
+	w.mu.Lock()
+	if w.closed || len(w.queue) >= w.cfg.MaxQueue {
+		w.mu.Unlock()
+		metricWriteBehindRecords.WithLabelValues("dropped").Inc()
+		return
+	}
+	w.queue = append(w.queue, r)
+	n := len(w.queue)
+	w.mu.Unlock()
+	metricWriteBehindQueue.Set(float64(n))
+	if n >= w.cfg.MaxBatch {
+		select {
+		case w.kick <- struct{}{}:
+		default:
+		}
+	}
+}
+
+func (w *WriteBehind) run() {
+	defer close(w.done)
+	flushTick := time.NewTicker(w.cfg.FlushEvery)
+	defer flushTick.Stop()
+	syncTick := time.NewTicker(w.cfg.SyncEvery)
+	defer syncTick.Stop()
+	syncing := make(chan struct{}, 1) // one Sync in flight at a time
+	for {
+		select {
+		case <-w.kick:
+			w.flush()
+		case <-flushTick.C:
+			w.flush()
+		case <-syncTick.C:
+			select {
+			case syncing <- struct{}{}:
+				go func() {
+					w.sync()
+					<-syncing
+				}()
+			default:
+			}
+		case <-w.stop:
+			for try := 0; try < closeFlushTries; try++ {
+				if w.flush() == 0 {
+					break
+				}
+			}
+			syncing <- struct{}{} // wait out a Sync in flight
+			w.sync()
+			w.closeErr = w.p.Close()
+			return
+		}
+	}
+}
+
+// flush writes the queue in batches; a failed batch and the rest stay
+// queued for the next flush. It returns how many records are queued.
+func (w *WriteBehind) flush() int {
+	w.mu.Lock()
+	pending := w.queue
+	w.queue = nil
+	w.mu.Unlock()
+
+	for len(pending) > 0 {
+		batch := pending[:min(len(pending), w.cfg.MaxBatch)]
+		start := time.Now()
+		err := w.p.WriteBatch(batch)
+		metricWriteBehindFlush.Observe(time.Since(start).Seconds())
+		if err != nil {
+			log.Printf("[store] write-behind: writing %d records: %v", len(batch), err)
+			metricWriteBehindRecords.WithLabelValues("failed").Add(float64(len(batch)))
+			break
+		}
+		metricWriteBehindRecords.WithLabelValues("written").Add(float64(len(batch)))
+		pending = pending[len(batch):]
+		w.mu.Lock()
+		w.dirty = true
+		w.mu.Unlock()
+	}
+
+	w.mu.Lock()
+	if len(pending) > 0 {
+		// Keep order: the unwritten records go ahead of newer ones, within the cap
+		w.queue = append(pending, w.queue...)
+		if over := len(w.queue) - w.cfg.MaxQueue; over > 0 {
+			w.queue = w.queue[:w.cfg.MaxQueue]
+			metricWriteBehindRecords.WithLabelValues("dropped").Add(float64(over))
+		}
+	}
+	n := len(w.queue)
+	w.mu.Unlock()
+	metricWriteBehindQueue.Set(float64(n))
+	return n
+}
+
+// sync makes written records durable when there are any.
+func (w *WriteBehind) sync() {
+	w.mu.Lock()
+	dirty := w.dirty
+	w.dirty = false
+	w.mu.Unlock()
+	if !dirty {
+		return
+	}
+	start := time.Now()
+	if err := w.p.Sync(); err != nil {
+		log.Printf("[store] write-behind: sync: %v", err)
+		w.mu.Lock()
+		w.dirty = true
+		w.mu.Unlock()
+	}
+	metricWriteBehindSync.Observe(time.Since(start).Seconds())
+}
+
+// Close stops queueing, writes and syncs what is queued and closes the
+// backend, or gives up when ctx ends first.
+func (w *WriteBehind) Close(ctx context.Context) error {
+	w.mu.Lock()
+	if w.closed {
+		w.mu.Unlock()
+		return nil
+	}
+	w.closed = true
+	w.mu.Unlock()
+	close(w.stop)
+	select {
+	case <-w.done:
+		return w.closeErr
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// FilePersister appends records to a file as JSON lines, the simplest disk
+// backend.
+type FilePersister struct {
+	mu sync.Mutex
+	f  *os.File
+	bw *bufio.Writer
+}
+
+// NewFilePersister opens path for appending, creating it if needed.
+func NewFilePersister(path string) (*FilePersister, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	return &FilePersister{f: f, bw: bufio.NewWriterSize(f, 64<<10)}, nil
+}
+
+// WriteBatch writes recs with one write to the file, without syncing.
+func (p *FilePersister) WriteBatch(recs []Record) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	enc := json.NewEncoder(p.bw)
+	for i := range recs {
+		if err := enc.Encode(&recs[i]); err != nil {
+			return err
+		}
+	}
+	return p.bw.Flush()
+}
+
+// Sync fsyncs the file; it doesn't block WriteBatch.
+func (p *FilePersister) Sync() error { return p.f.Sync() }
+
+func (p *FilePersister) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if err := p.bw.Flush(); err != nil {
+		p.f.Close()
+		return err
+	}
+	return p.f.Close()
+}
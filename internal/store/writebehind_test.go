@@ -0,0 +1,174 @@
+package store
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"yuzu/agent/internal/types"
+)
+
+// memPersister records batches; fail makes the next WriteBatch fail.
+type memPersister struct {
+	mu      sync.Mutex
+	batches [][]Record
+	syncs   int
+	fail    bool
+	closed  bool
+}
+
+func (p *memPersister) WriteBatch(recs []Record) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.fail {
+		p.fail = false
+		return errors.New("disk full")
+	}
+	p.batches = append(p.batches, append([]Record(nil), recs...))
+	return nil
+}
+
+func (p *memPersister) Sync() error {
+	p.mu.Lock()
+	p.syncs++
+	p.mu.Unlock()
+	return nil
+}
+
+func (p *memPersister) Close() error {
+	p.mu.Lock()
+	p.closed = true
+	p.mu.Unlock()
+	return nil
+}
+
+func TestWriteBehindBatchesAndFlushesOnClose(t *testing.T) {
+	p := &memPersister{fail: true}
+	w := NewWriteBehind(New(), p, WriteBehindConfig{MaxBatch: 4, FlushEvery: time.Hour, SyncEvery: time.Hour})
+	w.CreateSession(&types.Session{ID: "s1"})
+	for i := 0; i < 10; i++ {
+		w.AppendEvent("s1", "vad_start", map[string]any{"i": i})
+	}
+	w.AppendNetworkStats("s1", types.NetworkStats{Ts: time.Unix(1, 0)})
+	// Reads don't wait for the backend
+	if n := len(w.ListEvents("s1")); n != 10 {
+		t.Fatalf("events in the store = %d, want 10", n)
+	}
+	if err := w.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	var got []Record
+	for _, b := range p.batches {
+		if len(b) > 4 {
+			t.Errorf("batch of %d over MaxBatch", len(b))
+		}
+		got = append(got, b...)
+	}
+	// The failed first batch was retried ahead of everything else
+	if len(got) != 11 || got[0].Event.Payload["i"] != 0 || got[9].Event.Payload["i"] != 9 || got[10].Kind != "network_stats" {
+		t.Fatalf("written = %d records, first %+v", len(got), got[0])
+	}
+	if p.syncs != 1 || !p.closed {
+		t.Errorf("syncs = %d, closed = %v; want one sync before closing", p.syncs, p.closed)
+	}
+	w.AppendEvent("s1", "late", nil)
+	if w.queue != nil {
+		t.Error("events after Close should not be queued")
+	}
+}
+
+func TestWriteBehindBoundsQueue(t *testing.T) {
+	p := &memPersister{}
+	w := NewWriteBehind(New(), p, WriteBehindConfig{MaxBatch: 100, MaxQueue: 3, FlushEvery: time.Hour, SyncEvery: time.Hour})
+	w.CreateSession(&types.Session{ID: "s1"})
+	for i := 0; i < 5; i++ {
+		w.AppendEvent("s1", "feature", nil)
+	}
+	w.mu.Lock()
+	n := len(w.queue)
+	w.mu.Unlock()
+	if n != 3 {
+		t.Fatalf("queued %d, want the cap of 3", n)
+	}
+	w.Close(context.Background())
+}
+
+func TestFilePersister(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events.jsonl")
+	p, err := NewFilePersister(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := NewWriteBehind(New(), p, WriteBehindConfig{FlushEvery: time.Millisecond, SyncEvery: time.Millisecond})
+	w.CreateSession(&types.Session{ID: "s1"})
+	w.AppendEvent("s1", "bot_log", map[string]any{"line": "hello"})
+	if err := w.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	sc := bufio.NewScanner(f)
+	var lines []Record
+	for sc.Scan() {
+		var r Record
+		if err := json.Unmarshal(sc.Bytes(), &r); err != nil {
+			t.Fatal(err)
+		}
+		lines = append(lines, r)
+	}
+	if len(lines) != 1 || lines[0].SessionID != "s1" || lines[0].Event.Type != "bot_log" {
+		t.Fatalf("file = %+v", lines)
+	}
+}
+
+func TestWriteBehindRedactsOptedOutTranscripts(t *testing.T) {
+	p := &memPersister{}
+	w := NewWriteBehind(New(), p, WriteBehindConfig{FlushEvery: time.Hour, SyncEvery: time.Hour})
+	w.CreateSession(&types.Session{ID: "s1", Consent: types.Consent{NoTranscriptRetention: true}})
+	w.CreateSession(&types.Session{ID: "s2"})
+	for _, id := range []string{"s1", "s2"} {
+		w.AppendEvent(id, "transcript_final", map[string]any{"text": "hello there"})
+		w.AppendEvent(id, "agent_text", map[string]any{"text": "hi"})
+	}
+	// The live log keeps the text while the call runs
+	if txt := w.ListEvents("s1")[0].Payload["text"]; txt != "hello there" {
+		t.Fatalf("live text = %v", txt)
+	}
+	if err := w.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	persisted := map[string]int{}
+	for _, b := range p.batches {
+		for _, r := range b {
+			if !types.TranscriptEvent(r.Event.Type) {
+				continue
+			}
+			if _, ok := r.Event.Payload["text"]; ok {
+				persisted[r.SessionID]++
+			}
+		}
+	}
+	if persisted["s1"] != 0 || persisted["s2"] != 2 {
+		t.Fatalf("transcript text persisted per session = %v", persisted)
+	}
+	rejected := 0
+	for _, e := range w.ListEvents("s1") {
+		if e.Type == "recording_rejected" && e.Payload["what"] == "transcript_persist" {
+			rejected++
+		}
+	}
+	if rejected != 1 {
+		t.Fatalf("recording_rejected events = %d, want 1", rejected)
+	}
+}
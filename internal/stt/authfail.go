@@ -0,0 +1,99 @@
+package stt
+
+import (
+    "log"
+    "sync/atomic"
+    "time"
+
+    "yuzu/agent/internal/secrets"
+    pb "yuzu/agent/internal/stt/pb"
+)
+
+// authfail.go stops a rejected Deepgram key from turning into a retry
+// storm. When a connect gets 401 or 403, the connection re-reads its key
+// (DEEPGRAM_API_KEY_FILE or the secrets provider) and tries once more only
+// if the key changed. Otherwise the key is marked bad for the whole sidecar:
+//
+//   - the session gets a fatal auth_failed Error (Error.fatal), and its
+//     connection stops dialing
+//   - new sessions get the same error at once instead of connecting
+//   - /readyz reports not ready, so deployments fail fast on a bad key
+//
+// A stopped connection re-reads the key every STT_AUTH_RECHECK_S (default
+// 30) and /readyz on every probe; once it differs, the mark is cleared and
+// connections resume. Rejected connects are counted in
+// stt_auth_failures_total, and stt_auth_blocked is 1 while a key is marked
+// bad.
+
+var (
+    // badKey is the API key Deepgram last rejected, nil when none; an
+    // empty key can be rejected too
+    badKey atomic.Pointer[string]
+
+    authRecheck = time.Duration(atoiEnv("STT_AUTH_RECHECK_S", 30)) * time.Second
+)
+
+// keyBlocked reports whether key was rejected.
+func keyBlocked(key string) bool {
+    bad := badKey.Load()
+    return bad != nil && *bad == key
+}
+
+func blockKey(key string) {
+    badKey.Store(&key)
+    gaugeAuthBlocked.Set(1)
+}
+
+// unblockKey clears the mark if key is the one rejected.
+func unblockKey(key string) {
+    bad := badKey.Load()
+    if bad != nil && *bad == key && badKey.CompareAndSwap(bad, nil) {
+        gaugeAuthBlocked.Set(0)
+        log.Printf("[deepgram] API key changed; connecting again")
+    }
+}
+
+// authBlocked reports whether the sidecar holds a rejected key, clearing
+// the mark when the configured key has changed since.
+func authBlocked() bool {
+    bad := badKey.Load()
+    if bad == nil {
+        return false
+    }
+    if loadDeepgramKey() != *bad {
+        unblockKey(*bad)
+        return false
+    }
+    return true
+}
+
+// authFailed marks the connection's key bad after Deepgram rejected it
+// and reauth found no other. It returns once there is a key worth trying,
+// or false when the connection closed while waiting.
+func (d *DeepgramConn) authFailed(err error) bool {
+    blockKey(d.apiKey)
+    log.Printf("[deepgram] API key rejected (%v); not reconnecting until it changes", err)
+    d.emit(DGEvent{Type: "error", Text: err.Error(), Code: pb.ErrorCode_AUTH_FAILED, Fatal: true})
+    return d.waitForKey()
+}
+
+// waitForKey blocks while the connection's key is marked bad, and leaves
+// it holding the current key.
+func (d *DeepgramConn) waitForKey() bool {
+    t := time.NewTicker(authRecheck)
+    defer t.Stop()
+    for keyBlocked(d.apiKey) {
+        select {
+        case <-d.ctx.Done():
+            return false
+        case <-t.C:
+            secrets.Invalidate("DEEPGRAM_API_KEY")
+            if key := loadDeepgramKey(); key != d.apiKey {
+                unblockKey(d.apiKey)
+            }
+        }
+    }
+    // Another connection may have seen the change first
+    d.apiKey = loadDeepgramKey()
+    return true
+}
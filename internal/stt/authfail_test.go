@@ -0,0 +1,81 @@
+package stt
+
+import (
+    "context"
+    "net/http"
+    "net/http/httptest"
+    "os"
+    "path/filepath"
+    "strings"
+    "sync/atomic"
+    "testing"
+    "time"
+
+    pb "yuzu/agent/internal/stt/pb"
+)
+
+func TestRejectedKeyStopsReconnects(t *testing.T) {
+    keyFile := filepath.Join(t.TempDir(), "dg-key")
+    if err := os.WriteFile(keyFile, []byte("bad-key\n"), 0o600); err != nil {
+        t.Fatal(err)
+    }
+    t.Setenv("DEEPGRAM_API_KEY_FILE", keyFile)
+    defer func(d time.Duration) { authRecheck = d }(authRecheck)
+    authRecheck = 20 * time.Millisecond
+
+    var dials atomic.Int32
+    mock := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        dials.Add(1)
+        if r.Header.Get("Authorization") != "Token good-key" {
+            http.Error(w, "invalid credentials", http.StatusUnauthorized)
+            return
+        }
+        mockDeepgram(w, r)
+    }))
+    defer mock.Close()
+    ctx, cancel := context.WithCancel(context.Background())
+    defer cancel()
+    d := NewDeepgramConn(ctx, DGConfig{BaseURL: "ws" + strings.TrimPrefix(mock.URL, "http")}, "bad-key")
+    srv := &STTServer{ready: true}
+    d.Start()
+
+    e := <-d.Events
+    if e.Type != "error" || e.Code != pb.ErrorCode_AUTH_FAILED || !e.Fatal {
+        t.Fatalf("first event = %+v, want a fatal auth_failed error", e)
+    }
+    time.Sleep(10 * authRecheck)
+    if n := dials.Load(); n != 1 {
+        t.Fatalf("dialed %d times with a rejected key", n)
+    }
+    if srv.Ready() {
+        t.Fatal("ready with a rejected key")
+    }
+
+    // A session opened meanwhile fails without dialing
+    d2 := NewDeepgramConn(ctx, DGConfig{BaseURL: "ws" + strings.TrimPrefix(mock.URL, "http")}, "bad-key")
+    d2.Start()
+    if e := <-d2.Events; !e.Fatal {
+        t.Fatalf("second connection's first event = %+v", e)
+    }
+    if n := dials.Load(); n != 1 {
+        t.Fatalf("second connection dialed (%d dials)", n)
+    }
+
+    // Rotating the key resumes both
+    if err := os.WriteFile(keyFile, []byte("good-key\n"), 0o600); err != nil {
+        t.Fatal(err)
+    }
+    for _, c := range []*DeepgramConn{d, d2} {
+        select {
+        case e := <-c.Events:
+            if e.Type != "reconnected" {
+                t.Fatalf("after rotation: %+v", e)
+            }
+        case <-time.After(5 * time.Second):
+            t.Fatal("no reconnect after the key changed")
+        }
+    }
+    if !srv.Ready() {
+        t.Fatal("not ready after the key changed")
+    }
+}
@@ -0,0 +1,475 @@
+package stt
+
+import (
+    "context"
+    "encoding/binary"
+    "encoding/json"
+    "fmt"
+    "math"
+    "math/rand"
+    "net"
+    "net/http"
+    "net/http/httptest"
+    "os"
+    "path/filepath"
+    "runtime"
+    "sort"
+    "strings"
+    "sync"
+    "syscall"
+    "time"
+
+    "google.golang.org/grpc"
+    "google.golang.org/grpc/credentials/insecure"
+    "nhooyr.io/websocket"
+
+    pb "yuzu/agent/internal/stt/pb"
+)
+
+// bench.go is the sidecar's self-benchmark (stt-sidecar --bench). It runs
+// the real gRPC server on a private UDS against an in-process mock of the
+// Deepgram socket and drives N sessions with generated audio, the way the
+// gateway does: ControlStart, then 20ms AudioChunks paced in real time (or
+// faster), then Close. The mock answers like the provider: interims while
+// it hears speech, a final after a short silence, then UtteranceEnd. The
+// report covers CPU and memory per session, provider queue depth, dropped
+// frames and final latency, so a change to the audio path can be measured
+// without a Deepgram key.
+
+// BenchConfig describes a run.
+type BenchConfig struct {
+    Sessions int
+    Duration time.Duration
+    // Source is "tone" (voiced harmonics), "noise", or a file of PCM16 16kHz
+    // mono audio, looped; a WAV header is skipped.
+    Source string
+    // Speed is audio seconds sent per wall-clock second per session.
+    Speed float64
+    // Talk and Pause alternate for generated sources.
+    Talk, Pause time.Duration
+    // Ramp spreads session starts so they don't all connect at once.
+    Ramp time.Duration
+}
+
+// BenchReport is the outcome of a run.
+type BenchReport struct {
+    Sessions        int               `json:"sessions"`
+    WallSeconds     float64           `json:"wall_seconds"`
+    AudioSeconds    float64           `json:"audio_seconds_sent"`
+    FramesSent      uint64            `json:"frames_sent"`
+    Drops           map[string]uint64 `json:"drops"`
+    Interims        int               `json:"interims"`
+    Finals          int               `json:"finals"`
+    Errors          int               `json:"errors"`
+    FinalLatencyP50 float64           `json:"final_latency_p50_ms"`
+    FinalLatencyP95 float64           `json:"final_latency_p95_ms"`
+    QueueDepthAvg   float64           `json:"provider_queue_depth_avg"`
+    QueueDepthMax   int               `json:"provider_queue_depth_max"`
+    CPUSeconds      float64           `json:"cpu_seconds"`
+    CPUPerSession   float64           `json:"cpu_pct_of_core_per_session"`
+    HeapPerSession  float64           `json:"heap_bytes_per_session"`
+    Goroutines      int               `json:"goroutines_per_session"`
+}
+
+// String renders the report for a terminal.
+func (r BenchReport) String() string {
+    var drops []string
+    for k, v := range r.Drops {
+        drops = append(drops, fmt.Sprintf("%s=%d", k, v))
+    }
+    sort.Strings(drops)
+    if len(drops) == 0 {
+        drops = []string{"none"}
+    }
+    return fmt.Sprintf(`sessions            %d
+wall                %.1fs
+audio sent          %.1fs (%d frames)
+drops               %s
+interims / finals   %d / %d (errors %d)
+final latency       p50 %.0fms  p95 %.0fms
+provider queue      avg %.2f  max %d
+cpu                 %.2fs total, %.2f%% of a core per session
+heap per session    %.0f KiB
+goroutines/session  %d
+`, r.Sessions, r.WallSeconds, r.AudioSeconds, r.FramesSent, strings.Join(drops, " "), r.Interims, r.Finals, r.Errors,
+        r.FinalLatencyP50, r.FinalLatencyP95, r.QueueDepthAvg, r.QueueDepthMax, r.CPUSeconds, r.CPUPerSession, r.HeapPerSession/1024, r.Goroutines)
+}
+
+const (
+    benchFrameMs    = 20
+    benchFrameBytes = 16000 * 2 * benchFrameMs / 1000
+)
+
+// RunBench runs cfg and reports. It points DEEPGRAM_WS_URL at the mock and
+// turns off audio sample dumps for the rest of the process, so it is meant
+// for a process started just for the bench.
+func RunBench(ctx context.Context, cfg BenchConfig) (BenchReport, error) {
+    if cfg.Sessions <= 0 {
+        cfg.Sessions = 1
+    }
+    if cfg.Speed <= 0 {
+        cfg.Speed = 1
+    }
+    if cfg.Talk <= 0 {
+        cfg.Talk = 3 * time.Second
+    }
+    if cfg.Pause <= 0 {
+        cfg.Pause = 1500 * time.Millisecond
+    }
+    audio, err := benchAudio(cfg)
+    if err != nil {
+        return BenchReport{}, err
+    }
+
+    mock := httptest.NewServer(http.HandlerFunc(mockDeepgram))
+    defer mock.Close()
+    os.Setenv("DEEPGRAM_WS_URL", "ws"+strings.TrimPrefix(mock.URL, "http")+"/v1/listen")
+    os.Setenv("DEEPGRAM_API_KEY", "bench")
+    saveSamples = false
+
+    dir, err := os.MkdirTemp("", "stt-bench")
+    if err != nil {
+        return BenchReport{}, err
+    }
+    defer os.RemoveAll(dir)
+    sock := filepath.Join(dir, "stt.sock")
+    l, err := net.Listen("unix", sock)
+    if err != nil {
+        return BenchReport{}, err
+    }
+    gs := grpc.NewServer()
+    srv := NewSTTServer()
+    pb.RegisterSTTServer(gs, srv)
+    go gs.Serve(l)
+    defer gs.Stop()
+
+    conn, err := grpc.NewClient("unix://"+sock, grpc.WithTransportCredentials(insecure.NewCredentials()))
+    if err != nil {
+        return BenchReport{}, err
+    }
+    defer conn.Close()
+    client := pb.NewSTTClient(conn)
+
+    runtime.GC()
+    var before runtime.MemStats
+    runtime.ReadMemStats(&before)
+    goBefore := runtime.NumGoroutine()
+    cpuBefore := cpuTime()
+    start := time.Now()
+
+    ctx, cancel := context.WithTimeout(ctx, cfg.Duration)
+    defer cancel()
+    results := make([]benchSession, cfg.Sessions)
+    var wg sync.WaitGroup
+    for i := range results {
+        wg.Add(1)
+        go func(i int) {
+            defer wg.Done()
+            if cfg.Ramp > 0 {
+                select {
+                case <-time.After(cfg.Ramp * time.Duration(i) / time.Duration(cfg.Sessions)):
+                case <-ctx.Done():
+                    return
+                }
+            }
+            results[i].run(ctx, client, fmt.Sprintf("bench-%04d", i), audio, cfg.Speed)
+        }(i)
+    }
+
+    // Sample provider queues and the heap while the sessions run
+    var depthSum float64
+    var depthN, depthMax int
+    var peak runtime.MemStats
+    var goPeak int
+    tick := time.NewTicker(100 * time.Millisecond)
+    defer tick.Stop()
+    done := make(chan struct{})
+    go func() { wg.Wait(); close(done) }()
+sample:
+    for {
+        select {
+        case <-done:
+            break sample
+        case <-tick.C:
+            // Steady state only: closing sockets briefly fan out goroutines
+            if ctx.Err() != nil {
+                continue
+            }
+            srv.mu.Lock()
+            for _, s := range srv.sess {
+                d := s.conn().QueueLen()
+                depthSum += float64(d)
+                depthN++
+                depthMax = max(depthMax, d)
+            }
+            srv.mu.Unlock()
+            var m runtime.MemStats
+            runtime.ReadMemStats(&m)
+            if m.HeapInuse > peak.HeapInuse {
+                peak = m
+            }
+            goPeak = max(goPeak, runtime.NumGoroutine())
+        }
+    }
+
+    wall := time.Since(start)
+    rep := BenchReport{
+        Sessions:      cfg.Sessions,
+        WallSeconds:   wall.Seconds(),
+        Drops:         map[string]uint64{},
+        QueueDepthMax: depthMax,
+        CPUSeconds:    (cpuTime() - cpuBefore).Seconds(),
+    }
+    if depthN > 0 {
+        rep.QueueDepthAvg = depthSum / float64(depthN)
+    }
+    rep.CPUPerSession = 100 * rep.CPUSeconds / wall.Seconds() / float64(cfg.Sessions)
+    if peak.HeapInuse > before.HeapInuse {
+        rep.HeapPerSession = float64(peak.HeapInuse-before.HeapInuse) / float64(cfg.Sessions)
+    }
+    rep.Goroutines = (goPeak - goBefore) / cfg.Sessions
+    var lat []float64
+    for _, r := range results {
+        rep.FramesSent += r.frames
+        rep.Interims += r.interims
+        rep.Finals += r.finals
+        rep.Errors += r.errors
+        for k, v := range r.drops {
+            rep.Drops[k] += v
+        }
+        lat = append(lat, r.latency...)
+    }
+    rep.AudioSeconds = float64(rep.FramesSent) * benchFrameMs / 1000
+    sort.Float64s(lat)
+    rep.FinalLatencyP50 = percentile(lat, 0.5)
+    rep.FinalLatencyP95 = percentile(lat, 0.95)
+    return rep, nil
+}
+
+// JSON renders the report for scripts.
+func (r BenchReport) JSON() string {
+    b, _ := json.MarshalIndent(r, "", "  ")
+    return string(b)
+}
+
+// benchSession is one simulated gateway stream.
+type benchSession struct {
+    frames   uint64
+    interims int
+    finals   int
+    errors   int
+    drops    map[string]uint64
+    latency  []float64 // ms from the end of a talk spurt to its final
+}
+
+func (b *benchSession) run(ctx context.Context, client pb.STTClient, id string, audio benchAudioSource, speed float64) {
+    // The stream outlives ctx long enough to close cleanly
+    sctx, cancel := context.WithCancel(context.Background())
+    defer cancel()
+    stream, err := client.Session(sctx)
+    if err != nil {
+        b.errors++
+        return
+    }
+    var mu sync.Mutex
+    var spurtEnd time.Time
+    recvDone := make(chan struct{})
+    go func() {
+        defer close(recvDone)
+        for {
+            msg, err := stream.Recv()
+            if err != nil {
+                return
+            }
+            mu.Lock()
+            switch {
+            case msg.GetInterim() != nil:
+                b.interims++
+            case msg.GetFinal() != nil:
+                b.finals++
+                if !spurtEnd.IsZero() {
+                    b.latency = append(b.latency, float64(time.Since(spurtEnd).Milliseconds()))
+                    spurtEnd = time.Time{}
+                }
+            case msg.GetError() != nil:
+                b.errors++
+            case msg.GetMetrics() != nil && msg.GetMetrics().GetFinal():
+                b.drops = msg.GetMetrics().GetDrops()
+            }
+            mu.Unlock()
+        }
+    }()
+
+    if err := stream.Send(&pb.ClientMessage{Msg: &pb.ClientMessage_Start{Start: &pb.ControlStart{SessionId: id, UtteranceId: id + "-u1", SampleRate: 16000, ProtocolVersion: "1"}}}); err != nil {
+        b.errors++
+        return
+    }
+    period := time.Duration(float64(benchFrameMs*time.Millisecond) / speed)
+    next := time.Now()
+    for i := 0; ctx.Err() == nil; i++ {
+        frame, talking, lastOfSpurt := audio.frame(i)
+        if err := stream.Send(&pb.ClientMessage{Msg: &pb.ClientMessage_Audio{Audio: &pb.AudioChunk{Pcm16K: frame, DurationMs: benchFrameMs}}}); err != nil {
+            b.errors++
+            break
+        }
+        b.frames++
+        if talking && lastOfSpurt {
+            mu.Lock()
+            spurtEnd = time.Now()
+            mu.Unlock()
+        }
+        next = next.Add(period)
+        if d := time.Until(next); d > 0 {
+            select {
+            case <-time.After(d):
+            case <-ctx.Done():
+            }
+        }
+    }
+    _ = stream.Send(&pb.ClientMessage{Msg: &pb.ClientMessage_Close{Close: &pb.SessionClose{}}})
+    select {
+    case <-recvDone:
+    case <-time.After(2 * time.Second):
+    }
+}
+
+// benchAudioSource yields 20ms frames; generated sources know where their
+// talk spurts end, file sources report every frame as talk.
+type benchAudioSource struct {
+    frames  [][]byte
+    talking []bool
+}
+
+// frame returns frame i (looping), whether it is speech, and whether it is
+// the last speech frame before a pause.
+func (a benchAudioSource) frame(i int) ([]byte, bool, bool) {
+    n := len(a.frames)
+    i %= n
+    talk := a.talking[i]
+    return a.frames[i], talk, talk && !a.talking[(i+1)%n]
+}
+
+func benchAudio(cfg BenchConfig) (benchAudioSource, error) {
+    var a benchAudioSource
+    switch cfg.Source {
+    case "", "tone", "noise":
+        talk := int(cfg.Talk / (benchFrameMs * time.Millisecond))
+        pause := int(cfg.Pause / (benchFrameMs * time.Millisecond))
+        r := rand.New(rand.NewSource(1))
+        for i := 0; i < talk+pause; i++ {
+            speaking := i < talk
+            f := make([]byte, benchFrameBytes)
+            for j := 0; j < benchFrameBytes/2; j++ {
+                t := float64(i*benchFrameBytes/2+j) / 16000
+                v := r.NormFloat64() * 20 // room tone
+                if speaking && cfg.Source == "noise" {
+                    v = r.NormFloat64() * 3000
+                } else if speaking {
+                    env := 0.6 + 0.4*math.Sin(2*math.Pi*4*t)
+                    v += env * (5000*math.Sin(2*math.Pi*160*t) + 2000*math.Sin(2*math.Pi*320*t) + 800*math.Sin(2*math.Pi*640*t))
+                }
+                binary.LittleEndian.PutUint16(f[2*j:], uint16(int16(v)))
+            }
+            a.frames = append(a.frames, f)
+            a.talking = append(a.talking, speaking)
+        }
+    default:
+        b, err := os.ReadFile(cfg.Source)
+        if err != nil {
+            return a, err
+        }
+        if len(b) > 44 && string(b[:4]) == "RIFF" && string(b[8:12]) == "WAVE" {
+            b = b[44:]
+        }
+        for len(b) >= benchFrameBytes {
+            a.frames = append(a.frames, b[:benchFrameBytes])
+            a.talking = append(a.talking, calcRMS(b[:benchFrameBytes]) >= mockSpeechRMS)
+            b = b[benchFrameBytes:]
+        }
+        if len(a.frames) == 0 {
+            return a, fmt.Errorf("%s: less than %dms of audio", cfg.Source, benchFrameMs)
+        }
+    }
+    return a, nil
+}
+
+func percentile(sorted []float64, p float64) float64 {
+    if len(sorted) == 0 {
+        return 0
+    }
+    return sorted[int(p*float64(len(sorted)-1))]
+}
+
+// cpuTime is the process's user plus system CPU time so far.
+func cpuTime() time.Duration {
+    var ru syscall.Rusage
+    if err := syscall.Getrusage(syscall.RUSAGE_SELF, &ru); err != nil {
+        return 0
+    }
+    return time.Duration(ru.Utime.Nano() + ru.Stime.Nano())
+}
+
+// Mock provider timing, in 20ms frames of audio received.
+const (
+    mockSpeechRMS     = 300
+    mockInterimFrames = 10 // an interim every 200ms of speech
+    mockEndpointing   = 15 // final after 300ms of silence
+    mockUtteranceEnd  = 50 // UtteranceEnd after 1s of silence
+)
+
+var mockWords = strings.Fields("so I worked on the payments team where we moved billing to a new queue and cut latency in half")
+
+// mockDeepgram speaks enough of Deepgram's streaming protocol for the
+// sidecar: it counts loud frames, sends growing interims while they last,
+// a final once they stop, and UtteranceEnd after a longer silence.
+func mockDeepgram(w http.ResponseWriter, r *http.Request) {
+    ws, err := websocket.Accept(w, r, nil)
+    if err != nil {
+        return
+    }
+    defer ws.Close(websocket.StatusNormalClosure, "")
+    ctx := r.Context()
+    send := func(v map[string]any) bool {
+        b, _ := json.Marshal(v)
+        return ws.Write(ctx, websocket.MessageText, b) == nil
+    }
+    results := func(text string, final bool) map[string]any {
+        return map[string]any{"type": "Results", "is_final": final, "speech_final": final,
+            "channel": map[string]any{"alternatives": []any{map[string]any{"transcript": text}}}}
+    }
+    send(map[string]any{"type": "Metadata", "metadata": map[string]any{"model": "bench"}})
+    speech, silence := 0, 0
+    text := func() string { return strings.Join(mockWords[:min(1+speech/mockInterimFrames, len(mockWords))], " ") }
+    for {
+        typ, b, err := ws.Read(ctx)
+        if err != nil {
+            return
+        }
+        if typ != websocket.MessageBinary {
+            continue
+        }
+        if calcRMS(b) >= mockSpeechRMS {
+            if speech == 0 && silence >= mockUtteranceEnd {
+                send(map[string]any{"type": "SpeechStarted"})
+            }
+            speech++
+            silence = 0
+            if speech%mockInterimFrames == 0 && !send(results(text(), false)) {
+                return
+            }
+            continue
+        }
+        silence++
+        switch {
+        case speech > 0 && silence == mockEndpointing:
+            if !send(results(text(), true)) {
+                return
+            }
+            speech = 0
+        case silence == mockUtteranceEnd:
+            if !send(map[string]any{"type": "UtteranceEnd"}) {
+                return
+            }
+        }
+    }
+}
@@ -0,0 +1,52 @@
+package stt
+
+import (
+    "context"
+    "os"
+    "path/filepath"
+    "testing"
+    "time"
+)
+
+func TestRunBench(t *testing.T) {
+    if testing.Short() {
+        t.Skip("runs real sessions for a few seconds")
+    }
+    t.Setenv("DEEPGRAM_WS_URL", "")
+    t.Setenv("DEEPGRAM_API_KEY", "")
+    rep, err := RunBench(context.Background(), BenchConfig{
+        Sessions: 2,
+        Duration: 2500 * time.Millisecond,
+        Talk:     600 * time.Millisecond,
+        Pause:    600 * time.Millisecond,
+    })
+    if err != nil {
+        t.Fatal(err)
+    }
+    if rep.Errors != 0 || rep.FramesSent < 100 {
+        t.Fatalf("report = %+v", rep)
+    }
+    // Two talk spurts per session fit in the run; the mock finalizes each
+    if rep.Interims == 0 || rep.Finals < 2 || rep.FinalLatencyP50 <= 0 {
+        t.Errorf("interims=%d finals=%d p50=%.0fms", rep.Interims, rep.Finals, rep.FinalLatencyP50)
+    }
+}
+
+func TestBenchAudioFile(t *testing.T) {
+    path := filepath.Join(t.TempDir(), "in.wav")
+    wav := append([]byte("RIFF\x00\x00\x00\x00WAVE"), make([]byte, 32)...)
+    wav = append(wav, make([]byte, 3*benchFrameBytes+10)...)
+    if err := os.WriteFile(path, wav, 0o644); err != nil {
+        t.Fatal(err)
+    }
+    a, err := benchAudio(BenchConfig{Source: path})
+    if err != nil || len(a.frames) != 3 {
+        t.Fatalf("frames = %d, err = %v; want the header skipped and 3 whole frames", len(a.frames), err)
+    }
+    if _, talking, _ := a.frame(4); talking {
+        t.Error("silent frame reported as speech")
+    }
+    if _, err := benchAudio(BenchConfig{Source: filepath.Join(t.TempDir(), "missing")}); err == nil {
+        t.Error("missing file should fail")
+    }
+}
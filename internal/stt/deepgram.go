@@ -3,17 +3,26 @@ package stt
 import (
     "context"
     "encoding/json"
+    "errors"
     "fmt"
     "log"
     "net/http"
     "net/url"
     "os"
     "strings"
+    "sync/atomic"
     "time"
 
     "nhooyr.io/websocket"
+
+    "yuzu/agent/internal/chaos"
+    "yuzu/agent/internal/errdefs"
+    "yuzu/agent/internal/secrets"
+    pb "yuzu/agent/internal/stt/pb"
 )
 
+var errCircuitOpen = errdefs.ErrCircuitOpen
+
 // DeepgramConn maintains a single live websocket connection to Deepgram
 // for a session, sending PCM16@16k audio and receiving transcript events.
 type DeepgramConn struct {
@@ -23,18 +32,29 @@ type DeepgramConn struct {
     apiKey string
     url    string
 
+    // What serves the session, for Connected (see connectedMsg)
+    model     string
+    language  string
+    handshake atomic.Pointer[dgHandshake]
+
     ws *websocket.Conn
 
     // Outbound audio queue; caller should drop-latest upstream on pressure
-    sendQ chan []byte
+    sendQ chan *[]byte
     // Events channel emits interim/final transcripts
     Events chan DGEvent
 
     // Backoff/circuit
     fails    []time.Time
-    circuit  time.Time
+    circuit  atomic.Int64 // UnixNano until which connects are refused
+    // Socket up; read by Send to attribute drops (see drops.go)
+    connected atomic.Bool
     maxAge   time.Duration
 
+    // Recent raw frames for the admin endpoint (see framering.go); nil disables
+    frames  *frameRing
+    logRaw  bool
+
     // Track last interim/final text for UtteranceEnd fallback
     lastText      string
     lastFinalText string
@@ -44,7 +64,21 @@ type DGEvent struct {
     Type        string // "interim" | "final" | "error"
     UtteranceID string
     Text        string
+    Code        pb.ErrorCode // class of an "error" (see errcode.go)
     Raw         map[string]any
+    // Word timing of a final, for pace estimation; zero when the provider sent none
+    Words  int
+    Speech time.Duration
+    // Speaker is the diarized speaker of most words, 1-based; 0 without diarization
+    Speaker int
+    // Fatal marks an error the connection won't retry on its own (see authfail.go)
+    Fatal bool
+}
+
+// dgHandshake describes the current provider socket.
+type dgHandshake struct {
+    connect   time.Duration
+    requestID string
 }
 
 type DGConfig struct {
@@ -54,20 +88,25 @@ type DGConfig struct {
     Interim        bool
     UtterEndMs     int
     VADEvents      bool
+    Diarize        bool
     BaseURL        string
     SocketMaxAgeS  int
 }
 
 func NewDeepgramConn(parent context.Context, cfg DGConfig, apiKey string) *DeepgramConn {
     ctx, cancel := context.WithCancel(parent)
+    model, language := orDefault(cfg.Model, "nova-2"), orDefault(cfg.Language, "en-US")
     q := url.Values{}
-    q.Set("model", orDefault(cfg.Model, "nova-2"))
-    q.Set("language", orDefault(cfg.Language, "en-US"))
+    q.Set("model", model)
+    q.Set("language", language)
     q.Set("smart_format", "true")
     q.Set("endpointing", fmt.Sprintf("%d", nzd(cfg.EndpointingMs, 1000)))
     q.Set("interim_results", fmt.Sprintf("%t", cfg.Interim))
     q.Set("utterance_end_ms", fmt.Sprintf("%d", nzd(cfg.UtterEndMs, 1500)))
     q.Set("vad_events", fmt.Sprintf("%t", cfg.VADEvents))
+    if cfg.Diarize {
+        q.Set("diarize", "true")
+    }
     q.Set("encoding", "linear16")
     q.Set("sample_rate", "16000")
     q.Set("channels", "1")
@@ -76,56 +115,123 @@ func NewDeepgramConn(parent context.Context, cfg DGConfig, apiKey string) *Deepg
         base = "wss://api.deepgram.com/v1/listen"
     }
     return &DeepgramConn{
-        ctx:    ctx,
-        cancel: cancel,
-        apiKey: apiKey,
-        url:    base + "?" + q.Encode(),
-        sendQ:  make(chan []byte, 8),
-        Events: make(chan DGEvent, 32),
-        maxAge: time.Duration(nzd(cfg.SocketMaxAgeS, 900)) * time.Second,
+        ctx:      ctx,
+        cancel:   cancel,
+        apiKey:   apiKey,
+        url:      base + "?" + q.Encode(),
+        model:    model,
+        language: language,
+        sendQ:    make(chan *[]byte, 8),
+        Events:   make(chan DGEvent, 32),
+        maxAge:   time.Duration(nzd(cfg.SocketMaxAgeS, 900)) * time.Second,
+        frames:   newFrameRing(atoiEnv("STT_DEBUG_FRAMES", 200)),
+        logRaw:   strings.EqualFold(os.Getenv("STT_LOG_RAW_FRAMES"), "true"),
     }
 }
 
+// connectedMsg describes the provider serving sessionID. The handshake
+// fields stay zero until the socket is up.
+func (d *DeepgramConn) connectedMsg(sessionID string) *pb.ServerMessage {
+    c := &pb.Connected{SessionId: sessionID, Provider: "deepgram", Model: d.model, Language: d.language}
+    if h := d.handshake.Load(); h != nil {
+        c.ConnectMs, c.RequestId = uint32(h.connect.Milliseconds()), h.requestID
+    }
+    return &pb.ServerMessage{Msg: &pb.ServerMessage_Connected{Connected: c}}
+}
+
 func (d *DeepgramConn) Start() {
     go d.run()
 }
 
 func (d *DeepgramConn) Close() { d.cancel() }
 
-func (d *DeepgramConn) Send(pcm16k []byte) bool {
+// Send enqueues a frame for the provider. It returns "" when queued, and
+// the send loop puts the frame back in the pool once written (see
+// framepool.go); otherwise the frame is still the caller's and it returns
+// the reason it was dropped (see drops.go).
+func (d *DeepgramConn) Send(frame *[]byte) string {
     select {
-    case d.sendQ <- pcm16k:
-        return true
+    case d.sendQ <- frame:
+        return ""
     default:
-        return false
     }
+    switch {
+    case d.circuitOpen():
+        return dropCircuitOpen
+    case !d.connected.Load():
+        return dropSocketDead
+    }
+    return dropQueueFull
 }
 
+func (d *DeepgramConn) circuitOpen() bool { return time.Now().UnixNano() < d.circuit.Load() }
+
 func (d *DeepgramConn) QueueLen() int { return len(d.sendQ) }
 
 func (d *DeepgramConn) run() {
     defer close(d.Events)
     for {
-        if err := d.connectAndPump(); err != nil {
-            d.addFailure()
+        // A key already rejected fails at once instead of dialing (see authfail.go)
+        if keyBlocked(d.apiKey) {
+            d.emit(DGEvent{Type: "error", Text: "provider rejected the API key", Code: pb.ErrorCode_AUTH_FAILED, Fatal: true})
+            if !d.waitForKey() {
+                return
+            }
+        }
+        code := pb.ErrorCode_ERROR_CODE_UNSPECIFIED
+        if err := d.connectAndPump(); err != nil && !errors.Is(err, errRotate) {
+            code = classifyErr(err)
+            if d.ctx.Err() != nil {
+                return
+            }
+            // A rejected key is re-read, and retried only if it changed
+            if code == pb.ErrorCode_AUTH_FAILED {
+                metricAuthFailures.Inc()
+                if !d.reauth() {
+                    if !d.authFailed(err) {
+                        return
+                    }
+                    continue
+                }
+            }
+            // A socket dropped mid-stream is reconnected at once and not
+            // held against the circuit
+            if code != pb.ErrorCode_SOCKET_CLOSED {
+                d.addFailure()
+            }
             // emit error event so caller may choose to degrade
-            d.emit(DGEvent{Type: "error", Text: err.Error()})
+            d.emit(DGEvent{Type: "error", Text: err.Error(), Code: code})
         } else {
             d.resetFailures()
         }
         if d.ctx.Err() != nil {
             return
         }
-        // backoff
-        time.Sleep(d.nextBackoff())
+        time.Sleep(d.backoffFor(code))
     }
 }
 
+// reauth reloads the API key, picking up a rotated DEEPGRAM_API_KEY_FILE
+// or secret, and reports whether it changed.
+func (d *DeepgramConn) reauth() bool {
+    secrets.Invalidate("DEEPGRAM_API_KEY")
+    if key := loadDeepgramKey(); key != "" && key != d.apiKey {
+        log.Printf("[deepgram] auth failed; retrying with reloaded API key (len=%d)", len(key))
+        d.apiKey = key
+        return true
+    }
+    return false
+}
+
+// connectChaos injects faults into Deepgram connects; nil unless
+// CHAOS_ENABLED (see internal/chaos).
+var connectChaos = chaos.FromEnv(chaos.TargetSTT)
+
 func (d *DeepgramConn) connectAndPump() error {
     // circuit breaker
-    if time.Now().Before(d.circuit) {
+    if d.circuitOpen() {
         time.Sleep(500 * time.Millisecond)
-        return fmt.Errorf("circuit open")
+        return errdefs.CircuitOpen("deepgram")
     }
 
     hdr := make(http.Header)
@@ -136,16 +242,33 @@ func (d *DeepgramConn) connectAndPump() error {
     defer cancel()
     start := time.Now()
     log.Printf("[deepgram] connecting to %s (apiKey len=%d)", d.url, len(d.apiKey))
-    ws, _, err := websocket.Dial(ctx, d.url, &websocket.DialOptions{HTTPHeader: hdr})
+    // Injected faults stand in for a hung or failing provider
+    switch connectChaos.Inject(ctx) {
+    case chaos.Drop:
+        <-ctx.Done()
+        return errdefs.ProviderTransport("deepgram", ctx.Err())
+    case chaos.Error:
+        return errdefs.ProviderStatus("deepgram", http.StatusServiceUnavailable, chaos.ErrInjected.Error())
+    }
+    ws, resp, err := websocket.Dial(ctx, d.url, &websocket.DialOptions{HTTPHeader: hdr})
     if err != nil {
         log.Printf("[deepgram] connect error: %v", err)
-        return err
+        if resp != nil && resp.StatusCode != http.StatusSwitchingProtocols {
+            return errdefs.ProviderStatus("deepgram", resp.StatusCode, err.Error())
+        }
+        return errdefs.ProviderTransport("deepgram", err)
     }
-    log.Printf("[deepgram] connected in %dms", time.Since(start).Milliseconds())
-    metricConnectMS.Observe(float64(time.Since(start).Milliseconds()))
+    took := time.Since(start)
+    reqID := resp.Header.Get("dg-request-id")
+    log.Printf("[deepgram] connected in %dms request_id=%s", took.Milliseconds(), reqID)
+    metricConnectMS.Observe(float64(took.Milliseconds()))
     metricReconnects.Inc()
     d.ws = ws
+    d.handshake.Store(&dgHandshake{connect: took, requestID: reqID})
+    d.connected.Store(true)
     defer func() {
+        d.connected.Store(false)
+        d.handshake.Store(nil)
         _ = d.ws.Close(websocket.StatusNormalClosure, "bye")
         d.ws = nil
     }()
@@ -159,6 +282,8 @@ func (d *DeepgramConn) connectAndPump() error {
     var framesSent uint64
     go func() {
         defer close(sendDone)
+        // ws, not d.ws: the read loop's defer clears d.ws while this may
+        // still be writing
         // Keepalive: inject a silent 20ms frame if no data sent for a while
         keepAliveMs := atoiEnv("STT_KEEPALIVE_MS", 400)
         keepTicker := time.NewTicker(time.Duration(keepAliveMs) * time.Millisecond)
@@ -170,14 +295,16 @@ func (d *DeepgramConn) connectAndPump() error {
             select {
             case <-d.ctx.Done():
                 return
-            case b := <-d.sendQ:
-                if b == nil {
+            case frame := <-d.sendQ:
+                if frame == nil {
                     continue
                 }
+                b := *frame
                 wctx, cancel := context.WithTimeout(d.ctx, 5*time.Second)
-                err := d.ws.Write(wctx, websocket.MessageBinary, b)
+                err := ws.Write(wctx, websocket.MessageBinary, b)
                 cancel()
                 if err != nil {
+                    putFrame(frame)
                     log.Printf("[deepgram] write error: %v", err)
                     return
                 }
@@ -192,11 +319,12 @@ func (d *DeepgramConn) connectAndPump() error {
                     }
                     log.Printf("[deepgram] sent frames=%d bytes=%d%s", framesSent, bytesSent, hexPrefix)
                 }
+                putFrame(frame)
             case <-keepTicker.C:
                 // Only send keepalive if no recent data and the queue is empty
                 if len(d.sendQ) == 0 && time.Since(lastSend) >= time.Duration(keepAliveMs)*time.Millisecond {
                     wctx, cancel := context.WithTimeout(d.ctx, 2*time.Second)
-                    err := d.ws.Write(wctx, websocket.MessageBinary, silent)
+                    err := ws.Write(wctx, websocket.MessageBinary, silent)
                     cancel()
                     if err != nil {
                         log.Printf("[deepgram] keepalive write error: %v", err)
@@ -227,7 +355,7 @@ func (d *DeepgramConn) connectAndPump() error {
         // non-blocking rotation check
         select {
         case <-rotate:
-            return fmt.Errorf("rotate")
+            return errRotate
         default:
         }
         _, data, err := d.ws.Read(d.ctx)
@@ -238,17 +366,19 @@ func (d *DeepgramConn) connectAndPump() error {
         if len(data) == 0 {
             continue
         }
+        d.frames.add(time.Now(), data)
         var m map[string]any
         if err := json.Unmarshal(data, &m); err != nil {
             log.Printf("[deepgram] JSON parse error: %v, data: %s", err, string(data[:min(200, len(data))]))
             continue
         }
-        // Debug: log full raw response (truncated)
-        rawStr := string(data)
-        if len(rawStr) > 500 {
-            rawStr = rawStr[:500] + "..."
+        if d.logRaw {
+            rawStr := string(data)
+            if len(rawStr) > 500 {
+                rawStr = rawStr[:500] + "..."
+            }
+            log.Printf("[deepgram] recv raw: %s", rawStr)
         }
-        log.Printf("[deepgram] recv raw: %s", rawStr)
         // Parse Deepgram results shape leniently
         // Look for results.alternatives[0].transcript and results.is_final
         typ := toString(m["type"]) // may be "Results", "UtteranceEnd", "Metadata", "Error"
@@ -257,7 +387,7 @@ func (d *DeepgramConn) connectAndPump() error {
             msg := toString(m["error"]) 
             if msg == "" { msg = toString(m["message"]) }
             if msg == "" { msg = "provider_error" }
-            d.emit(DGEvent{Type: "error", Text: msg, Raw: m})
+            d.emit(DGEvent{Type: "error", Text: msg, Raw: m, Code: classifyFrame(m)})
             continue
         }
         if strings.EqualFold(typ, "Metadata") {
@@ -308,9 +438,12 @@ func (d *DeepgramConn) connectAndPump() error {
                 }
             }
             text := ""
+            words, speech, speaker := 0, time.Duration(0), 0
             if len(alts) > 0 {
                 if a0, ok := alts[0].(map[string]any); ok {
                     text = strings.TrimSpace(toString(a0["transcript"])) // Trim whitespace
+                    words, speech = wordTiming(a0)
+                    speaker = wordSpeaker(a0)
                 }
             }
             isFinal := toBool(m["is_final"]) || toBool(m["speech_final"])
@@ -324,7 +457,7 @@ func (d *DeepgramConn) connectAndPump() error {
                 if text != "" {
                     d.lastFinalText = text
                     log.Printf("[deepgram] emitting FINAL source=provider text=%q", text)
-                    d.emit(DGEvent{Type: "final", Text: text, Raw: m})
+                    d.emit(DGEvent{Type: "final", Text: text, Raw: m, Words: words, Speech: speech, Speaker: speaker})
                     metricFinalEmitted.WithLabelValues("provider").Inc()
                 } else {
                     log.Printf("[deepgram] skipping empty is_final result")
@@ -363,13 +496,27 @@ func (d *DeepgramConn) addFailure() {
     }
     d.fails = d.fails[:j]
     if len(d.fails) >= 3 {
-        d.circuit = time.Now().Add(30 * time.Second)
+        d.circuit.Store(time.Now().Add(30 * time.Second).UnixNano())
         metricCircuitOpens.Inc()
     }
 }
 
 func (d *DeepgramConn) resetFailures() { d.fails = nil }
 
+// backoffFor is the pause before reconnecting after a failure of class code.
+func (d *DeepgramConn) backoffFor(code pb.ErrorCode) time.Duration {
+    switch code {
+    case pb.ErrorCode_SOCKET_CLOSED:
+        return 250 * time.Millisecond
+    case pb.ErrorCode_RATE_LIMITED:
+        if b := d.nextBackoff(); b > 5*time.Second {
+            return b
+        }
+        return 5 * time.Second
+    }
+    return d.nextBackoff()
+}
+
 func (d *DeepgramConn) nextBackoff() time.Duration {
     n := len(d.fails)
     if n <= 0 {
@@ -399,6 +546,45 @@ func toBool(v any) bool {
     }
 }
 
+// wordTiming counts the words of an alternative and the time from the first
+// word's start to the last word's end (Deepgram reports seconds).
+func wordTiming(alt map[string]any) (int, time.Duration) {
+    ws, _ := alt["words"].([]any)
+    if len(ws) == 0 {
+        return 0, 0
+    }
+    first, _ := ws[0].(map[string]any)
+    last, _ := ws[len(ws)-1].(map[string]any)
+    start, _ := first["start"].(float64)
+    end, _ := last["end"].(float64)
+    if end <= start {
+        return len(ws), 0
+    }
+    return len(ws), time.Duration((end - start) * float64(time.Second))
+}
+
+// wordSpeaker returns the 1-based diarized speaker of most of an
+// alternative's words, or 0 when the words carry no speaker (diarize off).
+// Ties go to the speaker heard first.
+func wordSpeaker(alt map[string]any) int {
+    ws, _ := alt["words"].([]any)
+    counts := map[int]int{}
+    best, bestN := -1, 0
+    for _, w := range ws {
+        wm, _ := w.(map[string]any)
+        sp, ok := wm["speaker"].(float64)
+        if !ok {
+            continue
+        }
+        i := int(sp)
+        counts[i]++
+        if counts[i] > bestN {
+            best, bestN = i, counts[i]
+        }
+    }
+    return best + 1
+}
+
 func LoadDGConfigFromEnv() DGConfig {
     return DGConfig{
         Model:         os.Getenv("DEEPGRAM_MODEL"),
@@ -407,10 +593,25 @@ func LoadDGConfigFromEnv() DGConfig {
         Interim:       true,
         UtterEndMs:    atoiEnv("DEEPGRAM_UTTERANCE_END_MS", 1500),
         VADEvents:     true,
+        Diarize:       strings.EqualFold(strings.TrimSpace(os.Getenv("DEEPGRAM_DIARIZE")), "true"),
         BaseURL:       os.Getenv("DEEPGRAM_WS_URL"),
     }
 }
 
+// loadDeepgramKey reads DEEPGRAM_API_KEY_FILE when set (a mounted secret
+// that may be rotated under a running sidecar), else DEEPGRAM_API_KEY from
+// the secrets provider.
+func loadDeepgramKey() string {
+    if path := os.Getenv("DEEPGRAM_API_KEY_FILE"); path != "" {
+        b, err := os.ReadFile(path)
+        if err == nil {
+            return strings.TrimSpace(string(b))
+        }
+        log.Printf("[deepgram] DEEPGRAM_API_KEY_FILE: %v; using DEEPGRAM_API_KEY", err)
+    }
+    return secrets.Get("DEEPGRAM_API_KEY")
+}
+
 func atoiEnv(name string, def int) int {
     s := strings.TrimSpace(os.Getenv(name))
     if s == "" { return def }
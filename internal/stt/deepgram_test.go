@@ -0,0 +1,61 @@
+package stt
+
+import (
+    "context"
+    "encoding/json"
+    "net/http"
+    "net/http/httptest"
+    "strings"
+    "testing"
+    "time"
+)
+
+func TestWordSpeaker(t *testing.T) {
+    for _, c := range []struct {
+        alt  string
+        want int
+    }{
+        {`{"words":[{"start":0,"end":0.3}]}`, 0},
+        {`{"words":[{"speaker":1},{"speaker":0},{"speaker":1}]}`, 2},
+        {`{"words":[{"speaker":0},{"speaker":1}]}`, 1}, // tie: first heard
+        {`{}`, 0},
+    } {
+        var alt map[string]any
+        if err := json.Unmarshal([]byte(c.alt), &alt); err != nil {
+            t.Fatal(err)
+        }
+        if got := wordSpeaker(alt); got != c.want {
+            t.Errorf("wordSpeaker(%s) = %d, want %d", c.alt, got, c.want)
+        }
+    }
+}
+
+func TestConnectedDescribesProvider(t *testing.T) {
+    mock := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        w.Header().Set("dg-request-id", "req-123")
+        mockDeepgram(w, r)
+    }))
+    defer mock.Close()
+    ctx, cancel := context.WithCancel(context.Background())
+    defer cancel()
+    d := NewDeepgramConn(ctx, DGConfig{Language: "de", BaseURL: "ws" + strings.TrimPrefix(mock.URL, "http")}, "")
+
+    // Before the socket is up only the configuration is known
+    c := d.connectedMsg("s1").GetConnected()
+    if c.GetProvider() != "deepgram" || c.GetModel() != "nova-2" || c.GetLanguage() != "de" || c.GetRequestId() != "" {
+        t.Fatalf("before connect: %v", c)
+    }
+    d.Start()
+    for e := range d.Events {
+        if e.Type == "reconnected" {
+            break
+        }
+        if e.Type == "error" {
+            t.Fatalf("connect: %s", e.Text)
+        }
+    }
+    c = d.connectedMsg("s1").GetConnected()
+    if c.GetSessionId() != "s1" || c.GetRequestId() != "req-123" || c.GetConnectMs() > uint32(time.Minute.Milliseconds()) {
+        t.Fatalf("after connect: %v", c)
+    }
+}
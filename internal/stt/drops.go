@@ -0,0 +1,167 @@
+package stt
+
+import (
+    "bytes"
+    "context"
+    "encoding/json"
+    "log"
+    "net/http"
+    "os"
+    "strconv"
+    "strings"
+    "time"
+)
+
+// drops.go accounts for audio frames that never reach the provider. Every
+// drop has a reason:
+//
+//   queue_full    the send queue was full while the socket was up
+//   circuit_open  the queue filled while the breaker refused connects
+//   socket_dead   the queue filled while the socket was reconnecting
+//   oversize      the frame exceeded STT_MAX_FRAME_BYTES and was not queued
+//
+// stt_drops_total{reason} counts them; each session's counts travel on its
+// Metrics messages. A per-session alarm fires when more than
+// STT_DROP_ALERT_RATE of the frames in each second were dropped for
+// STT_DROP_ALERT_FOR_S seconds running. It logs, and POSTs to
+// STT_DROP_ALERT_WEBHOOK when set, once when it fires and once when it
+// clears.
+
+const (
+    dropQueueFull   = "queue_full"
+    dropCircuitOpen = "circuit_open"
+    dropSocketDead  = "socket_dead"
+    dropOversize    = "oversize"
+)
+
+var (
+    maxFrameBytes    = atoiEnv("STT_MAX_FRAME_BYTES", 32000) // 1s of 16kHz PCM16; 0 disables
+    dropAlertRate    = readDropAlertRate()
+    dropAlertFor     = time.Duration(atoiEnv("STT_DROP_ALERT_FOR_S", 10)) * time.Second
+    dropAlertWebhook = strings.TrimSpace(os.Getenv("STT_DROP_ALERT_WEBHOOK"))
+)
+
+func readDropAlertRate() float64 {
+    v := strings.TrimSpace(os.Getenv("STT_DROP_ALERT_RATE"))
+    if v == "" { return 0.05 }
+    f, err := strconv.ParseFloat(v, 64)
+    if err != nil || f < 0 || f > 1 {
+        log.Printf("[stt] invalid STT_DROP_ALERT_RATE=%q, using 0.05", v)
+        return 0.05
+    }
+    return f
+}
+
+// dropAlarm watches one session's drop rate in one-second buckets.
+type dropAlarm struct {
+    rate float64       // fraction of frames dropped that counts as a breach
+    hold time.Duration // how long breaches must last before firing
+
+    bucket          time.Time // start of the current bucket
+    frames, dropped int
+    breachSince     time.Time // start of the first breached bucket in a row
+    firing          bool
+}
+
+// newDropAlarm returns an alarm from the STT_DROP_ALERT_* settings, or nil
+// when STT_DROP_ALERT_RATE is 0.
+func newDropAlarm() *dropAlarm {
+    if dropAlertRate <= 0 { return nil }
+    return &dropAlarm{rate: dropAlertRate, hold: dropAlertFor}
+}
+
+// observe counts a frame seen at now. When it closes a bucket that changes
+// the alarm's state it returns "firing" or "resolved" with that bucket's
+// drop rate; otherwise event is "".
+func (a *dropAlarm) observe(now time.Time, dropped bool) (event string, rate float64) {
+    if a == nil { return "", 0 }
+    if a.bucket.IsZero() { a.bucket = now }
+    if now.Sub(a.bucket) >= time.Second {
+        rate = float64(a.dropped) / float64(a.frames)
+        if rate > a.rate {
+            if a.breachSince.IsZero() { a.breachSince = a.bucket }
+            if !a.firing && now.Sub(a.breachSince) >= a.hold {
+                a.firing, event = true, "firing"
+            }
+        } else {
+            a.breachSince = time.Time{}
+            if a.firing {
+                a.firing, event = false, "resolved"
+            }
+        }
+        a.bucket, a.frames, a.dropped = now, 0, 0
+    }
+    a.frames++
+    if dropped { a.dropped++ }
+    return event, rate
+}
+
+// noteFrame records a frame the session handled; reason is "" when it
+// reached the provider queue.
+func (s *Session) noteFrame(reason string) {
+    if reason != "" { metricDrops.WithLabelValues(reason).Inc() }
+    s.mu.Lock()
+    if reason != "" {
+        if s.drops == nil { s.drops = map[string]uint64{} }
+        s.drops[reason]++
+    }
+    event, rate := s.alarm.observe(s.clock.Now(), reason != "")
+    var drops map[string]uint64
+    if event != "" { drops = s.dropsLocked() }
+    s.mu.Unlock()
+    if event != "" { s.alertDrops(event, rate, drops) }
+}
+
+// Drops returns the session's dropped frames by reason.
+func (s *Session) Drops() map[string]uint64 {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+    return s.dropsLocked()
+}
+
+func (s *Session) dropsLocked() map[string]uint64 {
+    out := make(map[string]uint64, len(s.drops))
+    for k, v := range s.drops { out[k] = v }
+    return out
+}
+
+// dropAlert is the webhook payload.
+type dropAlert struct {
+    SessionID string            `json:"session_id"`
+    Event     string            `json:"event"` // firing | resolved
+    DropRate  float64           `json:"drop_rate"`
+    Threshold float64           `json:"threshold"`
+    ForS      int               `json:"for_s"`
+    Drops     map[string]uint64 `json:"drops"`
+    At        time.Time         `json:"at"`
+}
+
+func (s *Session) alertDrops(event string, rate float64, drops map[string]uint64) {
+    metricDropAlerts.WithLabelValues(event).Inc()
+    log.Printf("[stt] ALERT drop rate %s session=%s rate=%.3f threshold=%.3f for=%s drops=%v", event, s.id, rate, s.alarm.rate, s.alarm.hold, drops)
+    if dropAlertWebhook == "" { return }
+    body, _ := json.Marshal(dropAlert{SessionID: s.id, Event: event, DropRate: rate, Threshold: s.alarm.rate,
+        ForS: int(s.alarm.hold / time.Second), Drops: drops, At: s.clock.Now().UTC()})
+    // Off the audio path; a slow hook must not stall frames
+    go postDropAlert(dropAlertWebhook, body)
+}
+
+func postDropAlert(url string, body []byte) {
+    ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+    defer cancel()
+    req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+    if err != nil {
+        log.Printf("[stt] drop alert webhook: %v", err)
+        return
+    }
+    req.Header.Set("Content-Type", "application/json")
+    resp, err := http.DefaultClient.Do(req)
+    if err != nil {
+        log.Printf("[stt] drop alert webhook: %v", err)
+        return
+    }
+    resp.Body.Close()
+    if resp.StatusCode >= 300 {
+        log.Printf("[stt] drop alert webhook: HTTP %d", resp.StatusCode)
+    }
+}
@@ -0,0 +1,81 @@
+package stt
+
+import (
+    "encoding/json"
+    "net/http"
+    "net/http/httptest"
+    "testing"
+    "time"
+
+    "yuzu/agent/internal/clock"
+)
+
+func TestSendAttributesDrops(t *testing.T) {
+    d := &DeepgramConn{sendQ: make(chan *[]byte, 1)}
+    if r := d.Send(&[]byte{1}); r != "" {
+        t.Fatalf("queued frame reported %q", r)
+    }
+    if r := d.Send(&[]byte{2}); r != dropSocketDead {
+        t.Errorf("full queue while disconnected = %q, want socket_dead", r)
+    }
+    d.connected.Store(true)
+    if r := d.Send(&[]byte{3}); r != dropQueueFull {
+        t.Errorf("full queue while connected = %q, want queue_full", r)
+    }
+    d.circuit.Store(time.Now().Add(time.Minute).UnixNano())
+    if r := d.Send(&[]byte{4}); r != dropCircuitOpen {
+        t.Errorf("full queue with the breaker open = %q, want circuit_open", r)
+    }
+}
+
+func TestDropAlarmFiresAndResolves(t *testing.T) {
+    alerts := make(chan dropAlert, 4)
+    hook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        var a dropAlert
+        _ = json.NewDecoder(r.Body).Decode(&a)
+        alerts <- a
+    }))
+    defer hook.Close()
+    prev := dropAlertWebhook
+    dropAlertWebhook = hook.URL
+    defer func() { dropAlertWebhook = prev }()
+
+    clk := clock.NewFake(time.Unix(1700000000, 0))
+    s := idleSession(clk, "s1")
+    s.alarm = &dropAlarm{rate: 0.5, hold: 3 * time.Second}
+    // 10 frames a second, the first dropped of them dropped for reason
+    second := func(dropped int, reason string) {
+        for i := 0; i < 10; i++ {
+            r := ""
+            if i < dropped { r = reason }
+            s.noteFrame(r)
+            clk.Advance(100 * time.Millisecond)
+        }
+    }
+
+    second(3, dropQueueFull) // 30%: below the threshold
+    for i := 0; i < 3; i++ {
+        second(8, dropSocketDead)
+    }
+    select {
+    case a := <-alerts:
+        t.Fatalf("alert before the hold elapsed: %+v", a)
+    case <-time.After(50 * time.Millisecond):
+    }
+    // The next frame closes the third breached second
+    s.noteFrame(dropSocketDead)
+    a := <-alerts
+    if a.Event != "firing" || a.SessionID != "s1" || a.DropRate != 0.8 || a.Drops[dropSocketDead] != 25 || a.Drops[dropQueueFull] != 3 {
+        t.Fatalf("firing alert = %+v", a)
+    }
+
+    clk.Advance(100 * time.Millisecond)
+    second(0, "")
+    second(0, "")
+    if a := <-alerts; a.Event != "resolved" || a.DropRate >= 0.5 {
+        t.Fatalf("resolved alert = %+v", a)
+    }
+    if got := s.Drops(); got[dropSocketDead] != 25 || len(got) != 2 {
+        t.Errorf("Drops() = %v", got)
+    }
+}
@@ -0,0 +1,154 @@
+package stt
+
+import (
+    "math"
+    "os"
+    "slices"
+    "strconv"
+    "strings"
+)
+
+// dsp.go is an optional cleanup stage applied to each session's PCM16
+// (16 kHz mono) before it is queued for the provider. Laptop mics tend to
+// deliver a DC offset, fan and handling rumble, and speech far below the
+// level Deepgram is tuned for; the stages below address those in order:
+// DC removal, a second-order high-pass, and a slow AGC that only adapts on
+// frames loud enough to be speech so room noise is never pumped up. Every
+// stage is off by default and toggled independently.
+
+const dspSampleRate = 16000
+
+// DSPConfig selects the preprocessing stages.
+type DSPConfig struct {
+    DCRemove     bool
+    HighPassHz   float64 // 0 disables the high-pass
+    AGC          bool
+    AGCTargetRMS float64
+    AGCMaxGain   float64
+    AGCGateRMS   float64 // frames quieter than this keep the current gain
+}
+
+// Enabled reports whether any stage is on.
+func (c DSPConfig) Enabled() bool { return c.DCRemove || c.HighPassHz > 0 || c.AGC }
+
+// LoadDSPConfigFromEnv reads STT_DSP_DC_REMOVE, STT_DSP_HIGHPASS_HZ,
+// STT_DSP_AGC, STT_DSP_AGC_TARGET_RMS, STT_DSP_AGC_MAX_GAIN and
+// STT_DSP_AGC_GATE_RMS.
+func LoadDSPConfigFromEnv() DSPConfig {
+    return DSPConfig{
+        DCRemove:     strings.EqualFold(strings.TrimSpace(os.Getenv("STT_DSP_DC_REMOVE")), "true"),
+        HighPassHz:   floatEnv("STT_DSP_HIGHPASS_HZ", 0),
+        AGC:          strings.EqualFold(strings.TrimSpace(os.Getenv("STT_DSP_AGC")), "true"),
+        AGCTargetRMS: floatEnv("STT_DSP_AGC_TARGET_RMS", 3000),
+        AGCMaxGain:   floatEnv("STT_DSP_AGC_MAX_GAIN", 8),
+        AGCGateRMS:   floatEnv("STT_DSP_AGC_GATE_RMS", 150),
+    }
+}
+
+func floatEnv(name string, def float64) float64 {
+    f, err := strconv.ParseFloat(strings.TrimSpace(os.Getenv(name)), 64)
+    if err != nil || f < 0 { return def }
+    return f
+}
+
+// biquad is a direct form I second-order filter.
+type biquad struct {
+    b0, b1, b2, a1, a2 float64
+    x1, x2, y1, y2     float64
+}
+
+// newHighPass returns a Butterworth high-pass at hz (RBJ cookbook).
+func newHighPass(hz float64) *biquad {
+    w0 := 2 * math.Pi * hz / dspSampleRate
+    alpha := math.Sin(w0) / math.Sqrt2 // sin(w0)/(2Q) with Q = 1/√2
+    cos := math.Cos(w0)
+    a0 := 1 + alpha
+    return &biquad{
+        b0: (1 + cos) / 2 / a0,
+        b1: -(1 + cos) / a0,
+        b2: (1 + cos) / 2 / a0,
+        a1: -2 * cos / a0,
+        a2: (1 - alpha) / a0,
+    }
+}
+
+func (f *biquad) process(x float64) float64 {
+    y := f.b0*x + f.b1*f.x1 + f.b2*f.x2 - f.a1*f.y1 - f.a2*f.y2
+    f.x2, f.x1 = f.x1, x
+    f.y2, f.y1 = f.y1, y
+    return y
+}
+
+// Preprocessor carries filter state across one session's frames. It is
+// used from the session's audio path only and is not safe for concurrent
+// use.
+type Preprocessor struct {
+    cfg DSPConfig
+
+    dcX, dcY float64 // DC blocker state
+    hp       *biquad
+    gain     float64
+    buf      []float64
+}
+
+// NewPreprocessor returns nil when cfg enables nothing.
+func NewPreprocessor(cfg DSPConfig) *Preprocessor {
+    if !cfg.Enabled() { return nil }
+    p := &Preprocessor{cfg: cfg, gain: 1}
+    if cfg.HighPassHz > 0 { p.hp = newHighPass(cfg.HighPassHz) }
+    if p.cfg.AGCMaxGain < 1 { p.cfg.AGCMaxGain = 1 }
+    return p
+}
+
+// Process returns a cleaned copy of the PCM16 frame b.
+func (p *Preprocessor) Process(b []byte) []byte {
+    if p == nil || len(b) < 2 { return b }
+    return p.ProcessInto(nil, b)
+}
+
+// ProcessInto is Process writing into dst's storage, which it grows if
+// needed; with no stages on it copies b.
+func (p *Preprocessor) ProcessInto(dst, b []byte) []byte {
+    if p == nil || len(b) < 2 { return append(dst[:0], b...) }
+    n := len(b) / 2
+    if cap(p.buf) < n { p.buf = make([]float64, n) }
+    x := p.buf[:n]
+    var sum float64
+    for i := range x {
+        v := float64(int16(uint16(b[i*2]) | uint16(b[i*2+1])<<8))
+        if p.cfg.DCRemove {
+            // y[n] = x[n] - x[n-1] + R*y[n-1]; R=0.995 puts the corner near 13 Hz
+            y := v - p.dcX + 0.995*p.dcY
+            p.dcX, p.dcY = v, y
+            v = y
+        }
+        if p.hp != nil { v = p.hp.process(v) }
+        x[i] = v
+        sum += v * v
+    }
+
+    from, to := p.gain, p.gain
+    if p.cfg.AGC {
+        if rms := math.Sqrt(sum / float64(n)); rms >= p.cfg.AGCGateRMS && rms > 0 {
+            want := math.Max(0.25, math.Min(p.cfg.AGCMaxGain, p.cfg.AGCTargetRMS/rms))
+            // Cut quickly so loud onsets don't clip; raise slowly
+            rate := 0.05
+            if want < p.gain { rate = 0.5 }
+            to = p.gain + (want-p.gain)*rate
+        }
+        p.gain = to
+        metricDSPGain.Observe(to)
+    }
+
+    out := slices.Grow(dst[:0], n*2)[:n*2]
+    for i, v := range x {
+        if p.cfg.AGC {
+            // Ramp across the frame to avoid zipper noise
+            v *= from + (to-from)*float64(i+1)/float64(n)
+        }
+        s := int16(math.Max(math.MinInt16, math.Min(math.MaxInt16, math.Round(v))))
+        out[i*2] = byte(uint16(s))
+        out[i*2+1] = byte(uint16(s) >> 8)
+    }
+    return out
+}
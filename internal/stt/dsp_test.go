@@ -0,0 +1,78 @@
+package stt
+
+import (
+    "math"
+    "testing"
+)
+
+// tone renders n samples of amp*sin(hz) + offset as PCM16, continuing phase
+// from sample start.
+func tone(start, n int, hz, amp, offset float64) []byte {
+    b := make([]byte, n*2)
+    for i := 0; i < n; i++ {
+        v := int16(offset + amp*math.Sin(2*math.Pi*hz*float64(start+i)/dspSampleRate))
+        b[i*2], b[i*2+1] = byte(uint16(v)), byte(uint16(v)>>8)
+    }
+    return b
+}
+
+func pcmMean(b []byte) float64 {
+    var sum float64
+    for i := 0; i+1 < len(b); i += 2 {
+        sum += float64(int16(uint16(b[i]) | uint16(b[i+1])<<8))
+    }
+    return sum / float64(len(b)/2)
+}
+
+// settle runs 50 frames of 20ms through p and returns the last output.
+func settle(p *Preprocessor, hz, amp, offset float64) []byte {
+    var out []byte
+    for f := 0; f < 50; f++ {
+        out = p.Process(tone(f*320, 320, hz, amp, offset))
+    }
+    return out
+}
+
+func TestPreprocessorDisabledIsNil(t *testing.T) {
+    p := NewPreprocessor(DSPConfig{AGCTargetRMS: 3000})
+    if p != nil {
+        t.Fatal("no stage enabled should yield a nil preprocessor")
+    }
+    in := tone(0, 320, 440, 1000, 0)
+    if out := p.Process(in); &out[0] != &in[0] {
+        t.Error("nil preprocessor should pass frames through")
+    }
+}
+
+func TestPreprocessorRemovesDCAndRumble(t *testing.T) {
+    out := settle(NewPreprocessor(DSPConfig{DCRemove: true}), 440, 2000, 4000)
+    if m := pcmMean(out); math.Abs(m) > 100 {
+        t.Errorf("mean after DC removal = %.0f, want ~0", m)
+    }
+
+    hp := NewPreprocessor(DSPConfig{HighPassHz: 120})
+    if rms := calcRMS(settle(hp, 30, 3000, 0)); rms > 400 {
+        t.Errorf("30 Hz rumble RMS after high-pass = %.0f, want attenuated", rms)
+    }
+    hp = NewPreprocessor(DSPConfig{HighPassHz: 120})
+    if rms := calcRMS(settle(hp, 1000, 3000, 0)); rms < 1900 {
+        t.Errorf("1 kHz speech RMS after high-pass = %.0f, want ~2121", rms)
+    }
+}
+
+func TestPreprocessorAGC(t *testing.T) {
+    cfg := DSPConfig{AGC: true, AGCTargetRMS: 3000, AGCMaxGain: 8, AGCGateRMS: 150}
+    quiet := NewPreprocessor(cfg)
+    if rms := calcRMS(settle(quiet, 300, 800, 0)); rms < 1500 {
+        t.Errorf("quiet speech RMS after AGC = %.0f, want raised towards 3000", rms)
+    }
+    // Room noise under the gate is left alone
+    noise := NewPreprocessor(cfg)
+    if rms := calcRMS(settle(noise, 300, 100, 0)); rms > 80 {
+        t.Errorf("noise RMS after AGC = %.0f, want unchanged (~71)", rms)
+    }
+    loud := NewPreprocessor(cfg)
+    if rms := calcRMS(settle(loud, 300, 20000, 0)); rms > 4000 {
+        t.Errorf("loud speech RMS after AGC = %.0f, want cut towards 3000", rms)
+    }
+}
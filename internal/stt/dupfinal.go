@@ -0,0 +1,116 @@
+package stt
+
+import (
+    "os"
+    "strconv"
+    "strings"
+    "time"
+    "unicode"
+)
+
+// dupfinal.go drops finals that repeat the previous one with small
+// differences. Deepgram's UtteranceEnd fallback (deepgram.go) re-emits the
+// last cached final or interim, which can be a prefix or a superset of the
+// provider final already forwarded, and a late provider final can land after
+// UtteranceEnd has reset the utterance. Either way the orchestrator would
+// answer the same speech twice. A final arriving within
+// STT_DUP_FINAL_WINDOW_MS (default 2000, 0 disables) of the previous one is
+// dropped when, ignoring case and punctuation, the shorter text is at least
+// two words and starts the longer, or when their edit distance still leaves
+// them STT_DUP_FINAL_SIMILARITY (default 0.85) alike. Counted in
+// stt_utterance_events_total{type="fuzzy_duplicate_final"}.
+
+const (
+    // maxDupCompareLen bounds the edit-distance table; longer finals are
+    // compared by prefix only.
+    maxDupCompareLen = 400
+    // minDupPrefixWords keeps "No." followed by "No, I'd use Postgres"
+    // from counting as a repeat.
+    minDupPrefixWords = 2
+)
+
+// dupFinalState is embedded in Session and belongs to the run goroutine.
+// Unlike lastFinalText it survives utterance_end.
+type dupFinalState struct {
+    window     time.Duration
+    similarity float64
+
+    prevText string // normalized
+    prevAt   time.Time
+}
+
+func (d *dupFinalState) load() {
+    d.window = 2 * time.Second
+    if v, err := strconv.Atoi(os.Getenv("STT_DUP_FINAL_WINDOW_MS")); err == nil && v >= 0 {
+        d.window = time.Duration(v) * time.Millisecond
+    }
+    d.similarity = 0.85
+    if v, err := strconv.ParseFloat(os.Getenv("STT_DUP_FINAL_SIMILARITY"), 64); err == nil && v > 0 && v <= 1 {
+        d.similarity = v
+    }
+}
+
+// duplicate reports whether text, arriving at now, repeats the previous
+// final.
+func (d *dupFinalState) duplicate(text string, now time.Time) bool {
+    if d.window <= 0 || d.prevText == "" || now.Sub(d.prevAt) > d.window {
+        return false
+    }
+    return nearDuplicate(d.prevText, normalizeFinal(text), d.similarity)
+}
+
+// note records a forwarded final.
+func (d *dupFinalState) note(text string, now time.Time) {
+    d.prevText, d.prevAt = normalizeFinal(text), now
+}
+
+// normalizeFinal lowercases text and reduces it to words separated by single
+// spaces.
+func normalizeFinal(text string) string {
+    return strings.Join(strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+        return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '\''
+    }), " ")
+}
+
+// nearDuplicate compares two normalized finals.
+func nearDuplicate(a, b string, similarity float64) bool {
+    if a == "" || b == "" {
+        return false
+    }
+    if a == b || wordPrefix(a, b) || wordPrefix(b, a) {
+        return true
+    }
+    ra, rb := []rune(a), []rune(b)
+    if len(ra) > maxDupCompareLen || len(rb) > maxDupCompareLen {
+        return false
+    }
+    longest := max(len(ra), len(rb))
+    return 1-float64(levenshtein(ra, rb))/float64(longest) >= similarity
+}
+
+// wordPrefix reports whether p is a run of at least minDupPrefixWords
+// whole words starting s.
+func wordPrefix(p, s string) bool {
+    return strings.HasPrefix(s, p) && (len(s) == len(p) || s[len(p)] == ' ') && strings.Count(p, " ")+1 >= minDupPrefixWords
+}
+
+// levenshtein is the edit distance between a and b.
+func levenshtein(a, b []rune) int {
+    prev := make([]int, len(b)+1)
+    cur := make([]int, len(b)+1)
+    for j := range prev {
+        prev[j] = j
+    }
+    for i := 1; i <= len(a); i++ {
+        cur[0] = i
+        for j := 1; j <= len(b); j++ {
+            cost := 1
+            if a[i-1] == b[j-1] {
+                cost = 0
+            }
+            cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+        }
+        prev, cur = cur, prev
+    }
+    return prev[len(b)]
+}
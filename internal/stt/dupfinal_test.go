@@ -0,0 +1,63 @@
+package stt
+
+import (
+    "testing"
+    "time"
+
+    "yuzu/agent/internal/clock"
+    pb "yuzu/agent/internal/stt/pb"
+)
+
+func TestNearDuplicate(t *testing.T) {
+    for _, tc := range []struct {
+        a, b string
+        want bool
+    }{
+        {"I worked at Acme.", "i worked at acme", true},
+        {"I worked at Acme", "I worked at Acme for five years.", true}, // superset
+        {"I worked at Acme for five years", "I worked at Acme", true},  // fallback prefix
+        {"I worked at Acme for five years", "I worked at Acme for fife years", true},
+        {"No.", "No, I'd use Postgres.", false}, // one word is not a repeat
+        {"I worked at Acme", "I worked at Globex", false},
+        {"Tell me about caching", "I led the payments team", false},
+        {"yes", "yep", false},
+    } {
+        if got := nearDuplicate(normalizeFinal(tc.a), normalizeFinal(tc.b), 0.85); got != tc.want {
+            t.Errorf("nearDuplicate(%q, %q) = %v, want %v", tc.a, tc.b, got, tc.want)
+        }
+    }
+}
+
+func TestFallbackFinalSuppressed(t *testing.T) {
+    t.Setenv("STT_DUP_FINAL_WINDOW_MS", "")
+    clk := clock.NewFake(time.Unix(1700000000, 0))
+    s := idleSession(clk, "s1")
+    s.events = make(chan *pb.ServerMessage, 8)
+    s.dup.load()
+    s.utterID, s.inUtterance = "t1-u", true
+    finals := func() (out []string) {
+        for len(s.events) > 0 {
+            if f := (<-s.events).GetFinal(); f != nil {
+                out = append(out, f.GetText())
+            }
+        }
+        return out
+    }
+
+    s.handleEvent(DGEvent{Type: "final", Text: "I led the billing migration."})
+    // UtteranceEnd resets the utterance, then a late superset arrives
+    s.handleEvent(DGEvent{Type: "utterance_end"})
+    clk.Advance(500 * time.Millisecond)
+    s.handleEvent(DGEvent{Type: "final", Text: "I led the billing migration to Postgres"})
+    if got := finals(); len(got) != 1 || got[0] != "I led the billing migration." {
+        t.Fatalf("finals = %q, want only the first", got)
+    }
+
+    // Outside the window the same words are a new answer
+    s.handleEvent(DGEvent{Type: "utterance_end"})
+    clk.Advance(3 * time.Second)
+    s.handleEvent(DGEvent{Type: "final", Text: "I led the billing migration."})
+    if got := finals(); len(got) != 1 {
+        t.Fatalf("finals = %q, want the repeat after the window", got)
+    }
+}
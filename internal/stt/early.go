@@ -0,0 +1,80 @@
+package stt
+
+import (
+    "log"
+    "os"
+    "strconv"
+    "strings"
+    "sync/atomic"
+    "time"
+
+    pb "yuzu/agent/internal/stt/pb"
+)
+
+// early.go lets the "earliest" endpointing policy end an utterance without
+// waiting for the gateway's Drain. When the latest interim has not changed
+// for STT_EARLY_FINAL_MS (default 600, 0 disables) and the last audio frame
+// was quieter than STT_EARLY_MAX_RMS (default 300), the interim is emitted as
+// a final tagged source=early. The provider's own final for that utterance
+// is then dropped so the turn is not answered twice.
+
+const (
+    finalSourceDrain = "drain"
+    finalSourceEarly = "early"
+
+    // How often the run loop checks for a stable interim
+    earlyTick = 50 * time.Millisecond
+)
+
+// earlyState is embedded in Session. Fields other than quiet belong to the
+// run goroutine.
+type earlyState struct {
+    after  time.Duration // stable interim needed; 0 disables
+    maxRMS float64
+
+    quiet       atomic.Bool // last audio frame was under maxRMS; set by SendAudio
+    stableSince time.Time   // when lastInterim last changed
+    promoted    bool        // an early final was emitted for this utterance
+}
+
+// load reads the promotion settings for policy.
+func (e *earlyState) load(policy string) {
+    if !strings.EqualFold(policy, "earliest") {
+        return
+    }
+    e.after = 600 * time.Millisecond
+    if v, err := strconv.Atoi(os.Getenv("STT_EARLY_FINAL_MS")); err == nil && v >= 0 {
+        e.after = time.Duration(v) * time.Millisecond
+    }
+    e.maxRMS = 300
+    if v, err := strconv.ParseFloat(os.Getenv("STT_EARLY_MAX_RMS"), 64); err == nil && v > 0 {
+        e.maxRMS = v
+    }
+}
+
+// noteRMS records whether the latest frame was quiet.
+func (e *earlyState) noteRMS(rms float64) {
+    if e.after > 0 {
+        e.quiet.Store(rms < e.maxRMS)
+    }
+}
+
+// promoteStable emits the latest interim as a final once it has been stable
+// long enough while the mic is quiet.
+func (s *Session) promoteStable() {
+    if s.early.after <= 0 || s.finalEmitted || strings.TrimSpace(s.lastInterim) == "" || s.early.stableSince.IsZero() {
+        return
+    }
+    stable := s.clock.Since(s.early.stableSince)
+    if stable < s.early.after || !s.early.quiet.Load() {
+        return
+    }
+    utterID := s.utterance()
+    log.Printf("[stt] promoting interim stable for %dms to early final session=%s utterance=%s text=%q", stable.Milliseconds(), s.id, utterID, s.lastInterim)
+    s.events <- &pb.ServerMessage{Msg: &pb.ServerMessage_Final{Final: &pb.TranscriptFinal{SessionId: s.id, UtteranceId: utterID, Text: s.lastInterim, Source: finalSourceEarly, AudioFingerprint: s.print.take()}}}
+    s.finalEmitted = true
+    s.lastFinalText = s.lastInterim
+    s.dup.note(s.lastInterim, s.clock.Now())
+    s.early.promoted = true
+    metricUtteranceEvents.WithLabelValues("early_final").Inc()
+}
@@ -0,0 +1,68 @@
+package stt
+
+import (
+    "testing"
+    "time"
+
+    "yuzu/agent/internal/clock"
+    pb "yuzu/agent/internal/stt/pb"
+)
+
+func TestPromoteStableInterim(t *testing.T) {
+    t.Setenv("STT_EARLY_FINAL_MS", "500")
+    t.Setenv("STT_EARLY_MAX_RMS", "")
+    clk := clock.NewFake(time.Unix(1700000000, 0))
+    s := idleSession(clk, "s1")
+    s.events = make(chan *pb.ServerMessage, 8)
+    s.early.load("earliest")
+    s.utterID, s.inUtterance = "t1-u", true
+    drain := func() (out []*pb.ServerMessage) {
+        for len(s.events) > 0 {
+            out = append(out, <-s.events)
+        }
+        return out
+    }
+
+    s.handleEvent(DGEvent{Type: "interim", Text: "I worked on"})
+    s.early.noteRMS(50)
+    clk.Advance(400 * time.Millisecond)
+    s.handleEvent(DGEvent{Type: "interim", Text: "I worked on payments"})
+    clk.Advance(400 * time.Millisecond)
+    s.promoteStable()
+    if got := drain(); len(got) != 2 {
+        t.Fatalf("promoted an interim that changed 400ms ago: %v", got)
+    }
+
+    // Still loud: the candidate is mid-word
+    s.early.noteRMS(900)
+    clk.Advance(200 * time.Millisecond)
+    s.promoteStable()
+    if got := drain(); len(got) != 0 {
+        t.Fatalf("promoted while the mic was loud: %v", got)
+    }
+
+    s.early.noteRMS(50)
+    s.promoteStable()
+    got := drain()
+    if len(got) != 1 || got[0].GetFinal().GetSource() != finalSourceEarly || got[0].GetFinal().GetText() != "I worked on payments" || got[0].GetFinal().GetUtteranceId() != "t1-u" {
+        t.Fatalf("got %v, want one early final", got)
+    }
+    s.promoteStable()
+    if got := drain(); len(got) != 0 {
+        t.Fatalf("promoted twice: %v", got)
+    }
+
+    // The provider's final for the same speech is dropped
+    s.handleEvent(DGEvent{Type: "final", Text: "I worked on payments."})
+    if got := drain(); len(got) != 0 {
+        t.Fatalf("provider final after promotion forwarded: %v", got)
+    }
+}
+
+func TestEarlyOffUnderProviderPolicy(t *testing.T) {
+    var e earlyState
+    e.load("provider")
+    if e.after != 0 {
+        t.Fatalf("after = %s under the provider policy, want disabled", e.after)
+    }
+}
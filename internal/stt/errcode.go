@@ -0,0 +1,115 @@
+package stt
+
+import (
+    "context"
+    "errors"
+    "io"
+    "net"
+    "strings"
+
+    "nhooyr.io/websocket"
+
+    "yuzu/agent/internal/errdefs"
+    pb "yuzu/agent/internal/stt/pb"
+)
+
+// errcode.go maps provider failures onto the STT error taxonomy
+// (pb.ErrorCode), so the gateway and orchestrator can react to what went
+// wrong without reading Deepgram's messages:
+//
+//	auth_failed    401/403 on connect (fatal while the key is unchanged;
+//	               see authfail.go), or an auth error frame
+//	rate_limited   429 or a concurrency/rate error frame
+//	bad_audio      400 on connect, close 1008, or a DATA-* error frame
+//	timeout        dial/read timeouts and Deepgram's NET-0001 no-audio close
+//	socket_closed  the stream closed mid-session (EOF, other close codes)
+//	circuit_open   no attempt was made; too many recent failures
+//
+// Everything else is provider_error.
+
+var errRotate = errors.New("rotate")
+
+var errorCodeNames = map[pb.ErrorCode]string{
+    pb.ErrorCode_ERROR_CODE_UNSPECIFIED: "unspecified",
+    pb.ErrorCode_CONNECTION_FAILED:      "connection_failed",
+    pb.ErrorCode_PROVIDER_ERROR:         "provider_error",
+    pb.ErrorCode_TIMEOUT:                "timeout",
+    pb.ErrorCode_CIRCUIT_OPEN:           "circuit_open",
+    pb.ErrorCode_INVALID_AUDIO:          "bad_audio",
+    pb.ErrorCode_SHUTDOWN:               "shutdown",
+    pb.ErrorCode_AUTH_FAILED:            "auth_failed",
+    pb.ErrorCode_RATE_LIMITED:           "rate_limited",
+    pb.ErrorCode_SOCKET_CLOSED:          "socket_closed",
+}
+
+// errorCodeName is the taxonomy name of c, used in metrics, logs and the
+// deprecated pb.Error.Code.
+func errorCodeName(c pb.ErrorCode) string {
+    if n, ok := errorCodeNames[c]; ok {
+        return n
+    }
+    return "provider_error"
+}
+
+// classifyErr maps a connect or stream error from DeepgramConn.
+func classifyErr(err error) pb.ErrorCode {
+    var pe *errdefs.ProviderError
+    if errors.As(err, &pe) && pe.Status != 0 {
+        switch {
+        case pe.Status == 401 || pe.Status == 403:
+            return pb.ErrorCode_AUTH_FAILED
+        case pe.Status == 429:
+            return pb.ErrorCode_RATE_LIMITED
+        case pe.Status == 400 || pe.Status == 415:
+            return pb.ErrorCode_INVALID_AUDIO
+        case pe.Status == 408 || pe.Status == 504:
+            return pb.ErrorCode_TIMEOUT
+        }
+        return pb.ErrorCode_PROVIDER_ERROR
+    }
+    if errors.Is(err, errCircuitOpen) {
+        return pb.ErrorCode_CIRCUIT_OPEN
+    }
+    if errors.Is(err, errRotate) {
+        return pb.ErrorCode_SOCKET_CLOSED
+    }
+    var ce websocket.CloseError
+    if errors.As(err, &ce) {
+        switch {
+        case ce.Code == websocket.StatusPolicyViolation || ce.Code == websocket.StatusUnsupportedData:
+            return pb.ErrorCode_INVALID_AUDIO
+        case strings.Contains(ce.Reason, "NET-0001") || strings.Contains(strings.ToLower(ce.Reason), "timeout"):
+            return pb.ErrorCode_TIMEOUT
+        }
+        return pb.ErrorCode_SOCKET_CLOSED
+    }
+    var ne net.Error
+    if errors.Is(err, context.DeadlineExceeded) || (errors.As(err, &ne) && ne.Timeout()) {
+        return pb.ErrorCode_TIMEOUT
+    }
+    if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, net.ErrClosed) {
+        return pb.ErrorCode_SOCKET_CLOSED
+    }
+    if pe != nil {
+        // Dial failed before any HTTP response
+        return pb.ErrorCode_CONNECTION_FAILED
+    }
+    return pb.ErrorCode_PROVIDER_ERROR
+}
+
+// classifyFrame maps an in-band error frame ({"type":"Error", "err_code",
+// "err_msg"/"description"} or {"error": ...}).
+func classifyFrame(m map[string]any) pb.ErrorCode {
+    s := strings.ToLower(strings.Join([]string{toString(m["err_code"]), toString(m["err_msg"]), toString(m["description"]), toString(m["error"]), toString(m["message"])}, " "))
+    switch {
+    case strings.Contains(s, "auth") || strings.Contains(s, "401") || strings.Contains(s, "403") || strings.Contains(s, "credential"):
+        return pb.ErrorCode_AUTH_FAILED
+    case strings.Contains(s, "rate limit") || strings.Contains(s, "rate_limit") || strings.Contains(s, "429") || strings.Contains(s, "too many") || strings.Contains(s, "concurrency"):
+        return pb.ErrorCode_RATE_LIMITED
+    case strings.Contains(s, "net-0001") || strings.Contains(s, "timeout") || strings.Contains(s, "timed out"):
+        return pb.ErrorCode_TIMEOUT
+    case strings.Contains(s, "data-") || strings.Contains(s, "audio") || strings.Contains(s, "decode") || strings.Contains(s, "encoding"):
+        return pb.ErrorCode_INVALID_AUDIO
+    }
+    return pb.ErrorCode_PROVIDER_ERROR
+}
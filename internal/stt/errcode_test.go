@@ -0,0 +1,60 @@
+package stt
+
+import (
+    "context"
+    "errors"
+    "fmt"
+    "io"
+    "testing"
+
+    "nhooyr.io/websocket"
+
+    "yuzu/agent/internal/errdefs"
+    pb "yuzu/agent/internal/stt/pb"
+)
+
+func TestClassifyErr(t *testing.T) {
+    for _, c := range []struct {
+        name string
+        err  error
+        want pb.ErrorCode
+    }{
+        {"401", errdefs.ProviderStatus("deepgram", 401, "unauthorized"), pb.ErrorCode_AUTH_FAILED},
+        {"403", errdefs.ProviderStatus("deepgram", 403, "forbidden"), pb.ErrorCode_AUTH_FAILED},
+        {"429", errdefs.ProviderStatus("deepgram", 429, "slow down"), pb.ErrorCode_RATE_LIMITED},
+        {"400", errdefs.ProviderStatus("deepgram", 400, "bad encoding"), pb.ErrorCode_INVALID_AUDIO},
+        {"503", errdefs.ProviderStatus("deepgram", 503, "unavailable"), pb.ErrorCode_PROVIDER_ERROR},
+        {"circuit", &errdefs.ProviderError{Provider: "deepgram", Retryable: true, Err: errCircuitOpen}, pb.ErrorCode_CIRCUIT_OPEN},
+        {"dial refused", errdefs.ProviderTransport("deepgram", errors.New("connection refused")), pb.ErrorCode_CONNECTION_FAILED},
+        {"dial timeout", errdefs.ProviderTransport("deepgram", context.DeadlineExceeded), pb.ErrorCode_TIMEOUT},
+        {"no audio close", fmt.Errorf("read: %w", websocket.CloseError{Code: websocket.StatusInternalError, Reason: "NET-0001"}), pb.ErrorCode_TIMEOUT},
+        {"bad data close", websocket.CloseError{Code: websocket.StatusPolicyViolation, Reason: "DATA-0000"}, pb.ErrorCode_INVALID_AUDIO},
+        {"going away", websocket.CloseError{Code: websocket.StatusGoingAway}, pb.ErrorCode_SOCKET_CLOSED},
+        {"eof", fmt.Errorf("failed to get reader: %w", io.EOF), pb.ErrorCode_SOCKET_CLOSED},
+        {"other", errors.New("boom"), pb.ErrorCode_PROVIDER_ERROR},
+    } {
+        if got := classifyErr(c.err); got != c.want {
+            t.Errorf("%s: got %s, want %s", c.name, got, c.want)
+        }
+    }
+}
+
+func TestClassifyFrame(t *testing.T) {
+    for _, c := range []struct {
+        frame map[string]any
+        want  pb.ErrorCode
+    }{
+        {map[string]any{"type": "Error", "err_code": "INVALID_AUTH", "err_msg": "Invalid credentials."}, pb.ErrorCode_AUTH_FAILED},
+        {map[string]any{"type": "Error", "err_code": "TOO_MANY_REQUESTS", "err_msg": "Too many requests"}, pb.ErrorCode_RATE_LIMITED},
+        {map[string]any{"type": "Error", "err_code": "DATA-0000", "description": "Failed to decode audio; check sample rate"}, pb.ErrorCode_INVALID_AUDIO},
+        {map[string]any{"type": "Error", "err_code": "NET-0001", "description": "no audio received"}, pb.ErrorCode_TIMEOUT},
+        {map[string]any{"error": "something odd"}, pb.ErrorCode_PROVIDER_ERROR},
+    } {
+        if got := classifyFrame(c.frame); got != c.want {
+            t.Errorf("%v: got %s, want %s", c.frame, got, c.want)
+        }
+    }
+    if n := errorCodeName(pb.ErrorCode_INVALID_AUDIO); n != "bad_audio" {
+        t.Errorf("INVALID_AUDIO name = %q", n)
+    }
+}
@@ -0,0 +1,57 @@
+package stt
+
+import (
+    "math"
+    "sync"
+)
+
+// fingerprint.go fingerprints the audio behind each final, so a final sent
+// again after a reconnect can be recognized downstream as the same speech
+// rather than the candidate repeating themselves (see the orchestrator's
+// dupturn.go). Every voiced frame (RMS at least printMinRMS) adds its level,
+// quantized to half-octave steps, to an FNV-1a hash; silence is skipped so
+// padding around the speech doesn't change it. The hash restarts with every
+// final and travels as TranscriptFinal.audio_fingerprint, 0 when no voiced
+// audio was heard.
+
+const (
+    printMinRMS = 300
+
+    fnvOffset64 = 14695981039346656037
+    fnvPrime64  = 1099511628211
+)
+
+// audioPrint is embedded in Session. SendAudio adds to it on the receive
+// goroutine while finals take it on the run goroutine.
+type audioPrint struct {
+    mu     sync.Mutex
+    hash   uint64
+    voiced int
+}
+
+// add folds in one frame's RMS.
+func (p *audioPrint) add(rms float64) {
+    if rms < printMinRMS {
+        return
+    }
+    level := uint64(2 * math.Log2(rms))
+    p.mu.Lock()
+    if p.voiced == 0 {
+        p.hash = fnvOffset64
+    }
+    p.hash = (p.hash ^ level) * fnvPrime64
+    p.voiced++
+    p.mu.Unlock()
+}
+
+// take returns the fingerprint of the audio since the last take.
+func (p *audioPrint) take() uint64 {
+    p.mu.Lock()
+    defer p.mu.Unlock()
+    h := p.hash
+    if p.voiced == 0 {
+        h = 0
+    }
+    p.hash, p.voiced = 0, 0
+    return h
+}
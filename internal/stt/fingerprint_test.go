@@ -0,0 +1,33 @@
+package stt
+
+import "testing"
+
+func TestAudioPrintIgnoresSilenceAndRestarts(t *testing.T) {
+    speech := []float64{800, 2400, 3100, 2900, 1200, 650}
+    var a, b audioPrint
+    for _, rms := range speech {
+        a.add(rms)
+    }
+    // The same speech padded with silence, and a little louder within a step
+    b.add(20)
+    b.add(150)
+    for _, rms := range speech {
+        b.add(rms * 1.05)
+    }
+    b.add(90)
+    pa, pb := a.take(), b.take()
+    if pa == 0 || pa != pb {
+        t.Fatalf("prints = %x, %x; want equal and non-zero", pa, pb)
+    }
+    if a.take() != 0 {
+        t.Error("take should restart the print")
+    }
+
+    // Different speech, different print
+    for _, rms := range []float64{800, 2400, 700, 2900, 1200, 650} {
+        a.add(rms)
+    }
+    if a.take() == pa {
+        t.Error("different envelopes gave the same print")
+    }
+}
@@ -0,0 +1,36 @@
+package stt
+
+import "sync"
+
+// framepool.go recycles the buffers audio frames travel in. Every session
+// sends 50 frames a second, and each used to be a fresh slice from the DSP
+// stage that lived until the provider write; with many sessions that is
+// most of the sidecar's garbage. SendAudio now copies (or cleans) each
+// frame into a pooled buffer, the DeepgramConn queue carries it, and the
+// send loop returns it once written. Dropped frames go straight back.
+// BenchmarkSendAudio reports allocations per frame.
+
+// frameCap fits a 20 ms frame at 16 kHz mono PCM16; larger frames grow
+// their buffer once and keep it.
+const frameCap = 640
+
+var framePool = sync.Pool{New: func() any {
+    b := make([]byte, 0, frameCap)
+    return &b
+}}
+
+// getFrame returns an empty buffer from the pool. The pool holds pointers
+// so that getting and putting a buffer doesn't allocate its header.
+func getFrame() *[]byte {
+    p := framePool.Get().(*[]byte)
+    *p = (*p)[:0]
+    return p
+}
+
+// putFrame returns p to the pool; nothing may use it afterwards.
+func putFrame(p *[]byte) {
+    if p == nil || cap(*p) == 0 {
+        return
+    }
+    framePool.Put(p)
+}
@@ -0,0 +1,71 @@
+package stt
+
+import (
+    "io"
+    "log"
+    "testing"
+    "time"
+
+    "yuzu/agent/internal/clock"
+)
+
+// pooledSession returns a func that hands a Session with a provider queue
+// and nothing else one frame, and puts the frame back in the pool the way
+// the send loop does.
+func pooledSession(tb testing.TB, dsp DSPConfig) func([]byte) {
+    s := idleSession(clock.NewFake(time.Unix(1700000000, 0)), "pooled-session")
+    s.dg = &DeepgramConn{sendQ: make(chan *[]byte, 1)}
+    s.dsp = NewPreprocessor(dsp)
+    prevSave, prevOut := saveSamples, log.Writer()
+    saveSamples = false
+    log.SetOutput(io.Discard)
+    tb.Cleanup(func() {
+        saveSamples = prevSave
+        log.SetOutput(prevOut)
+    })
+    return func(b []byte) {
+        s.SendAudio(b)
+        putFrame(<-s.dg.sendQ)
+    }
+}
+
+func TestSendAudioDoesNotAllocate(t *testing.T) {
+    in := tone(0, 320, 440, 2000, 300)
+    for _, tc := range []struct {
+        name string
+        dsp  DSPConfig
+    }{
+        {"passthrough", DSPConfig{}},
+        {"dsp", DSPConfig{DCRemove: true, HighPassHz: 80, AGC: true, AGCTargetRMS: 3000, AGCMaxGain: 8, AGCGateRMS: 150}},
+    } {
+        sendFrame := pooledSession(t, tc.dsp)
+        // Fill the pool and the DSP's scratch buffer first
+        for i := 0; i < 20; i++ {
+            sendFrame(in)
+        }
+        if n := testing.AllocsPerRun(200, func() { sendFrame(in) }); n != 0 {
+            t.Errorf("%s: %.0f allocations per frame, want 0", tc.name, n)
+        }
+    }
+}
+
+func TestSendAudioQueuesACopy(t *testing.T) {
+    s := idleSession(clock.NewFake(time.Unix(1700000000, 0)), "s1")
+    s.dg = &DeepgramConn{sendQ: make(chan *[]byte, 1)}
+    in := []byte{1, 2, 3, 4}
+    s.SendAudio(in)
+    in[0] = 9
+    if f := <-s.dg.sendQ; string(*f) != "\x01\x02\x03\x04" {
+        t.Fatalf("queued %v, want the frame as sent", *f)
+    }
+}
+
+func BenchmarkSendAudio(b *testing.B) {
+    sendFrame := pooledSession(b, DSPConfig{DCRemove: true, HighPassHz: 80, AGC: true, AGCTargetRMS: 3000, AGCMaxGain: 8, AGCGateRMS: 150})
+    in := tone(0, 320, 440, 2000, 300)
+    b.ReportAllocs()
+    b.SetBytes(int64(len(in)))
+    for i := 0; i < b.N; i++ {
+        sendFrame(in)
+    }
+}
@@ -0,0 +1,176 @@
+package stt
+
+import (
+    "crypto/subtle"
+    "encoding/json"
+    "net/http"
+    "sort"
+    "strconv"
+    "strings"
+    "sync"
+    "time"
+)
+
+// framering.go keeps the last STT_DEBUG_FRAMES (default 200, 0 disables)
+// provider frames of each live session in memory instead of logging every
+// one, and serves them on the probes port when STT_ADMIN_TOKEN is set:
+//
+//	GET /admin/sessions                   live sessions and frames kept
+//	GET /admin/sessions/{id}/frames?limit=N   newest N frames, oldest first
+//
+// STT_LOG_RAW_FRAMES=true restores the old per-frame log line.
+
+// maxDebugFrameBytes bounds one kept frame; longer ones keep a prefix.
+const maxDebugFrameBytes = 8 << 10
+
+// DebugFrame is one provider message as received.
+type DebugFrame struct {
+    Ts        time.Time       `json:"ts"`
+    Bytes     int             `json:"bytes"`
+    Frame     json.RawMessage `json:"frame,omitempty"`  // the JSON as sent
+    Truncated string          `json:"prefix,omitempty"` // when too large or not JSON
+}
+
+// frameRing is a bounded ring of DebugFrames, safe for concurrent use.
+type frameRing struct {
+    mu   sync.Mutex
+    buf  []DebugFrame
+    next int
+    full bool
+}
+
+// newFrameRing returns a ring of n frames, or nil when n <= 0; a nil ring
+// ignores adds.
+func newFrameRing(n int) *frameRing {
+    if n <= 0 {
+        return nil
+    }
+    return &frameRing{buf: make([]DebugFrame, n)}
+}
+
+func (r *frameRing) add(at time.Time, data []byte) {
+    if r == nil {
+        return
+    }
+    f := DebugFrame{Ts: at, Bytes: len(data)}
+    if len(data) <= maxDebugFrameBytes && json.Valid(data) {
+        f.Frame = append(json.RawMessage(nil), data...)
+    } else {
+        f.Truncated = string(data[:min(len(data), 512)])
+    }
+    r.mu.Lock()
+    r.buf[r.next] = f
+    r.next = (r.next + 1) % len(r.buf)
+    if r.next == 0 {
+        r.full = true
+    }
+    r.mu.Unlock()
+}
+
+// last returns up to n of the newest frames, oldest first; n <= 0 means all.
+func (r *frameRing) last(n int) []DebugFrame {
+    if r == nil {
+        return nil
+    }
+    r.mu.Lock()
+    defer r.mu.Unlock()
+    size := r.next
+    if r.full {
+        size = len(r.buf)
+    }
+    if n <= 0 || n > size {
+        n = size
+    }
+    out := make([]DebugFrame, 0, n)
+    for i := size - n; i < size; i++ {
+        j := i
+        if r.full {
+            j = (r.next + i) % len(r.buf)
+        }
+        out = append(out, r.buf[j])
+    }
+    return out
+}
+
+func (r *frameRing) len() int {
+    if r == nil {
+        return 0
+    }
+    r.mu.Lock()
+    defer r.mu.Unlock()
+    if r.full {
+        return len(r.buf)
+    }
+    return r.next
+}
+
+// frameRing is the session's ring, nil before the provider is connected.
+func (s *Session) frameRing() *frameRing {
+    dg := s.conn()
+    if dg == nil {
+        return nil
+    }
+    return dg.frames
+}
+
+// AdminHandler serves the frame ring; it is a 404 unless STT_ADMIN_TOKEN
+// is set, and requires it as a bearer token.
+func (s *STTServer) AdminHandler() http.Handler {
+    return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        if s.adminToken == "" {
+            http.NotFound(w, r)
+            return
+        }
+        a := r.Header.Get("Authorization")
+        if len(a) < 7 || !strings.EqualFold(a[:7], "bearer ") || subtle.ConstantTimeCompare([]byte(strings.TrimSpace(a[7:])), []byte(s.adminToken)) != 1 {
+            http.Error(w, "admin token required", http.StatusUnauthorized)
+            return
+        }
+        if r.Method != http.MethodGet {
+            http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+            return
+        }
+        parts := strings.Split(strings.Trim(strings.TrimPrefix(r.URL.Path, "/admin/"), "/"), "/")
+        switch {
+        case len(parts) == 1 && parts[0] == "sessions":
+            s.writeAdminSessions(w)
+        case len(parts) == 3 && parts[0] == "sessions" && parts[2] == "frames":
+            s.writeAdminFrames(w, r, parts[1])
+        default:
+            http.NotFound(w, r)
+        }
+    })
+}
+
+func (s *STTServer) writeAdminSessions(w http.ResponseWriter) {
+    type item struct {
+        SessionID string `json:"session_id"`
+        Frames    int    `json:"frames"`
+    }
+    s.mu.Lock()
+    out := make([]item, 0, len(s.sess))
+    for id, sess := range s.sess {
+        out = append(out, item{SessionID: id, Frames: sess.frameRing().len()})
+    }
+    s.mu.Unlock()
+    sort.Slice(out, func(i, j int) bool { return out[i].SessionID < out[j].SessionID })
+    w.Header().Set("Content-Type", "application/json")
+    _ = json.NewEncoder(w).Encode(map[string]any{"sessions": out})
+}
+
+func (s *STTServer) writeAdminFrames(w http.ResponseWriter, r *http.Request, id string) {
+    s.mu.Lock()
+    sess := s.sess[id]
+    s.mu.Unlock()
+    if sess == nil {
+        http.NotFound(w, r)
+        return
+    }
+    limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+    frames := sess.frameRing().last(limit)
+    if frames == nil {
+        frames = []DebugFrame{}
+    }
+    w.Header().Set("Content-Type", "application/json")
+    _ = json.NewEncoder(w).Encode(map[string]any{"session_id": id, "frames": frames})
+}
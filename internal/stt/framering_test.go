@@ -0,0 +1,109 @@
+package stt
+
+import (
+    "encoding/json"
+    "fmt"
+    "net/http"
+    "net/http/httptest"
+    "strings"
+    "testing"
+    "time"
+
+    "yuzu/agent/internal/clock"
+)
+
+func TestFrameRingKeepsNewest(t *testing.T) {
+    r := newFrameRing(3)
+    t0 := time.Unix(1700000000, 0)
+    for i := 0; i < 5; i++ {
+        r.add(t0.Add(time.Duration(i)*time.Second), []byte(fmt.Sprintf(`{"i":%d}`, i)))
+    }
+    if r.len() != 3 {
+        t.Fatalf("len = %d, want 3", r.len())
+    }
+    got := r.last(0)
+    for k, want := range []string{`{"i":2}`, `{"i":3}`, `{"i":4}`} {
+        if string(got[k].Frame) != want {
+            t.Fatalf("frame %d = %s, want %s", k, got[k].Frame, want)
+        }
+    }
+    if two := r.last(2); len(two) != 2 || string(two[0].Frame) != `{"i":3}` {
+        t.Fatalf("last(2) = %+v", two)
+    }
+
+    var off *frameRing
+    off.add(t0, []byte(`{}`))
+    if off.len() != 0 || off.last(0) != nil {
+        t.Fatal("a nil ring should ignore frames")
+    }
+}
+
+func TestFrameRingTruncatesLargeAndInvalid(t *testing.T) {
+    r := newFrameRing(2)
+    r.add(time.Now(), []byte("not json"))
+    r.add(time.Now(), []byte(`{"x":"`+strings.Repeat("a", maxDebugFrameBytes)+`"}`))
+    got := r.last(0)
+    if got[0].Frame != nil || got[0].Truncated != "not json" {
+        t.Fatalf("invalid frame = %+v", got[0])
+    }
+    if got[1].Frame != nil || len(got[1].Truncated) != 512 || got[1].Bytes <= maxDebugFrameBytes {
+        t.Fatalf("large frame kept %d bytes of %d", len(got[1].Truncated), got[1].Bytes)
+    }
+}
+
+func TestAdminHandlerServesFrames(t *testing.T) {
+    clk := clock.NewFake(time.Unix(1700000000, 0))
+    s := &STTServer{sess: make(map[string]*Session), clock: clk}
+    sess := idleSession(clk, "s1")
+    sess.dg = &DeepgramConn{frames: newFrameRing(4)}
+    sess.dg.frames.add(clk.Now(), []byte(`{"type":"Results"}`))
+    sess.dg.frames.add(clk.Now(), []byte(`{"type":"UtteranceEnd"}`))
+    s.sess["s1"] = sess
+    s.sess["s2"] = idleSession(clk, "s2")
+
+    do := func(path, token string) *httptest.ResponseRecorder {
+        req := httptest.NewRequest(http.MethodGet, path, nil)
+        if token != "" {
+            req.Header.Set("Authorization", "Bearer "+token)
+        }
+        rec := httptest.NewRecorder()
+        s.AdminHandler().ServeHTTP(rec, req)
+        return rec
+    }
+
+    if rec := do("/admin/sessions", "x"); rec.Code != http.StatusNotFound {
+        t.Fatalf("without STT_ADMIN_TOKEN: %d, want 404", rec.Code)
+    }
+    s.adminToken = "secret"
+    if rec := do("/admin/sessions", "wrong"); rec.Code != http.StatusUnauthorized {
+        t.Fatalf("bad token: %d, want 401", rec.Code)
+    }
+
+    rec := do("/admin/sessions", "secret")
+    var list struct {
+        Sessions []struct {
+            SessionID string `json:"session_id"`
+            Frames    int    `json:"frames"`
+        } `json:"sessions"`
+    }
+    if err := json.Unmarshal(rec.Body.Bytes(), &list); err != nil || len(list.Sessions) != 2 {
+        t.Fatalf("sessions: %v %s", err, rec.Body)
+    }
+    if list.Sessions[0].SessionID != "s1" || list.Sessions[0].Frames != 2 || list.Sessions[1].Frames != 0 {
+        t.Fatalf("sessions = %+v", list.Sessions)
+    }
+
+    rec = do("/admin/sessions/s1/frames?limit=1", "secret")
+    var frames struct {
+        Frames []DebugFrame `json:"frames"`
+    }
+    if err := json.Unmarshal(rec.Body.Bytes(), &frames); err != nil || len(frames.Frames) != 1 {
+        t.Fatalf("frames: %v %s", err, rec.Body)
+    }
+    if string(frames.Frames[0].Frame) != `{"type":"UtteranceEnd"}` {
+        t.Fatalf("newest frame = %s", frames.Frames[0].Frame)
+    }
+    if rec := do("/admin/sessions/nope/frames", "secret"); rec.Code != http.StatusNotFound {
+        t.Fatalf("unknown session: %d, want 404", rec.Code)
+    }
+}
@@ -16,16 +16,26 @@ var (
         Help: "Total audio frames enqueued to provider",
     })
 
-    metricDrops = promauto.NewCounter(prometheus.CounterOpts{
+    metricDrops = promauto.NewCounterVec(prometheus.CounterOpts{
         Name: "stt_drops_total",
-        Help: "Total audio frames dropped due to backpressure",
-    })
+        Help: "Audio frames dropped before the provider, by reason (queue_full, circuit_open, socket_dead, oversize)",
+    }, []string{"reason"})
+
+    metricDropAlerts = promauto.NewCounterVec(prometheus.CounterOpts{
+        Name: "stt_drop_alerts_total",
+        Help: "Per-session drop-rate alerts (firing, resolved)",
+    }, []string{"event"})
 
     metricReconnects = promauto.NewCounter(prometheus.CounterOpts{
         Name: "stt_reconnects_total",
         Help: "Total reconnects to provider",
     })
 
+    metricErrors = promauto.NewCounterVec(prometheus.CounterOpts{
+        Name: "stt_errors_total",
+        Help: "Provider errors sent to clients, by taxonomy code (auth_failed, rate_limited, bad_audio, timeout, socket_closed, ...)",
+    }, []string{"code"})
+
     metricCircuitOpens = promauto.NewCounter(prometheus.CounterOpts{
         Name: "stt_circuit_open_total",
         Help: "Circuit breaker open events",
@@ -74,11 +84,86 @@ var (
     metricUtteranceEvents = promauto.NewCounterVec(prometheus.CounterOpts{
         Name: "stt_utterance_events_total",
         Help: "Utterance boundary events observed",
-    }, []string{"type"}) // speech_started, utterance_end
+    }, []string{"type"}) // speech_started, utterance_end, early_final, ...
 
     // Event channel drops
     metricEventDrops = promauto.NewCounter(prometheus.CounterOpts{
         Name: "stt_event_drops_total",
         Help: "Events dropped due to slow consumer (channel backpressure)",
     })
+
+    metricDSPGain = promauto.NewHistogram(prometheus.HistogramOpts{
+        Name:    "stt_dsp_agc_gain",
+        Help:    "Gain applied by the AGC preprocessing stage, per frame",
+        Buckets: []float64{0.25, 0.5, 1, 1.5, 2, 3, 4, 6, 8, 12, 16},
+    })
+
+    // Provider usage (see usage.go)
+    metricAudioSeconds = promauto.NewCounter(prometheus.CounterOpts{
+        Name: "stt_audio_seconds_total",
+        Help: "Seconds of audio streamed to the provider",
+    })
+
+    metricSpendUSD = promauto.NewCounter(prometheus.CounterOpts{
+        Name: "stt_estimated_spend_usd_total",
+        Help: "Estimated provider spend in USD at STT_COST_PER_MINUTE_USD",
+    })
+
+    metricSessionAudioSeconds = promauto.NewHistogram(prometheus.HistogramOpts{
+        Name:    "stt_session_audio_seconds",
+        Help:    "Audio seconds streamed per session, observed when it ends",
+        Buckets: prometheus.ExponentialBuckets(30, 2, 9),
+    })
+
+    metricSessionSpendUSD = promauto.NewHistogram(prometheus.HistogramOpts{
+        Name:    "stt_session_estimated_spend_usd",
+        Help:    "Estimated provider spend per session in USD, observed when it ends",
+        Buckets: []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5},
+    })
+
+    // Audio held while no utterance is open (see prespeech.go)
+    metricPreSpeechBytes = promauto.NewCounterVec(prometheus.CounterOpts{
+        Name: "stt_prespeech_bytes_total",
+        Help: "Pre-speech audio bytes leaving the buffer, by outcome (flushed, expired)",
+    }, []string{"outcome"})
+
+    // Interim stabilization (see stabilize.go)
+    metricInterimRevisions = promauto.NewCounter(prometheus.CounterOpts{
+        Name: "stt_interim_revisions_total",
+        Help: "Interims that changed a word already in the committed prefix",
+    })
+
+    // Shadow provider and failover (see shadow.go)
+    metricShadowFinals = promauto.NewCounterVec(prometheus.CounterOpts{
+        Name: "stt_shadow_finals_total",
+        Help: "Primary and shadow finals compared, by result (agree, disagree, primary_only, shadow_only)",
+    }, []string{"result"})
+
+    metricShadowLag = promauto.NewHistogramVec(prometheus.HistogramOpts{
+        Name:    "stt_shadow_final_lag_ms",
+        Help:    "How far the slower of a paired primary and shadow final trailed, by which came first (primary, shadow)",
+        Buckets: []float64{25, 50, 100, 200, 400, 800, 1500, 3000},
+    }, []string{"leader"})
+
+    metricFailovers = promauto.NewCounterVec(prometheus.CounterOpts{
+        Name: "stt_failover_switches_total",
+        Help: "Sessions switched to their shadow provider, by reason (errors, latency)",
+    }, []string{"reason"})
+
+    // Rejected API keys (see authfail.go)
+    metricAuthFailures = promauto.NewCounter(prometheus.CounterOpts{
+        Name: "stt_auth_failures_total",
+        Help: "Provider connects rejected with 401 or 403",
+    })
+
+    gaugeAuthBlocked = promauto.NewGauge(prometheus.GaugeOpts{
+        Name: "stt_auth_blocked",
+        Help: "1 while the provider API key is rejected and connects are stopped",
+    })
+
+    // Consent (ControlStart.no_audio_archive)
+    metricArchiveRefused = promauto.NewCounter(prometheus.CounterOpts{
+        Name: "stt_audio_archive_refused_total",
+        Help: "Sessions whose audio samples were not saved because they opted out of audio archiving",
+    })
 )
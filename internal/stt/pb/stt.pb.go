@@ -21,16 +21,22 @@ const (
 	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
 )
 
+// Provider-agnostic STT failure classes. Provider responses (HTTP status on
+// connect, in-band error frames, websocket close codes) are mapped onto these
+// so clients can react without parsing provider messages.
 type ErrorCode int32
 
 const (
 	ErrorCode_ERROR_CODE_UNSPECIFIED ErrorCode = 0
-	ErrorCode_CONNECTION_FAILED      ErrorCode = 1
-	ErrorCode_PROVIDER_ERROR         ErrorCode = 2
-	ErrorCode_TIMEOUT                ErrorCode = 3
-	ErrorCode_CIRCUIT_OPEN           ErrorCode = 4
-	ErrorCode_INVALID_AUDIO          ErrorCode = 5
+	ErrorCode_CONNECTION_FAILED      ErrorCode = 1 // could not reach the provider
+	ErrorCode_PROVIDER_ERROR         ErrorCode = 2 // anything not classified below
+	ErrorCode_TIMEOUT                ErrorCode = 3 // provider or network timeout, incl. no-audio timeouts
+	ErrorCode_CIRCUIT_OPEN           ErrorCode = 4 // too many recent failures; not attempting
+	ErrorCode_INVALID_AUDIO          ErrorCode = 5 // bad_audio: audio or encoding rejected
 	ErrorCode_SHUTDOWN               ErrorCode = 6
+	ErrorCode_AUTH_FAILED            ErrorCode = 7 // key rejected (401/403); retried after re-reading the key
+	ErrorCode_RATE_LIMITED           ErrorCode = 8 // 429 or concurrency limit; retried with a long backoff
+	ErrorCode_SOCKET_CLOSED          ErrorCode = 9 // stream closed mid-session; reconnected at once
 )
 
 // Enum value maps for ErrorCode.
@@ -43,6 +49,9 @@ var (
 		4: "CIRCUIT_OPEN",
 		5: "INVALID_AUDIO",
 		6: "SHUTDOWN",
+		7: "AUTH_FAILED",
+		8: "RATE_LIMITED",
+		9: "SOCKET_CLOSED",
 	}
 	ErrorCode_value = map[string]int32{
 		"ERROR_CODE_UNSPECIFIED": 0,
@@ -52,6 +61,9 @@ var (
 		"CIRCUIT_OPEN":           4,
 		"INVALID_AUDIO":          5,
 		"SHUTDOWN":               6,
+		"AUTH_FAILED":            7,
+		"RATE_LIMITED":           8,
+		"SOCKET_CLOSED":          9,
 	}
 )
 
@@ -91,6 +103,7 @@ type ControlStart struct {
 	Language        string                 `protobuf:"bytes,4,opt,name=language,proto3" json:"language,omitempty"`                                      // e.g., en-US
 	SampleRate      uint32                 `protobuf:"varint,5,opt,name=sample_rate,json=sampleRate,proto3" json:"sample_rate,omitempty"`               // expected input (16k PCM16)
 	ProtocolVersion string                 `protobuf:"bytes,6,opt,name=protocol_version,json=protocolVersion,proto3" json:"protocol_version,omitempty"` // client protocol version
+	NoAudioArchive  bool                   `protobuf:"varint,7,opt,name=no_audio_archive,json=noAudioArchive,proto3" json:"no_audio_archive,omitempty"` // consent: write none of the session's audio to disk
 	unknownFields   protoimpl.UnknownFields
 	sizeCache       protoimpl.SizeCache
 }
@@ -167,6 +180,13 @@ func (x *ControlStart) GetProtocolVersion() string {
 	return ""
 }
 
+func (x *ControlStart) GetNoAudioArchive() bool {
+	if x != nil {
+		return x.NoAudioArchive
+	}
+	return false
+}
+
 type AudioChunk struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Pcm16K        []byte                 `protobuf:"bytes,1,opt,name=pcm16k,proto3" json:"pcm16k,omitempty"`                            // linear PCM16 mono @16kHz
@@ -510,10 +530,19 @@ func (*ClientMessage_Close) isClientMessage_Msg() {}
 func (*ClientMessage_Ping) isClientMessage_Msg() {}
 
 // Sidecar -> Client messages
+// Connected is sent on ControlStart and again whenever the provider socket
+// is (re)established, describing what serves the session.
 type Connected struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	SessionId     string                 `protobuf:"bytes,1,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
-	Model         string                 `protobuf:"bytes,2,opt,name=model,proto3" json:"model,omitempty"` // e.g., nova-2
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	SessionId string                 `protobuf:"bytes,1,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
+	Model     string                 `protobuf:"bytes,2,opt,name=model,proto3" json:"model,omitempty"`                           // e.g., nova-2
+	Provider  string                 `protobuf:"bytes,3,opt,name=provider,proto3" json:"provider,omitempty"`                     // e.g., deepgram
+	Language  string                 `protobuf:"bytes,4,opt,name=language,proto3" json:"language,omitempty"`                     // e.g., en-US
+	ConnectMs uint32                 `protobuf:"varint,5,opt,name=connect_ms,json=connectMs,proto3" json:"connect_ms,omitempty"` // provider handshake time; 0 while still connecting
+	RequestId string                 `protobuf:"bytes,6,opt,name=request_id,json=requestId,proto3" json:"request_id,omitempty"`  // provider's request ID for the socket, for support tickets
+	// Set when the sidecar failed over to this provider mid-session:
+	// "errors" or "latency" (see internal/stt/shadow.go)
+	SwitchReason  string `protobuf:"bytes,7,opt,name=switch_reason,json=switchReason,proto3" json:"switch_reason,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -562,11 +591,51 @@ func (x *Connected) GetModel() string {
 	return ""
 }
 
+func (x *Connected) GetProvider() string {
+	if x != nil {
+		return x.Provider
+	}
+	return ""
+}
+
+func (x *Connected) GetLanguage() string {
+	if x != nil {
+		return x.Language
+	}
+	return ""
+}
+
+func (x *Connected) GetConnectMs() uint32 {
+	if x != nil {
+		return x.ConnectMs
+	}
+	return 0
+}
+
+func (x *Connected) GetRequestId() string {
+	if x != nil {
+		return x.RequestId
+	}
+	return ""
+}
+
+func (x *Connected) GetSwitchReason() string {
+	if x != nil {
+		return x.SwitchReason
+	}
+	return ""
+}
+
 type TranscriptInterim struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	SessionId     string                 `protobuf:"bytes,1,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
-	UtteranceId   string                 `protobuf:"bytes,2,opt,name=utterance_id,json=utteranceId,proto3" json:"utterance_id,omitempty"`
-	Text          string                 `protobuf:"bytes,3,opt,name=text,proto3" json:"text,omitempty"`
+	state       protoimpl.MessageState `protogen:"open.v1"`
+	SessionId   string                 `protobuf:"bytes,1,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
+	UtteranceId string                 `protobuf:"bytes,2,opt,name=utterance_id,json=utteranceId,proto3" json:"utterance_id,omitempty"`
+	Text        string                 `protobuf:"bytes,3,opt,name=text,proto3" json:"text,omitempty"`
+	// text split for display: the committed prefix only grows within an
+	// utterance, the volatile suffix may still change. Both empty when
+	// stabilization is off.
+	CommittedText string `protobuf:"bytes,4,opt,name=committed_text,json=committedText,proto3" json:"committed_text,omitempty"`
+	VolatileText  string `protobuf:"bytes,5,opt,name=volatile_text,json=volatileText,proto3" json:"volatile_text,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -622,13 +691,39 @@ func (x *TranscriptInterim) GetText() string {
 	return ""
 }
 
+func (x *TranscriptInterim) GetCommittedText() string {
+	if x != nil {
+		return x.CommittedText
+	}
+	return ""
+}
+
+func (x *TranscriptInterim) GetVolatileText() string {
+	if x != nil {
+		return x.VolatileText
+	}
+	return ""
+}
+
 type TranscriptFinal struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	SessionId     string                 `protobuf:"bytes,1,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
-	UtteranceId   string                 `protobuf:"bytes,2,opt,name=utterance_id,json=utteranceId,proto3" json:"utterance_id,omitempty"`
-	Text          string                 `protobuf:"bytes,3,opt,name=text,proto3" json:"text,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+	state       protoimpl.MessageState `protogen:"open.v1"`
+	SessionId   string                 `protobuf:"bytes,1,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
+	UtteranceId string                 `protobuf:"bytes,2,opt,name=utterance_id,json=utteranceId,proto3" json:"utterance_id,omitempty"`
+	Text        string                 `protobuf:"bytes,3,opt,name=text,proto3" json:"text,omitempty"`
+	// From provider word timestamps; zero when unavailable.
+	WordCount uint32 `protobuf:"varint,4,opt,name=word_count,json=wordCount,proto3" json:"word_count,omitempty"`
+	SpeechMs  uint32 `protobuf:"varint,5,opt,name=speech_ms,json=speechMs,proto3" json:"speech_ms,omitempty"` // first word start to last word end
+	// Diarized speaker of most of the words, 1-based; 0 when diarization is off.
+	Speaker uint32 `protobuf:"varint,6,opt,name=speaker,proto3" json:"speaker,omitempty"`
+	// What ended the utterance: "" (provider endpointing), "drain" or "early"
+	// (a stable interim promoted under the earliest policy).
+	Source string `protobuf:"bytes,7,opt,name=source,proto3" json:"source,omitempty"`
+	// Fingerprint of the voiced audio since the previous final: its energy
+	// envelope, hashed. The same audio sent again gives the same value; 0
+	// when no voiced audio was heard.
+	AudioFingerprint uint64 `protobuf:"fixed64,8,opt,name=audio_fingerprint,json=audioFingerprint,proto3" json:"audio_fingerprint,omitempty"`
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
 }
 
 func (x *TranscriptFinal) Reset() {
@@ -682,12 +777,50 @@ func (x *TranscriptFinal) GetText() string {
 	return ""
 }
 
+func (x *TranscriptFinal) GetWordCount() uint32 {
+	if x != nil {
+		return x.WordCount
+	}
+	return 0
+}
+
+func (x *TranscriptFinal) GetSpeechMs() uint32 {
+	if x != nil {
+		return x.SpeechMs
+	}
+	return 0
+}
+
+func (x *TranscriptFinal) GetSpeaker() uint32 {
+	if x != nil {
+		return x.Speaker
+	}
+	return 0
+}
+
+func (x *TranscriptFinal) GetSource() string {
+	if x != nil {
+		return x.Source
+	}
+	return ""
+}
+
+func (x *TranscriptFinal) GetAudioFingerprint() uint64 {
+	if x != nil {
+		return x.AudioFingerprint
+	}
+	return 0
+}
+
 type Error struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	SessionId     string                 `protobuf:"bytes,1,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
-	Code          string                 `protobuf:"bytes,2,opt,name=code,proto3" json:"code,omitempty"` // deprecated: use enum_code
-	Message       string                 `protobuf:"bytes,3,opt,name=message,proto3" json:"message,omitempty"`
-	EnumCode      ErrorCode              `protobuf:"varint,4,opt,name=enum_code,json=enumCode,proto3,enum=stt.v1.ErrorCode" json:"enum_code,omitempty"`
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	SessionId string                 `protobuf:"bytes,1,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
+	Code      string                 `protobuf:"bytes,2,opt,name=code,proto3" json:"code,omitempty"` // deprecated: use enum_code
+	Message   string                 `protobuf:"bytes,3,opt,name=message,proto3" json:"message,omitempty"`
+	EnumCode  ErrorCode              `protobuf:"varint,4,opt,name=enum_code,json=enumCode,proto3,enum=stt.v1.ErrorCode" json:"enum_code,omitempty"`
+	// The sidecar stopped retrying: the provider rejected its key, and it
+	// won't connect again until the key changes (see internal/stt/authfail.go)
+	Fatal         bool `protobuf:"varint,5,opt,name=fatal,proto3" json:"fatal,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -750,11 +883,27 @@ func (x *Error) GetEnumCode() ErrorCode {
 	return ErrorCode_ERROR_CODE_UNSPECIFIED
 }
 
+func (x *Error) GetFatal() bool {
+	if x != nil {
+		return x.Fatal
+	}
+	return false
+}
+
+// audio_seconds counts audio accepted for the provider (dropped frames are
+// not billed); estimated_cost_usd applies the sidecar's configured rate.
+// final is set on the last Metrics, sent in reply to SessionClose.
 type Metrics struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	SessionId     string                 `protobuf:"bytes,1,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
-	BytesSent     uint64                 `protobuf:"varint,2,opt,name=bytes_sent,json=bytesSent,proto3" json:"bytes_sent,omitempty"`
-	FramesSent    uint64                 `protobuf:"varint,3,opt,name=frames_sent,json=framesSent,proto3" json:"frames_sent,omitempty"`
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	SessionId        string                 `protobuf:"bytes,1,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
+	BytesSent        uint64                 `protobuf:"varint,2,opt,name=bytes_sent,json=bytesSent,proto3" json:"bytes_sent,omitempty"`
+	FramesSent       uint64                 `protobuf:"varint,3,opt,name=frames_sent,json=framesSent,proto3" json:"frames_sent,omitempty"`
+	AudioSeconds     float64                `protobuf:"fixed64,4,opt,name=audio_seconds,json=audioSeconds,proto3" json:"audio_seconds,omitempty"`
+	EstimatedCostUsd float64                `protobuf:"fixed64,5,opt,name=estimated_cost_usd,json=estimatedCostUsd,proto3" json:"estimated_cost_usd,omitempty"`
+	Final            bool                   `protobuf:"varint,6,opt,name=final,proto3" json:"final,omitempty"`
+	// Frames this session dropped before the provider, by reason
+	// (queue_full, circuit_open, socket_dead, oversize)
+	Drops         map[string]uint64 `protobuf:"bytes,7,rep,name=drops,proto3" json:"drops,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"varint,2,opt,name=value"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -810,6 +959,34 @@ func (x *Metrics) GetFramesSent() uint64 {
 	return 0
 }
 
+func (x *Metrics) GetAudioSeconds() float64 {
+	if x != nil {
+		return x.AudioSeconds
+	}
+	return 0
+}
+
+func (x *Metrics) GetEstimatedCostUsd() float64 {
+	if x != nil {
+		return x.EstimatedCostUsd
+	}
+	return 0
+}
+
+func (x *Metrics) GetFinal() bool {
+	if x != nil {
+		return x.Final
+	}
+	return false
+}
+
+func (x *Metrics) GetDrops() map[string]uint64 {
+	if x != nil {
+		return x.Drops
+	}
+	return nil
+}
+
 type ServerMessage struct {
 	state protoimpl.MessageState `protogen:"open.v1"`
 	// Types that are valid to be assigned to Msg:
@@ -960,7 +1137,7 @@ var File_stt_proto protoreflect.FileDescriptor
 
 const file_stt_proto_rawDesc = "" +
 	"\n" +
-	"\tstt.proto\x12\x06stt.v1\"\xd5\x01\n" +
+	"\tstt.proto\x12\x06stt.v1\"\xff\x01\n" +
 	"\fControlStart\x12\x1d\n" +
 	"\n" +
 	"session_id\x18\x01 \x01(\tR\tsessionId\x12\x1b\n" +
@@ -969,7 +1146,8 @@ const file_stt_proto_rawDesc = "" +
 	"\blanguage\x18\x04 \x01(\tR\blanguage\x12\x1f\n" +
 	"\vsample_rate\x18\x05 \x01(\rR\n" +
 	"sampleRate\x12)\n" +
-	"\x10protocol_version\x18\x06 \x01(\tR\x0fprotocolVersion\"E\n" +
+	"\x10protocol_version\x18\x06 \x01(\tR\x0fprotocolVersion\x12(\n" +
+	"\x10no_audio_archive\x18\a \x01(\bR\x0enoAudioArchive\"E\n" +
 	"\n" +
 	"AudioChunk\x12\x16\n" +
 	"\x06pcm16k\x18\x01 \x01(\fR\x06pcm16k\x12\x1f\n" +
@@ -987,34 +1165,58 @@ const file_stt_proto_rawDesc = "" +
 	"\x05drain\x18\x03 \x01(\v2\r.stt.v1.DrainH\x00R\x05drain\x12,\n" +
 	"\x05close\x18\x04 \x01(\v2\x14.stt.v1.SessionCloseH\x00R\x05close\x12\"\n" +
 	"\x04ping\x18\x05 \x01(\v2\f.stt.v1.PingH\x00R\x04pingB\x05\n" +
-	"\x03msg\"@\n" +
+	"\x03msg\"\xdb\x01\n" +
 	"\tConnected\x12\x1d\n" +
 	"\n" +
 	"session_id\x18\x01 \x01(\tR\tsessionId\x12\x14\n" +
-	"\x05model\x18\x02 \x01(\tR\x05model\"i\n" +
+	"\x05model\x18\x02 \x01(\tR\x05model\x12\x1a\n" +
+	"\bprovider\x18\x03 \x01(\tR\bprovider\x12\x1a\n" +
+	"\blanguage\x18\x04 \x01(\tR\blanguage\x12\x1d\n" +
+	"\n" +
+	"connect_ms\x18\x05 \x01(\rR\tconnectMs\x12\x1d\n" +
+	"\n" +
+	"request_id\x18\x06 \x01(\tR\trequestId\x12#\n" +
+	"\rswitch_reason\x18\a \x01(\tR\fswitchReason\"\xb5\x01\n" +
 	"\x11TranscriptInterim\x12\x1d\n" +
 	"\n" +
 	"session_id\x18\x01 \x01(\tR\tsessionId\x12!\n" +
 	"\futterance_id\x18\x02 \x01(\tR\vutteranceId\x12\x12\n" +
-	"\x04text\x18\x03 \x01(\tR\x04text\"g\n" +
+	"\x04text\x18\x03 \x01(\tR\x04text\x12%\n" +
+	"\x0ecommitted_text\x18\x04 \x01(\tR\rcommittedText\x12#\n" +
+	"\rvolatile_text\x18\x05 \x01(\tR\fvolatileText\"\x82\x02\n" +
 	"\x0fTranscriptFinal\x12\x1d\n" +
 	"\n" +
 	"session_id\x18\x01 \x01(\tR\tsessionId\x12!\n" +
 	"\futterance_id\x18\x02 \x01(\tR\vutteranceId\x12\x12\n" +
-	"\x04text\x18\x03 \x01(\tR\x04text\"\x84\x01\n" +
+	"\x04text\x18\x03 \x01(\tR\x04text\x12\x1d\n" +
+	"\n" +
+	"word_count\x18\x04 \x01(\rR\twordCount\x12\x1b\n" +
+	"\tspeech_ms\x18\x05 \x01(\rR\bspeechMs\x12\x18\n" +
+	"\aspeaker\x18\x06 \x01(\rR\aspeaker\x12\x16\n" +
+	"\x06source\x18\a \x01(\tR\x06source\x12+\n" +
+	"\x11audio_fingerprint\x18\b \x01(\x06R\x10audioFingerprint\"\x9a\x01\n" +
 	"\x05Error\x12\x1d\n" +
 	"\n" +
 	"session_id\x18\x01 \x01(\tR\tsessionId\x12\x12\n" +
 	"\x04code\x18\x02 \x01(\tR\x04code\x12\x18\n" +
 	"\amessage\x18\x03 \x01(\tR\amessage\x12.\n" +
-	"\tenum_code\x18\x04 \x01(\x0e2\x11.stt.v1.ErrorCodeR\benumCode\"h\n" +
+	"\tenum_code\x18\x04 \x01(\x0e2\x11.stt.v1.ErrorCodeR\benumCode\x12\x14\n" +
+	"\x05fatal\x18\x05 \x01(\bR\x05fatal\"\xbd\x02\n" +
 	"\aMetrics\x12\x1d\n" +
 	"\n" +
 	"session_id\x18\x01 \x01(\tR\tsessionId\x12\x1d\n" +
 	"\n" +
 	"bytes_sent\x18\x02 \x01(\x04R\tbytesSent\x12\x1f\n" +
 	"\vframes_sent\x18\x03 \x01(\x04R\n" +
-	"framesSent\"\xa9\x02\n" +
+	"framesSent\x12#\n" +
+	"\raudio_seconds\x18\x04 \x01(\x01R\faudioSeconds\x12,\n" +
+	"\x12estimated_cost_usd\x18\x05 \x01(\x01R\x10estimatedCostUsd\x12\x14\n" +
+	"\x05final\x18\x06 \x01(\bR\x05final\x120\n" +
+	"\x05drops\x18\a \x03(\v2\x1a.stt.v1.Metrics.DropsEntryR\x05drops\x1a8\n" +
+	"\n" +
+	"DropsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\x04R\x05value:\x028\x01\"\xa9\x02\n" +
 	"\rServerMessage\x121\n" +
 	"\tconnected\x18\x01 \x01(\v2\x11.stt.v1.ConnectedH\x00R\tconnected\x125\n" +
 	"\ainterim\x18\x02 \x01(\v2\x19.stt.v1.TranscriptInterimH\x00R\ainterim\x12/\n" +
@@ -1022,7 +1224,7 @@ const file_stt_proto_rawDesc = "" +
 	"\x05error\x18\x04 \x01(\v2\r.stt.v1.ErrorH\x00R\x05error\x12\"\n" +
 	"\x04pong\x18\x05 \x01(\v2\f.stt.v1.PongH\x00R\x04pong\x12+\n" +
 	"\ametrics\x18\x06 \x01(\v2\x0f.stt.v1.MetricsH\x00R\ametricsB\x05\n" +
-	"\x03msg*\x92\x01\n" +
+	"\x03msg*\xc8\x01\n" +
 	"\tErrorCode\x12\x1a\n" +
 	"\x16ERROR_CODE_UNSPECIFIED\x10\x00\x12\x15\n" +
 	"\x11CONNECTION_FAILED\x10\x01\x12\x12\n" +
@@ -1030,7 +1232,10 @@ const file_stt_proto_rawDesc = "" +
 	"\aTIMEOUT\x10\x03\x12\x10\n" +
 	"\fCIRCUIT_OPEN\x10\x04\x12\x11\n" +
 	"\rINVALID_AUDIO\x10\x05\x12\f\n" +
-	"\bSHUTDOWN\x10\x062B\n" +
+	"\bSHUTDOWN\x10\x06\x12\x0f\n" +
+	"\vAUTH_FAILED\x10\a\x12\x10\n" +
+	"\fRATE_LIMITED\x10\b\x12\x11\n" +
+	"\rSOCKET_CLOSED\x10\t2B\n" +
 	"\x03STT\x12;\n" +
 	"\aSession\x12\x15.stt.v1.ClientMessage\x1a\x15.stt.v1.ServerMessage(\x010\x01B Z\x1eyuzu/agent/internal/stt/pb;sttb\x06proto3"
 
@@ -1047,7 +1252,7 @@ func file_stt_proto_rawDescGZIP() []byte {
 }
 
 var file_stt_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
-var file_stt_proto_msgTypes = make([]protoimpl.MessageInfo, 13)
+var file_stt_proto_msgTypes = make([]protoimpl.MessageInfo, 14)
 var file_stt_proto_goTypes = []any{
 	(ErrorCode)(0),            // 0: stt.v1.ErrorCode
 	(*ControlStart)(nil),      // 1: stt.v1.ControlStart
@@ -1063,6 +1268,7 @@ var file_stt_proto_goTypes = []any{
 	(*Error)(nil),             // 11: stt.v1.Error
 	(*Metrics)(nil),           // 12: stt.v1.Metrics
 	(*ServerMessage)(nil),     // 13: stt.v1.ServerMessage
+	nil,                       // 14: stt.v1.Metrics.DropsEntry
 }
 var file_stt_proto_depIdxs = []int32{
 	1,  // 0: stt.v1.ClientMessage.start:type_name -> stt.v1.ControlStart
@@ -1071,19 +1277,20 @@ var file_stt_proto_depIdxs = []int32{
 	4,  // 3: stt.v1.ClientMessage.close:type_name -> stt.v1.SessionClose
 	5,  // 4: stt.v1.ClientMessage.ping:type_name -> stt.v1.Ping
 	0,  // 5: stt.v1.Error.enum_code:type_name -> stt.v1.ErrorCode
-	8,  // 6: stt.v1.ServerMessage.connected:type_name -> stt.v1.Connected
-	9,  // 7: stt.v1.ServerMessage.interim:type_name -> stt.v1.TranscriptInterim
-	10, // 8: stt.v1.ServerMessage.final:type_name -> stt.v1.TranscriptFinal
-	11, // 9: stt.v1.ServerMessage.error:type_name -> stt.v1.Error
-	6,  // 10: stt.v1.ServerMessage.pong:type_name -> stt.v1.Pong
-	12, // 11: stt.v1.ServerMessage.metrics:type_name -> stt.v1.Metrics
-	7,  // 12: stt.v1.STT.Session:input_type -> stt.v1.ClientMessage
-	13, // 13: stt.v1.STT.Session:output_type -> stt.v1.ServerMessage
-	13, // [13:14] is the sub-list for method output_type
-	12, // [12:13] is the sub-list for method input_type
-	12, // [12:12] is the sub-list for extension type_name
-	12, // [12:12] is the sub-list for extension extendee
-	0,  // [0:12] is the sub-list for field type_name
+	14, // 6: stt.v1.Metrics.drops:type_name -> stt.v1.Metrics.DropsEntry
+	8,  // 7: stt.v1.ServerMessage.connected:type_name -> stt.v1.Connected
+	9,  // 8: stt.v1.ServerMessage.interim:type_name -> stt.v1.TranscriptInterim
+	10, // 9: stt.v1.ServerMessage.final:type_name -> stt.v1.TranscriptFinal
+	11, // 10: stt.v1.ServerMessage.error:type_name -> stt.v1.Error
+	6,  // 11: stt.v1.ServerMessage.pong:type_name -> stt.v1.Pong
+	12, // 12: stt.v1.ServerMessage.metrics:type_name -> stt.v1.Metrics
+	7,  // 13: stt.v1.STT.Session:input_type -> stt.v1.ClientMessage
+	13, // 14: stt.v1.STT.Session:output_type -> stt.v1.ServerMessage
+	14, // [14:15] is the sub-list for method output_type
+	13, // [13:14] is the sub-list for method input_type
+	13, // [13:13] is the sub-list for extension type_name
+	13, // [13:13] is the sub-list for extension extendee
+	0,  // [0:13] is the sub-list for field type_name
 }
 
 func init() { file_stt_proto_init() }
@@ -1112,7 +1319,7 @@ func file_stt_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_stt_proto_rawDesc), len(file_stt_proto_rawDesc)),
 			NumEnums:      1,
-			NumMessages:   13,
+			NumMessages:   14,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
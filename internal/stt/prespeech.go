@@ -0,0 +1,56 @@
+package stt
+
+import (
+    "os"
+    "strconv"
+)
+
+// prespeech.go holds on to the audio a stream receives while no utterance is
+// open: before its first ControlStart, and after a Drain until the next one.
+// That audio used to be dropped (no session yet) or streamed into a provider
+// that had already been told the utterance was over. Instead the stream keeps
+// the last STT_PRESPEECH_MS of it (default 300, 0 disables) and, when the
+// next utterance starts, sends it to the provider ahead of the new audio, so
+// the first syllables the gateway's VAD needed to hear before firing are
+// transcribed too. Bytes leave the buffer as flushed or expired, counted in
+// stt_prespeech_bytes_total{outcome}.
+
+// preSpeech is the rolling buffer; it belongs to the stream's goroutine.
+type preSpeech struct {
+    max    int // bytes; 0 disables
+    frames [][]byte
+    size   int
+}
+
+func loadPreSpeech() preSpeech {
+    ms := 300
+    if v, err := strconv.Atoi(os.Getenv("STT_PRESPEECH_MS")); err == nil && v >= 0 {
+        ms = v
+    }
+    return preSpeech{max: ms * 32} // 16kHz PCM16: 32 bytes per ms
+}
+
+func (p *preSpeech) enabled() bool { return p.max > 0 }
+
+// add appends a frame, expiring the oldest ones beyond the window. The frame
+// is kept, not copied.
+func (p *preSpeech) add(b []byte) {
+    p.frames = append(p.frames, b)
+    p.size += len(b)
+    for p.size > p.max && len(p.frames) > 0 {
+        p.size -= len(p.frames[0])
+        metricPreSpeechBytes.WithLabelValues("expired").Add(float64(len(p.frames[0])))
+        p.frames[0] = nil
+        p.frames = p.frames[1:]
+    }
+}
+
+// take empties the buffer, returning its frames oldest first.
+func (p *preSpeech) take() [][]byte {
+    out := p.frames
+    if p.size > 0 {
+        metricPreSpeechBytes.WithLabelValues("flushed").Add(float64(p.size))
+    }
+    p.frames, p.size = nil, 0
+    return out
+}
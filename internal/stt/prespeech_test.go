@@ -0,0 +1,37 @@
+package stt
+
+import (
+    "bytes"
+    "testing"
+)
+
+func TestPreSpeechKeepsNewestWindow(t *testing.T) {
+    t.Setenv("STT_PRESPEECH_MS", "60")
+    p := loadPreSpeech()
+    if !p.enabled() || p.max != 60*32 {
+        t.Fatalf("max = %d, want %d", p.max, 60*32)
+    }
+    // Five 20ms frames, each filled with its index
+    for i := 0; i < 5; i++ {
+        p.add(bytes.Repeat([]byte{byte(i)}, 640))
+    }
+    got := p.take()
+    if len(got) != 3 {
+        t.Fatalf("kept %d frames, want the last 3", len(got))
+    }
+    for i, f := range got {
+        if f[0] != byte(i+2) {
+            t.Fatalf("frame %d is #%d, want #%d (oldest first)", i, f[0], i+2)
+        }
+    }
+    if p.size != 0 || len(p.take()) != 0 {
+        t.Fatal("take should empty the buffer")
+    }
+}
+
+func TestPreSpeechDisabled(t *testing.T) {
+    t.Setenv("STT_PRESPEECH_MS", "0")
+    if p := loadPreSpeech(); p.enabled() {
+        t.Fatal("STT_PRESPEECH_MS=0 should disable the buffer")
+    }
+}
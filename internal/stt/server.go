@@ -8,6 +8,7 @@ import (
     "sync"
     "time"
 
+    "yuzu/agent/internal/clock"
     pb "yuzu/agent/internal/stt/pb"
 )
 
@@ -18,15 +19,25 @@ type STTServer struct {
     mu    sync.Mutex
     sess  map[string]*Session
     idleTTL time.Duration
+    clock   clock.Clock
+    // adminToken guards AdminHandler (see framering.go); empty disables it
+    adminToken string
 }
 
 func NewSTTServer() *STTServer {
-    s := &STTServer{ready: true, sess: make(map[string]*Session)}
+    return newSTTServer(clock.Real)
+}
+
+func newSTTServer(clk clock.Clock) *STTServer {
+    s := &STTServer{ready: true, sess: make(map[string]*Session), clock: clk, adminToken: os.Getenv("STT_ADMIN_TOKEN")}
     s.idleTTL = readIdleTTL()
-    go s.reaper()
+    go s.reaper(clk.NewTicker(10 * time.Second))
     return s
 }
-func (s *STTServer) Ready() bool { return s.ready }
+
+// Ready reports whether the sidecar can serve sessions; not while its
+// provider key is rejected (see authfail.go).
+func (s *STTServer) Ready() bool { return s.ready && !authBlocked() }
 
 // Session handles the gRPC bidi stream, routing to per-session state and provider.
 func (s *STTServer) Session(stream pb.STT_SessionServer) error {
@@ -36,6 +47,9 @@ func (s *STTServer) Session(stream pb.STT_SessionServer) error {
     // Metrics cadence
     var bytesIn, framesIn uint64
     lastMet := time.Now()
+    // Audio between utterances waits here (see prespeech.go)
+    pre := loadPreSpeech()
+    open := false
 
     // Non-blocking forwarder from provider → client
     var evCh <-chan *pb.ServerMessage
@@ -68,14 +82,21 @@ func (s *STTServer) Session(stream pb.STT_SessionServer) error {
             s.mu.Lock()
             sess = s.sess[sessionID]
             if sess == nil {
-                sess = NewSession(ctx, sessionID)
+                sess = newSession(ctx, sessionID, s.clock)
                 s.sess[sessionID] = sess
                 gaugeSessions.Inc()
                 log.Printf("[stt] new session created session=%s", sessionID)
             }
             s.mu.Unlock()
+            if m.Start.GetNoAudioArchive() {
+                sess.noArchive.Store(true)
+            }
             sess.StartUtterance(utterID)
-            send(&pb.ServerMessage{Msg: &pb.ServerMessage_Connected{Connected: &pb.Connected{SessionId: sessionID, Model: "nova-2"}}})
+            open = true
+            for _, b := range pre.take() {
+                sess.SendAudio(b)
+            }
+            send(sess.conn().connectedMsg(sessionID))
             if evCh == nil {
                 evCh = sess.events
             }
@@ -86,19 +107,25 @@ func (s *STTServer) Session(stream pb.STT_SessionServer) error {
             if framesIn == 1 || framesIn%100 == 0 {
                 log.Printf("[stt] audio frame=%d bytes=%d sess_exists=%v", framesIn, len(b), sess != nil)
             }
-            if sess != nil && len(b) > 0 {
+            if !open && pre.enabled() {
+                if len(b) > 0 { pre.add(b) }
+            } else if sess != nil && len(b) > 0 {
                 sess.SendAudio(b)
             } else if sess == nil {
                 log.Printf("[stt] audio dropped: no session yet frame=%d", framesIn)
             }
             if time.Since(lastMet) >= time.Second || framesIn%10 == 0 {
-                send(&pb.ServerMessage{Msg: &pb.ServerMessage_Metrics{Metrics: &pb.Metrics{SessionId: sessionID, BytesSent: bytesIn, FramesSent: framesIn}}})
+                send(usageMetrics(sess, sessionID, bytesIn, framesIn, false))
                 lastMet = time.Now()
             }
         case *pb.ClientMessage_Drain:
             if sess != nil { sess.Drain() }
+            open = false
         case *pb.ClientMessage_Close:
-            if sess != nil { sess.Close() }
+            if sess != nil {
+                sess.Close()
+                send(usageMetrics(sess, sessionID, bytesIn, framesIn, true))
+            }
             if sessionID != "" {
                 s.mu.Lock()
                 delete(s.sess, sessionID)
@@ -114,6 +141,16 @@ func (s *STTServer) Session(stream pb.STT_SessionServer) error {
     }
 }
 
+// usageMetrics reports the stream's counters with sess's billed usage.
+func usageMetrics(sess *Session, sessionID string, bytesIn, framesIn uint64, final bool) *pb.ServerMessage {
+    m := &pb.Metrics{SessionId: sessionID, BytesSent: bytesIn, FramesSent: framesIn, Final: final}
+    if sess != nil {
+        m.AudioSeconds, m.EstimatedCostUsd = sess.Usage()
+        m.Drops = sess.Drops()
+    }
+    return &pb.ServerMessage{Msg: &pb.ServerMessage_Metrics{Metrics: m}}
+}
+
 // GracefulShutdown flips readiness and can await draining work if needed.
 func (s *STTServer) GracefulShutdown(ctx context.Context, timeout time.Duration) error {
     s.ready = false
@@ -125,20 +162,24 @@ func (s *STTServer) GracefulShutdown(ctx context.Context, timeout time.Duration)
     }
 }
 
-func (s *STTServer) reaper() {
-    ticker := time.NewTicker(10 * time.Second)
+func (s *STTServer) reaper(ticker clock.Ticker) {
     defer ticker.Stop()
-    for range ticker.C {
-        s.mu.Lock()
-        ttl := s.idleTTL
-        for id, sess := range s.sess {
-            if sess.IdleFor(ttl) {
-                sess.Close()
-                delete(s.sess, id)
-                gaugeSessions.Dec()
-            }
+    for range ticker.C() {
+        s.reapIdle()
+    }
+}
+
+// reapIdle closes sessions idle for at least idleTTL.
+func (s *STTServer) reapIdle() {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+    ttl := s.idleTTL
+    for id, sess := range s.sess {
+        if sess.IdleFor(ttl) {
+            sess.Close()
+            delete(s.sess, id)
+            gaugeSessions.Dec()
         }
-        s.mu.Unlock()
     }
 }
 
@@ -0,0 +1,88 @@
+package stt
+
+import (
+    "context"
+    "path/filepath"
+    "testing"
+    "time"
+
+    "github.com/prometheus/client_golang/prometheus/testutil"
+
+    "yuzu/agent/internal/clock"
+)
+
+// idleSession builds a Session without a provider connection.
+func idleSession(clk clock.Clock, id string) *Session {
+    ctx, cancel := context.WithCancel(context.Background())
+    return &Session{ctx: ctx, cancel: cancel, id: id, lastAct: clk.Now(), clock: clk}
+}
+
+func TestReaperClosesIdleSessions(t *testing.T) {
+    clk := clock.NewFake(time.Unix(1700000000, 0))
+    // No background reaper: the test drives reapIdle directly
+    s := &STTServer{sess: make(map[string]*Session), clock: clk, idleTTL: 60 * time.Second}
+
+    quiet := idleSession(clk, "quiet")
+    busy := idleSession(clk, "busy")
+    s.mu.Lock()
+    s.sess["quiet"], s.sess["busy"] = quiet, busy
+    s.mu.Unlock()
+
+    clk.Advance(50 * time.Second)
+    busy.Drain() // activity resets the idle timer
+    s.reapIdle()
+    if quiet.ctx.Err() != nil || busy.ctx.Err() != nil {
+        t.Fatal("no session should be reaped before the TTL")
+    }
+
+    clk.Advance(15 * time.Second)
+    s.reapIdle()
+    s.mu.Lock()
+    _, quietLeft := s.sess["quiet"]
+    _, busyLeft := s.sess["busy"]
+    s.mu.Unlock()
+    if quietLeft || quiet.ctx.Err() == nil {
+        t.Fatal("session idle for 65s should be closed and removed")
+    }
+    if !busyLeft || busy.ctx.Err() != nil {
+        t.Fatal("session active 15s ago should survive")
+    }
+}
+
+func TestReaperRunsOnTicker(t *testing.T) {
+    t.Setenv("STT_SESSION_IDLE_TTL_S", "60")
+    clk := clock.NewFake(time.Unix(1700000000, 0))
+    s := newSTTServer(clk)
+    sess := idleSession(clk, "a")
+    s.mu.Lock()
+    s.sess["a"] = sess
+    s.mu.Unlock()
+
+    clk.Advance(70 * time.Second)
+    select {
+    case <-sess.ctx.Done():
+    case <-time.After(2 * time.Second):
+        t.Fatal("reaper did not run on fake tick")
+    }
+}
+
+func TestNoAudioArchiveSkipsSamples(t *testing.T) {
+    prev := saveSamples
+    saveSamples = true
+    defer func() { saveSamples = prev }()
+    s := idleSession(clock.NewFake(time.Unix(1700000000, 0)), "optout1-session")
+    s.dg = &DeepgramConn{sendQ: make(chan *[]byte, 8)}
+    s.noArchive.Store(true)
+    before := testutil.ToFloat64(metricArchiveRefused)
+
+    for i := 0; i < 3; i++ {
+        s.SendAudio(tone(0, 320, 440, 8000, 0))
+        putFrame(<-s.dg.sendQ)
+    }
+    if files, _ := filepath.Glob("/tmp/stt_audio_sample_optout1-*"); len(files) != 0 {
+        t.Fatalf("audio written for an opted-out session: %v", files)
+    }
+    if n := testutil.ToFloat64(metricArchiveRefused) - before; n != 1 {
+        t.Fatalf("refusals counted %v times, want once per session", n)
+    }
+}
@@ -8,8 +8,10 @@ import (
     "os"
     "strings"
     "sync"
+    "sync/atomic"
     "time"
 
+    "yuzu/agent/internal/clock"
     pb "yuzu/agent/internal/stt/pb"
 )
 
@@ -21,172 +23,330 @@ type Session struct {
 
     id        string
     utterID   string
+    // baseUtterID is the orchestrator-issued ID from ControlStart; rollovers
+    // within it are numbered baseUtterID.<rollSeq>.
+    baseUtterID string
+    rollSeq     int
     startedAt time.Time
     lastAct   time.Time
+    clock     clock.Clock // drives lastAct/IdleFor so reaping is testable
 
-    dg     *DeepgramConn
+    dg     *DeepgramConn // the active connection; read it with conn() off the run goroutine
+    shadow *shadowState  // nil unless STT_SHADOW_MODEL is set (see shadow.go)
     events chan *pb.ServerMessage
+    dsp    *Preprocessor // nil unless STT_DSP_* enables a stage (see dsp.go)
 
     bytesIn  uint64
     framesIn uint64
     lastMet  time.Time
 
+    // Audio accepted for the provider (see usage.go)
+    billedBytes uint64
+    usageDone   bool
+
+    // Dropped frames by reason and the drop-rate alarm (see drops.go)
+    drops map[string]uint64
+    alarm *dropAlarm
+
     lastInterim string
     seenFirstInterim bool
     drainAt time.Time
     endpointPolicy string // "provider" | "earliest"
+    early earlyState // stable-interim promotion under "earliest" (see early.go)
+    dup dupFinalState // near-duplicate finals (see dupfinal.go)
+    print audioPrint // audio behind the next final (see fingerprint.go)
+    stable interimStabilizer // committed interim prefix (see stabilize.go)
     finalEmitted bool
     lastFinalText string
     lastSpeechStarted time.Time
     lastUtteranceEndAt time.Time
     lastInterimAt time.Time
     inUtterance bool
+
+    // The client opted out of audio archiving (ControlStart.no_audio_archive);
+    // once set it stays set
+    noArchive atomic.Bool
+    archiveRefused bool
 }
 
 func NewSession(parent context.Context, sessionID string) *Session {
+    return newSession(parent, sessionID, clock.Real)
+}
+
+func newSession(parent context.Context, sessionID string, clk clock.Clock) *Session {
     ctx, cancel := context.WithCancel(parent)
-    now := time.Now()
-    s := &Session{ctx: ctx, cancel: cancel, id: sessionID, lastMet: now, lastAct: now}
+    now := clk.Now()
+    s := &Session{ctx: ctx, cancel: cancel, id: sessionID, lastMet: now, lastAct: now, clock: clk, alarm: newDropAlarm()}
     // Create Deepgram connection
     cfg := LoadDGConfigFromEnv()
-    apiKey := os.Getenv("DEEPGRAM_API_KEY")
-    s.dg = NewDeepgramConn(ctx, cfg, apiKey)
+    s.dg = NewDeepgramConn(ctx, cfg, loadDeepgramKey())
+    s.shadow = newShadow(ctx, s.dg)
+    s.dsp = NewPreprocessor(LoadDSPConfigFromEnv())
     pol := os.Getenv("STT_ENDPOINTING_POLICY")
     if pol == "" { pol = "provider" }
     s.endpointPolicy = pol
+    s.early.load(pol)
+    s.dup.load()
+    s.stable.load()
     s.events = make(chan *pb.ServerMessage, 64)
     go s.run()
     s.dg.Start()
+    if s.shadow != nil {
+        s.shadow.legs[1].dg.Start()
+    }
     return s
 }
 
 func (s *Session) run() {
-    // forward Deepgram events to gRPC layer
-    for e := range s.dg.Events {
-        switch e.Type {
-        case "interim":
-            now := time.Now()
-            // Guardrail: if finalEmitted is stuck true and we've been seeing interims for > X ms, force reset
-            // This handles cases where UtteranceEnd was missed/dropped
-            if s.finalEmitted && !s.lastInterimAt.IsZero() {
-                stuckMs := 1200
-                if v := os.Getenv("STT_STUCK_FINAL_RESET_MS"); v != "" { fmt.Sscanf(v, "%d", &stuckMs) }
-                if now.Sub(s.lastInterimAt) < time.Duration(stuckMs)*time.Millisecond {
-                    // We've been getting interims continuously - check how long since final was emitted
-                    // Use startedAt as a proxy for when the final was emitted
-                    if now.Sub(s.startedAt) >= time.Duration(stuckMs)*time.Millisecond {
-                        log.Printf("[stt] GUARDRAIL: forcing reset of stuck finalEmitted after %dms of interims session=%s", stuckMs, s.id)
-                        s.finalEmitted = false
-                        s.lastFinalText = ""
-                        s.inUtterance = false
-                        metricUtteranceEvents.WithLabelValues("guardrail_reset").Inc()
-                    }
-                }
+    // Stable interims are promoted on a tick under the "earliest" policy
+    var tick <-chan time.Time
+    if s.early.after > 0 {
+        t := time.NewTicker(earlyTick)
+        defer t.Stop()
+        tick = t.C
+    }
+    // forward Deepgram events to gRPC layer; a shadow's are only observed
+    // until it takes over. Which connection is active can change with any
+    // event (switchProvider), so it is looked up each time round, and only
+    // the active one closing ends the session.
+    var closed [2]bool
+    for {
+        active := s.dg
+        var standby *DeepgramConn
+        var standbyEvents <-chan DGEvent
+        si := 0
+        if sh := s.shadow; sh != nil {
+            if closed[sh.active] {
+                close(s.events)
+                return
             }
-            // If idle (no active utterance), consider committing a new utterance based on silence and interim length
-            if !s.inUtterance {
-                minSil := 700
-                if v := os.Getenv("MIN_SILENCE_FOR_NEW_UTTER_MS"); v != "" { fmt.Sscanf(v, "%d", &minSil) }
-                minChars := 4
-                if v := os.Getenv("MIN_INTERIM_CHARS_FOR_NEW_UTTER"); v != "" { fmt.Sscanf(v, "%d", &minChars) }
-                prevInterimAt := s.lastInterimAt
-                silenceOK := prevInterimAt.IsZero() || now.Sub(prevInterimAt) >= time.Duration(minSil)*time.Millisecond || (!s.lastUtteranceEndAt.IsZero() && now.Sub(s.lastUtteranceEndAt) >= 0)
-                if len(strings.TrimSpace(e.Text)) >= minChars && silenceOK {
-                    newID := fmt.Sprintf("utt-%d", now.UnixMilli())
-                    log.Printf("[stt] committing new utterance on interim id=%s session=%s", newID, s.id)
-                    s.StartUtterance(newID)
-                    s.inUtterance = true
-                }
+            si = 1 - sh.active
+            if !closed[si] {
+                standby = sh.legs[si].dg
+                standbyEvents = standby.Events
             }
-            log.Printf("[stt] interim transcript session=%s text=%q", s.id, e.Text)
-            s.lastInterim = e.Text
-            s.lastInterimAt = time.Now()
-            if !s.seenFirstInterim && !s.startedAt.IsZero() {
-                s.seenFirstInterim = true
-                ms := time.Since(s.startedAt).Milliseconds()
-                if ms > 0 { metricTTFTMS.Observe(float64(ms)) }
+        }
+        select {
+        case e, ok := <-active.Events:
+            if !ok {
+                close(s.events)
+                return
             }
-            s.events <- &pb.ServerMessage{Msg: &pb.ServerMessage_Interim{Interim: &pb.TranscriptInterim{SessionId: s.id, UtteranceId: s.utterID, Text: e.Text}}}
-        case "final":
-            now := time.Now()
-            log.Printf("[stt] final transcript received session=%s text=%q finalEmitted=%v", s.id, e.Text, s.finalEmitted)
-            // Skip empty finals
-            if strings.TrimSpace(e.Text) == "" {
-                log.Printf("[stt] skipping empty final session=%s", s.id)
+            s.route(active, e)
+        case e, ok := <-standbyEvents:
+            if !ok {
+                closed[si] = true
                 continue
             }
-            // If we already emitted a final for the current utterance, decide if this is a new utterance.
-            if s.finalEmitted {
-                // If exact duplicate of last final, drop as duplicate.
-                if s.lastFinalText == e.Text {
-                    log.Printf("[stt] skipping duplicate final session=%s (same text)", s.id)
-                    continue
-                }
-                // Narrow rollover: require recent boundary or silence gap before creating a new utterance
-                minSil := 700
-                if v := os.Getenv("MIN_SILENCE_FOR_NEW_UTTER_MS"); v != "" { fmt.Sscanf(v, "%d", &minSil) }
-                boundaryOK := !s.lastUtteranceEndAt.IsZero() && now.Sub(s.lastUtteranceEndAt) <= 3*time.Second
-                silenceOK := s.lastInterimAt.IsZero() || now.Sub(s.lastInterimAt) >= time.Duration(minSil)*time.Millisecond
-                if boundaryOK || silenceOK {
-                    newID := fmt.Sprintf("utt-%d", now.UnixMilli())
-                    log.Printf("[stt] rolling to new utterance for subsequent final; new id=%s session=%s", newID, s.id)
-                    s.StartUtterance(newID)
-                } else {
-                    log.Printf("[stt] skipping subsequent final (no boundary/silence) session=%s", s.id)
-                    continue
+            s.route(standby, e)
+        case <-tick:
+            s.promoteStable()
+        }
+    }
+}
+
+// handleEvent forwards one provider event; it runs on the run goroutine.
+func (s *Session) handleEvent(e DGEvent) {
+    switch e.Type {
+    case "interim":
+        now := time.Now()
+        // Guardrail: if finalEmitted is stuck true and we've been seeing interims for > X ms, force reset
+        // This handles cases where UtteranceEnd was missed/dropped
+        if s.finalEmitted && !s.lastInterimAt.IsZero() {
+            stuckMs := 1200
+            if v := os.Getenv("STT_STUCK_FINAL_RESET_MS"); v != "" { fmt.Sscanf(v, "%d", &stuckMs) }
+            if now.Sub(s.lastInterimAt) < time.Duration(stuckMs)*time.Millisecond {
+                // We've been getting interims continuously - check how long since final was emitted
+                // Use startedAt as a proxy for when the final was emitted
+                if now.Sub(s.startedAt) >= time.Duration(stuckMs)*time.Millisecond {
+                    log.Printf("[stt] GUARDRAIL: forcing reset of stuck finalEmitted after %dms of interims session=%s", stuckMs, s.id)
+                    s.finalEmitted = false
+                    s.lastFinalText = ""
+                    s.inUtterance = false
+                    metricUtteranceEvents.WithLabelValues("guardrail_reset").Inc()
                 }
             }
-            if !s.drainAt.IsZero() {
-                ms := time.Since(s.drainAt).Milliseconds()
-                if ms > 0 { metricFinalLatencyMS.Observe(float64(ms)) }
+        }
+        // If idle (no active utterance), consider committing a new utterance based on silence and interim length
+        if !s.inUtterance {
+            minSil := 700
+            if v := os.Getenv("MIN_SILENCE_FOR_NEW_UTTER_MS"); v != "" { fmt.Sscanf(v, "%d", &minSil) }
+            minChars := 4
+            if v := os.Getenv("MIN_INTERIM_CHARS_FOR_NEW_UTTER"); v != "" { fmt.Sscanf(v, "%d", &minChars) }
+            prevInterimAt := s.lastInterimAt
+            silenceOK := prevInterimAt.IsZero() || now.Sub(prevInterimAt) >= time.Duration(minSil)*time.Millisecond || (!s.lastUtteranceEndAt.IsZero() && now.Sub(s.lastUtteranceEndAt) >= 0)
+            if len(strings.TrimSpace(e.Text)) >= minChars && silenceOK {
+                newID := s.rolloverID(now)
+                log.Printf("[stt] committing new utterance on interim id=%s session=%s", newID, s.id)
+                s.startUtterance(newID)
+                s.inUtterance = true
+            }
+        }
+        log.Printf("[stt] interim transcript session=%s text=%q", s.id, e.Text)
+        if e.Text != s.lastInterim {
+            s.early.stableSince = s.clock.Now()
+        }
+        s.lastInterim = e.Text
+        s.lastInterimAt = time.Now()
+        if !s.seenFirstInterim && !s.startedAt.IsZero() {
+            s.seenFirstInterim = true
+            ms := time.Since(s.startedAt).Milliseconds()
+            if ms > 0 { metricTTFTMS.Observe(float64(ms)) }
+        }
+        utterID := s.utterance()
+        committed, volatile := s.stable.update(utterID, e.Text)
+        s.events <- &pb.ServerMessage{Msg: &pb.ServerMessage_Interim{Interim: &pb.TranscriptInterim{SessionId: s.id, UtteranceId: utterID, Text: e.Text,
+            CommittedText: committed, VolatileText: volatile}}}
+    case "final":
+        now := time.Now()
+        log.Printf("[stt] final transcript received session=%s text=%q finalEmitted=%v", s.id, e.Text, s.finalEmitted)
+        // Skip empty finals
+        if strings.TrimSpace(e.Text) == "" {
+            log.Printf("[stt] skipping empty final session=%s", s.id)
+            return
+        }
+        // The utterance already ended on a promoted interim (see early.go)
+        if s.early.promoted {
+            s.early.promoted = false
+            log.Printf("[stt] skipping provider final after early promotion session=%s text=%q", s.id, e.Text)
+            metricUtteranceEvents.WithLabelValues("early_final_superseded").Inc()
+            return
+        }
+        // A fallback or late final repeating the one just forwarded
+        if s.dup.duplicate(e.Text, s.clock.Now()) {
+            log.Printf("[stt] skipping near-duplicate final session=%s text=%q", s.id, e.Text)
+            metricUtteranceEvents.WithLabelValues("fuzzy_duplicate_final").Inc()
+            return
+        }
+        // If we already emitted a final for the current utterance, decide if this is a new utterance.
+        if s.finalEmitted {
+            // If exact duplicate of last final, drop as duplicate.
+            if s.lastFinalText == e.Text {
+                log.Printf("[stt] skipping duplicate final session=%s (same text)", s.id)
+                return
             }
-            log.Printf("[stt] FORWARDING final to gateway session=%s text=%q utterance=%s", s.id, e.Text, s.utterID)
-            s.events <- &pb.ServerMessage{Msg: &pb.ServerMessage_Final{Final: &pb.TranscriptFinal{SessionId: s.id, UtteranceId: s.utterID, Text: e.Text}}}
-            s.finalEmitted = true
-            s.lastFinalText = e.Text
-        case "error":
-            log.Printf("[stt] error session=%s msg=%s", s.id, e.Text)
-            s.events <- &pb.ServerMessage{Msg: &pb.ServerMessage_Error{Error: &pb.Error{SessionId: s.id, EnumCode: pb.ErrorCode_PROVIDER_ERROR, Message: e.Text}}}
-        case "reconnected":
-            // Defensive reset on provider reconnect
-            log.Printf("[stt] provider reconnected; resetting session state session=%s", s.id)
-            s.finalEmitted = false
-            s.lastFinalText = ""
-            s.lastInterim = ""
-            s.seenFirstInterim = false
-            s.startedAt = time.Now()
-            s.inUtterance = false
-            s.lastUtteranceEndAt = time.Now()
-            metricUtteranceEvents.WithLabelValues("guardrail_reset").Inc()
-        case "utterance_end":
-            // Reset gating so subsequent utterances can be transcribed
-            log.Printf("[stt] utterance_end received, resetting gating session=%s (finalEmitted was %v)", s.id, s.finalEmitted)
-            s.finalEmitted = false
-            s.lastInterim = ""
-            s.seenFirstInterim = false
-            s.startedAt = time.Now()
-            s.lastFinalText = ""
-            s.inUtterance = false
-            s.lastUtteranceEndAt = time.Now()
-            metricUtteranceEvents.WithLabelValues("utterance_end").Inc()
-        case "speech_started":
-            // Treat SpeechStarted as a hint only; log/metric, do not segment on it
-            now := time.Now()
-            if !s.lastSpeechStarted.IsZero() && now.Sub(s.lastSpeechStarted) < 250*time.Millisecond {
-                log.Printf("[stt] speech_started ignored (debounced) session=%s", s.id)
-                break
+            // Narrow rollover: require recent boundary or silence gap before creating a new utterance
+            minSil := 700
+            if v := os.Getenv("MIN_SILENCE_FOR_NEW_UTTER_MS"); v != "" { fmt.Sscanf(v, "%d", &minSil) }
+            boundaryOK := !s.lastUtteranceEndAt.IsZero() && now.Sub(s.lastUtteranceEndAt) <= 3*time.Second
+            silenceOK := s.lastInterimAt.IsZero() || now.Sub(s.lastInterimAt) >= time.Duration(minSil)*time.Millisecond
+            if boundaryOK || silenceOK {
+                newID := s.rolloverID(now)
+                log.Printf("[stt] rolling to new utterance for subsequent final; new id=%s session=%s", newID, s.id)
+                s.startUtterance(newID)
+            } else {
+                log.Printf("[stt] skipping subsequent final (no boundary/silence) session=%s", s.id)
+                return
             }
-            s.lastSpeechStarted = now
-            log.Printf("[stt] speech_started hint session=%s", s.id)
-            metricUtteranceEvents.WithLabelValues("speech_started").Inc()
-        case "meta":
-            // ignore or surface in future
         }
+        s.mu.Lock()
+        drainAt, utterID := s.drainAt, s.utterID
+        s.finalEmitted = true
+        s.lastFinalText = e.Text
+        s.mu.Unlock()
+        if !drainAt.IsZero() {
+            ms := time.Since(drainAt).Milliseconds()
+            if ms > 0 { metricFinalLatencyMS.Observe(float64(ms)) }
+        }
+        log.Printf("[stt] FORWARDING final to gateway session=%s text=%q utterance=%s", s.id, e.Text, utterID)
+        s.events <- &pb.ServerMessage{Msg: &pb.ServerMessage_Final{Final: &pb.TranscriptFinal{SessionId: s.id, UtteranceId: utterID, Text: e.Text,
+            WordCount: uint32(e.Words), SpeechMs: uint32(e.Speech.Milliseconds()), Speaker: uint32(e.Speaker), AudioFingerprint: s.print.take()}}}
+        s.dup.note(e.Text, s.clock.Now())
+        s.stable.reset("")
+    case "error":
+        code := e.Code
+        if code == pb.ErrorCode_ERROR_CODE_UNSPECIFIED {
+            code = pb.ErrorCode_PROVIDER_ERROR
+        }
+        log.Printf("[stt] error session=%s code=%s msg=%s", s.id, errorCodeName(code), e.Text)
+        metricErrors.WithLabelValues(errorCodeName(code)).Inc()
+        s.events <- &pb.ServerMessage{Msg: &pb.ServerMessage_Error{Error: &pb.Error{SessionId: s.id, EnumCode: code, Code: errorCodeName(code), Message: e.Text, Fatal: e.Fatal}}}
+    case "reconnected":
+        // Defensive reset on provider reconnect
+        log.Printf("[stt] provider reconnected; resetting session state session=%s", s.id)
+        s.resetProviderState()
+        // Tell the client which socket serves it now
+        s.events <- s.dg.connectedMsg(s.id)
+    case "utterance_end":
+        // Reset gating so subsequent utterances can be transcribed
+        s.mu.Lock()
+        log.Printf("[stt] utterance_end received, resetting gating session=%s (finalEmitted was %v)", s.id, s.finalEmitted)
+        s.finalEmitted = false
+        s.early.promoted = false
+        s.lastInterim = ""
+        s.stable.reset("")
+        s.seenFirstInterim = false
+        s.startedAt = time.Now()
+        s.lastFinalText = ""
+        s.inUtterance = false
+        s.lastUtteranceEndAt = time.Now()
+        s.mu.Unlock()
+        metricUtteranceEvents.WithLabelValues("utterance_end").Inc()
+    case "speech_started":
+        // Treat SpeechStarted as a hint only; log/metric, do not segment on it
+        now := time.Now()
+        if !s.lastSpeechStarted.IsZero() && now.Sub(s.lastSpeechStarted) < 250*time.Millisecond {
+            log.Printf("[stt] speech_started ignored (debounced) session=%s", s.id)
+            break
+        }
+        s.lastSpeechStarted = now
+        log.Printf("[stt] speech_started hint session=%s", s.id)
+        metricUtteranceEvents.WithLabelValues("speech_started").Inc()
+    case "meta":
+        // ignore or surface in future
     }
-    close(s.events)
 }
 
+// resetProviderState forgets the utterance tracking built from the provider
+// socket's events, when a new socket (or provider) takes over. It holds s.mu
+// like startUtterance, which the gRPC handler calls on ControlStart while
+// the run goroutine may be resetting.
+func (s *Session) resetProviderState() {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+    s.finalEmitted = false
+    s.lastFinalText = ""
+    s.lastInterim = ""
+    s.seenFirstInterim = false
+    s.startedAt = time.Now()
+    s.inUtterance = false
+    s.lastUtteranceEndAt = time.Now()
+    s.stable.reset("")
+    metricUtteranceEvents.WithLabelValues("guardrail_reset").Inc()
+}
+
+// StartUtterance begins an utterance under a client-supplied ID (issued by
+// the orchestrator and relayed via ControlStart).
 func (s *Session) StartUtterance(utterID string) {
+    s.mu.Lock()
+    s.baseUtterID = utterID
+    s.rollSeq = 0
+    s.mu.Unlock()
+    s.startUtterance(utterID)
+}
+
+// rolloverID returns the ID for an utterance the session segments on its own.
+// IDs derive from the issued base so downstream can still attribute them;
+// without a base we fall back to the legacy time-based form.
+func (s *Session) rolloverID(now time.Time) string {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+    if s.baseUtterID == "" {
+        return fmt.Sprintf("utt-%d", now.UnixMilli())
+    }
+    s.rollSeq++
+    return fmt.Sprintf("%s.%d", s.baseUtterID, s.rollSeq)
+}
+
+// utterance returns the current utterance ID; StartUtterance sets it from
+// the gRPC handler.
+func (s *Session) utterance() string {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+    return s.utterID
+}
+
+func (s *Session) startUtterance(utterID string) {
     s.mu.Lock()
     s.utterID = utterID
     s.startedAt = time.Now()
@@ -200,31 +360,63 @@ func (s *Session) StartUtterance(utterID string) {
 }
 
 func (s *Session) SendAudio(b []byte) {
+    dg := s.conn()
     s.bytesIn += uint64(len(b))
     s.framesIn++
-    s.lastAct = time.Now()
+    s.mu.Lock()
+    s.lastAct = s.clock.Now()
+    s.mu.Unlock()
+    // The frame travels in a pooled buffer from here (see framepool.go)
+    frame := getFrame()
+    b = s.dsp.ProcessInto(*frame, b)
+    *frame = b
     // Calculate RMS for audio level diagnostics
     rms := calcRMS(b)
+    s.early.noteRMS(rms)
+    s.print.add(rms)
     if s.framesIn == 1 || s.framesIn%50 == 0 {
-        log.Printf("[stt] audio session=%s frame=%d bytes=%d rms=%.0f queueLen=%d", s.id, s.framesIn, len(b), rms, s.dg.QueueLen())
+        log.Printf("[stt] audio session=%s frame=%d bytes=%d rms=%.0f queueLen=%d", s.id, s.framesIn, len(b), rms, dg.QueueLen())
     }
-    // Save first high-RMS audio sample for format verification
-    if s.framesIn <= 500 && rms > 500 {
-        filename := fmt.Sprintf("/tmp/stt_audio_sample_%s_frame%d_rms%.0f.raw", s.id[:8], s.framesIn, rms)
-        _ = os.WriteFile(filename, b, 0644)
-        log.Printf("[stt] saved audio sample: %s", filename)
+    // Save first high-RMS audio sample for format verification, unless the
+    // session opted out of keeping audio
+    if saveSamples && s.framesIn <= 500 && rms > 500 && len(s.id) >= 8 {
+        if s.noArchive.Load() {
+            if !s.archiveRefused {
+                s.archiveRefused = true
+                metricArchiveRefused.Inc()
+                log.Printf("[stt] audio sample not saved session=%s: opted out of audio archiving", s.id)
+            }
+        } else {
+            filename := fmt.Sprintf("/tmp/stt_audio_sample_%s_frame%d_rms%.0f.raw", s.id[:8], s.framesIn, rms)
+            _ = os.WriteFile(filename, b, 0644)
+            log.Printf("[stt] saved audio sample: %s", filename)
+        }
     }
     // drop-latest policy if DG queue is congested
-    ok := s.dg.Send(b)
-    if !ok {
-        metricDrops.Inc()
-        log.Printf("[stt] DROPPED frame=%d rms=%.0f queueLen=%d", s.framesIn, rms, s.dg.QueueLen())
+    reason := dropOversize
+    if maxFrameBytes <= 0 || len(b) <= maxFrameBytes {
+        // Copied before the active connection owns the frame
+        s.sendShadow(b)
+        reason = dg.Send(frame)
+    }
+    if reason != "" {
+        putFrame(frame)
+    }
+    s.noteFrame(reason)
+    if reason != "" {
+        log.Printf("[stt] DROPPED frame=%d reason=%s bytes=%d rms=%.0f queueLen=%d", s.framesIn, reason, len(b), rms, dg.QueueLen())
+    } else {
+        s.recordBilled(len(b))
     }
     metricAudioBytes.Add(float64(len(b)))
     metricFrames.Inc()
-    gaugeQueueDepth.Set(float64(s.dg.QueueLen()))
+    gaugeQueueDepth.Set(float64(dg.QueueLen()))
 }
 
+// saveSamples gates the loud-frame dumps above; STT_SAVE_AUDIO_SAMPLES=false
+// turns them off, and the bench always does.
+var saveSamples = !strings.EqualFold(os.Getenv("STT_SAVE_AUDIO_SAMPLES"), "false")
+
 // calcRMS computes RMS of PCM16 audio
 func calcRMS(b []byte) float64 {
     if len(b) < 2 {
@@ -242,22 +434,35 @@ func calcRMS(b []byte) float64 {
 
 func (s *Session) Drain() {
     // No explicit control for provider; rely on endpointing.
-    s.lastAct = time.Now()
-    s.drainAt = s.lastAct
+    // Drain runs on the gRPC handler, so the utterance fields the run
+    // goroutine also touches are read and set under s.mu.
+    now := s.clock.Now()
+    s.mu.Lock()
+    s.lastAct = now
+    s.drainAt = now
+    var final *pb.ServerMessage
     if strings.EqualFold(s.endpointPolicy, "earliest") && !s.finalEmitted {
         // Emit a synthesized final using last interim text
-        s.events <- &pb.ServerMessage{Msg: &pb.ServerMessage_Final{Final: &pb.TranscriptFinal{SessionId: s.id, UtteranceId: s.utterID, Text: s.lastInterim}}}
+        final = &pb.ServerMessage{Msg: &pb.ServerMessage_Final{Final: &pb.TranscriptFinal{SessionId: s.id, UtteranceId: s.utterID, Text: s.lastInterim, Source: finalSourceDrain, AudioFingerprint: s.print.take()}}}
         s.finalEmitted = true
-        if !s.drainAt.IsZero() {
-            ms := time.Since(s.drainAt).Milliseconds()
-            if ms > 0 { metricFinalLatencyMS.Observe(float64(ms)) }
-        }
+    }
+    s.mu.Unlock()
+    s.shadowDrain(now)
+    if final != nil {
+        s.events <- final
+        ms := time.Since(now).Milliseconds()
+        if ms > 0 { metricFinalLatencyMS.Observe(float64(ms)) }
     }
 }
 
-func (s *Session) Close() { s.cancel() }
+func (s *Session) Close() {
+    s.finishUsage()
+    s.cancel()
+}
 
 // IdleFor returns true if the session has been idle for >= d.
 func (s *Session) IdleFor(d time.Duration) bool {
-    return time.Since(s.lastAct) >= d
+    s.mu.Lock()
+    defer s.mu.Unlock()
+    return s.clock.Since(s.lastAct) >= d
 }
@@ -0,0 +1,331 @@
+package stt
+
+import (
+    "context"
+    "log"
+    "os"
+    "sort"
+    "strconv"
+    "strings"
+    "time"
+)
+
+// shadow.go runs a second provider connection alongside the one serving a
+// session and fails over to it. With STT_SHADOW_MODEL set (default off), every
+// session opens a shadow Deepgram socket for that model, on
+// STT_SHADOW_WS_URL and in STT_SHADOW_LANGUAGE when set (else the primary's
+// DEEPGRAM_WS_URL and DEEPGRAM_LANGUAGE), and sends it the same audio. Only
+// the active connection's transcripts reach the client.
+//
+// The two connections' finals are paired when they land within
+// STT_SHADOW_MATCH_MS (default 3000) of each other and compared: they agree
+// when, ignoring case and punctuation, they are STT_SHADOW_AGREE_SIMILARITY
+// (default 0.8) alike. stt_shadow_finals_total{result} counts agree,
+// disagree, primary_only and shadow_only, and stt_shadow_final_lag_ms{leader}
+// how far the slower final trailed.
+//
+// The active connection is swapped out when, and the standby is healthier:
+//
+//   - errors   it reported STT_FAILOVER_ERRORS (default 3, 0 never) errors
+//     within 60s, and the standby is connected with fewer
+//   - latency  the median of its last STT_FAILOVER_LATENCY_FINALS (default 3)
+//     finals after a Drain exceeded STT_FAILOVER_LATENCY_MS (default 2000,
+//     0 never), and the standby's was under it
+//
+// At most one switch happens per STT_FAILOVER_COOLDOWN_S (default 60). The
+// former primary keeps running as the new shadow, so a session can switch
+// back. A switch is logged, counted in stt_failover_switches_total{reason},
+// and sent to the client as a Connected naming the new model with
+// switch_reason set. The shadow's audio is not included in the session's
+// usage figures.
+
+const shadowErrorWindow = 60 * time.Second
+
+type failoverConfig struct {
+    matchWindow time.Duration
+    agree       float64
+
+    errors        int
+    latency       time.Duration
+    latencyFinals int
+    cooldown      time.Duration
+}
+
+func loadFailoverConfig() failoverConfig {
+    c := failoverConfig{
+        matchWindow:   time.Duration(atoiEnv("STT_SHADOW_MATCH_MS", 3000)) * time.Millisecond,
+        agree:         0.8,
+        errors:        atoiEnv("STT_FAILOVER_ERRORS", 3),
+        latency:       time.Duration(atoiEnv("STT_FAILOVER_LATENCY_MS", 2000)) * time.Millisecond,
+        latencyFinals: max(atoiEnv("STT_FAILOVER_LATENCY_FINALS", 3), 1),
+        cooldown:      time.Duration(atoiEnv("STT_FAILOVER_COOLDOWN_S", 60)) * time.Second,
+    }
+    if v, err := strconv.ParseFloat(os.Getenv("STT_SHADOW_AGREE_SIMILARITY"), 64); err == nil && v > 0 && v <= 1 {
+        c.agree = v
+    }
+    return c
+}
+
+// shadowDGConfig is the shadow connection's config, ok false when
+// STT_SHADOW_MODEL is unset or "off".
+func shadowDGConfig() (DGConfig, bool) {
+    model := strings.TrimSpace(os.Getenv("STT_SHADOW_MODEL"))
+    if model == "" || model == "off" {
+        return DGConfig{}, false
+    }
+    cfg := LoadDGConfigFromEnv()
+    cfg.Model = model
+    if v := os.Getenv("STT_SHADOW_LANGUAGE"); v != "" {
+        cfg.Language = v
+    }
+    if v := os.Getenv("STT_SHADOW_WS_URL"); v != "" {
+        cfg.BaseURL = v
+    }
+    return cfg, true
+}
+
+// shadowState is a session's pair of connections. active and drainAt are
+// guarded by Session.mu; the rest belongs to the run goroutine, which is
+// also the only writer of active.
+type shadowState struct {
+    cfg  failoverConfig
+    legs [2]*sttLeg // legs[0] starts as the primary
+
+    active   int
+    drainAt  time.Time
+    drainSeq int
+
+    lastSwitch time.Time
+}
+
+// sttLeg is one connection and what it has been observed doing.
+type sttLeg struct {
+    dg *DeepgramConn
+
+    errs       []time.Time
+    lat        []time.Duration // finals after a Drain, newest last
+    sampledSeq int             // drainSeq of the last latency sample
+    dup        dupFinalState   // fallback re-emits aren't new finals
+    pending    *legFinal       // last final not yet paired
+}
+
+type legFinal struct {
+    text string
+    at   time.Time
+}
+
+// newShadow starts the shadow connection for a session served by primary,
+// or returns nil when shadowing is off.
+func newShadow(ctx context.Context, primary *DeepgramConn) *shadowState {
+    cfg, ok := shadowDGConfig()
+    if !ok {
+        return nil
+    }
+    sh := &shadowState{cfg: loadFailoverConfig()}
+    sh.legs[0] = &sttLeg{dg: primary}
+    sh.legs[1] = &sttLeg{dg: NewDeepgramConn(ctx, cfg, loadDeepgramKey())}
+    for _, l := range sh.legs {
+        l.dup.load()
+    }
+    return sh
+}
+
+// conn returns the connection serving the session; it changes on failover.
+func (s *Session) conn() *DeepgramConn {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+    return s.dg
+}
+
+// sendShadow copies a frame already sent to the active connection to the
+// standby. A frame the standby can't take is dropped without accounting.
+func (s *Session) sendShadow(b []byte) {
+    if s.shadow == nil {
+        return
+    }
+    s.mu.Lock()
+    standby := s.shadow.legs[1-s.shadow.active].dg
+    s.mu.Unlock()
+    frame := getFrame()
+    *frame = append((*frame)[:0], b...)
+    if standby.Send(frame) != "" {
+        putFrame(frame)
+    }
+}
+
+// shadowDrain starts timing the next final from each connection.
+func (s *Session) shadowDrain(now time.Time) {
+    if s.shadow == nil {
+        return
+    }
+    s.mu.Lock()
+    s.shadow.drainAt = now
+    s.shadow.drainSeq++
+    s.mu.Unlock()
+}
+
+// route handles an event from dg: the active connection's go on to
+// handleEvent, and both are observed for comparison and failover.
+func (s *Session) route(dg *DeepgramConn, e DGEvent) {
+    sh := s.shadow
+    if sh == nil {
+        s.handleEvent(e)
+        return
+    }
+    i := 0
+    if sh.legs[1].dg == dg {
+        i = 1
+    }
+    if i == sh.active {
+        s.handleEvent(e)
+    }
+    now := s.clock.Now()
+    s.observeLeg(i, e, now)
+    s.checkFailover(now)
+}
+
+// observeLeg records an event from legs[i].
+func (s *Session) observeLeg(i int, e DGEvent, now time.Time) {
+    sh := s.shadow
+    l := sh.legs[i]
+    switch e.Type {
+    case "error":
+        l.errs = append(pruneBefore(l.errs, now.Add(-shadowErrorWindow)), now)
+    case "final":
+        if strings.TrimSpace(e.Text) == "" || l.dup.duplicate(e.Text, now) {
+            return
+        }
+        l.dup.note(e.Text, now)
+        s.mu.Lock()
+        drainAt, seq := sh.drainAt, sh.drainSeq
+        s.mu.Unlock()
+        if seq != l.sampledSeq && !drainAt.IsZero() {
+            l.sampledSeq = seq
+            l.lat = append(l.lat, max(now.Sub(drainAt), 0))
+            if len(l.lat) > sh.cfg.latencyFinals {
+                l.lat = l.lat[len(l.lat)-sh.cfg.latencyFinals:]
+            }
+        }
+        s.pairFinal(i, legFinal{text: e.Text, at: now})
+    }
+}
+
+// pairFinal compares f, just received on legs[i], with the other
+// connection's waiting final, or leaves it waiting for one.
+func (s *Session) pairFinal(i int, f legFinal) {
+    sh := s.shadow
+    l, other := sh.legs[i], sh.legs[1-i]
+    sh.expirePending(f.at)
+    if other.pending == nil {
+        if l.pending != nil {
+            metricShadowFinals.WithLabelValues(sh.role(i) + "_only").Inc()
+        }
+        l.pending = &f
+        return
+    }
+    o := *other.pending
+    other.pending = nil
+    sim := textSimilarity(f.text, o.text)
+    result := "disagree"
+    if sim >= sh.cfg.agree {
+        result = "agree"
+    }
+    leader := sh.role(1 - i)
+    lag := f.at.Sub(o.at)
+    metricShadowFinals.WithLabelValues(result).Inc()
+    metricShadowLag.WithLabelValues(leader).Observe(float64(lag.Milliseconds()))
+    log.Printf("[stt] shadow final session=%s result=%s similarity=%.2f leader=%s lag_ms=%d", s.id, result, sim, leader, lag.Milliseconds())
+}
+
+// expirePending counts finals that waited past the match window unpaired.
+func (sh *shadowState) expirePending(now time.Time) {
+    for i, l := range sh.legs {
+        if l.pending != nil && now.Sub(l.pending.at) > sh.cfg.matchWindow {
+            metricShadowFinals.WithLabelValues(sh.role(i) + "_only").Inc()
+            l.pending = nil
+        }
+    }
+}
+
+// role names legs[i] for metrics.
+func (sh *shadowState) role(i int) string {
+    if i == sh.active {
+        return "primary"
+    }
+    return "shadow"
+}
+
+// checkFailover switches to the standby when the active connection is
+// failing and the standby is not.
+func (s *Session) checkFailover(now time.Time) {
+    sh := s.shadow
+    if !sh.lastSwitch.IsZero() && now.Sub(sh.lastSwitch) < sh.cfg.cooldown {
+        return
+    }
+    act, stb := sh.legs[sh.active], sh.legs[1-sh.active]
+    act.errs = pruneBefore(act.errs, now.Add(-shadowErrorWindow))
+    stb.errs = pruneBefore(stb.errs, now.Add(-shadowErrorWindow))
+    reason := ""
+    switch {
+    case sh.cfg.errors > 0 && len(act.errs) >= sh.cfg.errors && len(stb.errs) < len(act.errs) && stb.dg.connected.Load():
+        reason = "errors"
+    case sh.cfg.latency > 0 && len(act.lat) >= sh.cfg.latencyFinals && median(act.lat) > sh.cfg.latency &&
+        len(stb.lat) >= sh.cfg.latencyFinals && median(stb.lat) <= sh.cfg.latency:
+        reason = "latency"
+    }
+    if reason != "" {
+        s.switchProvider(reason, now)
+    }
+}
+
+// switchProvider makes the standby the active connection.
+func (s *Session) switchProvider(reason string, now time.Time) {
+    sh := s.shadow
+    from := sh.legs[sh.active].dg
+    s.mu.Lock()
+    sh.active = 1 - sh.active
+    s.dg = sh.legs[sh.active].dg
+    s.mu.Unlock()
+    sh.lastSwitch = now
+    for _, l := range sh.legs {
+        l.lat, l.pending = nil, nil
+    }
+    metricFailovers.WithLabelValues(reason).Inc()
+    log.Printf("[stt] FAILOVER session=%s reason=%s from=%s to=%s", s.id, reason, from.model, s.dg.model)
+    // The new provider has its own view of the utterance
+    s.resetProviderState()
+    msg := s.dg.connectedMsg(s.id)
+    msg.GetConnected().SwitchReason = reason
+    s.events <- msg
+}
+
+// pruneBefore drops the times before cutoff from ts, in place.
+func pruneBefore(ts []time.Time, cutoff time.Time) []time.Time {
+    j := 0
+    for _, t := range ts {
+        if !t.Before(cutoff) {
+            ts[j] = t
+            j++
+        }
+    }
+    return ts[:j]
+}
+
+func median(ds []time.Duration) time.Duration {
+    s := append([]time.Duration(nil), ds...)
+    sort.Slice(s, func(i, j int) bool { return s[i] < s[j] })
+    return s[len(s)/2]
+}
+
+// textSimilarity is 1 minus the edit distance of the normalized texts over
+// the longer one's length; long texts are compared on their first
+// maxDupCompareLen characters.
+func textSimilarity(a, b string) float64 {
+    ra, rb := []rune(normalizeFinal(a)), []rune(normalizeFinal(b))
+    ra, rb = ra[:min(len(ra), maxDupCompareLen)], rb[:min(len(rb), maxDupCompareLen)]
+    longest := max(len(ra), len(rb))
+    if longest == 0 {
+        return 1
+    }
+    return 1 - float64(levenshtein(ra, rb))/float64(longest)
+}
@@ -0,0 +1,191 @@
+package stt
+
+import (
+    "context"
+    "testing"
+    "time"
+
+    "github.com/prometheus/client_golang/prometheus/testutil"
+
+    "yuzu/agent/internal/clock"
+    pb "yuzu/agent/internal/stt/pb"
+)
+
+// shadowSession is an idle session served by primary with shadow in
+// standby; neither connection is started.
+func shadowSession(t *testing.T, clk clock.Clock) (s *Session, primary, shadow *DeepgramConn) {
+    t.Helper()
+    s = idleSession(clk, "s1")
+    s.events = make(chan *pb.ServerMessage, 32)
+    s.dup.load()
+    primary = NewDeepgramConn(context.Background(), DGConfig{Model: "nova-2"}, "")
+    shadow = NewDeepgramConn(context.Background(), DGConfig{Model: "nova-3"}, "")
+    shadow.connected.Store(true)
+    s.dg = primary
+    s.shadow = &shadowState{cfg: loadFailoverConfig()}
+    s.shadow.legs[0], s.shadow.legs[1] = &sttLeg{dg: primary}, &sttLeg{dg: shadow}
+    for _, l := range s.shadow.legs {
+        l.dup.load()
+    }
+    return s, primary, shadow
+}
+
+func drainEvents(s *Session) (out []*pb.ServerMessage) {
+    for len(s.events) > 0 {
+        out = append(out, <-s.events)
+    }
+    return out
+}
+
+func TestShadowComparesFinals(t *testing.T) {
+    clk := clock.NewFake(time.Unix(1700000000, 0))
+    s, primary, shadow := shadowSession(t, clk)
+    agree := testutil.ToFloat64(metricShadowFinals.WithLabelValues("agree"))
+    disagree := testutil.ToFloat64(metricShadowFinals.WithLabelValues("disagree"))
+    shadowOnly := testutil.ToFloat64(metricShadowFinals.WithLabelValues("shadow_only"))
+
+    s.StartUtterance("t1-u")
+    s.route(primary, DGEvent{Type: "final", Text: "I used Postgres for the ledger."})
+    clk.Advance(200 * time.Millisecond)
+    s.route(shadow, DGEvent{Type: "final", Text: "i used postgres for the ledger"})
+    // Only the primary's final reaches the client
+    if got := drainEvents(s); len(got) != 1 || got[0].GetFinal().GetText() != "I used Postgres for the ledger." {
+        t.Fatalf("forwarded %v, want the primary's final", got)
+    }
+    if n := testutil.ToFloat64(metricShadowFinals.WithLabelValues("agree")) - agree; n != 1 {
+        t.Errorf("agree += %v, want 1", n)
+    }
+
+    clk.Advance(5 * time.Second)
+    s.route(shadow, DGEvent{Type: "final", Text: "we sharded by tenant"})
+    clk.Advance(300 * time.Millisecond)
+    s.route(primary, DGEvent{Type: "final", Text: "we started by ten"})
+    if n := testutil.ToFloat64(metricShadowFinals.WithLabelValues("disagree")) - disagree; n != 1 {
+        t.Errorf("disagree += %v, want 1", n)
+    }
+
+    // A shadow final with no partner in the match window
+    clk.Advance(5 * time.Second)
+    s.route(shadow, DGEvent{Type: "final", Text: "and cached the balances"})
+    clk.Advance(5 * time.Second)
+    s.route(primary, DGEvent{Type: "final", Text: "then we moved on"})
+    if n := testutil.ToFloat64(metricShadowFinals.WithLabelValues("shadow_only")) - shadowOnly; n != 1 {
+        t.Errorf("shadow_only += %v, want 1", n)
+    }
+    if s.conn() != primary {
+        t.Fatal("switched without cause")
+    }
+}
+
+func TestFailoverOnErrorsAndBack(t *testing.T) {
+    clk := clock.NewFake(time.Unix(1700000000, 0))
+    s, primary, shadow := shadowSession(t, clk)
+
+    for i := 0; i < 3; i++ {
+        clk.Advance(time.Second)
+        s.route(primary, DGEvent{Type: "error", Text: "socket closed", Code: pb.ErrorCode_SOCKET_CLOSED})
+    }
+    if s.conn() != shadow {
+        t.Fatal("still on the primary after 3 errors")
+    }
+    got := drainEvents(s)
+    last := got[len(got)-1].GetConnected()
+    if last.GetModel() != "nova-3" || last.GetSwitchReason() != "errors" {
+        t.Fatalf("switch notice = %v", got[len(got)-1])
+    }
+
+    // The new primary's transcripts are forwarded, the old one's are not
+    s.StartUtterance("t2-u")
+    s.route(primary, DGEvent{Type: "final", Text: "from the old socket"})
+    s.route(shadow, DGEvent{Type: "final", Text: "from the new socket"})
+    if got := drainEvents(s); len(got) != 1 || got[0].GetFinal().GetText() != "from the new socket" {
+        t.Fatalf("forwarded %v after failover", got)
+    }
+
+    // Errors on the new primary wait out the cooldown
+    primary.connected.Store(true)
+    for i := 0; i < 3; i++ {
+        clk.Advance(time.Second)
+        s.route(shadow, DGEvent{Type: "error", Text: "rate limited", Code: pb.ErrorCode_RATE_LIMITED})
+    }
+    if s.conn() != shadow {
+        t.Fatal("switched back inside the cooldown")
+    }
+    clk.Advance(time.Minute)
+    for i := 0; i < 3; i++ {
+        s.route(shadow, DGEvent{Type: "error", Text: "rate limited", Code: pb.ErrorCode_RATE_LIMITED})
+    }
+    if s.conn() != primary {
+        t.Fatal("did not switch back after the cooldown")
+    }
+}
+
+func TestRunFollowsTheActiveProvider(t *testing.T) {
+    clk := clock.NewFake(time.Unix(1700000000, 0))
+    s, primary, shadow := shadowSession(t, clk)
+    done := make(chan struct{})
+    go func() { s.run(); close(done) }()
+
+    for i := 0; i < 3; i++ {
+        primary.Events <- DGEvent{Type: "error", Text: "socket closed", Code: pb.ErrorCode_SOCKET_CLOSED}
+    }
+    for c := (*pb.ServerMessage)(nil); c.GetConnected().GetSwitchReason() != "errors"; {
+        c = <-s.events
+    }
+
+    // The former primary going away doesn't end the session
+    close(primary.Events)
+    s.StartUtterance("t2-u")
+    shadow.Events <- DGEvent{Type: "final", Text: "still here"}
+    for m := range s.events {
+        if m.GetFinal().GetText() == "still here" {
+            break
+        }
+    }
+    select {
+    case <-done:
+        t.Fatal("the old primary's close ended the session")
+    default:
+    }
+
+    // The active one going away does
+    close(shadow.Events)
+    select {
+    case <-done:
+    case <-time.After(2 * time.Second):
+        t.Fatal("session outlived its active provider")
+    }
+}
+
+func TestFailoverOnLatency(t *testing.T) {
+    t.Setenv("STT_FAILOVER_LATENCY_MS", "1500")
+    clk := clock.NewFake(time.Unix(1700000000, 0))
+    s, primary, shadow := shadowSession(t, clk)
+
+    texts := []string{"one answer here", "a second answer", "the third answer"}
+    for i, text := range texts {
+        s.Drain()
+        clk.Advance(400 * time.Millisecond)
+        s.route(shadow, DGEvent{Type: "final", Text: text})
+        clk.Advance(2 * time.Second)
+        if s.conn() != primary {
+            t.Fatalf("switched after %d slow finals", i)
+        }
+        s.route(primary, DGEvent{Type: "final", Text: text})
+        clk.Advance(5 * time.Second)
+    }
+    if s.conn() != shadow {
+        t.Fatal("still on the slow primary")
+    }
+    got := drainEvents(s)
+    if c := got[len(got)-1].GetConnected(); c.GetSwitchReason() != "latency" {
+        t.Fatalf("switch notice = %v", got[len(got)-1])
+    }
+}
+
+func TestShadowOffByDefault(t *testing.T) {
+    t.Setenv("STT_SHADOW_MODEL", "")
+    if sh := newShadow(context.Background(), nil); sh != nil {
+        t.Fatal("shadow started without STT_SHADOW_MODEL")
+    }
+}
@@ -0,0 +1,95 @@
+package stt
+
+import (
+    "os"
+    "strconv"
+    "strings"
+    "unicode"
+)
+
+// stabilize.go steadies interim transcripts for display. Deepgram revises
+// an interim as more audio arrives, so the words on screen jump around.
+// Each interim is compared word by word with the previous one; a word that
+// has held its place for STT_INTERIM_STABLE_HITS interims in a row
+// (default 2, 0 disables) joins the committed prefix, which only ever grows
+// within an utterance. The rest, including the last word, which is often
+// still being heard, is the volatile suffix. Both ride on
+// TranscriptInterim (committed_text, volatile_text) next to the raw text,
+// so captions can draw the prefix as settled and anything starting early
+// work can rely on it. Words are compared ignoring case and punctuation.
+// When the provider later changes a committed word, the prefix is kept and
+// the revision is counted in stt_interim_revisions_total.
+
+// interimStabilizer belongs to the run goroutine.
+type interimStabilizer struct {
+    hits      int      // interims a word must survive; 0 disables
+    utterance string   // utterance the state belongs to
+    committed []string // committed words, in the form first seen
+    prev      []string // words of the previous interim
+    held      []int    // per word of prev: interims it has held its place
+}
+
+func (z *interimStabilizer) load() {
+    z.hits = 2
+    if v, err := strconv.Atoi(os.Getenv("STT_INTERIM_STABLE_HITS")); err == nil && v >= 0 {
+        z.hits = v
+    }
+}
+
+// reset starts over for utterance.
+func (z *interimStabilizer) reset(utterance string) {
+    z.utterance, z.committed, z.prev, z.held = utterance, nil, nil, nil
+}
+
+// update takes the next interim of utterance and returns its committed
+// prefix and volatile suffix; both are empty when disabled.
+func (z *interimStabilizer) update(utterance, text string) (committed, volatile string) {
+    if z.hits <= 0 {
+        return "", ""
+    }
+    if utterance != z.utterance {
+        z.reset(utterance)
+    }
+    words := strings.Fields(text)
+    held := make([]int, len(words))
+    for i, w := range words {
+        held[i] = 1
+        if i < len(z.prev) && sameWord(z.prev[i], w) && (i == 0 || held[i-1] > 1) {
+            held[i] = z.held[i] + 1
+        }
+    }
+    z.prev, z.held = words, held
+
+    n := len(z.committed)
+    if !wordsMatch(z.committed, words) {
+        // The provider changed its mind about a committed word; the prefix
+        // stays, and what follows it is still volatile
+        metricInterimRevisions.Inc()
+        return strings.Join(z.committed, " "), strings.Join(words[min(n, len(words)):], " ")
+    }
+    for n < len(words)-1 && held[n] >= z.hits {
+        z.committed = append(z.committed, words[n])
+        n++
+    }
+    return strings.Join(z.committed, " "), strings.Join(words[n:], " ")
+}
+
+// wordsMatch reports whether words starts with prefix.
+func wordsMatch(prefix, words []string) bool {
+    if len(words) < len(prefix) {
+        return false
+    }
+    for i, w := range prefix {
+        if !sameWord(w, words[i]) {
+            return false
+        }
+    }
+    return true
+}
+
+// sameWord compares words ignoring case and punctuation, which smart
+// formatting adds and removes as the sentence grows.
+func sameWord(a, b string) bool {
+    trim := func(r rune) bool { return unicode.IsPunct(r) }
+    return strings.EqualFold(strings.TrimFunc(a, trim), strings.TrimFunc(b, trim))
+}
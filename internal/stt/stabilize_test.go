@@ -0,0 +1,61 @@
+package stt
+
+import (
+    "testing"
+    "time"
+
+    "yuzu/agent/internal/clock"
+    pb "yuzu/agent/internal/stt/pb"
+)
+
+func TestStabilizerCommitsMonotonicPrefix(t *testing.T) {
+    z := interimStabilizer{hits: 2}
+    steps := []struct{ text, committed, volatile string }{
+        {"I worked", "", "I worked"},
+        {"I worked on", "I worked", "on"},
+        {"I worked on payments", "I worked on", "payments"},
+        // Smart formatting punctuates; the committed form is kept
+        {"I worked, on payments at Stripe", "I worked on payments", "at Stripe"},
+        // The provider rewrites a committed word: the prefix holds
+        {"I walked on payments at Stripe", "I worked on payments", "at Stripe"},
+    }
+    for i, st := range steps {
+        c, v := z.update("u1", st.text)
+        if c != st.committed || v != st.volatile {
+            t.Fatalf("step %d %q: committed=%q volatile=%q, want %q / %q", i, st.text, c, v, st.committed, st.volatile)
+        }
+    }
+    // A new utterance starts empty
+    if c, v := z.update("u2", "Next"); c != "" || v != "Next" {
+        t.Fatalf("new utterance: committed=%q volatile=%q", c, v)
+    }
+}
+
+func TestStabilizerLastWordStaysVolatile(t *testing.T) {
+    z := interimStabilizer{hits: 2}
+    if c, v := z.update("u1", "hello there"); c != "" || v != "hello there" {
+        t.Fatalf("first interim: committed=%q volatile=%q", c, v)
+    }
+    for i := 0; i < 3; i++ {
+        if c, v := z.update("u1", "hello there"); c != "hello" || v != "there" {
+            t.Fatalf("repeat %d: committed=%q volatile=%q", i, c, v)
+        }
+    }
+    if c, v := (&interimStabilizer{}).update("u1", "hello there"); c != "" || v != "" {
+        t.Fatalf("disabled: committed=%q volatile=%q, want both empty", c, v)
+    }
+}
+
+func TestInterimCarriesCommittedText(t *testing.T) {
+    s := idleSession(clock.NewFake(time.Unix(1700000000, 0)), "s1")
+    s.events = make(chan *pb.ServerMessage, 8)
+    s.stable.hits = 1
+    s.utterID, s.inUtterance = "t1-u", true
+    s.handleEvent(DGEvent{Type: "interim", Text: "tell me"})
+    s.handleEvent(DGEvent{Type: "interim", Text: "tell me about"})
+    <-s.events
+    in := (<-s.events).GetInterim()
+    if in.GetText() != "tell me about" || in.GetCommittedText() != "tell me" || in.GetVolatileText() != "about" {
+        t.Fatalf("interim = %v", in)
+    }
+}
@@ -0,0 +1,67 @@
+package stt
+
+import (
+    "log"
+    "os"
+    "strconv"
+    "strings"
+)
+
+// usage.go tracks how much audio each session streams to Deepgram and what
+// it is likely to cost. Only frames the provider queue accepted count;
+// dropped frames were never billed. The rate is per minute of streamed
+// audio, set with STT_COST_PER_MINUTE_USD (default: nova-2 streaming list
+// price). Totals are exported as counters; each session's figures travel on
+// the Metrics messages and are logged and observed when it ends.
+
+const (
+    bytesPerAudioSecond    = dspSampleRate * 2 // PCM16 mono
+    defaultCostPerMinuteUS = 0.0059
+)
+
+var costPerMinuteUSD = readCostPerMinute()
+
+func readCostPerMinute() float64 {
+    v := strings.TrimSpace(os.Getenv("STT_COST_PER_MINUTE_USD"))
+    if v == "" { return defaultCostPerMinuteUS }
+    f, err := strconv.ParseFloat(v, 64)
+    if err != nil || f < 0 {
+        log.Printf("[stt] invalid STT_COST_PER_MINUTE_USD=%q, using %.4f", v, defaultCostPerMinuteUS)
+        return defaultCostPerMinuteUS
+    }
+    return f
+}
+
+// audioCost estimates the spend for seconds of streamed audio.
+func audioCost(seconds float64) float64 { return seconds / 60 * costPerMinuteUSD }
+
+// recordBilled counts n bytes accepted for the provider.
+func (s *Session) recordBilled(n int) {
+    sec := float64(n) / bytesPerAudioSecond
+    s.mu.Lock()
+    s.billedBytes += uint64(n)
+    s.mu.Unlock()
+    metricAudioSeconds.Add(sec)
+    metricSpendUSD.Add(audioCost(sec))
+}
+
+// Usage returns the audio seconds streamed so far and their estimated cost.
+func (s *Session) Usage() (seconds, costUSD float64) {
+    s.mu.Lock()
+    seconds = float64(s.billedBytes) / bytesPerAudioSecond
+    s.mu.Unlock()
+    return seconds, audioCost(seconds)
+}
+
+// finishUsage observes the session's totals once, when it ends.
+func (s *Session) finishUsage() {
+    s.mu.Lock()
+    done := s.usageDone
+    s.usageDone = true
+    s.mu.Unlock()
+    if done { return }
+    sec, cost := s.Usage()
+    metricSessionAudioSeconds.Observe(sec)
+    metricSessionSpendUSD.Observe(cost)
+    log.Printf("[stt] session usage session=%s audio_s=%.1f est_cost_usd=%.4f", s.id, sec, cost)
+}
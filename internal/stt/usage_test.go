@@ -0,0 +1,35 @@
+package stt
+
+import (
+    "math"
+    "testing"
+    "time"
+
+    "yuzu/agent/internal/clock"
+)
+
+func TestSessionUsage(t *testing.T) {
+    sess := idleSession(clock.NewFake(time.Unix(1700000000, 0)), "s1")
+    // 90 seconds of audio in 20ms frames
+    for i := 0; i < 4500; i++ {
+        sess.recordBilled(640)
+    }
+    sec, cost := sess.Usage()
+    if math.Abs(sec-90) > 1e-9 {
+        t.Errorf("audio seconds = %v, want 90", sec)
+    }
+    if want := 1.5 * costPerMinuteUSD; math.Abs(cost-want) > 1e-12 {
+        t.Errorf("cost = %v, want %v", cost, want)
+    }
+
+    m := usageMetrics(sess, "s1", 4500*640, 4500, true).GetMetrics()
+    if !m.GetFinal() || m.GetAudioSeconds() != sec || m.GetEstimatedCostUsd() != cost {
+        t.Errorf("final metrics = %v", m)
+    }
+
+    sess.Close()
+    sess.Close() // reaper and stream may both close; usage is observed once
+    if !sess.usageDone {
+        t.Error("usage not finalized on close")
+    }
+}
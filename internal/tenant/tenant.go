@@ -0,0 +1,177 @@
+// Package tenant lets one deployment serve several customers. Each tenant
+// has its own API keys and may override the voice, response style (including
+// the system prompt), Daily room prefix and concurrent session quota.
+//
+// Without a tenants file the registry runs single-tenant: every request
+// belongs to the default tenant and no API key is required, which keeps
+// existing deployments and scripts working unchanged.
+package tenant
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"regexp"
+	"strings"
+
+	"yuzu/agent/internal/errdefs"
+	"yuzu/agent/internal/types"
+)
+
+// DefaultID is the tenant of single-tenant deployments.
+const DefaultID = "default"
+
+// Tenant is one customer's configuration. Empty overrides fall back to the
+// deployment config.
+type Tenant struct {
+	ID      string   `json:"id"`
+	APIKeys []string `json:"api_keys"`
+
+	// RoomPrefix replaces DAILY_ROOM_PREFIX; when empty the tenant ID is
+	// appended to the deployment prefix so room names never collide.
+	RoomPrefix string `json:"room_prefix,omitempty"`
+	// VoiceID replaces ELEVENLABS_VOICE_ID for the tenant's bots.
+	VoiceID string `json:"voice_id,omitempty"`
+	// Style provides the tenant's response defaults, including an optional
+	// system prompt override.
+	Style types.SessionStyle `json:"style,omitempty"`
+	// MaxActiveSessions caps sessions with a running bot; 0 is unlimited.
+	MaxActiveSessions int `json:"max_active_sessions,omitempty"`
+}
+
+// Registry resolves requests to tenants.
+type Registry struct {
+	tenants map[string]*Tenant
+	byKey   map[[sha256.Size]byte]*Tenant
+}
+
+var idPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{0,31}$`)
+
+// Single returns a single-tenant registry.
+func Single() *Registry {
+	return &Registry{tenants: map[string]*Tenant{DefaultID: {ID: DefaultID}}}
+}
+
+// NewRegistry validates ts and indexes them by ID and API key.
+func NewRegistry(ts []Tenant) (*Registry, error) {
+	if len(ts) == 0 {
+		return Single(), nil
+	}
+	r := &Registry{tenants: make(map[string]*Tenant), byKey: make(map[[sha256.Size]byte]*Tenant)}
+	for i := range ts {
+		t := &ts[i]
+		if !idPattern.MatchString(t.ID) {
+			return nil, fmt.Errorf("tenant %q: id must match %s", t.ID, idPattern)
+		}
+		if _, dup := r.tenants[t.ID]; dup {
+			return nil, fmt.Errorf("tenant %q: duplicate id", t.ID)
+		}
+		if len(t.APIKeys) == 0 {
+			return nil, fmt.Errorf("tenant %q: no api_keys", t.ID)
+		}
+		if err := t.Style.Validate(); err != nil {
+			return nil, fmt.Errorf("tenant %q: style: %w", t.ID, err)
+		}
+		if t.MaxActiveSessions < 0 {
+			return nil, fmt.Errorf("tenant %q: max_active_sessions must be >= 0", t.ID)
+		}
+		for _, k := range t.APIKeys {
+			if len(k) < 16 {
+				return nil, fmt.Errorf("tenant %q: api key shorter than 16 characters", t.ID)
+			}
+			h := sha256.Sum256([]byte(k))
+			if other, dup := r.byKey[h]; dup {
+				return nil, fmt.Errorf("tenant %q: api key already used by %q", t.ID, other.ID)
+			}
+			r.byKey[h] = t
+		}
+		r.tenants[t.ID] = t
+	}
+	return r, nil
+}
+
+// Load reads a tenants file ({"tenants": [...]}). An empty path yields a
+// single-tenant registry.
+func Load(path string) (*Registry, error) {
+	if path == "" {
+		return Single(), nil
+	}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, &errdefs.ConfigError{Key: "TENANTS_FILE", Msg: err.Error()}
+	}
+	var f struct {
+		Tenants []Tenant `json:"tenants"`
+	}
+	if err := json.Unmarshal(b, &f); err != nil {
+		return nil, &errdefs.ConfigError{Key: "TENANTS_FILE", Msg: "invalid JSON: " + err.Error()}
+	}
+	r, err := NewRegistry(f.Tenants)
+	if err != nil {
+		return nil, &errdefs.ConfigError{Key: "TENANTS_FILE", Msg: err.Error()}
+	}
+	return r, nil
+}
+
+// MultiTenant reports whether requests must carry an API key.
+func (r *Registry) MultiTenant() bool { return r.byKey != nil }
+
+// Get returns the tenant with id, or nil.
+func (r *Registry) Get(id string) *Tenant { return r.tenants[id] }
+
+// Authenticate resolves the tenant for req from its API key, sent as
+// "Authorization: Bearer <key>" or "X-API-Key". Keys are compared by hash so
+// lookup time does not depend on how much of a key matches.
+func (r *Registry) Authenticate(req *http.Request) (*Tenant, error) {
+	if !r.MultiTenant() {
+		return r.tenants[DefaultID], nil
+	}
+	key := APIKey(req)
+	if key == "" {
+		return nil, &errdefs.AuthError{Reason: "missing api key"}
+	}
+	t := r.byKey[sha256.Sum256([]byte(key))]
+	if t == nil {
+		return nil, &errdefs.AuthError{Reason: "unknown api key"}
+	}
+	return t, nil
+}
+
+// APIKey extracts the caller's API key from req.
+func APIKey(req *http.Request) string {
+	if k := strings.TrimSpace(req.Header.Get("X-API-Key")); k != "" {
+		return k
+	}
+	if a := req.Header.Get("Authorization"); len(a) > 7 && strings.EqualFold(a[:7], "bearer ") {
+		return strings.TrimSpace(a[7:])
+	}
+	return ""
+}
+
+// RoomName returns the Daily room name for a session of t, given the
+// deployment's DAILY_ROOM_PREFIX.
+func (t *Tenant) RoomName(deploymentPrefix, sessionID string) string {
+	switch {
+	case t.RoomPrefix != "":
+		return t.RoomPrefix + sessionID
+	case t.ID == DefaultID:
+		return deploymentPrefix + sessionID
+	}
+	return deploymentPrefix + t.ID + "-" + sessionID
+}
+
+type ctxKey struct{}
+
+// WithContext returns ctx carrying t.
+func WithContext(ctx context.Context, t *Tenant) context.Context {
+	return context.WithValue(ctx, ctxKey{}, t)
+}
+
+// FromContext returns the tenant stored by WithContext, or nil.
+func FromContext(ctx context.Context) *Tenant {
+	t, _ := ctx.Value(ctxKey{}).(*Tenant)
+	return t
+}
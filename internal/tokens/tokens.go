@@ -0,0 +1,228 @@
+// Package tokens estimates how many tokens a prompt costs, so requests can
+// be sized before they reach the model. It splits text the way tiktoken's
+// cl100k_base pre-tokenizer does (words with their leading space, runs of
+// up to three digits, punctuation runs, newlines) and prices each piece by
+// its length instead of running the BPE merges, which would mean shipping
+// the 100k-entry vocabulary. Common English words are one token, as in
+// cl100k; long or rare words and non-Latin scripts are priced on the high
+// side, so an estimate that fits a budget fits it for real.
+package tokens
+
+import (
+	"unicode"
+	"unicode/utf8"
+)
+
+// Chat message overhead in the OpenAI format: every message is wrapped in
+// PerMessage tokens and the reply is primed with ReplyPriming more.
+const (
+	PerMessage   = 3
+	ReplyPriming = 3
+)
+
+// Message is one chat message to be counted.
+type Message struct {
+	Role    string
+	Content string
+}
+
+// Count estimates the tokens in s.
+func Count(s string) int {
+	n := 0
+	split(s, func(p string) bool {
+		n += cost(p)
+		return true
+	})
+	return n
+}
+
+// Chat estimates the prompt tokens of a chat request with msgs.
+func Chat(msgs ...Message) int {
+	n := ReplyPriming
+	for _, m := range msgs {
+		n += PerMessage + Count(m.Role) + Count(m.Content)
+	}
+	return n
+}
+
+// Truncate returns the longest prefix of s that fits in max tokens.
+func Truncate(s string, max int) string {
+	n, end := 0, 0
+	split(s, func(p string) bool {
+		if n += cost(p); n > max {
+			return false
+		}
+		end += len(p)
+		return true
+	})
+	return s[:end]
+}
+
+// TruncateStart returns the longest suffix of s that fits in max tokens.
+func TruncateStart(s string, max int) string {
+	var ends []int // piece boundaries
+	var costs []int
+	end := 0
+	split(s, func(p string) bool {
+		end += len(p)
+		ends = append(ends, end)
+		costs = append(costs, cost(p))
+		return true
+	})
+	n, start := 0, len(s)
+	for i := len(costs) - 1; i >= 0; i-- {
+		if n += costs[i]; n > max {
+			break
+		}
+		start = 0
+		if i > 0 {
+			start = ends[i-1]
+		}
+	}
+	return s[start:]
+}
+
+// split calls yield with the pieces of s in order until it returns false.
+func split(s string, yield func(string) bool) {
+	for i := 0; i < len(s); {
+		j := i + pieceLen(s[i:])
+		if !yield(s[i:j]) {
+			return
+		}
+		i = j
+	}
+}
+
+// pieceLen returns the byte length of the piece s starts with, following
+// cl100k's pattern:
+//
+//	's|'t|'re|'ve|'m|'ll|'d | [^\r\n\p{L}\p{N}]?\p{L}+ | \p{N}{1,3} |
+//	 ?[^\s\p{L}\p{N}]+[\r\n]* | \s*[\r\n]+ | \s+(?!\S) | \s+
+func pieceLen(s string) int {
+	r, n := utf8.DecodeRuneInString(s)
+	if r == '\'' {
+		if k := contraction(s[n:]); k > 0 {
+			return n + k
+		}
+	}
+	if unicode.IsLetter(r) {
+		return n + letters(s[n:])
+	}
+	if !isNewline(r) && !unicode.IsNumber(r) {
+		// A leading space or symbol joins the word after it
+		if k := letters(s[n:]); k > 0 {
+			return n + k
+		}
+	}
+	if unicode.IsNumber(r) {
+		for i := 1; i < 3 && n < len(s); i++ {
+			r2, k := utf8.DecodeRuneInString(s[n:])
+			if !unicode.IsNumber(r2) {
+				break
+			}
+			n += k
+		}
+		return n
+	}
+	if isPunct(r) || (r == ' ' && n < len(s) && startsPunct(s[n:])) {
+		for n < len(s) {
+			r2, k := utf8.DecodeRuneInString(s[n:])
+			if !isPunct(r2) {
+				break
+			}
+			n += k
+		}
+		for n < len(s) && (s[n] == '\r' || s[n] == '\n') {
+			n++
+		}
+		return n
+	}
+	// Whitespace: through its last newline, else all but the space that
+	// leads the next word
+	end, lastNL := 0, -1
+	for end < len(s) {
+		r2, k := utf8.DecodeRuneInString(s[end:])
+		if !unicode.IsSpace(r2) {
+			break
+		}
+		if isNewline(r2) {
+			lastNL = end + k
+		}
+		end += k
+	}
+	if lastNL > 0 {
+		return lastNL
+	}
+	if end < len(s) && end > n {
+		_, k := utf8.DecodeLastRuneInString(s[:end])
+		return end - k
+	}
+	return end
+}
+
+// cost prices one piece. A word costs a token per six ASCII letters and
+// one per other letter; a punctuation run one per two characters; digits
+// and whitespace one.
+func cost(p string) int {
+	var ascii, other, punct int
+	for _, r := range p {
+		switch {
+		case unicode.IsLetter(r) && r < utf8.RuneSelf:
+			ascii++
+		case unicode.IsLetter(r):
+			other++
+		case isPunct(r):
+			punct++
+		}
+	}
+	switch {
+	case ascii+other > 0:
+		return (ascii+5)/6 + other
+	case punct > 0:
+		return (punct + 1) / 2
+	default:
+		return 1
+	}
+}
+
+func contraction(s string) int {
+	for _, c := range []string{"s", "t", "re", "ve", "m", "ll", "d"} {
+		if len(s) >= len(c) && equalFoldASCII(s[:len(c)], c) {
+			return len(c)
+		}
+	}
+	return 0
+}
+
+func equalFoldASCII(a, b string) bool {
+	for i := 0; i < len(a); i++ {
+		if a[i]|0x20 != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// letters returns the byte length of the run of letters s starts with.
+func letters(s string) int {
+	n := 0
+	for n < len(s) {
+		r, k := utf8.DecodeRuneInString(s[n:])
+		if !unicode.IsLetter(r) {
+			break
+		}
+		n += k
+	}
+	return n
+}
+
+func isNewline(r rune) bool { return r == '\r' || r == '\n' }
+
+func isPunct(r rune) bool {
+	return !unicode.IsSpace(r) && !unicode.IsLetter(r) && !unicode.IsNumber(r)
+}
+
+func startsPunct(s string) bool {
+	r, _ := utf8.DecodeRuneInString(s)
+	return isPunct(r)
+}
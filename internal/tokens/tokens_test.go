@@ -0,0 +1,90 @@
+package tokens
+
+import (
+	"strings"
+	"testing"
+)
+
+func pieces(s string) []string {
+	var out []string
+	split(s, func(p string) bool {
+		out = append(out, p)
+		return true
+	})
+	return out
+}
+
+func TestSplitLikeCL100K(t *testing.T) {
+	cases := []struct {
+		in   string
+		want []string
+	}{
+		{"Hello, world!", []string{"Hello", ",", " world", "!"}},
+		{"I'm here  now", []string{"I", "'m", " here", " ", " now"}},
+		{"call 5551234567", []string{"call", " ", "555", "123", "456", "7"}},
+		{"end.\n\nNext", []string{"end", ".\n\n", "Next"}},
+		{"a \n b", []string{"a", " \n", " b"}},
+		{"(see ...)", []string{"(see", " ...)"}},
+	}
+	for _, c := range cases {
+		if got := pieces(c.in); strings.Join(got, "|") != strings.Join(c.want, "|") {
+			t.Errorf("split(%q) = %q, want %q", c.in, got, c.want)
+		}
+	}
+}
+
+func TestCount(t *testing.T) {
+	cases := []struct {
+		in   string
+		want int
+	}{
+		{"", 0},
+		{"Hello, world!", 4},
+		{"Tell me about the migration you led.", 9}, // cl100k: 8, " migration" is one token
+		{"2024", 2},
+		{"こんにちは", 5},
+	}
+	for _, c := range cases {
+		if got := Count(c.in); got != c.want {
+			t.Errorf("Count(%q) = %d, want %d", c.in, got, c.want)
+		}
+	}
+	// Nothing is lost or repeated between pieces
+	s := "Résumé: 12 years @ Acme, Inc.\r\n\tLed the team's migration!!"
+	if got := strings.Join(pieces(s), ""); got != s {
+		t.Errorf("pieces rejoin to %q", got)
+	}
+}
+
+func TestChat(t *testing.T) {
+	got := Chat(Message{Role: "system", Content: "Be brief."}, Message{Role: "user", Content: "Hi"})
+	// priming + 2 * (wrapper + role) + "Be brief." + "Hi"
+	if want := ReplyPriming + 2*(PerMessage+1) + 3 + 1; got != want {
+		t.Errorf("Chat = %d, want %d", got, want)
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	s := "one two three four five"
+	if got := Truncate(s, 3); got != "one two three" {
+		t.Errorf("Truncate = %q", got)
+	}
+	if got := TruncateStart(s, 2); got != " four five" {
+		t.Errorf("TruncateStart = %q", got)
+	}
+	if Truncate(s, 100) != s || TruncateStart(s, 100) != s {
+		t.Error("a fitting string should come back whole")
+	}
+	if Truncate(s, 0) != "" || TruncateStart(s, 0) != "" {
+		t.Error("a zero budget should leave nothing")
+	}
+	long := strings.Repeat("The candidate described the rollout in detail. ", 200)
+	for _, max := range []int{1, 17, 500} {
+		if n := Count(Truncate(long, max)); n > max {
+			t.Errorf("Truncate(%d) costs %d", max, n)
+		}
+		if n := Count(TruncateStart(long, max)); n > max {
+			t.Errorf("TruncateStart(%d) costs %d", max, n)
+		}
+	}
+}
@@ -0,0 +1,122 @@
+package tts
+
+import (
+    "encoding/binary"
+    "math"
+    "os"
+    "strconv"
+    "strings"
+)
+
+// loudness.go evens out how loud the agent sounds. Voices, and the
+// fallback provider, come back several dB apart, which the candidate hears
+// and which moves the echo that barge-in thresholds are tuned against.
+// With TTS_NORMALIZE=true each sentence's PCM is measured and scaled to
+// TTS_TARGET_DBFS (default -20) before it is streamed. The measure is a
+// gated RMS in the manner of BS.1770 loudness, without the K-weighting:
+// 20ms blocks under -60 dBFS are ignored as silence, then blocks more than
+// 10 dB below the mean of the rest, so pauses don't drag the level down.
+// The gain is capped at ±TTS_MAX_GAIN_DB (default 12), and a peak limiter
+// holds samples under TTS_LIMIT_DBFS (default -1), releasing over 50ms.
+// Applied gains are observed in tts_loudness_gain_db and limited samples
+// counted in tts_limited_samples_total.
+
+const (
+    loudnessBlock      = 48000 / 50 // samples per 20ms block
+    loudnessAbsGateDB  = -60.0
+    loudnessRelGateDB  = -10.0
+    limiterReleaseSecs = 0.05
+)
+
+// loudnessConfig is off in its zero value.
+type loudnessConfig struct {
+    enabled   bool
+    targetDB  float64 // dBFS
+    maxGainDB float64
+    ceilingDB float64 // dBFS
+}
+
+func loudnessConfigFromEnv() loudnessConfig {
+    c := loudnessConfig{
+        enabled:   strings.EqualFold(strings.TrimSpace(os.Getenv("TTS_NORMALIZE")), "true"),
+        targetDB:  -20,
+        maxGainDB: 12,
+        ceilingDB: -1,
+    }
+    if v, err := strconv.ParseFloat(os.Getenv("TTS_TARGET_DBFS"), 64); err == nil && v < 0 { c.targetDB = v }
+    if v, err := strconv.ParseFloat(os.Getenv("TTS_MAX_GAIN_DB"), 64); err == nil && v >= 0 { c.maxGainDB = v }
+    if v, err := strconv.ParseFloat(os.Getenv("TTS_LIMIT_DBFS"), 64); err == nil && v <= 0 { c.ceilingDB = v }
+    return c
+}
+
+// normalize scales pcm (PCM16 mono, 48kHz) in place to the target level
+// and returns the gain applied in dB; silence is left alone.
+func (c loudnessConfig) normalize(pcm []byte) float64 {
+    if !c.enabled {
+        return 0
+    }
+    level, ok := gatedLevel(pcm)
+    if !ok {
+        return 0
+    }
+    gainDB := math.Max(-c.maxGainDB, math.Min(c.maxGainDB, c.targetDB-level))
+    gain := math.Pow(10, gainDB/20)
+    ceiling := 32768 * math.Pow(10, c.ceilingDB/20)
+    release := math.Exp(-1 / (limiterReleaseSecs * 48000))
+    env, limited := 1.0, 0
+    for i := 0; i+1 < len(pcm); i += 2 {
+        x := float64(int16(binary.LittleEndian.Uint16(pcm[i:]))) * gain
+        // Instant attack, exponential release back to unity
+        env = math.Max(math.Abs(x)/ceiling, 1+(env-1)*release)
+        if env > 1 {
+            x /= env
+            limited++
+        }
+        // A 0 dBFS ceiling lets x reach 32768, which would wrap
+        x = math.Max(math.MinInt16, math.Min(math.MaxInt16, math.Round(x)))
+        binary.LittleEndian.PutUint16(pcm[i:], uint16(int16(x)))
+    }
+    ttsLoudnessGainDB.Observe(gainDB)
+    ttsLimitedSamples.Add(float64(limited))
+    return gainDB
+}
+
+// gatedLevel returns the gated RMS level of pcm in dBFS; ok is false when
+// every block is below the absolute gate.
+func gatedLevel(pcm []byte) (float64, bool) {
+    n := len(pcm) / 2
+    var powers []float64 // mean square per block, full scale = 1
+    for start := 0; start < n; start += loudnessBlock {
+        end := min(start+loudnessBlock, n)
+        var sum float64
+        for i := start; i < end; i++ {
+            s := float64(int16(binary.LittleEndian.Uint16(pcm[2*i:]))) / 32768
+            sum += s * s
+        }
+        if p := sum / float64(end-start); toDB(p) >= loudnessAbsGateDB {
+            powers = append(powers, p)
+        }
+    }
+    if len(powers) == 0 {
+        return 0, false
+    }
+    relGate := toDB(mean(powers)) + loudnessRelGateDB
+    var kept []float64
+    for _, p := range powers {
+        if toDB(p) >= relGate {
+            kept = append(kept, p)
+        }
+    }
+    return toDB(mean(kept)), true
+}
+
+// toDB converts a mean square to dBFS.
+func toDB(p float64) float64 { return 10 * math.Log10(math.Max(p, 1e-12)) }
+
+func mean(xs []float64) float64 {
+    var sum float64
+    for _, x := range xs {
+        sum += x
+    }
+    return sum / float64(len(xs))
+}
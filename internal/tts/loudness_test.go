@@ -0,0 +1,83 @@
+package tts
+
+import (
+    "encoding/binary"
+    "math"
+    "testing"
+)
+
+// tone returns secs of a 440Hz sine at amp (full scale 32767), followed by
+// as much silence.
+func tone(amp float64, secs float64) []byte {
+    n := int(secs * 48000)
+    pcm := make([]byte, 4*n)
+    for i := 0; i < n; i++ {
+        s := amp * math.Sin(2*math.Pi*440*float64(i)/48000)
+        binary.LittleEndian.PutUint16(pcm[2*i:], uint16(int16(s)))
+    }
+    return pcm
+}
+
+func peak(pcm []byte) float64 {
+    var p float64
+    for i := 0; i+1 < len(pcm); i += 2 {
+        p = math.Max(p, math.Abs(float64(int16(binary.LittleEndian.Uint16(pcm[i:])))))
+    }
+    return p
+}
+
+func TestNormalizeEvensOutVoices(t *testing.T) {
+    c := loudnessConfig{enabled: true, targetDB: -20, maxGainDB: 12, ceilingDB: -1}
+    quiet, loud := tone(1500, 0.5), tone(12000, 0.5)
+    c.normalize(quiet)
+    c.normalize(loud)
+    // Trailing silence is gated out, so both land on the target
+    for name, pcm := range map[string][]byte{"quiet": quiet, "loud": loud} {
+        if level, _ := gatedLevel(pcm); math.Abs(level-(-20)) > 0.5 {
+            t.Errorf("%s voice at %.1f dBFS, want -20", name, level)
+        }
+    }
+    if _, ok := gatedLevel(make([]byte, 9600)); ok {
+        t.Error("silence should have no level")
+    }
+}
+
+func TestNormalizeLimitsPeaks(t *testing.T) {
+    // -20 dBFS RMS wants a sine peaking at -17 dBFS; a -18 dBFS ceiling
+    // must hold it down
+    c := loudnessConfig{enabled: true, targetDB: -20, maxGainDB: 12, ceilingDB: -18}
+    pcm := tone(3000, 0.2)
+    c.normalize(pcm)
+    if p, max := peak(pcm), 32768*math.Pow(10, -18.0/20); p > max+1 {
+        t.Fatalf("peak %.0f over the %.0f ceiling", p, max)
+    }
+}
+
+func TestNormalizeCapsGainAndOff(t *testing.T) {
+    c := loudnessConfig{enabled: true, targetDB: -20, maxGainDB: 6, ceilingDB: -1}
+    if g := c.normalize(tone(100, 0.2)); g != 6 {
+        t.Fatalf("gain %.1f dB, want capped at 6", g)
+    }
+    pcm := tone(100, 0.2)
+    before := string(pcm)
+    if g := (loudnessConfig{}).normalize(pcm); g != 0 || string(pcm) != before {
+        t.Fatal("disabled normalization should leave audio alone")
+    }
+}
+
+func TestNormalizeClampsAtFullScale(t *testing.T) {
+    // A 0 dBFS ceiling lets the limiter land exactly on 32768; it must not
+    // wrap to -32768
+    c := loudnessConfig{enabled: true, targetDB: -1, maxGainDB: 12, ceilingDB: 0}
+    pcm := tone(20000, 0.2)
+    signs := make([]bool, len(pcm)/2)
+    for i := range signs {
+        signs[i] = int16(binary.LittleEndian.Uint16(pcm[2*i:])) > 0
+    }
+    c.normalize(pcm)
+    for i, pos := range signs {
+        if s := int16(binary.LittleEndian.Uint16(pcm[2*i:])); pos && s < 0 {
+            t.Fatalf("sample %d wrapped to %d", i, s)
+        }
+    }
+}
@@ -11,11 +11,16 @@ var (
         Help: "Total TTS synthesis requests by status",
     }, []string{"status"})
 
-    ttsFirstFrameMS = promauto.NewHistogram(prometheus.HistogramOpts{
+    ttsRetriesTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+        Name: "tts_retries_total",
+        Help: "Provider requests retried after a transient failure, by failure code",
+    }, []string{"code"})
+
+    ttsFirstFrameMS = promauto.NewHistogramVec(prometheus.HistogramOpts{
         Name:    "tts_first_frame_ms",
-        Help:    "Latency from request start to first audio frame sent",
+        Help:    "Latency from request start to first audio frame sent, by voice",
         Buckets: prometheus.ExponentialBuckets(20, 1.6, 10),
-    })
+    }, []string{"voice"})
 
     ttsTotalDurationMS = promauto.NewHistogram(prometheus.HistogramOpts{
         Name:    "tts_total_duration_ms",
@@ -28,5 +33,38 @@ var (
         Help:    "Latency of ElevenLabs API response (first byte)",
         Buckets: prometheus.ExponentialBuckets(20, 1.6, 10),
     })
-)
 
+    // Per-sentence usage (see usage.go)
+    ttsCharacters = promauto.NewCounterVec(prometheus.CounterOpts{
+        Name: "tts_characters_total",
+        Help: "Characters sent to the provider for synthesis, by voice",
+    }, []string{"voice"})
+
+    ttsAudioSeconds = promauto.NewCounterVec(prometheus.CounterOpts{
+        Name: "tts_audio_seconds_total",
+        Help: "Seconds of audio synthesized, by voice",
+    }, []string{"voice"})
+
+    ttsSpendUSD = promauto.NewCounter(prometheus.CounterOpts{
+        Name: "tts_estimated_spend_usd_total",
+        Help: "Estimated provider spend in USD at TTS_COST_PER_1K_CHARS_USD",
+    })
+
+    // Loudness normalization (see loudness.go)
+    ttsLoudnessGainDB = promauto.NewHistogram(prometheus.HistogramOpts{
+        Name:    "tts_loudness_gain_db",
+        Help:    "Gain applied to normalize each sentence's level, in dB",
+        Buckets: prometheus.LinearBuckets(-12, 3, 9),
+    })
+
+    ttsLimitedSamples = promauto.NewCounter(prometheus.CounterOpts{
+        Name: "tts_limited_samples_total",
+        Help: "Samples the peak limiter turned down after normalization",
+    })
+
+    // Intonation hints (see prosody.go)
+    ttsProsodyHints = promauto.NewCounterVec(prometheus.CounterOpts{
+        Name: "tts_prosody_hints_total",
+        Help: "Sentences synthesized with intonation voice settings, by intonation (question, exclamation)",
+    }, []string{"intonation"})
+)
@@ -27,6 +27,8 @@ type StartRequest struct {
 	RequestId     string                 `protobuf:"bytes,2,opt,name=request_id,json=requestId,proto3" json:"request_id,omitempty"`
 	VoiceId       string                 `protobuf:"bytes,3,opt,name=voice_id,json=voiceId,proto3" json:"voice_id,omitempty"` // ElevenLabs voice id
 	Text          string                 `protobuf:"bytes,4,opt,name=text,proto3" json:"text,omitempty"`
+	SpeakingRate  float32                `protobuf:"fixed32,5,opt,name=speaking_rate,json=speakingRate,proto3" json:"speaking_rate,omitempty"` // 0 = provider default
+	Intonation    string                 `protobuf:"bytes,6,opt,name=intonation,proto3" json:"intonation,omitempty"`                           // "question" | "exclamation" | "" (statement); see prosody.go
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -89,6 +91,20 @@ func (x *StartRequest) GetText() string {
 	return ""
 }
 
+func (x *StartRequest) GetSpeakingRate() float32 {
+	if x != nil {
+		return x.SpeakingRate
+	}
+	return 0
+}
+
+func (x *StartRequest) GetIntonation() string {
+	if x != nil {
+		return x.Intonation
+	}
+	return ""
+}
+
 type Cancel struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	RequestId     string                 `protobuf:"bytes,1,opt,name=request_id,json=requestId,proto3" json:"request_id,omitempty"`
@@ -215,9 +231,17 @@ func (*ClientMessage_Start) isClientMessage_Msg() {}
 
 func (*ClientMessage_Cancel) isClientMessage_Msg() {}
 
+// Connected is sent once the provider has accepted the request (after any
+// retries), describing what serves it. Failed requests get only Failed.
 type Connected struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	SessionId     string                 `protobuf:"bytes,1,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
+	Provider      string                 `protobuf:"bytes,2,opt,name=provider,proto3" json:"provider,omitempty"`                     // e.g., elevenlabs
+	Model         string                 `protobuf:"bytes,3,opt,name=model,proto3" json:"model,omitempty"`                           // ELEVENLABS_MODEL_ID; empty for the voice's default
+	Language      string                 `protobuf:"bytes,4,opt,name=language,proto3" json:"language,omitempty"`                     // ELEVENLABS_LANGUAGE_CODE; empty when unset
+	ConnectMs     uint32                 `protobuf:"varint,5,opt,name=connect_ms,json=connectMs,proto3" json:"connect_ms,omitempty"` // time to the provider's response headers
+	RequestId     string                 `protobuf:"bytes,6,opt,name=request_id,json=requestId,proto3" json:"request_id,omitempty"`  // provider's request ID, for support tickets
+	VoiceId       string                 `protobuf:"bytes,7,opt,name=voice_id,json=voiceId,proto3" json:"voice_id,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -259,6 +283,48 @@ func (x *Connected) GetSessionId() string {
 	return ""
 }
 
+func (x *Connected) GetProvider() string {
+	if x != nil {
+		return x.Provider
+	}
+	return ""
+}
+
+func (x *Connected) GetModel() string {
+	if x != nil {
+		return x.Model
+	}
+	return ""
+}
+
+func (x *Connected) GetLanguage() string {
+	if x != nil {
+		return x.Language
+	}
+	return ""
+}
+
+func (x *Connected) GetConnectMs() uint32 {
+	if x != nil {
+		return x.ConnectMs
+	}
+	return 0
+}
+
+func (x *Connected) GetRequestId() string {
+	if x != nil {
+		return x.RequestId
+	}
+	return ""
+}
+
+func (x *Connected) GetVoiceId() string {
+	if x != nil {
+		return x.VoiceId
+	}
+	return ""
+}
+
 type AudioChunk struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Pcm48K        []byte                 `protobuf:"bytes,1,opt,name=pcm48k,proto3" json:"pcm48k,omitempty"`
@@ -355,6 +421,84 @@ func (x *Error) GetMessage() string {
 	return ""
 }
 
+// Failed is sent instead of audio once retries against the provider are
+// exhausted, so the caller can re-route or re-queue the sentence.
+type Failed struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	RequestId     string                 `protobuf:"bytes,1,opt,name=request_id,json=requestId,proto3" json:"request_id,omitempty"`
+	Code          string                 `protobuf:"bytes,2,opt,name=code,proto3" json:"code,omitempty"` // http_5xx | http_429 | network | http
+	Message       string                 `protobuf:"bytes,3,opt,name=message,proto3" json:"message,omitempty"`
+	Attempts      uint32                 `protobuf:"varint,4,opt,name=attempts,proto3" json:"attempts,omitempty"`
+	Retryable     bool                   `protobuf:"varint,5,opt,name=retryable,proto3" json:"retryable,omitempty"` // false for client errors that another try won't fix
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Failed) Reset() {
+	*x = Failed{}
+	mi := &file_tts_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Failed) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Failed) ProtoMessage() {}
+
+func (x *Failed) ProtoReflect() protoreflect.Message {
+	mi := &file_tts_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Failed.ProtoReflect.Descriptor instead.
+func (*Failed) Descriptor() ([]byte, []int) {
+	return file_tts_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *Failed) GetRequestId() string {
+	if x != nil {
+		return x.RequestId
+	}
+	return ""
+}
+
+func (x *Failed) GetCode() string {
+	if x != nil {
+		return x.Code
+	}
+	return ""
+}
+
+func (x *Failed) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *Failed) GetAttempts() uint32 {
+	if x != nil {
+		return x.Attempts
+	}
+	return 0
+}
+
+func (x *Failed) GetRetryable() bool {
+	if x != nil {
+		return x.Retryable
+	}
+	return false
+}
+
 type ServerMessage struct {
 	state protoimpl.MessageState `protogen:"open.v1"`
 	// Types that are valid to be assigned to Msg:
@@ -362,6 +506,7 @@ type ServerMessage struct {
 	//	*ServerMessage_Connected
 	//	*ServerMessage_Audio
 	//	*ServerMessage_Error
+	//	*ServerMessage_Failed
 	Msg           isServerMessage_Msg `protobuf_oneof:"msg"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
@@ -369,7 +514,7 @@ type ServerMessage struct {
 
 func (x *ServerMessage) Reset() {
 	*x = ServerMessage{}
-	mi := &file_tts_proto_msgTypes[6]
+	mi := &file_tts_proto_msgTypes[7]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -381,7 +526,7 @@ func (x *ServerMessage) String() string {
 func (*ServerMessage) ProtoMessage() {}
 
 func (x *ServerMessage) ProtoReflect() protoreflect.Message {
-	mi := &file_tts_proto_msgTypes[6]
+	mi := &file_tts_proto_msgTypes[7]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -394,7 +539,7 @@ func (x *ServerMessage) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ServerMessage.ProtoReflect.Descriptor instead.
 func (*ServerMessage) Descriptor() ([]byte, []int) {
-	return file_tts_proto_rawDescGZIP(), []int{6}
+	return file_tts_proto_rawDescGZIP(), []int{7}
 }
 
 func (x *ServerMessage) GetMsg() isServerMessage_Msg {
@@ -431,6 +576,15 @@ func (x *ServerMessage) GetError() *Error {
 	return nil
 }
 
+func (x *ServerMessage) GetFailed() *Failed {
+	if x != nil {
+		if x, ok := x.Msg.(*ServerMessage_Failed); ok {
+			return x.Failed
+		}
+	}
+	return nil
+}
+
 type isServerMessage_Msg interface {
 	isServerMessage_Msg()
 }
@@ -447,44 +601,70 @@ type ServerMessage_Error struct {
 	Error *Error `protobuf:"bytes,3,opt,name=error,proto3,oneof"`
 }
 
+type ServerMessage_Failed struct {
+	Failed *Failed `protobuf:"bytes,4,opt,name=failed,proto3,oneof"`
+}
+
 func (*ServerMessage_Connected) isServerMessage_Msg() {}
 
 func (*ServerMessage_Audio) isServerMessage_Msg() {}
 
 func (*ServerMessage_Error) isServerMessage_Msg() {}
 
+func (*ServerMessage_Failed) isServerMessage_Msg() {}
+
 var File_tts_proto protoreflect.FileDescriptor
 
 const file_tts_proto_rawDesc = "" +
 	"\n" +
-	"\ttts.proto\x12\x06tts.v1\"{\n" +
+	"\ttts.proto\x12\x06tts.v1\"\xc0\x01\n" +
 	"\fStartRequest\x12\x1d\n" +
 	"\n" +
 	"session_id\x18\x01 \x01(\tR\tsessionId\x12\x1d\n" +
 	"\n" +
 	"request_id\x18\x02 \x01(\tR\trequestId\x12\x19\n" +
 	"\bvoice_id\x18\x03 \x01(\tR\avoiceId\x12\x12\n" +
-	"\x04text\x18\x04 \x01(\tR\x04text\"'\n" +
+	"\x04text\x18\x04 \x01(\tR\x04text\x12#\n" +
+	"\rspeaking_rate\x18\x05 \x01(\x02R\fspeakingRate\x12\x1e\n" +
+	"\n" +
+	"intonation\x18\x06 \x01(\tR\n" +
+	"intonation\"'\n" +
 	"\x06Cancel\x12\x1d\n" +
 	"\n" +
 	"request_id\x18\x01 \x01(\tR\trequestId\"n\n" +
 	"\rClientMessage\x12,\n" +
 	"\x05start\x18\x01 \x01(\v2\x14.tts.v1.StartRequestH\x00R\x05start\x12(\n" +
 	"\x06cancel\x18\x02 \x01(\v2\x0e.tts.v1.CancelH\x00R\x06cancelB\x05\n" +
-	"\x03msg\"*\n" +
+	"\x03msg\"\xd1\x01\n" +
 	"\tConnected\x12\x1d\n" +
 	"\n" +
-	"session_id\x18\x01 \x01(\tR\tsessionId\"$\n" +
+	"session_id\x18\x01 \x01(\tR\tsessionId\x12\x1a\n" +
+	"\bprovider\x18\x02 \x01(\tR\bprovider\x12\x14\n" +
+	"\x05model\x18\x03 \x01(\tR\x05model\x12\x1a\n" +
+	"\blanguage\x18\x04 \x01(\tR\blanguage\x12\x1d\n" +
+	"\n" +
+	"connect_ms\x18\x05 \x01(\rR\tconnectMs\x12\x1d\n" +
+	"\n" +
+	"request_id\x18\x06 \x01(\tR\trequestId\x12\x19\n" +
+	"\bvoice_id\x18\a \x01(\tR\avoiceId\"$\n" +
 	"\n" +
 	"AudioChunk\x12\x16\n" +
 	"\x06pcm48k\x18\x01 \x01(\fR\x06pcm48k\"5\n" +
 	"\x05Error\x12\x12\n" +
 	"\x04code\x18\x01 \x01(\tR\x04code\x12\x18\n" +
-	"\amessage\x18\x02 \x01(\tR\amessage\"\x9c\x01\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\"\x8f\x01\n" +
+	"\x06Failed\x12\x1d\n" +
+	"\n" +
+	"request_id\x18\x01 \x01(\tR\trequestId\x12\x12\n" +
+	"\x04code\x18\x02 \x01(\tR\x04code\x12\x18\n" +
+	"\amessage\x18\x03 \x01(\tR\amessage\x12\x1a\n" +
+	"\battempts\x18\x04 \x01(\rR\battempts\x12\x1c\n" +
+	"\tretryable\x18\x05 \x01(\bR\tretryable\"\xc6\x01\n" +
 	"\rServerMessage\x121\n" +
 	"\tconnected\x18\x01 \x01(\v2\x11.tts.v1.ConnectedH\x00R\tconnected\x12*\n" +
 	"\x05audio\x18\x02 \x01(\v2\x12.tts.v1.AudioChunkH\x00R\x05audio\x12%\n" +
-	"\x05error\x18\x03 \x01(\v2\r.tts.v1.ErrorH\x00R\x05errorB\x05\n" +
+	"\x05error\x18\x03 \x01(\v2\r.tts.v1.ErrorH\x00R\x05error\x12(\n" +
+	"\x06failed\x18\x04 \x01(\v2\x0e.tts.v1.FailedH\x00R\x06failedB\x05\n" +
 	"\x03msg2B\n" +
 	"\x03TTS\x12;\n" +
 	"\aSession\x12\x15.tts.v1.ClientMessage\x1a\x15.tts.v1.ServerMessage(\x010\x01B\"Z yuzu/agent/internal/tts/pb;ttspbb\x06proto3"
@@ -501,7 +681,7 @@ func file_tts_proto_rawDescGZIP() []byte {
 	return file_tts_proto_rawDescData
 }
 
-var file_tts_proto_msgTypes = make([]protoimpl.MessageInfo, 7)
+var file_tts_proto_msgTypes = make([]protoimpl.MessageInfo, 8)
 var file_tts_proto_goTypes = []any{
 	(*StartRequest)(nil),  // 0: tts.v1.StartRequest
 	(*Cancel)(nil),        // 1: tts.v1.Cancel
@@ -509,7 +689,8 @@ var file_tts_proto_goTypes = []any{
 	(*Connected)(nil),     // 3: tts.v1.Connected
 	(*AudioChunk)(nil),    // 4: tts.v1.AudioChunk
 	(*Error)(nil),         // 5: tts.v1.Error
-	(*ServerMessage)(nil), // 6: tts.v1.ServerMessage
+	(*Failed)(nil),        // 6: tts.v1.Failed
+	(*ServerMessage)(nil), // 7: tts.v1.ServerMessage
 }
 var file_tts_proto_depIdxs = []int32{
 	0, // 0: tts.v1.ClientMessage.start:type_name -> tts.v1.StartRequest
@@ -517,13 +698,14 @@ var file_tts_proto_depIdxs = []int32{
 	3, // 2: tts.v1.ServerMessage.connected:type_name -> tts.v1.Connected
 	4, // 3: tts.v1.ServerMessage.audio:type_name -> tts.v1.AudioChunk
 	5, // 4: tts.v1.ServerMessage.error:type_name -> tts.v1.Error
-	2, // 5: tts.v1.TTS.Session:input_type -> tts.v1.ClientMessage
-	6, // 6: tts.v1.TTS.Session:output_type -> tts.v1.ServerMessage
-	6, // [6:7] is the sub-list for method output_type
-	5, // [5:6] is the sub-list for method input_type
-	5, // [5:5] is the sub-list for extension type_name
-	5, // [5:5] is the sub-list for extension extendee
-	0, // [0:5] is the sub-list for field type_name
+	6, // 5: tts.v1.ServerMessage.failed:type_name -> tts.v1.Failed
+	2, // 6: tts.v1.TTS.Session:input_type -> tts.v1.ClientMessage
+	7, // 7: tts.v1.TTS.Session:output_type -> tts.v1.ServerMessage
+	7, // [7:8] is the sub-list for method output_type
+	6, // [6:7] is the sub-list for method input_type
+	6, // [6:6] is the sub-list for extension type_name
+	6, // [6:6] is the sub-list for extension extendee
+	0, // [0:6] is the sub-list for field type_name
 }
 
 func init() { file_tts_proto_init() }
@@ -535,10 +717,11 @@ func file_tts_proto_init() {
 		(*ClientMessage_Start)(nil),
 		(*ClientMessage_Cancel)(nil),
 	}
-	file_tts_proto_msgTypes[6].OneofWrappers = []any{
+	file_tts_proto_msgTypes[7].OneofWrappers = []any{
 		(*ServerMessage_Connected)(nil),
 		(*ServerMessage_Audio)(nil),
 		(*ServerMessage_Error)(nil),
+		(*ServerMessage_Failed)(nil),
 	}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
@@ -546,7 +729,7 @@ func file_tts_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_tts_proto_rawDesc), len(file_tts_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   7,
+			NumMessages:   8,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
@@ -0,0 +1,69 @@
+package tts
+
+import (
+    "log"
+    "os"
+    "strconv"
+    "strings"
+)
+
+// prosody.go turns a sentence's intonation hint (StartRequest.intonation,
+// set by the orchestrator from its closing punctuation) into ElevenLabs
+// voice settings, so questions rise and exclamations carry some energy
+// instead of every sentence landing the same way. TTS_PROSODY_QUESTION
+// (default "stability=0.4") and TTS_PROSODY_EXCLAMATION (default
+// "stability=0.3,style=0.4") list the settings as comma-separated
+// key=value pairs; the keys are stability, similarity_boost and style, each
+// 0 to 1. "off" sends a kind with the voice's own settings, as statements
+// always are. Sentences sent with hints are counted in
+// tts_prosody_hints_total{intonation}.
+
+var prosodyKeys = map[string]bool{"stability": true, "similarity_boost": true, "style": true}
+
+// prosodyHints maps an intonation to the voice settings it adds.
+var prosodyHints = map[string]map[string]float64{
+    "question":    parseProsody("TTS_PROSODY_QUESTION", "stability=0.4"),
+    "exclamation": parseProsody("TTS_PROSODY_EXCLAMATION", "stability=0.3,style=0.4"),
+}
+
+// parseProsody reads the settings in env, or def when unset. Bad pairs are
+// logged and skipped.
+func parseProsody(env, def string) map[string]float64 {
+    v := strings.TrimSpace(os.Getenv(env))
+    if v == "" {
+        v = def
+    }
+    if v == "off" {
+        return nil
+    }
+    out := map[string]float64{}
+    for _, pair := range strings.Split(v, ",") {
+        k, val, _ := strings.Cut(strings.TrimSpace(pair), "=")
+        f, err := strconv.ParseFloat(strings.TrimSpace(val), 64)
+        if !prosodyKeys[k] || err != nil || f < 0 || f > 1 {
+            log.Printf("[tts] %s: ignoring %q", env, pair)
+            continue
+        }
+        out[k] = f
+    }
+    return out
+}
+
+// voiceSettings is the request's voice_settings object, or nil to use the
+// voice's own.
+func voiceSettings(rate float32, intonation string) map[string]any {
+    vs := map[string]any{}
+    if rate > 0 {
+        vs["speed"] = rate
+    }
+    if hints := prosodyHints[intonation]; len(hints) > 0 {
+        for k, v := range hints {
+            vs[k] = v
+        }
+        ttsProsodyHints.WithLabelValues(intonation).Inc()
+    }
+    if len(vs) == 0 {
+        return nil
+    }
+    return vs
+}
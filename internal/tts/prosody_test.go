@@ -0,0 +1,26 @@
+package tts
+
+import "testing"
+
+func TestProsodySettings(t *testing.T) {
+    t.Setenv("TTS_PROSODY_QUESTION", "stability=0.35, style=0.2,pitch=3,similarity_boost=1.5")
+    got := parseProsody("TTS_PROSODY_QUESTION", "stability=0.4")
+    if len(got) != 2 || got["stability"] != 0.35 || got["style"] != 0.2 {
+        t.Fatalf("parsed %v, want stability and style only", got)
+    }
+    t.Setenv("TTS_PROSODY_QUESTION", "off")
+    if got := parseProsody("TTS_PROSODY_QUESTION", "stability=0.4"); got != nil {
+        t.Fatalf("off parsed as %v", got)
+    }
+
+    if vs := voiceSettings(0, ""); vs != nil {
+        t.Errorf("statement at default rate = %v, want the voice's own settings", vs)
+    }
+    vs := voiceSettings(1.1, "exclamation")
+    if vs["speed"] != float32(1.1) || vs["stability"] != 0.3 || vs["style"] != 0.4 {
+        t.Errorf("exclamation at 1.1 = %v", vs)
+    }
+    if vs := voiceSettings(0, "question"); len(vs) != 1 || vs["stability"] != 0.4 {
+        t.Errorf("question = %v", vs)
+    }
+}
@@ -0,0 +1,34 @@
+package tts
+
+import (
+    "context"
+    "io"
+    "net/http"
+    "strings"
+
+    "yuzu/agent/internal/errdefs"
+    "yuzu/agent/internal/secrets"
+)
+
+// CheckProvider verifies ElevenLabs config is present and, when ping is set,
+// that the API accepts our key. Listing models costs no characters; TTS-only
+// keys may lack the permission for it, which still proves the key is valid.
+func CheckProvider(ctx context.Context, ping bool) error {
+    apiKey := secrets.Get("ELEVENLABS_API_KEY")
+    if apiKey == "" {
+        return &errdefs.ConfigError{Key: "ELEVENLABS_API_KEY", Msg: "missing"}
+    }
+    if !ping { return nil }
+    req, err := http.NewRequestWithContext(ctx, http.MethodGet, providerURL()+"/v1/models", nil)
+    if err != nil { return err }
+    req.Header.Set("xi-api-key", apiKey)
+    resp, err := http.DefaultClient.Do(req)
+    if err != nil { return errdefs.ProviderTransport("elevenlabs", err) }
+    defer resp.Body.Close()
+    b, _ := io.ReadAll(io.LimitReader(resp.Body, 256))
+    if resp.StatusCode/100 == 2 { return nil }
+    if resp.StatusCode == http.StatusUnauthorized && strings.Contains(string(b), "missing_permissions") {
+        return nil
+    }
+    return errdefs.ProviderStatus("elevenlabs", resp.StatusCode, strings.TrimSpace(string(b)))
+}
@@ -0,0 +1,64 @@
+package tts
+
+import (
+    "context"
+    "net/http"
+    "os"
+    "strconv"
+    "time"
+)
+
+// retryPolicy bounds how often a synthesis request is retried against the
+// provider. Only failures before any audio was sent are retried, so a retry
+// never replays part of a sentence.
+type retryPolicy struct {
+    max  int           // retries after the first attempt
+    base time.Duration // first backoff, doubled per retry
+    cap  time.Duration // upper bound for backoff and Retry-After
+}
+
+func retryPolicyFromEnv() retryPolicy {
+    p := retryPolicy{max: 2, base: 200 * time.Millisecond, cap: 2 * time.Second}
+    if v, err := strconv.Atoi(os.Getenv("TTS_RETRY_MAX")); err == nil && v >= 0 { p.max = v }
+    if v, err := strconv.Atoi(os.Getenv("TTS_RETRY_BASE_MS")); err == nil && v > 0 { p.base = time.Duration(v) * time.Millisecond }
+    if v, err := strconv.Atoi(os.Getenv("TTS_RETRY_CAP_MS")); err == nil && v > 0 { p.cap = time.Duration(v) * time.Millisecond }
+    return p
+}
+
+// delay returns the wait before retry number attempt (1-based). A
+// Retry-After header in seconds wins over the exponential schedule.
+func (p retryPolicy) delay(attempt int, retryAfter string) time.Duration {
+    d := p.base << (attempt - 1)
+    if s, err := strconv.Atoi(retryAfter); err == nil && s >= 0 {
+        d = time.Duration(s) * time.Second
+    }
+    if d > p.cap || d <= 0 { d = p.cap }
+    return d
+}
+
+// classifyFailure maps a provider response to a Failed code and whether
+// trying again might help. err is a transport error (status is then 0).
+func classifyFailure(status int, err error) (code string, retryable bool) {
+    switch {
+    case err != nil:
+        return "network", true
+    case status == http.StatusTooManyRequests:
+        return "http_429", true
+    case status >= 500:
+        return "http_5xx", true
+    default:
+        return "http", false
+    }
+}
+
+// sleepCtx waits for d or until ctx is done, reporting whether the full wait elapsed.
+func sleepCtx(ctx context.Context, d time.Duration) bool {
+    t := time.NewTimer(d)
+    defer t.Stop()
+    select {
+    case <-ctx.Done():
+        return false
+    case <-t.C:
+        return true
+    }
+}
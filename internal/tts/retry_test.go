@@ -0,0 +1,116 @@
+package tts
+
+import (
+    "context"
+    "net/http"
+    "net/http/httptest"
+    "sync/atomic"
+    "testing"
+    "time"
+
+    "google.golang.org/grpc"
+
+    pb "yuzu/agent/internal/tts/pb"
+)
+
+func TestRetryDelay(t *testing.T) {
+    p := retryPolicy{max: 3, base: 100 * time.Millisecond, cap: time.Second}
+    for _, c := range []struct {
+        attempt    int
+        retryAfter string
+        want       time.Duration
+    }{
+        {1, "", 100 * time.Millisecond},
+        {2, "", 200 * time.Millisecond},
+        {5, "", time.Second},   // capped
+        {1, "0", time.Second},  // zero falls back to the cap rather than hammering
+        {1, "30", time.Second}, // Retry-After is capped too
+        {2, "abc", 200 * time.Millisecond},
+    } {
+        if got := p.delay(c.attempt, c.retryAfter); got != c.want {
+            t.Errorf("delay(%d, %q) = %s, want %s", c.attempt, c.retryAfter, got, c.want)
+        }
+    }
+}
+
+// sessionStream feeds one StartRequest and records what the server sends.
+type sessionStream struct {
+    grpc.ServerStream
+    start *pb.StartRequest
+    sent  []*pb.ServerMessage
+}
+
+func (f *sessionStream) Context() context.Context { return context.Background() }
+func (f *sessionStream) Send(m *pb.ServerMessage) error { f.sent = append(f.sent, m); return nil }
+func (f *sessionStream) Recv() (*pb.ClientMessage, error) {
+    return &pb.ClientMessage{Msg: &pb.ClientMessage_Start{Start: f.start}}, nil
+}
+
+func runSession(t *testing.T, handler http.HandlerFunc) (*sessionStream, int32) {
+    t.Helper()
+    var calls int32
+    ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        atomic.AddInt32(&calls, 1)
+        handler(w, r)
+    }))
+    defer ts.Close()
+    old := elevenLabsURL
+    elevenLabsURL = ts.URL
+    defer func() { elevenLabsURL = old }()
+    t.Setenv("ELEVENLABS_API_KEY", "test")
+
+    s := &Server{retry: retryPolicy{max: 2, base: time.Millisecond, cap: 5 * time.Millisecond}}
+    st := &sessionStream{start: &pb.StartRequest{SessionId: "s1", RequestId: "r1", VoiceId: "v", Text: "hi"}}
+    if err := s.Session(st); err != nil {
+        t.Fatalf("Session: %v", err)
+    }
+    return st, atomic.LoadInt32(&calls)
+}
+
+func TestSessionRetriesThenSucceeds(t *testing.T) {
+    var n int32
+    st, calls := runSession(t, func(w http.ResponseWriter, r *http.Request) {
+        if atomic.AddInt32(&n, 1) == 1 {
+            w.WriteHeader(http.StatusServiceUnavailable)
+            return
+        }
+        w.Header().Set("request-id", "el-2")
+        w.Write(make([]byte, 1920))
+    })
+    if calls != 2 {
+        t.Fatalf("provider calls = %d, want 2", calls)
+    }
+    // Connected describes the attempt that succeeded
+    c := st.sent[0].GetConnected()
+    if c == nil || c.GetProvider() != "elevenlabs" || c.GetVoiceId() != "v" || c.GetRequestId() != "el-2" || c.GetSessionId() != "s1" {
+        t.Fatalf("first message = %v, want connected", st.sent[0])
+    }
+    if last := st.sent[len(st.sent)-1]; last.GetAudio() == nil {
+        t.Fatalf("last message = %v, want audio", last)
+    }
+}
+
+func TestSessionFailedAfterRetries(t *testing.T) {
+    st, calls := runSession(t, func(w http.ResponseWriter, r *http.Request) {
+        w.WriteHeader(http.StatusTooManyRequests)
+    })
+    if calls != 3 {
+        t.Fatalf("provider calls = %d, want 3", calls)
+    }
+    f := st.sent[len(st.sent)-1].GetFailed()
+    if f == nil || f.GetCode() != "http_429" || f.GetAttempts() != 3 || !f.GetRetryable() || f.GetRequestId() != "r1" {
+        t.Fatalf("failed = %v, want http_429 after 3 attempts", f)
+    }
+}
+
+func TestSessionClientErrorNotRetried(t *testing.T) {
+    st, calls := runSession(t, func(w http.ResponseWriter, r *http.Request) {
+        w.WriteHeader(http.StatusBadRequest)
+    })
+    if calls != 1 {
+        t.Fatalf("provider calls = %d, want 1", calls)
+    }
+    if f := st.sent[len(st.sent)-1].GetFailed(); f == nil || f.GetRetryable() {
+        t.Fatalf("failed = %v, want non-retryable", f)
+    }
+}
@@ -2,19 +2,43 @@ package tts
 
 import (
     "bytes"
+    "context"
     "encoding/json"
     "fmt"
     "io"
+    "log"
     "net/http"
     "os"
+    "strings"
     "time"
 
+    "yuzu/agent/internal/chaos"
+    "yuzu/agent/internal/errdefs"
+    "yuzu/agent/internal/secrets"
+    "yuzu/agent/internal/warmup"
     pb "yuzu/agent/internal/tts/pb"
 )
 
-type Server struct{ pb.UnimplementedTTSServer }
+// elevenLabsURL is the provider base URL; tests point it at a local server.
+var elevenLabsURL = "https://api.elevenlabs.io"
 
-func NewServer() *Server { return &Server{} }
+// providerURL lets ELEVENLABS_BASE_URL redirect requests (proxies, fakes).
+func providerURL() string {
+    if v := os.Getenv("ELEVENLABS_BASE_URL"); v != "" { return strings.TrimRight(v, "/") }
+    return elevenLabsURL
+}
+
+type Server struct {
+    pb.UnimplementedTTSServer
+    retry retryPolicy
+    chaos *chaos.Injector // nil unless CHAOS_ENABLED (see internal/chaos)
+    loudness loudnessConfig // per-sentence level normalization (see loudness.go)
+    warm *warmup.Warmer // nil unless TTS_WARMUP (see warm.go)
+}
+
+func NewServer() *Server {
+    return &Server{retry: retryPolicyFromEnv(), chaos: chaos.FromEnv(chaos.TargetTTS), loudness: loudnessConfigFromEnv()}
+}
 
 func (s *Server) Session(stream pb.TTS_SessionServer) error {
     parent := stream.Context()
@@ -25,44 +49,26 @@ func (s *Server) Session(stream pb.TTS_SessionServer) error {
     if err != nil { return err }
     start := msg.GetStart()
     if start == nil { return fmt.Errorf("expected start request") }
-    _ = stream.Send(&pb.ServerMessage{Msg: &pb.ServerMessage_Connected{Connected: &pb.Connected{SessionId: start.GetSessionId()}}})
+    s.warm.Touch()
 
-    apiKey := os.Getenv("ELEVENLABS_API_KEY")
+    apiKey := secrets.Get("ELEVENLABS_API_KEY")
     if apiKey == "" {
         ttsSynthesisTotal.WithLabelValues("config_error").Inc()
-        _ = stream.Send(&pb.ServerMessage{Msg: &pb.ServerMessage_Error{Error: &pb.Error{Code:"config", Message:"missing ELEVENLABS_API_KEY"}}})
+        cerr := &errdefs.ConfigError{Key: "ELEVENLABS_API_KEY", Msg: "missing"}
+        _ = stream.Send(&pb.ServerMessage{Msg: &pb.ServerMessage_Error{Error: &pb.Error{Code: errdefs.Class(cerr), Message: cerr.Error()}}})
         return nil
     }
 
-    // Build request to ElevenLabs (non-streaming REST)
-    // Request PCM 16-bit 48kHz mono format directly
-    url := fmt.Sprintf("https://api.elevenlabs.io/v1/text-to-speech/%s?output_format=pcm_48000", start.GetVoiceId())
-    body := map[string]any{"text": start.GetText()}
-    reqBytes, _ := json.Marshal(body)
-    req, err := http.NewRequestWithContext(parent, http.MethodPost, url, bytes.NewReader(reqBytes))
-    if err != nil {
-        ttsSynthesisTotal.WithLabelValues("request_error").Inc()
-        return err
-    }
-    req.Header.Set("xi-api-key", apiKey)
-    req.Header.Set("accept", "audio/wav")
-    req.Header.Set("content-type", "application/json")
-
-    apiStart := time.Now()
-    resp, err := http.DefaultClient.Do(req)
-    if err != nil {
-        ttsSynthesisTotal.WithLabelValues("http_error").Inc()
-        return err
-    }
-    defer resp.Body.Close()
-    ttsElevenLabsLatencyMS.Observe(float64(time.Since(apiStart).Milliseconds()))
-
-    if resp.StatusCode/100 != 2 {
-        ttsSynthesisTotal.WithLabelValues("api_error").Inc()
-        b,_ := io.ReadAll(io.LimitReader(resp.Body,1024))
-        _ = stream.Send(&pb.ServerMessage{Msg:&pb.ServerMessage_Error{Error:&pb.Error{Code:"http", Message:fmt.Sprintf("status=%d body=%s",resp.StatusCode,string(b))}}})
+    // Retry transient provider failures (5xx/429/network) with backoff. Nothing
+    // has been sent yet, so a retry can't duplicate audio.
+    resp, conn, failed := s.synthesize(parent, apiKey, start)
+    if failed != nil {
+        ttsSynthesisTotal.WithLabelValues("failed").Inc()
+        _ = stream.Send(&pb.ServerMessage{Msg: &pb.ServerMessage_Failed{Failed: failed}})
         return nil
     }
+    defer resp.Body.Close()
+    _ = stream.Send(&pb.ServerMessage{Msg: &pb.ServerMessage_Connected{Connected: conn}})
 
     // Read raw PCM16@48k bytes (ElevenLabs pcm_48000 format is raw 16-bit mono PCM)
     pcm, err := io.ReadAll(resp.Body)
@@ -77,6 +83,11 @@ func (s *Server) Session(stream pb.TTS_SessionServer) error {
         return nil
     }
 
+    s.loudness.normalize(pcm)
+
+    // Billed whether or not the caller hears it all
+    recordSentence(start.GetVoiceId(), start.GetText(), len(pcm))
+
     frameBytes := 48000/50*2 // 20ms * 48000 * 2 bytes
     pos := 0
     firstFrame := true
@@ -90,7 +101,7 @@ func (s *Server) Session(stream pb.TTS_SessionServer) error {
             return nil
         }
         if firstFrame {
-            ttsFirstFrameMS.Observe(float64(time.Since(startTime).Milliseconds()))
+            ttsFirstFrameMS.WithLabelValues(voices.label(start.GetVoiceId())).Observe(float64(time.Since(startTime).Milliseconds()))
             firstFrame = false
         }
         time.Sleep(20*time.Millisecond)
@@ -101,6 +112,83 @@ func (s *Server) Session(stream pb.TTS_SessionServer) error {
     return nil
 }
 
+// synthesize posts the text to ElevenLabs, retrying per s.retry. It returns
+// the successful response and the Connected describing it or, once attempts
+// are exhausted or the failure is not retryable, the Failed message to send
+// back. ELEVENLABS_MODEL_ID and ELEVENLABS_LANGUAGE_CODE, when set, pick the
+// model and language instead of the voice's defaults.
+func (s *Server) synthesize(ctx context.Context, apiKey string, start *pb.StartRequest) (*http.Response, *pb.Connected, *pb.Failed) {
+    // Request PCM 16-bit 48kHz mono format directly
+    url := fmt.Sprintf("%s/v1/text-to-speech/%s?output_format=pcm_48000", providerURL(), start.GetVoiceId())
+    body := map[string]any{"text": start.GetText()}
+    conn := &pb.Connected{SessionId: start.GetSessionId(), Provider: "elevenlabs", VoiceId: start.GetVoiceId(),
+        Model: os.Getenv("ELEVENLABS_MODEL_ID"), Language: os.Getenv("ELEVENLABS_LANGUAGE_CODE")}
+    if conn.Model != "" {
+        body["model_id"] = conn.Model
+    }
+    if conn.Language != "" {
+        body["language_code"] = conn.Language
+    }
+    // Pace mirroring and the sentence's intonation (see prosody.go)
+    if vs := voiceSettings(start.GetSpeakingRate(), start.GetIntonation()); vs != nil {
+        body["voice_settings"] = vs
+    }
+    reqBytes, _ := json.Marshal(body)
+    for attempt := 1; ; attempt++ {
+        req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(reqBytes))
+        if err != nil {
+            ttsSynthesisTotal.WithLabelValues("request_error").Inc()
+            return nil, nil, &pb.Failed{RequestId: start.GetRequestId(), Code: "request", Message: err.Error(), Attempts: uint32(attempt)}
+        }
+        req.Header.Set("xi-api-key", apiKey)
+        req.Header.Set("accept", "audio/wav")
+        req.Header.Set("content-type", "application/json")
+
+        apiStart := time.Now()
+        var resp *http.Response
+        // Injected faults stand in for a hung or failing provider
+        switch s.chaos.Inject(ctx) {
+        case chaos.Drop:
+            <-ctx.Done()
+            err = ctx.Err()
+        case chaos.Error:
+            resp = &http.Response{StatusCode: http.StatusServiceUnavailable, Header: http.Header{}, Body: io.NopCloser(strings.NewReader(chaos.ErrInjected.Error()))}
+        default:
+            resp, err = http.DefaultClient.Do(req)
+        }
+        status, retryAfter, msg := 0, "", ""
+        if err != nil {
+            ttsSynthesisTotal.WithLabelValues("http_error").Inc()
+            msg = err.Error()
+        } else {
+            ttsElevenLabsLatencyMS.Observe(float64(time.Since(apiStart).Milliseconds()))
+            if resp.StatusCode/100 == 2 {
+                conn.ConnectMs, conn.RequestId = uint32(time.Since(apiStart).Milliseconds()), resp.Header.Get("request-id")
+                return resp, conn, nil
+            }
+            ttsSynthesisTotal.WithLabelValues("api_error").Inc()
+            b, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+            resp.Body.Close()
+            status, retryAfter = resp.StatusCode, resp.Header.Get("Retry-After")
+            // A rejected key may have been rotated; read it again next time
+            if status == http.StatusUnauthorized { secrets.Invalidate("ELEVENLABS_API_KEY") }
+            msg = fmt.Sprintf("status=%d body=%s", status, string(b))
+        }
+
+        code, retryable := classifyFailure(status, err)
+        if !retryable || attempt > s.retry.max || ctx.Err() != nil {
+            log.Printf("[tts] synthesis failed sid=%s req=%s code=%s attempts=%d: %s", start.GetSessionId(), start.GetRequestId(), code, attempt, msg)
+            return nil, nil, &pb.Failed{RequestId: start.GetRequestId(), Code: code, Message: msg, Attempts: uint32(attempt), Retryable: retryable}
+        }
+        d := s.retry.delay(attempt, retryAfter)
+        ttsRetriesTotal.WithLabelValues(code).Inc()
+        log.Printf("[tts] retrying sid=%s req=%s code=%s attempt=%d backoff=%s", start.GetSessionId(), start.GetRequestId(), code, attempt, d)
+        if !sleepCtx(ctx, d) {
+            return nil, nil, &pb.Failed{RequestId: start.GetRequestId(), Code: code, Message: msg, Attempts: uint32(attempt), Retryable: true}
+        }
+    }
+}
+
 // readWAVPCM16 is a small WAV parser that returns raw PCM16 bytes for mono (or averages stereo) at any sample rate.
 // For simplicity we assume input WAV is 48kHz mono 16-bit; if stereo, we average channels.
 func readWAVPCM16(r io.Reader) ([]byte, error) {
@@ -0,0 +1,141 @@
+package tts
+
+import (
+    "encoding/binary"
+    "fmt"
+    "io"
+    "log"
+    "net/http"
+    "os"
+    "strconv"
+    "strings"
+    "time"
+
+    "yuzu/agent/internal/secrets"
+    pb "yuzu/agent/internal/tts/pb"
+)
+
+// synthesize.go serves GET /synthesize on the probes port so voices and
+// settings can be tried from a browser or curl without a gRPC client:
+//
+//	curl -o hi.wav 'localhost:8084/synthesize?text=Hello&voice=<id>&rate=1.1'
+//
+// intonation=question or exclamation applies that kind's prosody hints
+// (see prosody.go).
+//
+// The reply is one complete WAV (48kHz mono PCM16), synthesized the same way
+// as a Session, retries included. voice defaults to ELEVENLABS_VOICE_ID.
+// Only WAV is offered: OGG would need an Opus encoder this service does not
+// carry. Like the API server's dev endpoints it needs DEV_MODE=true or an
+// X-Dev-Key header matching DEV_KEY, since every call spends provider quota.
+
+// maxSynthesizeChars bounds the text of one request.
+const maxSynthesizeChars = 1000
+
+// SynthesizeHandler returns the /synthesize handler.
+func (s *Server) SynthesizeHandler() http.Handler {
+    return http.HandlerFunc(s.handleSynthesize)
+}
+
+func (s *Server) handleSynthesize(w http.ResponseWriter, r *http.Request) {
+    if r.Method != http.MethodGet {
+        http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+        return
+    }
+    if !devAuthorized(r) {
+        http.Error(w, "dev endpoint", http.StatusForbidden)
+        return
+    }
+    q := r.URL.Query()
+    text := strings.TrimSpace(q.Get("text"))
+    if text == "" || len(text) > maxSynthesizeChars {
+        http.Error(w, fmt.Sprintf("text is required and at most %d bytes", maxSynthesizeChars), http.StatusBadRequest)
+        return
+    }
+    if f := q.Get("format"); f != "" && f != "wav" {
+        http.Error(w, "only format=wav is supported", http.StatusBadRequest)
+        return
+    }
+    voice := q.Get("voice")
+    if voice == "" {
+        voice = os.Getenv("ELEVENLABS_VOICE_ID")
+    }
+    if voice == "" {
+        http.Error(w, "voice is required (ELEVENLABS_VOICE_ID is unset)", http.StatusBadRequest)
+        return
+    }
+    var rate float32
+    if v := q.Get("rate"); v != "" {
+        f, err := strconv.ParseFloat(v, 32)
+        if err != nil || f <= 0 {
+            http.Error(w, "rate must be a positive number", http.StatusBadRequest)
+            return
+        }
+        rate = float32(f)
+    }
+    intonation := q.Get("intonation")
+    if _, ok := prosodyHints[intonation]; intonation != "" && !ok {
+        http.Error(w, "intonation must be question or exclamation", http.StatusBadRequest)
+        return
+    }
+    apiKey := secrets.Get("ELEVENLABS_API_KEY")
+    if apiKey == "" {
+        http.Error(w, "ELEVENLABS_API_KEY missing", http.StatusServiceUnavailable)
+        return
+    }
+
+    start := &pb.StartRequest{SessionId: "synthesize", RequestId: time.Now().Format("20060102150405.000"), VoiceId: voice, Text: text, SpeakingRate: rate, Intonation: intonation}
+    resp, conn, failed := s.synthesize(r.Context(), apiKey, start)
+    if failed != nil {
+        status := http.StatusBadGateway
+        if failed.GetRetryable() {
+            status = http.StatusServiceUnavailable
+        }
+        http.Error(w, fmt.Sprintf("synthesis failed: %s: %s", failed.GetCode(), failed.GetMessage()), status)
+        return
+    }
+    defer resp.Body.Close()
+    pcm, err := io.ReadAll(resp.Body)
+    if err != nil || len(pcm) == 0 {
+        http.Error(w, "empty audio response", http.StatusBadGateway)
+        return
+    }
+    s.loudness.normalize(pcm)
+    recordSentence(voice, text, len(pcm))
+    log.Printf("[tts] synthesize voice=%s text_len=%d rate=%.2f bytes=%d connect_ms=%d request_id=%s", voice, len(text), rate, len(pcm), conn.GetConnectMs(), conn.GetRequestId())
+    w.Header().Set("Content-Type", "audio/wav")
+    w.Header().Set("Content-Disposition", `attachment; filename="synthesize.wav"`)
+    w.Header().Set("Content-Length", strconv.Itoa(44+len(pcm)))
+    _ = writeWAV(w, pcm, 48000)
+}
+
+// writeWAV writes mono PCM16 as a WAV file.
+func writeWAV(w io.Writer, pcm []byte, sampleRate uint32) error {
+    h := make([]byte, 44)
+    copy(h[0:], "RIFF")
+    binary.LittleEndian.PutUint32(h[4:], uint32(36+len(pcm)))
+    copy(h[8:], "WAVEfmt ")
+    binary.LittleEndian.PutUint32(h[16:], 16)
+    binary.LittleEndian.PutUint16(h[20:], 1) // PCM
+    binary.LittleEndian.PutUint16(h[22:], 1) // mono
+    binary.LittleEndian.PutUint32(h[24:], sampleRate)
+    binary.LittleEndian.PutUint32(h[28:], sampleRate*2)
+    binary.LittleEndian.PutUint16(h[32:], 2)
+    binary.LittleEndian.PutUint16(h[34:], 16)
+    copy(h[36:], "data")
+    binary.LittleEndian.PutUint32(h[40:], uint32(len(pcm)))
+    if _, err := w.Write(h); err != nil {
+        return err
+    }
+    _, err := w.Write(pcm)
+    return err
+}
+
+// devAuthorized mirrors the API server's dev-endpoint check.
+func devAuthorized(r *http.Request) bool {
+    if os.Getenv("DEV_MODE") == "true" {
+        return true
+    }
+    key := os.Getenv("DEV_KEY")
+    return key != "" && r.Header.Get("X-Dev-Key") == key
+}
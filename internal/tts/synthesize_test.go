@@ -0,0 +1,72 @@
+package tts
+
+import (
+    "bytes"
+    "net/http"
+    "net/http/httptest"
+    "strings"
+    "testing"
+    "time"
+)
+
+func TestSynthesizeHandler(t *testing.T) {
+    var gotPath string
+    ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        gotPath = r.URL.Path
+        w.Write(make([]byte, 960))
+    }))
+    defer ts.Close()
+    old := elevenLabsURL
+    elevenLabsURL = ts.URL
+    defer func() { elevenLabsURL = old }()
+    t.Setenv("ELEVENLABS_API_KEY", "test")
+    t.Setenv("ELEVENLABS_VOICE_ID", "default-voice")
+    t.Setenv("DEV_MODE", "")
+    t.Setenv("DEV_KEY", "k")
+
+    h := (&Server{retry: retryPolicy{max: 0, base: time.Millisecond, cap: time.Millisecond}}).SynthesizeHandler()
+    get := func(query, key string) *httptest.ResponseRecorder {
+        req := httptest.NewRequest(http.MethodGet, "/synthesize?"+query, nil)
+        if key != "" {
+            req.Header.Set("X-Dev-Key", key)
+        }
+        rec := httptest.NewRecorder()
+        h.ServeHTTP(rec, req)
+        return rec
+    }
+
+    if rec := get("text=hi", ""); rec.Code != http.StatusForbidden {
+        t.Fatalf("without dev key: status %d, want 403", rec.Code)
+    }
+    if rec := get("text=hi&format=ogg", "k"); rec.Code != http.StatusBadRequest {
+        t.Fatalf("format=ogg: status %d, want 400", rec.Code)
+    }
+    if rec := get("text=", "k"); rec.Code != http.StatusBadRequest {
+        t.Fatalf("empty text: status %d, want 400", rec.Code)
+    }
+    if rec := get("text=hi&intonation=sarcastic", "k"); rec.Code != http.StatusBadRequest {
+        t.Fatalf("unknown intonation: status %d, want 400", rec.Code)
+    }
+
+    rec := get("text=Hello+there", "k")
+    if rec.Code != http.StatusOK {
+        t.Fatalf("status %d: %s", rec.Code, rec.Body)
+    }
+    if gotPath != "/v1/text-to-speech/default-voice" {
+        t.Errorf("provider path = %q, want the default voice", gotPath)
+    }
+    body := rec.Body.Bytes()
+    if len(body) != 44+960 || !bytes.HasPrefix(body, []byte("RIFF")) || rec.Header().Get("Content-Type") != "audio/wav" {
+        t.Fatalf("got %d bytes %q..., want a 1004-byte WAV", len(body), body[:min(12, len(body))])
+    }
+    // The result parses with the service's own reader
+    pcm, err := readWAVPCM16(bytes.NewReader(body))
+    if err != nil || len(pcm) != 960 {
+        t.Fatalf("readWAVPCM16 = %d bytes, %v", len(pcm), err)
+    }
+
+    get("text=Hi&voice=other", "k")
+    if !strings.HasSuffix(gotPath, "/other") {
+        t.Errorf("provider path = %q, want voice=other", gotPath)
+    }
+}
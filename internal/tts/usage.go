@@ -0,0 +1,77 @@
+package tts
+
+import (
+    "log"
+    "os"
+    "strconv"
+    "strings"
+    "sync"
+    "unicode/utf8"
+)
+
+// usage.go records what each synthesized sentence cost: characters sent to
+// the provider, seconds of audio produced and time to the first frame, all
+// labeled by voice. The provider bills per character, so spend is estimated
+// at TTS_COST_PER_1K_CHARS_USD (default 0.30). Voice IDs are client input,
+// so only the first TTS_METRIC_MAX_VOICES (default 20) distinct ones get
+// their own label; later ones share "other".
+
+const (
+    bytesPerAudioSecond      = 48000 * 2 // PCM16 mono at 48kHz
+    defaultCostPer1KCharsUSD = 0.30
+    defaultMaxVoiceLabels    = 20
+)
+
+var costPer1KCharsUSD = readCostPer1KChars()
+
+func readCostPer1KChars() float64 {
+    v := strings.TrimSpace(os.Getenv("TTS_COST_PER_1K_CHARS_USD"))
+    if v == "" { return defaultCostPer1KCharsUSD }
+    f, err := strconv.ParseFloat(v, 64)
+    if err != nil || f < 0 {
+        log.Printf("[tts] invalid TTS_COST_PER_1K_CHARS_USD=%q, using %.2f", v, defaultCostPer1KCharsUSD)
+        return defaultCostPer1KCharsUSD
+    }
+    return f
+}
+
+// charCost estimates the spend for chars characters of text.
+func charCost(chars int) float64 { return float64(chars) / 1000 * costPer1KCharsUSD }
+
+// voiceLabels bounds the voice label's cardinality.
+type voiceLabels struct {
+    mu    sync.Mutex
+    max   int
+    known map[string]bool
+}
+
+var voices = newVoiceLabels(maxVoiceLabelsFromEnv())
+
+func newVoiceLabels(max int) *voiceLabels {
+    return &voiceLabels{max: max, known: map[string]bool{}}
+}
+
+func maxVoiceLabelsFromEnv() int {
+    if n, err := strconv.Atoi(os.Getenv("TTS_METRIC_MAX_VOICES")); err == nil && n >= 0 { return n }
+    return defaultMaxVoiceLabels
+}
+
+// label returns the metric label for voice.
+func (v *voiceLabels) label(voice string) string {
+    if voice == "" { return "none" }
+    v.mu.Lock()
+    defer v.mu.Unlock()
+    if v.known[voice] { return voice }
+    if len(v.known) >= v.max { return "other" }
+    v.known[voice] = true
+    return voice
+}
+
+// recordSentence counts one synthesized sentence against its voice.
+func recordSentence(voice, text string, pcmBytes int) {
+    label := voices.label(voice)
+    chars := utf8.RuneCountInString(text)
+    ttsCharacters.WithLabelValues(label).Add(float64(chars))
+    ttsAudioSeconds.WithLabelValues(label).Add(float64(pcmBytes) / bytesPerAudioSecond)
+    ttsSpendUSD.Add(charCost(chars))
+}
@@ -0,0 +1,28 @@
+package tts
+
+import (
+    "math"
+    "testing"
+)
+
+func TestVoiceLabelsBounded(t *testing.T) {
+    v := newVoiceLabels(2)
+    for voice, want := range map[string]string{"": "none", "a": "a"} {
+        if got := v.label(voice); got != want {
+            t.Errorf("label(%q) = %q, want %q", voice, got, want)
+        }
+    }
+    v.label("b")
+    if got := v.label("c"); got != "other" {
+        t.Errorf("third voice labeled %q, want other", got)
+    }
+    if got := v.label("a"); got != "a" {
+        t.Errorf("known voice relabeled %q", got)
+    }
+}
+
+func TestCharCost(t *testing.T) {
+    if got, want := charCost(2500), 2.5*costPer1KCharsUSD; math.Abs(got-want) > 1e-12 {
+        t.Errorf("charCost(2500) = %v, want %v", got, want)
+    }
+}
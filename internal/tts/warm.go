@@ -0,0 +1,61 @@
+package tts
+
+import (
+    "bytes"
+    "context"
+    "encoding/json"
+    "fmt"
+    "io"
+    "net/http"
+    "os"
+    "strings"
+
+    "yuzu/agent/internal/errdefs"
+    "yuzu/agent/internal/secrets"
+    "yuzu/agent/internal/warmup"
+)
+
+// warm.go is the TTS service's warm-up request (see internal/warmup): a
+// one-character synthesis in TTS_WARMUP_VOICE_ID (default
+// ELEVENLABS_VOICE_ID) with the session model, through the client real
+// sessions use. It is billed as one character but left out of the usage
+// metrics, and skips retries and chaos injection.
+
+// warmText is what a warm-up synthesizes.
+const warmText = "."
+
+// SetWarmer has Session note real traffic on w, so warm-ups only fill
+// idle spells.
+func (s *Server) SetWarmer(w *warmup.Warmer) { s.warm = w }
+
+// Warm sends the warm-up synthesis.
+func (s *Server) Warm(ctx context.Context) error {
+    apiKey := secrets.Get("ELEVENLABS_API_KEY")
+    if apiKey == "" {
+        return &errdefs.ConfigError{Key: "ELEVENLABS_API_KEY", Msg: "missing"}
+    }
+    voice := os.Getenv("TTS_WARMUP_VOICE_ID")
+    if voice == "" { voice = os.Getenv("ELEVENLABS_VOICE_ID") }
+    if voice == "" {
+        return &errdefs.ConfigError{Key: "TTS_WARMUP_VOICE_ID", Msg: "no voice to warm"}
+    }
+    body := map[string]any{"text": warmText}
+    if m := os.Getenv("ELEVENLABS_MODEL_ID"); m != "" { body["model_id"] = m }
+    reqBytes, _ := json.Marshal(body)
+    url := fmt.Sprintf("%s/v1/text-to-speech/%s?output_format=pcm_48000", providerURL(), voice)
+    req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(reqBytes))
+    if err != nil { return err }
+    req.Header.Set("xi-api-key", apiKey)
+    req.Header.Set("accept", "audio/wav")
+    req.Header.Set("content-type", "application/json")
+    resp, err := http.DefaultClient.Do(req)
+    if err != nil { return errdefs.ProviderTransport("elevenlabs", err) }
+    defer resp.Body.Close()
+    if resp.StatusCode/100 != 2 {
+        b, _ := io.ReadAll(io.LimitReader(resp.Body, 256))
+        return errdefs.ProviderStatus("elevenlabs", resp.StatusCode, strings.TrimSpace(string(b)))
+    }
+    // Drain the audio so the connection goes back to the pool
+    io.Copy(io.Discard, resp.Body)
+    return nil
+}
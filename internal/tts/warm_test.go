@@ -0,0 +1,39 @@
+package tts
+
+import (
+    "context"
+    "encoding/json"
+    "net/http"
+    "net/http/httptest"
+    "testing"
+)
+
+func TestWarmSynthesizesOneCharacter(t *testing.T) {
+    var gotPath, gotText string
+    ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        gotPath = r.URL.Path
+        var body map[string]any
+        json.NewDecoder(r.Body).Decode(&body)
+        gotText, _ = body["text"].(string)
+        w.Write(make([]byte, 960))
+    }))
+    defer ts.Close()
+    old := elevenLabsURL
+    elevenLabsURL = ts.URL
+    defer func() { elevenLabsURL = old }()
+    t.Setenv("ELEVENLABS_API_KEY", "test")
+    t.Setenv("ELEVENLABS_VOICE_ID", "default-voice")
+    t.Setenv("TTS_WARMUP_VOICE_ID", "")
+
+    if err := (&Server{}).Warm(context.Background()); err != nil {
+        t.Fatal(err)
+    }
+    if gotPath != "/v1/text-to-speech/default-voice" || gotText != warmText {
+        t.Fatalf("warmed %s with %q", gotPath, gotText)
+    }
+
+    t.Setenv("ELEVENLABS_VOICE_ID", "")
+    if err := (&Server{}).Warm(context.Background()); err == nil {
+        t.Fatal("warmed without a voice")
+    }
+}
@@ -1,6 +1,11 @@
 package types
 
-import "time"
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"time"
+)
 
 type Event struct {
 	Type    string         `json:"type"`
@@ -10,13 +15,395 @@ type Event struct {
 
 type Session struct {
 	ID        string    `json:"session_id"`
+	TenantID  string    `json:"tenant_id,omitempty"`
 	RoomName  string    `json:"room_name"`
 	RoomURL   string    `json:"room_url"`
 	BotToken  string    `json:"bot_token"`
 	CreatedAt time.Time `json:"created_at"`
 	Status    string    `json:"status"`
 
+	Style SessionStyle `json:"style"`
+
+	// Settings from the preset the session was created with, snapshotted
+	// so later preset edits don't change it (see Preset).
+	Preset  string          `json:"preset,omitempty"`
+	VoiceID string          `json:"voice_id,omitempty"`
+	Flow    json.RawMessage `json:"flow,omitempty"`
+	VAD     VADSettings     `json:"vad"`
+	// TurnPolicy names a floor.Strategies entry; empty uses the
+	// deployment's FLOOR_INTERJECTION_MODE
+	TurnPolicy string `json:"turn_policy,omitempty"`
+
 	BotPID          int        `json:"bot_pid,omitempty"`
 	BotLastExitCode int        `json:"bot_last_exit_code,omitempty"`
 	BotLastExitAt   *time.Time `json:"bot_last_exit_at,omitempty"`
+
+	// How the worker's clock compared with ours, as of its last disconnect
+	ClockSkew *ClockSkew `json:"clock_skew,omitempty"`
+	// Where the worker runs, from its latest worker_hello
+	Device *WorkerDevice `json:"device,omitempty"`
+
+	// What the candidate did not consent to being kept
+	Consent Consent `json:"consent"`
+}
+
+// Consent lists the recordings a session opted out of at creation. The
+// zero value records as before; each subsystem that keeps something
+// checks its flag through store.Store.GetConsent.
+type Consent struct {
+	// No audio is written to disk (the STT sidecar's samples)
+	NoAudioArchive bool `json:"no_audio_archive,omitempty"`
+	// Transcript text is kept only while the bot is in the call: it is never
+	// persisted, exported or searchable, and is redacted when the bot exits
+	NoTranscriptRetention bool `json:"no_transcript_retention,omitempty"`
+	// The LLM service never samples the session's prompts and replies
+	NoLLMLogging bool `json:"no_llm_logging,omitempty"`
+}
+
+// DoNotRecord is the consent of a session that opted out of everything.
+func DoNotRecord() Consent {
+	return Consent{NoAudioArchive: true, NoTranscriptRetention: true, NoLLMLogging: true}
+}
+
+// Any reports whether the session opted out of anything.
+func (c Consent) Any() bool {
+	return c.NoAudioArchive || c.NoTranscriptRetention || c.NoLLMLogging
+}
+
+// TranscriptEvent reports whether events of type typ carry transcript text
+// in their "text" payload field.
+func TranscriptEvent(typ string) bool {
+	return typ == "transcript_final" || typ == "agent_text"
+}
+
+// WorkerDevice is the environment a session's worker reported in
+// worker_hello, for matching audio problems to setups. Fields the worker
+// left out stay empty.
+type WorkerDevice struct {
+	OS             string `json:"os,omitempty"`
+	AudioDevice    string `json:"audio_device,omitempty"`
+	GatewayVersion string `json:"gateway_version,omitempty"`
+	// Whether echo cancellation runs on the worker's mic; nil when unknown
+	AECEnabled *bool     `json:"aec_enabled,omitempty"`
+	ReportedAt time.Time `json:"reported_at"`
+}
+
+// ClockSkew compares the timestamps a session's worker put on its messages
+// with when the API server received them. Delay mixes network latency with
+// any clock offset; command round trips separate the two.
+type ClockSkew struct {
+	From  time.Time `json:"from"`
+	Until time.Time `json:"until"`
+
+	// Receive time minus the message's ts_ms, over Messages worker messages
+	Messages int       `json:"messages"`
+	DelayMs  SkewStats `json:"delay_ms"`
+	// Mean change in delay between consecutive messages
+	JitterMs float64 `json:"jitter_ms"`
+	// Change per minute in the lowest delay of each minute; nil under three minutes
+	DriftMsPerMin *float64 `json:"drift_ms_per_min,omitempty"`
+
+	// Commands acked by the worker, timed on our clock
+	RoundTrips int        `json:"round_trips"`
+	RTTMs      *SkewStats `json:"rtt_ms,omitempty"`
+	// Worker clock minus ours, from the fastest round trip, give or take
+	// OffsetErrorMs (half that round trip)
+	OffsetMs      *float64 `json:"offset_ms,omitempty"`
+	OffsetErrorMs *float64 `json:"offset_error_ms,omitempty"`
+
+	// ok, clock_offset, clock_drift, network_jitter, or unknown without a
+	// round trip to tell the offset from latency
+	Assessment string `json:"assessment"`
+}
+
+// SkewStats summarizes a set of millisecond values.
+type SkewStats struct {
+	Min float64 `json:"min"`
+	Avg float64 `json:"avg"`
+	P95 float64 `json:"p95"`
+	Max float64 `json:"max"`
+}
+
+// NetworkStats is one WebRTC stats sample reported by the worker. Metrics
+// the worker could not measure are nil.
+type NetworkStats struct {
+	Ts            time.Time `json:"timestamp"`
+	RTTMs         *float64  `json:"rtt_ms,omitempty"`
+	JitterMs      *float64  `json:"jitter_ms,omitempty"`
+	PacketLossPct *float64  `json:"packet_loss_pct,omitempty"`
+	AudioLevel    *float64  `json:"audio_level,omitempty"` // 0-1
+}
+
+// Personas and verbosity levels understood by the orchestrator's prompt builder.
+const (
+	PersonaFriendly             = "friendly"
+	PersonaFormal               = "formal"
+	PersonaTechnicalInterviewer = "technical-interviewer"
+
+	VerbosityBrief    = "brief"
+	VerbosityNormal   = "normal"
+	VerbosityDetailed = "detailed"
+)
+
+// MaxSystemPromptLen bounds a tenant's system prompt override.
+const MaxSystemPromptLen = 4000
+
+// MaxInstructionsLen bounds a session's own prompt instructions.
+const MaxInstructionsLen = 2000
+
+// MaxInterviewSeconds bounds SessionStyle.MaxDurationSeconds (4 hours).
+const MaxInterviewSeconds = 4 * 60 * 60
+
+// SessionStyle controls how the agent responds in a session. A nil
+// Temperature and a zero MaxTokens leave the provider default in place;
+// a temperature of 0 is a setting of its own. SystemPrompt
+// is a tenant-level override of the built prompt; the API does not accept
+// it from callers. Instructions are the session's own section of the
+// prompt, after the tenant and flow sections and subordinate to them;
+// callers may set them.
+type SessionStyle struct {
+	Persona      string   `json:"persona,omitempty"`
+	Verbosity    string   `json:"verbosity,omitempty"`
+	Temperature  *float64 `json:"temperature,omitempty"`
+	MaxTokens    int      `json:"max_tokens,omitempty"`
+	SystemPrompt string   `json:"system_prompt,omitempty"`
+	Instructions string   `json:"instructions,omitempty"`
+	// Captions streams live candidate and agent captions to the room
+	Captions bool `json:"captions,omitempty"`
+	// TokenStream sends the agent's reply to the room token by token
+	TokenStream bool `json:"token_stream,omitempty"`
+	// EchoTest repeats each final transcript back through TTS instead of
+	// replying, to check the audio loop during bring-up
+	EchoTest bool `json:"echo_test,omitempty"`
+	// MaxDurationSeconds ends the interview after this long, with a
+	// wrap-up beforehand; 0 uses the orchestrator's ORCH_MAX_SESSION_MS
+	MaxDurationSeconds int `json:"max_duration_s,omitempty"`
+}
+
+// Merge fills unset fields of s from def.
+func (s SessionStyle) Merge(def SessionStyle) SessionStyle {
+	if s.Persona == "" {
+		s.Persona = def.Persona
+	}
+	if s.Verbosity == "" {
+		s.Verbosity = def.Verbosity
+	}
+	if s.Temperature == nil {
+		s.Temperature = def.Temperature
+	}
+	if s.MaxTokens == 0 {
+		s.MaxTokens = def.MaxTokens
+	}
+	if s.SystemPrompt == "" {
+		s.SystemPrompt = def.SystemPrompt
+	}
+	if s.Instructions == "" {
+		s.Instructions = def.Instructions
+	}
+	if s.MaxDurationSeconds == 0 {
+		s.MaxDurationSeconds = def.MaxDurationSeconds
+	}
+	// Unset and false look alike, so any layer can turn captions on
+	s.Captions = s.Captions || def.Captions
+	s.TokenStream = s.TokenStream || def.TokenStream
+	s.EchoTest = s.EchoTest || def.EchoTest
+	return s
+}
+
+// Validate rejects unknown personas/verbosity and out-of-range sampling values.
+func (s SessionStyle) Validate() error {
+	switch s.Persona {
+	case "", PersonaFriendly, PersonaFormal, PersonaTechnicalInterviewer:
+	default:
+		return fmt.Errorf("unknown persona %q", s.Persona)
+	}
+	switch s.Verbosity {
+	case "", VerbosityBrief, VerbosityNormal, VerbosityDetailed:
+	default:
+		return fmt.Errorf("unknown verbosity %q", s.Verbosity)
+	}
+	if t := s.Temperature; t != nil && (*t < 0 || *t > 2) {
+		return fmt.Errorf("temperature must be within [0, 2]")
+	}
+	if s.MaxTokens < 0 || s.MaxTokens > 4096 {
+		return fmt.Errorf("max_tokens must be within [0, 4096]")
+	}
+	if len(s.SystemPrompt) > MaxSystemPromptLen {
+		return fmt.Errorf("system_prompt must be at most %d bytes", MaxSystemPromptLen)
+	}
+	if len(s.Instructions) > MaxInstructionsLen {
+		return fmt.Errorf("instructions must be at most %d bytes", MaxInstructionsLen)
+	}
+	if s.MaxDurationSeconds < 0 || s.MaxDurationSeconds > MaxInterviewSeconds {
+		return fmt.Errorf("max_duration_s must be within [0, %d]", MaxInterviewSeconds)
+	}
+	return nil
+}
+
+// VADSettings tune barge-in for a session; zero fields come from Profile,
+// then the deployment's LOCAL_STOP_MIN_RMS, LOCAL_STOP_GUARD_MS and the
+// orchestrator's hangover.
+type VADSettings struct {
+	Profile  string `json:"profile,omitempty"` // a BargeInProfiles name, or "auto"
+	MinRMS   int    `json:"min_rms,omitempty"`
+	GuardMs  int    `json:"guard_ms,omitempty"`
+	Hangover int    `json:"hangover,omitempty"` // quiet 20 ms frames that end speech
+}
+
+// BargeInProfileAuto starts a session on the deployment's thresholds and
+// switches to the profile the orchestrator suggests from the noise floor
+// it hears in the first seconds.
+const BargeInProfileAuto = "auto"
+
+// BargeInProfiles are the built-in barge-in tunings per audio setup. A
+// headset hears no echo of the agent, so it can be sensitive; laptop
+// speakers leak the agent into the mic and need a high floor and a long
+// guard; a phone sits in between, with more background noise.
+var BargeInProfiles = map[string]VADSettings{
+	"headset":         {MinRMS: 600, GuardMs: 300, Hangover: 15},
+	"laptop-speakers": {MinRMS: 1800, GuardMs: 1200, Hangover: 25},
+	"phone":           {MinRMS: 1200, GuardMs: 800, Hangover: 20},
+}
+
+// Resolve fills the zero thresholds from v's profile.
+func (v VADSettings) Resolve() VADSettings {
+	p := BargeInProfiles[v.Profile]
+	if v.MinRMS == 0 {
+		v.MinRMS = p.MinRMS
+	}
+	if v.GuardMs == 0 {
+		v.GuardMs = p.GuardMs
+	}
+	if v.Hangover == 0 {
+		v.Hangover = p.Hangover
+	}
+	return v
+}
+
+// Validate bounds the thresholds and checks the profile name.
+func (v VADSettings) Validate() error {
+	if _, ok := BargeInProfiles[v.Profile]; !ok && v.Profile != "" && v.Profile != BargeInProfileAuto {
+		return fmt.Errorf("unknown vad.profile %q", v.Profile)
+	}
+	if v.MinRMS < 0 || v.MinRMS > 32767 {
+		return fmt.Errorf("vad.min_rms must be within [0, 32767]")
+	}
+	if v.GuardMs < 0 || v.GuardMs > 10000 {
+		return fmt.Errorf("vad.guard_ms must be within [0, 10000]")
+	}
+	if v.Hangover < 0 || v.Hangover > 500 {
+		return fmt.Errorf("vad.hangover must be within [0, 500]")
+	}
+	return nil
+}
+
+// MaxFlowLen bounds a preset's flow definition.
+const MaxFlowLen = 32 << 10
+
+var presetName = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{0,62}$`)
+
+// Preset is a named session template such as "phone-screen" or
+// "tech-deep-dive". Style carries the LLM settings and, unlike a caller's
+// style, may set SystemPrompt. Flow is an orchestrator interview flow
+// ({"name": ..., "stages": [...]}), checked in full by the orchestrator.
+type Preset struct {
+	Name        string          `json:"name"`
+	Description string          `json:"description,omitempty"`
+	Style       SessionStyle    `json:"style"`
+	VoiceID     string          `json:"voice_id,omitempty"`
+	Flow        json.RawMessage `json:"flow,omitempty"`
+	VAD         VADSettings     `json:"vad"`
+	UpdatedAt   time.Time       `json:"updated_at"`
+}
+
+// Validate checks everything but the flow's stage semantics.
+func (p Preset) Validate() error {
+	if !presetName.MatchString(p.Name) {
+		return fmt.Errorf("name must be 1-63 lowercase letters, digits or dashes")
+	}
+	// Any tenant key can write presets; the prompt stays the operator's
+	if p.Style.SystemPrompt != "" {
+		return fmt.Errorf("system_prompt is configured per tenant")
+	}
+	if err := p.Style.Validate(); err != nil {
+		return err
+	}
+	if err := p.VAD.Validate(); err != nil {
+		return err
+	}
+	if len(p.Flow) == 0 {
+		return nil
+	}
+	if len(p.Flow) > MaxFlowLen {
+		return fmt.Errorf("flow must be at most %d bytes", MaxFlowLen)
+	}
+	var f struct {
+		Name   string `json:"name"`
+		Stages []struct {
+			ID string `json:"id"`
+		} `json:"stages"`
+	}
+	if err := json.Unmarshal(p.Flow, &f); err != nil {
+		return fmt.Errorf("flow: %v", err)
+	}
+	if f.Name == "" || len(f.Stages) == 0 {
+		return fmt.Errorf("flow needs a name and at least one stage")
+	}
+	for i, s := range f.Stages {
+		if s.ID == "" {
+			return fmt.Errorf("flow stage %d needs an id", i)
+		}
+	}
+	return nil
+}
+
+// Context document bounds. The documents travel to the worker in one
+// environment variable, so the total, measured as that JSON, stays well
+// under the exec limit.
+const (
+	MaxContextDocs     = 8
+	MaxContextDocLen   = 32 << 10
+	MaxContextTotalLen = 64 << 10
+)
+
+// ContextDoc is reference material uploaded for one session, such as the
+// job description or the candidate's resume. The orchestrator splits it
+// into chunks and adds the ones relevant to each turn to the prompt.
+type ContextDoc struct {
+	Name string `json:"name"`
+	Kind string `json:"kind,omitempty"` // free-form label, e.g. "resume"
+	Text string `json:"text"`
+}
+
+// CheckContextDocs bounds a session's documents: their count, and their
+// size as encoded into LLM_CONTEXT_JSON, escaping included.
+func CheckContextDocs(docs []ContextDoc) error {
+	if len(docs) > MaxContextDocs {
+		return fmt.Errorf("at most %d context documents per session", MaxContextDocs)
+	}
+	b, err := json.Marshal(docs)
+	if err != nil {
+		return err
+	}
+	if len(b) > MaxContextTotalLen {
+		return fmt.Errorf("context documents must total at most %d bytes as JSON, got %d", MaxContextTotalLen, len(b))
+	}
+	return nil
+}
+
+// Validate checks the name and bounds the text.
+func (d ContextDoc) Validate() error {
+	if !presetName.MatchString(d.Name) {
+		return fmt.Errorf("name must be 1-63 lowercase letters, digits or dashes")
+	}
+	if len(d.Kind) > 64 {
+		return fmt.Errorf("kind must be at most 64 bytes")
+	}
+	if d.Text == "" {
+		return fmt.Errorf("text is required")
+	}
+	if len(d.Text) > MaxContextDocLen {
+		return fmt.Errorf("text must be at most %d bytes", MaxContextDocLen)
+	}
+	return nil
 }
@@ -0,0 +1,18 @@
+package warmup
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	metricRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "warmup_requests_total",
+		Help: "Provider warm-up requests, by service, trigger (start, idle) and result",
+	}, []string{"service", "trigger", "result"})
+	metricLatency = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "warmup_latency_ms",
+		Help:    "Latency of successful provider warm-ups (ms), by service and trigger",
+		Buckets: []float64{50, 100, 200, 300, 500, 750, 1000, 1500, 2500, 5000, 10000},
+	}, []string{"service", "trigger"})
+)
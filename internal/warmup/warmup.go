@@ -0,0 +1,108 @@
+// Package warmup keeps a service's provider connection warm, so the first
+// real turn of a session doesn't pay for DNS, TLS, a cold connection pool
+// or a provider that has scaled its model down. With <SERVICE>_WARMUP=on
+// (LLM_WARMUP, TTS_WARMUP; default off) the service sends its smallest
+// useful request (a one-token completion, a one-character synthesis) at
+// start, and again whenever it has gone <SERVICE>_WARMUP_IDLE_S (default
+// 60, under the HTTP client's 90 s idle-connection timeout; 0 only warms
+// at start) without real traffic or a warm-up. Each warm-up is bounded by
+// <SERVICE>_WARMUP_TIMEOUT_MS (default 10000) and counted in
+// warmup_requests_total{service,trigger,result}; the latency of those that
+// succeed is in warmup_latency_ms{service,trigger}.
+package warmup
+
+import (
+	"context"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"yuzu/agent/internal/clock"
+)
+
+// Warmer runs Warm at start and after idle spells.
+type Warmer struct {
+	Service string
+	Warm    func(ctx context.Context) error
+	Idle    time.Duration // 0 only warms at start
+	Timeout time.Duration
+	Clock   clock.Clock
+
+	mu   sync.Mutex
+	last time.Time // last real request or warm-up
+}
+
+// FromEnv returns the Warmer service's env configures, or nil when
+// warm-ups are off.
+func FromEnv(service string, warm func(ctx context.Context) error) *Warmer {
+	prefix := strings.ToUpper(service)
+	if v := os.Getenv(prefix + "_WARMUP"); v != "on" && v != "true" && v != "1" {
+		return nil
+	}
+	w := &Warmer{Service: service, Warm: warm, Idle: 60 * time.Second, Timeout: 10 * time.Second, Clock: clock.Real}
+	if v, err := strconv.Atoi(os.Getenv(prefix + "_WARMUP_IDLE_S")); err == nil && v >= 0 {
+		w.Idle = time.Duration(v) * time.Second
+	}
+	if v, err := strconv.Atoi(os.Getenv(prefix + "_WARMUP_TIMEOUT_MS")); err == nil && v > 0 {
+		w.Timeout = time.Duration(v) * time.Millisecond
+	}
+	return w
+}
+
+// Touch notes real traffic, which keeps the connection warm by itself.
+func (w *Warmer) Touch() {
+	if w == nil {
+		return
+	}
+	w.mu.Lock()
+	w.last = w.Clock.Now()
+	w.mu.Unlock()
+}
+
+// Run warms once, then after every idle spell until ctx ends.
+func (w *Warmer) Run(ctx context.Context) {
+	w.warm(ctx, "start")
+	if w.Idle <= 0 {
+		return
+	}
+	t := w.Clock.NewTicker(min(w.Idle/4, 5*time.Second))
+	defer t.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C():
+			w.tick(ctx)
+		}
+	}
+}
+
+// tick warms if the service has been idle for w.Idle.
+func (w *Warmer) tick(ctx context.Context) {
+	w.mu.Lock()
+	due := w.Clock.Since(w.last) >= w.Idle
+	w.mu.Unlock()
+	if due {
+		w.warm(ctx, "idle")
+	}
+}
+
+func (w *Warmer) warm(ctx context.Context, trigger string) {
+	w.Touch()
+	ctx, cancel := context.WithTimeout(ctx, w.Timeout)
+	defer cancel()
+	start := w.Clock.Now()
+	err := w.Warm(ctx)
+	ms := w.Clock.Since(start).Milliseconds()
+	if err != nil {
+		metricRequests.WithLabelValues(w.Service, trigger, "error").Inc()
+		log.Printf("[%s] warm-up (%s) failed after %dms: %v", w.Service, trigger, ms, err)
+		return
+	}
+	metricRequests.WithLabelValues(w.Service, trigger, "ok").Inc()
+	metricLatency.WithLabelValues(w.Service, trigger).Observe(float64(ms))
+	log.Printf("[%s] warm-up (%s) took %dms", w.Service, trigger, ms)
+}
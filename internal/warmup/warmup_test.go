@@ -0,0 +1,53 @@
+package warmup
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"yuzu/agent/internal/clock"
+)
+
+func TestWarmsAfterIdleOnly(t *testing.T) {
+	clk := clock.NewFake(time.Unix(1700000000, 0))
+	warms := 0
+	w := &Warmer{Service: "test", Idle: time.Minute, Timeout: time.Second, Clock: clk, Warm: func(context.Context) error {
+		warms++
+		return nil
+	}}
+	ctx := context.Background()
+	w.warm(ctx, "start")
+
+	clk.Advance(50 * time.Second)
+	w.Touch() // a real request
+	clk.Advance(50 * time.Second)
+	w.tick(ctx)
+	if warms != 1 {
+		t.Fatalf("warmed %d times, want no idle warm-up 50s after traffic", warms)
+	}
+	clk.Advance(10 * time.Second)
+	w.tick(ctx)
+	w.tick(ctx)
+	if warms != 2 {
+		t.Fatalf("warmed %d times, want one idle warm-up", warms)
+	}
+
+	// Touch on a nil Warmer is a no-op, as when warm-ups are off
+	var off *Warmer
+	off.Touch()
+}
+
+func TestFromEnv(t *testing.T) {
+	warm := func(context.Context) error { return nil }
+	t.Setenv("LLM_WARMUP", "")
+	if FromEnv("llm", warm) != nil {
+		t.Fatal("warm-ups on by default")
+	}
+	t.Setenv("LLM_WARMUP", "on")
+	t.Setenv("LLM_WARMUP_IDLE_S", "0")
+	t.Setenv("LLM_WARMUP_TIMEOUT_MS", "2500")
+	w := FromEnv("llm", warm)
+	if w == nil || w.Idle != 0 || w.Timeout != 2500*time.Millisecond {
+		t.Fatalf("FromEnv = %+v", w)
+	}
+}
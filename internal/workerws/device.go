@@ -0,0 +1,70 @@
+package workerws
+
+import (
+    "fmt"
+    "time"
+
+    "yuzu/agent/internal/types"
+)
+
+// device.go records the environment a worker reports in worker_hello's
+// optional payload.device object ({os, audio_device, gateway_version,
+// aec_enabled}) on the session (types.Session.Device), so support can line
+// audio complaints up with setups in GET /sessions/{id}. A worker that only
+// learns part of it later (the gateway's audio devices and echo
+// cancellation once it has joined the room) sends it again in a
+// worker_device message. Each report replaces what an earlier one said;
+// strings are cut to maxDeviceFieldLen.
+
+const maxDeviceFieldLen = 200
+
+var deviceStringKeys = []string{"os", "audio_device", "gateway_version"}
+
+// helloDevice checks payload.device when present.
+func helloDevice() fieldRule {
+    return func(m Message) *ValidationError {
+        v, ok := m.Payload["device"]
+        if !ok { return nil }
+        dev, ok := v.(map[string]any)
+        if !ok {
+            return &ValidationError{Reason: "invalid_field", Field: "payload.device", Detail: fmt.Sprintf("expected object, got %T", v)}
+        }
+        for _, k := range deviceStringKeys {
+            if v, ok := dev[k]; ok {
+                if _, ok := v.(string); !ok {
+                    return &ValidationError{Reason: "invalid_field", Field: "payload.device." + k, Detail: fmt.Sprintf("expected string, got %T", v)}
+                }
+            }
+        }
+        if v, ok := dev["aec_enabled"]; ok {
+            if _, ok := v.(bool); !ok {
+                return &ValidationError{Reason: "invalid_field", Field: "payload.device.aec_enabled", Detail: fmt.Sprintf("expected bool, got %T", v)}
+            }
+        }
+        return nil
+    }
+}
+
+// parseDevice reads a validated hello's device, ok false without one.
+func parseDevice(msg Message, now time.Time) (types.WorkerDevice, bool) {
+    dev, ok := msg.Payload["device"].(map[string]any)
+    if !ok { return types.WorkerDevice{}, false }
+    d := types.WorkerDevice{ReportedAt: now.UTC()}
+    dst := []*string{&d.OS, &d.AudioDevice, &d.GatewayVersion}
+    for i, k := range deviceStringKeys {
+        s, _ := dev[k].(string)
+        if len(s) > maxDeviceFieldLen { s = s[:maxDeviceFieldLen] }
+        *dst[i] = s
+    }
+    if b, ok := dev["aec_enabled"].(bool); ok {
+        d.AECEnabled = &b
+    }
+    return d, true
+}
+
+// recordDevice stores the device a hello or worker_device reported.
+func (s *Server) recordDevice(sessionID string, msg Message) {
+    if d, ok := parseDevice(msg, time.Now()); ok {
+        s.Store.SetWorkerDevice(sessionID, d)
+    }
+}
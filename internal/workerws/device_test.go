@@ -0,0 +1,66 @@
+package workerws
+
+import (
+    "context"
+    "encoding/json"
+    "strings"
+    "testing"
+    "time"
+
+    ws "nhooyr.io/websocket"
+)
+
+func TestHelloRecordsDevice(t *testing.T) {
+    _, st, url := drainServer(t, 1000)
+    c, _, err := dialWorker(t, url)
+    if err != nil {
+        t.Fatal(err)
+    }
+    defer c.Close(ws.StatusNormalClosure, "")
+    ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+    defer cancel()
+    hello := func(seq int64, device map[string]any) {
+        b, _ := json.Marshal(Message{Type: "worker_hello", SessionID: "s1", TsMs: time.Now().UnixMilli(), Seq: seq,
+            Payload: map[string]any{"version": "p1", "device": device}})
+        if err := c.Write(ctx, ws.MessageText, b); err != nil {
+            t.Fatal(err)
+        }
+        // The policy reply means the hello was handled
+        if _, _, err := c.Read(ctx); err != nil {
+            t.Fatal(err)
+        }
+    }
+
+    hello(1, map[string]any{"os": "Linux-6.1-x86_64", "audio_device": "daily:bot-mic", "gateway_version": "1.4.2", "aec_enabled": false})
+    d := st.GetSession("s1").Device
+    if d == nil || d.OS != "Linux-6.1-x86_64" || d.AudioDevice != "daily:bot-mic" || d.GatewayVersion != "1.4.2" || d.AECEnabled == nil || *d.AECEnabled || d.ReportedAt.IsZero() {
+        t.Fatalf("device = %+v", d)
+    }
+
+    // A later hello replaces it; overlong fields are cut
+    hello(2, map[string]any{"os": strings.Repeat("x", 300)})
+    d = st.GetSession("s1").Device
+    if len(d.OS) != maxDeviceFieldLen || d.AudioDevice != "" || d.AECEnabled != nil {
+        t.Fatalf("after second hello: %+v", d)
+    }
+
+    // worker_device updates it without a new hello
+    b, _ := json.Marshal(Message{Type: "worker_device", SessionID: "s1", TsMs: time.Now().UnixMilli(), Seq: 3,
+        Payload: map[string]any{"device": map[string]any{"os": "Linux-6.1-x86_64", "audio_device": "daily:bot-mic 48k mono", "aec_enabled": true}}})
+    if err := c.Write(ctx, ws.MessageText, b); err != nil {
+        t.Fatal(err)
+    }
+    // Messages are handled in order, so the reply to an invalid one means
+    // the update was handled
+    b, _ = json.Marshal(Message{Type: "vad_start", SessionID: "s1", TsMs: time.Now().UnixMilli(), Seq: 4})
+    if err := c.Write(ctx, ws.MessageText, b); err != nil {
+        t.Fatal(err)
+    }
+    if _, _, err := c.Read(ctx); err != nil {
+        t.Fatal(err)
+    }
+    d = st.GetSession("s1").Device
+    if d.AECEnabled == nil || !*d.AECEnabled || d.AudioDevice != "daily:bot-mic 48k mono" {
+        t.Fatalf("after worker_device: %+v", d)
+    }
+}
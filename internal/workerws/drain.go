@@ -0,0 +1,121 @@
+package workerws
+
+import (
+    "context"
+    "fmt"
+    "log"
+    "net/http"
+    "sync"
+    "time"
+
+    "yuzu/agent/internal/errdefs"
+
+    ws "nhooyr.io/websocket"
+)
+
+// drain.go ends worker connections cleanly on shutdown. Drain sends every
+// connected worker a server_shutdown message, a command whose payload
+// carries the reason and a reconnect hint, and waits up to
+// WORKER_DRAIN_TIMEOUT_MS (default 2000) for the worker to answer with its
+// cmd_ack or a tts_stopped, so speech is cut at the worker rather than mid
+// frame. The connection is then closed with 1001 (going away), which tells
+// the gateway to reconnect, through the load balancer, to another replica.
+// Upgrades arriving while draining are refused with 503 and Retry-After.
+// Each session records worker_drain_started and worker_drained {acked,
+// waited_ms}; drains are counted in workerws_drains_total{outcome}.
+
+const drainReason = "server shutdown"
+
+// drainState is embedded in Server.
+type drainState struct {
+    drainMu  sync.Mutex
+    draining bool
+    acks     map[string]drainWaiter // session -> worker's answer to server_shutdown
+}
+
+type drainWaiter struct {
+    commandID string
+    done      chan struct{}
+}
+
+func (s *Server) isDraining() bool {
+    s.drainMu.Lock()
+    defer s.drainMu.Unlock()
+    return s.draining
+}
+
+// drainAnswered notes a worker message that may answer server_shutdown.
+func (s *Server) drainAnswered(sessionID string, msg Message) {
+    if msg.Type != "cmd_ack" && msg.Type != "tts_stopped" {
+        return
+    }
+    s.drainMu.Lock()
+    defer s.drainMu.Unlock()
+    w, ok := s.acks[sessionID]
+    if !ok || (msg.Type == "cmd_ack" && msg.CommandID != w.commandID) {
+        return
+    }
+    delete(s.acks, sessionID)
+    close(w.done)
+}
+
+// Drain refuses new workers and shuts down the connected ones, returning
+// once all are closed or ctx ends.
+func (s *Server) Drain(ctx context.Context) {
+    s.drainMu.Lock()
+    s.draining = true
+    s.drainMu.Unlock()
+
+    timeout := time.Duration(s.Cfg.Worker.DrainTimeoutMs) * time.Millisecond
+    var wg sync.WaitGroup
+    for _, id := range s.Reg.Sessions() {
+        wg.Add(1)
+        go func(id string) {
+            defer wg.Done()
+            s.drainSession(ctx, id, timeout)
+        }(id)
+    }
+    wg.Wait()
+}
+
+func (s *Server) drainSession(ctx context.Context, sessionID string, timeout time.Duration) {
+    start := time.Now()
+    w := drainWaiter{commandID: fmt.Sprintf("shutdown-%d", start.UnixNano()), done: make(chan struct{})}
+    s.drainMu.Lock()
+    s.acks[sessionID] = w
+    s.drainMu.Unlock()
+    defer func() {
+        s.drainMu.Lock()
+        delete(s.acks, sessionID)
+        s.drainMu.Unlock()
+    }()
+
+    s.Store.AppendEvent(sessionID, "worker_drain_started", map[string]any{"command_id": w.commandID, "timeout_ms": timeout.Milliseconds()})
+    out := Message{Type: "server_shutdown", TsMs: start.UnixMilli(), SessionID: sessionID, CommandID: w.commandID, Payload: map[string]any{
+        "reason":    drainReason,
+        "reconnect": true,
+    }}
+    outcome := "acked"
+    sendCtx, cancel := context.WithTimeout(ctx, timeout)
+    defer cancel()
+    if err := s.Reg.SendJSON(sendCtx, sessionID, out); err != nil {
+        log.Printf("ws drain session=%s: %v", sessionID, err)
+        outcome = "send_error"
+    } else {
+        select {
+        case <-w.done:
+        case <-sendCtx.Done():
+            outcome = "timeout"
+        }
+    }
+    s.Reg.Close(sessionID, ws.StatusGoingAway, drainReason)
+    metricDrains.WithLabelValues(outcome).Inc()
+    s.Store.AppendEvent(sessionID, "worker_drained", map[string]any{"acked": outcome == "acked", "outcome": outcome, "waited_ms": time.Since(start).Milliseconds()})
+}
+
+// refuseDraining answers an upgrade that arrives while draining.
+func refuseDraining(w http.ResponseWriter) {
+    w.Header().Set("Retry-After", "1")
+    w.Header().Set("X-Error-Class", errdefs.ClassBackpressure)
+    http.Error(w, drainReason, http.StatusServiceUnavailable)
+}
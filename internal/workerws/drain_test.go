@@ -0,0 +1,140 @@
+package workerws
+
+import (
+    "context"
+    "encoding/json"
+    "net/http"
+    "net/http/httptest"
+    "strings"
+    "testing"
+    "time"
+
+    "yuzu/agent/internal/auth"
+    "yuzu/agent/internal/config"
+    "yuzu/agent/internal/store"
+    "yuzu/agent/internal/types"
+
+    ws "nhooyr.io/websocket"
+)
+
+func drainServer(t *testing.T, timeoutMs int) (*Server, *store.Memory, string) {
+    t.Helper()
+    var cfg config.Config
+    cfg.Worker.TokenSecret = "secret"
+    cfg.Worker.TokenSkewSecs = 60
+    cfg.Worker.RateLimits = "default=100"
+    cfg.Worker.DrainTimeoutMs = timeoutMs
+    st := store.New()
+    if err := st.CreateSession(&types.Session{ID: "s1"}); err != nil {
+        t.Fatal(err)
+    }
+    s := NewServer(cfg, st, NewRegistry())
+    hs := httptest.NewServer(http.HandlerFunc(s.HandleWorkerWS))
+    t.Cleanup(hs.Close)
+    return s, st, "ws" + strings.TrimPrefix(hs.URL, "http") + "?session_id=s1"
+}
+
+func dialWorker(t *testing.T, url string) (*ws.Conn, *http.Response, error) {
+    t.Helper()
+    tok, err := auth.GenerateWorkerToken("secret", "s1", time.Now().Add(time.Minute).Unix())
+    if err != nil {
+        t.Fatal(err)
+    }
+    h := http.Header{"Authorization": {"Bearer " + tok}}
+    return ws.Dial(context.Background(), url, &ws.DialOptions{HTTPHeader: h})
+}
+
+// waitConnected waits for the read loop to register the worker.
+func waitConnected(t *testing.T, s *Server) {
+    t.Helper()
+    for i := 0; s.Reg.Get("s1") == nil; i++ {
+        if i == 100 {
+            t.Fatal("worker never registered")
+        }
+        time.Sleep(5 * time.Millisecond)
+    }
+}
+
+func drainedEvent(st *store.Memory) map[string]any {
+    for _, ev := range st.ListEvents("s1") {
+        if ev.Type == "worker_drained" {
+            return ev.Payload
+        }
+    }
+    return nil
+}
+
+func TestDrainWaitsForAckAndClosesGoingAway(t *testing.T) {
+    s, st, url := drainServer(t, 2000)
+    c, _, err := dialWorker(t, url)
+    if err != nil {
+        t.Fatal(err)
+    }
+    waitConnected(t, s)
+
+    closed := make(chan ws.StatusCode, 1)
+    go func() {
+        ctx := context.Background()
+        for {
+            _, data, err := c.Read(ctx)
+            if err != nil {
+                closed <- ws.CloseStatus(err)
+                return
+            }
+            var m Message
+            json.Unmarshal(data, &m)
+            if m.Type == "server_shutdown" {
+                ack := Message{Type: "cmd_ack", TsMs: time.Now().UnixMilli(), SessionID: "s1", Seq: 1, CommandID: m.CommandID,
+                    Payload: map[string]any{"ack": true, "error": ""}}
+                b, _ := json.Marshal(ack)
+                c.Write(ctx, ws.MessageText, b)
+            }
+        }
+    }()
+
+    start := time.Now()
+    s.Drain(context.Background())
+    if took := time.Since(start); took > time.Second {
+        t.Fatalf("drain took %s despite the ack", took)
+    }
+    select {
+    case code := <-closed:
+        if code != ws.StatusGoingAway {
+            t.Fatalf("close code %d, want going away", code)
+        }
+    case <-time.After(2 * time.Second):
+        t.Fatal("worker was not closed")
+    }
+    if p := drainedEvent(st); p == nil || p["acked"] != true {
+        t.Fatalf("worker_drained = %v, want acked", p)
+    }
+
+    // Upgrades are refused from now on
+    _, resp, err := dialWorker(t, url)
+    if err == nil || resp == nil || resp.StatusCode != http.StatusServiceUnavailable || resp.Header.Get("Retry-After") == "" {
+        t.Fatalf("dial while draining: err=%v resp=%v, want 503 with Retry-After", err, resp)
+    }
+}
+
+func TestDrainTimesOutSilentWorker(t *testing.T) {
+    s, st, url := drainServer(t, 50)
+    c, _, err := dialWorker(t, url)
+    if err != nil {
+        t.Fatal(err)
+    }
+    defer c.Close(ws.StatusNormalClosure, "")
+    waitConnected(t, s)
+    go func() {
+        // Reads, so the close handshake completes, but never answers
+        for {
+            if _, _, err := c.Read(context.Background()); err != nil {
+                return
+            }
+        }
+    }()
+
+    s.Drain(context.Background())
+    if p := drainedEvent(st); p == nil || p["acked"] != false || p["outcome"] != "timeout" {
+        t.Fatalf("worker_drained = %v, want a timeout", p)
+    }
+}
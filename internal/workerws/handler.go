@@ -1,6 +1,7 @@
 package workerws
 
 import (
+    "context"
     "encoding/json"
     "log"
     "net/http"
@@ -8,7 +9,10 @@ import (
     "time"
 
     "yuzu/agent/internal/auth"
+    "yuzu/agent/internal/bus"
     "yuzu/agent/internal/config"
+    "yuzu/agent/internal/errdefs"
+    "yuzu/agent/internal/floor"
     "yuzu/agent/internal/store"
 
     ws "nhooyr.io/websocket"
@@ -19,21 +23,45 @@ type Message struct {
     TsMs        int64          `json:"ts_ms"`
     SessionID   string         `json:"session_id"`
     Seq         int64          `json:"seq"`
+    Epoch       int64          `json:"epoch,omitempty"` // worker connection epoch; seq restarts with it
     CommandID   string         `json:"command_id,omitempty"`
     UtteranceID string         `json:"utterance_id,omitempty"`
     Payload     map[string]any `json:"payload,omitempty"`
 }
 
+// Worker connection events on the bus (see package bus).
+var (
+    TopicMessage      = bus.NewTopic[Message]("worker.message")
+    TopicDisconnected = bus.NewTopic[Disconnect]("worker.disconnected")
+)
+
+// Disconnect is published on TopicDisconnected when a worker's socket has
+// closed. By then a reconnected worker may hold the session; Conn tells
+// the two apart (see Registry.Superseded).
+type Disconnect struct {
+    Conn uint64 // registry generation of the closed connection
+}
+
 type Server struct {
     Cfg      config.Config
-    Store    *store.Store
+    Store    store.Store
     Reg      *Registry
-    OnMessage func(sessionID string, msg Message)
-    lastSeq  map[string]int64
+    // Bus gets every accepted worker message on TopicMessage, and
+    // TopicDisconnected after a worker's socket has closed
+    Bus *bus.Bus
+
+    skew *skewBook // clock skew per session (see skew.go)
+    drainState     // shutdown (see drain.go)
+    sttRelayState  // STT sidecar connection (see sttrelay.go)
 }
 
-func NewServer(cfg config.Config, st *store.Store, reg *Registry) *Server {
-    return &Server{Cfg: cfg, Store: st, Reg: reg, lastSeq: make(map[string]int64)}
+func NewServer(cfg config.Config, st store.Store, reg *Registry) *Server {
+    s := &Server{Cfg: cfg, Store: st, Reg: reg, skew: newSkewBook()}
+    s.acks = make(map[string]drainWaiter)
+    reg.mu.Lock()
+    reg.sent = s.skew.sent
+    reg.mu.Unlock()
+    return s
 }
 
 func (s *Server) HandleWorkerWS(w http.ResponseWriter, r *http.Request) {
@@ -50,16 +78,21 @@ func (s *Server) HandleWorkerWS(w http.ResponseWriter, r *http.Request) {
     // Auth header
     authz := r.Header.Get("Authorization")
     if !strings.HasPrefix(authz, "Bearer ") {
-        http.Error(w, "missing bearer token", http.StatusUnauthorized)
+        errdefs.WriteHTTP(w, &errdefs.AuthError{Reason: "missing bearer token"})
         return
     }
     token := strings.TrimPrefix(authz, "Bearer ")
     if s.Cfg.Worker.TokenSecret == "" {
-        http.Error(w, "worker auth not configured", http.StatusUnauthorized)
+        errdefs.WriteHTTP(w, &errdefs.ConfigError{Key: "WORKER_TOKEN_SECRET", Msg: "worker auth not configured"})
         return
     }
     if _, _, err := auth.ValidateWorkerToken(s.Cfg.Worker.TokenSecret, token, sessionID, time.Now(), s.Cfg.Worker.TokenSkewSecs); err != nil {
-        http.Error(w, "invalid token", http.StatusUnauthorized)
+        errdefs.WriteHTTP(w, &errdefs.AuthError{Reason: "invalid token", Err: err})
+        return
+    }
+
+    if s.isDraining() {
+        refuseDraining(w)
         return
     }
 
@@ -68,14 +101,18 @@ func (s *Server) HandleWorkerWS(w http.ResponseWriter, r *http.Request) {
         log.Printf("ws accept: %v", err)
         return
     }
-    replaced := s.Reg.Replace(sessionID, c)
+    gen, replaced := s.Reg.Replace(sessionID, c)
+    s.skew.open(sessionID)
     if replaced {
         s.Store.AppendEvent(sessionID, "worker_replaced", nil)
     }
     s.Store.AppendEvent(sessionID, "worker_connected", nil)
-    s.lastSeq[sessionID] = 0
+    var seqs seqTracker
 
+    limiter := newConnLimiter(parseRateLimits(s.Cfg.Worker.RateLimits), s.Cfg.Worker.RateLimitMaxDrops)
+    closeCode, closeReason := ws.StatusNormalClosure, "done"
     ctx := r.Context()
+    relay := s.newSTTRelay(ctx, sessionID)
     for {
         typ, data, err := c.Read(ctx)
         if err != nil {
@@ -84,9 +121,37 @@ func (s *Server) HandleWorkerWS(w http.ResponseWriter, r *http.Request) {
         if typ != ws.MessageText && typ != ws.MessageBinary {
             continue
         }
+        if relay != nil && typ == ws.MessageBinary {
+            // Mic audio for the STT sidecar (see sttrelay.go)
+            if v := s.rateLimit(sessionID, limiter, relayAudioType); v == rateDisconnect {
+                closeCode, closeReason = ws.StatusPolicyViolation, "rate limit exceeded"
+                break
+            } else if v == rateDrop {
+                continue
+            }
+            relay.audio(data)
+            continue
+        }
         var msg Message
-        if err := json.Unmarshal(data, &msg); err != nil {
-            s.Store.AppendEvent(sessionID, "worker_msg_invalid", map[string]any{"error": err.Error()})
+        decodeErr := json.Unmarshal(data, &msg)
+        limitType := msg.Type
+        if decodeErr != nil {
+            // Charged to the default class before the rejection, so a flood
+            // of garbage frames is limited like any other
+            limitType = ""
+        }
+        if v := s.rateLimit(sessionID, limiter, limitType); v == rateDisconnect {
+            closeCode, closeReason = ws.StatusPolicyViolation, "rate limit exceeded"
+            break
+        } else if v == rateDrop {
+            continue
+        }
+        if decodeErr != nil {
+            s.rejectMessage(ctx, sessionID, msg, &ValidationError{Reason: "decode_error", Detail: decodeErr.Error()})
+            continue
+        }
+        if verr := Validate(sessionID, msg); verr != nil {
+            s.rejectMessage(ctx, sessionID, msg, verr)
             continue
         }
         payload := msg.Payload
@@ -95,15 +160,29 @@ func (s *Server) HandleWorkerWS(w http.ResponseWriter, r *http.Request) {
         payload["seq"] = msg.Seq
         if msg.CommandID != "" { payload["command_id"] = msg.CommandID }
         if msg.UtteranceID != "" { payload["utterance_id"] = msg.UtteranceID }
-        s.Store.AppendEvent(sessionID, msg.Type, payload)
+        if s.skew.received(sessionID, msg, time.Now()) {
+            s.storeSkew(sessionID)
+        }
+        if msg.Type == "webrtc_stats" {
+            s.recordNetworkStats(sessionID, msg)
+        } else {
+            s.Store.AppendEvent(sessionID, msg.Type, payload)
+        }
+        s.drainAnswered(sessionID, msg)
+        if msg.Type == "worker_device" {
+            s.recordDevice(sessionID, msg)
+        }
         // Handle hello -> capture capabilities and send policy
         if msg.Type == "worker_hello" {
             // parse local_stop_capable from payload
             if v, ok := msg.Payload["local_stop_capable"].(bool); ok {
                 s.Store.SetLocalStopCapable(sessionID, v)
             }
+            s.recordDevice(sessionID, msg)
             // Send policy if configured
-            enabled := s.Cfg.Worker.LocalStopEnabled
+            // Local stop would cut the agent before an interjection could be
+            // held or a guard window applied (see floor.Strategy)
+            enabled := s.Cfg.Worker.LocalStopEnabled && s.turnStrategy(sessionID).LocalStop()
             s.Store.SetLocalStopEnabled(sessionID, enabled)
             // Respond with policy message
             out := Message{Type: "policy", TsMs: time.Now().UnixMilli(), SessionID: sessionID, Payload: map[string]any{"local_stop_enabled": enabled}}
@@ -114,17 +193,59 @@ func (s *Server) HandleWorkerWS(w http.ResponseWriter, r *http.Request) {
                 s.Store.AppendEvent(sessionID, "worker_policy_sent", map[string]any{"local_stop_enabled": enabled})
             }
         }
-        // Sequence gap detection
-        prev := s.lastSeq[sessionID]
-        if msg.Seq > prev+1 && prev != 0 {
-            s.Store.AppendEvent(sessionID, "worker_seq_gap", map[string]any{"prev": prev, "now": msg.Seq, "gap": msg.Seq - prev})
+        // Sequence gap detection within this connection's epoch (see seq.go)
+        prev, newEpoch := seqs.observe(msg)
+        if prev != 0 {
+            s.Store.AppendEvent(sessionID, "worker_seq_gap", map[string]any{"prev": prev, "now": msg.Seq, "gap": msg.Seq - prev, "epoch": msg.Epoch})
         }
-        if msg.Seq > prev { s.lastSeq[sessionID] = msg.Seq }
-        if s.OnMessage != nil {
-            s.OnMessage(sessionID, msg)
+        if newEpoch {
+            s.Store.AppendEvent(sessionID, "worker_seq_epoch", map[string]any{"epoch": msg.Epoch, "seq": msg.Seq, "msg_type": msg.Type})
         }
+        bus.Publish(s.Bus, TopicMessage, sessionID, msg)
+        if relay != nil {
+            relay.control(msg)
+        }
+    }
+    if relay != nil {
+        relay.close()
     }
-    _ = c.Close(ws.StatusNormalClosure, "done")
-    s.Reg.Remove(sessionID)
+    _ = c.Close(closeCode, closeReason)
+    s.Reg.Remove(sessionID, gen)
     s.Store.AppendEvent(sessionID, "worker_disconnected", nil)
+    if r, ok := s.storeSkew(sessionID); ok {
+        metricClockSkewReports.WithLabelValues(r.Assessment).Inc()
+        s.Store.AppendEvent(sessionID, "clock_skew_report", map[string]any{"assessment": r.Assessment, "messages": r.Messages, "round_trips": r.RoundTrips})
+    }
+    if !s.Reg.Superseded(sessionID, gen) {
+        s.skew.drop(sessionID)
+    }
+    bus.Publish(s.Bus, TopicDisconnected, sessionID, Disconnect{Conn: gen})
+}
+
+// rejectMessage records an invalid worker message and tells the worker why.
+func (s *Server) rejectMessage(ctx context.Context, sessionID string, msg Message, verr *ValidationError) {
+    metricMsgInvalid.WithLabelValues(typeLabel(msg.Type), verr.Reason).Inc()
+    info := map[string]any{"error": verr.Error(), "reason": verr.Reason, "msg_type": msg.Type, "msg_seq": msg.Seq}
+    if verr.Field != "" { info["field"] = verr.Field }
+    s.Store.AppendEvent(sessionID, "worker_msg_invalid", info)
+    out := Message{Type: "error", TsMs: time.Now().UnixMilli(), SessionID: sessionID, Payload: map[string]any{
+        "reason":      verr.Reason,
+        "field":       verr.Field,
+        "message":     verr.Error(),
+        "in_reply_to": map[string]any{"type": msg.Type, "seq": msg.Seq},
+    }}
+    if err := s.Reg.SendJSON(ctx, sessionID, out); err != nil {
+        log.Printf("ws error reply session=%s: %v", sessionID, err)
+    }
+}
+
+// turnStrategy is the session's turn policy, or the deployment's.
+func (s *Server) turnStrategy(sessionID string) floor.Strategy {
+    def := floor.Policy{Interject: s.Cfg.Floor.InterjectionMode, MaxInterjectMs: int64(s.Cfg.Floor.MaxInterjectionMs)}
+    if sess := s.Store.GetSession(sessionID); sess != nil {
+        if st, ok := floor.StrategyFor(sess.TurnPolicy, def); ok {
+            return st
+        }
+    }
+    return def
 }
@@ -0,0 +1,67 @@
+package workerws
+
+import (
+    "github.com/prometheus/client_golang/prometheus"
+    "github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+    metricMsgInvalid = promauto.NewCounterVec(prometheus.CounterOpts{
+        Name: "workerws_msg_invalid_total",
+        Help: "Worker messages rejected by schema validation, by message type and reason",
+    }, []string{"type", "reason"})
+    metricMsgRateLimited = promauto.NewCounterVec(prometheus.CounterOpts{
+        Name: "workerws_msg_rate_limited_total",
+        Help: "Worker messages dropped by the per-connection rate limit, by message type",
+    }, []string{"type"})
+    metricRateLimitDisconnects = promauto.NewCounter(prometheus.CounterOpts{
+        Name: "workerws_rate_limit_disconnects_total",
+        Help: "Worker connections closed for sustained rate-limit abuse",
+    })
+
+    // Call quality from webrtc_stats (see netstats.go)
+    metricRTT = promauto.NewHistogram(prometheus.HistogramOpts{
+        Name:    "workerws_webrtc_rtt_ms",
+        Help:    "WebRTC round-trip time reported by workers (ms)",
+        Buckets: []float64{20, 50, 100, 150, 200, 300, 500, 1000, 2000},
+    })
+    metricJitter = promauto.NewHistogram(prometheus.HistogramOpts{
+        Name:    "workerws_webrtc_jitter_ms",
+        Help:    "WebRTC inbound audio jitter reported by workers (ms)",
+        Buckets: []float64{5, 10, 20, 30, 50, 100, 200, 500},
+    })
+    metricPacketLoss = promauto.NewHistogram(prometheus.HistogramOpts{
+        Name:    "workerws_webrtc_packet_loss_pct",
+        Help:    "WebRTC packet loss reported by workers (percent)",
+        Buckets: []float64{0.1, 0.5, 1, 2, 5, 10, 20, 50},
+    })
+
+    // Clock skew reports at worker disconnect (see skew.go)
+    metricClockSkewReports = promauto.NewCounterVec(prometheus.CounterOpts{
+        Name: "workerws_clock_skew_reports_total",
+        Help: "Clock skew reports stored when a worker disconnects, by assessment",
+    }, []string{"assessment"})
+
+    // Worker connections closed on shutdown (see drain.go)
+    metricDrains = promauto.NewCounterVec(prometheus.CounterOpts{
+        Name: "workerws_drains_total",
+        Help: "Worker connections drained on shutdown, by outcome (acked, timeout, send_error)",
+    }, []string{"outcome"})
+
+    // Mic audio relayed to the STT sidecar (see sttrelay.go)
+    metricRelayFrames = promauto.NewCounterVec(prometheus.CounterOpts{
+        Name: "workerws_stt_relay_frames_total",
+        Help: "Worker audio frames for the STT relay, by result (forwarded, not_started, oversize, invalid, queue_full, send_error)",
+    }, []string{"result"})
+    metricRelayTranscripts = promauto.NewCounterVec(prometheus.CounterOpts{
+        Name: "workerws_stt_relay_transcripts_total",
+        Help: "STT sidecar messages relayed back to workers, by kind (connected, interim, final, error)",
+    }, []string{"kind"})
+)
+
+// typeLabel bounds the type label to known message types.
+func typeLabel(t string) string {
+    if _, ok := schemas[t]; ok || t == relayAudioType { return t }
+    if t == "" { return "none" }
+    return "other"
+}
@@ -0,0 +1,35 @@
+package workerws
+
+import (
+    "time"
+
+    "yuzu/agent/internal/types"
+)
+
+// netstats.go ingests the worker's periodic WebRTC stats (webrtc_stats).
+// Samples go to the store's per-session stats buffer rather than the event
+// log, which they would otherwise crowd out at one per second, and feed the
+// call-quality histograms that alerts are built on.
+
+var netStatKeys = []string{"rtt_ms", "jitter_ms", "packet_loss_pct", "audio_level"}
+
+// parseNetworkStats reads a validated webrtc_stats message.
+func parseNetworkStats(msg Message) types.NetworkStats {
+    ns := types.NetworkStats{Ts: time.UnixMilli(msg.TsMs).UTC()}
+    dst := []**float64{&ns.RTTMs, &ns.JitterMs, &ns.PacketLossPct, &ns.AudioLevel}
+    for i, k := range netStatKeys {
+        if f, ok := msg.Payload[k].(float64); ok {
+            *dst[i] = &f
+        }
+    }
+    return ns
+}
+
+// recordNetworkStats stores a sample and observes its metrics.
+func (s *Server) recordNetworkStats(sessionID string, msg Message) {
+    ns := parseNetworkStats(msg)
+    s.Store.AppendNetworkStats(sessionID, ns)
+    if ns.RTTMs != nil { metricRTT.Observe(*ns.RTTMs) }
+    if ns.JitterMs != nil { metricJitter.Observe(*ns.JitterMs) }
+    if ns.PacketLossPct != nil { metricPacketLoss.Observe(*ns.PacketLossPct) }
+}
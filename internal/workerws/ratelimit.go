@@ -0,0 +1,154 @@
+package workerws
+
+import (
+    "strconv"
+    "strings"
+    "time"
+)
+
+// ratelimit.go caps how fast one worker connection may send each class of
+// message, so a misbehaving gateway can't flood the event store. Messages
+// over the limit are dropped and summarised in a worker_msg_rate_limited
+// event at most once a second per class. Frames that fail to decode are
+// charged to the default class before they are rejected, so they write no
+// worker_msg_invalid events past the limit. A connection that keeps dropping
+// (WORKER_WS_RATE_LIMIT_MAX_DROPS within rateAbuseWindow) is closed with a
+// policy violation.
+
+const (
+    defaultRateLimits = "vad=10,audio=100,default=100"
+    rateAbuseWindow   = 10 * time.Second
+    rateReportEvery   = time.Second
+)
+
+// rateClassOf groups message types that share a limit.
+func rateClassOf(msgType string) string {
+    switch msgType {
+    case "vad_start", "vad_end":
+        return "vad"
+    case relayAudioType:
+        return "audio"
+    default:
+        return "default"
+    }
+}
+
+// parseRateLimits parses "class=per_sec,..." over the built-in defaults.
+// A rate of 0 lifts the limit for that class; bad entries are skipped.
+func parseRateLimits(v string) map[string]float64 {
+    out := map[string]float64{}
+    for _, src := range []string{defaultRateLimits, v} {
+        for _, kv := range strings.Split(src, ",") {
+            class, r, ok := strings.Cut(strings.TrimSpace(kv), "=")
+            if !ok || class == "" { continue }
+            if f, err := strconv.ParseFloat(strings.TrimSpace(r), 64); err == nil && f >= 0 {
+                out[strings.TrimSpace(class)] = f
+            }
+        }
+    }
+    return out
+}
+
+// tokenBucket allows rate messages per second with a one-second burst.
+type tokenBucket struct {
+    rate   float64
+    tokens float64
+    last   time.Time
+}
+
+func (b *tokenBucket) allow(now time.Time) bool {
+    if b.last.IsZero() {
+        b.tokens = b.rate
+    } else if el := now.Sub(b.last).Seconds(); el > 0 {
+        b.tokens += el * b.rate
+        if b.tokens > b.rate { b.tokens = b.rate }
+    }
+    b.last = now
+    if b.tokens < 1 { return false }
+    b.tokens--
+    return true
+}
+
+// rateVerdict is what the read loop does with one message.
+type rateVerdict int
+
+const (
+    rateAllow rateVerdict = iota
+    rateDrop
+    rateDisconnect
+)
+
+// connLimiter is owned by one connection's read loop and is not safe for
+// concurrent use.
+type connLimiter struct {
+    limits   map[string]float64
+    maxDrops int // drops per rateAbuseWindow before disconnecting; 0 never
+
+    buckets     map[string]*tokenBucket
+    pending     map[string]int // drops not yet reported, by class
+    lastReport  map[string]time.Time
+    windowStart time.Time
+    windowDrops int
+}
+
+func newConnLimiter(limits map[string]float64, maxDrops int) *connLimiter {
+    return &connLimiter{
+        limits:     limits,
+        maxDrops:   maxDrops,
+        buckets:    map[string]*tokenBucket{},
+        pending:    map[string]int{},
+        lastReport: map[string]time.Time{},
+    }
+}
+
+// rateLimit charges one message of msgType to l, counting and reporting a
+// drop; the caller drops the message or closes the connection as told.
+func (s *Server) rateLimit(sessionID string, l *connLimiter, msgType string) rateVerdict {
+    v := l.check(msgType, time.Now())
+    if v == rateAllow { return v }
+    metricMsgRateLimited.WithLabelValues(typeLabel(msgType)).Inc()
+    if v == rateDisconnect {
+        metricRateLimitDisconnects.Inc()
+        s.Store.AppendEvent(sessionID, "worker_rate_limit_disconnect", map[string]any{"msg_type": msgType, "window_drops": l.windowDrops})
+        return v
+    }
+    if n := l.report(msgType, time.Now()); n > 0 {
+        class := rateClassOf(msgType)
+        s.Store.AppendEvent(sessionID, "worker_msg_rate_limited", map[string]any{"class": class, "msg_type": msgType, "dropped": n, "limit_per_sec": l.limits[class]})
+    }
+    return v
+}
+
+// check charges one message of msgType against its class.
+func (l *connLimiter) check(msgType string, now time.Time) rateVerdict {
+    class := rateClassOf(msgType)
+    rate := l.limits[class]
+    if rate <= 0 { return rateAllow }
+    b := l.buckets[class]
+    if b == nil {
+        b = &tokenBucket{rate: rate}
+        l.buckets[class] = b
+    }
+    if b.allow(now) { return rateAllow }
+
+    l.pending[class]++
+    if now.Sub(l.windowStart) >= rateAbuseWindow {
+        l.windowStart, l.windowDrops = now, 0
+    }
+    l.windowDrops++
+    if l.maxDrops > 0 && l.windowDrops >= l.maxDrops {
+        return rateDisconnect
+    }
+    return rateDrop
+}
+
+// report returns the drops to summarise for msgType's class, or 0 when the
+// class reported less than rateReportEvery ago.
+func (l *connLimiter) report(msgType string, now time.Time) int {
+    class := rateClassOf(msgType)
+    n := l.pending[class]
+    if n == 0 || now.Sub(l.lastReport[class]) < rateReportEvery { return 0 }
+    l.pending[class] = 0
+    l.lastReport[class] = now
+    return n
+}
@@ -0,0 +1,106 @@
+package workerws
+
+import (
+    "context"
+    "testing"
+    "time"
+
+    ws "nhooyr.io/websocket"
+)
+
+func TestParseRateLimits(t *testing.T) {
+    got := parseRateLimits(" vad = 5, bad, tts=-1, default=0")
+    if got["vad"] != 5 || got["default"] != 0 || got["audio"] != 100 || len(got) != 3 {
+        t.Errorf("parseRateLimits = %v", got)
+    }
+    if got := parseRateLimits(""); got["vad"] != 10 || got["default"] != 100 {
+        t.Errorf("defaults = %v", got)
+    }
+}
+
+func TestConnLimiterDropsReportsAndDisconnects(t *testing.T) {
+    l := newConnLimiter(map[string]float64{"vad": 2, "default": 0}, 5)
+    now := time.Unix(1000, 0)
+
+    for i := 0; i < 2; i++ {
+        if v := l.check("vad_start", now); v != rateAllow {
+            t.Fatalf("message %d within burst = %v", i, v)
+        }
+    }
+    if v := l.check("vad_end", now); v != rateDrop {
+        t.Fatalf("over the limit = %v, want drop", v)
+    }
+    if n := l.report("vad_end", now); n != 1 {
+        t.Errorf("first report = %d, want 1", n)
+    }
+    l.check("vad_end", now)
+    if n := l.report("vad_end", now); n != 0 {
+        t.Errorf("report within a second = %d, want 0", n)
+    }
+    // Unlimited classes never drop
+    for i := 0; i < 50; i++ {
+        if l.check("tts_started", now) != rateAllow {
+            t.Fatal("default=0 should not limit")
+        }
+    }
+
+    // Tokens refill with time
+    now = now.Add(time.Second)
+    if l.check("vad_start", now) != rateAllow {
+        t.Error("bucket did not refill")
+    }
+    if n := l.report("vad_start", now); n != 1 {
+        t.Errorf("deferred report = %d, want 1", n)
+    }
+
+    // Sustained abuse: the fifth drop in the window disconnects
+    l.check("vad_start", now)
+    var v rateVerdict
+    for i := 0; i < 5 && v != rateDisconnect; i++ {
+        v = l.check("vad_start", now)
+    }
+    if v != rateDisconnect {
+        t.Errorf("verdict after repeated drops = %v, want disconnect", v)
+    }
+}
+
+func TestGarbageFramesAreRateLimited(t *testing.T) {
+    s, st, url := drainServer(t, 1000)
+    s.Cfg.Worker.RateLimits = "default=5"
+    s.Cfg.Worker.RateLimitMaxDrops = 20
+    c, _, err := dialWorker(t, url)
+    if err != nil {
+        t.Fatal(err)
+    }
+    defer c.Close(ws.StatusNormalClosure, "")
+    waitConnected(t, s)
+    ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+    defer cancel()
+    go func() {
+        // Drain the error replies until the server closes the connection
+        for {
+            if _, _, err := c.Read(ctx); err != nil {
+                return
+            }
+        }
+    }()
+    for i := 0; i < 50; i++ {
+        if c.Write(ctx, ws.MessageText, []byte("{not json")) != nil {
+            break
+        }
+    }
+
+    counts := map[string]int{}
+    for deadline := time.Now().Add(2 * time.Second); counts["worker_disconnected"] == 0; time.Sleep(10 * time.Millisecond) {
+        if time.Now().After(deadline) {
+            t.Fatal("worker was never disconnected")
+        }
+        counts = map[string]int{}
+        for _, ev := range st.ListEvents("s1") {
+            counts[ev.Type]++
+        }
+    }
+    if counts["worker_msg_invalid"] != 5 || counts["worker_rate_limit_disconnect"] != 1 {
+        t.Errorf("events = %v, want 5 rejections then a rate limit disconnect", counts)
+    }
+}
@@ -4,27 +4,37 @@ import (
     "context"
     "encoding/json"
     "sync"
+    "time"
     ws "nhooyr.io/websocket"
 )
 
-// Registry keeps at most one worker connection per session.
+// Registry keeps at most one worker connection per session. Each
+// connection gets a generation, increasing across the registry, so a
+// closing connection can tell whether a newer one has taken its session
+// over.
 type Registry struct {
     mu    sync.Mutex
     conns map[string]*ws.Conn
+    gens  map[string]uint64 // generation of each session's connection
+    gen   uint64
+    // sent times commands for the skew report (see skew.go); set by NewServer
+    sent func(sessionID string, msg Message, now time.Time)
 }
 
-func NewRegistry() *Registry { return &Registry{conns: make(map[string]*ws.Conn)} }
+func NewRegistry() *Registry { return &Registry{conns: make(map[string]*ws.Conn), gens: make(map[string]uint64)} }
 
-// Replace sets the connection for a session and closes the previous one if present.
-func (r *Registry) Replace(sessionID string, c *ws.Conn) (prevClosed bool) {
+// Replace sets the connection for a session and closes the previous one if
+// present. It returns the new connection's generation.
+func (r *Registry) Replace(sessionID string, c *ws.Conn) (gen uint64, prevClosed bool) {
     r.mu.Lock()
     defer r.mu.Unlock()
     if old, ok := r.conns[sessionID]; ok && old != nil {
         _ = old.Close(ws.StatusNormalClosure, "replaced")
         prevClosed = true
     }
-    r.conns[sessionID] = c
-    return
+    r.gen++
+    r.conns[sessionID], r.gens[sessionID] = c, r.gen
+    return r.gen, prevClosed
 }
 
 func (r *Registry) Get(sessionID string) *ws.Conn {
@@ -32,17 +42,51 @@ func (r *Registry) Get(sessionID string) *ws.Conn {
     return r.conns[sessionID]
 }
 
-func (r *Registry) Remove(sessionID string) {
+// Remove drops the session's connection of generation gen; a newer
+// connection that replaced it stays.
+func (r *Registry) Remove(sessionID string, gen uint64) {
     r.mu.Lock(); defer r.mu.Unlock()
-    delete(r.conns, sessionID)
+    if r.gens[sessionID] == gen {
+        delete(r.conns, sessionID)
+        delete(r.gens, sessionID)
+    }
+}
+
+// Superseded reports whether a connection newer than generation gen holds
+// the session.
+func (r *Registry) Superseded(sessionID string, gen uint64) bool {
+    r.mu.Lock(); defer r.mu.Unlock()
+    cur, ok := r.gens[sessionID]
+    return ok && cur != gen
+}
+
+// Sessions returns the sessions with a connection.
+func (r *Registry) Sessions() []string {
+    r.mu.Lock(); defer r.mu.Unlock()
+    ids := make([]string, 0, len(r.conns))
+    for id := range r.conns {
+        ids = append(ids, id)
+    }
+    return ids
+}
+
+// Close closes a session's connection with code; its read loop then
+// removes it.
+func (r *Registry) Close(sessionID string, code ws.StatusCode, reason string) {
+    if c := r.Get(sessionID); c != nil {
+        _ = c.Close(code, reason)
+    }
 }
 
 // Send JSON helper with context.
 func (r *Registry) SendJSON(ctx context.Context, sessionID string, v any) error {
     r.mu.Lock()
-    c := r.conns[sessionID]
+    c, sent := r.conns[sessionID], r.sent
     r.mu.Unlock()
     if c == nil { return nil }
+    if msg, ok := v.(Message); ok && sent != nil {
+        sent(sessionID, msg, time.Now())
+    }
     return c.Write(ctx, ws.MessageText, mustJSON(v))
 }
 
@@ -0,0 +1,28 @@
+package workerws
+
+// seq.go detects lost worker messages. seq counts per connection, so a
+// worker that reconnects or restarts begins again at 1; gaps are only
+// meaningful within one connection epoch. An epoch starts on every accepted
+// connection, on worker_hello, and whenever the worker declares a new
+// epoch in the envelope (gateways set it once per connection).
+
+// seqTracker is owned by one connection's read loop.
+type seqTracker struct {
+    epoch int64 // worker-declared epoch; 0 until one is seen
+    last  int64
+}
+
+// observe records msg. It returns the previous seq of the epoch when msg
+// skipped ahead of it (0 otherwise), and whether msg started a new epoch.
+func (t *seqTracker) observe(msg Message) (gapAfter int64, newEpoch bool) {
+    if msg.Type == "worker_hello" || (msg.Epoch != 0 && msg.Epoch != t.epoch) {
+        newEpoch = t.last != 0
+        t.epoch, t.last = msg.Epoch, msg.Seq
+        return 0, newEpoch
+    }
+    if t.last != 0 && msg.Seq > t.last+1 {
+        gapAfter = t.last
+    }
+    if msg.Seq > t.last { t.last = msg.Seq }
+    return gapAfter, false
+}
@@ -0,0 +1,34 @@
+package workerws
+
+import "testing"
+
+func TestSeqTrackerEpochs(t *testing.T) {
+    var tr seqTracker
+    step := func(typ string, epoch, seq int64) (int64, bool) {
+        return tr.observe(Message{Type: typ, Epoch: epoch, Seq: seq})
+    }
+    if prev, ne := step("worker_hello", 0, 1); prev != 0 || ne {
+        t.Fatalf("first hello = %d,%v", prev, ne)
+    }
+    step("vad_start", 0, 2)
+    if prev, _ := step("vad_end", 0, 5); prev != 2 {
+        t.Errorf("gap within a connection: prev = %d, want 2", prev)
+    }
+
+    // Worker restarted and says hello again at seq 1: a new epoch, no gap
+    if prev, ne := step("worker_hello", 0, 1); prev != 0 || !ne {
+        t.Errorf("hello after restart = %d,%v, want new epoch", prev, ne)
+    }
+    if prev, _ := step("vad_start", 0, 2); prev != 0 {
+        t.Errorf("spurious gap after restart, prev = %d", prev)
+    }
+
+    // A declared epoch change resets without a hello
+    step("vad_end", 7, 3)
+    if prev, ne := step("vad_start", 8, 1); prev != 0 || !ne {
+        t.Errorf("epoch change = %d,%v, want new epoch", prev, ne)
+    }
+    if prev, _ := step("vad_end", 8, 3); prev != 1 {
+        t.Errorf("gap in epoch 8: prev = %d, want 1", prev)
+    }
+}
@@ -0,0 +1,238 @@
+package workerws
+
+import (
+    "math"
+    "sort"
+    "sync"
+    "time"
+
+    "yuzu/agent/internal/types"
+)
+
+// skew.go builds each session's clock skew report (types.ClockSkew). Every
+// worker message is timed against its ts_ms: that delay is network latency
+// plus the worker's clock offset, so on its own it can't tell a slow network
+// from a wrong clock. Commands carrying a command_id are timed too, and the
+// worker's cmd_ack closes a round trip measured on our clock alone; as in
+// NTP, the fastest round trip bounds the offset to half its length. The
+// lowest delay of each minute tracks drift, since queueing only adds delay.
+//
+// A session's tracker lives as long as its worker connection: it is opened
+// when the worker connects (a reconnect carries on with it) and dropped
+// when that connection closes, so sessions the store has since evicted
+// don't keep one. The report is stored on the session when its worker
+// disconnects and every skewStoreEvery messages before that, and served
+// with the session and its network stats. Without a round trip the offset
+// can't be told from latency, so the assessment is "unknown" unless the
+// delays alone show a problem. Reports at disconnect are counted in
+// workerws_clock_skew_reports_total{assessment}.
+
+const (
+    skewStoreEvery    = 100
+    maxSkewSamples    = 2000 // delays kept for percentiles
+    maxSkewPending    = 64   // commands awaiting an ack
+    skewMinDriftMins  = 3
+    skewOffsetMs      = 100 // larger offsets count as a clock problem
+    skewDriftMsPerMin = 5
+    skewJitterMs      = 30
+)
+
+// skewTracker accumulates one session's measurements.
+type skewTracker struct {
+    from, last time.Time
+    messages   int
+    delays     []float64 // newest maxSkewSamples
+    sum, min, max float64
+    prevDelay  float64
+    jitterSum  float64
+    minutes    []float64 // lowest delay per minute since from
+
+    pending    map[string]time.Time // command_id -> sent
+    rtts       []float64
+    bestRTT    float64
+    bestOffset float64
+}
+
+// skewBook holds the trackers of sessions with a worker. Sends come from
+// other goroutines than the read loop, hence the lock.
+type skewBook struct {
+    mu       sync.Mutex
+    sessions map[string]*skewTracker
+}
+
+func newSkewBook() *skewBook { return &skewBook{sessions: make(map[string]*skewTracker)} }
+
+// open starts timing the session's worker, keeping any tracker it has.
+func (b *skewBook) open(sessionID string) {
+    b.mu.Lock()
+    defer b.mu.Unlock()
+    if b.sessions[sessionID] == nil {
+        b.sessions[sessionID] = &skewTracker{pending: make(map[string]time.Time)}
+    }
+}
+
+// drop forgets the session's tracker.
+func (b *skewBook) drop(sessionID string) {
+    b.mu.Lock()
+    defer b.mu.Unlock()
+    delete(b.sessions, sessionID)
+}
+
+// sent notes a command sent to the worker at now.
+func (b *skewBook) sent(sessionID string, msg Message, now time.Time) {
+    if msg.CommandID == "" {
+        return
+    }
+    b.mu.Lock()
+    defer b.mu.Unlock()
+    t := b.sessions[sessionID]
+    if t == nil {
+        return // sent as the worker disconnected
+    }
+    if len(t.pending) >= maxSkewPending {
+        // Unacked commands would pile up forever; start over
+        clear(t.pending)
+    }
+    t.pending[msg.CommandID] = now
+}
+
+// received times a worker message that arrived at now, and reports
+// whether the session's report is due to be stored.
+func (b *skewBook) received(sessionID string, msg Message, now time.Time) bool {
+    if msg.TsMs <= 0 {
+        return false
+    }
+    b.mu.Lock()
+    defer b.mu.Unlock()
+    t := b.sessions[sessionID]
+    if t == nil {
+        return false
+    }
+    worker := time.UnixMilli(msg.TsMs)
+    d := float64(now.Sub(worker).Milliseconds())
+    if t.messages == 0 {
+        t.from, t.min, t.max = now, d, d
+    } else {
+        t.jitterSum += math.Abs(d - t.prevDelay)
+    }
+    t.messages++
+    t.last, t.prevDelay = now, d
+    t.sum += d
+    t.min, t.max = math.Min(t.min, d), math.Max(t.max, d)
+    if len(t.delays) == maxSkewSamples {
+        t.delays = t.delays[1:]
+    }
+    t.delays = append(t.delays, d)
+    if m := int(now.Sub(t.from) / time.Minute); m < len(t.minutes) {
+        t.minutes[m] = math.Min(t.minutes[m], d)
+    } else {
+        for len(t.minutes) < m {
+            t.minutes = append(t.minutes, math.NaN()) // a minute without messages
+        }
+        t.minutes = append(t.minutes, d)
+    }
+
+    if sent, ok := t.pending[msg.CommandID]; ok && msg.CommandID != "" {
+        delete(t.pending, msg.CommandID)
+        rtt := float64(now.Sub(sent).Milliseconds())
+        t.rtts = append(t.rtts, rtt)
+        if len(t.rtts) == 1 || rtt < t.bestRTT {
+            // Worker time minus the midpoint of the round trip
+            t.bestRTT = rtt
+            t.bestOffset = float64(worker.Sub(sent).Milliseconds()) - rtt/2
+        }
+    }
+    return t.messages%skewStoreEvery == 0
+}
+
+// report returns the session's report; ok is false when nothing was timed.
+func (b *skewBook) report(sessionID string) (types.ClockSkew, bool) {
+    b.mu.Lock()
+    defer b.mu.Unlock()
+    t := b.sessions[sessionID]
+    if t == nil || t.messages == 0 {
+        return types.ClockSkew{}, false
+    }
+    r := types.ClockSkew{
+        From:       t.from.UTC(),
+        Until:      t.last.UTC(),
+        Messages:   t.messages,
+        DelayMs:    skewStats(t.delays, t.min, t.sum/float64(t.messages), t.max),
+        RoundTrips: len(t.rtts),
+    }
+    if t.messages > 1 {
+        r.JitterMs = t.jitterSum / float64(t.messages-1)
+    }
+    if drift, ok := driftPerMinute(t.minutes); ok {
+        r.DriftMsPerMin = &drift
+    }
+    if len(t.rtts) > 0 {
+        rtt := skewStats(t.rtts, math.NaN(), math.NaN(), math.NaN())
+        offset, bound := t.bestOffset, t.bestRTT/2
+        r.RTTMs, r.OffsetMs, r.OffsetErrorMs = &rtt, &offset, &bound
+    }
+    r.Assessment = assessSkew(r)
+    return r, true
+}
+
+// assessSkew names the likeliest problem in r. A round trip pins the offset
+// down; without one, a negative delay still proves the worker runs ahead,
+// but "ok" can't be claimed.
+func assessSkew(r types.ClockSkew) string {
+    switch {
+    case r.OffsetMs != nil && math.Abs(*r.OffsetMs)-*r.OffsetErrorMs > skewOffsetMs,
+        r.OffsetMs == nil && r.DelayMs.Min < -skewOffsetMs:
+        return "clock_offset"
+    case r.DriftMsPerMin != nil && math.Abs(*r.DriftMsPerMin) >= skewDriftMsPerMin:
+        return "clock_drift"
+    case r.JitterMs >= skewJitterMs:
+        return "network_jitter"
+    case r.OffsetMs == nil:
+        return "unknown"
+    }
+    return "ok"
+}
+
+// skewStats summarizes xs; NaN min, avg or max are taken from xs.
+func skewStats(xs []float64, min, avg, max float64) types.SkewStats {
+    sorted := append([]float64(nil), xs...)
+    sort.Float64s(sorted)
+    if math.IsNaN(avg) {
+        avg = 0
+        for _, x := range sorted {
+            avg += x
+        }
+        avg /= float64(len(sorted))
+    }
+    if math.IsNaN(min) {
+        min, max = sorted[0], sorted[len(sorted)-1]
+    }
+    return types.SkewStats{Min: min, Avg: avg, P95: sorted[(len(sorted)*95+99)/100-1], Max: max}
+}
+
+// driftPerMinute fits a line through the per-minute lowest delays and
+// returns its slope.
+func driftPerMinute(minutes []float64) (float64, bool) {
+    var n, sx, sy, sxx, sxy float64
+    for i, y := range minutes {
+        if math.IsNaN(y) {
+            continue
+        }
+        x := float64(i)
+        n++
+        sx, sy, sxx, sxy = sx+x, sy+y, sxx+x*x, sxy+x*y
+    }
+    if n < skewMinDriftMins || len(minutes) < skewMinDriftMins {
+        return 0, false
+    }
+    return (n*sxy - sx*sy) / (n*sxx - sx*sx), true
+}
+
+// storeSkew saves the session's current report on it.
+func (s *Server) storeSkew(sessionID string) (types.ClockSkew, bool) {
+    r, ok := s.skew.report(sessionID)
+    if ok {
+        s.Store.SetClockSkew(sessionID, r)
+    }
+    return r, ok
+}
@@ -0,0 +1,148 @@
+package workerws
+
+import (
+    "context"
+    "encoding/json"
+    "math"
+    "testing"
+    "time"
+
+    ws "nhooyr.io/websocket"
+)
+
+func TestSkewBookSeparatesOffsetFromLatency(t *testing.T) {
+    b := newSkewBook()
+    b.open("s1")
+    start := time.Unix(1000, 0)
+    // The worker's clock runs 300ms behind ours over a 40ms one-way path
+    const offset, oneWay = -300 * time.Millisecond, 40 * time.Millisecond
+    for i := 0; i < 10; i++ {
+        sent := start.Add(time.Duration(i) * time.Second)
+        id := "c" + string(rune('0'+i))
+        b.sent("s1", Message{Type: "barge_in", CommandID: id}, sent)
+        acked := sent.Add(oneWay).Add(offset)
+        b.received("s1", Message{Type: "cmd_ack", CommandID: id, TsMs: acked.UnixMilli()}, sent.Add(2*oneWay))
+    }
+    r, ok := b.report("s1")
+    if !ok || r.Messages != 10 || r.RoundTrips != 10 {
+        t.Fatalf("report = %+v, %v", r, ok)
+    }
+    if r.DelayMs.Min != 340 || r.RTTMs == nil || r.RTTMs.Max != 80 {
+        t.Errorf("delay = %+v, rtt = %+v", r.DelayMs, r.RTTMs)
+    }
+    if *r.OffsetMs != -300 || *r.OffsetErrorMs != 40 {
+        t.Errorf("offset = %v ± %v, want -300 ± 40", *r.OffsetMs, *r.OffsetErrorMs)
+    }
+    if r.Assessment != "clock_offset" {
+        t.Errorf("assessment = %q", r.Assessment)
+    }
+    if _, ok := b.report("other"); ok {
+        t.Error("a session without messages should have no report")
+    }
+}
+
+func TestSkewBookDriftAndStoreCadence(t *testing.T) {
+    b := newSkewBook()
+    b.open("s1")
+    start := time.Unix(1000, 0)
+    due := 0
+    // Five minutes of messages every 3s; the worker's clock loses 10ms a minute
+    for i := 0; i < 100; i++ {
+        now := start.Add(time.Duration(i) * 3 * time.Second)
+        lag := time.Duration(float64(now.Sub(start)) / float64(time.Minute) * 10 * float64(time.Millisecond))
+        worker := now.Add(-20 * time.Millisecond).Add(-lag)
+        if b.received("s1", Message{Type: "vad_start", TsMs: worker.UnixMilli()}, now) {
+            due++
+        }
+    }
+    if due != 1 {
+        t.Errorf("report due %d times in %d messages, want 1", due, skewStoreEvery)
+    }
+    r, _ := b.report("s1")
+    if r.DriftMsPerMin == nil || math.Abs(*r.DriftMsPerMin-10) > 1 {
+        t.Fatalf("drift = %v, want about 10ms/min", r.DriftMsPerMin)
+    }
+    if r.Assessment != "clock_drift" {
+        t.Errorf("assessment = %q", r.Assessment)
+    }
+    b.drop("s1")
+    if _, ok := b.report("s1"); ok {
+        t.Error("dropped session still reported")
+    }
+    // Nothing is timed for a session without a connection
+    b.received("s1", Message{Type: "vad_start", TsMs: start.UnixMilli()}, start)
+    b.sent("s1", Message{Type: "barge_in", CommandID: "c1"}, start)
+    if len(b.sessions) != 0 {
+        t.Errorf("trackers = %v", b.sessions)
+    }
+}
+
+func TestAssessSkewJitter(t *testing.T) {
+    b := newSkewBook()
+    b.open("s1")
+    now := time.Unix(1000, 0)
+    for i, d := range []int{20, 90, 25, 100, 30} {
+        at := now.Add(time.Duration(i) * time.Second)
+        b.received("s1", Message{Type: "vad_end", TsMs: at.Add(-time.Duration(d) * time.Millisecond).UnixMilli()}, at)
+    }
+    if r, _ := b.report("s1"); r.Assessment != "network_jitter" || r.DelayMs.Max != 100 {
+        t.Errorf("report = %+v", r)
+    }
+}
+
+func TestAssessSkewUnknownWithoutRoundTrips(t *testing.T) {
+    b := newSkewBook()
+    b.open("s1")
+    now := time.Unix(1000, 0)
+    for i := 0; i < 5; i++ {
+        at := now.Add(time.Duration(i) * time.Second)
+        b.received("s1", Message{Type: "vad_end", TsMs: at.Add(-20 * time.Millisecond).UnixMilli()}, at)
+    }
+    if r, _ := b.report("s1"); r.Assessment != "unknown" || r.RoundTrips != 0 {
+        t.Errorf("report without round trips = %+v", r)
+    }
+    // An acked command settles it
+    b.sent("s1", Message{Type: "barge_in", CommandID: "c1"}, now.Add(5*time.Second))
+    b.received("s1", Message{Type: "cmd_ack", CommandID: "c1", TsMs: now.Add(5*time.Second + 10*time.Millisecond).UnixMilli()}, now.Add(5*time.Second+20*time.Millisecond))
+    if r, _ := b.report("s1"); r.Assessment != "ok" || r.RoundTrips != 1 {
+        t.Errorf("report with a round trip = %+v", r)
+    }
+}
+
+func TestSkewTrackerEndsWithTheConnection(t *testing.T) {
+    s, st, url := drainServer(t, 1000)
+    c, _, err := dialWorker(t, url)
+    if err != nil {
+        t.Fatal(err)
+    }
+    waitConnected(t, s)
+    ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+    defer cancel()
+    // The policy reply means the hello was timed
+    b, _ := json.Marshal(Message{Type: "worker_hello", SessionID: "s1", TsMs: time.Now().UnixMilli(), Seq: 1,
+        Payload: map[string]any{"version": "p1"}})
+    if err := c.Write(ctx, ws.MessageText, b); err != nil {
+        t.Fatal(err)
+    }
+    if _, _, err := c.Read(ctx); err != nil {
+        t.Fatal(err)
+    }
+    c.Close(ws.StatusNormalClosure, "")
+
+    deadline := time.Now().Add(2 * time.Second)
+    for {
+        s.skew.mu.Lock()
+        n := len(s.skew.sessions)
+        s.skew.mu.Unlock()
+        if n == 0 {
+            break
+        }
+        if time.Now().After(deadline) {
+            t.Fatalf("%d trackers left after the worker disconnected", n)
+        }
+        time.Sleep(10 * time.Millisecond)
+    }
+    if sk := st.GetSession("s1").ClockSkew; sk == nil || sk.Assessment != "unknown" {
+        t.Errorf("clock skew at disconnect = %+v", sk)
+    }
+}
@@ -0,0 +1,325 @@
+package workerws
+
+import (
+    "context"
+    "errors"
+    "io"
+    "log"
+    "strings"
+    "sync"
+    "time"
+
+    "yuzu/agent/internal/grpcmw"
+    sttpb "yuzu/agent/internal/stt/pb"
+
+    "google.golang.org/grpc"
+    "google.golang.org/grpc/codes"
+    "google.golang.org/grpc/credentials/insecure"
+    "google.golang.org/grpc/status"
+)
+
+// sttrelay.go lets a worker stream mic audio through the API server to the
+// STT sidecar instead of owning the STT connection itself. With
+// WORKER_STT_RELAY set to the sidecar's address (a socket path such as
+// /run/app/stt.sock, unix://..., or host:port; default off), binary frames
+// on /ws/worker are PCM16 16 kHz mono audio rather than JSON. stt_start
+// (utterance_id, optional payload.language) opens the sidecar stream, or
+// marks the next utterance on it, and stt_stop drains it for a final. The
+// sidecar's answers come back over the same socket as stt_connected,
+// stt_interim {text, committed_text, volatile_text}, stt_final {text,
+// word_count, speech_ms, source} and stt_error {code, message}; finals and
+// errors are also appended to the event log. A broken stream is reopened
+// by the next stt_start.
+//
+// Frames are rate-limited as stt_audio (the "audio" class of
+// WORKER_WS_RATE_LIMITS) and must be a whole number of samples of at most
+// maxRelayFrameBytes. They and the stt_start/stt_stop they interleave with
+// are queued, up to relayQueueLen, for a goroutine that sends them to the
+// sidecar, so a slow sidecar doesn't hold up the read loop; frames that find
+// the queue full are dropped. Frames are counted in
+// workerws_stt_relay_frames_total{result} and what came back in
+// workerws_stt_relay_transcripts_total{kind}.
+
+const (
+    // 16 kHz PCM16 is 32 bytes per ms; a frame is normally 20 ms (640 bytes).
+    // 512 ms stays under the WebSocket read limit (32 KiB).
+    relayBytesPerMs    = 32
+    maxRelayFrameBytes = 16 << 10
+
+    relayAudioType = "stt_audio" // rate-limit type of a binary frame
+    relayQueueLen  = 50          // a second of 20 ms frames
+    relayCloseWait = time.Second
+)
+
+// sttRelayState is embedded in Server: the sidecar connection, shared by
+// every session's stream.
+type sttRelayState struct {
+    sttMu   sync.Mutex
+    sttConn *grpc.ClientConn
+    sttDial func() (*grpc.ClientConn, error) // tests replace it
+}
+
+// relayEnabled reports whether binary frames are relayed to STT.
+func (s *Server) relayEnabled() bool {
+    v := strings.TrimSpace(s.Cfg.Worker.STTRelay)
+    return v != "" && v != "off"
+}
+
+// sttClient returns a client on the sidecar connection, dialing it once.
+func (s *Server) sttClient() (sttpb.STTClient, error) {
+    s.sttMu.Lock()
+    defer s.sttMu.Unlock()
+    if s.sttConn == nil {
+        dial := s.sttDial
+        if dial == nil {
+            dial = s.dialSTT
+        }
+        conn, err := dial()
+        if err != nil {
+            return nil, err
+        }
+        s.sttConn = conn
+    }
+    return sttpb.NewSTTClient(s.sttConn), nil
+}
+
+func (s *Server) dialSTT() (*grpc.ClientConn, error) {
+    target := strings.TrimSpace(s.Cfg.Worker.STTRelay)
+    if strings.HasPrefix(target, "/") {
+        target = "unix://" + target
+    }
+    opts := append(grpcmw.TransportFromEnv().DialOptions(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+    opts = append(opts, grpcmw.CompressionDialOptions("stt")...)
+    return grpc.NewClient(target, opts...)
+}
+
+// sttRelay is one worker connection's STT stream. audio, control and close
+// run on the connection's read loop and queue what they send; run sends it,
+// and recv reads the answers, each on its own goroutine.
+type sttRelay struct {
+    s         *Server
+    ctx       context.Context
+    sessionID string
+
+    out  chan relayMsg
+    done chan struct{} // closed when run returns
+
+    mu     sync.Mutex
+    stream sttpb.STT_SessionClient
+    cancel context.CancelFunc
+}
+
+// relayMsg is a message queued for the stream it was meant for.
+type relayMsg struct {
+    st  sttpb.STT_SessionClient
+    msg *sttpb.ClientMessage
+}
+
+// newSTTRelay returns the relay for a worker connection, or nil when the
+// relay is off.
+func (s *Server) newSTTRelay(ctx context.Context, sessionID string) *sttRelay {
+    if !s.relayEnabled() {
+        return nil
+    }
+    r := &sttRelay{s: s, ctx: ctx, sessionID: sessionID, out: make(chan relayMsg, relayQueueLen), done: make(chan struct{})}
+    go r.run()
+    return r
+}
+
+// run sends queued messages in order until close. A message for a stream
+// that has since been dropped is discarded.
+func (r *sttRelay) run() {
+    defer close(r.done)
+    for m := range r.out {
+        if r.current() != m.st {
+            continue
+        }
+        err := m.st.Send(m.msg)
+        if _, ok := m.msg.Msg.(*sttpb.ClientMessage_Audio); ok {
+            result := "forwarded"
+            if err != nil {
+                result = "send_error"
+            }
+            metricRelayFrames.WithLabelValues(result).Inc()
+        }
+        if err != nil {
+            r.drop(m.st)
+            if start := m.msg.GetStart(); start != nil {
+                r.fail(start.GetUtteranceId(), sttpb.ErrorCode_CONNECTION_FAILED, err)
+            }
+        }
+    }
+    if st := r.current(); st != nil {
+        _ = st.Send(&sttpb.ClientMessage{Msg: &sttpb.ClientMessage_Close{Close: &sttpb.SessionClose{}}})
+        _ = st.CloseSend()
+        r.drop(st)
+    }
+}
+
+// queue hands msg for st to run. Audio is dropped when the queue is full;
+// control messages wait for room.
+func (r *sttRelay) queue(st sttpb.STT_SessionClient, msg *sttpb.ClientMessage) bool {
+    m := relayMsg{st: st, msg: msg}
+    if _, ok := msg.Msg.(*sttpb.ClientMessage_Audio); ok {
+        select {
+        case r.out <- m:
+            return true
+        default:
+            return false
+        }
+    }
+    select {
+    case r.out <- m:
+        return true
+    case <-r.ctx.Done():
+        return false
+    }
+}
+
+func (r *sttRelay) current() sttpb.STT_SessionClient {
+    r.mu.Lock()
+    defer r.mu.Unlock()
+    return r.stream
+}
+
+// open starts the sidecar stream if there is none.
+func (r *sttRelay) open() (sttpb.STT_SessionClient, error) {
+    if st := r.current(); st != nil {
+        return st, nil
+    }
+    client, err := r.s.sttClient()
+    if err != nil {
+        return nil, err
+    }
+    ctx, cancel := context.WithCancel(r.ctx)
+    st, err := client.Session(ctx)
+    if err != nil {
+        cancel()
+        return nil, err
+    }
+    r.mu.Lock()
+    r.stream, r.cancel = st, cancel
+    r.mu.Unlock()
+    go r.recv(st)
+    return st, nil
+}
+
+// drop forgets st after it failed, so the next stt_start reopens.
+func (r *sttRelay) drop(st sttpb.STT_SessionClient) {
+    r.mu.Lock()
+    defer r.mu.Unlock()
+    if r.stream != st {
+        return
+    }
+    r.cancel()
+    r.stream, r.cancel = nil, nil
+}
+
+// audio queues one binary frame.
+func (r *sttRelay) audio(data []byte) {
+    st := r.current()
+    switch {
+    case len(data) > maxRelayFrameBytes:
+        metricRelayFrames.WithLabelValues("oversize").Inc()
+        return
+    case len(data) == 0 || len(data)%2 != 0:
+        metricRelayFrames.WithLabelValues("invalid").Inc()
+        return
+    case st == nil:
+        metricRelayFrames.WithLabelValues("not_started").Inc()
+        return
+    }
+    chunk := &sttpb.AudioChunk{Pcm16K: data, DurationMs: uint32(len(data) / relayBytesPerMs)}
+    if !r.queue(st, &sttpb.ClientMessage{Msg: &sttpb.ClientMessage_Audio{Audio: chunk}}) {
+        metricRelayFrames.WithLabelValues("queue_full").Inc()
+    }
+}
+
+// control handles stt_start and stt_stop; other messages are ignored.
+func (r *sttRelay) control(msg Message) {
+    switch msg.Type {
+    case "stt_start":
+        st, err := r.open()
+        if err != nil {
+            r.fail(msg.UtteranceID, sttpb.ErrorCode_CONNECTION_FAILED, err)
+            return
+        }
+        lang, _ := msg.Payload["language"].(string)
+        if lang == "" {
+            lang = "en-US"
+        }
+        start := &sttpb.ControlStart{SessionId: r.sessionID, UtteranceId: msg.UtteranceID, Language: lang, SampleRate: 16000, ProtocolVersion: "1"}
+        r.queue(st, &sttpb.ClientMessage{Msg: &sttpb.ClientMessage_Start{Start: start}})
+    case "stt_stop":
+        if st := r.current(); st != nil {
+            r.queue(st, &sttpb.ClientMessage{Msg: &sttpb.ClientMessage_Drain{Drain: &sttpb.Drain{}}})
+        }
+    }
+}
+
+// close ends the sidecar stream when the worker goes, once what is queued
+// has been sent or relayCloseWait has passed.
+func (r *sttRelay) close() {
+    close(r.out)
+    select {
+    case <-r.done:
+    case <-time.After(relayCloseWait):
+    }
+}
+
+// recv forwards what the sidecar says on st to the worker.
+func (r *sttRelay) recv(st sttpb.STT_SessionClient) {
+    for {
+        in, err := st.Recv()
+        if err != nil {
+            if !errors.Is(err, io.EOF) && status.Code(err) != codes.Canceled && r.ctx.Err() == nil {
+                r.fail("", sttpb.ErrorCode_SOCKET_CLOSED, err)
+            }
+            r.drop(st)
+            return
+        }
+        var out Message
+        switch m := in.Msg.(type) {
+        case *sttpb.ServerMessage_Connected:
+            c := m.Connected
+            out = Message{Type: "stt_connected", Payload: map[string]any{"provider": c.GetProvider(), "model": c.GetModel(), "language": c.GetLanguage(), "connect_ms": c.GetConnectMs()}}
+        case *sttpb.ServerMessage_Interim:
+            i := m.Interim
+            out = Message{Type: "stt_interim", UtteranceID: i.GetUtteranceId(), Payload: map[string]any{"text": i.GetText(), "committed_text": i.GetCommittedText(), "volatile_text": i.GetVolatileText()}}
+        case *sttpb.ServerMessage_Final:
+            f := m.Final
+            out = Message{Type: "stt_final", UtteranceID: f.GetUtteranceId(), Payload: map[string]any{"text": f.GetText(), "word_count": f.GetWordCount(), "speech_ms": f.GetSpeechMs(), "source": f.GetSource()}}
+        case *sttpb.ServerMessage_Error:
+            e := m.Error
+            out = Message{Type: "stt_error", Payload: map[string]any{"code": e.GetEnumCode().String(), "message": e.GetMessage()}}
+        default:
+            continue
+        }
+        r.send(out)
+    }
+}
+
+// fail tells the worker the relay couldn't serve it.
+func (r *sttRelay) fail(utteranceID string, code sttpb.ErrorCode, err error) {
+    log.Printf("ws stt relay session=%s: %v", r.sessionID, err)
+    r.send(Message{Type: "stt_error", UtteranceID: utteranceID, Payload: map[string]any{"code": code.String(), "message": err.Error()}})
+}
+
+func (r *sttRelay) send(out Message) {
+    out.TsMs = time.Now().UnixMilli()
+    out.SessionID = r.sessionID
+    kind := strings.TrimPrefix(out.Type, "stt_")
+    metricRelayTranscripts.WithLabelValues(kind).Inc()
+    if out.Type == "stt_final" || out.Type == "stt_error" {
+        info := map[string]any{"utterance_id": out.UtteranceID}
+        for k, v := range out.Payload {
+            info[k] = v
+        }
+        r.s.Store.AppendEvent(r.sessionID, "stt_relay_"+kind, info)
+    }
+    ctx, cancel := context.WithTimeout(r.ctx, 5*time.Second)
+    defer cancel()
+    if err := r.s.Reg.SendJSON(ctx, r.sessionID, out); err != nil && r.ctx.Err() == nil {
+        log.Printf("ws stt relay session=%s: send %s: %v", r.sessionID, out.Type, err)
+    }
+}
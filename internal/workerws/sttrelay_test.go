@@ -0,0 +1,163 @@
+package workerws
+
+import (
+    "context"
+    "encoding/json"
+    "fmt"
+    "io"
+    "net"
+    "testing"
+    "time"
+
+    sttpb "yuzu/agent/internal/stt/pb"
+
+    "github.com/prometheus/client_golang/prometheus/testutil"
+    "google.golang.org/grpc"
+    "google.golang.org/grpc/credentials/insecure"
+    ws "nhooyr.io/websocket"
+)
+
+// fakeSTT answers ControlStart with Connected and Drain with a final
+// reporting how much audio it got.
+type fakeSTT struct {
+    sttpb.UnimplementedSTTServer
+}
+
+func (fakeSTT) Session(stream sttpb.STT_SessionServer) error {
+    var utt string
+    var bytes int
+    for {
+        in, err := stream.Recv()
+        if err == io.EOF {
+            return nil
+        }
+        if err != nil {
+            return err
+        }
+        switch m := in.Msg.(type) {
+        case *sttpb.ClientMessage_Start:
+            utt = m.Start.GetUtteranceId()
+            stream.Send(&sttpb.ServerMessage{Msg: &sttpb.ServerMessage_Connected{Connected: &sttpb.Connected{Provider: "fake", Language: m.Start.GetLanguage()}}})
+        case *sttpb.ClientMessage_Audio:
+            bytes += len(m.Audio.GetPcm16K())
+            stream.Send(&sttpb.ServerMessage{Msg: &sttpb.ServerMessage_Interim{Interim: &sttpb.TranscriptInterim{UtteranceId: utt, Text: "hel"}}})
+        case *sttpb.ClientMessage_Drain:
+            stream.Send(&sttpb.ServerMessage{Msg: &sttpb.ServerMessage_Final{Final: &sttpb.TranscriptFinal{UtteranceId: utt, Text: fmt.Sprintf("heard %d bytes", bytes)}}})
+        }
+    }
+}
+
+func TestSTTRelayForwardsAudioAndTranscripts(t *testing.T) {
+    l, err := net.Listen("tcp", "127.0.0.1:0")
+    if err != nil {
+        t.Fatal(err)
+    }
+    gs := grpc.NewServer()
+    sttpb.RegisterSTTServer(gs, fakeSTT{})
+    go gs.Serve(l)
+    defer gs.Stop()
+
+    s, st, url := drainServer(t, 2000)
+    s.Cfg.Worker.STTRelay = l.Addr().String()
+    s.sttDial = func() (*grpc.ClientConn, error) {
+        return grpc.NewClient(l.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+    }
+    c, _, err := dialWorker(t, url)
+    if err != nil {
+        t.Fatal(err)
+    }
+    defer c.Close(ws.StatusNormalClosure, "")
+    ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+    defer cancel()
+
+    send := func(seq int64, typ string, payload map[string]any) {
+        b, _ := json.Marshal(Message{Type: typ, TsMs: time.Now().UnixMilli(), SessionID: "s1", Seq: seq, UtteranceID: "utt-1", Payload: payload})
+        if err := c.Write(ctx, ws.MessageText, b); err != nil {
+            t.Fatal(err)
+        }
+    }
+    send(1, "stt_start", map[string]any{"language": "de-DE"})
+    frame := make([]byte, 640)
+    for i := 0; i < 3; i++ {
+        if err := c.Write(ctx, ws.MessageBinary, frame); err != nil {
+            t.Fatal(err)
+        }
+    }
+    send(2, "stt_stop", nil)
+
+    var types []string
+    var final Message
+    for final.Type == "" {
+        _, data, err := c.Read(ctx)
+        if err != nil {
+            t.Fatalf("read: %v (got %v)", err, types)
+        }
+        var m Message
+        if err := json.Unmarshal(data, &m); err != nil {
+            t.Fatal(err)
+        }
+        types = append(types, m.Type)
+        if m.Type == "stt_connected" && m.Payload["language"] != "de-DE" {
+            t.Errorf("stt_connected = %v", m.Payload)
+        }
+        if m.Type == "stt_final" {
+            final = m
+        }
+    }
+    if types[0] != "stt_connected" || len(types) != 5 {
+        t.Fatalf("relayed %v, want connected, three interims and a final", types)
+    }
+    if final.UtteranceID != "utt-1" || final.Payload["text"] != "heard 1920 bytes" {
+        t.Fatalf("stt_final = %+v", final)
+    }
+    n := 0
+    for _, ev := range st.ListEvents("s1") {
+        if ev.Type == "stt_relay_final" {
+            n++
+        }
+    }
+    if n != 1 {
+        t.Fatalf("stt_relay_final events = %d", n)
+    }
+}
+
+func TestSTTRelayOffByDefault(t *testing.T) {
+    var s Server
+    if s.newSTTRelay(context.Background(), "s1") != nil {
+        t.Fatal("relay on with WORKER_STT_RELAY unset")
+    }
+    s.Cfg.Worker.STTRelay = "off"
+    if s.newSTTRelay(context.Background(), "s1") != nil {
+        t.Fatal("relay on with WORKER_STT_RELAY=off")
+    }
+}
+
+// stalledStream is a sidecar stream nothing is read from.
+type stalledStream struct {
+    sttpb.STT_SessionClient
+}
+
+func TestSTTRelayFramesDontBlockTheReadLoop(t *testing.T) {
+    // No run goroutine: the queue only fills
+    r := &sttRelay{ctx: context.Background(), sessionID: "s1", out: make(chan relayMsg, 2), stream: &stalledStream{}}
+    count := func(result string) float64 { return testutil.ToFloat64(metricRelayFrames.WithLabelValues(result)) }
+    invalid, oversize, full := count("invalid"), count("oversize"), count("queue_full")
+
+    r.audio(make([]byte, 641))
+    r.audio(make([]byte, maxRelayFrameBytes+2))
+    done := make(chan struct{})
+    go func() {
+        for i := 0; i < 5; i++ {
+            r.audio(make([]byte, 640))
+        }
+        close(done)
+    }()
+    select {
+    case <-done:
+    case <-time.After(2 * time.Second):
+        t.Fatal("audio blocked on a full queue")
+    }
+    if count("invalid")-invalid != 1 || count("oversize")-oversize != 1 || count("queue_full")-full != 3 || len(r.out) != 2 {
+        t.Fatalf("invalid +%v oversize +%v queue_full +%v queued %d", count("invalid")-invalid, count("oversize")-oversize, count("queue_full")-full, len(r.out))
+    }
+}
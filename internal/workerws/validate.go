@@ -0,0 +1,150 @@
+package workerws
+
+import "fmt"
+
+// ValidationError describes why a worker message was rejected. It is sent
+// back to the worker as an "error" message so gateway bugs surface at the
+// source instead of only in the backend's event log.
+type ValidationError struct {
+    Reason string // decode_error | missing_field | invalid_field | session_mismatch
+    Field  string // offending field, dotted for payload keys (e.g. "payload.source")
+    Detail string
+}
+
+func (e *ValidationError) Error() string {
+    if e.Field == "" { return fmt.Sprintf("%s: %s", e.Reason, e.Detail) }
+    return fmt.Sprintf("%s: %s: %s", e.Reason, e.Field, e.Detail)
+}
+
+// fieldRule checks one required field of a message.
+type fieldRule func(Message) *ValidationError
+
+// schemas lists required fields per worker → backend type (see
+// internal/protocol/protocol.md). Types not listed are accepted as-is for
+// forward compatibility.
+var schemas = map[string][]fieldRule{
+    "worker_hello":          {payloadString("version"), payloadOptionalBool("local_stop_capable"), helloDevice()},
+    "worker_device":         {payloadAnyOf("device"), helloDevice()},
+    "vad_start":             {payloadString("source")},
+    "vad_end":               {payloadString("source")},
+    "tts_started":           {utteranceID()},
+    "tts_first_audio":       {utteranceID()},
+    "tts_stopped":           {utteranceID(), payloadString("reason")},
+    "tts_queue_peak_frames": {utteranceID()},
+    "cmd_ack":               {commandID()},
+    "transcript_final":      {utteranceID(), payloadString("text")},
+    "agent_text":            {utteranceID(), payloadString("text")},
+    "moderation_flagged":    {payloadString("action")},
+    "turn_state":            {payloadString("to"), payloadString("trigger")},
+    "llm_status":            {payloadString("reason")},
+    "barge_in_profile_suggested": {payloadString("profile")},
+    "interview_ended":       {payloadString("reason")},
+    "stt_start":             {utteranceID()},
+    "tts_usage": {
+        payloadAnyOf("characters"),
+        payloadOptionalNumber("characters", 0, 1e9),
+        payloadOptionalNumber("audio_seconds", 0, 1e7),
+        payloadOptionalNumber("estimated_cost_usd", 0, 1e6),
+    },
+    "webrtc_stats": {
+        payloadAnyOf(netStatKeys...),
+        payloadOptionalNumber("rtt_ms", 0, 60000),
+        payloadOptionalNumber("jitter_ms", 0, 60000),
+        payloadOptionalNumber("packet_loss_pct", 0, 100),
+        payloadOptionalNumber("audio_level", 0, 1),
+    },
+}
+
+// Validate checks the envelope and the per-type required fields of msg
+// received on sessionID's connection.
+func Validate(sessionID string, msg Message) *ValidationError {
+    if msg.Type == "" {
+        return &ValidationError{Reason: "missing_field", Field: "type", Detail: "message type is required"}
+    }
+    if msg.SessionID == "" {
+        return &ValidationError{Reason: "missing_field", Field: "session_id", Detail: "session_id is required"}
+    }
+    if msg.SessionID != sessionID {
+        return &ValidationError{Reason: "session_mismatch", Field: "session_id", Detail: fmt.Sprintf("connection is for session %s", sessionID)}
+    }
+    if msg.TsMs <= 0 {
+        return &ValidationError{Reason: "missing_field", Field: "ts_ms", Detail: "ts_ms must be a positive unix millisecond timestamp"}
+    }
+    if msg.Seq <= 0 {
+        return &ValidationError{Reason: "missing_field", Field: "seq", Detail: "seq must start at 1 and increase per message"}
+    }
+    for _, r := range schemas[msg.Type] {
+        if err := r(msg); err != nil { return err }
+    }
+    return nil
+}
+
+func utteranceID() fieldRule {
+    return func(m Message) *ValidationError {
+        if m.UtteranceID == "" {
+            return &ValidationError{Reason: "missing_field", Field: "utterance_id", Detail: m.Type + " requires utterance_id"}
+        }
+        return nil
+    }
+}
+
+func commandID() fieldRule {
+    return func(m Message) *ValidationError {
+        if m.CommandID == "" {
+            return &ValidationError{Reason: "missing_field", Field: "command_id", Detail: m.Type + " requires command_id"}
+        }
+        return nil
+    }
+}
+
+func payloadString(key string) fieldRule {
+    field := "payload." + key
+    return func(m Message) *ValidationError {
+        v, ok := m.Payload[key]
+        if !ok {
+            return &ValidationError{Reason: "missing_field", Field: field, Detail: m.Type + " requires " + field}
+        }
+        if s, ok := v.(string); !ok || s == "" {
+            return &ValidationError{Reason: "invalid_field", Field: field, Detail: fmt.Sprintf("expected non-empty string, got %T", v)}
+        }
+        return nil
+    }
+}
+
+func payloadOptionalBool(key string) fieldRule {
+    field := "payload." + key
+    return func(m Message) *ValidationError {
+        v, ok := m.Payload[key]
+        if !ok { return nil }
+        if _, ok := v.(bool); !ok {
+            return &ValidationError{Reason: "invalid_field", Field: field, Detail: fmt.Sprintf("expected bool, got %T", v)}
+        }
+        return nil
+    }
+}
+
+func payloadOptionalNumber(key string, lo, hi float64) fieldRule {
+    field := "payload." + key
+    return func(m Message) *ValidationError {
+        v, ok := m.Payload[key]
+        if !ok { return nil }
+        f, ok := v.(float64)
+        if !ok {
+            return &ValidationError{Reason: "invalid_field", Field: field, Detail: fmt.Sprintf("expected number, got %T", v)}
+        }
+        if f < lo || f > hi {
+            return &ValidationError{Reason: "invalid_field", Field: field, Detail: fmt.Sprintf("%g is outside [%g, %g]", f, lo, hi)}
+        }
+        return nil
+    }
+}
+
+// payloadAnyOf requires at least one of keys.
+func payloadAnyOf(keys ...string) fieldRule {
+    return func(m Message) *ValidationError {
+        for _, k := range keys {
+            if _, ok := m.Payload[k]; ok { return nil }
+        }
+        return &ValidationError{Reason: "missing_field", Field: "payload", Detail: fmt.Sprintf("%s requires one of %v", m.Type, keys)}
+    }
+}
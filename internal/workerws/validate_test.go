@@ -0,0 +1,51 @@
+package workerws
+
+import "testing"
+
+func TestValidate(t *testing.T) {
+    ok := Message{Type: "vad_start", SessionID: "s1", TsMs: 1, Seq: 1, Payload: map[string]any{"source": "candidate_audio"}}
+    if err := Validate("s1", ok); err != nil {
+        t.Fatalf("valid message rejected: %v", err)
+    }
+
+    cases := []struct {
+        name   string
+        mut    func(m *Message)
+        reason string
+        field  string
+    }{
+        {"no type", func(m *Message) { m.Type = "" }, "missing_field", "type"},
+        {"other session", func(m *Message) { m.SessionID = "s2" }, "session_mismatch", "session_id"},
+        {"no seq", func(m *Message) { m.Seq = 0 }, "missing_field", "seq"},
+        {"no source", func(m *Message) { m.Payload = nil }, "missing_field", "payload.source"},
+        {"bad source", func(m *Message) { m.Payload = map[string]any{"source": 3.0} }, "invalid_field", "payload.source"},
+        {"stop without utterance", func(m *Message) { m.Type = "tts_stopped"; m.Payload["reason"] = "completed" }, "missing_field", "utterance_id"},
+        {"ack without command", func(m *Message) { m.Type = "cmd_ack" }, "missing_field", "command_id"},
+        {"hello bad capability", func(m *Message) { m.Type = "worker_hello"; m.Payload = map[string]any{"version": "p1", "local_stop_capable": "yes"} }, "invalid_field", "payload.local_stop_capable"},
+        {"hello device not an object", func(m *Message) { m.Type = "worker_hello"; m.Payload = map[string]any{"version": "p1", "device": "linux"} }, "invalid_field", "payload.device"},
+        {"device update without device", func(m *Message) { m.Type = "worker_device"; m.Payload = map[string]any{} }, "missing_field", "payload"},
+        {"hello device bad aec", func(m *Message) { m.Type = "worker_hello"; m.Payload = map[string]any{"version": "p1", "device": map[string]any{"aec_enabled": "on"}} }, "invalid_field", "payload.device.aec_enabled"},
+        {"empty stats", func(m *Message) { m.Type = "webrtc_stats"; m.Payload = map[string]any{} }, "missing_field", "payload"},
+        {"usage negative cost", func(m *Message) { m.Type = "tts_usage"; m.Payload = map[string]any{"characters": 10.0, "estimated_cost_usd": -1.0} }, "invalid_field", "payload.estimated_cost_usd"},
+        {"turn state without trigger", func(m *Message) { m.Type = "turn_state"; m.Payload = map[string]any{"to": "LISTENING"} }, "missing_field", "payload.trigger"},
+        {"llm status without reason", func(m *Message) { m.Type = "llm_status"; m.Payload = map[string]any{"degraded": true} }, "missing_field", "payload.reason"},
+        {"profile suggestion without profile", func(m *Message) { m.Type = "barge_in_profile_suggested"; m.Payload = map[string]any{"applied": false} }, "missing_field", "payload.profile"},
+        {"interview ended without reason", func(m *Message) { m.Type = "interview_ended"; m.Payload = nil }, "missing_field", "payload.reason"},
+        {"stats loss over 100", func(m *Message) { m.Type = "webrtc_stats"; m.Payload = map[string]any{"packet_loss_pct": 101.0} }, "invalid_field", "payload.packet_loss_pct"},
+    }
+    for _, c := range cases {
+        m := ok
+        m.Payload = map[string]any{"source": "candidate_audio"}
+        c.mut(&m)
+        err := Validate("s1", m)
+        if err == nil || err.Reason != c.reason || err.Field != c.field {
+            t.Errorf("%s: got %v, want %s on %s", c.name, err, c.reason, c.field)
+        }
+    }
+
+    unknown := ok
+    unknown.Type = "future_event"
+    if err := Validate("s1", unknown); err != nil {
+        t.Errorf("unknown types should pass: %v", err)
+    }
+}
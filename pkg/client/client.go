@@ -0,0 +1,541 @@
+// Package client is a Go client for the agent's control API (cmd/server):
+// the HTTP session endpoints and the worker WebSocket protocol described in
+// internal/protocol/protocol.md. It has no dependency on the server's
+// internal packages, so services outside this module can use it.
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Client talks to one API server. The zero value is not usable; call New.
+type Client struct {
+	baseURL string
+	apiKey  string
+	devKey  string
+	http    *http.Client
+
+	// PollInterval paces StreamEvents.
+	PollInterval time.Duration
+}
+
+// Option configures a Client.
+type Option func(*Client)
+
+// WithAPIKey sends key as X-API-Key, for multi-tenant deployments.
+func WithAPIKey(key string) Option { return func(c *Client) { c.apiKey = key } }
+
+// WithDevKey sends key as X-Dev-Key on dev-only endpoints.
+func WithDevKey(key string) Option { return func(c *Client) { c.devKey = key } }
+
+// WithHTTPClient replaces http.DefaultClient.
+func WithHTTPClient(hc *http.Client) Option { return func(c *Client) { c.http = hc } }
+
+// New returns a client for the server at baseURL (e.g. http://localhost:8080).
+func New(baseURL string, opts ...Option) *Client {
+	c := &Client{baseURL: strings.TrimSuffix(baseURL, "/"), http: http.DefaultClient, PollInterval: time.Second}
+	for _, o := range opts {
+		o(c)
+	}
+	return c
+}
+
+// Style shapes the agent's replies; zero fields use the server defaults.
+// Temperature is a pointer so that 0 can be asked for.
+type Style struct {
+	Persona     string   `json:"persona,omitempty"`
+	Verbosity   string   `json:"verbosity,omitempty"`
+	Temperature *float64 `json:"temperature,omitempty"`
+	MaxTokens   int      `json:"max_tokens,omitempty"`
+	// SystemPrompt is only accepted in presets.
+	SystemPrompt string `json:"system_prompt,omitempty"`
+	// Instructions are added to the prompt for this session alone and take
+	// precedence over the tenant's and the flow's.
+	Instructions string `json:"instructions,omitempty"`
+	// Captions streams live captions to the room as "caption" app messages.
+	Captions bool `json:"captions,omitempty"`
+	// TokenStream sends the agent's reply to the room as "token_delta"
+	// app messages while it is generated.
+	TokenStream bool `json:"token_stream,omitempty"`
+	// EchoTest has the agent repeat what it heard ("You said: ...")
+	// instead of replying, to check the audio path end to end.
+	EchoTest bool `json:"echo_test,omitempty"`
+	// MaxDurationSeconds ends the interview after this long, with a
+	// closing statement.
+	MaxDurationSeconds int `json:"max_duration_s,omitempty"`
+}
+
+// Preset is a named session template; see PutPreset.
+type Preset struct {
+	Name        string          `json:"name"`
+	Description string          `json:"description,omitempty"`
+	Style       Style           `json:"style"`
+	VoiceID     string          `json:"voice_id,omitempty"`
+	Flow        json.RawMessage `json:"flow,omitempty"`
+	VAD         struct {
+		// Profile is headset, laptop-speakers, phone or auto; the
+		// thresholds below override its values.
+		Profile  string `json:"profile,omitempty"`
+		MinRMS   int    `json:"min_rms,omitempty"`
+		GuardMs  int    `json:"guard_ms,omitempty"`
+		Hangover int    `json:"hangover,omitempty"`
+	} `json:"vad"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// ContextDoc is reference material for a session; see UploadContext.
+type ContextDoc struct {
+	Name string `json:"name"`
+	Kind string `json:"kind,omitempty"` // e.g. "resume", "job"
+	Text string `json:"text"`
+}
+
+// ContextDocInfo describes an uploaded document.
+type ContextDocInfo struct {
+	Name  string `json:"name"`
+	Kind  string `json:"kind,omitempty"`
+	Bytes int    `json:"bytes"`
+}
+
+// Session is a created interview session.
+type Session struct {
+	ID       string `json:"session_id"`
+	TenantID string `json:"tenant_id"`
+	RoomName string `json:"room_name"`
+	RoomURL  string `json:"room_url"`
+	BotToken string `json:"bot_token"`
+	Style    Style  `json:"style"`
+}
+
+// SessionInfo is a session as listed by ListSessions.
+type SessionInfo struct {
+	ID         string    `json:"session_id"`
+	RoomName   string    `json:"room_name"`
+	CreatedAt  time.Time `json:"created_at"`
+	Status     string    `json:"status"`
+	BotRunning bool      `json:"bot_running"`
+	Events     int       `json:"events"`
+}
+
+// Event is one entry of a session's event log.
+type Event struct {
+	Type      string         `json:"type"`
+	Timestamp time.Time      `json:"timestamp"`
+	Payload   map[string]any `json:"payload,omitempty"`
+}
+
+// NetworkSample is one WebRTC stats report; unmeasured metrics are nil.
+type NetworkSample struct {
+	Timestamp     time.Time `json:"timestamp"`
+	RTTMs         *float64  `json:"rtt_ms,omitempty"`
+	JitterMs      *float64  `json:"jitter_ms,omitempty"`
+	PacketLossPct *float64  `json:"packet_loss_pct,omitempty"`
+	AudioLevel    *float64  `json:"audio_level,omitempty"`
+}
+
+// StatSummary aggregates one metric.
+type StatSummary struct {
+	Avg float64 `json:"avg"`
+	P95 float64 `json:"p95"`
+	Max float64 `json:"max"`
+}
+
+// NetworkStats is a session's call-quality report.
+type NetworkStats struct {
+	Summary struct {
+		Samples       int          `json:"samples"`
+		FirstAt       *time.Time   `json:"first_at,omitempty"`
+		LastAt        *time.Time   `json:"last_at,omitempty"`
+		RTTMs         *StatSummary `json:"rtt_ms,omitempty"`
+		JitterMs      *StatSummary `json:"jitter_ms,omitempty"`
+		PacketLossPct *StatSummary `json:"packet_loss_pct,omitempty"`
+		AudioLevel    *StatSummary `json:"audio_level,omitempty"`
+	} `json:"summary"`
+	Samples []NetworkSample `json:"samples"`
+	// Nil until the server has stored a report for the session
+	ClockSkew *ClockSkew `json:"clock_skew"`
+}
+
+// ClockSkew compares the worker's message timestamps with the server's
+// clock. Delays mix network latency with clock offset; OffsetMs, from
+// command round trips, separates them.
+type ClockSkew struct {
+	From          time.Time    `json:"from"`
+	Until         time.Time    `json:"until"`
+	Messages      int          `json:"messages"`
+	DelayMs       StatSummary  `json:"delay_ms"`
+	JitterMs      float64      `json:"jitter_ms"`
+	DriftMsPerMin *float64     `json:"drift_ms_per_min,omitempty"`
+	RoundTrips    int          `json:"round_trips"`
+	RTTMs         *StatSummary `json:"rtt_ms,omitempty"`
+	OffsetMs      *float64     `json:"offset_ms,omitempty"`
+	OffsetErrorMs *float64     `json:"offset_error_ms,omitempty"`
+	// ok, clock_offset, clock_drift, network_jitter or unknown
+	Assessment string `json:"assessment"`
+}
+
+// SearchResult is a session whose transcript matched a Search, with up to
+// five of its matching utterances.
+type SearchResult struct {
+	SessionID  string    `json:"session_id"`
+	CreatedAt  time.Time `json:"created_at"`
+	Status     string    `json:"status"`
+	Matches    int       `json:"matches"`
+	Utterances []struct {
+		Role      string    `json:"role"`   // candidate | agent | interviewer
+		Source    string    `json:"source"` // event | summary
+		TurnID    string    `json:"turn_id,omitempty"`
+		Ts        time.Time `json:"ts"`
+		Text      string    `json:"text"`
+		Highlight string    `json:"highlight"` // matches wrapped in <mark></mark>
+	} `json:"utterances"`
+}
+
+// WSCreds are the worker WebSocket URL and token for a session.
+type WSCreds struct {
+	URL     string `json:"ws_url"`
+	Token   string `json:"worker_token"`
+	ExpUnix int64  `json:"exp_unix"`
+}
+
+// APIError is a non-2xx response.
+type APIError struct {
+	StatusCode int
+	Class      string        // X-Error-Class, when the server set one
+	RetryAfter time.Duration // from Retry-After on 429/503
+	Message    string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("api: %d %s", e.StatusCode, e.Message)
+}
+
+// CreateSession creates a session and its Daily room. style may be nil.
+func (c *Client) CreateSession(ctx context.Context, style *Style) (*Session, error) {
+	return c.CreateSessionFromPreset(ctx, "", style)
+}
+
+// CreateSessionFromPreset creates a session from the named preset; fields
+// set in style override the preset's.
+func (c *Client) CreateSessionFromPreset(ctx context.Context, preset string, style *Style) (*Session, error) {
+	return c.CreateSessionWithProfile(ctx, preset, "", style)
+}
+
+// CreateSessionWithProfile is CreateSessionFromPreset with the barge-in
+// profile for the candidate's audio setup (headset, laptop-speakers, phone,
+// or auto to follow the noise floor), which replaces the preset's
+// thresholds. preset and profile may be empty.
+func (c *Client) CreateSessionWithProfile(ctx context.Context, preset, profile string, style *Style) (*Session, error) {
+	body := map[string]any{}
+	if preset != "" {
+		body["preset"] = preset
+	}
+	if profile != "" {
+		body["barge_in_profile"] = profile
+	}
+	if style != nil {
+		body["style"] = style
+	}
+	var s Session
+	if err := c.do(ctx, http.MethodPost, "/sessions", body, &s, false); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// ListSessions returns the caller's sessions, newest first.
+func (c *Client) ListSessions(ctx context.Context) ([]SessionInfo, error) {
+	var out struct {
+		Sessions []SessionInfo `json:"sessions"`
+	}
+	if err := c.do(ctx, http.MethodGet, "/sessions", nil, &out, false); err != nil {
+		return nil, err
+	}
+	return out.Sessions, nil
+}
+
+// StartBot starts the session's bot; it is a no-op when one is running.
+func (c *Client) StartBot(ctx context.Context, sessionID string) error {
+	return c.do(ctx, http.MethodPost, "/sessions/"+url.PathEscape(sessionID)+"/start", nil, nil, false)
+}
+
+// EndSession stops the session's bot.
+func (c *Client) EndSession(ctx context.Context, sessionID string) error {
+	return c.do(ctx, http.MethodPost, "/sessions/"+url.PathEscape(sessionID)+"/end", nil, nil, false)
+}
+
+// Events returns the session's event log.
+func (c *Client) Events(ctx context.Context, sessionID string) ([]Event, error) {
+	var out struct {
+		Events []Event `json:"events"`
+	}
+	if err := c.do(ctx, http.MethodGet, "/sessions/"+url.PathEscape(sessionID)+"/events", nil, &out, false); err != nil {
+		return nil, err
+	}
+	return out.Events, nil
+}
+
+// EventsSince returns the session's events from index since on, and the
+// index to ask for next. Indexes stay stable when the server truncates old
+// events.
+func (c *Client) EventsSince(ctx context.Context, sessionID string, since int) ([]Event, int, error) {
+	var out struct {
+		Events    []Event `json:"events"`
+		NextIndex int     `json:"next_index"`
+	}
+	path := "/sessions/" + url.PathEscape(sessionID) + "/events?since_index=" + strconv.Itoa(since)
+	if err := c.do(ctx, http.MethodGet, path, nil, &out, false); err != nil {
+		return nil, since, err
+	}
+	return out.Events, out.NextIndex, nil
+}
+
+// StreamEvents calls fn for each event from index since onwards, polling
+// every PollInterval, until ctx ends or fn returns an error. It returns
+// ctx's error or fn's.
+func (c *Client) StreamEvents(ctx context.Context, sessionID string, since int, fn func(index int, ev Event) error) error {
+	t := time.NewTicker(c.PollInterval)
+	defer t.Stop()
+	for {
+		evs, next, err := c.EventsSince(ctx, sessionID, since)
+		if err != nil {
+			return err
+		}
+		// Events before next-len(evs) were truncated before we saw them
+		for i, ev := range evs {
+			if err := fn(next-len(evs)+i, ev); err != nil {
+				return err
+			}
+		}
+		since = next
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-t.C:
+		}
+	}
+}
+
+// NetworkStats returns the session's WebRTC stats summary and up to limit
+// of its latest samples; limit <= 0 uses the server default.
+func (c *Client) NetworkStats(ctx context.Context, sessionID string, limit int) (*NetworkStats, error) {
+	path := "/sessions/" + url.PathEscape(sessionID) + "/network-stats"
+	if limit > 0 {
+		path += "?limit=" + strconv.Itoa(limit)
+	}
+	var ns NetworkStats
+	if err := c.do(ctx, http.MethodGet, path, nil, &ns, false); err != nil {
+		return nil, err
+	}
+	return &ns, nil
+}
+
+// Search returns the caller's sessions whose transcripts contain every
+// word of q ("word*" matches a prefix), best first; limit <= 0 uses the
+// server default.
+func (c *Client) Search(ctx context.Context, q string, limit int) ([]SearchResult, error) {
+	path := "/search?q=" + url.QueryEscape(q)
+	if limit > 0 {
+		path += "&limit=" + strconv.Itoa(limit)
+	}
+	var out struct {
+		Results []SearchResult `json:"results"`
+	}
+	if err := c.do(ctx, http.MethodGet, path, nil, &out, false); err != nil {
+		return nil, err
+	}
+	return out.Results, nil
+}
+
+// Export writes the session's turns in format (e.g. "openai-jsonl") to w.
+func (c *Client) Export(ctx context.Context, sessionID, format string, w io.Writer) error {
+	resp, err := c.send(ctx, http.MethodGet, "/sessions/"+url.PathEscape(sessionID)+"/export?format="+url.QueryEscape(format), nil, false)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, err = io.Copy(w, resp.Body)
+	return err
+}
+
+// UploadContext adds doc to the session, replacing a document with the
+// same name, and returns the session's documents. Upload before StartBot:
+// the orchestrator draws on them to ground the agent's replies.
+func (c *Client) UploadContext(ctx context.Context, sessionID string, doc ContextDoc) ([]ContextDocInfo, error) {
+	var out struct {
+		Documents []ContextDocInfo `json:"documents"`
+	}
+	if err := c.do(ctx, http.MethodPost, "/sessions/"+url.PathEscape(sessionID)+"/context", doc, &out, false); err != nil {
+		return nil, err
+	}
+	return out.Documents, nil
+}
+
+// ListContext returns the session's uploaded documents.
+func (c *Client) ListContext(ctx context.Context, sessionID string) ([]ContextDocInfo, error) {
+	var out struct {
+		Documents []ContextDocInfo `json:"documents"`
+	}
+	if err := c.do(ctx, http.MethodGet, "/sessions/"+url.PathEscape(sessionID)+"/context", nil, &out, false); err != nil {
+		return nil, err
+	}
+	return out.Documents, nil
+}
+
+// ListPresets returns the caller's presets sorted by name.
+func (c *Client) ListPresets(ctx context.Context) ([]Preset, error) {
+	var out struct {
+		Presets []Preset `json:"presets"`
+	}
+	if err := c.do(ctx, http.MethodGet, "/presets", nil, &out, false); err != nil {
+		return nil, err
+	}
+	return out.Presets, nil
+}
+
+// GetPreset returns the named preset.
+func (c *Client) GetPreset(ctx context.Context, name string) (*Preset, error) {
+	var p Preset
+	if err := c.do(ctx, http.MethodGet, "/presets/"+url.PathEscape(name), nil, &p, false); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// PutPreset creates or replaces p.
+func (c *Client) PutPreset(ctx context.Context, p Preset) (*Preset, error) {
+	var out Preset
+	if err := c.do(ctx, http.MethodPut, "/presets/"+url.PathEscape(p.Name), p, &out, false); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// DeletePreset removes the named preset; sessions created from it keep
+// their settings.
+func (c *Client) DeletePreset(ctx context.Context, name string) error {
+	return c.do(ctx, http.MethodDelete, "/presets/"+url.PathEscape(name), nil, nil, false)
+}
+
+// WorkerCreds mints worker WebSocket credentials (dev-only endpoint).
+func (c *Client) WorkerCreds(ctx context.Context, sessionID string) (*WSCreds, error) {
+	var cr WSCreds
+	if err := c.do(ctx, http.MethodPost, "/sessions/"+url.PathEscape(sessionID)+"/ws-creds", nil, &cr, true); err != nil {
+		return nil, err
+	}
+	return &cr, nil
+}
+
+// DebugVAD injects a vad_start or vad_end into the floor dispatcher
+// without a worker (dev-only endpoint).
+func (c *Client) DebugVAD(ctx context.Context, sessionID string, start bool) error {
+	action := "vad-end"
+	if start {
+		action = "vad-start"
+	}
+	return c.do(ctx, http.MethodPost, "/sessions/"+url.PathEscape(sessionID)+"/debug/"+action, nil, nil, true)
+}
+
+// Healthz checks the server's liveness endpoint.
+func (c *Client) Healthz(ctx context.Context) error {
+	resp, err := c.send(ctx, http.MethodGet, "/healthz", nil, false)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// Health returns the server's dependency checks (GET /health). The report
+// is returned with an *APIError when a check failed.
+func (c *Client) Health(ctx context.Context) (map[string]any, error) {
+	req, err := c.request(ctx, http.MethodGet, "/health", nil, false)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	var out map[string]any
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return nil, fmt.Errorf("api: decode health: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return out, &APIError{StatusCode: resp.StatusCode, Message: "unhealthy"}
+	}
+	return out, nil
+}
+
+// do sends body as JSON and decodes the response into out when non-nil.
+func (c *Client) do(ctx context.Context, method, path string, body, out any, dev bool) error {
+	resp, err := c.send(ctx, method, path, body, dev)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if out == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("api: decode %s %s: %w", method, path, err)
+	}
+	return nil
+}
+
+// send performs the request and turns non-2xx responses into *APIError.
+func (c *Client) send(ctx context.Context, method, path string, body any, dev bool) (*http.Response, error) {
+	req, err := c.request(ctx, method, path, body, dev)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode/100 != 2 {
+		defer resp.Body.Close()
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 4<<10))
+		ae := &APIError{StatusCode: resp.StatusCode, Class: resp.Header.Get("X-Error-Class"), Message: strings.TrimSpace(string(msg))}
+		if secs, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil {
+			ae.RetryAfter = time.Duration(secs) * time.Second
+		}
+		return nil, ae
+	}
+	return resp, nil
+}
+
+func (c *Client) request(ctx context.Context, method, path string, body any, dev bool) (*http.Request, error) {
+	var rd io.Reader
+	if body != nil {
+		b, err := json.Marshal(body)
+		if err != nil {
+			return nil, err
+		}
+		rd = bytes.NewReader(b)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, rd)
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.apiKey != "" {
+		req.Header.Set("X-API-Key", c.apiKey)
+	}
+	if dev && c.devKey != "" {
+		req.Header.Set("X-Dev-Key", c.devKey)
+	}
+	return req, nil
+}
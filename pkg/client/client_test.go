@@ -0,0 +1,187 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"yuzu/agent/internal/api"
+	"yuzu/agent/internal/config"
+	"yuzu/agent/internal/store"
+	"yuzu/agent/internal/workerws"
+)
+
+type fakeDaily struct{}
+
+func (fakeDaily) CreateRoom(name, privacy string) error { return nil }
+func (fakeDaily) CreateMeetingToken(roomName, userName string, exp int64, isBot bool) (string, error) {
+	return "tok", nil
+}
+func (fakeDaily) DeleteRoom(name string) error { return nil }
+
+type fakeRunner struct{ running map[string]bool }
+
+func (r *fakeRunner) Start(id string, env map[string]string) error { r.running[id] = true; return nil }
+func (r *fakeRunner) Stop(id string) error                         { delete(r.running, id); return nil }
+func (r *fakeRunner) IsRunning(id string) bool                     { return r.running[id] }
+
+func newTestServer(t *testing.T) (*httptest.Server, store.Store) {
+	t.Helper()
+	cfg := config.Load()
+	cfg.Daily.APIKey, cfg.Daily.Domain = "key", "example.daily.co"
+	cfg.Dev.Mode = true
+	cfg.Worker.TokenSecret = "secret"
+	st := store.New()
+	mux := http.NewServeMux()
+	mux.Handle("/", api.NewRouter(api.NewHandlers(cfg, st, fakeDaily{}, &fakeRunner{running: map[string]bool{}})))
+	mux.HandleFunc("/ws/worker", workerws.NewServer(cfg, st, workerws.NewRegistry()).HandleWorkerWS)
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	return srv, st
+}
+
+func TestClientSessionLifecycle(t *testing.T) {
+	srv, _ := newTestServer(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	c := New(srv.URL)
+	c.PollInterval = 10 * time.Millisecond
+
+	sess, err := c.CreateSession(ctx, &Style{Persona: "formal"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sess.ID == "" || sess.Style.Persona != "formal" {
+		t.Fatalf("session = %+v", sess)
+	}
+	docs, err := c.UploadContext(ctx, sess.ID, ContextDoc{Name: "resume", Kind: "resume", Text: "Five years of Go."})
+	if err != nil || len(docs) != 1 || docs[0].Name != "resume" || docs[0].Bytes != 17 {
+		t.Fatalf("UploadContext = %+v, %v", docs, err)
+	}
+	if docs, err := c.ListContext(ctx, sess.ID); err != nil || len(docs) != 1 {
+		t.Fatalf("ListContext = %+v, %v", docs, err)
+	}
+	if err := c.StartBot(ctx, sess.ID); err != nil {
+		t.Fatal(err)
+	}
+	list, err := c.ListSessions(ctx)
+	if err != nil || len(list) != 1 || list[0].ID != sess.ID || !list[0].BotRunning {
+		t.Fatalf("ListSessions = %+v, %v", list, err)
+	}
+	if err := c.EndSession(ctx, sess.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	var seen []string
+	stop := errors.New("stop")
+	err = c.StreamEvents(ctx, sess.ID, 0, func(i int, ev Event) error {
+		seen = append(seen, ev.Type)
+		if ev.Type == "bot_stopped" {
+			return stop
+		}
+		return nil
+	})
+	if err != stop || seen[0] != "session_created" {
+		t.Fatalf("StreamEvents = %v, events %v", err, seen)
+	}
+
+	var ae *APIError
+	if err := c.StartBot(ctx, "nope"); !errors.As(err, &ae) || ae.StatusCode != http.StatusNotFound {
+		t.Errorf("StartBot(unknown) = %v, want 404 APIError", err)
+	}
+}
+
+func TestWorkerConnSendsVAD(t *testing.T) {
+	srv, st := newTestServer(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	c := New(srv.URL)
+	sess, err := c.CreateSession(ctx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	creds, err := c.WorkerCreds(ctx, sess.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w, err := DialWorker(ctx, creds.URL, creds.Token, sess.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+
+	if err := w.Hello(ctx, "test", true); err != nil {
+		t.Fatal(err)
+	}
+	if m, err := w.Read(ctx); err != nil || m.Type != "policy" {
+		t.Fatalf("reply to hello = %+v, %v", m, err)
+	}
+	if err := w.SendVAD(ctx, true, "candidate_audio"); err != nil {
+		t.Fatal(err)
+	}
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		for _, ev := range st.ListEvents(sess.ID) {
+			if ev.Type == "vad_start" {
+				if ev.Payload["seq"] != int64(2) {
+					t.Errorf("vad_start seq = %v, want 2", ev.Payload["seq"])
+				}
+				return
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatal("vad_start never reached the server")
+}
+
+func TestWorkerStatsReachNetworkStats(t *testing.T) {
+	srv, _ := newTestServer(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	c := New(srv.URL)
+	sess, err := c.CreateSession(ctx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	creds, err := c.WorkerCreds(ctx, sess.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w, err := DialWorker(ctx, creds.URL, creds.Token, sess.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+
+	f := func(v float64) *float64 { return &v }
+	for _, rtt := range []float64{40, 60, 200} {
+		if err := w.SendStats(ctx, NetworkSample{RTTMs: f(rtt), PacketLossPct: f(1)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Out of range values are rejected, not stored
+	if err := w.SendStats(ctx, NetworkSample{PacketLossPct: f(140)}); err != nil {
+		t.Fatal(err)
+	}
+	if m, err := w.Read(ctx); err != nil || m.Type != "error" || m.Payload["field"] != "payload.packet_loss_pct" {
+		t.Fatalf("reply to bad stats = %+v, %v", m, err)
+	}
+
+	ns, err := c.NetworkStats(ctx, sess.ID, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rtt := ns.Summary.RTTMs
+	if ns.Summary.Samples != 3 || rtt == nil || rtt.Avg != 100 || rtt.Max != 200 || ns.Summary.JitterMs != nil {
+		t.Fatalf("summary = %+v rtt %+v", ns.Summary, rtt)
+	}
+	if len(ns.Samples) != 2 || *ns.Samples[1].RTTMs != 200 {
+		t.Errorf("samples = %+v, want the latest two", ns.Samples)
+	}
+	if _, err := c.NetworkStats(ctx, "nope", 0); err == nil {
+		t.Error("unknown session should 404")
+	}
+}
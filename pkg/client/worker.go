@@ -0,0 +1,120 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	ws "nhooyr.io/websocket"
+)
+
+// WorkerMessage is the worker protocol envelope.
+type WorkerMessage struct {
+	Type        string         `json:"type"`
+	TsMs        int64          `json:"ts_ms"`
+	SessionID   string         `json:"session_id"`
+	Seq         int64          `json:"seq"`
+	Epoch       int64          `json:"epoch,omitempty"`
+	CommandID   string         `json:"command_id,omitempty"`
+	UtteranceID string         `json:"utterance_id,omitempty"`
+	Payload     map[string]any `json:"payload,omitempty"`
+}
+
+// WorkerConn is a worker WebSocket connection. It fills in ts_ms, seq and
+// epoch on every message it sends; it is safe for concurrent use.
+type WorkerConn struct {
+	conn      *ws.Conn
+	sessionID string
+	epoch     int64
+
+	mu  sync.Mutex
+	seq int64
+}
+
+// DialWorker connects to the worker WebSocket for sessionID, e.g. with the
+// URL and token from Client.WorkerCreds.
+func DialWorker(ctx context.Context, wsURL, token, sessionID string) (*WorkerConn, error) {
+	h := http.Header{}
+	h.Set("Authorization", "Bearer "+token)
+	c, _, err := ws.Dial(ctx, wsURL, &ws.DialOptions{HTTPHeader: h})
+	if err != nil {
+		return nil, fmt.Errorf("worker ws: %w", err)
+	}
+	return &WorkerConn{conn: c, sessionID: sessionID, epoch: time.Now().UnixMilli()}, nil
+}
+
+// Send stamps and sends msg.
+func (w *WorkerConn) Send(ctx context.Context, msg WorkerMessage) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.seq++
+	msg.Seq, msg.Epoch, msg.SessionID = w.seq, w.epoch, w.sessionID
+	if msg.TsMs == 0 {
+		msg.TsMs = time.Now().UnixMilli()
+	}
+	b, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	return w.conn.Write(ctx, ws.MessageText, b)
+}
+
+// Hello announces the worker; the server answers with a policy message.
+func (w *WorkerConn) Hello(ctx context.Context, version string, localStopCapable bool) error {
+	return w.Send(ctx, WorkerMessage{Type: "worker_hello", Payload: map[string]any{"version": version, "local_stop_capable": localStopCapable}})
+}
+
+// SendVAD reports the candidate starting (start) or stopping speech.
+func (w *WorkerConn) SendVAD(ctx context.Context, start bool, source string) error {
+	typ := "vad_end"
+	if start {
+		typ = "vad_start"
+	}
+	return w.Send(ctx, WorkerMessage{Type: typ, Payload: map[string]any{"source": source}})
+}
+
+// SendTTS reports a TTS lifecycle event (tts_started, tts_first_audio,
+// tts_stopped) for utteranceID; reason is required for tts_stopped.
+func (w *WorkerConn) SendTTS(ctx context.Context, typ, utteranceID, reason string) error {
+	p := map[string]any{"source": "worker_local"}
+	if reason != "" {
+		p["reason"] = reason
+	}
+	return w.Send(ctx, WorkerMessage{Type: typ, UtteranceID: utteranceID, Payload: p})
+}
+
+// SendStats reports a WebRTC stats sample; the sample's Timestamp is
+// ignored in favour of the send time.
+func (w *WorkerConn) SendStats(ctx context.Context, s NetworkSample) error {
+	p := map[string]any{}
+	for k, v := range map[string]*float64{"rtt_ms": s.RTTMs, "jitter_ms": s.JitterMs, "packet_loss_pct": s.PacketLossPct, "audio_level": s.AudioLevel} {
+		if v != nil {
+			p[k] = *v
+		}
+	}
+	return w.Send(ctx, WorkerMessage{Type: "webrtc_stats", Payload: p})
+}
+
+// Ack acknowledges a command such as stop_tts.
+func (w *WorkerConn) Ack(ctx context.Context, commandID, errMsg string) error {
+	return w.Send(ctx, WorkerMessage{Type: "cmd_ack", CommandID: commandID, Payload: map[string]any{"ack": errMsg == "", "error": errMsg}})
+}
+
+// Read returns the next message from the server (policy, stop_tts, error).
+func (w *WorkerConn) Read(ctx context.Context) (WorkerMessage, error) {
+	var m WorkerMessage
+	_, b, err := w.conn.Read(ctx)
+	if err != nil {
+		return m, err
+	}
+	err = json.Unmarshal(b, &m)
+	return m, err
+}
+
+// Close closes the connection normally.
+func (w *WorkerConn) Close() error {
+	return w.conn.Close(ws.StatusNormalClosure, "done")
+}